@@ -32,11 +32,15 @@ import (
 	"github.com/horizoncd/horizon/core/config"
 	accessctl "github.com/horizoncd/horizon/core/controller/access"
 	accesstokenctl "github.com/horizoncd/horizon/core/controller/accesstoken"
+	alertctl "github.com/horizoncd/horizon/core/controller/alert"
 	applicationctl "github.com/horizoncd/horizon/core/controller/application"
 	applicationregionctl "github.com/horizoncd/horizon/core/controller/applicationregion"
+	auditctl "github.com/horizoncd/horizon/core/controller/audit"
 	"github.com/horizoncd/horizon/core/controller/build"
+	canaryctl "github.com/horizoncd/horizon/core/controller/canary"
 	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
 	codectl "github.com/horizoncd/horizon/core/controller/code"
+	costctl "github.com/horizoncd/horizon/core/controller/cost"
 	environmentctl "github.com/horizoncd/horizon/core/controller/environment"
 	environmentregionctl "github.com/horizoncd/horizon/core/controller/environmentregion"
 	envtemplatectl "github.com/horizoncd/horizon/core/controller/envtemplate"
@@ -50,13 +54,16 @@ import (
 	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
 	regionctl "github.com/horizoncd/horizon/core/controller/region"
 	registryctl "github.com/horizoncd/horizon/core/controller/registry"
+	releasepipelinectl "github.com/horizoncd/horizon/core/controller/releasepipeline"
 	roltctl "github.com/horizoncd/horizon/core/controller/role"
+	scheduledeployctl "github.com/horizoncd/horizon/core/controller/scheduledeploy"
 	scopectl "github.com/horizoncd/horizon/core/controller/scope"
 	tagctl "github.com/horizoncd/horizon/core/controller/tag"
 	templatectl "github.com/horizoncd/horizon/core/controller/template"
 	templateschematagctl "github.com/horizoncd/horizon/core/controller/templateschematag"
 	terminalctl "github.com/horizoncd/horizon/core/controller/terminal"
 	userctl "github.com/horizoncd/horizon/core/controller/user"
+	usergroupctl "github.com/horizoncd/horizon/core/controller/usergroup"
 	webhookctl "github.com/horizoncd/horizon/core/controller/webhook"
 	accessapi "github.com/horizoncd/horizon/core/http/api/v1/access"
 	"github.com/horizoncd/horizon/core/http/api/v1/accesstoken"
@@ -82,8 +89,12 @@ import (
 	"github.com/horizoncd/horizon/core/http/api/v1/template"
 	accessv2 "github.com/horizoncd/horizon/core/http/api/v2/access"
 	accesstokenv2 "github.com/horizoncd/horizon/core/http/api/v2/accesstoken"
+	alertv2 "github.com/horizoncd/horizon/core/http/api/v2/alert"
 	applicationregionv2 "github.com/horizoncd/horizon/core/http/api/v2/applicationregion"
+	auditv2 "github.com/horizoncd/horizon/core/http/api/v2/audit"
+	canaryv2 "github.com/horizoncd/horizon/core/http/api/v2/canary"
 	codev2 "github.com/horizoncd/horizon/core/http/api/v2/code"
+	costv2 "github.com/horizoncd/horizon/core/http/api/v2/cost"
 	environmentv2 "github.com/horizoncd/horizon/core/http/api/v2/environment"
 	environmentregionv2 "github.com/horizoncd/horizon/core/http/api/v2/environmentregion"
 	eventv2 "github.com/horizoncd/horizon/core/http/api/v2/event"
@@ -94,23 +105,32 @@ import (
 	pipelinerunv2 "github.com/horizoncd/horizon/core/http/api/v2/pipelinerun"
 	regionv2 "github.com/horizoncd/horizon/core/http/api/v2/region"
 	registryv2 "github.com/horizoncd/horizon/core/http/api/v2/registry"
+	releasepipelinev2 "github.com/horizoncd/horizon/core/http/api/v2/releasepipeline"
 	rolev2 "github.com/horizoncd/horizon/core/http/api/v2/role"
+	scheduledeployv2 "github.com/horizoncd/horizon/core/http/api/v2/scheduledeploy"
 	scopev2 "github.com/horizoncd/horizon/core/http/api/v2/scope"
 	tagv2 "github.com/horizoncd/horizon/core/http/api/v2/tag"
 	templateschematagv2 "github.com/horizoncd/horizon/core/http/api/v2/templateschematag"
 	terminalv2 "github.com/horizoncd/horizon/core/http/api/v2/terminal"
 	userv2 "github.com/horizoncd/horizon/core/http/api/v2/user"
+	usergroupv2 "github.com/horizoncd/horizon/core/http/api/v2/usergroup"
 	webhookv2 "github.com/horizoncd/horizon/core/http/api/v2/webhook"
 	"github.com/horizoncd/horizon/core/middleware"
 	"github.com/horizoncd/horizon/core/middleware/auth"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
+	gitlib "github.com/horizoncd/horizon/lib/git"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
 	"github.com/horizoncd/horizon/pkg/cd"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	"github.com/horizoncd/horizon/pkg/grafana"
 	"github.com/horizoncd/horizon/pkg/jobs"
 	"github.com/horizoncd/horizon/pkg/jobs/jobautofree"
+	"github.com/horizoncd/horizon/pkg/jobs/jobcanary"
+	"github.com/horizoncd/horizon/pkg/jobs/jobdrift"
 	"github.com/horizoncd/horizon/pkg/jobs/jobgrafanasync"
+	"github.com/horizoncd/horizon/pkg/jobs/jobmemberexpiry"
+	"github.com/horizoncd/horizon/pkg/jobs/jobretention"
+	"github.com/horizoncd/horizon/pkg/jobs/jobscheduledeploy"
 	"github.com/horizoncd/horizon/pkg/jobs/jobwebhook"
 	"github.com/horizoncd/horizon/pkg/token/generator"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
@@ -127,6 +147,7 @@ import (
 	templatev2 "github.com/horizoncd/horizon/core/http/api/v2/template"
 	"github.com/horizoncd/horizon/core/http/health"
 	"github.com/horizoncd/horizon/core/http/metrics"
+	auditmiddle "github.com/horizoncd/horizon/core/middleware/audit"
 	ginlogmiddle "github.com/horizoncd/horizon/core/middleware/ginlog"
 	logmiddle "github.com/horizoncd/horizon/core/middleware/log"
 	metricsmiddle "github.com/horizoncd/horizon/core/middleware/metrics"
@@ -138,14 +159,18 @@ import (
 	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/pkg/application/gitrepo"
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
+	approvalservice "github.com/horizoncd/horizon/pkg/approval/service"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
 	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/render"
 	clusterservice "github.com/horizoncd/horizon/pkg/cluster/service"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
+	gitlabconfig "github.com/horizoncd/horizon/pkg/config/gitlab"
 	oauthconfig "github.com/horizoncd/horizon/pkg/config/oauth"
 	"github.com/horizoncd/horizon/pkg/config/pprof"
 	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	"github.com/horizoncd/horizon/pkg/cost"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
@@ -155,8 +180,10 @@ import (
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	"github.com/horizoncd/horizon/pkg/rbac"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschemarepo "github.com/horizoncd/horizon/pkg/templaterelease/schema/repo"
+	templatesource "github.com/horizoncd/horizon/pkg/templaterelease/source"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
 	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
@@ -312,41 +339,72 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	// init manager parameter
 	manager := managerparam.InitManager(mysqlDB)
 
-	gitlabGitops, err := gitlablib.New(coreConfig.GitopsRepoConfig.Token, coreConfig.GitopsRepoConfig.URL)
+	templateRepo, err := templaterepo.NewRepo(coreConfig.TemplateRepo)
 	if err != nil {
 		panic(err)
 	}
-	// check existence of gitops root group
+
+	var (
+		applicationGitRepo gitrepo.ApplicationGitRepo
+		clusterGitRepo     clustergitrepo.ClusterGitRepo
+	)
 	rootGroupPath := coreConfig.GitopsRepoConfig.RootGroupPath
-	rootGroup, err := gitlabGitops.GetGroup(ctx, rootGroupPath)
-	if err != nil {
-		log.Printf("failed to get gitops root group, error: %s, start to create it", err.Error())
-		rootGroup, err = gitlabGitops.CreateGroup(ctx, rootGroupPath, rootGroupPath,
-			nil, coreConfig.GitopsRepoConfig.DefaultVisibility)
+	switch clusterRepoConfig := coreConfig.GitopsRepoConfig.ClusterRepo; clusterRepoConfig.Kind {
+	case "", gitlabconfig.GitopsRepoKindGitlab:
+		gitlabGitops, err := gitlablib.New(coreConfig.GitopsRepoConfig.Token, coreConfig.GitopsRepoConfig.URL)
 		if err != nil {
 			panic(err)
 		}
-	}
-
-	applicationGitRepo, err := gitrepo.NewApplicationGitlabRepo(ctx, gitlabGitops, gitrepo.ApplicationGitRepoConfig{
-		RootGroup:         rootGroup,
-		DefaultBranch:     coreConfig.GitopsRepoConfig.DefaultBranch,
-		DefaultVisibility: coreConfig.GitopsRepoConfig.DefaultVisibility,
-	})
-
-	if err != nil {
-		panic(err)
-	}
-
-	templateRepo, err := templaterepo.NewRepo(coreConfig.TemplateRepo)
-	if err != nil {
-		panic(err)
-	}
-
-	clusterGitRepo, err := clustergitrepo.NewClusterGitlabRepo(ctx, rootGroup, templateRepo, gitlabGitops,
-		coreConfig.GitopsRepoConfig.DefaultBranch, coreConfig.GitopsRepoConfig.DefaultVisibility)
-	if err != nil {
-		panic(err)
+		// check existence of gitops root group
+		rootGroup, err := gitlabGitops.GetGroup(ctx, rootGroupPath)
+		if err != nil {
+			log.Printf("failed to get gitops root group, error: %s, start to create it", err.Error())
+			rootGroup, err = gitlabGitops.CreateGroup(ctx, rootGroupPath, rootGroupPath,
+				nil, coreConfig.GitopsRepoConfig.DefaultVisibility)
+			if err != nil {
+				panic(err)
+			}
+		}
+		applicationGitRepo, err = gitrepo.NewApplicationGitlabRepo(ctx, gitlabGitops, gitrepo.ApplicationGitRepoConfig{
+			RootGroup:         rootGroup,
+			DefaultBranch:     coreConfig.GitopsRepoConfig.DefaultBranch,
+			DefaultVisibility: coreConfig.GitopsRepoConfig.DefaultVisibility,
+		})
+		if err != nil {
+			panic(err)
+		}
+		clusterGitRepo, err = clustergitrepo.NewClusterGitlabRepo(ctx, rootGroup, templateRepo, gitlabGitops,
+			coreConfig.GitopsRepoConfig.DefaultBranch, coreConfig.GitopsRepoConfig.DefaultVisibility)
+		if err != nil {
+			panic(err)
+		}
+	case gitlabconfig.GitopsRepoKindGit:
+		gitLib, err := gitlib.New(gitlib.Config{
+			URL:           clusterRepoConfig.URL,
+			Token:         clusterRepoConfig.Token,
+			CacheDir:      clusterRepoConfig.CacheDir,
+			DefaultBranch: coreConfig.GitopsRepoConfig.DefaultBranch,
+		})
+		if err != nil {
+			panic(err)
+		}
+		applicationGitRepo = gitrepo.NewApplicationGitRepo(gitLib, gitrepo.ApplicationGitRepoGitConfig{
+			RootPath:      rootGroupPath,
+			FlatRepoPath:  clusterRepoConfig.FlatRepoPath,
+			DefaultBranch: coreConfig.GitopsRepoConfig.DefaultBranch,
+		})
+		clusterGitRepo, err = clustergitrepo.NewClusterGitRepo(ctx, templateRepo, gitLib,
+			clustergitrepo.ClusterGitRepoConfig{
+				RootPath:      rootGroupPath,
+				FlatRepoPath:  clusterRepoConfig.FlatRepoPath,
+				CloneURL:      clusterRepoConfig.CloneURL,
+				DefaultBranch: coreConfig.GitopsRepoConfig.DefaultBranch,
+			})
+		if err != nil {
+			panic(err)
+		}
+	default:
+		panic(fmt.Sprintf("unsupported kind of cluster repo: %s", clusterRepoConfig.Kind))
 	}
 
 	templateSchemaGetter := templateschemarepo.NewSchemaGetter(ctx, templateRepo, manager)
@@ -375,7 +433,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		panic(err)
 	}
 	mservice := memberservice.NewService(roleService, oauthManager, manager)
-	rbacAuthorizer := rbac.NewAuthorizer(roleService, mservice)
+	rbacAuthorizer := rbac.NewAuthorizer(roleService, mservice, manager)
 
 	// init scope service
 	scopeFile, err := os.OpenFile(flags.ScopeRoleFile, os.O_RDONLY, 0644)
@@ -425,6 +483,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	groupSvc := groupservice.NewService(manager)
 	applicationSvc := applicationservice.NewService(groupSvc, manager)
 	clusterSvc := clusterservice.NewService(applicationSvc, manager)
+	approvalSvc := approvalservice.NewService(mservice, manager)
 	userSvc := userservice.NewService(manager)
 	tokenSvc := tokenservice.NewService(manager, coreConfig.TokenConfig)
 
@@ -436,6 +495,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 
 	grafanaService := grafana.NewService(coreConfig.GrafanaConfig, manager, client)
 
+	costReporter, err := cost.NewReporter(&coreConfig.CostConfig)
+	if err != nil {
+		panic(err)
+	}
+
 	parameter := &param.Param{
 		Manager:              manager,
 		OauthManager:         oauthManager,
@@ -450,6 +514,9 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		ScopeService:         scopeService,
 		ApplicationGitRepo:   applicationGitRepo,
 		TemplateSchemaGetter: templateSchemaGetter,
+		TemplateSourceGetter: templatesource.NewGetter(coreConfig.TemplateSources),
+		MigrationGetter:      migration.NewGetter(templateRepo, manager),
+		ManifestRenderer:     render.NewRenderer(templateRepo, manager),
 		CD: cd.NewCD(clusterGitRepo, coreConfig.ArgoCDMapper,
 			coreConfig.GitopsRepoConfig.DefaultBranch),
 		K8sUtil:        cd.NewK8sUtil(),
@@ -458,7 +525,9 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		ClusterGitRepo: clusterGitRepo,
 		GitGetter:      gitGetter,
 		GrafanaService: grafanaService,
+		ApprovalSvc:    approvalSvc,
 		BuildSchema:    buildSchema,
+		Authorizer:     rbacAuthorizer,
 	}
 
 	var (
@@ -503,6 +572,13 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		scopeCtl             = scopectl.NewController(parameter)
 		webhookCtl           = webhookctl.NewController(parameter)
 		eventCtl             = eventctl.NewController(parameter)
+		canaryCtl            = canaryctl.NewController(parameter)
+		costCtl              = costctl.NewController(costReporter, parameter)
+		alertCtl             = alertctl.NewController(&coreConfig.AlertConfig, parameter)
+		scheduledDeployCtl   = scheduledeployctl.NewController(parameter)
+		releasePipelineCtl   = releasepipelinectl.NewController(parameter, clusterCtl)
+		userGroupCtl         = usergroupctl.NewController(parameter)
+		auditCtl             = auditctl.NewController(parameter)
 	)
 
 	var (
@@ -541,6 +617,9 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		applicationAPIV2       = appv2.NewAPI(applicationCtl)
 		applicationRegionAPIV2 = applicationregionv2.NewAPI(applicationRegionCtl)
 		buildSchemaAPI         = buildAPI.NewAPI(buildSchemaCtrl)
+		canaryAPIV2            = canaryv2.NewAPI(canaryCtl)
+		costAPIV2              = costv2.NewAPI(costCtl)
+		alertAPIV2             = alertv2.NewAPI(alertCtl)
 		clusterAPIV2           = clusterv2.NewAPI(clusterCtl)
 		codeGitAPIV2           = codev2.NewAPI(codeGitCtl)
 		environmentAPIV2       = environmentv2.NewAPI(environmentCtl)
@@ -555,13 +634,17 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		regionAPIV2            = regionv2.NewAPI(regionCtl, tagCtl)
 		registryAPIV2          = registryv2.NewAPI(registryCtl)
 		roleAPIV2              = rolev2.NewAPI(roleCtl)
+		scheduledDeployAPIV2   = scheduledeployv2.NewAPI(scheduledDeployCtl)
+		releasePipelineAPIV2   = releasepipelinev2.NewAPI(releasePipelineCtl)
 		scopeAPIV2             = scopev2.NewAPI(scopeCtl)
 		tagAPIV2               = tagv2.NewAPI(tagCtl)
 		templateAPIV2          = templatev2.NewAPI(templateCtl, templateSchemaTagCtl)
 		templateSchemaTagAPIV2 = templateschematagv2.NewAPI(templateSchemaTagCtl)
 		terminalAPIV2          = terminalv2.NewAPI(terminalCtl)
 		userAPIV2              = userv2.NewAPI(userCtl, store)
+		userGroupAPIV2         = usergroupv2.NewAPI(userGroupCtl)
 		webhookAPIV2           = webhookv2.NewAPI(webhookCtl)
+		auditAPIV2             = auditv2.NewAPI(auditCtl)
 	)
 
 	// start jobs
@@ -574,16 +657,46 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	grafanaSyncJob := func(ctx context.Context) {
 		jobgrafanasync.Run(ctx, coreConfig, manager, client)
 	}
-	go jobs.Run(ctx, &coreConfig.JobConfig, autoFreeJob, webhookJob, grafanaSyncJob)
+	scheduledDeployJob := func(ctx context.Context) {
+		jobscheduledeploy.Run(ctx, &coreConfig.ScheduledDeployConfig, manager, rbacAuthorizer, clusterCtl)
+	}
+	retentionJob := func(ctx context.Context) {
+		jobretention.Run(ctx, &coreConfig.RetentionConfig, manager, parameter.TektonFty, coreConfig.TektonMapper)
+	}
+	memberExpiryJob := func(ctx context.Context) {
+		jobmemberexpiry.Run(ctx, &coreConfig.MemberExpiryConfig, manager)
+	}
+	jobList := []jobs.Job{autoFreeJob, webhookJob, grafanaSyncJob, scheduledDeployJob, retentionJob, memberExpiryJob}
+	if coreConfig.CanaryConfig.Enabled {
+		canaryJob := func(ctx context.Context) {
+			jobcanary.Run(ctx, &coreConfig.CanaryConfig, manager, clusterCtl)
+		}
+		jobList = append(jobList, canaryJob)
+	}
+	if coreConfig.DriftConfig.Enabled {
+		driftJob := func(ctx context.Context) {
+			jobdrift.Run(ctx, &coreConfig.DriftConfig, manager, parameter.CD, parameter.ClusterGitRepo)
+		}
+		jobList = append(jobList, driftJob)
+	}
+	go jobs.Run(ctx, &coreConfig.JobConfig, jobList...)
 
 	// init server
 	r := gin.New()
+	// gin trusts all proxies by default, so that client ip could be forged by X-Forwarded-For
+	if err := r.SetTrustedProxies(coreConfig.ServerConfig.TrustedProxies); err != nil {
+		panic(err)
+	}
 	// use middleware
 	middlewares := []gin.HandlerFunc{
 		ginlogmiddle.Middleware(gin.DefaultWriter, "/health", "/metrics"),
 		gin.Recovery(),
 		requestid.Middleware(), // requestID middleware, attach a requestID to context
 		logmiddle.Middleware(), // log middleware, attach a logger to context
+		// audit middleware, record mutating requests, including the rejected ones
+		auditmiddle.Middleware(manager.AuditLogMgr, coreConfig.AuditConfig,
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/health")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/metrics"))),
 
 		metricsmiddle.Middleware( // metrics middleware
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/health")),
@@ -650,7 +763,10 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		applicationAPIV2,
 		applicationRegionAPIV2,
 		buildSchemaAPI,
+		canaryAPIV2,
 		clusterAPIV2,
+		costAPIV2,
+		alertAPIV2,
 		codeGitAPIV2,
 		environmentAPIV2,
 		environmentRegionAPIV2,
@@ -663,13 +779,17 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		regionAPIV2,
 		registryAPIV2,
 		roleAPIV2,
+		scheduledDeployAPIV2,
+		releasePipelineAPIV2,
 		scopeAPIV2,
 		tagAPIV2,
 		templateAPIV2,
 		templateSchemaTagAPIV2,
 		terminalAPIV2,
 		userAPIV2,
+		userGroupAPIV2,
 		webhookAPIV2,
+		auditAPIV2,
 	}
 
 	// start cloud event server
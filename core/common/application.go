@@ -21,4 +21,7 @@ const (
 	ApplicationQueryByRelease        = "templateRelease"
 	ApplicationQueryByGroup          = "groupID"
 	ApplicationQueryByGroupRecursive = "groupRecursive"
+	// ApplicationQueryExcludeGroups and ApplicationQueryExcludeIDs are set internally to hide private applications
+	ApplicationQueryExcludeGroups = "excludeGroupIDs"
+	ApplicationQueryExcludeIDs    = "excludeIDs"
 )
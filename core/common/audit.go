@@ -0,0 +1,81 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	contextAuditBeforeKey = "auditBefore"
+	contextAuthMethodKey  = "authMethod"
+)
+
+const (
+	AuditLogQueryUserID       = "userID"
+	AuditLogQueryResource     = "resource"
+	AuditLogQueryResourceName = "resourceName"
+	AuditLogQueryStartTime    = "startTime"
+	AuditLogQueryEndTime      = "endTime"
+)
+
+// AuditBefore holds the json of the resource before it's updated,
+// the audit middleware compares it with the request body to get the diff
+type AuditBefore struct {
+	Value []byte
+}
+
+// WithAuditBefore attaches an empty AuditBefore to context, which is filled by controllers by SetAuditBefore
+func WithAuditBefore(c *gin.Context) *AuditBefore {
+	before := &AuditBefore{}
+	c.Set(contextAuditBeforeKey, before)
+	return before
+}
+
+// SetAuditBefore records the state of the resource before updating it, it's marshaled immediately,
+// so later changes to it are not recorded. It does nothing if the request doesn't need a diff.
+func SetAuditBefore(ctx context.Context, before interface{}) {
+	auditBefore, ok := ctx.Value(contextAuditBeforeKey).(*AuditBefore)
+	if !ok || auditBefore == nil {
+		return
+	}
+	content, err := json.Marshal(before)
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal the resource before updating for audit, err: %v", err)
+		return
+	}
+	auditBefore.Value = content
+}
+
+// AuditBeforeNeeded tells whether the state of the resource before updating it is needed,
+// controllers can skip reading it if not
+func AuditBeforeNeeded(ctx context.Context) bool {
+	auditBefore, ok := ctx.Value(contextAuditBeforeKey).(*AuditBefore)
+	return ok && auditBefore != nil
+}
+
+// SetAuthMethod records how the request is authenticated, it's set by the authenticator which authenticates it
+func SetAuthMethod(c *gin.Context, method string) {
+	c.Set(contextAuthMethodKey, method)
+}
+
+func AuthMethodFromContext(ctx context.Context) string {
+	method, _ := ctx.Value(contextAuthMethodKey).(string)
+	return method
+}
@@ -32,6 +32,11 @@ const (
 	ClusterQueryContainerName = "containerName"
 	ClusterQueryPodName       = "podName"
 	ClusterQueryTailLines     = "tailLines"
+	ClusterQuerySinceSeconds  = "sinceSeconds"
+	ClusterQuerySinceTime     = "sinceTime"
+	ClusterQueryPrevious      = "previous"
+	ClusterQueryFollow        = "follow"
+	ClusterQueryCronJob       = "cronJob"
 	ClusterQueryStart         = "start"
 	ClusterQueryEnd           = "end"
 	ClusterQueryExtraOwner    = "extraOwner"
@@ -46,11 +51,24 @@ const (
 	ClusterQueryByGVK = "gvk"
 
 	ClusterQueryResourceName = "resourceName"
+
+	ClusterQueryOlderThan = "olderThan"
+
+	ClusterQueryDryRun = "dryRun"
+
+	// ClusterQueryExcludeGroups and ClusterQueryExcludeApplications are set internally to hide private clusters
+	ClusterQueryExcludeGroups       = "excludeGroupIDs"
+	ClusterQueryExcludeApplications = "excludeApplicationIDs"
+	// ClusterQueryByGroups is set internally to list clusters of applications directly under the groups
+	ClusterQueryByGroups = "groupIDs"
 )
 
 const (
 	ClusterClusterLabelKey = "cloudnative.music.netease.com/cluster"
 	ClusterRestartTimeKey  = "cloudnative.music.netease.com/user-restart-time"
+	// ClusterReleaseBatchesKey is the annotation of statefulset, which specifies the number of batches
+	// to release the pods in by the partition of rolling update
+	ClusterReleaseBatchesKey = "cloudnative.music.netease.com/release-batches"
 )
 
 // status of cluster
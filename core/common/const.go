@@ -114,6 +114,7 @@ const (
 	ParamResourceType  = "resourceType"
 	ParamResourceID    = "resourceID"
 	ParamAccessTokenID = "accessTokenID"
+	ParamPipelinerunID = "pipelinerunID"
 )
 
 const (
@@ -26,6 +26,10 @@ const (
 	GitopsFilePipeline       = "pipeline/pipeline.yaml"
 	GitopsFilePipelineOutput = "pipeline/pipeline-output.yaml"
 	GitopsFileManifest       = "manifest.yaml"
+	// GitopsFileAlerts holds the PrometheusRule of cluster, it is loaded by GitopsFileAlertsTemplate
+	// with .Files.Get, so that the templates of prometheus such as {{ $value }} are not rendered by helm
+	GitopsFileAlerts         = "alerts/prometheusrule.yaml"
+	GitopsFileAlertsTemplate = "templates/horizon-alerts.yaml"
 
 	// value namespace
 	GitopsEnvValueNamespace  = "env"
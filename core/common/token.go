@@ -0,0 +1,37 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+)
+
+const contextTokenRestrictionsKey = "tokenRestrictions"
+
+// SetTokenRestrictions attaches restrictions of the access token of the request to context
+func SetTokenRestrictions(c *gin.Context, restrictions *tokenmodels.Restrictions) {
+	c.Set(contextTokenRestrictionsKey, restrictions)
+}
+
+// TokenRestrictionsFromContext returns restrictions of the access token,
+// and false if the request is not authenticated by access token
+func TokenRestrictionsFromContext(ctx context.Context) (*tokenmodels.Restrictions, bool) {
+	restrictions, ok := ctx.Value(contextTokenRestrictionsKey).(*tokenmodels.Restrictions)
+	return restrictions, ok && restrictions != nil
+}
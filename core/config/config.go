@@ -17,19 +17,28 @@ package config
 import (
 	"io/ioutil"
 	"strings"
+	"time"
 
+	"github.com/horizoncd/horizon/pkg/config/alert"
 	"github.com/horizoncd/horizon/pkg/config/argocd"
+	"github.com/horizoncd/horizon/pkg/config/audit"
 	"github.com/horizoncd/horizon/pkg/config/authenticate"
 	"github.com/horizoncd/horizon/pkg/config/autofree"
+	"github.com/horizoncd/horizon/pkg/config/canary"
+	"github.com/horizoncd/horizon/pkg/config/cost"
 	"github.com/horizoncd/horizon/pkg/config/db"
+	"github.com/horizoncd/horizon/pkg/config/drift"
 	"github.com/horizoncd/horizon/pkg/config/eventhandler"
 	"github.com/horizoncd/horizon/pkg/config/git"
 	"github.com/horizoncd/horizon/pkg/config/gitlab"
 	"github.com/horizoncd/horizon/pkg/config/grafana"
 	"github.com/horizoncd/horizon/pkg/config/job"
+	"github.com/horizoncd/horizon/pkg/config/memberexpiry"
 	"github.com/horizoncd/horizon/pkg/config/oauth"
 	"github.com/horizoncd/horizon/pkg/config/pprof"
 	"github.com/horizoncd/horizon/pkg/config/redis"
+	"github.com/horizoncd/horizon/pkg/config/retention"
+	"github.com/horizoncd/horizon/pkg/config/scheduledeploy"
 	"github.com/horizoncd/horizon/pkg/config/server"
 	"github.com/horizoncd/horizon/pkg/config/session"
 	"github.com/horizoncd/horizon/pkg/config/tekton"
@@ -53,6 +62,7 @@ type Config struct {
 	RedisConfig            redis.Redis             `yaml:"redisConfig"`
 	TektonMapper           tekton.Mapper           `yaml:"tektonMapper"`
 	TemplateRepo           templaterepo.Repo       `yaml:"templateRepo"`
+	TemplateSources        []*templaterepo.Source  `yaml:"templateSources"`
 	AccessSecretKeys       authenticate.KeysConfig `yaml:"accessSecretKeys"`
 	GrafanaConfig          grafana.Config          `yaml:"grafanaConfig"`
 	Oauth                  oauth.Server            `yaml:"oauth"`
@@ -63,6 +73,14 @@ type Config struct {
 	CodeGitRepos           []*git.Repo             `yaml:"gitRepos"`
 	TokenConfig            token.Config            `yaml:"tokenConfig"`
 	TemplateUpgradeMapper  template.UpgradeMapper  `yaml:"templateUpgradeMapper"`
+	CanaryConfig           canary.Config           `yaml:"canary"`
+	ScheduledDeployConfig  scheduledeploy.Config   `yaml:"scheduledDeploy"`
+	DriftConfig            drift.Config            `yaml:"drift"`
+	RetentionConfig        retention.Config        `yaml:"pipelinerunRetention"`
+	MemberExpiryConfig     memberexpiry.Config     `yaml:"memberExpiry"`
+	AuditConfig            audit.Config            `yaml:"audit"`
+	CostConfig             cost.Config             `yaml:"cost"`
+	AlertConfig            alert.Config            `yaml:"alert"`
 }
 
 func LoadConfig(configFilePath string) (*Config, error) {
@@ -103,6 +121,36 @@ func LoadConfig(configFilePath string) (*Config, error) {
 	if config.EventHandlerConfig.IdleWaitInterval <= 0 {
 		config.EventHandlerConfig.IdleWaitInterval = 3
 	}
+	if config.CanaryConfig.JobInterval <= 0 {
+		config.CanaryConfig.JobInterval = time.Minute
+	}
+	if config.ScheduledDeployConfig.JobInterval <= 0 {
+		config.ScheduledDeployConfig.JobInterval = time.Minute
+	}
+	if config.ScheduledDeployConfig.BatchSize <= 0 {
+		config.ScheduledDeployConfig.BatchSize = 20
+	}
+	if config.DriftConfig.JobInterval <= 0 {
+		config.DriftConfig.JobInterval = 5 * time.Minute
+	}
+	if config.DriftConfig.BatchSize <= 0 {
+		config.DriftConfig.BatchSize = 50
+	}
+	if config.RetentionConfig.JobInterval <= 0 {
+		config.RetentionConfig.JobInterval = time.Hour
+	}
+	if config.RetentionConfig.BatchSize <= 0 {
+		config.RetentionConfig.BatchSize = 50
+	}
+	if config.MemberExpiryConfig.JobInterval <= 0 {
+		config.MemberExpiryConfig.JobInterval = time.Minute
+	}
+	if config.MemberExpiryConfig.BatchSize <= 0 {
+		config.MemberExpiryConfig.BatchSize = 50
+	}
+	if config.AuditConfig.MaxBodySize <= 0 {
+		config.AuditConfig.MaxBodySize = 64 * 1024
+	}
 	if config.WebhookConfig.ClientTimeout <= 0 {
 		config.WebhookConfig.ClientTimeout = 30
 	}
@@ -115,6 +163,18 @@ func LoadConfig(configFilePath string) (*Config, error) {
 	if config.WebhookConfig.ResponseBodyTruncateSize <= 0 {
 		config.WebhookConfig.ResponseBodyTruncateSize = 16384
 	}
+	if config.WebhookConfig.MaxAttempts <= 0 {
+		config.WebhookConfig.MaxAttempts = 5
+	}
+	if config.WebhookConfig.RetryBackoffBase <= 0 {
+		config.WebhookConfig.RetryBackoffBase = 10
+	}
+	if config.WebhookConfig.RetryBackoffMax <= 0 {
+		config.WebhookConfig.RetryBackoffMax = 3600
+	}
+	if config.WebhookConfig.DisableThreshold <= 0 {
+		config.WebhookConfig.DisableThreshold = 50
+	}
 
 	return &config, nil
 }
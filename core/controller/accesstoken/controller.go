@@ -23,6 +23,7 @@ import (
 	"github.com/google/uuid"
 
 	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 
 	"github.com/horizoncd/horizon/core/common"
@@ -98,7 +99,7 @@ func (c *controller) CreateResourceAccessToken(ctx context.Context, request Crea
 	userID = robot.ID
 
 	token, err := c.tokenSvc.CreateAccessToken(ctx, request.Name,
-		request.ExpiresAt, userID, request.Scopes)
+		request.ExpiresAt, userID, request.Scopes, request.Restrictions)
 	if err != nil {
 		return nil, err
 	}
@@ -112,9 +113,10 @@ func (c *controller) CreateResourceAccessToken(ctx context.Context, request Crea
 		ResourceAccessToken: ResourceAccessToken{
 			CreateResourceAccessTokenRequest: CreateResourceAccessTokenRequest{
 				CreatePersonalAccessTokenRequest: CreatePersonalAccessTokenRequest{
-					Name:      token.Name,
-					Scopes:    request.Scopes,
-					ExpiresAt: parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+					Name:         token.Name,
+					Scopes:       request.Scopes,
+					ExpiresAt:    parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+					Restrictions: restrictionsOf(token),
 				},
 				Role: request.Role,
 			},
@@ -140,7 +142,7 @@ func (c *controller) CreatePersonalAccessToken(ctx context.Context,
 	}
 
 	token, err := c.tokenSvc.CreateAccessToken(ctx, request.Name, request.ExpiresAt,
-		currentUser.GetID(), request.Scopes)
+		currentUser.GetID(), request.Scopes, request.Restrictions)
 	if err != nil {
 		return nil, err
 	}
@@ -148,9 +150,10 @@ func (c *controller) CreatePersonalAccessToken(ctx context.Context,
 	resp := &CreatePersonalAccessTokenResponse{
 		PersonalAccessToken: PersonalAccessToken{
 			CreatePersonalAccessTokenRequest: CreatePersonalAccessTokenRequest{
-				Name:      token.Name,
-				Scopes:    request.Scopes,
-				ExpiresAt: parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+				Name:         token.Name,
+				Scopes:       request.Scopes,
+				ExpiresAt:    parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+				Restrictions: restrictionsOf(token),
 			},
 			CreatedAt: token.CreatedAt,
 			CreatedBy: &usermodels.UserBasic{
@@ -184,9 +187,10 @@ func (c *controller) ListPersonalAccessTokens(ctx context.Context,
 		}
 		accessTokens = append(accessTokens, PersonalAccessToken{
 			CreatePersonalAccessTokenRequest: CreatePersonalAccessTokenRequest{
-				Name:      token.Name,
-				Scopes:    strings.Split(token.Scope, " "),
-				ExpiresAt: parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+				Name:         token.Name,
+				Scopes:       strings.Split(token.Scope, " "),
+				ExpiresAt:    parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+				Restrictions: restrictionsOf(&token.Token),
 			},
 			CreatedAt: token.CreatedAt,
 			CreatedBy: &usermodels.UserBasic{
@@ -194,7 +198,9 @@ func (c *controller) ListPersonalAccessTokens(ctx context.Context,
 				Name:  creator.Name,
 				Email: creator.Email,
 			},
-			ID: token.ID,
+			ID:         token.ID,
+			LastUsedAt: token.LastUsedAt,
+			LastUsedIP: token.LastUsedIP,
 		})
 	}
 
@@ -219,9 +225,10 @@ func (c *controller) ListResourceAccessTokens(ctx context.Context, resourceType
 		accessTokens = append(accessTokens, ResourceAccessToken{
 			CreateResourceAccessTokenRequest: CreateResourceAccessTokenRequest{
 				CreatePersonalAccessTokenRequest: CreatePersonalAccessTokenRequest{
-					Name:      token.Name,
-					Scopes:    strings.Split(token.Scope, " "),
-					ExpiresAt: parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+					Name:         token.Name,
+					Scopes:       strings.Split(token.Scope, " "),
+					ExpiresAt:    parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+					Restrictions: restrictionsOf(&token.Token),
 				},
 				Role: token.Role,
 			},
@@ -231,7 +238,9 @@ func (c *controller) ListResourceAccessTokens(ctx context.Context, resourceType
 				Name:  creator.Name,
 				Email: creator.Email,
 			},
-			ID: token.ID,
+			ID:         token.ID,
+			LastUsedAt: token.LastUsedAt,
+			LastUsedIP: token.LastUsedIP,
 		})
 	}
 
@@ -333,6 +342,13 @@ func generateRobot(token, resourceType string, resourceID uint) *usermodels.User
 	}
 }
 
+func restrictionsOf(token *tokenmodels.Token) *tokenmodels.Restrictions {
+	if token.Resources == "" && token.Verbs == "" && token.AllowedCIDRs == "" {
+		return nil
+	}
+	return token.GetRestrictions()
+}
+
 func parseExpiredAt(startTime time.Time, expiresIn time.Duration) string {
 	expiredAt := NeverExpire
 	if expiresIn > 0 {
@@ -17,6 +17,7 @@ package accesstoken
 import (
 	"time"
 
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 )
 
@@ -30,6 +31,8 @@ type CreatePersonalAccessTokenRequest struct {
 	Name      string   `json:"name"`
 	Scopes    []string `json:"scopes"`
 	ExpiresAt string   `json:"expiresAt"`
+	// Restrictions restricts the token to resources, verbs and source addresses, e.g. for CI robots
+	Restrictions *tokenmodels.Restrictions `json:"restrictions,omitempty"`
 }
 
 type CreateResourceAccessTokenRequest struct {
@@ -39,16 +42,20 @@ type CreateResourceAccessTokenRequest struct {
 
 type PersonalAccessToken struct {
 	CreatePersonalAccessTokenRequest
-	ID        uint                  `json:"id"`
-	CreatedAt time.Time             `json:"createdAt"`
-	CreatedBy *usermodels.UserBasic `json:"createdBy"`
+	ID         uint                  `json:"id"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	CreatedBy  *usermodels.UserBasic `json:"createdBy"`
+	LastUsedAt *time.Time            `json:"lastUsedAt"`
+	LastUsedIP string                `json:"lastUsedIP"`
 }
 
 type ResourceAccessToken struct {
 	CreateResourceAccessTokenRequest
-	ID        uint                  `json:"id"`
-	CreatedAt time.Time             `json:"createdAt"`
-	CreatedBy *usermodels.UserBasic `json:"createdBy"`
+	ID         uint                  `json:"id"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	CreatedBy  *usermodels.UserBasic `json:"createdBy"`
+	LastUsedAt *time.Time            `json:"lastUsedAt"`
+	LastUsedIP string                `json:"lastUsedIP"`
 }
 
 type CreatePersonalAccessTokenResponse struct {
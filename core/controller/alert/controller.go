@@ -0,0 +1,164 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alert
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	alertmanager "github.com/horizoncd/horizon/pkg/alert/manager"
+	"github.com/horizoncd/horizon/pkg/alert/manifest"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	alertconfig "github.com/horizoncd/horizon/pkg/config/alert"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// ListRules lists alert rules of cluster
+	ListRules(ctx context.Context, clusterID uint) (*ListRulesResponse, error)
+	// UpdateRules replaces alert rules of cluster, the rules are rendered into a PrometheusRule
+	// and committed to the gitops repo of cluster, which takes effect after the next deploy
+	UpdateRules(ctx context.Context, clusterID uint, r *UpdateRulesRequest) error
+	// Notify receives the notification from alertmanager, and creates events of the alerts,
+	// which are sent to the webhooks of clusters
+	Notify(ctx context.Context, token string, r *Notification) error
+}
+
+type controller struct {
+	config         *alertconfig.Config
+	clusterMgr     clustermanager.Manager
+	applicationMgr applicationmanager.Manager
+	eventMgr       eventmanager.Manager
+	alertRuleMgr   alertmanager.Manager
+	clusterGitRepo clustergitrepo.ClusterGitRepo
+}
+
+func NewController(config *alertconfig.Config, param *param.Param) Controller {
+	return &controller{
+		config:         config,
+		clusterMgr:     param.ClusterMgr,
+		applicationMgr: param.ApplicationManager,
+		eventMgr:       param.EventManager,
+		alertRuleMgr:   param.AlertRuleMgr,
+		clusterGitRepo: param.ClusterGitRepo,
+	}
+}
+
+func (c *controller) ListRules(ctx context.Context, clusterID uint) (*ListRulesResponse, error) {
+	const op = "alert controller: list rules"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	rules, err := c.alertRuleMgr.ListByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return ofRules(rules), nil
+}
+
+func (c *controller) UpdateRules(ctx context.Context, clusterID uint, r *UpdateRulesRequest) error {
+	const op = "alert controller: update rules"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+
+	rules := r.toRules(clusterID)
+	if err := alertmanager.ValidateUpdate(rules); err != nil {
+		return err
+	}
+	manifests, err := manifest.Render(c.config, rules, &manifest.QueryVars{
+		Application: application.Name,
+		Cluster:     cluster.Name,
+		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
+	})
+	if err != nil {
+		return err
+	}
+
+	// rules are saved before committed to the gitops repo, and restored if the commit fails
+	oldRules, err := c.alertRuleMgr.ListByClusterID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if err := c.alertRuleMgr.UpdateByClusterID(ctx, clusterID, rules); err != nil {
+		return err
+	}
+	if err := c.clusterGitRepo.UpdateAlertRules(ctx, application.Name, cluster.Name, manifests); err != nil {
+		if restoreErr := c.alertRuleMgr.UpdateByClusterID(ctx, clusterID, oldRules); restoreErr != nil {
+			log.Errorf(ctx, "failed to restore alert rules of cluster %d, err: %v", clusterID, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *controller) Notify(ctx context.Context, token string, r *Notification) error {
+	const op = "alert controller: notify"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if c.config.Receiver.Token == "" ||
+		subtle.ConstantTimeCompare([]byte(token), []byte(c.config.Receiver.Token)) != 1 {
+		return perror.Wrap(herrors.ErrTokenInvalid, "token of alert receiver is invalid")
+	}
+
+	for _, alert := range r.Alerts {
+		clusterName := alert.Labels[manifest.LabelCluster]
+		if clusterName == "" {
+			continue
+		}
+		cluster, err := c.clusterMgr.GetByName(ctx, clusterName)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				log.Warningf(ctx, "cluster %s of alert %s is not found", clusterName, alert.Labels[_labelAlertName])
+				continue
+			}
+			return err
+		}
+		extra, err := json.Marshal(ofNotifiedAlert(alert))
+		if err != nil {
+			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		extraStr := string(extra)
+		if _, err := c.eventMgr.CreateEvent(ctx, &eventmodels.Event{
+			EventSummary: eventmodels.EventSummary{
+				ResourceType: common.ResourceCluster,
+				EventType:    eventmodels.ClusterAlerted,
+				ResourceID:   cluster.ID,
+				Extra:        &extraStr,
+			},
+			CreatedBy: c.config.Receiver.AccountID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alert
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	mockgitrepo "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	alertmodels "github.com/horizoncd/horizon/pkg/alert/models"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	alertconfig "github.com/horizoncd/horizon/pkg/config/alert"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	ctx     = context.Background()
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&clustermodels.Cluster{}, &appmodels.Application{}, &groupmodels.Group{},
+		&membermodels.Member{}, &usermodels.User{}, &tagmodels.Tag{},
+		&eventmodels.Event{}, &alertmodels.AlertRule{}); err != nil {
+		panic(err)
+	}
+	// nolint
+	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "horizon",
+		ID:   uint(1),
+	})
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := mockgitrepo.NewMockClusterGitRepo(mockCtl)
+
+	app, err := manager.ApplicationManager.Create(ctx, &appmodels.Application{
+		Name: "alert-app",
+	}, nil)
+	assert.Nil(t, err)
+	cluster, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+		ApplicationID:   app.ID,
+		Name:            "alert-cluster",
+		EnvironmentName: "online",
+		RegionName:      "hz",
+	}, nil, nil)
+	assert.Nil(t, err)
+	ctl := NewController(&alertconfig.Config{
+		Receiver: alertconfig.Receiver{
+			URL:             "https://horizon/apis/internal/v2/alerts",
+			Token:           "token",
+			TokenSecretName: "horizon-alert",
+			TokenSecretKey:  "token",
+			AccountID:       2,
+		},
+	}, &param.Param{
+		Manager:        manager,
+		ClusterGitRepo: clusterGitRepo,
+	})
+
+	var manifests string
+	updateAlertRules := func(_ context.Context, _, _ string, content []byte) error {
+		manifests = string(content)
+		return nil
+	}
+	clusterGitRepo.EXPECT().UpdateAlertRules(gomock.Any(), app.Name, cluster.Name, gomock.Any()).
+		DoAndReturn(updateAlertRules)
+
+	err = ctl.UpdateRules(ctx, cluster.ID, &UpdateRulesRequest{
+		Rules: []*Rule{
+			{
+				Name:      "restarts",
+				Type:      alertmodels.RuleTypeRestarts,
+				Operator:  alertmodels.OperatorGreaterThan,
+				Threshold: 3,
+			},
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(manifests, "kind: PrometheusRule"))
+	assert.True(t, strings.Contains(manifests, "kind: AlertmanagerConfig"))
+	assert.True(t, strings.Contains(manifests, "https://horizon/apis/internal/v2/alerts"))
+	assert.True(t, strings.Contains(manifests, "horizon-alert"))
+
+	resp, err := ctl.ListRules(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.Rules))
+	assert.Equal(t, "5m", resp.Rules[0].Duration)
+	assert.Equal(t, alertmodels.SeverityWarning, resp.Rules[0].Severity)
+
+	// invalid rules are neither committed nor saved
+	err = ctl.UpdateRules(ctx, cluster.ID, &UpdateRulesRequest{
+		Rules: []*Rule{{Name: "custom", Type: alertmodels.RuleTypeQuery, Operator: ">"}},
+	})
+	assert.NotNil(t, err)
+
+	// rules are restored if failed to commit to the gitops repo
+	clusterGitRepo.EXPECT().UpdateAlertRules(gomock.Any(), app.Name, cluster.Name, gomock.Any()).
+		Return(errors.New("gitops error"))
+	err = ctl.UpdateRules(ctx, cluster.ID, &UpdateRulesRequest{
+		Rules: []*Rule{{Name: "restarts", Type: alertmodels.RuleTypeRestarts, Operator: ">", Threshold: 5}},
+	})
+	assert.NotNil(t, err)
+	resp, err = ctl.ListRules(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.Rules))
+	assert.Equal(t, float64(3), resp.Rules[0].Threshold)
+
+	// notifications of alerts are turned into events of clusters
+	notification := &Notification{
+		Status: "firing",
+		Alerts: []*NotifiedAlert{
+			{
+				Status: "firing",
+				Labels: map[string]string{
+					"alertname": "restarts", "severity": alertmodels.SeverityWarning,
+					"horizon_cluster": cluster.Name,
+				},
+				Annotations: map[string]string{"summary": "restarts of cluster alert-cluster is 4"},
+				StartsAt:    time.Now(),
+			}, {
+				Status: "firing",
+				Labels: map[string]string{"alertname": "restarts", "horizon_cluster": "not-exists"},
+			},
+		},
+	}
+	err = ctl.Notify(ctx, "wrong", notification)
+	assert.Equal(t, herrors.ErrTokenInvalid, perror.Cause(err))
+	err = ctl.Notify(ctx, "token", notification)
+	assert.Nil(t, err)
+	events, err := manager.EventManager.ListEvents(ctx, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, eventmodels.ClusterAlerted, events[0].EventType)
+	assert.Equal(t, cluster.ID, events[0].ResourceID)
+	assert.Equal(t, uint(2), events[0].CreatedBy)
+	assert.Contains(t, *events[0].Extra, "restarts of cluster alert-cluster is 4")
+
+	clusterGitRepo.EXPECT().UpdateAlertRules(gomock.Any(), app.Name, cluster.Name, gomock.Any()).
+		DoAndReturn(updateAlertRules)
+	err = ctl.UpdateRules(ctx, cluster.ID, &UpdateRulesRequest{})
+	assert.Nil(t, err)
+	assert.Empty(t, manifests)
+	resp, err = ctl.ListRules(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(resp.Rules))
+}
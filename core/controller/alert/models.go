@@ -0,0 +1,129 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alert
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/alert/manifest"
+	alertmodels "github.com/horizoncd/horizon/pkg/alert/models"
+)
+
+const (
+	_defaultDuration = "5m"
+	_defaultSeverity = alertmodels.SeverityWarning
+
+	_labelAlertName    = "alertname"
+	_annotationSummary = "summary"
+)
+
+type ListRulesResponse struct {
+	Rules []*Rule `json:"rules"`
+}
+
+type Rule struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Query       string  `json:"query,omitempty"`
+	Operator    string  `json:"operator"`
+	Threshold   float64 `json:"threshold"`
+	Duration    string  `json:"duration"`
+	Severity    string  `json:"severity"`
+	Description string  `json:"description,omitempty"`
+}
+
+type UpdateRulesRequest struct {
+	Rules []*Rule `json:"rules"`
+}
+
+func (r *UpdateRulesRequest) toRules(clusterID uint) []*alertmodels.AlertRule {
+	rules := make([]*alertmodels.AlertRule, 0, len(r.Rules))
+	for _, rule := range r.Rules {
+		duration, severity := rule.Duration, rule.Severity
+		if duration == "" {
+			duration = _defaultDuration
+		}
+		if severity == "" {
+			severity = _defaultSeverity
+		}
+		rules = append(rules, &alertmodels.AlertRule{
+			ClusterID:   clusterID,
+			Name:        rule.Name,
+			Type:        rule.Type,
+			Query:       rule.Query,
+			Operator:    rule.Operator,
+			Threshold:   rule.Threshold,
+			Duration:    duration,
+			Severity:    severity,
+			Description: rule.Description,
+		})
+	}
+	return rules
+}
+
+func ofRules(rules []*alertmodels.AlertRule) *ListRulesResponse {
+	resp := &ListRulesResponse{
+		Rules: make([]*Rule, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		resp.Rules = append(resp.Rules, &Rule{
+			Name:        rule.Name,
+			Type:        rule.Type,
+			Query:       rule.Query,
+			Operator:    rule.Operator,
+			Threshold:   rule.Threshold,
+			Duration:    rule.Duration,
+			Severity:    rule.Severity,
+			Description: rule.Description,
+		})
+	}
+	return resp
+}
+
+// Notification is the notification sent by the webhook receiver of alertmanager
+type Notification struct {
+	Status string           `json:"status"`
+	Alerts []*NotifiedAlert `json:"alerts"`
+}
+
+type NotifiedAlert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+// AlertEvent is the extra of the events of alerts
+type AlertEvent struct {
+	Alert    string    `json:"alert"`
+	Status   string    `json:"status"`
+	Severity string    `json:"severity"`
+	Summary  string    `json:"summary"`
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+}
+
+func ofNotifiedAlert(alert *NotifiedAlert) *AlertEvent {
+	return &AlertEvent{
+		Alert:    alert.Labels[_labelAlertName],
+		Status:   alert.Status,
+		Severity: alert.Labels[manifest.LabelSeverity],
+		Summary:  alert.Annotations[_annotationSummary],
+		StartsAt: alert.StartsAt,
+		EndsAt:   alert.EndsAt,
+	}
+}
@@ -50,6 +50,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/permission"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"github.com/horizoncd/horizon/pkg/visibility"
 )
 
 type Controller interface {
@@ -96,6 +97,7 @@ type controller struct {
 	applicationRegionMgr applicationregionmanager.Manager
 	pipelinemanager      pipelinemanager.Manager
 	buildSchema          *build.Schema
+	visibilityChecker    visibility.Checker
 }
 
 var _ Controller = (*controller)(nil)
@@ -116,6 +118,7 @@ func NewController(param *param.Param) Controller {
 		applicationRegionMgr: param.ApplicationRegionManager,
 		pipelinemanager:      param.PipelineMgr,
 		buildSchema:          param.BuildSchema,
+		visibilityChecker:    param.VisibilityChecker,
 	}
 }
 
@@ -175,10 +178,11 @@ func (c *controller) GetApplicationV2(ctx context.Context, id uint) (_ *GetAppli
 	fullPath := fmt.Sprintf("%v/%v", group.FullPath, app.Name)
 
 	resp := &GetApplicationResponseV2{
-		ID:          id,
-		Name:        app.Name,
-		Description: app.Description,
-		Priority:    string(app.Priority),
+		ID:              id,
+		Name:            app.Name,
+		Description:     app.Description,
+		Priority:        string(app.Priority),
+		VisibilityLevel: app.VisibilityLevel,
 		Git: func() *codemodels.Git {
 			if app.GitURL == "" {
 				return nil
@@ -267,6 +271,9 @@ func (c *controller) CreateApplication(ctx context.Context, groupID uint,
 	if err != nil {
 		return nil, err
 	}
+	if applicationModel.VisibilityLevel == visibility.Private {
+		c.visibilityChecker.Invalidate()
+	}
 
 	// 5. get fullPath
 	fullPath := fmt.Sprintf("%v/%v", group.FullPath, applicationModel.Name)
@@ -327,6 +334,11 @@ func (c *controller) CreateApplicationV2(ctx context.Context, groupID uint,
 			return nil, err
 		}
 	}
+	if request.VisibilityLevel != nil {
+		if err := visibility.Validate(*request.VisibilityLevel); err != nil {
+			return nil, err
+		}
+	}
 	if request.Git != nil {
 		if err := validateGitURL(request.Git.URL); err != nil {
 			return nil, err
@@ -372,6 +384,9 @@ func (c *controller) CreateApplicationV2(ctx context.Context, groupID uint,
 	if err != nil {
 		return nil, err
 	}
+	if applicationDBModel.VisibilityLevel == visibility.Private {
+		c.visibilityChecker.Invalidate()
+	}
 
 	fullPath, err := func() (string, error) {
 		group, err := c.groupSvc.GetChildByID(ctx, groupID)
@@ -456,6 +471,9 @@ func (c *controller) UpdateApplication(ctx context.Context, id uint,
 	if err != nil {
 		return nil, err
 	}
+	if applicationModel.VisibilityLevel != appExistsInDB.VisibilityLevel {
+		c.visibilityChecker.Invalidate()
+	}
 
 	// 5. record event
 	if _, err := c.eventMgr.CreateEvent(ctx, &eventmodels.Event{
@@ -495,12 +513,20 @@ func (c *controller) UpdateApplicationV2(ctx context.Context, id uint,
 	if err != nil {
 		return err
 	}
+	if common.AuditBeforeNeeded(ctx) {
+		common.SetAuditBefore(ctx, c.applicationBeforeUpdate(ctx, appExistsInDB, request))
+	}
 
 	if request.Priority != nil {
 		if err := validatePriority(*request.Priority); err != nil {
 			return err
 		}
 	}
+	if request.VisibilityLevel != nil {
+		if err := visibility.Validate(*request.VisibilityLevel); err != nil {
+			return err
+		}
+	}
 	if request.Git != nil {
 		if err := validateGitURL(request.Git.URL); err != nil {
 			return err
@@ -525,7 +551,42 @@ func (c *controller) UpdateApplicationV2(ctx context.Context, id uint,
 	// 4. update application in db
 	applicationModel := request.UpdateToApplicationModel(appExistsInDB)
 	_, err = c.applicationMgr.UpdateByID(ctx, id, applicationModel)
-	return err
+	if err != nil {
+		return err
+	}
+	if applicationModel.VisibilityLevel != appExistsInDB.VisibilityLevel {
+		c.visibilityChecker.Invalidate()
+	}
+	return nil
+}
+
+// applicationBeforeUpdate returns the application before updating it for audit,
+// configs in git repo are read only if they are updated
+func (c *controller) applicationBeforeUpdate(ctx context.Context, app *models.Application,
+	request *CreateOrUpdateApplicationRequestV2) *GetApplicationResponseV2 {
+	before := &GetApplicationResponseV2{
+		ID:              app.ID,
+		Name:            app.Name,
+		Description:     app.Description,
+		Priority:        string(app.Priority),
+		VisibilityLevel: app.VisibilityLevel,
+	}
+	if app.GitURL != "" {
+		before.Git = codemodels.NewGit(app.GitURL, app.GitSubfolder, app.GitRefType, app.GitRef)
+	}
+	if app.Template != "" {
+		before.TemplateInfo = &codemodels.TemplateInfo{Name: app.Template, Release: app.TemplateRelease}
+	}
+	if request.BuildConfig != nil || request.TemplateConfig != nil {
+		applicationRepo, err := c.applicationGitRepo.GetApplication(ctx, app.Name, common.ApplicationRepoDefaultEnv)
+		if err != nil {
+			log.Warningf(ctx, "failed to get application %s for audit, err: %v", app.Name, err)
+		} else {
+			before.BuildConfig = applicationRepo.BuildConf
+			before.TemplateConfig = applicationRepo.TemplateConf
+		}
+	}
+	return before
 }
 
 func (c *controller) DeleteApplication(ctx context.Context, id uint, hard bool) (err error) {
@@ -599,13 +660,20 @@ func (c *controller) Transfer(ctx context.Context, id uint, groupID uint) error
 		return err
 	}
 
-	return c.applicationMgr.Transfer(ctx, id, group.ID)
+	if err := c.applicationMgr.Transfer(ctx, id, group.ID); err != nil {
+		return err
+	}
+	c.visibilityChecker.Invalidate()
+	return nil
 }
 
 func (c *controller) validateCreate(b Base) error {
 	if err := validatePriority(b.Priority); err != nil {
 		return err
 	}
+	if err := visibility.Validate(b.VisibilityLevel); err != nil {
+		return err
+	}
 	if b.Template == nil {
 		return perror.Wrap(herrors.ErrParamInvalid, "template cannot be empty")
 	}
@@ -618,6 +686,9 @@ func (c *controller) validateUpdate(b Base) error {
 			return err
 		}
 	}
+	if err := visibility.Validate(b.VisibilityLevel); err != nil {
+		return err
+	}
 	return validateGit(b)
 }
 
@@ -746,6 +817,13 @@ func (c *controller) List(ctx context.Context, query *q.Query) (
 		}
 	}
 
+	// private applications are invisible to non members, except for the applications of a user
+	if _, ok := query.Keywords[common.ApplicationQueryByUser]; !ok {
+		if err := c.excludeInvisible(ctx, query); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	listApplicationResp = []*ListApplicationResponse{}
 	// 1. get application in db
 	count, applications, err := c.applicationMgr.List(ctx, subGroupIDs, query)
@@ -788,6 +866,28 @@ func (c *controller) List(ctx context.Context, query *q.Query) (
 	return listApplicationResp, count, nil
 }
 
+// excludeInvisible excludes the private groups and applications which are invisible to the current user
+func (c *controller) excludeInvisible(ctx context.Context, query *q.Query) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	invisible, err := c.visibilityChecker.ListInvisible(ctx, currentUser)
+	if err != nil {
+		return err
+	}
+	if query.Keywords == nil {
+		query.Keywords = q.KeyWords{}
+	}
+	if len(invisible.Groups) > 0 {
+		query.Keywords[common.ApplicationQueryExcludeGroups] = invisible.GroupIDs()
+	}
+	if len(invisible.Applications) > 0 {
+		query.Keywords[common.ApplicationQueryExcludeIDs] = invisible.ApplicationIDs()
+	}
+	return nil
+}
+
 func (c *controller) GetSelectableRegionsByEnv(ctx context.Context, id uint, env string) (
 	regionmodels.RegionParts, error) {
 	application, err := c.applicationMgr.GetByID(ctx, id)
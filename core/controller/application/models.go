@@ -20,15 +20,17 @@ import (
 	"github.com/horizoncd/horizon/pkg/application/models"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/visibility"
 )
 
 // Base holds the parameters which can be updated of an application
 type Base struct {
-	Description   string          `json:"description"`
-	Priority      string          `json:"priority"`
-	Template      *Template       `json:"template"`
-	Git           *codemodels.Git `json:"git"`
-	TemplateInput *TemplateInput  `json:"templateInput"`
+	Description     string          `json:"description"`
+	Priority        string          `json:"priority"`
+	VisibilityLevel string          `json:"visibilityLevel"`
+	Template        *Template       `json:"template"`
+	Git             *codemodels.Git `json:"git"`
+	TemplateInput   *TemplateInput  `json:"templateInput"`
 }
 
 type TemplateInput struct {
@@ -82,6 +84,7 @@ func (m *CreateApplicationRequest) toApplicationModel(groupID uint) *models.Appl
 		Name:            m.Name,
 		Description:     m.Description,
 		Priority:        models.Priority(m.Priority),
+		VisibilityLevel: visibility.LevelOrDefault(m.VisibilityLevel),
 		GitURL:          m.Git.URL,
 		GitSubfolder:    m.Git.Subfolder,
 		GitRefType:      m.Git.RefType(),
@@ -96,6 +99,7 @@ func (m *UpdateApplicationRequest) toApplicationModel(appExistsInDB *models.Appl
 	application := &models.Application{
 		Description:     appExistsInDB.Description,
 		Priority:        appExistsInDB.Priority,
+		VisibilityLevel: appExistsInDB.VisibilityLevel,
 		GitURL:          appExistsInDB.GitURL,
 		GitSubfolder:    appExistsInDB.GitSubfolder,
 		GitRef:          appExistsInDB.GitRef,
@@ -107,6 +111,9 @@ func (m *UpdateApplicationRequest) toApplicationModel(appExistsInDB *models.Appl
 	if m.Priority != "" {
 		application.Priority = models.Priority(m.Priority)
 	}
+	if m.VisibilityLevel != "" {
+		application.VisibilityLevel = m.VisibilityLevel
+	}
 	if m.Git != nil {
 		if m.Git.URL != "" {
 			application.GitURL = m.Git.URL
@@ -140,8 +147,9 @@ func ofApplicationModel(app *models.Application, fullPath string, trs []*trmodel
 	resp := &GetApplicationResponse{
 		CreateApplicationRequest: CreateApplicationRequest{
 			Base: Base{
-				Description: app.Description,
-				Priority:    string(app.Priority),
+				Description:     app.Description,
+				Priority:        string(app.Priority),
+				VisibilityLevel: app.VisibilityLevel,
 				Template: &Template{
 					Name:               app.Template,
 					Release:            app.TemplateRelease,
@@ -19,14 +19,16 @@ import (
 
 	"github.com/horizoncd/horizon/pkg/application/models"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/visibility"
 )
 
 type GetApplicationResponseV2 struct {
-	ID          uint            `json:"id"`
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	Priority    string          `json:"priority"`
-	Git         *codemodels.Git `json:"git"`
+	ID              uint            `json:"id"`
+	Name            string          `json:"name"`
+	Description     string          `json:"description"`
+	Priority        string          `json:"priority"`
+	VisibilityLevel string          `json:"visibilityLevel"`
+	Git             *codemodels.Git `json:"git"`
 
 	BuildConfig    map[string]interface{}   `json:"buildConfig"`
 	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
@@ -42,13 +44,14 @@ type GetApplicationResponseV2 struct {
 
 // CreateOrUpdateApplicationRequestV2 holds the parameters required to create an application
 type CreateOrUpdateApplicationRequestV2 struct {
-	Name           string                   `json:"name"`
-	Description    string                   `json:"description"`
-	Priority       *string                  `json:"priority"`
-	Git            *codemodels.Git          `json:"git"`
-	BuildConfig    map[string]interface{}   `json:"buildConfig"`
-	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
-	TemplateConfig map[string]interface{}   `json:"templateConfig"`
+	Name            string                   `json:"name"`
+	Description     string                   `json:"description"`
+	Priority        *string                  `json:"priority"`
+	VisibilityLevel *string                  `json:"visibilityLevel"`
+	Git             *codemodels.Git          `json:"git"`
+	BuildConfig     map[string]interface{}   `json:"buildConfig"`
+	TemplateInfo    *codemodels.TemplateInfo `json:"templateInfo"`
+	TemplateConfig  map[string]interface{}   `json:"templateConfig"`
 
 	// TODO(remove it): only for internal usage
 	ExtraMembers map[string]string `json:"extraMembers"`
@@ -65,6 +68,12 @@ func (req *CreateOrUpdateApplicationRequestV2) CreateToApplicationModel(groupID
 			}
 			return ""
 		}(),
+		VisibilityLevel: func() string {
+			if req.VisibilityLevel != nil {
+				return visibility.LevelOrDefault(*req.VisibilityLevel)
+			}
+			return visibility.Public
+		}(),
 		GitURL: func() string {
 			if req.Git != nil {
 				return req.Git.URL
@@ -109,6 +118,7 @@ func (req *CreateOrUpdateApplicationRequestV2) UpdateToApplicationModel(
 	application := &models.Application{
 		Description:     appExistsInDB.Description,
 		Priority:        appExistsInDB.Priority,
+		VisibilityLevel: appExistsInDB.VisibilityLevel,
 		GitURL:          appExistsInDB.GitURL,
 		GitSubfolder:    appExistsInDB.GitSubfolder,
 		GitRef:          appExistsInDB.GitRef,
@@ -120,6 +130,9 @@ func (req *CreateOrUpdateApplicationRequestV2) UpdateToApplicationModel(
 	if req.Priority != nil {
 		application.Priority = models.Priority(*req.Priority)
 	}
+	if req.VisibilityLevel != nil {
+		application.VisibilityLevel = visibility.LevelOrDefault(*req.VisibilityLevel)
+	}
 	if req.Git != nil {
 		application.GitURL = req.Git.URL
 		application.GitRefType = req.Git.RefType()
@@ -0,0 +1,106 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	auditmanager "github.com/horizoncd/horizon/pkg/audit/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _exportBatchSize = 500
+
+type Controller interface {
+	// List lists audit logs matching the query, only admin is allowed
+	List(ctx context.Context, query *q.Query) ([]*AuditLog, int64, error)
+	// Export writes all audit logs matching the query to w as json lines, only admin is allowed
+	Export(ctx context.Context, query *q.Query, w io.Writer) error
+}
+
+type controller struct {
+	auditLogMgr auditmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		auditLogMgr: param.AuditLogMgr,
+	}
+}
+
+func (c *controller) List(ctx context.Context, query *q.Query) ([]*AuditLog, int64, error) {
+	const op = "audit controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, 0, err
+	}
+	auditLogs, total, err := c.auditLogMgr.List(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp := make([]*AuditLog, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		resp = append(resp, ofAuditLog(auditLog))
+	}
+	return resp, total, nil
+}
+
+func (c *controller) Export(ctx context.Context, query *q.Query, w io.Writer) error {
+	const op = "audit controller: export"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	var afterID uint
+	for {
+		auditLogs, err := c.auditLogMgr.ListAfterID(ctx, query, afterID, _exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, auditLog := range auditLogs {
+			if err := encoder.Encode(ofAuditLog(auditLog)); err != nil {
+				return perror.Wrapf(herrors.ErrWriteFailed, "failed to write audit log: %v", err)
+			}
+			afterID = auditLog.ID
+		}
+		if len(auditLogs) < _exportBatchSize {
+			return nil
+		}
+	}
+}
+
+func checkAdmin(ctx context.Context) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !currentUser.IsAdmin() {
+		return perror.Wrap(herrors.ErrForbidden, "only admin can access audit logs")
+	}
+	return nil
+}
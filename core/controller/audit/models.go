@@ -0,0 +1,59 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/audit/models"
+)
+
+type AuditLog struct {
+	ID           uint      `json:"id"`
+	RequestID    string    `json:"requestID"`
+	UserID       uint      `json:"userID"`
+	UserName     string    `json:"userName"`
+	AuthMethod   string    `json:"authMethod"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Verb         string    `json:"verb"`
+	Resource     string    `json:"resource"`
+	ResourceName string    `json:"resourceName"`
+	SubResource  string    `json:"subResource"`
+	RequestBody  string    `json:"requestBody"`
+	Diff         string    `json:"diff"`
+	StatusCode   int       `json:"statusCode"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func ofAuditLog(auditLog *models.AuditLog) *AuditLog {
+	return &AuditLog{
+		ID:           auditLog.ID,
+		RequestID:    auditLog.RequestID,
+		UserID:       auditLog.UserID,
+		UserName:     auditLog.UserName,
+		AuthMethod:   auditLog.AuthMethod,
+		Method:       auditLog.Method,
+		Path:         auditLog.Path,
+		Verb:         auditLog.Verb,
+		Resource:     auditLog.Resource,
+		ResourceName: auditLog.ResourceName,
+		SubResource:  auditLog.SubResource,
+		RequestBody:  auditLog.RequestBody,
+		Diff:         auditLog.Diff,
+		StatusCode:   auditLog.StatusCode,
+		CreatedAt:    auditLog.CreatedAt,
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	"context"
+
+	canarymanager "github.com/horizoncd/horizon/pkg/canary/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// ListRules lists canary analysis rules of cluster
+	ListRules(ctx context.Context, clusterID uint) (*ListRulesResponse, error)
+	// UpdateRules replaces canary analysis rules of cluster
+	UpdateRules(ctx context.Context, clusterID uint, r *UpdateRulesRequest) error
+}
+
+type controller struct {
+	clusterMgr    clustermanager.Manager
+	canaryRuleMgr canarymanager.Manager
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		clusterMgr:    param.ClusterMgr,
+		canaryRuleMgr: param.CanaryRuleMgr,
+	}
+}
+
+func (c *controller) ListRules(ctx context.Context, clusterID uint) (*ListRulesResponse, error) {
+	const op = "canary controller: list rules"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	rules, err := c.canaryRuleMgr.ListByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return ofRules(rules), nil
+}
+
+func (c *controller) UpdateRules(ctx context.Context, clusterID uint, r *UpdateRulesRequest) error {
+	const op = "canary controller: update rules"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return err
+	}
+
+	rules := r.toRules(clusterID)
+	if err := canarymanager.ValidateUpdate(rules); err != nil {
+		return err
+	}
+	return c.canaryRuleMgr.UpdateByClusterID(ctx, clusterID, rules)
+}
@@ -0,0 +1,66 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	canarymodels "github.com/horizoncd/horizon/pkg/canary/models"
+)
+
+type ListRulesResponse struct {
+	Rules []*Rule `json:"rules"`
+}
+
+type Rule struct {
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Query     string  `json:"query,omitempty"`
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+}
+
+type UpdateRulesRequest struct {
+	Rules []*Rule `json:"rules"`
+}
+
+func (r *UpdateRulesRequest) toRules(clusterID uint) []*canarymodels.CanaryRule {
+	rules := make([]*canarymodels.CanaryRule, 0, len(r.Rules))
+	for _, rule := range r.Rules {
+		rules = append(rules, &canarymodels.CanaryRule{
+			ClusterID: clusterID,
+			Name:      rule.Name,
+			Type:      rule.Type,
+			Query:     rule.Query,
+			Operator:  rule.Operator,
+			Threshold: rule.Threshold,
+		})
+	}
+	return rules
+}
+
+func ofRules(rules []*canarymodels.CanaryRule) *ListRulesResponse {
+	resp := &ListRulesResponse{
+		Rules: make([]*Rule, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		resp.Rules = append(resp.Rules, &Rule{
+			Name:      rule.Name,
+			Type:      rule.Type,
+			Query:     rule.Query,
+			Operator:  rule.Operator,
+			Threshold: rule.Threshold,
+		})
+	}
+	return resp
+}
@@ -16,6 +16,7 @@ package cluster
 
 import (
 	"context"
+	"time"
 
 	"github.com/horizoncd/horizon/core/config"
 	"github.com/horizoncd/horizon/core/controller/build"
@@ -23,11 +24,16 @@ import (
 	appgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
 	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
+	approvalmanager "github.com/horizoncd/horizon/pkg/approval/manager"
+	approvalservice "github.com/horizoncd/horizon/pkg/approval/service"
 	"github.com/horizoncd/horizon/pkg/cd"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
 	registryfty "github.com/horizoncd/horizon/pkg/cluster/registry/factory"
+	"github.com/horizoncd/horizon/pkg/cluster/render"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
 	collectionmanager "github.com/horizoncd/horizon/pkg/collection/manager"
 	"github.com/horizoncd/horizon/pkg/config/grafana"
@@ -44,15 +50,18 @@ import (
 	"github.com/horizoncd/horizon/pkg/param"
 	prmanager "github.com/horizoncd/horizon/pkg/pipelinerun/manager"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pipelinerun/pipeline/manager"
+	"github.com/horizoncd/horizon/pkg/rbac"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	templateschematagmanager "github.com/horizoncd/horizon/pkg/templateschematag/manager"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usersvc "github.com/horizoncd/horizon/pkg/user/service"
+	"github.com/horizoncd/horizon/pkg/visibility"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -76,8 +85,18 @@ type Controller interface {
 	Restart(ctx context.Context, clusterID uint) (*PipelinerunIDResponse, error)
 	Deploy(ctx context.Context, clusterID uint, request *DeployRequest) (*PipelinerunIDResponse, error)
 	Rollback(ctx context.Context, clusterID uint, request *RollbackRequest) (*PipelinerunIDResponse, error)
+	// Promote promotes the image and config deployed in source cluster to the cluster
+	Promote(ctx context.Context, clusterID uint, request *PromoteRequest) (*PipelinerunIDResponse, error)
+	// ApprovePipelinerun approves the pipelinerun in protected environment and continues to run it
+	ApprovePipelinerun(ctx context.Context, pipelinerunID uint, request *ApprovalRequest) (*PipelinerunIDResponse, error)
+	// RejectPipelinerun rejects the pipelinerun in protected environment and cancels it
+	RejectPipelinerun(ctx context.Context, pipelinerunID uint, request *ApprovalRequest) error
 
 	FreeCluster(ctx context.Context, clusterID uint) error
+	// ListImageTags lists image tags of the cluster in the registry of its region
+	ListImageTags(ctx context.Context, clusterID uint) ([]*registry.Tag, error)
+	// DeleteImageTags deletes image tags of the cluster pushed earlier than olderThan ago
+	DeleteImageTags(ctx context.Context, clusterID uint, olderThan time.Duration) ([]*registry.Tag, error)
 
 	// InternalDeploy todo(zx): remove after InternalDeployV2 is stabilized
 	InternalDeploy(ctx context.Context, clusterID uint,
@@ -85,6 +104,11 @@ type Controller interface {
 
 	ExecuteAction(ctx context.Context, clusterID uint, action string,
 		gvk schema.GroupVersionResource) error
+	// TriggerCronJob creates a job from the cronjob of the cluster immediately,
+	// cronJob could be empty if the cluster has only one cronjob
+	TriggerCronJob(ctx context.Context, clusterID uint, cronJob string) (*cd.CronJobRun, error)
+	// ListCronJobRuns lists jobs created by the cronjobs of the cluster, the latest first
+	ListCronJobRuns(ctx context.Context, clusterID uint, cronJob string) ([]*cd.CronJobRun, error)
 
 	// Deprecated: GetClusterStatus
 	GetClusterStatus(ctx context.Context, clusterID uint) (_ *GetClusterStatusResponse, err error)
@@ -95,8 +119,13 @@ type Controller interface {
 	Exec(ctx context.Context, clusterID uint, r *ExecRequest) (_ ExecResponse, err error)
 
 	GetDiff(ctx context.Context, clusterID uint, refType, ref string) (*GetDiffResponse, error)
+	// GetManifestDiff renders manifests of master and gitops branch locally and returns the diffs of objects
+	GetManifestDiff(ctx context.Context, clusterID uint) ([]*render.ObjectDiff, error)
 	GetContainerLog(ctx context.Context, clusterID uint, podName, containerName string, tailLines int64) (
 		<-chan string, error)
+	// StreamContainerLogs streams logs of the container in pods of the cluster from the kubernetes api,
+	// lines of multiple pods are merged and prefixed by the pod name
+	StreamContainerLogs(ctx context.Context, clusterID uint, r *StreamContainerLogsRequest) (<-chan string, error)
 
 	DeleteClusterPods(ctx context.Context, clusterID uint, podName []string) (BatchResponse, error)
 	GetClusterPod(ctx context.Context, clusterID uint, podName string) (
@@ -120,6 +149,13 @@ type Controller interface {
 	GetStep(ctx context.Context, clusterID uint) (resp *GetStepResponse, err error)
 	// Deprecated: for internal usage, v1 to v2
 	Upgrade(ctx context.Context, clusterID uint) error
+	// UpgradeTemplate upgrades the cluster to another template release with values migrated,
+	// nothing is committed if dryRun is true
+	UpgradeTemplate(ctx context.Context, clusterID uint,
+		r *UpgradeTemplateRequest, dryRun bool) (*UpgradeTemplateResponse, error)
+	// BulkUpgradeTemplate upgrades all clusters on the release to another template release
+	BulkUpgradeTemplate(ctx context.Context, releaseID uint,
+		r *BulkUpgradeTemplateRequest, dryRun bool) ([]*UpgradeTemplateResponse, error)
 	ToggleLikeStatus(ctx context.Context, clusterID uint, like *WhetherLike) (err error)
 }
 
@@ -135,6 +171,8 @@ type controller struct {
 	applicationSvc        applicationservice.Service
 	templateReleaseMgr    trmanager.Manager
 	templateSchemaGetter  templateschema.Getter
+	migrationGetter       migration.Getter
+	manifestRenderer      render.Renderer
 	outputGetter          output.Getter
 	envMgr                envmanager.Manager
 	envRegionMgr          environmentregionmapper.Manager
@@ -158,6 +196,11 @@ type controller struct {
 	tokenConfig           token.Config
 	templateUpgradeMapper template.UpgradeMapper
 	collectionManager     collectionmanager.Manager
+	approvalSvc           approvalservice.Service
+	approvalMgr           approvalmanager.Manager
+	kubeClientFty         kubeclient.Factory
+	authorizer            rbac.Authorizer
+	visibilityChecker     visibility.Checker
 }
 
 var _ Controller = (*controller)(nil)
@@ -174,6 +217,8 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		applicationSvc:        param.ApplicationSvc,
 		templateReleaseMgr:    param.TemplateReleaseManager,
 		templateSchemaGetter:  param.TemplateSchemaGetter,
+		migrationGetter:       param.MigrationGetter,
+		manifestRenderer:      param.ManifestRenderer,
 		autoFreeSvc:           param.AutoFreeSvc,
 		outputGetter:          param.OutputGetter,
 		envMgr:                param.EnvMgr,
@@ -198,5 +243,10 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		tokenConfig:           config.TokenConfig,
 		templateUpgradeMapper: config.TemplateUpgradeMapper,
 		collectionManager:     param.CollectionMgr,
+		approvalSvc:           param.ApprovalSvc,
+		approvalMgr:           param.ApprovalMgr,
+		kubeClientFty:         kubeclient.Fty,
+		authorizer:            param.Authorizer,
+		visibilityChecker:     param.VisibilityChecker,
 	}
 }
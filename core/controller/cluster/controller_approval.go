@@ -0,0 +1,202 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	amodels "github.com/horizoncd/horizon/pkg/application/models"
+	approvalmodels "github.com/horizoncd/horizon/pkg/approval/models"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// ApprovePipelinerun approves the pipelinerun which is pending approval and continues to run it
+func (c *controller) ApprovePipelinerun(ctx context.Context, pipelinerunID uint,
+	r *ApprovalRequest) (_ *PipelinerunIDResponse, err error) {
+	const op = "cluster controller: approve pipelinerun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pipelinerun, cluster, err := c.decidePipelinerun(ctx, pipelinerunID, approvalmodels.DecisionApproved, r)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.resumePipelinerun(ctx, application, cluster, pipelinerun); err != nil {
+		return nil, err
+	}
+
+	return &PipelinerunIDResponse{
+		PipelinerunID: pipelinerun.ID,
+	}, nil
+}
+
+// resumePipelinerun continues to run the approved pipelinerun according to its action
+func (c *controller) resumePipelinerun(ctx context.Context, application *amodels.Application,
+	cluster *cmodels.Cluster, pipelinerun *prmodels.Pipelinerun) error {
+	switch pipelinerun.Action {
+	case prmodels.ActionBuildDeploy:
+		return c.buildDeploy(ctx, application, cluster, pipelinerun)
+	case prmodels.ActionDeploy:
+		// check again right before merging, in case gitops branch moves after the approval is recorded
+		if err := c.checkConfigUnchanged(ctx, application, cluster, pipelinerun); err != nil {
+			return err
+		}
+		diff, err := c.clusterGitRepo.CompareConfig(ctx, application.Name, cluster.Name,
+			&pipelinerun.LastConfigCommit, &pipelinerun.ConfigCommit)
+		if err != nil {
+			return err
+		}
+		return c.deploy(ctx, application, cluster, pipelinerun, diff != "")
+	case prmodels.ActionRollback:
+		if pipelinerun.RollbackFrom == nil {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"the pipelinerun with id: %v does not have a pipelinerun to roll back to", pipelinerun.ID)
+		}
+		rollbackFrom, err := c.pipelinerunMgr.GetByID(ctx, *pipelinerun.RollbackFrom)
+		if err != nil {
+			return err
+		}
+		return c.rollback(ctx, application, cluster, pipelinerun, rollbackFrom)
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"action %v of pipelinerun does not support approval", pipelinerun.Action)
+	}
+}
+
+// checkConfigUnchanged makes sure the deploy pipelinerun deploys exactly the config reviewed by approvers,
+// a new deploy should be requested if the branches of cluster repo have moved since the request
+func (c *controller) checkConfigUnchanged(ctx context.Context, application *amodels.Application,
+	cluster *cmodels.Cluster, pipelinerun *prmodels.Pipelinerun) error {
+	if pipelinerun.Action != prmodels.ActionDeploy {
+		return nil
+	}
+	configCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
+	if err != nil {
+		return err
+	}
+	if configCommit.Gitops != pipelinerun.ConfigCommit || configCommit.Master != pipelinerun.LastConfigCommit {
+		return perror.Wrapf(herrors.ErrClusterConfigChanged,
+			"config of cluster has changed from %v to %v since the deploy was requested, "+
+				"please reject it and request a new deploy", pipelinerun.ConfigCommit, configCommit.Gitops)
+	}
+	return nil
+}
+
+// RejectPipelinerun rejects the pipelinerun which is pending approval and cancels it
+func (c *controller) RejectPipelinerun(ctx context.Context, pipelinerunID uint,
+	r *ApprovalRequest) (err error) {
+	const op = "cluster controller: reject pipelinerun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, _, err = c.decidePipelinerun(ctx, pipelinerunID, approvalmodels.DecisionRejected, r)
+	return err
+}
+
+// decidePipelinerun checks the current user can decide the pipelinerun, records the decision
+// and updates status of the pipelinerun
+func (c *controller) decidePipelinerun(ctx context.Context, pipelinerunID uint, decision string,
+	r *ApprovalRequest) (*prmodels.Pipelinerun, *cmodels.Cluster, error) {
+	pipelinerun, err := c.pipelinerunMgr.GetByID(ctx, pipelinerunID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pipelinerun.Status != string(prmodels.StatusPendingApproval) {
+		return nil, nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"the pipelinerun with id: %v is not pending approval", pipelinerunID)
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, pipelinerun.ClusterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := c.approvalSvc.CheckApprover(ctx, cluster, pipelinerun); err != nil {
+		return nil, nil, err
+	}
+	if decision == approvalmodels.DecisionApproved {
+		if err := c.checkDeployWindows(ctx, cluster); err != nil {
+			return nil, nil, err
+		}
+		application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := c.checkConfigUnchanged(ctx, application, cluster, pipelinerun); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var comment string
+	if r != nil {
+		comment = r.Comment
+	}
+	// the approval can only be created once for each pipelinerun
+	if _, err := c.approvalMgr.Create(ctx, &approvalmodels.Approval{
+		PipelinerunID: pipelinerunID,
+		Decision:      decision,
+		Comment:       comment,
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	eventType := eventmodels.PipelinerunApproved
+	if decision == approvalmodels.DecisionApproved {
+		err = c.updatePipelineRunStatus(ctx, pipelinerun.Action, pipelinerunID, prmodels.StatusCreated, "")
+		pipelinerun.Status = string(prmodels.StatusCreated)
+	} else {
+		eventType = eventmodels.PipelinerunRejected
+		err = c.updatePipelineRunStatus(ctx, pipelinerun.Action, pipelinerunID, prmodels.StatusCancelled, "")
+		pipelinerun.Status = string(prmodels.StatusCancelled)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	c.recordApprovalEvent(ctx, pipelinerunID, eventType, comment)
+	return pipelinerun, cluster, nil
+}
+
+// initialPipelinerunStatus returns the status of pipelinerun when it is created
+func initialPipelinerunStatus(requireApproval bool) prmodels.PipelineStatus {
+	if requireApproval {
+		return prmodels.StatusPendingApproval
+	}
+	return prmodels.StatusCreated
+}
+
+func (c *controller) recordApprovalEvent(ctx context.Context, pipelinerunID uint,
+	eventType string, comment string) {
+	event := &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourcePipelinerun,
+			EventType:    eventType,
+			ResourceID:   pipelinerunID,
+		},
+	}
+	if comment != "" {
+		event.Extra = &comment
+	}
+	if _, err := c.eventMgr.CreateEvent(ctx, event); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+}
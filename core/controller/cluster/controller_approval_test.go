@@ -0,0 +1,210 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	membermock "github.com/horizoncd/horizon/mock/pkg/member/service"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	approvalmodels "github.com/horizoncd/horizon/pkg/approval/models"
+	approvalservice "github.com/horizoncd/horizon/pkg/approval/service"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testDecidePipelinerun(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	_ = db.AutoMigrate(&envmodels.Environment{}, &appmodels.Application{}, &cmodels.Cluster{},
+		&prmodels.Pipelinerun{}, &approvalmodels.Approval{}, &eventmodels.Event{})
+	manager := managerparam.InitManager(db)
+	mockCtl := gomock.NewController(t)
+	memberSvc := membermock.NewMockService(mockCtl)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	c := controller{
+		clusterMgr:     manager.ClusterMgr,
+		applicationMgr: manager.ApplicationManager,
+		clusterGitRepo: clusterGitRepo,
+		envMgr:         manager.EnvMgr,
+		pipelinerunMgr: manager.PipelinerunMgr,
+		eventMgr:       manager.EventManager,
+		approvalMgr:    manager.ApprovalMgr,
+		approvalSvc:    approvalservice.NewService(memberSvc, manager),
+	}
+
+	now := time.Now()
+	_, err := manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{
+		Name:      "online",
+		Protected: true,
+		Approvers: envmodels.Approvers{
+			Roles: []string{"pe"},
+		},
+	})
+	assert.Nil(t, err)
+	_, err = manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{
+		Name:      "perf",
+		Protected: true,
+		Approvers: envmodels.Approvers{
+			Users: []string{"jerry@horizoncd.com"},
+		},
+		DeployWindows: envmodels.DeployWindows{
+			{
+				Weekdays:  []time.Weekday{now.Add(-24 * time.Hour).Weekday()},
+				StartTime: "00:00",
+				EndTime:   "23:59",
+			},
+		},
+	})
+	assert.Nil(t, err)
+	application := &appmodels.Application{Name: "app-approval"}
+	assert.Nil(t, db.Create(application).Error)
+	online := &cmodels.Cluster{Name: "cluster-online", EnvironmentName: "online", ApplicationID: application.ID}
+	perf := &cmodels.Cluster{Name: "cluster-perf", EnvironmentName: "perf"}
+	assert.Nil(t, db.Create(online).Error)
+	assert.Nil(t, db.Create(perf).Error)
+
+	required, err := c.approvalSvc.RequireApproval(ctx, online)
+	assert.Nil(t, err)
+	assert.True(t, required)
+	assert.Equal(t, prmodels.StatusPendingApproval, initialPipelinerunStatus(required))
+
+	pr, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: online.ID,
+		Action:    prmodels.ActionDeploy,
+		Status:    string(prmodels.StatusPendingApproval),
+		CreatedBy: 1,
+	})
+	assert.Nil(t, err)
+
+	// the creator can not approve its own pipelinerun
+	// nolint
+	creatorCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "tony",
+		Email: "tony@horizoncd.com",
+		ID:    uint(1),
+	})
+	_, err = c.ApprovePipelinerun(creatorCtx, pr.ID, &ApprovalRequest{})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	// nolint
+	guestCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "guest",
+		Email: "guest@horizoncd.com",
+		ID:    uint(4),
+	})
+	memberSvc.EXPECT().GetMemberOfResource(guestCtx, common.ResourceCluster, gomock.Any()).
+		Return(&membermodels.Member{Role: "guest"}, nil)
+	err = c.RejectPipelinerun(guestCtx, pr.ID, &ApprovalRequest{})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	// nolint
+	peCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "pe",
+		Email: "pe@horizoncd.com",
+		ID:    uint(3),
+	})
+	memberSvc.EXPECT().GetMemberOfResource(peCtx, common.ResourceCluster, gomock.Any()).
+		Return(&membermodels.Member{Role: "pe"}, nil)
+	err = c.RejectPipelinerun(peCtx, pr.ID, &ApprovalRequest{Comment: "not now"})
+	assert.Nil(t, err)
+
+	pr, err = manager.PipelinerunMgr.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusCancelled), pr.Status)
+	approval, err := manager.ApprovalMgr.GetByPipelinerunID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, approvalmodels.DecisionRejected, approval.Decision)
+	assert.Equal(t, "not now", approval.Comment)
+	events, err := manager.EventManager.ListEvents(ctx, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, eventmodels.PipelinerunRejected, events[0].EventType)
+	assert.Equal(t, pr.ID, events[0].ResourceID)
+
+	// the pipelinerun can only be decided once
+	err = c.RejectPipelinerun(peCtx, pr.ID, &ApprovalRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// approvers are not allowed to approve out of deploy windows
+	pr, err = manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: perf.ID,
+		Action:    prmodels.ActionDeploy,
+		Status:    string(prmodels.StatusPendingApproval),
+		CreatedBy: 1,
+	})
+	assert.Nil(t, err)
+	// nolint
+	jerryCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "jerry",
+		Email: "jerry@horizoncd.com",
+		ID:    uint(5),
+	})
+	_, err = c.ApprovePipelinerun(jerryCtx, pr.ID, &ApprovalRequest{})
+	assert.Equal(t, herrors.ErrDeployWindowClosed, perror.Cause(err))
+	pr, err = manager.PipelinerunMgr.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusPendingApproval), pr.Status)
+
+	// but rejecting is always allowed
+	err = c.RejectPipelinerun(jerryCtx, pr.ID, nil)
+	assert.Nil(t, err)
+
+	// approvers are not allowed to approve when the config has changed since the request
+	pr, err = manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID:        online.ID,
+		Action:           prmodels.ActionDeploy,
+		Status:           string(prmodels.StatusPendingApproval),
+		LastConfigCommit: "master-1",
+		ConfigCommit:     "gitops-1",
+		CreatedBy:        1,
+	})
+	assert.Nil(t, err)
+	memberSvc.EXPECT().GetMemberOfResource(peCtx, common.ResourceCluster, gomock.Any()).
+		Return(&membermodels.Member{Role: "pe"}, nil).Times(2)
+	clusterGitRepo.EXPECT().GetConfigCommit(gomock.Any(), application.Name, online.Name).
+		Return(&gitrepo.ClusterCommit{Master: "master-1", Gitops: "gitops-2"}, nil)
+	_, err = c.ApprovePipelinerun(peCtx, pr.ID, &ApprovalRequest{})
+	assert.Equal(t, herrors.ErrClusterConfigChanged, perror.Cause(err))
+	pr, err = manager.PipelinerunMgr.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusPendingApproval), pr.Status)
+	_, err = manager.ApprovalMgr.GetByPipelinerunID(ctx, pr.ID)
+	assert.NotNil(t, err)
+
+	// the recorded commits are deployed when the config is unchanged
+	clusterGitRepo.EXPECT().GetConfigCommit(gomock.Any(), application.Name, online.Name).
+		Return(&gitrepo.ClusterCommit{Master: "master-1", Gitops: "gitops-1"}, nil).Times(2)
+	compareErr := errors.New("compare failed")
+	clusterGitRepo.EXPECT().CompareConfig(gomock.Any(), application.Name, online.Name,
+		gomock.Eq(&pr.LastConfigCommit), gomock.Eq(&pr.ConfigCommit)).Return("", compareErr)
+	_, err = c.ApprovePipelinerun(peCtx, pr.ID, &ApprovalRequest{})
+	assert.Equal(t, compareErr, perror.Cause(err))
+}
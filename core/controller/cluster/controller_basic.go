@@ -28,6 +28,7 @@ import (
 	"github.com/horizoncd/horizon/core/middleware/requestid"
 	"github.com/horizoncd/horizon/lib/q"
 	"github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/cd"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
@@ -112,6 +113,10 @@ func (c *controller) List(ctx context.Context, query *q.Query) ([]*ListClusterWi
 		}
 	}
 
+	if err := c.excludeInvisible(ctx, currentUser, query); err != nil {
+		return nil, 0, err
+	}
+
 	count, clusters, err := c.clusterMgr.List(ctx, query, applicationIDs...)
 	if err != nil {
 		return nil, 0,
@@ -132,6 +137,24 @@ func (c *controller) List(ctx context.Context, query *q.Query) ([]*ListClusterWi
 	return responses, count, nil
 }
 
+// excludeInvisible excludes the clusters of private groups and applications which are invisible to the user
+func (c *controller) excludeInvisible(ctx context.Context, currentUser userauth.User, query *q.Query) error {
+	invisible, err := c.visibilityChecker.ListInvisible(ctx, currentUser)
+	if err != nil {
+		return err
+	}
+	if query.Keywords == nil {
+		query.Keywords = q.KeyWords{}
+	}
+	if len(invisible.Groups) > 0 {
+		query.Keywords[common.ClusterQueryExcludeGroups] = invisible.GroupIDs()
+	}
+	if len(invisible.Applications) > 0 {
+		query.Keywords[common.ClusterQueryExcludeApplications] = invisible.ApplicationIDs()
+	}
+	return nil
+}
+
 func (c *controller) ListByApplication(ctx context.Context,
 	query *q.Query) (_ int, _ []*ListClusterResponse, err error) {
 	const op = "cluster controller: list cluster"
@@ -738,6 +761,20 @@ func (c *controller) GetClusterByName(ctx context.Context,
 		return nil, err
 	}
 
+	// private clusters are not found for the users who are not their members
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	invisible, err := c.visibilityChecker.ListInvisible(ctx, currentUser)
+	if err != nil {
+		return nil, err
+	}
+	if invisible.HasApplication(application.ID) || invisible.HasGroup(application.GroupID) {
+		return nil, herrors.NewErrNotFound(herrors.ClusterInDB,
+			fmt.Sprintf("cluster %s not found", clusterName))
+	}
+
 	// 3. get full path
 	group, err := c.groupSvc.GetChildByID(ctx, application.GroupID)
 	if err != nil {
@@ -24,6 +24,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	mockcd "github.com/horizoncd/horizon/mock/pkg/cd"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
@@ -31,6 +32,7 @@ import (
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	envmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
@@ -95,13 +97,14 @@ func testListClusterByNameFuzzily(t *testing.T) {
 	}
 
 	c = &controller{
-		clusterMgr:     manager.ClusterMgr,
-		applicationMgr: manager.ApplicationManager,
-		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
-		groupManager:   manager.GroupManager,
-		memberManager:  manager.MemberManager,
-		eventMgr:       manager.EventManager,
-		commitGetter:   commitGetter,
+		visibilityChecker: manager.VisibilityChecker,
+		clusterMgr:        manager.ClusterMgr,
+		applicationMgr:    manager.ApplicationManager,
+		applicationSvc:    applicationservice.NewService(groupservice.NewService(manager), manager),
+		groupManager:      manager.GroupManager,
+		memberManager:     manager.MemberManager,
+		eventMgr:          manager.EventManager,
+		commitGetter:      commitGetter,
 	}
 
 	resps, count, err := c.List(ctx, &q.Query{Keywords: q.KeyWords{common.ClusterQueryName: "fuzzilyCluster"}})
@@ -116,6 +119,69 @@ func testListClusterByNameFuzzily(t *testing.T) {
 	}
 }
 
+func testPrivateClusterInvisible(t *testing.T) {
+	// the creator of the group is its owner
+	group, err := manager.GroupManager.Create(ctx, &groupmodels.Group{
+		Name:            "groupForPrivateCluster",
+		Path:            "groupForPrivateCluster",
+		VisibilityLevel: "private",
+	})
+	assert.Nil(t, err)
+	application, err := manager.ApplicationManager.Create(ctx, &appmodels.Application{
+		GroupID:  group.ID,
+		Name:     "appForPrivateCluster",
+		Priority: "P3",
+	}, nil)
+	assert.Nil(t, err)
+	_, err = manager.RegionMgr.Create(ctx, &regionmodels.Region{
+		Name:        "hzPrivate",
+		DisplayName: "HZPrivate",
+	})
+	assert.Nil(t, err)
+	_, err = manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "privateCluster",
+		EnvironmentName: "testPrivate",
+		RegionName:      "hzPrivate",
+	}, nil, nil)
+	assert.Nil(t, err)
+	manager.VisibilityChecker.Invalidate()
+
+	c = &controller{
+		visibilityChecker: manager.VisibilityChecker,
+		clusterMgr:        manager.ClusterMgr,
+		applicationMgr:    manager.ApplicationManager,
+		applicationSvc:    applicationservice.NewService(groupservice.NewService(manager), manager),
+		groupSvc:          groupservice.NewService(manager),
+		groupManager:      manager.GroupManager,
+		memberManager:     manager.MemberManager,
+		commitGetter:      commitGetter,
+	}
+
+	resp, err := c.GetClusterByName(ctx, "privateCluster")
+	assert.Nil(t, err)
+	assert.Equal(t, "/groupForPrivateCluster/appForPrivateCluster/privateCluster", resp.FullPath)
+	resps, count, err := c.List(ctx, &q.Query{Keywords: q.KeyWords{common.ClusterQueryName: "privateCluster"}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 1, len(resps))
+
+	// private clusters are invisible to non-members
+	nonMemberCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{ // nolint
+		Name: "non-member",
+		ID:   999,
+	})
+	_, err = c.GetClusterByName(nonMemberCtx, "privateCluster")
+	e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	assert.Equal(t, herrors.ClusterInDB, e.Source)
+	resps, count, err = c.List(nonMemberCtx,
+		&q.Query{Keywords: q.KeyWords{common.ClusterQueryName: "privateCluster"}})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 0, len(resps))
+}
+
 func testListUserClustersByNameFuzzily(t *testing.T) {
 	// init data
 	region, err := manager.RegionMgr.Create(ctx, &regionmodels.Region{
@@ -212,13 +278,14 @@ func testListUserClustersByNameFuzzily(t *testing.T) {
 	assert.Nil(t, err)
 
 	c = &controller{
-		clusterMgr:     manager.ClusterMgr,
-		applicationMgr: manager.ApplicationManager,
-		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
-		groupManager:   manager.GroupManager,
-		memberManager:  manager.MemberManager,
-		eventMgr:       manager.EventManager,
-		commitGetter:   commitGetter,
+		visibilityChecker: manager.VisibilityChecker,
+		clusterMgr:        manager.ClusterMgr,
+		applicationMgr:    manager.ApplicationManager,
+		applicationSvc:    applicationservice.NewService(groupservice.NewService(manager), manager),
+		groupManager:      manager.GroupManager,
+		memberManager:     manager.MemberManager,
+		eventMgr:          manager.EventManager,
+		commitGetter:      commitGetter,
 	}
 
 	resps, count, err := c.List(ctx,
@@ -298,14 +365,15 @@ func testControllerFreeOrDeleteClusterFailed(t *testing.T) {
 	cd.EXPECT().DeleteCluster(gomock.Any(), gomock.Any()).Return(errors.New("test")).AnyTimes()
 
 	c = &controller{
-		cd:             cd,
-		clusterMgr:     manager.ClusterMgr,
-		applicationMgr: manager.ApplicationManager,
-		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
-		groupManager:   manager.GroupManager,
-		envMgr:         manager.EnvMgr,
-		regionMgr:      manager.RegionMgr,
-		eventMgr:       manager.EventManager,
+		visibilityChecker: manager.VisibilityChecker,
+		cd:                cd,
+		clusterMgr:        manager.ClusterMgr,
+		applicationMgr:    manager.ApplicationManager,
+		applicationSvc:    applicationservice.NewService(groupservice.NewService(manager), manager),
+		groupManager:      manager.GroupManager,
+		envMgr:            manager.EnvMgr,
+		regionMgr:         manager.RegionMgr,
+		eventMgr:          manager.EventManager,
 	}
 
 	id, err := registrydao.NewDAO(db).Create(ctx, &registrymodels.Registry{
@@ -376,6 +376,17 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 	if err != nil {
 		return err
 	}
+	// the cluster before updating it for audit, it's recorded again with configs after read from git repo
+	before := &UpdateClusterRequestV2{
+		Description:  cluster.Description,
+		Environment:  &cluster.EnvironmentName,
+		Region:       &cluster.RegionName,
+		TemplateInfo: &codemodels.TemplateInfo{Name: cluster.Template, Release: cluster.TemplateRelease},
+	}
+	if cluster.GitURL != "" {
+		before.Git = codemodels.NewGit(cluster.GitURL, cluster.GitSubfolder, cluster.GitRefType, cluster.GitRef)
+	}
+	common.SetAuditBefore(ctx, before)
 
 	// 2. check if we should update region and env
 	var regionEntity *regionmodels.RegionEntity
@@ -443,6 +454,8 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 			return nil, nil, perror.Wrapf(herrors.ErrParamInvalid, "git repo  %s not support v2 interface",
 				cluster.Name)
 		}
+		before.BuildConfig, before.TemplateConfig = files.PipelineJSONBlob, files.ApplicationJSONBlob
+		common.SetAuditBefore(ctx, before)
 
 		buildConfig := r.BuildConfig
 		templateConfig := r.TemplateConfig
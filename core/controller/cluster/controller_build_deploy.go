@@ -24,8 +24,11 @@ import (
 	"time"
 
 	"github.com/horizoncd/horizon/core/common"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/git"
 	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
@@ -41,13 +44,11 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 	const op = "cluster controller: build deploy"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	currentUser, err := common.UserFromContext(ctx)
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
 	if err != nil {
 		return nil, err
 	}
-
-	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
-	if err != nil {
+	if err := c.checkDeployWindows(ctx, cluster); err != nil {
 		return nil, err
 	}
 
@@ -92,10 +93,14 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 	}
 
 	// 2. add pipelinerun in db
+	requireApproval, err := c.approvalSvc.RequireApproval(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
 	pr := &prmodels.Pipelinerun{
 		ClusterID:        clusterID,
 		Action:           prmodels.ActionBuildDeploy,
-		Status:           string(prmodels.StatusCreated),
+		Status:           string(initialPipelinerunStatus(requireApproval)),
 		Title:            r.Title,
 		Description:      r.Description,
 		GitURL:           cluster.GitURL,
@@ -110,34 +115,61 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+	if requireApproval {
+		c.recordApprovalEvent(ctx, prCreated.ID, eventmodels.PipelinerunApprovalRequested, "")
+		return &BuildDeployResponse{
+			PipelinerunID: prCreated.ID,
+		}, nil
+	}
+
+	if err := c.buildDeploy(ctx, application, cluster, prCreated); err != nil {
+		return nil, err
+	}
+	return &BuildDeployResponse{
+		PipelinerunID: prCreated.ID,
+	}, nil
+}
+
+// buildDeploy creates pipelinerun in tekton to build image and deploy the cluster
+func (c *controller) buildDeploy(ctx context.Context, application *appmodels.Application,
+	cluster *clustermodels.Cluster, prCreated *prmodels.Pipelinerun) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return err
+	}
 
 	// 3. generate a JWT token for tekton callback
 	token, err := c.tokenSvc.CreateJWTToken(strconv.Itoa(int(currentUser.GetID())),
 		c.tokenConfig.CallbackTokenExpireIn, tokensvc.WithPipelinerunID(prCreated.ID))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// 4. create pipelinerun in k8s
 	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	clusterFiles, err := c.clusterGitRepo.GetCluster(ctx,
 		application.Name, cluster.Name, tr.ChartName)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	prGit := tekton.PipelineRunGit{
 		URL:       cluster.GitURL,
 		Subfolder: cluster.GitSubfolder,
-		Commit:    commit.ID,
+		Commit:    prCreated.GitCommit,
 	}
 	switch prCreated.GitRefType {
 	case codemodels.GitRefTypeTag:
@@ -153,7 +185,7 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		ClusterID:        cluster.ID,
 		Environment:      cluster.EnvironmentName,
 		Git:              prGit,
-		ImageURL:         imageURL,
+		ImageURL:         prCreated.ImageURL,
 		Operator:         currentUser.GetEmail(),
 		PipelinerunID:    prCreated.ID,
 		PipelineJSONBlob: clusterFiles.PipelineJSONBlob,
@@ -163,19 +195,17 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		Token:            token,
 	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// update event id returned from tekton-trigger EventListener
-	log.Infof(ctx, "received event id: %s from tekton-trigger EventListener, pipelinerunID: %d", ciEventID, pr.ID)
-	err = c.pipelinerunMgr.UpdateCIEventIDByID(ctx, pr.ID, ciEventID)
+	log.Infof(ctx, "received event id: %s from tekton-trigger EventListener, pipelinerunID: %d", ciEventID, prCreated.ID)
+	err = c.pipelinerunMgr.UpdateCIEventIDByID(ctx, prCreated.ID, ciEventID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return &BuildDeployResponse{
-		PipelinerunID: prCreated.ID,
-	}, nil
+	return nil
 }
 
 func assembleImageURL(regionEntity *regionmodels.RegionEntity,
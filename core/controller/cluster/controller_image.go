@@ -0,0 +1,115 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) ListImageTags(ctx context.Context, clusterID uint) (_ []*registry.Tag, err error) {
+	const op = "cluster controller: list image tags"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	rg, application, cluster, err := c.getClusterRegistry(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return rg.ListImageTags(ctx, application.Name, cluster.Name)
+}
+
+func (c *controller) DeleteImageTags(ctx context.Context, clusterID uint,
+	olderThan time.Duration) (_ []*registry.Tag, err error) {
+	const op = "cluster controller: delete image tags"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if olderThan <= 0 {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "olderThan must be positive, got %v", olderThan)
+	}
+	rg, application, cluster, err := c.getClusterRegistry(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	inUse, err := c.listImagesInUse(ctx, application, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return rg.DeleteTagsOlderThan(ctx, application.Name, cluster.Name, time.Now().Add(-olderThan), inUse)
+}
+
+// listImagesInUse lists the image of the latest successful pipelinerun,
+// and the image in pipeline output of gitops branch, which may be deployed or rolled back to
+func (c *controller) listImagesInUse(ctx context.Context, application *appmodels.Application,
+	cluster *cmodels.Cluster) ([]string, error) {
+	images := make([]string, 0, 2)
+	latestPR, err := c.pipelinerunMgr.GetLatestSuccessByClusterID(ctx, cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	if latestPR != nil && latestPR.ImageURL != "" {
+		images = append(images, latestPR.ImageURL)
+	}
+
+	output, err := c.clusterGitRepo.GetPipelineOutput(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		// it's fine that the cluster has never been built
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok &&
+			perror.Cause(err) != herrors.ErrPipelineOutputEmpty {
+			return nil, err
+		}
+	}
+	if output, ok := output.(map[string]interface{}); ok {
+		if image, ok := output["image"].(string); ok && image != "" {
+			images = append(images, image)
+		}
+	}
+	return images, nil
+}
+
+// getClusterRegistry returns the registry of cluster's region, with the application
+// and cluster to locate the cluster's repository
+func (c *controller) getClusterRegistry(ctx context.Context,
+	clusterID uint) (registry.Registry, *appmodels.Application, *cmodels.Cluster, error) {
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rg, err := c.registryFty.GetRegistryByConfig(ctx, &registry.Config{
+		Server:             regionEntity.Registry.Server,
+		Token:              regionEntity.Registry.Token,
+		InsecureSkipVerify: regionEntity.Registry.InsecureSkipTLSVerify,
+		Kind:               regionEntity.Registry.Kind,
+		Path:               regionEntity.Registry.Path,
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return rg, application, cluster, nil
+}
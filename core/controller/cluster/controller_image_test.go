@@ -0,0 +1,113 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	registrymock "github.com/horizoncd/horizon/mock/pkg/cluster/registry"
+	registryftymock "github.com/horizoncd/horizon/mock/pkg/cluster/registry/factory"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testImageTags(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	_ = db.AutoMigrate(&cmodels.Cluster{}, &appmodels.Application{},
+		&regionmodels.Region{}, &registrymodels.Registry{}, &prmodels.Pipelinerun{})
+	manager := managerparam.InitManager(db)
+	mockCtl := gomock.NewController(t)
+	registryFty := registryftymock.NewMockRegistryGetter(mockCtl)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	c := controller{
+		clusterMgr:     manager.ClusterMgr,
+		applicationMgr: manager.ApplicationManager,
+		regionMgr:      manager.RegionMgr,
+		pipelinerunMgr: manager.PipelinerunMgr,
+		clusterGitRepo: clusterGitRepo,
+		registryFty:    registryFty,
+	}
+
+	rg := &registrymodels.Registry{Name: "registry", Server: "https://registry.com", Path: "horizon",
+		Kind: "registry"}
+	assert.Nil(t, db.Create(rg).Error)
+	assert.Nil(t, db.Create(&regionmodels.Region{Name: "hz", RegistryID: rg.ID}).Error)
+	application := &appmodels.Application{Name: "app"}
+	assert.Nil(t, db.Create(application).Error)
+	cluster := &cmodels.Cluster{ApplicationID: application.ID, Name: "app-test", RegionName: "hz",
+		Template: "javaapp"}
+	assert.Nil(t, db.Create(cluster).Error)
+
+	registryMock := registrymock.NewMockRegistry(mockCtl)
+	registryFty.EXPECT().GetRegistryByConfig(ctx, &registry.Config{
+		Server: "https://registry.com",
+		Path:   "horizon",
+		Kind:   "registry",
+	}).Return(registryMock, nil).AnyTimes()
+
+	tags := []*registry.Tag{{Name: "v2", Digest: "sha256:2", PushedAt: time.Now()}}
+	registryMock.EXPECT().ListImageTags(ctx, "app", "app-test").Return(tags, nil)
+	listed, err := c.ListImageTags(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, tags, listed)
+
+	_, err = c.DeleteImageTags(ctx, cluster.ID, 0)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// the cluster is never built
+	clusterGitRepo.EXPECT().GetPipelineOutput(ctx, "app", "app-test", "javaapp").
+		Return(nil, herrors.ErrPipelineOutputEmpty)
+	deletedTags := []*registry.Tag{{Name: "v1", Digest: "sha256:1", PushedAt: time.Now().Add(-48 * time.Hour)}}
+	registryMock.EXPECT().DeleteTagsOlderThan(ctx, "app", "app-test", gomock.Any(), []string{}).DoAndReturn(
+		func(_, _, _ interface{}, before time.Time, _ []string) ([]*registry.Tag, error) {
+			assert.WithinDuration(t, time.Now().Add(-24*time.Hour), before, time.Minute)
+			return deletedTags, nil
+		})
+	deleted, err := c.DeleteImageTags(ctx, cluster.ID, 24*time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, deletedTags, deleted)
+
+	// images of the latest successful pipelinerun and gitops are in use
+	_, err = manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Status:    string(prmodels.StatusOK),
+		GitCommit: "b6a2e3f",
+		ImageURL:  "registry.com/horizon/app/app-test:b6a2e3f",
+	})
+	assert.Nil(t, err)
+	clusterGitRepo.EXPECT().GetPipelineOutput(ctx, "app", "app-test", "javaapp").
+		Return(map[string]interface{}{"image": "registry.com/horizon/app/app-test:c8d1f0a"}, nil)
+	registryMock.EXPECT().DeleteTagsOlderThan(ctx, "app", "app-test", gomock.Any(), []string{
+		"registry.com/horizon/app/app-test:b6a2e3f", "registry.com/horizon/app/app-test:c8d1f0a",
+	}).Return(nil, nil)
+	_, err = c.DeleteImageTags(ctx, cluster.ID, 24*time.Hour)
+	assert.Nil(t, err)
+
+	_, err = c.ListImageTags(ctx, cluster.ID+1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,230 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// _maxLogStreamPods limits the pods whose logs are streamed in one request
+const _maxLogStreamPods = 20
+
+func (c *controller) StreamContainerLogs(ctx context.Context, clusterID uint,
+	r *StreamContainerLogsRequest) (<-chan string, error) {
+	const op = "cluster controller: stream container logs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := validateStreamContainerLogsRequest(r); err != nil {
+		return nil, err
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	namespace, regionEntity, err := c.getNamespaceAndRegion(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	_, kubeClient, err := c.kubeClientFty.GetByK8SServer(regionEntity.Server, regionEntity.Certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	// check all pods before streaming, so that the request fails as a whole
+	logOptions := make([]*corev1.PodLogOptions, 0, len(r.Pods))
+	for _, podName := range r.Pods {
+		pod, err := kubeClient.Basic.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return nil, herrors.NewErrNotFound(herrors.PodsInK8S, err.Error())
+			}
+			return nil, herrors.NewErrGetFailed(herrors.PodsInK8S, err.Error())
+		}
+		if pod.Labels[common.ClusterClusterLabelKey] != cluster.Name {
+			return nil, herrors.NewErrNotFound(herrors.PodsInK8S,
+				fmt.Sprintf("pod %s not found in cluster %s", podName, cluster.Name))
+		}
+		options, err := podLogOptions(pod, r)
+		if err != nil {
+			return nil, err
+		}
+		logOptions = append(logOptions, options)
+	}
+
+	streams := make([]io.ReadCloser, 0, len(r.Pods))
+	for i, podName := range r.Pods {
+		stream, err := kubeClient.Basic.CoreV1().Pods(namespace).GetLogs(podName, logOptions[i]).Stream(ctx)
+		if err != nil {
+			for _, opened := range streams {
+				_ = opened.Close()
+			}
+			return nil, herrors.NewErrGetFailed(herrors.PodLogsInK8S, err.Error())
+		}
+		streams = append(streams, stream)
+	}
+
+	logC := make(chan string)
+	var wg sync.WaitGroup
+	for i, podName := range r.Pods {
+		wg.Add(1)
+		go func(podName string, stream io.ReadCloser) {
+			defer wg.Done()
+			defer stream.Close()
+			pipePodLogs(ctx, podName, stream, logC)
+		}(podName, streams[i])
+	}
+	go func() {
+		wg.Wait()
+		close(logC)
+	}()
+	return logC, nil
+}
+
+func validateStreamContainerLogsRequest(r *StreamContainerLogsRequest) error {
+	if len(r.Pods) == 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "at least one pod is required")
+	}
+	if len(r.Pods) > _maxLogStreamPods {
+		return perror.Wrapf(herrors.ErrParamInvalid, "logs of at most %d pods could be streamed", _maxLogStreamPods)
+	}
+	seen := make(map[string]struct{}, len(r.Pods))
+	for _, pod := range r.Pods {
+		if _, ok := seen[pod]; ok {
+			return perror.Wrapf(herrors.ErrParamInvalid, "pod %s is duplicated", pod)
+		}
+		seen[pod] = struct{}{}
+	}
+	if r.SinceSeconds != nil && r.SinceTime != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, "sinceSeconds and sinceTime are exclusive")
+	}
+	if r.SinceSeconds != nil && *r.SinceSeconds <= 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "sinceSeconds must be positive")
+	}
+	if r.TailLines != nil && *r.TailLines < 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "tailLines must not be negative")
+	}
+	return nil
+}
+
+// podLogOptions checks the container of the pod, and takes the first container if it's not specified
+func podLogOptions(pod *corev1.Pod, r *StreamContainerLogsRequest) (*corev1.PodLogOptions, error) {
+	container := r.Container
+	if container == "" {
+		if len(pod.Spec.Containers) == 0 {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "pod %s has no containers", pod.Name)
+		}
+		container = pod.Spec.Containers[0].Name
+	}
+	found := false
+	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		if c.Name == container {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "container %s not found in pod %s", container, pod.Name)
+	}
+	if r.Previous && !hasTerminated(pod, container) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"container %s in pod %s has no previous terminated instance", container, pod.Name)
+	}
+
+	options := &corev1.PodLogOptions{
+		Container:    container,
+		Follow:       r.Follow,
+		Previous:     r.Previous,
+		SinceSeconds: r.SinceSeconds,
+		TailLines:    r.TailLines,
+	}
+	if r.SinceTime != nil {
+		sinceTime := metav1.NewTime(*r.SinceTime)
+		options.SinceTime = &sinceTime
+	}
+	return options, nil
+}
+
+func hasTerminated(pod *corev1.Pod, container string) bool {
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if status.Name == container {
+			return status.LastTerminationState.Terminated != nil
+		}
+	}
+	return false
+}
+
+// pipePodLogs sends lines of the stream prefixed by the pod name, until the stream ends or ctx is done
+func pipePodLogs(ctx context.Context, podName string, stream io.Reader, logC chan<- string) {
+	send := func(line string) bool {
+		select {
+		case logC <- fmt.Sprintf("[%s] %s\n", podName, line):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	reader := bufio.NewReader(stream)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" && !send(strings.TrimRight(line, "\r\n")) {
+			return
+		}
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				send(fmt.Sprintf("failed to read logs: %v", err))
+			}
+			return
+		}
+	}
+}
+
+// getNamespaceAndRegion gets the namespace of the cluster from its env values, and the entity of its region
+func (c *controller) getNamespaceAndRegion(ctx context.Context,
+	cluster *clustermodels.Cluster) (string, *regionmodels.RegionEntity, error) {
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return "", nil, err
+	}
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return "", nil, err
+	}
+	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, tr.ChartName)
+	if err != nil {
+		return "", nil, err
+	}
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return "", nil, err
+	}
+	return envValue.Namespace, regionEntity, nil
+}
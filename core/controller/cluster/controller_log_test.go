@@ -0,0 +1,187 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	applicationmanangermock "github.com/horizoncd/horizon/mock/pkg/application/manager"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	kubeclientmock "github.com/horizoncd/horizon/mock/pkg/cluster/kubeclient"
+	clustermanagermock "github.com/horizoncd/horizon/mock/pkg/cluster/manager"
+	trmanagermock "github.com/horizoncd/horizon/mock/pkg/templaterelease/manager"
+	applicationmodel "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestStreamContainerLogs(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	clusterManagerMock := clustermanagermock.NewMockManager(mockCtl)
+	appManagerMock := applicationmanangermock.NewMockManager(mockCtl)
+	trManagerMock := trmanagermock.NewMockManager(mockCtl)
+	clusterGitRepoMock := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	kubeClientFtyMock := kubeclientmock.NewMockFactory(mockCtl)
+	db, _ := orm.NewSqliteDB("")
+	_ = db.AutoMigrate(&regionmodels.Region{}, &registrymodels.Registry{})
+	manager := managerparam.InitManager(db)
+
+	_, err := manager.RegistryManager.Create(ctx, &registrymodels.Registry{
+		Model: global.Model{ID: 1},
+	})
+	assert.Nil(t, err)
+	_, err = manager.RegionMgr.Create(ctx, &regionmodels.Region{
+		Model:      global.Model{ID: 1},
+		Name:       "hz",
+		Server:     "https://hz.k8s.com",
+		RegistryID: 1,
+	})
+	assert.Nil(t, err)
+
+	newPod := func(name, cluster string, restarted bool) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-ns",
+				Labels:    map[string]string{common.ClusterClusterLabelKey: cluster},
+			},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init"}},
+				Containers:     []corev1.Container{{Name: "app"}, {Name: "sidecar"}},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "app"}, {Name: "sidecar"}},
+			},
+		}
+		if restarted {
+			pod.Status.ContainerStatuses[0].RestartCount = 1
+			pod.Status.ContainerStatuses[0].LastTerminationState.Terminated = &corev1.ContainerStateTerminated{
+				ExitCode: 1,
+			}
+		}
+		return pod
+	}
+	clientset := fake.NewSimpleClientset(newPod("app-1", "app", false), newPod("app-2", "app", true),
+		newPod("other-1", "other", false))
+
+	c := controller{
+		clusterMgr:         clusterManagerMock,
+		applicationMgr:     appManagerMock,
+		templateReleaseMgr: trManagerMock,
+		clusterGitRepo:     clusterGitRepoMock,
+		regionMgr:          manager.RegionMgr,
+		kubeClientFty:      kubeClientFtyMock,
+	}
+	clusterManagerMock.EXPECT().GetByID(gomock.Any(), uint(1)).Return(&clustermodels.Cluster{
+		Model:      global.Model{ID: 1},
+		Name:       "app",
+		RegionName: "hz",
+	}, nil).AnyTimes()
+	appManagerMock.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+		Return(&applicationmodel.Application{Name: "app"}, nil).AnyTimes()
+	trManagerMock.EXPECT().GetByTemplateNameAndRelease(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&trmodels.TemplateRelease{ChartName: "javaapp"}, nil).AnyTimes()
+	clusterGitRepoMock.EXPECT().GetEnvValue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&gitrepo.EnvValue{Namespace: "test-ns"}, nil).AnyTimes()
+	kubeClientFtyMock.EXPECT().GetByK8SServer("https://hz.k8s.com", gomock.Any()).
+		Return(nil, &kube.Client{Basic: clientset}, nil).AnyTimes()
+
+	// logs of multiple pods are merged with pod prefixes
+	tailLines := int64(100)
+	logC, err := c.StreamContainerLogs(ctx, 1, &StreamContainerLogsRequest{
+		Pods:      []string{"app-1", "app-2"},
+		Follow:    true,
+		TailLines: &tailLines,
+	})
+	assert.Nil(t, err)
+	lines := make([]string, 0)
+	for line := range logC {
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+	assert.Equal(t, []string{"[app-1] fake logs\n", "[app-2] fake logs\n"}, lines)
+
+	logOptions := make([]*corev1.PodLogOptions, 0)
+	for _, action := range clientset.Actions() {
+		if action.GetSubresource() == "log" {
+			logOptions = append(logOptions, action.(k8stesting.GenericAction).GetValue().(*corev1.PodLogOptions))
+		}
+	}
+	assert.Equal(t, 2, len(logOptions))
+	for _, options := range logOptions {
+		assert.Equal(t, "app", options.Container)
+		assert.True(t, options.Follow)
+		assert.Equal(t, tailLines, *options.TailLines)
+	}
+
+	// previous terminated container and since time
+	clientset.ClearActions()
+	sinceTime := time.Now().Add(-time.Hour)
+	logC, err = c.StreamContainerLogs(ctx, 1, &StreamContainerLogsRequest{
+		Pods:      []string{"app-2"},
+		Container: "app",
+		Previous:  true,
+		SinceTime: &sinceTime,
+	})
+	assert.Nil(t, err)
+	for line := range logC {
+		assert.Equal(t, "[app-2] fake logs\n", line)
+	}
+	for _, action := range clientset.Actions() {
+		if action.GetSubresource() == "log" {
+			options := action.(k8stesting.GenericAction).GetValue().(*corev1.PodLogOptions)
+			assert.True(t, options.Previous)
+			assert.Equal(t, sinceTime.Unix(), options.SinceTime.Unix())
+		}
+	}
+
+	sinceSeconds := int64(60)
+	for _, r := range []*StreamContainerLogsRequest{
+		{},
+		{Pods: []string{"app-1", "app-1"}},
+		{Pods: []string{"app-1"}, SinceSeconds: &sinceSeconds, SinceTime: &sinceTime},
+		{Pods: []string{"app-1"}, Container: "nonexistent"},
+		{Pods: []string{"app-1"}, Previous: true},
+	} {
+		_, err = c.StreamContainerLogs(ctx, 1, r)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+
+	// pods not found or belonging to other clusters
+	for _, pod := range []string{"nonexistent", "other-1"} {
+		_, err = c.StreamContainerLogs(ctx, 1, &StreamContainerLogsRequest{Pods: []string{"app-1", pod}})
+		e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+		assert.True(t, ok)
+		assert.Equal(t, herrors.PodsInK8S, e.Source)
+	}
+}
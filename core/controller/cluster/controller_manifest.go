@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/render"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) GetManifestDiff(ctx context.Context, clusterID uint) ([]*render.ObjectDiff, error) {
+	const op = "cluster controller: get manifest diff"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return nil, err
+	}
+	namespace := ""
+	if envValue != nil {
+		namespace = envValue.Namespace
+	}
+
+	// like CompareConfig, master branch is compared with gitops branch
+	renderRevision := func(revision string) ([]*render.Object, error) {
+		files, err := c.clusterGitRepo.GetRenderFiles(ctx, application.Name, cluster.Name, revision)
+		if err != nil {
+			return nil, err
+		}
+		return c.manifestRenderer.Render(ctx, &render.Params{
+			TemplateName: cluster.Template,
+			ReleaseName:  cluster.Name,
+			Namespace:    namespace,
+			Files:        files,
+		})
+	}
+	master, err := renderRevision(c.clusterGitRepo.DefaultBranch())
+	if err != nil {
+		return nil, err
+	}
+	gitops, err := renderRevision(gitrepo.GitOpsBranch)
+	if err != nil {
+		return nil, err
+	}
+	return render.Diff(master, gitops)
+}
@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	rendermock "github.com/horizoncd/horizon/mock/pkg/cluster/render"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/render"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+func testGetManifestDiff(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	_ = db.AutoMigrate(&cmodels.Cluster{}, &appmodels.Application{})
+	manager := managerparam.InitManager(db)
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	renderer := rendermock.NewMockRenderer(mockCtl)
+	c := controller{
+		clusterMgr:       manager.ClusterMgr,
+		applicationMgr:   manager.ApplicationManager,
+		clusterGitRepo:   clusterGitRepo,
+		manifestRenderer: renderer,
+	}
+
+	application := &appmodels.Application{Name: "app-manifest"}
+	assert.Nil(t, db.Create(application).Error)
+	cluster := &cmodels.Cluster{ApplicationID: application.ID, Name: "app-manifest-1",
+		Template: "javaapp", TemplateRelease: "v1.0.0"}
+	assert.Nil(t, db.Create(cluster).Error)
+
+	gitopsFiles := &gitrepo.RenderFiles{Chart: &gitrepo.Chart{Name: "gitops"}}
+	masterFiles := &gitrepo.RenderFiles{Chart: &gitrepo.Chart{Name: "master"}}
+	clusterGitRepo.EXPECT().GetEnvValue(gomock.Any(), application.Name, cluster.Name, "javaapp").
+		Return(&gitrepo.EnvValue{Namespace: "ns"}, nil)
+	clusterGitRepo.EXPECT().DefaultBranch().Return("master")
+	clusterGitRepo.EXPECT().GetRenderFiles(gomock.Any(), application.Name, cluster.Name,
+		gitrepo.GitOpsBranch).Return(gitopsFiles, nil)
+	clusterGitRepo.EXPECT().GetRenderFiles(gomock.Any(), application.Name, cluster.Name,
+		"master").Return(masterFiles, nil)
+
+	deployment := &render.Object{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "ns",
+		Name: cluster.Name, Manifest: "replicas: 1\n"}
+	renderer.EXPECT().Render(gomock.Any(), &render.Params{TemplateName: "javaapp", ReleaseName: cluster.Name,
+		Namespace: "ns", Files: gitopsFiles}).Return([]*render.Object{deployment}, nil)
+	renderer.EXPECT().Render(gomock.Any(), &render.Params{TemplateName: "javaapp", ReleaseName: cluster.Name,
+		Namespace: "ns", Files: masterFiles}).Return([]*render.Object{{APIVersion: "apps/v1", Kind: "Deployment",
+		Namespace: "ns", Name: cluster.Name, Manifest: "replicas: 2\n"}}, nil)
+
+	diffs, err := c.GetManifestDiff(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(diffs))
+	assert.Equal(t, render.ActionChanged, diffs[0].Action)
+	assert.Equal(t, "Deployment", diffs[0].Kind)
+	// master is diffed against gitops
+	assert.Contains(t, diffs[0].Diff, "-replicas: 2")
+	assert.Contains(t, diffs[0].Diff, "+replicas: 1")
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/horizoncd/horizon/core/common"
@@ -45,6 +46,9 @@ func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *Pipelineru
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkDeployWindows(ctx, cluster); err != nil {
+		return nil, err
+	}
 
 	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
 	if err != nil {
@@ -121,6 +125,9 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkDeployWindows(ctx, cluster); err != nil {
+		return nil, err
+	}
 	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
 	if err != nil {
 		return nil, err
@@ -158,10 +165,14 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	}
 
 	// 2. create pipeline record
+	requireApproval, err := c.approvalSvc.RequireApproval(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
 	prCreated, err := c.pipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
 		ClusterID:        clusterID,
 		Action:           prmodels.ActionDeploy,
-		Status:           string(prmodels.StatusCreated),
+		Status:           string(initialPipelinerunStatus(requireApproval)),
 		Title:            r.Title,
 		Description:      r.Description,
 		LastConfigCommit: configCommit.Master,
@@ -170,36 +181,58 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+	if requireApproval {
+		c.recordApprovalEvent(ctx, prCreated.ID, eventmodels.PipelinerunApprovalRequested, "")
+		return &PipelinerunIDResponse{
+			PipelinerunID: prCreated.ID,
+		}, nil
+	}
+
+	if err := c.deploy(ctx, application, cluster, prCreated, diff != ""); err != nil {
+		return nil, err
+	}
+	return &PipelinerunIDResponse{
+		PipelinerunID: prCreated.ID,
+	}, nil
+}
+
+// deploy merges the config of cluster if needed and deploys it in cd system
+func (c *controller) deploy(ctx context.Context, application *amodels.Application,
+	cluster *cmodels.Cluster, prCreated *prmodels.Pipelinerun, merge bool) (err error) {
+	configCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
+	if err != nil {
+		return err
+	}
 
 	// 3. merge branch & update status
 	var commit string
-	if diff == "" {
+	if !merge {
 		// freed cluster is allowed to deploy without diff
 		commit = configCommit.Master
 	} else {
 		commit, err = c.clusterGitRepo.MergeBranch(ctx, application.Name, cluster.Name,
 			gitrepo.GitOpsBranch, c.clusterGitRepo.DefaultBranch(), &prCreated.ID)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 	if err := c.updatePipelineRunStatus(ctx, prmodels.ActionDeploy,
 		prCreated.ID, prmodels.StatusMerged, commit); err != nil {
-		return nil, err
+		return err
 	}
 
 	// 5. create cluster in cd system
 	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, tr.ChartName)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	repoInfo := c.clusterGitRepo.GetRepoInfo(ctx, application.Name, cluster.Name)
 	if err := c.cd.CreateCluster(ctx, &cd.CreateClusterParams{
@@ -210,7 +243,7 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 		RegionEntity: regionEntity,
 		Namespace:    envValue.Namespace,
 	}); err != nil {
-		return nil, err
+		return err
 	}
 
 	// 6. reset cluster status
@@ -218,7 +251,7 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 		cluster.Status = common.ClusterStatusEmpty
 		cluster, err = c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
@@ -228,10 +261,10 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 		Cluster:     cluster.Name,
 		Revision:    commit,
 	}); err != nil {
-		return nil, err
+		return err
 	}
 	if err := c.updatePipelineRunStatus(ctx, prmodels.ActionDeploy, prCreated.ID, prmodels.StatusOK, commit); err != nil {
-		return nil, err
+		return err
 	}
 
 	// 8. record event
@@ -245,9 +278,7 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
 	}
 
-	return &PipelinerunIDResponse{
-		PipelinerunID: prCreated.ID,
-	}, nil
+	return nil
 }
 
 func (c *controller) Rollback(ctx context.Context,
@@ -289,10 +320,14 @@ func (c *controller) Rollback(ctx context.Context,
 	}
 
 	// 3. create record
+	requireApproval, err := c.approvalSvc.RequireApproval(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
 	prCreated, err := c.pipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
 		ClusterID:        clusterID,
 		Action:           prmodels.ActionRollback,
-		Status:           string(prmodels.StatusCreated),
+		Status:           string(initialPipelinerunStatus(requireApproval)),
 		Title:            prmodels.ActionRollback,
 		GitURL:           pipelinerun.GitURL,
 		GitRefType:       pipelinerun.GitRefType,
@@ -306,28 +341,45 @@ func (c *controller) Rollback(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	if requireApproval {
+		c.recordApprovalEvent(ctx, prCreated.ID, eventmodels.PipelinerunApprovalRequested, "")
+		return &PipelinerunIDResponse{
+			PipelinerunID: prCreated.ID,
+		}, nil
+	}
+
+	if err := c.rollback(ctx, application, cluster, prCreated, pipelinerun); err != nil {
+		return nil, err
+	}
+	return &PipelinerunIDResponse{
+		PipelinerunID: prCreated.ID,
+	}, nil
+}
 
+// rollback rolls back the config of cluster to the pipelinerun and deploys it in cd system
+func (c *controller) rollback(ctx context.Context, application *amodels.Application,
+	cluster *cmodels.Cluster, prCreated, pipelinerun *prmodels.Pipelinerun) (err error) {
 	// Deprecated: for internal usage
 	err = c.checkAndSyncGitOpsBranch(ctx, application.Name, cluster.Name, pipelinerun.ConfigCommit)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// 4. rollback cluster config in git repo and update status
 	newConfigCommit, err := c.clusterGitRepo.Rollback(ctx, application.Name, cluster.Name, pipelinerun.ConfigCommit)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if err := c.updatePipelineRunStatus(ctx, prmodels.ActionRollback, prCreated.ID, prmodels.StatusCommitted,
 		newConfigCommit); err != nil {
-		return nil, err
+		return err
 	}
 
 	// 5. merge branch & update config commit and status
 	masterRevision, err := c.clusterGitRepo.MergeBranch(ctx, application.Name, cluster.Name,
 		gitrepo.GitOpsBranch, c.clusterGitRepo.DefaultBranch(), &prCreated.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if err := c.pipelinerunMgr.UpdateConfigCommitByID(ctx, prCreated.ID, masterRevision); err != nil {
 		log.Errorf(ctx, "UpdateConfigCommitByID error, pr = %d, commit = %s, err = %v",
@@ -335,24 +387,24 @@ func (c *controller) Rollback(ctx context.Context,
 	}
 	if err := c.updatePipelineRunStatus(ctx, prmodels.ActionRollback, prCreated.ID, prmodels.StatusMerged,
 		masterRevision); err != nil {
-		return nil, err
+		return err
 	}
 
 	// 6. update template and tags in db
 	// TODO(zhuxu): remove strong dependencies on db updates, just print an err log when updates fail
 	cluster, err = c.updateTemplateAndTagsFromFile(ctx, application, cluster)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// 7. create cluster in cd system
 	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, cluster.Template)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	repoInfo := c.clusterGitRepo.GetRepoInfo(ctx, application.Name, cluster.Name)
 	if err := c.cd.CreateCluster(ctx, &cd.CreateClusterParams{
@@ -363,7 +415,7 @@ func (c *controller) Rollback(ctx context.Context,
 		RegionEntity: regionEntity,
 		Namespace:    envValue.Namespace,
 	}); err != nil {
-		return nil, err
+		return err
 	}
 
 	// 8. reset cluster status
@@ -371,7 +423,7 @@ func (c *controller) Rollback(ctx context.Context,
 		cluster.Status = common.ClusterStatusEmpty
 		cluster, err = c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
@@ -381,11 +433,11 @@ func (c *controller) Rollback(ctx context.Context,
 		Cluster:     cluster.Name,
 		Revision:    masterRevision,
 	}); err != nil {
-		return nil, err
+		return err
 	}
 	if err := c.updatePipelineRunStatus(ctx,
 		prmodels.ActionRollback, prCreated.ID, prmodels.StatusOK, masterRevision); err != nil {
-		return nil, err
+		return err
 	}
 
 	// 10. record event
@@ -399,6 +451,136 @@ func (c *controller) Rollback(ctx context.Context,
 		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
 	}
 
+	return nil
+}
+
+func (c *controller) Promote(ctx context.Context,
+	clusterID uint, r *PromoteRequest) (_ *PipelinerunIDResponse, err error) {
+	const op = "cluster controller: promote"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. get clusters and do some validation
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	sourceCluster, err := c.clusterMgr.GetByID(ctx, r.SourceClusterID)
+	if err != nil {
+		return nil, err
+	}
+	if sourceCluster.ApplicationID != cluster.ApplicationID || sourceCluster.ID == cluster.ID {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"cluster %v cannot be promoted to cluster %v", sourceCluster.Name, cluster.Name)
+	}
+	if err := c.checkDeployWindows(ctx, cluster); err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. get pipeline output deployed in source cluster
+	sourceConfigCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, sourceCluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	sourceDiff, err := c.clusterGitRepo.CompareConfig(ctx, application.Name, sourceCluster.Name,
+		&sourceConfigCommit.Master, &sourceConfigCommit.Gitops)
+	if err != nil {
+		return nil, err
+	}
+	if sourceDiff != "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"cluster %v has changes not deployed yet", sourceCluster.Name)
+	}
+	sourcePipelinerun, err := c.pipelinerunMgr.GetLatestSuccessByClusterID(ctx, sourceCluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	if sourcePipelinerun == nil {
+		// the image may not be built by horizon
+		sourcePipelinerun = &prmodels.Pipelinerun{}
+	}
+	sourceTR, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx,
+		sourceCluster.Template, sourceCluster.TemplateRelease)
+	if err != nil {
+		return nil, err
+	}
+	output, err := c.clusterGitRepo.GetPipelineOutput(ctx, application.Name, sourceCluster.Name, sourceTR.ChartName)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. update pipeline output of cluster if it's changed
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return nil, err
+	}
+	currentOutput, err := c.clusterGitRepo.GetPipelineOutput(ctx, application.Name, cluster.Name, tr.ChartName)
+	if err != nil {
+		// it's fine that the cluster has never been built
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok &&
+			perror.Cause(err) != herrors.ErrPipelineOutputEmpty {
+			return nil, err
+		}
+	}
+	if !reflect.DeepEqual(currentOutput, output) {
+		if _, err := c.clusterGitRepo.UpdatePipelineOutput(ctx, application.Name, cluster.Name,
+			tr.ChartName, output); err != nil {
+			return nil, err
+		}
+	}
+	configCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := c.clusterGitRepo.CompareConfig(ctx, application.Name, cluster.Name,
+		&configCommit.Master, &configCommit.Gitops)
+	if err != nil {
+		return nil, err
+	}
+	if diff == "" && cluster.Status != common.ClusterStatusFreed {
+		return nil, perror.Wrap(herrors.ErrClusterNoChange, "there is no change to promote")
+	}
+
+	// 4. create pipeline record
+	requireApproval, err := c.approvalSvc.RequireApproval(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	title := r.Title
+	if title == "" {
+		title = fmt.Sprintf("promote from %v", sourceCluster.Name)
+	}
+	prCreated, err := c.pipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID:        clusterID,
+		Action:           prmodels.ActionDeploy,
+		Status:           string(initialPipelinerunStatus(requireApproval)),
+		Title:            title,
+		Description:      r.Description,
+		GitURL:           sourcePipelinerun.GitURL,
+		GitRefType:       sourcePipelinerun.GitRefType,
+		GitRef:           sourcePipelinerun.GitRef,
+		GitCommit:        sourcePipelinerun.GitCommit,
+		ImageURL:         sourcePipelinerun.ImageURL,
+		LastConfigCommit: configCommit.Master,
+		ConfigCommit:     configCommit.Gitops,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if requireApproval {
+		c.recordApprovalEvent(ctx, prCreated.ID, eventmodels.PipelinerunApprovalRequested, "")
+		return &PipelinerunIDResponse{
+			PipelinerunID: prCreated.ID,
+		}, nil
+	}
+
+	// 5. merge branch and deploy
+	if err := c.deploy(ctx, application, cluster, prCreated, diff != ""); err != nil {
+		return nil, err
+	}
 	return &PipelinerunIDResponse{
 		PipelinerunID: prCreated.ID,
 	}, nil
@@ -453,6 +635,45 @@ func (c *controller) ExecuteAction(ctx context.Context, clusterID uint,
 	})
 }
 
+func (c *controller) TriggerCronJob(ctx context.Context, clusterID uint, cronJob string) (*cd.CronJobRun, error) {
+	const op = "cluster controller: trigger cronjob"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	params, err := c.cronJobParams(ctx, clusterID, cronJob)
+	if err != nil {
+		return nil, err
+	}
+	return c.cd.TriggerCronJob(ctx, params)
+}
+
+func (c *controller) ListCronJobRuns(ctx context.Context, clusterID uint, cronJob string) ([]*cd.CronJobRun, error) {
+	const op = "cluster controller: list cronjob runs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	params, err := c.cronJobParams(ctx, clusterID, cronJob)
+	if err != nil {
+		return nil, err
+	}
+	return c.cd.ListCronJobRuns(ctx, params)
+}
+
+func (c *controller) cronJobParams(ctx context.Context, clusterID uint, cronJob string) (*cd.CronJobParams, error) {
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, err
+	}
+	return &cd.CronJobParams{
+		Environment:  cluster.EnvironmentName,
+		Cluster:      cluster.Name,
+		RegionEntity: regionEntity,
+		CronJob:      cronJob,
+	}, nil
+}
+
 // onlineCommand the location of online.sh in pod is /home/appops/.probe/online-once.sh
 var onlineCommands = []string{"bash", "-c", `
 export ONLINE_SHELL="/home/appops/.probe/online-once.sh"
@@ -829,3 +1050,26 @@ func (c *controller) syncGitOpsBranch(ctx context.Context, application, cluster
 	}
 	return nil
 }
+
+// checkDeployWindows checks whether the cluster can be deployed now according to
+// the deploy windows of its environment, admins are allowed to deploy at any time
+func (c *controller) checkDeployWindows(ctx context.Context, cluster *cmodels.Cluster) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if currentUser.IsAdmin() {
+		return nil
+	}
+
+	env, err := c.envMgr.GetByName(ctx, cluster.EnvironmentName)
+	if err != nil {
+		return err
+	}
+	if !env.DeployWindows.Allowed(time.Now()) {
+		windows, _ := json.Marshal(env.DeployWindows)
+		return perror.Wrapf(herrors.ErrDeployWindowClosed,
+			"clusters in environment %s can only be deployed in windows %s", env.Name, string(windows))
+	}
+	return nil
+}
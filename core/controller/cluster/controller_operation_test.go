@@ -0,0 +1,166 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	approvalservice "github.com/horizoncd/horizon/pkg/approval/service"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testCheckDeployWindows(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	_ = db.AutoMigrate(&envmodels.Environment{})
+	manager := managerparam.InitManager(db)
+	c := controller{
+		envMgr: manager.EnvMgr,
+	}
+
+	now := time.Now()
+	_, err := manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{
+		Name: "online",
+		DeployWindows: envmodels.DeployWindows{
+			{
+				// yesterday is never today
+				Weekdays:  []time.Weekday{now.Add(-24 * time.Hour).Weekday()},
+				StartTime: "00:00",
+				EndTime:   "23:59",
+			},
+		},
+	})
+	assert.Nil(t, err)
+	_, err = manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{
+		Name: "test",
+	})
+	assert.Nil(t, err)
+
+	err = c.checkDeployWindows(ctx, &cmodels.Cluster{EnvironmentName: "online"})
+	assert.Equal(t, herrors.ErrDeployWindowClosed, perror.Cause(err))
+	assert.Nil(t, c.checkDeployWindows(ctx, &cmodels.Cluster{EnvironmentName: "test"}))
+
+	// admins are allowed to deploy at any time
+	// nolint
+	adminCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "admin",
+		ID:    uint(2),
+		Admin: true,
+	})
+	assert.Nil(t, c.checkDeployWindows(adminCtx, &cmodels.Cluster{EnvironmentName: "online"}))
+}
+
+func testPromote(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	_ = db.AutoMigrate(&envmodels.Environment{}, &cmodels.Cluster{}, &appmodels.Application{},
+		&trmodels.TemplateRelease{}, &prmodels.Pipelinerun{}, &eventmodels.Event{})
+	manager := managerparam.InitManager(db)
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	c := controller{
+		clusterMgr:         manager.ClusterMgr,
+		clusterGitRepo:     clusterGitRepo,
+		applicationMgr:     manager.ApplicationManager,
+		templateReleaseMgr: manager.TemplateReleaseManager,
+		envMgr:             manager.EnvMgr,
+		pipelinerunMgr:     manager.PipelinerunMgr,
+		eventMgr:           manager.EventManager,
+		approvalSvc:        approvalservice.NewService(nil, manager),
+	}
+
+	_, err := manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{Name: "test"})
+	assert.Nil(t, err)
+	_, err = manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{Name: "online", Protected: true})
+	assert.Nil(t, err)
+	application := &appmodels.Application{Name: "app"}
+	assert.Nil(t, db.Create(application).Error)
+	_, err = manager.TemplateReleaseManager.Create(ctx, &trmodels.TemplateRelease{
+		TemplateName: "javaapp",
+		Name:         "v1.0.0",
+		ChartName:    "javaapp",
+	})
+	assert.Nil(t, err)
+	source := &cmodels.Cluster{ApplicationID: application.ID, Name: "app-test", EnvironmentName: "test",
+		Template: "javaapp", TemplateRelease: "v1.0.0"}
+	target := &cmodels.Cluster{ApplicationID: application.ID, Name: "app-online", EnvironmentName: "online",
+		Template: "javaapp", TemplateRelease: "v1.0.0"}
+	other := &cmodels.Cluster{ApplicationID: application.ID + 1, Name: "other", EnvironmentName: "test"}
+	for _, cluster := range []*cmodels.Cluster{source, target, other} {
+		assert.Nil(t, db.Create(cluster).Error)
+	}
+	sourcePR, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: source.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusOK),
+		GitCommit: "b6a2e3f",
+		ImageURL:  "harbor.com/app/app-test:b6a2e3f",
+	})
+	assert.Nil(t, err)
+
+	// only clusters of the same application can be promoted
+	_, err = c.Promote(ctx, target.ID, &PromoteRequest{SourceClusterID: other.ID})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// source cluster with undeployed changes can not be promoted
+	clusterGitRepo.EXPECT().GetConfigCommit(ctx, "app", "app-test").Return(&gitrepo.ClusterCommit{
+		Master: "master", Gitops: "gitops",
+	}, nil).AnyTimes()
+	clusterGitRepo.EXPECT().CompareConfig(ctx, "app", "app-test", gomock.Any(), gomock.Any()).
+		Return("diff", nil).Times(1)
+	_, err = c.Promote(ctx, target.ID, &PromoteRequest{SourceClusterID: source.ID})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// pipeline output is copied to the target cluster, and waits for approval in protected environment
+	output := map[string]interface{}{"image": sourcePR.ImageURL}
+	clusterGitRepo.EXPECT().CompareConfig(ctx, "app", "app-test", gomock.Any(), gomock.Any()).
+		Return("", nil).Times(1)
+	clusterGitRepo.EXPECT().GetPipelineOutput(ctx, "app", "app-test", "javaapp").Return(output, nil)
+	clusterGitRepo.EXPECT().GetPipelineOutput(ctx, "app", "app-online", "javaapp").
+		Return(nil, herrors.ErrPipelineOutputEmpty)
+	clusterGitRepo.EXPECT().UpdatePipelineOutput(ctx, "app", "app-online", "javaapp", output).
+		Return("gitops-online", nil)
+	clusterGitRepo.EXPECT().GetConfigCommit(ctx, "app", "app-online").Return(&gitrepo.ClusterCommit{
+		Master: "master-online", Gitops: "gitops-online",
+	}, nil)
+	clusterGitRepo.EXPECT().CompareConfig(ctx, "app", "app-online", gomock.Any(), gomock.Any()).
+		Return("diff", nil)
+	resp, err := c.Promote(ctx, target.ID, &PromoteRequest{SourceClusterID: source.ID})
+	assert.Nil(t, err)
+
+	pr, err := manager.PipelinerunMgr.GetByID(ctx, resp.PipelinerunID)
+	assert.Nil(t, err)
+	assert.Equal(t, target.ID, pr.ClusterID)
+	assert.Equal(t, string(prmodels.StatusPendingApproval), pr.Status)
+	assert.Equal(t, "promote from app-test", pr.Title)
+	assert.Equal(t, sourcePR.ImageURL, pr.ImageURL)
+	assert.Equal(t, sourcePR.GitCommit, pr.GitCommit)
+	assert.Equal(t, "gitops-online", pr.ConfigCommit)
+}
@@ -65,7 +65,8 @@ func (c *controller) GetClusterBuildStatus(ctx context.Context, clusterID uint)
 	}
 
 	if latestPipelinerun == nil ||
-		latestPipelinerun.Action != prmodels.ActionBuildDeploy {
+		latestPipelinerun.Action != prmodels.ActionBuildDeploy ||
+		latestPipelinerun.Status == string(prmodels.StatusPendingApproval) {
 		resp.RunningTask = &RunningTask{
 			Task: _taskNone,
 		}
@@ -106,7 +107,8 @@ func (c *controller) GetClusterStatus(ctx context.Context, clusterID uint) (_ *G
 	}
 
 	if latestPipelinerun == nil ||
-		latestPipelinerun.Action != prmodels.ActionBuildDeploy {
+		latestPipelinerun.Action != prmodels.ActionBuildDeploy ||
+		latestPipelinerun.Status == string(prmodels.StatusPendingApproval) {
 		resp.RunningTask = &RunningTask{
 			Task: _taskNone,
 		}
@@ -158,7 +160,8 @@ func (c *controller) GetClusterStatus(ctx context.Context, clusterID uint) (_ *G
 		if clusterState.Status == health.HealthStatusHealthy &&
 			latestPipelinerun != nil &&
 			latestPipelinerun.Status != string(prmodels.StatusFailed) &&
-			latestPipelinerun.Status != string(prmodels.StatusCancelled) {
+			latestPipelinerun.Status != string(prmodels.StatusCancelled) &&
+			latestPipelinerun.Status != string(prmodels.StatusPendingApproval) {
 			var (
 				image       string
 				restartTime time.Time
@@ -340,28 +343,14 @@ func (c *controller) GetContainerLog(ctx context.Context, clusterID uint, podNam
 		return nil, err
 	}
 
-	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
-	if err != nil {
-		return nil, err
-	}
-
-	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
-	if err != nil {
-		return nil, err
-	}
-	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, tr.ChartName)
-	if err != nil {
-		return nil, err
-	}
-
-	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	namespace, regionEntity, err := c.getNamespaceAndRegion(ctx, cluster)
 	if err != nil {
 		return nil, err
 	}
 
 	param := cd.GetContainerLogParams{
 		RegionEntity: regionEntity,
-		Namespace:    envValue.Namespace,
+		Namespace:    namespace,
 		Environment:  cluster.EnvironmentName,
 		Cluster:      cluster.Name,
 		Pod:          podName,
@@ -534,6 +523,8 @@ func (c *controller) GetStep(ctx context.Context, clusterID uint) (resp *GetStep
 			Replicas:     steps.Replicas,
 			ManualPaused: steps.ManualPaused,
 			AutoPromote:  steps.AutoPromote,
+			StepPaused:   steps.StepPaused,
+			StepPausedAt: steps.StepPausedAt,
 		}
 	} else {
 		resp = &GetStepResponse{
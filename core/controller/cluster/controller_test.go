@@ -50,6 +50,7 @@ import (
 	trschemamock "github.com/horizoncd/horizon/mock/pkg/templaterelease/schema"
 	appgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	approvalservice "github.com/horizoncd/horizon/pkg/approval/service"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
@@ -502,9 +503,16 @@ func TestAll(t *testing.T) {
 	t.Run("TestPinyin", testPinyin)
 	t.Run("TestListClusterByNameFuzzily", testListClusterByNameFuzzily)
 	t.Run("TestListUserClustersByNameFuzzily", testListUserClustersByNameFuzzily)
+	t.Run("TestPrivateClusterInvisible", testPrivateClusterInvisible)
 	t.Run("TestListClusterWithExpiry", testListClusterWithExpiry)
 	t.Run("TestControllerFreeOrDeleteClusterFailed", testControllerFreeOrDeleteClusterFailed)
 	t.Run("TestGetClusterStatusV2", testGetClusterStatusV2)
+	t.Run("TestCheckDeployWindows", testCheckDeployWindows)
+	t.Run("TestDecidePipelinerun", testDecidePipelinerun)
+	t.Run("TestPromote", testPromote)
+	t.Run("TestImageTags", testImageTags)
+	t.Run("TestUpgradeTemplate", testUpgradeTemplate)
+	t.Run("TestGetManifestDiff", testGetManifestDiff)
 }
 
 // nolint
@@ -627,6 +635,7 @@ func test(t *testing.T) {
 	assert.NotNil(t, env)
 
 	c = &controller{
+		visibilityChecker:    manager.VisibilityChecker,
 		clusterMgr:           manager.ClusterMgr,
 		clusterGitRepo:       clusterGitRepo,
 		commitGetter:         commitGetter,
@@ -649,6 +658,7 @@ func test(t *testing.T) {
 		tagMgr:               tagManager,
 		applicationGitRepo:   applicationGitRepo,
 		eventMgr:             manager.EventManager,
+		approvalSvc:          approvalservice.NewService(nil, manager),
 		tokenSvc: tokenservice.NewService(manager, tokenconfig.Config{
 			JwtSigningKey:         "horizon",
 			CallbackTokenExpireIn: time.Hour * 2,
@@ -1308,6 +1318,7 @@ func testV2(t *testing.T) {
 	assert.NotNil(t, tr)
 
 	c = &controller{
+		visibilityChecker:    manager.VisibilityChecker,
 		clusterMgr:           manager.ClusterMgr,
 		clusterGitRepo:       clusterGitRepo,
 		applicationMgr:       appMgr,
@@ -1513,6 +1524,7 @@ func testUpgrade(t *testing.T) {
 	}
 
 	c = &controller{
+		visibilityChecker:     manager.VisibilityChecker,
 		clusterMgr:            manager.ClusterMgr,
 		clusterGitRepo:        clusterGitRepo,
 		applicationMgr:        appMgr,
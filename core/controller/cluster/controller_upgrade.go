@@ -0,0 +1,305 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	amodels "github.com/horizoncd/horizon/pkg/application/models"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) UpgradeTemplate(ctx context.Context, clusterID uint,
+	r *UpgradeTemplateRequest, dryRun bool) (*UpgradeTemplateResponse, error) {
+	const op = "cluster controller: upgrade template"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	targetRelease, err := c.getUpgradeTargetRelease(ctx, cluster, r)
+	if err != nil {
+		return nil, err
+	}
+	return c.upgradeTemplate(ctx, cluster, targetRelease, dryRun)
+}
+
+func (c *controller) BulkUpgradeTemplate(ctx context.Context, releaseID uint,
+	r *BulkUpgradeTemplateRequest, dryRun bool) ([]*UpgradeTemplateResponse, error) {
+	const op = "cluster controller: bulk upgrade template"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	release, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
+	if err != nil {
+		return nil, err
+	}
+	if r.Template == "" {
+		r.Template = release.TemplateName
+	}
+	if err := checkTemplateSwitch(ctx, release.TemplateName, r.Template); err != nil {
+		return nil, err
+	}
+	if r.Template == release.TemplateName && r.Release == release.Name {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"target release is the same as release %s-%s", release.TemplateName, release.Name)
+	}
+	targetRelease, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, r.Template, r.Release)
+	if err != nil {
+		return nil, err
+	}
+
+	_, clusters, err := c.clusterMgr.List(ctx, &q.Query{
+		Keywords: q.KeyWords{
+			common.ClusterQueryByTemplate: release.TemplateName,
+			common.ClusterQueryByRelease:  release.Name,
+		},
+		WithoutPagination: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	clusterIDs := make(map[uint]bool, len(r.ClusterIDs))
+	for _, id := range r.ClusterIDs {
+		clusterIDs[id] = true
+	}
+
+	// clusters are upgraded one by one, failure of one cluster does not stop the others
+	results := make([]*UpgradeTemplateResponse, 0, len(clusters))
+	for _, cluster := range clusters {
+		if len(clusterIDs) > 0 && !clusterIDs[cluster.ID] {
+			continue
+		}
+		// permission on the release does not imply permission on clusters of the release
+		var result *UpgradeTemplateResponse
+		err := rbac.AuthorizeAction(ctx, c.authorizer, common.ResourceCluster, cluster.ID, "upgrade")
+		if err == nil {
+			result, err = c.upgradeTemplate(ctx, cluster.Cluster, targetRelease, dryRun)
+		}
+		if err != nil {
+			log.Warningf(ctx, "failed to upgrade cluster %s to %s-%s, err: %v",
+				cluster.Name, targetRelease.TemplateName, targetRelease.Name, err)
+			result = &UpgradeTemplateResponse{
+				ClusterID: cluster.ID,
+				Cluster:   cluster.Name,
+				From: &codemodels.TemplateInfo{
+					Name:    cluster.Template,
+					Release: cluster.TemplateRelease,
+				},
+				To: &codemodels.TemplateInfo{
+					Name:    targetRelease.TemplateName,
+					Release: targetRelease.Name,
+				},
+				Error: err.Error(),
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (c *controller) getUpgradeTargetRelease(ctx context.Context,
+	cluster *cmodels.Cluster, r *UpgradeTemplateRequest) (*trmodels.TemplateRelease, error) {
+	template := r.Template
+	if template == "" {
+		template = cluster.Template
+	}
+	if r.Release == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "release of target template is empty")
+	}
+	if template == cluster.Template && r.Release == cluster.TemplateRelease {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"cluster %s is already on %s-%s", cluster.Name, template, r.Release)
+	}
+	if err := checkTemplateSwitch(ctx, cluster.Template, template); err != nil {
+		return nil, err
+	}
+	return c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, template, r.Release)
+}
+
+// checkTemplateSwitch checks whether current user is allowed to upgrade clusters from one template to another,
+// only admin is allowed to switch template, others can only upgrade clusters within the template
+func checkTemplateSwitch(ctx context.Context, from, to string) error {
+	if from == to {
+		return nil
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !currentUser.IsAdmin() {
+		return perror.Wrapf(herrors.ErrForbidden,
+			"only admin is allowed to upgrade clusters of %s to another template %s", from, to)
+	}
+	return nil
+}
+
+// upgradeTemplate migrates values of cluster with migrations shipped in target release,
+// validates them by schemas of target release, and then commits them to gitops repo if not dryRun
+func (c *controller) upgradeTemplate(ctx context.Context, cluster *cmodels.Cluster,
+	targetRelease *trmodels.TemplateRelease, dryRun bool) (*UpgradeTemplateResponse, error) {
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. get values from gitops repo, only clusters of v2 are supported
+	files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return nil, err
+	}
+	if files.Manifest == nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "git repo %s not support v2 interface",
+			cluster.Name)
+	}
+
+	// 2. migrate values
+	migrations, err := c.migrationGetter.GetMigrations(ctx, targetRelease.TemplateName, targetRelease.Name)
+	if err != nil {
+		return nil, err
+	}
+	templateConfig, buildConfig, err := migrations.Apply(cluster.TemplateRelease,
+		files.ApplicationJSONBlob, files.PipelineJSONBlob)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. validate values by schemas of target release
+	targetTemplateInfo := &codemodels.TemplateInfo{
+		Name:    targetRelease.TemplateName,
+		Release: targetRelease.Name,
+	}
+	renderValues, err := c.getRenderValueFromTag(ctx, cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	info := BuildTemplateInfo{
+		BuildConfig:    buildConfig,
+		TemplateInfo:   targetTemplateInfo,
+		TemplateConfig: templateConfig,
+	}
+	if err := info.Validate(ctx, c.templateSchemaGetter, renderValues, c.buildSchema); err != nil {
+		return nil, err
+	}
+
+	diff, err := diffValues(files.ApplicationJSONBlob, files.PipelineJSONBlob, templateConfig, buildConfig)
+	if err != nil {
+		return nil, err
+	}
+	resp := &UpgradeTemplateResponse{
+		ClusterID: cluster.ID,
+		Cluster:   cluster.Name,
+		From: &codemodels.TemplateInfo{
+			Name:    cluster.Template,
+			Release: cluster.TemplateRelease,
+		},
+		To:             targetTemplateInfo,
+		BuildConfig:    buildConfig,
+		TemplateConfig: templateConfig,
+		Diff:           diff,
+	}
+	if dryRun {
+		return resp, nil
+	}
+
+	// 4. commit to gitops repo
+	if err := c.commitUpgrade(ctx, application, cluster, targetRelease, templateConfig, buildConfig); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *controller) commitUpgrade(ctx context.Context, application *amodels.Application,
+	cluster *cmodels.Cluster, targetRelease *trmodels.TemplateRelease,
+	templateConfig, buildConfig map[string]interface{}) error {
+	if err := c.clusterGitRepo.UpdateCluster(ctx, &gitrepo.UpdateClusterParams{
+		BaseParams: &gitrepo.BaseParams{
+			ClusterID:           cluster.ID,
+			Cluster:             cluster.Name,
+			PipelineJSONBlob:    buildConfig,
+			ApplicationJSONBlob: templateConfig,
+			TemplateRelease:     targetRelease,
+			Application:         application,
+			Environment:         cluster.EnvironmentName,
+			Version:             common.MetaVersion2,
+		},
+		SourceTemplate: cluster.Template,
+	}); err != nil {
+		return err
+	}
+
+	if _, err := c.eventMgr.CreateEvent(ctx, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			EventType:    eventmodels.ClusterUpdated,
+			ResourceID:   cluster.ID,
+		},
+	}); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+
+	cluster.Template = targetRelease.TemplateName
+	cluster.TemplateRelease = targetRelease.Name
+	_, err := c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
+	return err
+}
+
+// diffValues returns unified diff of application and pipeline values in yaml
+func diffValues(application, pipeline, newApplication, newPipeline map[string]interface{}) (string, error) {
+	var diff string
+	for _, file := range []struct {
+		name     string
+		from, to map[string]interface{}
+	}{
+		{common.GitopsFileApplication, application, newApplication},
+		{common.GitopsFilePipeline, pipeline, newPipeline},
+	} {
+		from, err := yaml.Marshal(file.from)
+		if err != nil {
+			return "", perror.Wrapf(herrors.ErrParamInvalid, "failed to marshal values: %v", err)
+		}
+		to, err := yaml.Marshal(file.to)
+		if err != nil {
+			return "", perror.Wrapf(herrors.ErrParamInvalid, "failed to marshal values: %v", err)
+		}
+		d, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(from)),
+			B:        difflib.SplitLines(string(to)),
+			FromFile: fmt.Sprintf("a/%s", file.name),
+			ToFile:   fmt.Sprintf("b/%s", file.name),
+			Context:  3,
+		})
+		if err != nil {
+			return "", perror.Wrapf(herrors.ErrParamInvalid, "failed to diff values: %v", err)
+		}
+		diff += d
+	}
+	return diff, nil
+}
@@ -0,0 +1,199 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	rbacmock "github.com/horizoncd/horizon/mock/pkg/rbac"
+	migrationmock "github.com/horizoncd/horizon/mock/pkg/templaterelease/migration"
+	trschemamock "github.com/horizoncd/horizon/mock/pkg/templaterelease/schema"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	tagmodels "github.com/horizoncd/horizon/pkg/templateschematag/models"
+)
+
+func testUpgradeTemplate(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	_ = db.AutoMigrate(&cmodels.Cluster{}, &appmodels.Application{}, &regionmodels.Region{},
+		&trmodels.TemplateRelease{}, &tagmodels.ClusterTemplateSchemaTag{}, &eventmodels.Event{})
+	manager := managerparam.InitManager(db)
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	templateSchemaGetter := trschemamock.NewMockGetter(mockCtl)
+	migrationGetter := migrationmock.NewMockGetter(mockCtl)
+	authorizer := rbacmock.NewMockAuthorizer(mockCtl)
+	c := controller{
+		authorizer:           authorizer,
+		clusterMgr:           manager.ClusterMgr,
+		applicationMgr:       manager.ApplicationManager,
+		templateReleaseMgr:   manager.TemplateReleaseManager,
+		schemaTagManager:     manager.ClusterSchemaTagMgr,
+		eventMgr:             manager.EventManager,
+		clusterGitRepo:       clusterGitRepo,
+		templateSchemaGetter: templateSchemaGetter,
+		migrationGetter:      migrationGetter,
+	}
+
+	assert.Nil(t, db.Create(&regionmodels.Region{Name: "hz"}).Error)
+	application := &appmodels.Application{Name: "app-upgrade"}
+	assert.Nil(t, db.Create(application).Error)
+	release, err := manager.TemplateReleaseManager.Create(ctx, &trmodels.TemplateRelease{
+		TemplateName: "javaapp", Name: "v1.0.0", ChartName: "javaapp", ChartVersion: "v1.0.0-5e5193b3"})
+	assert.Nil(t, err)
+	_, err = manager.TemplateReleaseManager.Create(ctx, &trmodels.TemplateRelease{
+		TemplateName: "javaapp", Name: "v2.0.0", ChartName: "javaapp", ChartVersion: "v2.0.0-33da3204"})
+	assert.Nil(t, err)
+	var clusters []*cmodels.Cluster
+	for _, name := range []string{"app-upgrade-1", "app-upgrade-2", "app-upgrade-3"} {
+		cluster := &cmodels.Cluster{ApplicationID: application.ID, Name: name, RegionName: "hz",
+			EnvironmentName: "test", Template: "javaapp", TemplateRelease: "v1.0.0"}
+		assert.Nil(t, db.Create(cluster).Error)
+		clusters = append(clusters, cluster)
+	}
+
+	migrations, err := migration.Parse([]byte(`migrations:
+- fromReleases: [v1.0.0]
+  application:
+  - op: rename
+    from: app.spec.replica
+    path: app.spec.replicas
+`))
+	assert.Nil(t, err)
+	migrationGetter.EXPECT().GetMigrations(gomock.Any(), "javaapp", "v2.0.0").Return(migrations, nil).AnyTimes()
+	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), "javaapp", "v2.0.0", gomock.Any()).
+		Return(&trschema.Schemas{
+			Application: &trschema.Schema{
+				JSONSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"app": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"spec": map[string]interface{}{
+									"type":     "object",
+									"required": []interface{}{"replicas"},
+								},
+							},
+						},
+					},
+				},
+			},
+			Pipeline: &trschema.Schema{},
+		}, nil).AnyTimes()
+	clusterFiles := func() *gitrepo.ClusterFiles {
+		return &gitrepo.ClusterFiles{
+			PipelineJSONBlob: map[string]interface{}{"buildxml": "<project/>"},
+			ApplicationJSONBlob: map[string]interface{}{
+				"app": map[string]interface{}{"spec": map[string]interface{}{"replica": 1}},
+			},
+			Manifest: map[string]interface{}{"version": "0.0.2"},
+		}
+	}
+
+	// dry run
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), application.Name, "app-upgrade-1", "javaapp").
+		Return(clusterFiles(), nil)
+	resp, err := c.UpgradeTemplate(ctx, clusters[0].ID, &UpgradeTemplateRequest{Release: "v2.0.0"}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.0", resp.From.Release)
+	assert.Equal(t, "v2.0.0", resp.To.Release)
+	assert.Equal(t, map[string]interface{}{
+		"app": map[string]interface{}{"spec": map[string]interface{}{"replicas": 1}},
+	}, resp.TemplateConfig)
+	assert.Contains(t, resp.Diff, "-    replica: 1\n")
+	assert.Contains(t, resp.Diff, "+    replicas: 1\n")
+	assert.NotContains(t, resp.Diff, "pipeline/pipeline.yaml")
+
+	_, err = c.UpgradeTemplate(ctx, clusters[0].ID, &UpgradeTemplateRequest{Release: "v1.0.0"}, true)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// values not valid for target release
+	invalidFiles := clusterFiles()
+	invalidFiles.ApplicationJSONBlob = map[string]interface{}{"app": map[string]interface{}{
+		"spec": map[string]interface{}{"instances": 1}}}
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), application.Name, "app-upgrade-1", "javaapp").
+		Return(invalidFiles, nil)
+	_, err = c.UpgradeTemplate(ctx, clusters[0].ID, &UpgradeTemplateRequest{Release: "v2.0.0"}, true)
+	assert.NotNil(t, err)
+
+	// bulk upgrade, cluster not supporting v2 or not permitted to upgrade fails alone
+	authorizer.EXPECT().Authorize(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, attr auth.Attributes) (auth.Decision, string, error) {
+			assert.Equal(t, "clusters", attr.GetResource())
+			assert.Equal(t, "upgrade", attr.GetSubResource())
+			if attr.GetName() == strconv.Itoa(int(clusters[2].ID)) {
+				return auth.DecisionDeny, "guest", nil
+			}
+			return auth.DecisionAllow, "", nil
+		}).Times(3)
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), application.Name, "app-upgrade-1", "javaapp").
+		Return(clusterFiles(), nil)
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), application.Name, "app-upgrade-2", "javaapp").
+		Return(&gitrepo.ClusterFiles{}, nil)
+	clusterGitRepo.EXPECT().UpdateCluster(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, params *gitrepo.UpdateClusterParams) error {
+			assert.Equal(t, "app-upgrade-1", params.Cluster)
+			assert.Equal(t, "v2.0.0", params.TemplateRelease.Name)
+			assert.Equal(t, "javaapp", params.SourceTemplate)
+			assert.Equal(t, map[string]interface{}{"buildxml": "<project/>"}, params.PipelineJSONBlob)
+			return nil
+		})
+	results, err := c.BulkUpgradeTemplate(ctx, release.ID,
+		&BulkUpgradeTemplateRequest{UpgradeTemplateRequest: UpgradeTemplateRequest{Release: "v2.0.0"}}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(results))
+	for _, result := range results {
+		if result.ClusterID == clusters[0].ID {
+			assert.Empty(t, result.Error)
+		} else {
+			assert.NotEmpty(t, result.Error)
+		}
+	}
+	cluster, err := manager.ClusterMgr.GetByID(ctx, clusters[0].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "v2.0.0", cluster.TemplateRelease)
+	cluster, err = manager.ClusterMgr.GetByID(ctx, clusters[1].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.0", cluster.TemplateRelease)
+	cluster, err = manager.ClusterMgr.GetByID(ctx, clusters[2].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.0", cluster.TemplateRelease)
+
+	// only admin is allowed to switch clusters to another template
+	_, err = c.BulkUpgradeTemplate(ctx, release.ID, &BulkUpgradeTemplateRequest{
+		UpgradeTemplateRequest: UpgradeTemplateRequest{Template: "tomcat", Release: "v1.0.0"}}, true)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = c.UpgradeTemplate(ctx, clusters[1].ID,
+		&UpgradeTemplateRequest{Template: "tomcat", Release: "v1.0.0"}, true)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+}
@@ -67,6 +67,13 @@ type RollbackRequest struct {
 	PipelinerunID uint `json:"pipelinerunID"`
 }
 
+type PromoteRequest struct {
+	// SourceClusterID is the cluster whose image and config are promoted
+	SourceClusterID uint   `json:"sourceClusterID"`
+	Title           string `json:"title"`
+	Description     string `json:"description"`
+}
+
 type BatchResponse map[string]OperationResult
 type OperationResult struct {
 	// Result bool value indicates whether the result is successfully
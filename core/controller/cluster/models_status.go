@@ -15,6 +15,8 @@
 package cluster
 
 import (
+	"time"
+
 	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	"github.com/horizoncd/horizon/pkg/grafana"
 	corev1 "k8s.io/api/core/v1"
@@ -84,4 +86,20 @@ type GetStepResponse struct {
 	Replicas     []int `json:"replicas"`
 	ManualPaused bool  `json:"manualPaused"`
 	AutoPromote  bool  `json:"autoPromote"`
+	StepPaused   bool  `json:"stepPaused"`
+	// StepPausedAt is the time when the cluster is paused by the pause step
+	StepPausedAt *time.Time `json:"stepPausedAt,omitempty"`
+}
+
+type StreamContainerLogsRequest struct {
+	Pods      []string
+	Container string
+	// Follow keeps streaming new logs until the request is canceled
+	Follow    bool
+	TailLines *int64
+	// SinceSeconds and SinceTime are exclusive
+	SinceSeconds *int64
+	SinceTime    *time.Time
+	// Previous streams logs of the last terminated container
+	Previous bool
 }
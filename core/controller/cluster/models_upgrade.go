@@ -0,0 +1,44 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+)
+
+type UpgradeTemplateRequest struct {
+	// Template is the name of target template, defaults to template of the cluster
+	Template string `json:"template"`
+	Release  string `json:"release"`
+}
+
+type BulkUpgradeTemplateRequest struct {
+	UpgradeTemplateRequest
+	// ClusterIDs limits clusters to upgrade, all clusters of the release are upgraded if empty
+	ClusterIDs []uint `json:"clusterIDs"`
+}
+
+type UpgradeTemplateResponse struct {
+	ClusterID      uint                     `json:"clusterID"`
+	Cluster        string                   `json:"cluster"`
+	From           *codemodels.TemplateInfo `json:"from"`
+	To             *codemodels.TemplateInfo `json:"to"`
+	BuildConfig    map[string]interface{}   `json:"buildConfig,omitempty"`
+	TemplateConfig map[string]interface{}   `json:"templateConfig,omitempty"`
+	// Diff is the unified diff of values before and after upgrading
+	Diff string `json:"diff"`
+	// Error is the reason why cluster failed to upgrade, only used in bulk upgrading
+	Error string `json:"error,omitempty"`
+}
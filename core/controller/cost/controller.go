@@ -0,0 +1,283 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cost"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	// _maxTimeRange limits the time range of report, as the queries are evaluated over the whole range
+	_maxTimeRange = 90 * 24 * time.Hour
+	// _concurrency is the number of regions queried concurrently
+	_concurrency = 8
+)
+
+type Controller interface {
+	// GetClusterReport reports resource usage and cost of cluster
+	GetClusterReport(ctx context.Context, clusterID uint, r *TimeRange) (*Report, error)
+	// GetApplicationReport reports resource usage and cost of all clusters of application
+	GetApplicationReport(ctx context.Context, applicationID uint, r *TimeRange) (*Report, error)
+	// GetGroupReport reports resource usage and cost of applications under the group and its subgroups
+	GetGroupReport(ctx context.Context, groupID uint, r *TimeRange) (*Report, error)
+}
+
+type controller struct {
+	reporter       cost.Reporter
+	clusterMgr     clustermanager.Manager
+	applicationMgr appmanager.Manager
+	groupMgr       groupmanager.Manager
+	regionMgr      regionmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(reporter cost.Reporter, param *param.Param) Controller {
+	return &controller{
+		reporter:       reporter,
+		clusterMgr:     param.ClusterMgr,
+		applicationMgr: param.ApplicationManager,
+		groupMgr:       param.GroupManager,
+		regionMgr:      param.RegionMgr,
+	}
+}
+
+func (c *controller) GetClusterReport(ctx context.Context, clusterID uint, r *TimeRange) (*Report, error) {
+	const op = "cost controller: get cluster report"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	report, err := c.newReport(r)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	region, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, err
+	}
+	usages, err := c.reporter.GetUsages(ctx, region, []string{cluster.Name}, report.StartTime, report.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	usage := usages[cluster.Name]
+
+	report.Usage = usage
+	report.Clusters = []*ClusterCost{ofCluster(cluster, usage)}
+	return report, nil
+}
+
+func (c *controller) GetApplicationReport(ctx context.Context, applicationID uint, r *TimeRange) (*Report, error) {
+	const op = "cost controller: get application report"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	report, err := c.newReport(r)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.applicationMgr.GetByID(ctx, applicationID); err != nil {
+		return nil, err
+	}
+	_, clusters, err := c.clusterMgr.List(ctx, &q.Query{
+		Keywords:          q.KeyWords{common.ParamApplicationID: applicationID},
+		WithoutPagination: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Clusters = c.clusterCosts(ctx, clusters, report)
+	report.Usage = c.sum(report.Clusters)
+	return report, nil
+}
+
+func (c *controller) GetGroupReport(ctx context.Context, groupID uint, r *TimeRange) (*Report, error) {
+	const op = "cost controller: get group report"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	report, err := c.newReport(r)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.groupMgr.GetByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	groups, err := c.groupMgr.GetSubGroupsByGroupIDs(ctx, []uint{groupID})
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := make([]uint, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, group.ID)
+	}
+	applications, err := c.applicationMgr.GetByGroupIDs(ctx, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	applicationCosts := make(map[uint]*ApplicationCost, len(applications))
+	for _, application := range applications {
+		applicationCosts[application.ID] = &ApplicationCost{
+			ID:       application.ID,
+			Name:     application.Name,
+			Clusters: []*ClusterCost{},
+		}
+	}
+	_, clusters, err := c.clusterMgr.List(ctx, &q.Query{
+		Keywords:          q.KeyWords{common.ClusterQueryByGroups: groupIDs},
+		WithoutPagination: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clusterCosts := c.clusterCosts(ctx, clusters, report)
+	for i, cluster := range clusters {
+		if applicationCost, ok := applicationCosts[cluster.ApplicationID]; ok {
+			applicationCost.Clusters = append(applicationCost.Clusters, clusterCosts[i])
+		}
+	}
+	report.Applications = make([]*ApplicationCost, 0, len(applicationCosts))
+	for _, applicationCost := range applicationCosts {
+		applicationCost.Usage = c.sum(applicationCost.Clusters)
+		report.Applications = append(report.Applications, applicationCost)
+	}
+	// the most expensive applications come first
+	sort.Slice(report.Applications, func(i, j int) bool {
+		if report.Applications[i].Usage.MonthlyCost != report.Applications[j].Usage.MonthlyCost {
+			return report.Applications[i].Usage.MonthlyCost > report.Applications[j].Usage.MonthlyCost
+		}
+		return report.Applications[i].ID < report.Applications[j].ID
+	})
+	usages := make([]*cost.Usage, 0, len(report.Applications))
+	for _, applicationCost := range report.Applications {
+		usages = append(usages, applicationCost.Usage)
+	}
+	report.Usage = c.reporter.Sum(usages...)
+	return report, nil
+}
+
+func (c *controller) newReport(r *TimeRange) (*Report, error) {
+	start, end := cost.DefaultTimeRange()
+	if r != nil && !r.EndTime.IsZero() {
+		end = r.EndTime
+		start = end.Add(-7 * 24 * time.Hour)
+	}
+	if r != nil && !r.StartTime.IsZero() {
+		start = r.StartTime
+	}
+	if !start.Before(end) {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "startTime should be before endTime")
+	}
+	if end.Sub(start) > _maxTimeRange {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "time range should not exceed %v", _maxTimeRange)
+	}
+	return &Report{
+		StartTime: start,
+		EndTime:   end,
+		Currency:  c.reporter.Currency(),
+	}, nil
+}
+
+// clusterCosts gets usages of clusters by region, the clusters of a region are queried together,
+// and regions are queried concurrently. Clusters whose usage is unavailable are reported with error
+func (c *controller) clusterCosts(ctx context.Context,
+	clusters []*clustermodels.ClusterWithRegion, report *Report) []*ClusterCost {
+	costs := make([]*ClusterCost, len(clusters))
+	indexesByRegion := map[string][]int{}
+	for i, cluster := range clusters {
+		costs[i] = ofCluster(cluster.Cluster, nil)
+		indexesByRegion[cluster.RegionName] = append(indexesByRegion[cluster.RegionName], i)
+	}
+
+	sem := make(chan struct{}, _concurrency)
+	var wg sync.WaitGroup
+	for regionName, indexes := range indexesByRegion {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(regionName string, indexes []int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			usages, err := c.regionUsages(ctx, regionName, clusters, indexes, report)
+			if err != nil {
+				log.Warningf(ctx, "failed to get usages of clusters in region %v: %v", regionName, err)
+			}
+			for _, i := range indexes {
+				if err != nil {
+					costs[i].Error = err.Error()
+					continue
+				}
+				costs[i].Usage = usages[clusters[i].Name]
+			}
+		}(regionName, indexes)
+	}
+	wg.Wait()
+	return costs
+}
+
+// regionUsages gets usages of the clusters at indexes, which are all in the region
+func (c *controller) regionUsages(ctx context.Context, regionName string,
+	clusters []*clustermodels.ClusterWithRegion, indexes []int, report *Report) (map[string]*cost.Usage, error) {
+	region, err := c.regionMgr.GetRegionEntity(ctx, regionName)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		names = append(names, clusters[i].Name)
+	}
+	return c.reporter.GetUsages(ctx, region, names, report.StartTime, report.EndTime)
+}
+
+func (c *controller) sum(clusters []*ClusterCost) *cost.Usage {
+	usages := make([]*cost.Usage, 0, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.Usage != nil {
+			usages = append(usages, cluster.Usage)
+		}
+	}
+	return c.reporter.Sum(usages...)
+}
+
+func ofCluster(cluster *clustermodels.Cluster, usage *cost.Usage) *ClusterCost {
+	return &ClusterCost{
+		ID:          cluster.ID,
+		Name:        cluster.Name,
+		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
+		Usage:       usage,
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	costconfig "github.com/horizoncd/horizon/pkg/config/cost"
+	"github.com/horizoncd/horizon/pkg/cost"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/horizoncd/horizon/pkg/util/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	ctx     = context.Background()
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&clustermodels.Cluster{}, &appmodels.Application{}, &groupmodels.Group{},
+		&regionmodels.Region{}, &registrymodels.Registry{}, &membermodels.Member{},
+		&usermodels.User{}, &tagmodels.Tag{}); err != nil {
+		panic(err)
+	}
+	// nolint
+	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "horizon",
+		ID:   uint(1),
+	})
+	os.Exit(m.Run())
+}
+
+// newFakePrometheus returns a fake prometheus server which reports the cpu request and peak of clusters,
+// and the number of requests is recorded by calls
+func newFakePrometheus(t *testing.T, cpuRequests map[string]float64, calls *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		matched := regexp.MustCompile(`^cpu_(request|peak)\{cluster=~"([^"]*)"\}$`).
+			FindStringSubmatch(r.URL.Query().Get("query"))
+		var clusters []string
+		if matched != nil {
+			clusters = strings.Split(matched[2], "|")
+		}
+		samples := make([]string, 0)
+		for _, cluster := range clusters {
+			value, ok := cpuRequests[cluster]
+			if !ok {
+				continue
+			}
+			if matched[1] == "peak" {
+				value /= 2
+			}
+			samples = append(samples, fmt.Sprintf(`{"metric":{"%s":"%s"},"value":[1792310400,"%v"]}`,
+				prometheus.ClusterLabel, cluster, value))
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"status":"success","data":{"resultType":"vector","result":[%s]}}`,
+			strings.Join(samples, ","))))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test(t *testing.T) {
+	calls := 0
+	server := newFakePrometheus(t, map[string]float64{"cost-c1": 2, "cost-c2": 4, "cost-c3": 1, "cost-c4": 8},
+		&calls)
+	reporter, err := cost.NewReporter(&costconfig.Config{
+		Currency:         "CNY",
+		DefaultUnitPrice: costconfig.UnitPrice{CPU: 0.1},
+		Queries: map[string]string{
+			cost.MetricCPURequest: `cpu_request{cluster=~"{{.Clusters}}"}`,
+			cost.MetricCPUPeak:    `cpu_peak{cluster=~"{{.Clusters}}"}`,
+		},
+	})
+	assert.Nil(t, err)
+
+	_, err = manager.RegistryManager.Create(ctx, &registrymodels.Registry{
+		Model: global.Model{ID: 1},
+	})
+	assert.Nil(t, err)
+	_, err = manager.RegionMgr.Create(ctx, &regionmodels.Region{
+		Name:          "cost-hz",
+		PrometheusURL: server.URL,
+		RegistryID:    1,
+	})
+	assert.Nil(t, err)
+	_, err = manager.RegionMgr.Create(ctx, &regionmodels.Region{
+		Name:       "cost-sh",
+		RegistryID: 1,
+	})
+	assert.Nil(t, err)
+
+	group, err := manager.GroupManager.Create(ctx, &groupmodels.Group{Name: "cost", Path: "cost"})
+	assert.Nil(t, err)
+	subGroup, err := manager.GroupManager.Create(ctx, &groupmodels.Group{
+		Name: "cost-sub", Path: "cost-sub", ParentID: group.ID,
+	})
+	assert.Nil(t, err)
+	app1, err := manager.ApplicationManager.Create(ctx, &appmodels.Application{
+		GroupID: group.ID, Name: "cost-app1",
+	}, nil)
+	assert.Nil(t, err)
+	app2, err := manager.ApplicationManager.Create(ctx, &appmodels.Application{
+		GroupID: subGroup.ID, Name: "cost-app2",
+	}, nil)
+	assert.Nil(t, err)
+	// clusters of applications out of the group are not reported
+	otherGroup, err := manager.GroupManager.Create(ctx, &groupmodels.Group{Name: "cost-other", Path: "cost-other"})
+	assert.Nil(t, err)
+	app3, err := manager.ApplicationManager.Create(ctx, &appmodels.Application{
+		GroupID: otherGroup.ID, Name: "cost-app3",
+	}, nil)
+	assert.Nil(t, err)
+
+	var clusters []*clustermodels.Cluster
+	for _, c := range []struct {
+		name   string
+		appID  uint
+		region string
+	}{
+		{name: "cost-c1", appID: app1.ID, region: "cost-hz"},
+		{name: "cost-c2", appID: app2.ID, region: "cost-hz"},
+		{name: "cost-c3", appID: app2.ID, region: "cost-sh"},
+		{name: "cost-c4", appID: app3.ID, region: "cost-hz"},
+	} {
+		cluster, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+			ApplicationID:   c.appID,
+			Name:            c.name,
+			EnvironmentName: "online",
+			RegionName:      c.region,
+		}, nil, nil)
+		assert.Nil(t, err)
+		clusters = append(clusters, cluster)
+	}
+
+	ctl := NewController(reporter, &param.Param{Manager: manager})
+	end := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	timeRange := &TimeRange{EndTime: end}
+
+	report, err := ctl.GetClusterReport(ctx, clusters[0].ID, timeRange)
+	assert.Nil(t, err)
+	assert.Equal(t, end.Add(-7*24*time.Hour), report.StartTime)
+	assert.Equal(t, "CNY", report.Currency)
+	assert.Equal(t, float64(2), report.Usage.CPU.Request)
+	assert.Equal(t, cost.SuggestionDecrease, report.Usage.CPU.Suggestion)
+	assert.InDelta(t, 2*0.1*730, report.Usage.MonthlyCost, 1e-9)
+	assert.Equal(t, 1, len(report.Clusters))
+
+	// the prometheus url of region sh is empty
+	_, err = ctl.GetClusterReport(ctx, clusters[2].ID, timeRange)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	report, err = ctl.GetApplicationReport(ctx, app2.ID, timeRange)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(report.Clusters))
+	assert.Equal(t, float64(4), report.Usage.CPU.Request)
+	for _, cluster := range report.Clusters {
+		if cluster.Name == "cost-c3" {
+			assert.Nil(t, cluster.Usage)
+			assert.NotEmpty(t, cluster.Error)
+		} else {
+			assert.NotNil(t, cluster.Usage)
+			assert.Empty(t, cluster.Error)
+		}
+	}
+
+	// clusters of a region are queried together
+	calls = 0
+	report, err = ctl.GetGroupReport(ctx, group.ID, &TimeRange{EndTime: end.Add(time.Hour)})
+	assert.Nil(t, err)
+	assert.Equal(t, 6, calls)
+	assert.Equal(t, float64(6), report.Usage.CPU.Request)
+	assert.Equal(t, 2, len(report.Applications))
+	assert.Equal(t, app2.ID, report.Applications[0].ID)
+	assert.Equal(t, 2, len(report.Applications[0].Clusters))
+	assert.Equal(t, app1.ID, report.Applications[1].ID)
+
+	report, err = ctl.GetGroupReport(ctx, subGroup.ID, timeRange)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(report.Applications))
+
+	_, err = ctl.GetGroupReport(ctx, group.ID, &TimeRange{StartTime: end, EndTime: end})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctl.GetGroupReport(ctx, group.ID, &TimeRange{StartTime: end.Add(-100 * 24 * time.Hour), EndTime: end})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
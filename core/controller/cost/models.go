@@ -0,0 +1,56 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/cost"
+)
+
+// Report is the resource usage and cost report of cluster, application or group
+type Report struct {
+	StartTime time.Time   `json:"startTime"`
+	EndTime   time.Time   `json:"endTime"`
+	Currency  string      `json:"currency"`
+	Usage     *cost.Usage `json:"usage"`
+	// Applications are reported by group
+	Applications []*ApplicationCost `json:"applications,omitempty"`
+	// Clusters are reported by cluster and application
+	Clusters []*ClusterCost `json:"clusters,omitempty"`
+}
+
+type ApplicationCost struct {
+	ID       uint           `json:"id"`
+	Name     string         `json:"name"`
+	Usage    *cost.Usage    `json:"usage"`
+	Clusters []*ClusterCost `json:"clusters"`
+}
+
+type ClusterCost struct {
+	ID          uint        `json:"id"`
+	Name        string      `json:"name"`
+	Environment string      `json:"environment"`
+	Region      string      `json:"region"`
+	Usage       *cost.Usage `json:"usage,omitempty"`
+	// Error is the reason why the usage of cluster is unavailable, such as the prometheus is unreachable
+	Error string `json:"error,omitempty"`
+}
+
+// TimeRange is the time range of report, the last 7 days is used if it is zero
+type TimeRange struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
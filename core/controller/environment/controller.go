@@ -17,11 +17,14 @@ package environment
 import (
 	"context"
 
+	herrors "github.com/horizoncd/horizon/core/errors"
 	environmentmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	"github.com/horizoncd/horizon/pkg/environment/models"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	envregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 )
@@ -46,6 +49,7 @@ func NewController(param *param.Param) Controller {
 		envMgr:       param.EnvMgr,
 		envRegionMgr: param.EnvRegionMgr,
 		regionMgr:    param.RegionMgr,
+		roleSvc:      param.RoleService,
 	}
 }
 
@@ -54,6 +58,7 @@ type controller struct {
 	envRegionMgr envregionmanager.Manager
 	regionMgr    regionmanager.Manager
 	autoFreeSvc  *service.AutoFreeSVC
+	roleSvc      role.Service
 }
 
 func (c *controller) GetByID(ctx context.Context, id uint) (*Environment, error) {
@@ -74,9 +79,23 @@ func (c *controller) GetByName(ctx context.Context, name string) (*Environment,
 }
 
 func (c *controller) Create(ctx context.Context, request *CreateEnvironmentRequest) (uint, error) {
+	if err := request.DeployWindows.Validate(); err != nil {
+		return 0, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	if err := c.validateApprovers(ctx, &request.Approvers); err != nil {
+		return 0, err
+	}
+	if err := models.ValidateTerminalPolicy(request.TerminalPolicy); err != nil {
+		return 0, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
 	environment, err := c.envMgr.CreateEnvironment(ctx, &models.Environment{
-		Name:        request.Name,
-		DisplayName: request.DisplayName,
+		Name:           request.Name,
+		DisplayName:    request.DisplayName,
+		DeployWindows:  request.DeployWindows,
+		Protected:      request.Protected,
+		Approvers:      request.Approvers,
+		AutoResync:     request.AutoResync,
+		TerminalPolicy: request.TerminalPolicy,
 	})
 	if err != nil {
 		return 0, err
@@ -85,11 +104,58 @@ func (c *controller) Create(ctx context.Context, request *CreateEnvironmentReque
 }
 
 func (c *controller) UpdateByID(ctx context.Context, id uint, request *UpdateEnvironmentRequest) error {
+	environment, err := c.envMgr.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	deployWindows := environment.DeployWindows
+	if request.DeployWindows != nil {
+		if err := request.DeployWindows.Validate(); err != nil {
+			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		deployWindows = *request.DeployWindows
+	}
+	protected := environment.Protected
+	if request.Protected != nil {
+		protected = *request.Protected
+	}
+	approvers := environment.Approvers
+	if request.Approvers != nil {
+		if err := c.validateApprovers(ctx, request.Approvers); err != nil {
+			return err
+		}
+		approvers = *request.Approvers
+	}
+	autoResync := environment.AutoResync
+	if request.AutoResync != nil {
+		autoResync = *request.AutoResync
+	}
+	terminalPolicy := environment.TerminalPolicy
+	if request.TerminalPolicy != nil {
+		if err := models.ValidateTerminalPolicy(*request.TerminalPolicy); err != nil {
+			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		terminalPolicy = *request.TerminalPolicy
+	}
 	return c.envMgr.UpdateByID(ctx, id, &models.Environment{
-		DisplayName: request.DisplayName,
+		DisplayName:    request.DisplayName,
+		DeployWindows:  deployWindows,
+		Protected:      protected,
+		Approvers:      approvers,
+		AutoResync:     autoResync,
+		TerminalPolicy: terminalPolicy,
 	})
 }
 
+func (c *controller) validateApprovers(ctx context.Context, approvers *models.Approvers) error {
+	for _, roleName := range approvers.Roles {
+		if _, err := c.roleSvc.GetRole(ctx, roleName); err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "role %s of approvers is not found", roleName)
+		}
+	}
+	return nil
+}
+
 func (c *controller) ListEnvironments(ctx context.Context) (_ Environments, err error) {
 	envs, err := c.envMgr.ListAllEnvironment(ctx)
 	if err != nil {
@@ -18,15 +18,22 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/region"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
+	rolemock "github.com/horizoncd/horizon/mock/pkg/rbac/role"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/environment/models"
 	"github.com/horizoncd/horizon/pkg/environment/service"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	"github.com/stretchr/testify/assert"
 )
@@ -54,9 +61,14 @@ func TestMain(m *testing.M) {
 }
 
 func Test(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	roleSvc := rolemock.NewMockService(mockCtl)
+	roleSvc.EXPECT().GetRole(gomock.Any(), "pe").Return(&types.Role{Name: "pe"}, nil).AnyTimes()
+	roleSvc.EXPECT().GetRole(gomock.Any(), "nobody").Return(nil, role.ErrorRoleNotFound).AnyTimes()
 	param := &param.Param{
 		AutoFreeSvc: service.New([]string{}),
 		Manager:     manager,
+		RoleService: roleSvc,
 	}
 	regionCtl := region.NewController(param)
 	_, err := regionCtl.Create(ctx, &region.CreateRegionRequest{
@@ -106,4 +118,97 @@ func Test(t *testing.T) {
 	assert.Equal(t, 1, len(envs))
 	assert.Equal(t, "dev", envs[0].Name)
 	assert.Equal(t, "DEV-update", envs[0].DisplayName)
+
+	// deploy windows
+	windows := models.DeployWindows{
+		{
+			Weekdays:  []time.Weekday{time.Tuesday, time.Wednesday, time.Thursday},
+			StartTime: "10:00",
+			EndTime:   "16:00",
+		},
+	}
+	err = ctl.UpdateByID(ctx, devID, &UpdateEnvironmentRequest{
+		DisplayName:   "DEV",
+		DeployWindows: &windows,
+	})
+	assert.Nil(t, err)
+	env, err = ctl.GetByID(ctx, devID)
+	assert.Nil(t, err)
+	assert.Equal(t, windows, env.DeployWindows)
+
+	// deploy windows are kept if omitted
+	err = ctl.UpdateByID(ctx, devID, &UpdateEnvironmentRequest{
+		DisplayName: "DEV",
+	})
+	assert.Nil(t, err)
+	env, err = ctl.GetByID(ctx, devID)
+	assert.Nil(t, err)
+	assert.Equal(t, windows, env.DeployWindows)
+
+	invalidWindows := models.DeployWindows{{StartTime: "16:00", EndTime: "10:00"}}
+	err = ctl.UpdateByID(ctx, devID, &UpdateEnvironmentRequest{
+		DisplayName:   "DEV",
+		DeployWindows: &invalidWindows,
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// protected environment and its approvers
+	protected := true
+	approvers := models.Approvers{Roles: []string{"pe"}, Users: []string{"tony@horizoncd.com"}}
+	err = ctl.UpdateByID(ctx, devID, &UpdateEnvironmentRequest{
+		DisplayName: "DEV",
+		Protected:   &protected,
+		Approvers:   &approvers,
+	})
+	assert.Nil(t, err)
+	env, err = ctl.GetByID(ctx, devID)
+	assert.Nil(t, err)
+	assert.True(t, env.Protected)
+	assert.Equal(t, approvers, env.Approvers)
+	assert.Equal(t, windows, env.DeployWindows)
+	assert.False(t, env.AutoResync)
+
+	// drift policy
+	autoResync := true
+	err = ctl.UpdateByID(ctx, devID, &UpdateEnvironmentRequest{
+		DisplayName: "DEV",
+		AutoResync:  &autoResync,
+	})
+	assert.Nil(t, err)
+	env, err = ctl.GetByID(ctx, devID)
+	assert.Nil(t, err)
+	assert.True(t, env.AutoResync)
+	assert.True(t, env.Protected)
+	assert.Equal(t, models.TerminalPolicyDefault, env.TerminalPolicy)
+
+	// terminal policy
+	terminalPolicy := models.TerminalPolicyDisabled
+	err = ctl.UpdateByID(ctx, devID, &UpdateEnvironmentRequest{
+		DisplayName:    "DEV",
+		TerminalPolicy: &terminalPolicy,
+	})
+	assert.Nil(t, err)
+	env, err = ctl.GetByID(ctx, devID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.TerminalPolicyDisabled, env.TerminalPolicy)
+	assert.True(t, env.AutoResync)
+	invalidPolicy := "audit"
+	err = ctl.UpdateByID(ctx, devID, &UpdateEnvironmentRequest{
+		DisplayName:    "DEV",
+		TerminalPolicy: &invalidPolicy,
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	invalidApprovers := models.Approvers{Roles: []string{"nobody"}}
+	err = ctl.UpdateByID(ctx, devID, &UpdateEnvironmentRequest{
+		DisplayName: "DEV",
+		Approvers:   &invalidApprovers,
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctl.Create(ctx, &CreateEnvironmentRequest{
+		Name:      "online",
+		Protected: true,
+		Approvers: invalidApprovers,
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
 }
@@ -22,12 +22,21 @@ import (
 )
 
 type Environment struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	DisplayName string    `json:"displayName"`
-	AutoFree    bool      `json:"autoFree"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	AutoFree    bool   `json:"autoFree"`
+	// DeployWindows limits when clusters of the environment can be deployed, empty means no limit
+	DeployWindows models.DeployWindows `json:"deployWindows"`
+	// Protected environment requires approval before clusters are deployed
+	Protected bool             `json:"protected"`
+	Approvers models.Approvers `json:"approvers"`
+	// AutoResync means drifted clusters of the environment are resynced automatically
+	AutoResync bool `json:"autoResync"`
+	// TerminalPolicy is empty to record terminal sessions if possible, record to require it, or disabled
+	TerminalPolicy string    `json:"terminalPolicy"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
 }
 
 type Environments []*Environment
@@ -43,20 +52,39 @@ func ofEnvironmentModels(envs []*models.Environment, autoFreeSvc *service.AutoFr
 
 func ofEnvironmentModel(env *models.Environment, isAutoFree bool) *Environment {
 	return &Environment{
-		ID:          env.ID,
-		Name:        env.Name,
-		DisplayName: env.DisplayName,
-		AutoFree:    isAutoFree,
-		CreatedAt:   env.CreatedAt,
-		UpdatedAt:   env.UpdatedAt,
+		ID:             env.ID,
+		Name:           env.Name,
+		DisplayName:    env.DisplayName,
+		AutoFree:       isAutoFree,
+		DeployWindows:  env.DeployWindows,
+		Protected:      env.Protected,
+		Approvers:      env.Approvers,
+		AutoResync:     env.AutoResync,
+		TerminalPolicy: env.TerminalPolicy,
+		CreatedAt:      env.CreatedAt,
+		UpdatedAt:      env.UpdatedAt,
 	}
 }
 
 type CreateEnvironmentRequest struct {
-	Name        string `json:"name"`
-	DisplayName string `json:"displayName"`
+	Name           string               `json:"name"`
+	DisplayName    string               `json:"displayName"`
+	DeployWindows  models.DeployWindows `json:"deployWindows"`
+	Protected      bool                 `json:"protected"`
+	Approvers      models.Approvers     `json:"approvers"`
+	AutoResync     bool                 `json:"autoResync"`
+	TerminalPolicy string               `json:"terminalPolicy"`
 }
 
 type UpdateEnvironmentRequest struct {
 	DisplayName string `json:"displayName"`
+	// DeployWindows is kept unchanged if it's nil
+	DeployWindows *models.DeployWindows `json:"deployWindows"`
+	// Protected and Approvers are kept unchanged if they're nil
+	Protected *bool             `json:"protected"`
+	Approvers *models.Approvers `json:"approvers"`
+	// AutoResync is kept unchanged if it's nil
+	AutoResync *bool `json:"autoResync"`
+	// TerminalPolicy is kept unchanged if it's nil
+	TerminalPolicy *string `json:"terminalPolicy"`
 }
@@ -40,6 +40,7 @@ import (
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"github.com/horizoncd/horizon/pkg/visibility"
 )
 
 const (
@@ -83,6 +84,7 @@ type controller struct {
 	memberSvc          memberservice.Service
 	templateMgr        tmanager.Manager
 	templateReleaseMgr trmanager.Manager
+	visibilityChecker  visibility.Checker
 }
 
 // NewController initializes a new group controller
@@ -94,6 +96,7 @@ func NewController(param *param.Param) Controller {
 		memberSvc:          param.MemberService,
 		templateMgr:        param.TemplateMgr,
 		templateReleaseMgr: param.TemplateReleaseManager,
+		visibilityChecker:  param.VisibilityChecker,
 	}
 }
 
@@ -115,8 +118,12 @@ func (c *controller) GetChildren(ctx context.Context, id uint, pageNumber, pageS
 		}
 	}
 
-	// query children
-	children, count, err := c.groupManager.GetChildren(ctx, id, pageNumber, pageSize)
+	// query children, private children are invisible to non members
+	invisible, err := c.listInvisible(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	children, count, err := c.groupManager.GetChildren(ctx, id, invisible, pageNumber, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -177,7 +184,12 @@ func (c *controller) SearchGroups(ctx context.Context, params *SearchParams) ([]
 	if err != nil {
 		return nil, 0, err
 	}
-	if matchedGroups == nil {
+	invisible, err := c.listInvisible(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	matchedGroups = filterInvisibleGroups(matchedGroups, invisible)
+	if len(matchedGroups) == 0 {
 		return []*service.Child{}, 0, nil
 	}
 
@@ -220,6 +232,21 @@ func (c *controller) SearchChildren(ctx context.Context, params *SearchParams) (
 	if err != nil {
 		return nil, 0, err
 	}
+
+	// filter out private groups and applications which are invisible to the current user
+	invisible, err := c.listInvisible(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	matchedGroups = filterInvisibleGroups(matchedGroups, invisible)
+	visibleApplications := make([]*appmodels.Application, 0, len(matchedApplications))
+	for _, application := range matchedApplications {
+		if invisible.HasApplication(application.ID) || invisible.HasGroup(application.GroupID) {
+			continue
+		}
+		visibleApplications = append(visibleApplications, application)
+	}
+	matchedApplications = visibleApplications
 	var groupIDs []uint
 	for _, application := range matchedApplications {
 		groupIDs = append(groupIDs, application.GroupID)
@@ -264,8 +291,12 @@ func (c *controller) GetSubGroups(ctx context.Context, id uint, pageNumber, page
 		}
 	}
 
-	// query subGroups
-	subGroups, count, err := c.groupManager.GetSubGroups(ctx, id, pageNumber, pageSize)
+	// query subGroups, private subGroups are invisible to non members
+	invisible, err := c.listInvisible(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	subGroups, count, err := c.groupManager.GetSubGroups(ctx, id, invisible, pageNumber, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -309,6 +340,21 @@ func (c *controller) GetSubGroups(ctx context.Context, id uint, pageNumber, page
 
 // UpdateBasic update basic info of a group, including name, path, description and visibilityLevel
 func (c *controller) UpdateBasic(ctx context.Context, id uint, updateGroup *UpdateGroup) error {
+	if err := visibility.Validate(updateGroup.VisibilityLevel); err != nil {
+		return err
+	}
+	if common.AuditBeforeNeeded(ctx) {
+		groupBefore, err := c.groupManager.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		common.SetAuditBefore(ctx, &UpdateGroup{
+			Name:            groupBefore.Name,
+			Path:            groupBefore.Path,
+			VisibilityLevel: groupBefore.VisibilityLevel,
+			Description:     groupBefore.Description,
+		})
+	}
 	group := convertUpdateGroupToGroup(updateGroup)
 	group.ID = id
 
@@ -316,6 +362,7 @@ func (c *controller) UpdateBasic(ctx context.Context, id uint, updateGroup *Upda
 	if err != nil {
 		return err
 	}
+	c.visibilityChecker.Invalidate()
 
 	return nil
 }
@@ -326,18 +373,23 @@ func (c *controller) Transfer(ctx context.Context, id, newParentID uint) error {
 	if err != nil {
 		return err
 	}
+	c.visibilityChecker.Invalidate()
 
 	return nil
 }
 
 // CreateGroup add a group
 func (c *controller) CreateGroup(ctx context.Context, newGroup *NewGroup) (uint, error) {
+	if err := visibility.Validate(newGroup.VisibilityLevel); err != nil {
+		return 0, err
+	}
 	groupEntity := convertNewGroupToGroup(newGroup)
 
 	group, err := c.groupManager.Create(ctx, groupEntity)
 	if err != nil {
 		return 0, err
 	}
+	c.visibilityChecker.Invalidate()
 
 	return group.ID, err
 }
@@ -365,6 +417,12 @@ func (c *controller) GetByFullPath(ctx context.Context,
 			return nil, err
 		}
 
+		// private resources are not found for the users who are not their members
+		invisible, err := c.listInvisible(ctx)
+		if err != nil {
+			return nil, err
+		}
+
 		// get mapping between id and group
 		idToGroup := service.GenerateIDToGroup(groups)
 
@@ -375,6 +433,9 @@ func (c *controller) GetByFullPath(ctx context.Context,
 		for k, v := range idToFull {
 			// resourcePath pointing to a group
 			if v.FullPath == resourcePath {
+				if invisible.HasGroup(k) {
+					return nil, perror.Wrap(errNotMatch, errMsg)
+				}
 				g := idToGroup[k]
 				child := service.ConvertGroupToChild(g, v)
 				return child, nil
@@ -387,6 +448,9 @@ func (c *controller) GetByFullPath(ctx context.Context,
 		}
 		app, err := c.applicationManager.GetByName(ctx, paths[len(paths)-1])
 		if app != nil && err == nil {
+			if invisible.HasApplication(app.ID) || invisible.HasGroup(app.GroupID) {
+				return nil, perror.Wrap(errNotMatch, errMsg)
+			}
 			appParentFull, ok := idToFull[app.GroupID]
 			if ok && fmt.Sprintf("%s/%s", appParentFull.FullPath, app.Name) == resourcePath {
 				return service.ConvertApplicationToChild(app, &service.Full{
@@ -405,7 +469,7 @@ func (c *controller) GetByFullPath(ctx context.Context,
 			return nil, perror.Wrap(errNotMatch, errMsg)
 		}
 		app, err = c.applicationManager.GetByID(ctx, cluster.ApplicationID)
-		if err != nil {
+		if err != nil || invisible.HasApplication(app.ID) || invisible.HasGroup(app.GroupID) {
 			return nil, perror.Wrap(errNotMatch, errMsg)
 		}
 		appParentFull, ok := idToFull[app.GroupID]
@@ -653,6 +717,26 @@ func generateChildrenWithLevelStruct(groupID uint, groups []*models.Group,
 	return firstLevelChildren
 }
 
+// listInvisible lists the private groups and applications which are invisible to the current user
+func (c *controller) listInvisible(ctx context.Context) (*models.Invisible, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.visibilityChecker.ListInvisible(ctx, currentUser)
+}
+
+// filterInvisibleGroups filters out the invisible groups
+func filterInvisibleGroups(groups []*models.Group, invisible *models.Invisible) []*models.Group {
+	visibleGroups := make([]*models.Group, 0, len(groups))
+	for _, group := range groups {
+		if !invisible.HasGroup(group.ID) {
+			visibleGroups = append(visibleGroups, group)
+		}
+	}
+	return visibleGroups
+}
+
 func (c *controller) formatFullFromGroup(ctx context.Context, group *models.Group) (*service.Full, error) {
 	groups, err := c.groupManager.GetByIDs(ctx, groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs))
 	if err != nil {
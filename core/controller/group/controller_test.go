@@ -32,6 +32,7 @@ import (
 	applicationdao "github.com/horizoncd/horizon/pkg/application/dao"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	"github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
@@ -91,6 +92,11 @@ func init() {
 		fmt.Printf("%+v", err)
 		os.Exit(1)
 	}
+	err = db.AutoMigrate(&clustermodels.Cluster{})
+	if err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(1)
+	}
 
 	callbacks.RegisterCustomCallbacks(db)
 }
@@ -620,6 +626,16 @@ func TestControllerGetByPath(t *testing.T) {
 		Priority:    "P0",
 	}, nil)
 	assert.Nil(t, err)
+	cluster := &clustermodels.Cluster{
+		ApplicationID: app.ID,
+		Name:          "cluster",
+	}
+	assert.Nil(t, db.Create(cluster).Error)
+	// nolint
+	nonMemberCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "non-member",
+		ID:   999,
+	})
 
 	type args struct {
 		ctx          context.Context
@@ -681,6 +697,42 @@ func TestControllerGetByPath(t *testing.T) {
 				path: "/a/app-not-exists",
 			},
 			wantErr: true,
+		}, {
+			name: "clusterExist",
+			args: args{
+				ctx:  ctx,
+				path: "/a/app/cluster",
+			},
+			want: &service.Child{
+				ID:       cluster.ID,
+				Name:     "cluster",
+				Path:     "cluster",
+				ParentID: app.ID,
+				FullName: "1/app/cluster",
+				FullPath: "/a/app/cluster",
+				Type:     service.ChildTypeCluster,
+			},
+		}, {
+			name: "groupInvisibleToNonMember",
+			args: args{
+				ctx:  nonMemberCtx,
+				path: "/a",
+			},
+			wantErr: true,
+		}, {
+			name: "applicationInvisibleToNonMember",
+			args: args{
+				ctx:  nonMemberCtx,
+				path: "/a/app",
+			},
+			wantErr: true,
+		}, {
+			name: "clusterInvisibleToNonMember",
+			args: args{
+				ctx:  nonMemberCtx,
+				path: "/a/app/cluster",
+			},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
@@ -698,6 +750,7 @@ func TestControllerGetByPath(t *testing.T) {
 
 	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Group{})
 	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&appmodels.Application{})
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&clustermodels.Cluster{})
 }
 
 func TestControllerGetChildren(t *testing.T) {
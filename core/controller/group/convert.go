@@ -16,6 +16,7 @@ package group
 
 import (
 	"github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/visibility"
 )
 
 // convertNewGroupToGroup convert newGroup model to group model
@@ -23,7 +24,7 @@ func convertNewGroupToGroup(newGroup *NewGroup) *models.Group {
 	return &models.Group{
 		Name:            newGroup.Name,
 		Path:            newGroup.Path,
-		VisibilityLevel: newGroup.VisibilityLevel,
+		VisibilityLevel: visibility.LevelOrDefault(newGroup.VisibilityLevel),
 		Description:     newGroup.Description,
 		ParentID:        newGroup.ParentID,
 	}
@@ -34,7 +35,7 @@ func convertUpdateGroupToGroup(updateGroup *UpdateGroup) *models.Group {
 	return &models.Group{
 		Name:            updateGroup.Name,
 		Path:            updateGroup.Path,
-		VisibilityLevel: updateGroup.VisibilityLevel,
+		VisibilityLevel: visibility.LevelOrDefault(updateGroup.VisibilityLevel),
 		Description:     updateGroup.Description,
 	}
 }
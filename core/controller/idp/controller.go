@@ -31,6 +31,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/param"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodel "github.com/horizoncd/horizon/pkg/user/models"
+	usergroupmanager "github.com/horizoncd/horizon/pkg/usergroup/manager"
 	linkmanager "github.com/horizoncd/horizon/pkg/userlink/manager"
 	"golang.org/x/oauth2"
 )
@@ -52,16 +53,18 @@ type Controller interface {
 }
 
 type controller struct {
-	idpManager  manager.Manager
-	userManager usermanager.Manager
-	linkManager linkmanager.Manager
+	idpManager   manager.Manager
+	userManager  usermanager.Manager
+	linkManager  linkmanager.Manager
+	userGroupMgr usergroupmanager.Manager
 }
 
 func NewController(param *param.Param) Controller {
 	return &controller{
-		idpManager:  param.IdpManager,
-		userManager: param.UserManager,
-		linkManager: param.UserLinksManager,
+		idpManager:   param.IdpManager,
+		userManager:  param.UserManager,
+		linkManager:  param.UserLinksManager,
+		userGroupMgr: param.UserGroupMgr,
 	}
 }
 
@@ -185,6 +188,14 @@ func (c *controller) LoginOrLink(ctx context.Context,
 			}
 		}
 	}
+
+	// groups of user are synced from identity provider on every login, so that member bindings
+	// of groups are applied to the user
+	if user != nil && idp.GroupsClaim != "" {
+		if _, err := c.userGroupMgr.SyncUserGroups(ctx, user.ID, idp.ID, claims.Groups); err != nil {
+			return nil, err
+		}
+	}
 	return user, nil
 }
 
@@ -0,0 +1,110 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	idpmodels "github.com/horizoncd/horizon/pkg/idp/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	usergroupmodels "github.com/horizoncd/horizon/pkg/usergroup/models"
+	linkmodels "github.com/horizoncd/horizon/pkg/userlink/models"
+)
+
+// fakeOIDCServer issues a fake token for any code, and returns userinfo with groups for the token
+func fakeOIDCServer(groups *[]string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"fake-token","token_type":"Bearer"}`))
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fake-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"sub":    "10001",
+			"name":   "tom",
+			"email":  "tom@horizon.com",
+			"groups": *groups,
+		})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestLoginOrLinkSyncGroups(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&idpmodels.IdentityProvider{}, &usermodels.User{}, &linkmodels.UserLink{},
+		&usergroupmodels.UserGroup{}, &usergroupmodels.UserGroupMember{}))
+	manager := managerparam.InitManager(db)
+	c := &controller{
+		idpManager:   manager.IdpManager,
+		userManager:  manager.UserManager,
+		linkManager:  manager.UserLinksManager,
+		userGroupMgr: manager.UserGroupMgr,
+	}
+	ctx := context.Background()
+
+	groups := []string{"dev", "ops"}
+	server := fakeOIDCServer(&groups)
+	defer server.Close()
+	method := idpmodels.TokenEndpointAuthMethod(idpmodels.ClientSecretSentAsPost)
+	idp, err := manager.IdpManager.Create(ctx, &idpmodels.IdentityProvider{
+		Name:                    "company",
+		AuthorizationEndpoint:   server.URL + "/auth",
+		TokenEndpoint:           server.URL + "/token",
+		UserinfoEndpoint:        server.URL + "/userinfo",
+		Jwks:                    server.URL + "/jwks",
+		SigningAlgs:             "RS256",
+		ClientID:                "horizon",
+		ClientSecret:            "secret",
+		TokenEndpointAuthMethod: &method,
+		GroupsClaim:             "groups",
+	})
+	assert.Nil(t, err)
+	state := base64.StdEncoding.EncodeToString([]byte(url.Values{providerKey: []string{"company"}}.Encode()))
+
+	// register
+	user, err := c.LoginOrLink(ctx, "code", state, "http://horizon.com/callback")
+	assert.Nil(t, err)
+	assert.Equal(t, "tom", user.Name)
+	userGroups, err := manager.UserGroupMgr.ListByUserID(ctx, user.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(userGroups))
+	assert.Equal(t, "dev", userGroups[0].Name)
+	assert.Equal(t, idp.ID, userGroups[0].IdpID)
+
+	// sign in after user left dev in identity provider
+	groups = []string{"ops"}
+	user2, err := c.LoginOrLink(ctx, "code", state, "http://horizon.com/callback")
+	assert.Nil(t, err)
+	assert.Equal(t, user.ID, user2.ID)
+	userGroups, err = manager.UserGroupMgr.ListByUserID(ctx, user.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(userGroups))
+	assert.Equal(t, "ops", userGroups[0].Name)
+}
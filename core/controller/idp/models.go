@@ -42,6 +42,7 @@ type IdentityProvider struct {
 	Jwks                    string                         `json:"jwks,omitempty"`
 	ClientID                string                         `json:"clientID,omitempty"`
 	ClientSecret            string                         `json:"clientSecret,omitempty"`
+	GroupsClaim             string                         `json:"groupsClaim,omitempty"`
 	CreatedAt               time.Time                      `json:"createdAt"`
 	UpdatedAt               time.Time                      `json:"updatedAt"`
 }
@@ -66,6 +67,7 @@ func ofIDPModel(idp *models.IdentityProvider) *IdentityProvider {
 		Jwks:                    idp.Jwks,
 		ClientID:                idp.ClientID,
 		ClientSecret:            idp.ClientSecret,
+		GroupsClaim:             idp.GroupsClaim,
 		CreatedAt:               idp.CreatedAt,
 		UpdatedAt:               idp.UpdatedAt,
 	}
@@ -99,6 +101,7 @@ func (r *CreateIDPRequest) toModel() *models.IdentityProvider {
 		Jwks:                    r.Jwks,
 		ClientID:                r.ClientID,
 		ClientSecret:            r.ClientSecret,
+		GroupsClaim:             r.GroupsClaim,
 	}
 	return idp
 }
@@ -118,6 +121,7 @@ type UpdateIDPRequest struct {
 	Jwks                    string                         `json:"jwks,omitempty"`
 	ClientID                string                         `json:"clientID"`
 	ClientSecret            string                         `json:"clientSecret"`
+	GroupsClaim             string                         `json:"groupsClaim,omitempty"`
 }
 
 func (r *UpdateIDPRequest) toModel() *models.IdentityProvider {
@@ -136,6 +140,7 @@ func (r *UpdateIDPRequest) toModel() *models.IdentityProvider {
 		Jwks:                    r.Jwks,
 		ClientID:                r.ClientID,
 		ClientSecret:            r.ClientSecret,
+		GroupsClaim:             r.GroupsClaim,
 	}
 	return idp
 }
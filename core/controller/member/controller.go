@@ -17,6 +17,7 @@ package member
 import (
 	"context"
 	"strconv"
+	"time"
 
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
@@ -25,8 +26,10 @@ import (
 type Controller interface {
 	// CreateMember create a member of the group
 	CreateMember(ctx context.Context, postMember *PostMember) (*Member, error)
-	// UpdateMember update a member of the group
-	UpdateMember(ctx context.Context, id uint, role string) (*Member, error)
+	// UpdateMember update a member of the group, a nil expiredAt keeps the current expiry
+	// unless clearExpiredAt is set, which makes the member never expire
+	UpdateMember(ctx context.Context, id uint, role string, expiredAt *time.Time,
+		clearExpiredAt bool) (*Member, error)
 	// RemoveMember leave group or remove a member of the group
 	RemoveMember(ctx context.Context, id uint) error
 	// ListMember list all the member of the group (and all the member from parent group)
@@ -60,8 +63,9 @@ func (c *controller) CreateMember(ctx context.Context, postMember *PostMember) (
 	return retMember, nil
 }
 
-func (c *controller) UpdateMember(ctx context.Context, id uint, role string) (*Member, error) {
-	member, err := c.memberService.UpdateMember(ctx, id, role)
+func (c *controller) UpdateMember(ctx context.Context, id uint, role string,
+	expiredAt *time.Time, clearExpiredAt bool) (*Member, error) {
+	member, err := c.memberService.UpdateMember(ctx, id, role, expiredAt, clearExpiredAt)
 	if err != nil {
 		return nil, err
 	}
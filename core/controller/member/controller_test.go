@@ -227,7 +227,7 @@ func TestCreateGetUpdateRemoveList(t *testing.T) {
 	assert.True(t, PostMemberAndMemberEqual(postMember2, *retMember2))
 
 	// update member
-	retMember3, err := ctl.UpdateMember(ctx, retMember2.ID, "maitainer")
+	retMember3, err := ctl.UpdateMember(ctx, retMember2.ID, "maitainer", nil, false)
 	assert.Nil(t, err)
 	postMember2.Role = "maitainer"
 
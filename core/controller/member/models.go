@@ -30,11 +30,16 @@ import (
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	usergroupmanager "github.com/horizoncd/horizon/pkg/usergroup/manager"
 )
 
 type UpdateMember struct {
 	ID   uint   `json:"id"`
 	Role string `json:"role"`
+	// ExpiredAt the time when the member expires, nil keeps the current expiry
+	ExpiredAt *time.Time `json:"expiredAt,omitempty"`
+	// ClearExpiredAt makes the member never expire
+	ClearExpiredAt bool `json:"clearExpiredAt,omitempty"`
 }
 
 type PostMember struct {
@@ -52,6 +57,12 @@ type PostMember struct {
 
 	// Role owner/maintainer/develop/...
 	Role string `json:"role"`
+
+	// ExpiredAt the time when the member expires, nil means never
+	ExpiredAt *time.Time `json:"expiredAt,omitempty"`
+
+	// ClearExpiredAt makes the member never expire if it already exists
+	ClearExpiredAt bool `json:"clearExpiredAt,omitempty"`
 }
 
 type Member struct {
@@ -80,18 +91,36 @@ type Member struct {
 	GrantorName string `json:"grantorName"`
 	// GrantTime
 	GrantTime time.Time `json:"grantTime"`
+	// ExpiredAt the time when the member expires, nil means never
+	ExpiredAt *time.Time `json:"expiredAt,omitempty"`
+	// RemainingSeconds the remaining seconds before the member expires
+	RemainingSeconds int64 `json:"remainingSeconds,omitempty"`
 }
 
 func CovertPostMember(member *PostMember) memberservice.PostMember {
 	return memberservice.PostMember{
-		ResourceType: member.ResourceType,
-		ResourceID:   member.ResourceID,
-		MemberInfo:   member.MemberNameID,
-		MemberType:   member.MemberType,
-		Role:         member.Role,
+		ResourceType:   member.ResourceType,
+		ResourceID:     member.ResourceID,
+		MemberInfo:     member.MemberNameID,
+		MemberType:     member.MemberType,
+		Role:           member.Role,
+		ExpiredAt:      member.ExpiredAt,
+		ClearExpiredAt: member.ClearExpiredAt,
 	}
 }
 
+// remainingSeconds returns the remaining seconds before expiredAt, 0 if never expired
+func remainingSeconds(expiredAt *time.Time) int64 {
+	if expiredAt == nil {
+		return 0
+	}
+	remaining := int64(time.Until(*expiredAt).Seconds())
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
 type ConvertMemberHelp interface {
 	ConvertMember(ctx context.Context, member *models.Member) (*Member, error)
 	ConvertMembers(ctx context.Context, member []models.Member) ([]Member, error)
@@ -104,6 +133,7 @@ type converter struct {
 	clusterSvc     clusterservice.Service
 	templateMgr    tmanager.Manager
 	releaseMgr     trmanager.Manager
+	userGroupMgr   usergroupmanager.Manager
 }
 
 func New(param *param.Param) ConvertMemberHelp {
@@ -114,6 +144,7 @@ func New(param *param.Param) ConvertMemberHelp {
 		clusterSvc:     param.ClusterSvc,
 		templateMgr:    param.TemplateMgr,
 		releaseMgr:     param.TemplateReleaseManager,
+		userGroupMgr:   param.UserGroupMgr,
 	}
 }
 
@@ -132,28 +163,35 @@ func (c *converter) ConvertMember(ctx context.Context, member *models.Member) (_
 		}
 		memberInfo = user.Name
 	} else {
-		// TODO(tom) covert groupID to GroupName
-		return nil, errors.New("group member not support yet")
+		userGroup, err := c.userGroupMgr.GetByID(ctx, member.MemberNameID)
+		if err != nil {
+			return nil, err
+		}
+		memberInfo = userGroup.Name
 	}
 
 	return &Member{
-		ID:           member.ID,
-		MemberType:   member.MemberType,
-		MemberName:   memberInfo,
-		MemberNameID: member.MemberNameID,
-		ResourceType: member.ResourceType,
-		ResourceID:   member.ResourceID,
-		Role:         member.Role,
-		GrantedBy:    member.GrantedBy,
-		GrantTime:    member.UpdatedAt,
+		ID:               member.ID,
+		MemberType:       member.MemberType,
+		MemberName:       memberInfo,
+		MemberNameID:     member.MemberNameID,
+		ResourceType:     member.ResourceType,
+		ResourceID:       member.ResourceID,
+		Role:             member.Role,
+		GrantedBy:        member.GrantedBy,
+		GrantTime:        member.UpdatedAt,
+		ExpiredAt:        member.ExpiredAt,
+		RemainingSeconds: remainingSeconds(member.ExpiredAt),
 	}, nil
 }
 func (c *converter) ConvertMembers(ctx context.Context, members []models.Member) ([]Member, error) {
-	var userIDs []uint
+	var userIDs, userGroupIDs []uint
 
 	for _, member := range members {
-		if member.MemberType != models.MemberUser {
-			return nil, errors.New("Only Support User MemberType yet")
+		if member.MemberType == models.MemberGroup {
+			userGroupIDs = append(userGroupIDs, member.MemberNameID)
+			userIDs = append(userIDs, member.GrantedBy)
+			continue
 		}
 		userIDs = append(userIDs, member.MemberNameID, member.GrantedBy)
 	}
@@ -165,6 +203,14 @@ func (c *converter) ConvertMembers(ctx context.Context, members []models.Member)
 	for _, userItem := range users {
 		userIDToName[userItem.ID] = userItem.Name
 	}
+	userGroups, err := c.userGroupMgr.ListByIDs(ctx, userGroupIDs)
+	if err != nil {
+		return nil, err
+	}
+	userGroupIDToName := make(map[uint]string)
+	for _, userGroup := range userGroups {
+		userGroupIDToName[userGroup.ID] = userGroup.Name
+	}
 	var retMembers []Member
 	for _, member := range members {
 		var resourceName, resourcePath string
@@ -211,19 +257,25 @@ func (c *converter) ConvertMembers(ctx context.Context, members []models.Member)
 		default:
 			return nil, fmt.Errorf("%s is not support now", member.ResourceType)
 		}
+		memberName := userIDToName[member.MemberNameID]
+		if member.MemberType == models.MemberGroup {
+			memberName = userGroupIDToName[member.MemberNameID]
+		}
 		retMembers = append(retMembers, Member{
-			ID:           member.ID,
-			MemberType:   member.MemberType,
-			MemberName:   userIDToName[member.MemberNameID],
-			MemberNameID: member.MemberNameID,
-			ResourceType: member.ResourceType,
-			ResourceID:   member.ResourceID,
-			ResourceName: resourceName,
-			ResourcePath: resourcePath,
-			Role:         member.Role,
-			GrantedBy:    member.GrantedBy,
-			GrantorName:  userIDToName[member.GrantedBy],
-			GrantTime:    member.UpdatedAt,
+			ID:               member.ID,
+			MemberType:       member.MemberType,
+			MemberName:       memberName,
+			MemberNameID:     member.MemberNameID,
+			ResourceType:     member.ResourceType,
+			ResourceID:       member.ResourceID,
+			ResourceName:     resourceName,
+			ResourcePath:     resourcePath,
+			Role:             member.Role,
+			GrantedBy:        member.GrantedBy,
+			GrantorName:      userIDToName[member.GrantedBy],
+			GrantTime:        member.UpdatedAt,
+			ExpiredAt:        member.ExpiredAt,
+			RemainingSeconds: remainingSeconds(member.ExpiredAt),
 		})
 	}
 	return retMembers, nil
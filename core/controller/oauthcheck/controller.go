@@ -30,15 +30,25 @@ import (
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
 	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 )
 
 type Controller interface {
-	ValidateToken(ctx context.Context, token string) error
-	LoadAccessTokenUser(ctx context.Context, token string) (user.User, error)
-	CheckScopePermission(ctx context.Context, token string, authInfo auth.RequestInfo) (bool, string, error)
+	// LoadToken loads the token by code, the token is passed to the other methods
+	// so that it is loaded only once for each request
+	LoadToken(ctx context.Context, code string) (*tokenmodels.Token, error)
+	ValidateToken(ctx context.Context, token *tokenmodels.Token) error
+	LoadAccessTokenUser(ctx context.Context, token *tokenmodels.Token) (user.User, error)
+	CheckScopePermission(ctx context.Context, token *tokenmodels.Token, usr user.User,
+		authInfo auth.RequestInfo) (bool, string, error)
+	// RecordUsage records the last time and source ip the token is used
+	RecordUsage(ctx context.Context, token *tokenmodels.Token, ip string) error
 }
 
+// _usageRecordInterval avoids writing db on every request of a token
+const _usageRecordInterval = time.Minute
+
 type controller struct {
 	tokenManager tokenmanager.Manager
 	userManager  usermanager.Manager
@@ -55,12 +65,11 @@ func NewOauthChecker(param *param.Param) Controller {
 	}
 }
 
-func (c *controller) ValidateToken(ctx context.Context, accessToken string) error {
-	token, err := c.tokenManager.LoadTokenByCode(ctx, accessToken)
-	if err != nil {
-		return err
-	}
+func (c *controller) LoadToken(ctx context.Context, code string) (*tokenmodels.Token, error) {
+	return c.tokenManager.LoadTokenByCode(ctx, code)
+}
 
+func (c *controller) ValidateToken(ctx context.Context, token *tokenmodels.Token) error {
 	isExpired := func() bool {
 		return token.CreatedAt.Add(token.ExpiresIn).Before(time.Now())
 	}
@@ -79,12 +88,7 @@ func (c *controller) ValidateToken(ctx context.Context, accessToken string) erro
 	return nil
 }
 
-func (c *controller) LoadAccessTokenUser(ctx context.Context, accessToken string) (user.User, error) {
-	token, err := c.tokenManager.LoadTokenByCode(ctx, accessToken)
-	if err != nil {
-		return nil, err
-	}
-
+func (c *controller) LoadAccessTokenUser(ctx context.Context, token *tokenmodels.Token) (user.User, error) {
 	usr, err := c.userManager.GetUserByID(ctx, token.UserID)
 	if err != nil {
 		return nil, err
@@ -98,18 +102,8 @@ func (c *controller) LoadAccessTokenUser(ctx context.Context, accessToken string
 	}, nil
 }
 
-func (c *controller) CheckScopePermission(ctx context.Context, accessToken string,
+func (c *controller) CheckScopePermission(ctx context.Context, token *tokenmodels.Token, usr user.User,
 	requestInfo auth.RequestInfo) (bool, string, error) {
-	token, err := c.tokenManager.LoadTokenByCode(ctx, accessToken)
-	if err != nil {
-		return false, "", err
-	}
-
-	usr, err := c.LoadAccessTokenUser(ctx, accessToken)
-	if err != nil {
-		return false, "", err
-	}
-
 	record := rbactype.AttributesRecord{
 		User:            usr,
 		Verb:            requestInfo.Verb,
@@ -135,3 +129,11 @@ func (c *controller) CheckScopePermission(ctx context.Context, accessToken strin
 	}
 	return false, "", nil
 }
+
+func (c *controller) RecordUsage(ctx context.Context, token *tokenmodels.Token, ip string) error {
+	now := time.Now()
+	if token.LastUsedIP == ip && token.LastUsedAt != nil && now.Sub(*token.LastUsedAt) < _usageRecordInterval {
+		return nil
+	}
+	return c.tokenManager.UpdateLastUsed(ctx, token.ID, ip, now)
+}
@@ -18,7 +18,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"regexp"
 
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
@@ -46,8 +48,17 @@ type Controller interface {
 	List(ctx context.Context, clusterID uint, canRollback bool, query q.Query) (int, []*PipelineBasic, error)
 	StopPipelinerun(ctx context.Context, pipelinerunID uint) error
 	StopPipelinerunForCluster(ctx context.Context, clusterID uint) error
+	// SearchLogs searches logs of the latest pipelineruns of cluster collected into the log storage
+	SearchLogs(ctx context.Context, clusterID uint, request *SearchLogsRequest) (*SearchLogsResponse, error)
 }
 
+const (
+	// _maxSearchedPipelineruns is the max number of pipelineruns whose logs are searched
+	_maxSearchedPipelineruns = 50
+	// _maxLogMatches is the max number of matched lines returned
+	_maxLogMatches = 200
+)
+
 type controller struct {
 	pipelinerunMgr prmanager.Manager
 	applicationMgr appmanager.Manager
@@ -342,3 +353,53 @@ func (c *controller) StopPipelinerunForCluster(ctx context.Context, clusterID ui
 
 	return tektonClient.StopPipelineRun(ctx, pipelinerun.CIEventID)
 }
+
+func (c *controller) SearchLogs(ctx context.Context, clusterID uint,
+	request *SearchLogsRequest) (_ *SearchLogsResponse, err error) {
+	const op = "pipelinerun controller: search logs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if request.Pattern == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "pattern is required")
+	}
+	expr := request.Pattern
+	if !request.Regex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid pattern: %v", err)
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	tektonCollector, err := c.tektonFty.GetTektonCollector(cluster.EnvironmentName)
+	if err != nil {
+		return nil, perror.WithMessagef(err, "faild to get tekton collector for %s", cluster.EnvironmentName)
+	}
+	pipelineruns, err := c.pipelinerunMgr.ListCollectedByClusterID(ctx, clusterID, 0, _maxSearchedPipelineruns)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &SearchLogsResponse{Matches: make([]*LogMatch, 0)}
+	for _, pr := range pipelineruns {
+		matches, err := tektonCollector.SearchPipelineRunLog(ctx, pr, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if len(resp.Matches) >= _maxLogMatches {
+				resp.Truncated = true
+				return resp, nil
+			}
+			resp.Matches = append(resp.Matches, &LogMatch{
+				PipelinerunID: pr.ID,
+				LogMatch:      *match,
+			})
+		}
+	}
+	return resp, nil
+}
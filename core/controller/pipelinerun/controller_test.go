@@ -18,11 +18,13 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"regexp"
 	"strconv"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/lib/q"
 	applicationmockmanager "github.com/horizoncd/horizon/mock/pkg/application/manager"
@@ -41,6 +43,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
 	envmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/git"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
@@ -137,6 +140,61 @@ func TestGetAndListPipelinerun(t *testing.T) {
 	t.Logf("%s", string(body))
 }
 
+func TestSearchLogs(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	ctx := context.TODO()
+
+	mockClusterManager := clustermockmananger.NewMockManager(mockCtl)
+	mockPipelineManager := pipelinemockmanager.NewMockManager(mockCtl)
+	tektonFty := tektonftymock.NewMockFactory(mockCtl)
+	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
+	var ctl Controller = &controller{
+		pipelinerunMgr: mockPipelineManager,
+		clusterMgr:     mockClusterManager,
+		tektonFty:      tektonFty,
+	}
+
+	// invalid pattern
+	_, err := ctl.SearchLogs(ctx, 1, &SearchLogsRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctl.SearchLogs(ctx, 1, &SearchLogsRequest{Pattern: "error[", Regex: true})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	mockClusterManager.EXPECT().GetByID(ctx, uint(1)).Return(&clustermodel.Cluster{
+		EnvironmentName: "test",
+	}, nil).AnyTimes()
+	tektonFty.EXPECT().GetTektonCollector("test").Return(tektonCollector, nil).AnyTimes()
+	pipelineruns := []*models.Pipelinerun{{ID: 3, ClusterID: 1}, {ID: 2, ClusterID: 1}}
+	mockPipelineManager.EXPECT().ListCollectedByClusterID(ctx, uint(1), 0, _maxSearchedPipelineruns).
+		Return(pipelineruns, nil).AnyTimes()
+	tektonCollector.EXPECT().SearchPipelineRunLog(ctx, pipelineruns[0], gomock.Any()).DoAndReturn(
+		func(ctx context.Context, pr *models.Pipelinerun, pattern *regexp.Regexp) ([]*collector.LogMatch, error) {
+			// plain string is quoted
+			assert.Equal(t, `error\[`, pattern.String())
+			return []*collector.LogMatch{{Task: "build", Step: "compile", LineNumber: 10, Line: "error[1]"}}, nil
+		})
+	tektonCollector.EXPECT().SearchPipelineRunLog(ctx, pipelineruns[1], gomock.Any()).Return(nil, nil)
+
+	resp, err := ctl.SearchLogs(ctx, 1, &SearchLogsRequest{Pattern: "error["})
+	assert.Nil(t, err)
+	assert.False(t, resp.Truncated)
+	assert.Equal(t, []*LogMatch{{
+		PipelinerunID: 3,
+		LogMatch:      collector.LogMatch{Task: "build", Step: "compile", LineNumber: 10, Line: "error[1]"},
+	}}, resp.Matches)
+
+	// matches are truncated
+	matches := make([]*collector.LogMatch, _maxLogMatches+1)
+	for i := range matches {
+		matches[i] = &collector.LogMatch{LineNumber: i + 1}
+	}
+	tektonCollector.EXPECT().SearchPipelineRunLog(ctx, pipelineruns[0], gomock.Any()).Return(matches, nil)
+	resp, err = ctl.SearchLogs(ctx, 1, &SearchLogsRequest{Pattern: "error", Regex: true})
+	assert.Nil(t, err)
+	assert.True(t, resp.Truncated)
+	assert.Equal(t, _maxLogMatches, len(resp.Matches))
+}
+
 func TestGetDiff(t *testing.T) {
 	mockCtl := gomock.NewController(t)
 	ctx := context.TODO()
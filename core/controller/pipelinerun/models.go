@@ -16,6 +16,8 @@ package pipelinerun
 
 import (
 	"time"
+
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
 )
 
 type GetDiffResponse struct {
@@ -88,3 +90,21 @@ type UserInfo struct {
 	UserID   uint   `json:"userID"`
 	UserName string `json:"userName"`
 }
+
+type SearchLogsRequest struct {
+	// Pattern is the string or regular expression to search
+	Pattern string
+	// Regex indicates whether Pattern is a regular expression
+	Regex bool
+}
+
+type LogMatch struct {
+	PipelinerunID uint `json:"pipelinerunID"`
+	collector.LogMatch
+}
+
+type SearchLogsResponse struct {
+	Matches []*LogMatch `json:"matches"`
+	// Truncated indicates there're more matches not returned
+	Truncated bool `json:"truncated"`
+}
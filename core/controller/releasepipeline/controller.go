@@ -0,0 +1,272 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasepipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	releasepipelinemanager "github.com/horizoncd/horizon/pkg/releasepipeline/manager"
+	"github.com/horizoncd/horizon/pkg/releasepipeline/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// Create creates a release pipeline of application
+	Create(ctx context.Context, applicationID uint, r *CreateReleasePipelineRequest) (*ReleasePipeline, error)
+	// List lists release pipelines of application
+	List(ctx context.Context, applicationID uint) ([]*ReleasePipeline, error)
+	// Get gets a release pipeline of application
+	Get(ctx context.Context, applicationID, id uint) (*ReleasePipeline, error)
+	// Update updates a release pipeline of application
+	Update(ctx context.Context, applicationID, id uint, r *UpdateReleasePipelineRequest) (*ReleasePipeline, error)
+	// Delete deletes a release pipeline of application
+	Delete(ctx context.Context, applicationID, id uint) error
+	// Promote promotes the image and config of the previous stage to the stage,
+	// the previous stage must be healthy if it has a health gate
+	Promote(ctx context.Context, applicationID, id uint, r *PromoteRequest) (*PromoteResponse, error)
+}
+
+type controller struct {
+	clusterMgr         clustermanager.Manager
+	releasePipelineMgr releasepipelinemanager.Manager
+	clusterCtl         clusterctl.Controller
+	authorizer         rbac.Authorizer
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param, clusterCtl clusterctl.Controller) Controller {
+	return &controller{
+		clusterMgr:         param.ClusterMgr,
+		releasePipelineMgr: param.ReleasePipelineMgr,
+		clusterCtl:         clusterCtl,
+		authorizer:         param.Authorizer,
+	}
+}
+
+func (c *controller) Create(ctx context.Context, applicationID uint,
+	r *CreateReleasePipelineRequest) (*ReleasePipeline, error) {
+	const op = "release pipeline controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	stages := toStages(r.Stages)
+	if err := c.validate(ctx, applicationID, r.Name, stages); err != nil {
+		return nil, err
+	}
+	pipeline, err := c.releasePipelineMgr.Create(ctx, &models.ReleasePipeline{
+		ApplicationID: applicationID,
+		Name:          r.Name,
+		Description:   r.Description,
+		Stages:        stages,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.ofReleasePipeline(ctx, pipeline)
+}
+
+func (c *controller) List(ctx context.Context, applicationID uint) ([]*ReleasePipeline, error) {
+	const op = "release pipeline controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pipelines, err := c.releasePipelineMgr.ListByApplicationID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]*ReleasePipeline, 0, len(pipelines))
+	for _, pipeline := range pipelines {
+		p, err := c.ofReleasePipeline(ctx, pipeline)
+		if err != nil {
+			return nil, err
+		}
+		resp = append(resp, p)
+	}
+	return resp, nil
+}
+
+func (c *controller) Get(ctx context.Context, applicationID, id uint) (*ReleasePipeline, error) {
+	const op = "release pipeline controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pipeline, err := c.get(ctx, applicationID, id)
+	if err != nil {
+		return nil, err
+	}
+	return c.ofReleasePipeline(ctx, pipeline)
+}
+
+func (c *controller) Update(ctx context.Context, applicationID, id uint,
+	r *UpdateReleasePipelineRequest) (*ReleasePipeline, error) {
+	const op = "release pipeline controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pipeline, err := c.get(ctx, applicationID, id)
+	if err != nil {
+		return nil, err
+	}
+	stages := toStages(r.Stages)
+	if err := c.validate(ctx, applicationID, r.Name, stages); err != nil {
+		return nil, err
+	}
+	pipeline.Name = r.Name
+	pipeline.Description = r.Description
+	pipeline.Stages = stages
+	if err := c.releasePipelineMgr.UpdateByID(ctx, id, pipeline); err != nil {
+		return nil, err
+	}
+	return c.ofReleasePipeline(ctx, pipeline)
+}
+
+func (c *controller) Delete(ctx context.Context, applicationID, id uint) error {
+	const op = "release pipeline controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.get(ctx, applicationID, id); err != nil {
+		return err
+	}
+	return c.releasePipelineMgr.DeleteByID(ctx, id)
+}
+
+func (c *controller) Promote(ctx context.Context, applicationID, id uint,
+	r *PromoteRequest) (*PromoteResponse, error) {
+	const op = "release pipeline controller: promote"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pipeline, err := c.get(ctx, applicationID, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Stage <= 0 || r.Stage >= len(pipeline.Stages) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"stage should be in [1, %d), the first stage cannot be promoted to", len(pipeline.Stages))
+	}
+	source, target := pipeline.Stages[r.Stage-1], pipeline.Stages[r.Stage]
+
+	// promoting deploys the target cluster, so the user must be allowed to deploy it
+	if err := rbac.AuthorizeAction(ctx, c.authorizer, common.ResourceCluster,
+		target.ClusterID, "deploy"); err != nil {
+		return nil, err
+	}
+
+	if source.HealthGate {
+		status, err := c.clusterCtl.GetClusterStatusV2(ctx, source.ClusterID)
+		if err != nil {
+			return nil, err
+		}
+		if status.Status != string(health.HealthStatusHealthy) {
+			return nil, perror.Wrapf(herrors.ErrHealthGateNotPassed,
+				"cluster %d of stage %d is %s", source.ClusterID, r.Stage-1, status.Status)
+		}
+	}
+
+	resp, err := c.clusterCtl.Promote(ctx, target.ClusterID, &clusterctl.PromoteRequest{
+		SourceClusterID: source.ClusterID,
+		Title:           r.Title,
+		Description:     r.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PromoteResponse{
+		ClusterID:     target.ClusterID,
+		PipelinerunID: resp.PipelinerunID,
+	}, nil
+}
+
+func (c *controller) get(ctx context.Context, applicationID, id uint) (*models.ReleasePipeline, error) {
+	pipeline, err := c.releasePipelineMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline.ApplicationID != applicationID {
+		return nil, herrors.NewErrNotFound(herrors.ReleasePipelineInDB,
+			fmt.Sprintf("release pipeline %d not found in application %d", id, applicationID))
+	}
+	return pipeline, nil
+}
+
+func (c *controller) validate(ctx context.Context, applicationID uint, name string, stages models.Stages) error {
+	if name == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "name of release pipeline cannot be empty")
+	}
+	if len(stages) < 2 {
+		return perror.Wrap(herrors.ErrParamInvalid, "release pipeline should have at least 2 stages")
+	}
+	seen := make(map[uint]struct{}, len(stages))
+	for _, stage := range stages {
+		if _, ok := seen[stage.ClusterID]; ok {
+			return perror.Wrapf(herrors.ErrParamInvalid, "cluster %d is duplicated in stages", stage.ClusterID)
+		}
+		seen[stage.ClusterID] = struct{}{}
+		cluster, err := c.clusterMgr.GetByID(ctx, stage.ClusterID)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				return perror.Wrapf(herrors.ErrParamInvalid, "cluster %d is not found", stage.ClusterID)
+			}
+			return err
+		}
+		if cluster.ApplicationID != applicationID {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"cluster %s does not belong to the application", cluster.Name)
+		}
+	}
+	return nil
+}
+
+func (c *controller) ofReleasePipeline(ctx context.Context,
+	pipeline *models.ReleasePipeline) (*ReleasePipeline, error) {
+	_, clusters, err := c.clusterMgr.ListByApplicationID(ctx, pipeline.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	clusterMap := make(map[uint]*clustermodels.Cluster, len(clusters))
+	for _, cluster := range clusters {
+		clusterMap[cluster.ID] = cluster.Cluster
+	}
+
+	stages := make([]*StageInfo, 0, len(pipeline.Stages))
+	for _, stage := range pipeline.Stages {
+		info := &StageInfo{
+			ClusterID:  stage.ClusterID,
+			HealthGate: stage.HealthGate,
+		}
+		if cluster, ok := clusterMap[stage.ClusterID]; ok {
+			info.ClusterName = cluster.Name
+			info.Environment = cluster.EnvironmentName
+		}
+		stages = append(stages, info)
+	}
+	return &ReleasePipeline{
+		ID:            pipeline.ID,
+		ApplicationID: pipeline.ApplicationID,
+		Name:          pipeline.Name,
+		Description:   pipeline.Description,
+		Stages:        stages,
+		CreatedBy:     pipeline.CreatedBy,
+		UpdatedBy:     pipeline.UpdatedBy,
+		CreatedAt:     pipeline.CreatedAt,
+		UpdatedAt:     pipeline.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,202 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasepipeline
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	rbacmock "github.com/horizoncd/horizon/mock/pkg/rbac"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/releasepipeline/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ctx     context.Context
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+)
+
+// fakeClusterController records promotions instead of deploying clusters
+type fakeClusterController struct {
+	clusterctl.Controller
+	status   map[uint]string
+	promoted map[uint]uint
+}
+
+func (f *fakeClusterController) GetClusterStatusV2(ctx context.Context,
+	clusterID uint) (*clusterctl.StatusResponseV2, error) {
+	return &clusterctl.StatusResponseV2{Status: f.status[clusterID]}, nil
+}
+
+func (f *fakeClusterController) Promote(ctx context.Context, clusterID uint,
+	r *clusterctl.PromoteRequest) (*clusterctl.PipelinerunIDResponse, error) {
+	f.promoted[clusterID] = r.SourceClusterID
+	return &clusterctl.PipelinerunIDResponse{PipelinerunID: clusterID * 10}, nil
+}
+
+// nolint
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.ReleasePipeline{}, &clustermodels.Cluster{},
+		&regionmodels.Region{}); err != nil {
+		panic(err)
+	}
+	ctx = context.WithValue(context.TODO(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "tony",
+		ID:   uint(1),
+	})
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	clusters := []*clustermodels.Cluster{
+		{ApplicationID: 1, Name: "app-test", EnvironmentName: "test"},
+		{ApplicationID: 1, Name: "app-pre", EnvironmentName: "pre"},
+		{ApplicationID: 1, Name: "app-online", EnvironmentName: "online"},
+		{ApplicationID: 2, Name: "other-test", EnvironmentName: "test"},
+	}
+	for _, cluster := range clusters {
+		assert.Nil(t, db.Create(cluster).Error)
+	}
+	clusterCtl := &fakeClusterController{
+		status:   map[uint]string{},
+		promoted: map[uint]uint{},
+	}
+	mockCtl := gomock.NewController(t)
+	authorizer := rbacmock.NewMockAuthorizer(mockCtl)
+	// tony is overridden to guest on the online cluster
+	authorizer.EXPECT().Authorize(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, attr auth.Attributes) (auth.Decision, string, error) {
+			assert.Equal(t, common.ResourceCluster, attr.GetResource())
+			assert.Equal(t, "deploy", attr.GetSubResource())
+			if attr.GetName() == strconv.Itoa(int(clusters[2].ID)) {
+				return auth.DecisionDeny, "guest", nil
+			}
+			return auth.DecisionAllow, "", nil
+		}).AnyTimes()
+	c := NewController(&param.Param{Manager: manager, Authorizer: authorizer}, clusterCtl)
+
+	// invalid stages
+	_, err := c.Create(ctx, 1, &CreateReleasePipelineRequest{
+		Name:   "release",
+		Stages: []*Stage{{ClusterID: clusters[0].ID}},
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Create(ctx, 1, &CreateReleasePipelineRequest{
+		Name:   "release",
+		Stages: []*Stage{{ClusterID: clusters[0].ID}, {ClusterID: clusters[0].ID}},
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Create(ctx, 1, &CreateReleasePipelineRequest{
+		Name:   "release",
+		Stages: []*Stage{{ClusterID: clusters[0].ID}, {ClusterID: clusters[3].ID}},
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Create(ctx, 1, &CreateReleasePipelineRequest{
+		Name:   "release",
+		Stages: []*Stage{{ClusterID: clusters[0].ID}, {ClusterID: 100}},
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	pipeline, err := c.Create(ctx, 1, &CreateReleasePipelineRequest{
+		Name: "release",
+		Stages: []*Stage{
+			{ClusterID: clusters[0].ID, HealthGate: true},
+			{ClusterID: clusters[1].ID},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(pipeline.Stages))
+	assert.Equal(t, "app-test", pipeline.Stages[0].ClusterName)
+	assert.Equal(t, "pre", pipeline.Stages[1].Environment)
+
+	pipeline, err = c.Update(ctx, 1, pipeline.ID, &UpdateReleasePipelineRequest{
+		Name: "release",
+		Stages: []*Stage{
+			{ClusterID: clusters[0].ID, HealthGate: true},
+			{ClusterID: clusters[1].ID},
+			{ClusterID: clusters[2].ID},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(pipeline.Stages))
+
+	// pipelines are isolated by application
+	_, err = c.Get(ctx, 2, pipeline.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	pipelines, err := c.List(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pipelines))
+
+	// promote
+	_, err = c.Promote(ctx, 1, pipeline.ID, &PromoteRequest{Stage: 0})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Promote(ctx, 1, pipeline.ID, &PromoteRequest{Stage: 3})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	clusterCtl.status[clusters[0].ID] = string(health.HealthStatusProgressing)
+	_, err = c.Promote(ctx, 1, pipeline.ID, &PromoteRequest{Stage: 1})
+	assert.Equal(t, herrors.ErrHealthGateNotPassed, perror.Cause(err))
+	assert.Equal(t, 0, len(clusterCtl.promoted))
+
+	clusterCtl.status[clusters[0].ID] = string(health.HealthStatusHealthy)
+	resp, err := c.Promote(ctx, 1, pipeline.ID, &PromoteRequest{Stage: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, clusters[1].ID, resp.ClusterID)
+	assert.Equal(t, clusters[1].ID*10, resp.PipelinerunID)
+	assert.Equal(t, clusters[0].ID, clusterCtl.promoted[clusters[1].ID])
+
+	// user cannot promote to a cluster that they are not allowed to deploy
+	_, err = c.Promote(ctx, 1, pipeline.ID, &PromoteRequest{Stage: 2})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, ok = clusterCtl.promoted[clusters[2].ID]
+	assert.False(t, ok)
+
+	// stage without health gate is promoted directly
+	pipeline, err = c.Update(ctx, 1, pipeline.ID, &UpdateReleasePipelineRequest{
+		Name: "release",
+		Stages: []*Stage{
+			{ClusterID: clusters[2].ID},
+			{ClusterID: clusters[1].ID},
+			{ClusterID: clusters[0].ID},
+		},
+	})
+	assert.Nil(t, err)
+	_, err = c.Promote(ctx, 1, pipeline.ID, &PromoteRequest{Stage: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, clusters[1].ID, clusterCtl.promoted[clusters[0].ID])
+
+	err = c.Delete(ctx, 1, pipeline.ID)
+	assert.Nil(t, err)
+	pipelines, err = c.List(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(pipelines))
+}
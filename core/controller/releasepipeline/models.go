@@ -0,0 +1,81 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasepipeline
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/releasepipeline/models"
+)
+
+type Stage struct {
+	ClusterID uint `json:"clusterID"`
+	// HealthGate means the cluster must be healthy before being promoted to the next stage
+	HealthGate bool `json:"healthGate"`
+}
+
+type CreateReleasePipelineRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Stages      []*Stage `json:"stages"`
+}
+
+type UpdateReleasePipelineRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Stages      []*Stage `json:"stages"`
+}
+
+type PromoteRequest struct {
+	// Stage is the index of stage to promote to, the previous stage is the source
+	Stage       int    `json:"stage"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type PromoteResponse struct {
+	ClusterID     uint `json:"clusterID"`
+	PipelinerunID uint `json:"pipelinerunID"`
+}
+
+type StageInfo struct {
+	ClusterID   uint   `json:"clusterID"`
+	ClusterName string `json:"clusterName"`
+	Environment string `json:"environment"`
+	HealthGate  bool   `json:"healthGate"`
+}
+
+type ReleasePipeline struct {
+	ID            uint         `json:"id"`
+	ApplicationID uint         `json:"applicationID"`
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Stages        []*StageInfo `json:"stages"`
+	CreatedBy     uint         `json:"createdBy"`
+	UpdatedBy     uint         `json:"updatedBy"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	UpdatedAt     time.Time    `json:"updatedAt"`
+}
+
+func toStages(stages []*Stage) models.Stages {
+	ret := make(models.Stages, 0, len(stages))
+	for _, stage := range stages {
+		ret = append(ret, &models.Stage{
+			ClusterID:  stage.ClusterID,
+			HealthGate: stage.HealthGate,
+		})
+	}
+	return ret
+}
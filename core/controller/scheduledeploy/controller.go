@@ -0,0 +1,154 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduledeploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	scheduledeploymanager "github.com/horizoncd/horizon/pkg/scheduledeploy/manager"
+	"github.com/horizoncd/horizon/pkg/scheduledeploy/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// Create schedules a builddeploy, deploy or restart of cluster at a future time
+	Create(ctx context.Context, clusterID uint, r *CreateScheduledDeployRequest) (*ScheduledDeploy, error)
+	// List lists scheduled deploys of cluster
+	List(ctx context.Context, clusterID uint) ([]*ScheduledDeploy, error)
+	// Cancel cancels a pending scheduled deploy of cluster
+	Cancel(ctx context.Context, clusterID, id uint) error
+}
+
+type controller struct {
+	clusterMgr         clustermanager.Manager
+	envMgr             envmanager.Manager
+	scheduledDeployMgr scheduledeploymanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		clusterMgr:         param.ClusterMgr,
+		envMgr:             param.EnvMgr,
+		scheduledDeployMgr: param.ScheduledDeployMgr,
+	}
+}
+
+func (c *controller) Create(ctx context.Context, clusterID uint,
+	r *CreateScheduledDeployRequest) (*ScheduledDeploy, error) {
+	const op = "scheduled deploy controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	switch r.Action {
+	case models.ActionBuildDeploy, models.ActionDeploy, models.ActionRestart:
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action %s", r.Action)
+	}
+	if !r.ScheduledAt.After(time.Now()) {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "scheduledAt should be a future time")
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the scheduled deploy is executed as its creator,
+	// so check deploy windows in advance to avoid a doomed schedule
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !currentUser.IsAdmin() {
+		env, err := c.envMgr.GetByName(ctx, cluster.EnvironmentName)
+		if err != nil {
+			return nil, err
+		}
+		if !env.DeployWindows.Allowed(r.ScheduledAt) {
+			return nil, perror.Wrapf(herrors.ErrDeployWindowClosed,
+				"%v is not in deploy windows of environment %s", r.ScheduledAt, env.Name)
+		}
+	}
+
+	request := &clusterctl.BuildDeployRequest{
+		Title:       r.Title,
+		Description: r.Description,
+	}
+	if r.Action == models.ActionBuildDeploy {
+		request.Git = r.Git
+	}
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, fmt.Sprintf("failed to marshal request, err: %v", err))
+	}
+
+	scheduledDeploy, err := c.scheduledDeployMgr.Create(ctx, &models.ScheduledDeploy{
+		ClusterID:   clusterID,
+		Action:      r.Action,
+		Request:     string(requestBytes),
+		ScheduledAt: r.ScheduledAt,
+		Status:      models.StatusPending,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ofScheduledDeploy(scheduledDeploy), nil
+}
+
+func (c *controller) List(ctx context.Context, clusterID uint) ([]*ScheduledDeploy, error) {
+	const op = "scheduled deploy controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	scheduledDeploys, err := c.scheduledDeployMgr.ListByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return ofScheduledDeploys(scheduledDeploys), nil
+}
+
+func (c *controller) Cancel(ctx context.Context, clusterID, id uint) error {
+	const op = "scheduled deploy controller: cancel"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	scheduledDeploy, err := c.scheduledDeployMgr.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if scheduledDeploy.ClusterID != clusterID {
+		return herrors.NewErrNotFound(herrors.ScheduledDeployInDB,
+			fmt.Sprintf("scheduled deploy %d not found in cluster %d", id, clusterID))
+	}
+
+	cancelled, err := c.scheduledDeployMgr.UpdateStatus(ctx, id, models.StatusPending, models.StatusCancelled)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"scheduled deploy %d cannot be cancelled, only pending ones can be cancelled", id)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduledeploy
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/scheduledeploy/models"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	ctx     = context.Background()
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&clustermodels.Cluster{}, &envmodels.Environment{}, &membermodels.Member{},
+		&usermodels.User{}, &tagmodels.Tag{}, &models.ScheduledDeploy{}); err != nil {
+		panic(err)
+	}
+	// nolint
+	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "horizon",
+		ID:   uint(1),
+	})
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	now := time.Now()
+	// only the current hour of tomorrow is allowed
+	tomorrow := now.Add(24 * time.Hour)
+	_, err := manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{
+		Name: "online",
+		DeployWindows: envmodels.DeployWindows{
+			{
+				Weekdays:  []time.Weekday{tomorrow.Weekday()},
+				StartTime: tomorrow.Truncate(time.Hour).Format("15:04"),
+				EndTime:   tomorrow.Truncate(time.Hour).Add(59 * time.Minute).Format("15:04"),
+			},
+		},
+	})
+	assert.Nil(t, err)
+	cluster, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+		Name:            "cluster",
+		EnvironmentName: "online",
+	}, nil, nil)
+	assert.Nil(t, err)
+
+	ctl := NewController(&param.Param{Manager: manager})
+
+	// invalid requests
+	_, err = ctl.Create(ctx, cluster.ID, &CreateScheduledDeployRequest{
+		Action:      "rollback",
+		ScheduledAt: tomorrow,
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctl.Create(ctx, cluster.ID, &CreateScheduledDeployRequest{
+		Action:      models.ActionRestart,
+		ScheduledAt: now.Add(-time.Minute),
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// not in deploy windows
+	_, err = ctl.Create(ctx, cluster.ID, &CreateScheduledDeployRequest{
+		Action:      models.ActionRestart,
+		ScheduledAt: tomorrow.Add(time.Hour),
+	})
+	assert.Equal(t, herrors.ErrDeployWindowClosed, perror.Cause(err))
+
+	// admins are allowed to schedule at any time
+	// nolint
+	adminCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "admin",
+		ID:    uint(2),
+		Admin: true,
+	})
+	restart, err := ctl.Create(adminCtx, cluster.ID, &CreateScheduledDeployRequest{
+		Action:      models.ActionRestart,
+		ScheduledAt: tomorrow.Add(time.Hour),
+	})
+	assert.Nil(t, err)
+
+	buildDeploy, err := ctl.Create(ctx, cluster.ID, &CreateScheduledDeployRequest{
+		Action:      models.ActionBuildDeploy,
+		ScheduledAt: tomorrow.Truncate(time.Hour),
+		Title:       "nightly",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusPending, buildDeploy.Status)
+	assert.Equal(t, "nightly", buildDeploy.Title)
+
+	scheduledDeploys, err := ctl.List(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(scheduledDeploys))
+	assert.Equal(t, restart.ID, scheduledDeploys[0].ID)
+
+	// cancel
+	err = ctl.Cancel(ctx, cluster.ID+1, buildDeploy.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	assert.Nil(t, ctl.Cancel(ctx, cluster.ID, buildDeploy.ID))
+	err = ctl.Cancel(ctx, cluster.ID, buildDeploy.ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	scheduledDeploys, err = ctl.List(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusCancelled, scheduledDeploys[1].Status)
+}
@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduledeploy
+
+import (
+	"encoding/json"
+	"time"
+
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/pkg/scheduledeploy/models"
+)
+
+type CreateScheduledDeployRequest struct {
+	// Action is one of builddeploy, deploy and restart
+	Action      string    `json:"action"`
+	ScheduledAt time.Time `json:"scheduledAt"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	// Git is only used by builddeploy, the git ref of cluster is used if it's empty
+	Git *clusterctl.BuildDeployRequestGit `json:"git,omitempty"`
+}
+
+type ScheduledDeploy struct {
+	ID            uint                              `json:"id"`
+	ClusterID     uint                              `json:"clusterID"`
+	Action        string                            `json:"action"`
+	ScheduledAt   time.Time                         `json:"scheduledAt"`
+	Title         string                            `json:"title"`
+	Description   string                            `json:"description"`
+	Git           *clusterctl.BuildDeployRequestGit `json:"git,omitempty"`
+	Status        string                            `json:"status"`
+	PipelinerunID uint                              `json:"pipelinerunID,omitempty"`
+	Message       string                            `json:"message,omitempty"`
+	CreatedBy     uint                              `json:"createdBy"`
+	CreatedAt     time.Time                         `json:"createdAt"`
+	UpdatedAt     time.Time                         `json:"updatedAt"`
+}
+
+func ofScheduledDeploy(scheduledDeploy *models.ScheduledDeploy) *ScheduledDeploy {
+	resp := &ScheduledDeploy{
+		ID:            scheduledDeploy.ID,
+		ClusterID:     scheduledDeploy.ClusterID,
+		Action:        scheduledDeploy.Action,
+		ScheduledAt:   scheduledDeploy.ScheduledAt,
+		Status:        scheduledDeploy.Status,
+		PipelinerunID: scheduledDeploy.PipelinerunID,
+		Message:       scheduledDeploy.Message,
+		CreatedBy:     scheduledDeploy.CreatedBy,
+		CreatedAt:     scheduledDeploy.CreatedAt,
+		UpdatedAt:     scheduledDeploy.UpdatedAt,
+	}
+	var request clusterctl.BuildDeployRequest
+	if err := json.Unmarshal([]byte(scheduledDeploy.Request), &request); err == nil {
+		resp.Title = request.Title
+		resp.Description = request.Description
+		resp.Git = request.Git
+	}
+	return resp
+}
+
+func ofScheduledDeploys(scheduledDeploys []*models.ScheduledDeploy) []*ScheduledDeploy {
+	resp := make([]*ScheduledDeploy, 0, len(scheduledDeploys))
+	for _, scheduledDeploy := range scheduledDeploys {
+		resp = append(resp, ofScheduledDeploy(scheduledDeploy))
+	}
+	return resp
+}
@@ -43,6 +43,7 @@ import (
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	"github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/templaterelease/source"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
 	"github.com/horizoncd/horizon/pkg/util/permission"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
@@ -79,12 +80,13 @@ type Controller interface {
 	UpdateTemplate(ctx context.Context, templateID uint, request UpdateTemplateRequest) error
 	// UpdateRelease deletes a template release by ID
 	UpdateRelease(ctx context.Context, releaseID uint, request UpdateReleaseRequest) error
-	// SyncReleaseToRepo downloads template from gitlab, packages the template and uploads it to chart repo
+	// SyncReleaseToRepo downloads template from its source, packages the template and uploads it to chart repo
 	SyncReleaseToRepo(ctx context.Context, releaseID uint) error
 }
 
 type controller struct {
 	gitgetter            git.Helper
+	sourceGetter         source.Getter
 	templateRepo         templaterepo.TemplateRepo
 	groupMgr             gmanager.Manager
 	templateMgr          tmanager.Manager
@@ -100,6 +102,7 @@ var _ Controller = (*controller)(nil)
 func NewController(param *param.Param, repo templaterepo.TemplateRepo) Controller {
 	return &controller{
 		gitgetter:            param.GitGetter,
+		sourceGetter:         param.TemplateSourceGetter,
 		templateMgr:          param.TemplateMgr,
 		templateReleaseMgr:   param.TemplateReleaseManager,
 		templateSchemaGetter: param.TemplateSchemaGetter,
@@ -608,16 +611,18 @@ func (c *controller) CreateRelease(ctx context.Context,
 	}
 
 	if syncToRepo, ok := ctx.Value(hctx.ReleaseSyncToRepo).(bool); !ok || (ok && syncToRepo) {
-		tag, err := c.getTag(ctx, template.Repository, template.ChartName, release.Name)
+		archive, err := c.getArchive(ctx, template, release.Name)
 		if err != nil {
 			return nil, err
 		}
-		chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, tag.ShortID)
-		err = c.syncReleaseToRepo(tag.ArchiveData, template.ChartName, chartVersion)
+		revision := source.ShortDigest(archive.Digest)
+		chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, revision)
+		err = c.syncReleaseToRepo(archive.Data, template.ChartName, chartVersion)
 		if err != nil {
 			return nil, err
 		}
-		release.CommitID = tag.ShortID
+		release.CommitID = revision
+		release.Digest = archive.Digest
 		release.SyncStatus = trmodels.StatusSucceed
 		release.ChartVersion = chartVersion
 	} else {
@@ -773,6 +778,12 @@ func (c *controller) UpdateTemplate(ctx context.Context, templateID uint, reques
 			"can not modify template repository while releases existing:\n"+
 				"releases numbers: %d", len(releases))
 	}
+	if len(releases) != 0 && request.SourceKind != "" &&
+		request.SourceKind != template.SourceKind {
+		return perror.Wrapf(herrors.ErrForbidden,
+			"can not modify template source kind while releases existing:\n"+
+				"releases numbers: %d", len(releases))
+	}
 
 	tplUpdate, err := request.toTemplateModel(ctx)
 	if err != nil {
@@ -809,43 +820,64 @@ func (c *controller) SyncReleaseToRepo(ctx context.Context, releaseID uint) erro
 		return err
 	}
 
-	tag, err := c.getTag(ctx, template.Repository, template.ChartName, release.Name)
+	archive, err := c.getArchive(ctx, template, release.Name)
 	if err != nil {
 		return err
 	}
-	chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, tag.ShortID)
-	err = c.syncReleaseToRepo(tag.ArchiveData, template.ChartName, chartVersion)
+	chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, source.ShortDigest(archive.Digest))
+	err = c.syncReleaseToRepo(archive.Data, template.ChartName, chartVersion)
 	if err != nil {
-		_ = c.handleReleaseSyncStatus(ctx, release, tag.ShortID, err.Error())
+		_ = c.handleReleaseSyncStatus(ctx, release, archive.Digest, err.Error())
 	} else {
-		_ = c.handleReleaseSyncStatus(ctx, release, tag.ShortID, "")
+		_ = c.handleReleaseSyncStatus(ctx, release, archive.Digest, "")
 	}
 	return err
 }
 
 func (c *controller) handleReleaseSyncStatus(ctx context.Context,
-	release *trmodels.TemplateRelease, commitID string, failedReason string) error {
+	release *trmodels.TemplateRelease, digest string, failedReason string) error {
+	revision := source.ShortDigest(digest)
 	if failedReason == "" {
 		release.SyncStatus = trmodels.StatusSucceed
 		release.FailedReason = ""
-		release.ChartVersion = fmt.Sprintf(common.ChartVersionFormat, release.Name, commitID)
+		release.ChartVersion = fmt.Sprintf(common.ChartVersionFormat, release.Name, revision)
 	} else {
 		release.FailedReason = failedReason
 		release.SyncStatus = trmodels.StatusFailed
 	}
-	release.CommitID = commitID
+	release.CommitID = revision
+	release.Digest = digest
 	release.LastSyncAt = time.Now()
 	return c.templateReleaseMgr.UpdateByID(ctx, release.ID, release)
 }
 
-func (c *controller) getTag(ctx context.Context, repository,
-	name, tag string) (*git.Tag, error) {
-	tagDetail, err := c.gitgetter.GetTagArchive(ctx, repository, tag)
-	if err != nil {
-		return nil, err
+// getArchive pulls the chart archive of version from source of the template,
+// digest of archive from git is the commit id of tag
+func (c *controller) getArchive(ctx context.Context,
+	template *models.Template, version string) (*source.Archive, error) {
+	if template.SourceKind == "" || template.SourceKind == models.SourceKindGit {
+		tag, err := c.gitgetter.GetTagArchive(ctx, template.Repository, version)
+		if err != nil {
+			return nil, err
+		}
+		return &source.Archive{
+			Digest: tag.ShortID,
+			Data:   tag.ArchiveData,
+		}, nil
 	}
+	return c.sourceGetter.GetArchive(ctx, template.SourceKind, template.Repository, version)
+}
 
-	return tagDetail, nil
+func (c *controller) getDigest(ctx context.Context,
+	template *models.Template, version string) (string, error) {
+	if template.SourceKind == "" || template.SourceKind == models.SourceKindGit {
+		archive, err := c.getArchive(ctx, template, version)
+		if err != nil {
+			return "", err
+		}
+		return archive.Digest, nil
+	}
+	return c.sourceGetter.GetDigest(ctx, template.SourceKind, template.Repository, version)
 }
 
 func (c *controller) checkStatusForReleases(ctx context.Context,
@@ -871,12 +903,12 @@ func (c *controller) checkStatusForRelease(ctx context.Context,
 		return release, nil
 	}
 
-	tag, err := c.getTag(ctx, template.Repository, release.ChartName, release.Name)
+	digest, err := c.getDigest(ctx, template, release.Name)
 	if err != nil {
 		release.SyncStatus = trmodels.StatusUnknown
 		return release, err
 	}
-	if tag.ShortID != release.CommitID {
+	if digest != release.Digest {
 		release.SyncStatus = trmodels.StatusOutOfSync
 	}
 	return release, nil
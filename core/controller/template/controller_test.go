@@ -35,6 +35,7 @@ import (
 	releasemanagermock "github.com/horizoncd/horizon/mock/pkg/templaterelease/manager"
 	trmock "github.com/horizoncd/horizon/mock/pkg/templaterelease/manager"
 	trschemamock "github.com/horizoncd/horizon/mock/pkg/templaterelease/schema"
+	sourcemock "github.com/horizoncd/horizon/mock/pkg/templaterelease/source"
 	mock_repo "github.com/horizoncd/horizon/mock/pkg/templaterepo"
 	amodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
@@ -55,6 +56,8 @@ import (
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	reposchema "github.com/horizoncd/horizon/pkg/templaterelease/schema/repo"
+	"github.com/horizoncd/horizon/pkg/templaterelease/source"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
@@ -157,6 +160,7 @@ func TestList(t *testing.T) {
 				Template:    1,
 				Name:        tags[0],
 				CommitID:    "test",
+				Digest:      "test",
 				SyncStatus:  trmodels.StatusSucceed,
 				Recommended: &recommends[0],
 				OnlyOwner:   &onlyOwnerTrue,
@@ -167,6 +171,7 @@ func TestList(t *testing.T) {
 				Template:    1,
 				Name:        tags[1],
 				CommitID:    "test",
+				Digest:      "test",
 				SyncStatus:  trmodels.StatusSucceed,
 				Recommended: &recommends[1],
 				OnlyOwner:   &onlyOwnerFalse,
@@ -177,6 +182,7 @@ func TestList(t *testing.T) {
 				Template:    1,
 				Name:        tags[2],
 				CommitID:    "test3",
+				Digest:      "test3",
 				SyncStatus:  trmodels.StatusSucceed,
 				Recommended: &recommends[2],
 				OnlyOwner:   &onlyOwnerFalse,
@@ -670,3 +676,79 @@ func createChart(t *testing.T, ctl Controller, groupID uint) {
 	_, err = ctl.CreateRelease(ctx, template.ID, request.CreateReleaseRequest)
 	assert.Nil(t, err)
 }
+
+func TestCreateReleaseFromOCI(t *testing.T) {
+	createContext()
+	mockCtl := gomock.NewController(t)
+	repo := mock_repo.NewMockTemplateRepo(mockCtl)
+	sourceGetter := sourcemock.NewMockGetter(mockCtl)
+	ctl := &controller{
+		sourceGetter:       sourceGetter,
+		templateRepo:       repo,
+		groupMgr:           mgr.GroupManager,
+		templateMgr:        mgr.TemplateMgr,
+		templateReleaseMgr: mgr.TemplateReleaseManager,
+		memberMgr:          mgr.MemberManager,
+	}
+
+	var buf bytes.Buffer
+	err := templaterepo.ChartSerialize(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "javaapp", Version: "1.0.0"},
+	}, &buf)
+	assert.Nil(t, err)
+
+	ociRepo := "oci://harbor.example.com/charts/javaapp"
+	digest := "sha256:5e5193b3c0b8e4e8b8a7dd3f0d2b2f4e8e6d8b7c6a5f4e3d2c1b0a9f8e7d6c5b"
+	sourceGetter.EXPECT().GetArchive(gomock.Any(), tmodels.SourceKindOCI, ociRepo, "v1.0.0").
+		Return(&source.Archive{Digest: digest, Data: buf.Bytes()}, nil)
+	repo.EXPECT().UploadChart(gomock.Any()).DoAndReturn(func(c *chart.Chart) error {
+		assert.Equal(t, "v1.0.0-5e5193b3", c.Metadata.Version)
+		return nil
+	})
+
+	_, err = ctl.CreateTemplate(ctx, 0, CreateTemplateRequest{
+		Name:       "javaapp",
+		Repository: "harbor.example.com",
+		SourceKind: tmodels.SourceKindOCI,
+	})
+	assert.Equal(t, herrors.ErrTemplateParamInvalid, perror.Cause(err))
+
+	template, err := ctl.CreateTemplate(ctx, 0, CreateTemplateRequest{
+		Name:       "javaapp",
+		Repository: ociRepo,
+		SourceKind: tmodels.SourceKindOCI,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, tmodels.SourceKindOCI, template.SourceKind)
+	release, err := ctl.CreateRelease(ctx, template.ID, CreateReleaseRequest{Name: "v1.0.0"})
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.0-5e5193b3", release.ChartVersion)
+	assert.Equal(t, digest, release.Digest)
+
+	templateModel, err := mgr.TemplateMgr.GetByID(ctx, template.ID)
+	assert.Nil(t, err)
+
+	// chart is not changed
+	sourceGetter.EXPECT().GetDigest(gomock.Any(), tmodels.SourceKindOCI, ociRepo, "v1.0.0").
+		Return(digest, nil)
+	releaseModel, err := ctl.checkStatusForRelease(ctx, templateModel, &trmodels.TemplateRelease{
+		Name: "v1.0.0", Digest: digest, SyncStatus: trmodels.StatusSucceed,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, trmodels.StatusSucceed, releaseModel.SyncStatus)
+
+	// chart is pushed again
+	sourceGetter.EXPECT().GetDigest(gomock.Any(), tmodels.SourceKindOCI, ociRepo, "v1.0.0").
+		Return("sha256:0d2b2f4e", nil)
+	releaseModel, err = ctl.checkStatusForRelease(ctx, templateModel, releaseModel)
+	assert.Nil(t, err)
+	assert.Equal(t, trmodels.StatusOutOfSync, releaseModel.SyncStatus)
+
+	// source kind can not be changed while releases existing
+	err = ctl.UpdateTemplate(ctx, template.ID, UpdateTemplateRequest{
+		Name:       "javaapp",
+		Repository: ociRepo,
+		SourceKind: tmodels.SourceKindHelm,
+	})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+}
@@ -16,8 +16,10 @@ package template
 
 import (
 	"context"
+	"net/url"
 	"regexp"
 	"sort"
+	"strings"
 	"time"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -25,6 +27,7 @@ import (
 	tmodels "github.com/horizoncd/horizon/pkg/template/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/templaterepo/oci"
 )
 
 type CreateTemplateRequest struct {
@@ -34,7 +37,9 @@ type CreateTemplateRequest struct {
 	Name                 string `json:"name"`
 	Description          string `json:"description"`
 	Repository           string `json:"repository"`
-	OnlyOwner            bool   `json:"onlyOwner"`
+	// SourceKind is the kind of repository: git, oci or helm, default is git
+	SourceKind string `json:"sourceKind"`
+	OnlyOwner  bool   `json:"onlyOwner"`
 }
 
 func (c *CreateTemplateRequest) toTemplateModel(ctx context.Context) (*tmodels.Template, error) {
@@ -42,6 +47,9 @@ func (c *CreateTemplateRequest) toTemplateModel(ctx context.Context) (*tmodels.T
 		return nil, perror.Wrap(herrors.ErrTemplateParamInvalid,
 			"Repository is empty")
 	}
+	if err := checkSource(c.SourceKind, c.Repository); err != nil {
+		return nil, err
+	}
 	if !checkIfNameValid(c.Name) {
 		return nil, perror.Wrap(herrors.ErrTemplateParamInvalid,
 			"Name starts with a letter and consists of an "+
@@ -51,6 +59,7 @@ func (c *CreateTemplateRequest) toTemplateModel(ctx context.Context) (*tmodels.T
 		Name:        c.Name,
 		Description: c.Description,
 		Repository:  c.Repository,
+		SourceKind:  c.SourceKind,
 		OnlyOwner:   &c.OnlyOwner,
 	}
 	return t, nil
@@ -81,6 +90,7 @@ type UpdateTemplateRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Repository  string `json:"repository"`
+	SourceKind  string `json:"sourceKind"`
 	OnlyOwner   bool   `json:"onlyOwner"`
 	WithoutCI   bool   `json:"withoutCI"`
 }
@@ -90,6 +100,9 @@ func (c *UpdateTemplateRequest) toTemplateModel(ctx context.Context) (*tmodels.T
 		return nil, perror.Wrap(herrors.ErrTemplateParamInvalid,
 			"Repository is empty")
 	}
+	if err := checkSource(c.SourceKind, c.Repository); err != nil {
+		return nil, err
+	}
 	if !checkIfNameValid(c.Name) {
 		return nil, perror.Wrap(herrors.ErrTemplateParamInvalid,
 			"Name starts with a letter and consists of an "+
@@ -100,6 +113,7 @@ func (c *UpdateTemplateRequest) toTemplateModel(ctx context.Context) (*tmodels.T
 		Name:        c.Name,
 		Description: c.Description,
 		Repository:  c.Repository,
+		SourceKind:  c.SourceKind,
 		OnlyOwner:   &c.OnlyOwner,
 		WithoutCI:   c.WithoutCI,
 	}
@@ -129,6 +143,7 @@ type Template struct {
 	ChartName   string    `json:"chartName"`
 	Description string    `json:"description"`
 	Repository  string    `json:"repository"`
+	SourceKind  string    `json:"sourceKind"`
 	Releases    Releases  `json:"releases,omitempty"`
 	FullPath    string    `json:"fullPath,omitempty"`
 	GroupID     uint      `json:"group"`
@@ -150,6 +165,7 @@ func toTemplate(m *tmodels.Template) *Template {
 		ChartName:   m.ChartName,
 		Description: m.Description,
 		Repository:  m.Repository,
+		SourceKind:  m.SourceKind,
 		GroupID:     m.GroupID,
 		WithoutCI:   m.WithoutCI,
 		CreatedAt:   m.Model.CreatedAt,
@@ -188,6 +204,7 @@ type Release struct {
 	Recommended    bool      `json:"recommended"`
 	OnlyOwner      bool      `json:"onlyOwner"`
 	CommitID       string    `json:"commitID"`
+	Digest         string    `json:"digest"`
 	SyncStatusCode uint8     `json:"syncStatusCode"`
 	SyncStatus     string    `json:"syncStatus"`
 	LastSyncAt     time.Time `json:"lastSyncAt"`
@@ -233,6 +250,7 @@ func toRelease(m *trmodels.TemplateRelease) *Release {
 		SyncStatusCode: uint8(m.SyncStatus),
 		LastSyncAt:     m.LastSyncAt,
 		CommitID:       m.CommitID,
+		Digest:         m.Digest,
 		FailedReason:   m.FailedReason,
 		CreatedAt:      m.Model.CreatedAt,
 		UpdatedAt:      m.Model.UpdatedAt,
@@ -316,3 +334,24 @@ func checkIfNameValid(name string) bool {
 	pattern := regexp.MustCompile("^(([a-z][-a-z0-9_]*)?[a-z0-9])?$")
 	return pattern.MatchString(name)
 }
+
+// checkSource checks the repository matches the source kind
+func checkSource(kind, repository string) error {
+	switch kind {
+	case "", tmodels.SourceKindGit:
+	case tmodels.SourceKindOCI:
+		if !strings.Contains(strings.TrimPrefix(repository, oci.Scheme), "/") {
+			return perror.Wrap(herrors.ErrTemplateParamInvalid,
+				"Repository of oci should be like oci://harbor.example.com/charts/javaapp")
+		}
+	case tmodels.SourceKindHelm:
+		u, err := url.Parse(repository)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || strings.Trim(u.Path, "/") == "" {
+			return perror.Wrap(herrors.ErrTemplateParamInvalid,
+				"Repository of helm should be like https://charts.example.com/stable/javaapp")
+		}
+	default:
+		return perror.Wrapf(herrors.ErrTemplateParamInvalid, "SourceKind %s is not supported", kind)
+	}
+	return nil
+}
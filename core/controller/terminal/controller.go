@@ -22,19 +22,28 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
 	envregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	terminalrecordingmanager "github.com/horizoncd/horizon/pkg/terminalrecording/manager"
+	recordingmodels "github.com/horizoncd/horizon/pkg/terminalrecording/models"
 	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 	"k8s.io/client-go/tools/remotecommand"
 
@@ -47,6 +56,10 @@ type Controller interface {
 	// CreateShell returns sessionID and sockJSHandler according to clusterID,podName,containerName
 	CreateShell(ctx context.Context, clusterID uint, podName, containerName string) (sessionID string,
 		sockJSHandler http.Handler, err error)
+	// ListRecordings lists terminal recordings of the cluster, the latest first
+	ListRecordings(ctx context.Context, clusterID uint, query *q.Query) ([]*Recording, int64, error)
+	// GetRecording gets the content of a terminal recording of the cluster in asciinema v2 format
+	GetRecording(ctx context.Context, clusterID, recordingID uint) ([]byte, error)
 }
 
 type controller struct {
@@ -58,6 +71,8 @@ type controller struct {
 	envRegionMgr       envregionmanager.Manager
 	regionMgr          regionmanager.Manager
 	clusterGitRepo     gitrepo.ClusterGitRepo
+	tektonFty          factory.Factory
+	recordingMgr       terminalrecordingmanager.Manager
 }
 
 var _ Controller = (*controller)(nil)
@@ -72,6 +87,8 @@ func NewController(param *param.Param) Controller {
 		envRegionMgr:       param.EnvRegionMgr,
 		regionMgr:          param.RegionMgr,
 		clusterGitRepo:     param.ClusterGitRepo,
+		tektonFty:          param.TektonFty,
+		recordingMgr:       param.TerminalRecordingMgr,
 	}
 }
 
@@ -137,10 +154,16 @@ func (c *controller) GetSockJSHandler(ctx context.Context, sessionID string) (ht
 		RandomID:    randomID,
 	}
 
+	recording, err := c.startRecording(ctx, cluster, ref)
+	if err != nil {
+		return nil, err
+	}
+
 	terminalSessions.Set(ref.String(), Session{
-		id:       ref.String(),
-		bound:    make(chan error),
-		sizeChan: make(chan remotecommand.TerminalSize),
+		id:        ref.String(),
+		bound:     make(chan error, 1),
+		sizeChan:  make(chan remotecommand.TerminalSize),
+		recording: recording,
 	})
 
 	go WaitForTerminal(kubeClient.Basic, kubeConfig, ref)
@@ -199,10 +222,16 @@ func (c *controller) CreateShell(ctx context.Context, clusterID uint, podName,
 		RandomID:    randomID,
 	}
 
+	recording, err := c.startRecording(ctx, cluster, ref)
+	if err != nil {
+		return "", nil, err
+	}
+
 	terminalSessions.Set(ref.String(), Session{
-		id:       ref.String(),
-		bound:    make(chan error),
-		sizeChan: make(chan remotecommand.TerminalSize),
+		id:        ref.String(),
+		bound:     make(chan error, 1),
+		sizeChan:  make(chan remotecommand.TerminalSize),
+		recording: recording,
 	})
 
 	handler := sockjs.NewHandler("/apis/core/v1", sockjs.DefaultOptions, handleShellSession(ctx, ref.String()))
@@ -211,6 +240,99 @@ func (c *controller) CreateShell(ctx context.Context, clusterID uint, podName,
 	return randomID, handler, nil
 }
 
+// startRecording checks the terminal policy of the environment, and starts recording the session if possible
+func (c *controller) startRecording(ctx context.Context, cluster *clustermodels.Cluster,
+	ref ContainerRef) (*sessionRecording, error) {
+	env, err := c.envMgr.GetByName(ctx, cluster.EnvironmentName)
+	if err != nil {
+		return nil, err
+	}
+	if env.TerminalPolicy == envmodels.TerminalPolicyDisabled {
+		return nil, perror.Wrapf(herrors.ErrShellDisabled, "shell access is disabled in environment %s", env.Name)
+	}
+	storage, err := c.tektonFty.GetLogStorage(env.Name)
+	if err != nil {
+		if env.TerminalPolicy == envmodels.TerminalPolicyRecord {
+			return nil, perror.Wrapf(herrors.ErrShellDisabled,
+				"shell sessions must be recorded in environment %s, but failed to get log storage: %v", env.Name, err)
+		}
+		log.Warningf(ctx, "shell session of cluster %s is not recorded: %v", cluster.Name, err)
+		return nil, nil
+	}
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	recording, err := c.recordingMgr.Create(ctx, &recordingmodels.TerminalRecording{
+		SessionID:   ref.String(),
+		ClusterID:   cluster.ID,
+		Environment: env.Name,
+		Pod:         ref.Pod,
+		Container:   ref.Container,
+		UserID:      currentUser.GetID(),
+		UserName:    currentUser.GetName(),
+		ObjectPath:  recordingPath(env.Name, ref, currentUser.GetID(), now),
+		StartedAt:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newSessionRecording(storage, c.recordingMgr, recording,
+		env.TerminalPolicy == envmodels.TerminalPolicyRecord), nil
+}
+
+func (c *controller) ListRecordings(ctx context.Context, clusterID uint,
+	query *q.Query) ([]*Recording, int64, error) {
+	const op = "terminal controller: list recordings"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if query.Keywords == nil {
+		query.Keywords = q.KeyWords{}
+	}
+	query.Keywords[common.TerminalRecordingQueryClusterID] = clusterID
+	recordings, total, err := c.recordingMgr.List(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp := make([]*Recording, 0, len(recordings))
+	for _, recording := range recordings {
+		resp = append(resp, ofRecording(recording))
+	}
+	return resp, total, nil
+}
+
+func (c *controller) GetRecording(ctx context.Context, clusterID, recordingID uint) ([]byte, error) {
+	const op = "terminal controller: get recording"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	recording, err := c.recordingMgr.GetByID(ctx, recordingID)
+	if err != nil {
+		return nil, err
+	}
+	if recording.ClusterID != clusterID {
+		return nil, herrors.NewErrNotFound(herrors.TerminalRecordingInDB,
+			fmt.Sprintf("terminal recording %d not found in cluster %d", recordingID, clusterID))
+	}
+	if recording.EndedAt == nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "terminal recording %d is in progress", recordingID)
+	}
+	storage, err := c.tektonFty.GetLogStorage(recording.Environment)
+	if err != nil {
+		return nil, err
+	}
+	content := make([]byte, 0, recording.Size)
+	for i := 0; i < recording.Chunks; i++ {
+		chunk, err := storage.GetObject(ctx, recordingChunkPath(recording.ObjectPath, i))
+		if err != nil {
+			return nil, herrors.NewErrGetFailed(herrors.LogStorage, err.Error())
+		}
+		content = append(content, chunk...)
+	}
+	return content, nil
+}
+
 func genRandomID() (string, error) {
 	bytes := make([]byte, 5)
 	if _, err := rand.Read(bytes); err != nil {
@@ -14,6 +14,38 @@
 
 package terminal
 
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/terminalrecording/models"
+)
+
 type SessionIDResp struct {
 	ID string `json:"id"`
 }
+
+type Recording struct {
+	ID        uint       `json:"id"`
+	ClusterID uint       `json:"clusterID"`
+	Pod       string     `json:"pod"`
+	Container string     `json:"container"`
+	UserID    uint       `json:"userID"`
+	UserName  string     `json:"userName"`
+	Size      int64      `json:"size"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt"`
+}
+
+func ofRecording(recording *models.TerminalRecording) *Recording {
+	return &Recording{
+		ID:        recording.ID,
+		ClusterID: recording.ClusterID,
+		Pod:       recording.Pod,
+		Container: recording.Container,
+		UserID:    recording.UserID,
+		UserName:  recording.UserName,
+		Size:      recording.Size,
+		StartedAt: recording.StartedAt,
+		EndedAt:   recording.EndedAt,
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/lib/s3"
+	terminalrecordingmanager "github.com/horizoncd/horizon/pkg/terminalrecording/manager"
+	"github.com/horizoncd/horizon/pkg/terminalrecording/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_recordingDir = "terminalrecordings"
+	// _maxRecordingSize limits the size of a recording, the events beyond are dropped,
+	// or the session is ended if it must be recorded
+	_maxRecordingSize = 64 << 20
+	// _recordingChunkSize is the size of pending events that triggers a flush into the log storage
+	_recordingChunkSize     = 1 << 20
+	_recordingFlushInterval = time.Minute
+	_recordingSaveTimeout   = time.Minute
+	_defaultTerminalWidth   = 80
+	_defaultTerminalHeight  = 24
+
+	_eventInput  = "i"
+	_eventOutput = "o"
+	_eventResize = "r"
+)
+
+// castHeader is the header of asciinema v2 format,
+// see https://docs.asciinema.org/manual/asciicast/v2/
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     uint16            `json:"width"`
+	Height    uint16            `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// recorder records input, output and resize events of a terminal session in asciinema v2 format,
+// and keeps the events until they are flushed
+type recorder struct {
+	lock  sync.Mutex
+	start time.Time
+	title string
+	// width and height of the terminal before any events
+	width  uint16
+	height uint16
+	// header is marshaled when it is flushed the first time, and nil before
+	header []byte
+	// events are the events not flushed yet
+	events bytes.Buffer
+	// size is the bytes of all the events recorded
+	size int
+	full bool
+	// pending is notified when the events grow to a chunk, or the recording becomes full
+	pending chan struct{}
+}
+
+func newRecorder(start time.Time, title string) *recorder {
+	return &recorder{
+		start:   start,
+		title:   title,
+		width:   _defaultTerminalWidth,
+		height:  _defaultTerminalHeight,
+		pending: make(chan struct{}, 1),
+	}
+}
+
+func (r *recorder) Input(data string) {
+	r.record(_eventInput, data)
+}
+
+func (r *recorder) Output(data []byte) {
+	r.record(_eventOutput, string(data))
+}
+
+func (r *recorder) Resize(width, height uint16) {
+	r.lock.Lock()
+	// the size before any events is taken as the initial size in header
+	if r.size == 0 {
+		r.width, r.height = width, height
+		r.lock.Unlock()
+		return
+	}
+	r.lock.Unlock()
+	r.record(_eventResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+func (r *recorder) record(code, data string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.full {
+		return
+	}
+	elapsed := float64(time.Since(r.start).Microseconds()) / float64(time.Second/time.Microsecond)
+	event, err := json.Marshal([]interface{}{elapsed, code, data})
+	if err != nil {
+		return
+	}
+	if r.size+len(event)+1 > _maxRecordingSize {
+		r.full = true
+		r.notify()
+		return
+	}
+	r.events.Write(event)
+	r.events.WriteByte('\n')
+	r.size += len(event) + 1
+	if r.events.Len() >= _recordingChunkSize {
+		r.notify()
+	}
+}
+
+func (r *recorder) notify() {
+	select {
+	case r.pending <- struct{}{}:
+	default:
+	}
+}
+
+// Full reports whether the recording reaches the size limit and drops the events since
+func (r *recorder) Full() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.full
+}
+
+// Pending returns the events not flushed yet, with the header ahead if it is not flushed either.
+// It returns nil if no events are pending, unless the header is not flushed and it is the final flush.
+func (r *recorder) Pending(final bool) []byte {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.events.Len() == 0 && (r.header != nil || !final) {
+		return nil
+	}
+	header := r.header
+	if header == nil {
+		marshaled, _ := json.Marshal(castHeader{
+			Version:   2,
+			Width:     r.width,
+			Height:    r.height,
+			Timestamp: r.start.Unix(),
+			Title:     r.title,
+			Env:       map[string]string{"TERM": "xterm"},
+		})
+		header = append(marshaled, '\n')
+	}
+	content := make([]byte, 0, len(header)+r.events.Len())
+	if r.header == nil {
+		content = append(content, header...)
+	}
+	return append(content, r.events.Bytes()...)
+}
+
+// Flushed discards the content returned by Pending after it is flushed
+func (r *recorder) Flushed(content []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	n := len(content)
+	if r.header == nil {
+		// the header is the first line of content
+		headerLen := bytes.IndexByte(content, '\n') + 1
+		r.header = content[:headerLen]
+		n -= headerLen
+	}
+	r.events.Next(n)
+}
+
+// sessionRecording records a terminal session, and flushes it into the log storage by chunks
+// until the session ends
+type sessionRecording struct {
+	*recorder
+	storage      s3.Interface
+	recordingMgr terminalrecordingmanager.Manager
+	recording    *models.TerminalRecording
+	// required ends the session once the recording is full, instead of dropping the events beyond
+	required bool
+	chunks   int
+	size     int64
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+func newSessionRecording(storage s3.Interface, recordingMgr terminalrecordingmanager.Manager,
+	recording *models.TerminalRecording, required bool) *sessionRecording {
+	s := &sessionRecording{
+		recorder: newRecorder(recording.StartedAt,
+			fmt.Sprintf("%s/%s by %s", recording.Pod, recording.Container, recording.UserName)),
+		storage:      storage,
+		recordingMgr: recordingMgr,
+		recording:    recording,
+		required:     required,
+		stop:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// run flushes the recording periodically or once a chunk is pending, until the session ends
+func (s *sessionRecording) run() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(_recordingFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.pending:
+			s.flush(false)
+			if s.required && s.Full() {
+				terminalSessions.Close(s.recording.SessionID, 2,
+					"Session ended as the terminal recording reaches the size limit")
+			}
+		case <-ticker.C:
+			s.flush(false)
+		case <-s.stop:
+			s.flush(true)
+			s.end()
+			return
+		}
+	}
+}
+
+// flush puts the pending events into the log storage as the next chunk,
+// the events are kept to be flushed next time if it fails.
+// The final flush puts the header at least, so that a recording without events can be played as well.
+func (s *sessionRecording) flush(final bool) {
+	content := s.Pending(final)
+	if len(content) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), _recordingSaveTimeout)
+	defer cancel()
+	if err := s.storage.PutObject(ctx, recordingChunkPath(s.recording.ObjectPath, s.chunks),
+		bytes.NewReader(content), nil); err != nil {
+		log.Errorf(ctx, "failed to save chunk %d of terminal recording %d, err: %v", s.chunks, s.recording.ID, err)
+		return
+	}
+	s.Flushed(content)
+	s.chunks++
+	s.size += int64(len(content))
+}
+
+func (s *sessionRecording) end() {
+	ctx, cancel := context.WithTimeout(context.Background(), _recordingSaveTimeout)
+	defer cancel()
+
+	if err := s.recordingMgr.UpdateEnded(ctx, s.recording.ID, time.Now(), s.size, s.chunks); err != nil {
+		log.Errorf(ctx, "failed to update terminal recording %d, err: %v", s.recording.ID, err)
+	}
+}
+
+// save flushes the rest of the recording after the session ends, and marks the recording ended
+func (s *sessionRecording) save() {
+	close(s.stop)
+	<-s.stopped
+}
+
+// recordingChunkPath is the path of the index-th chunk of a recording, the recording is the chunks concatenated
+func recordingChunkPath(objectPath string, index int) string {
+	return fmt.Sprintf("%s.%d", objectPath, index)
+}
+
+// recordingPath indexes recordings by environment, cluster, pod, date and user in the log storage
+func recordingPath(environment string, ref ContainerRef, userID uint, startedAt time.Time) string {
+	return fmt.Sprintf("%s/%s/%d/%s/%s/%d-%s.cast", _recordingDir, environment, ref.ClusterID, ref.Pod,
+		startedAt.Format("20060102"), userID, ref.RandomID)
+}
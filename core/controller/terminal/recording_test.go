@@ -0,0 +1,155 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/s3"
+	recordingmanagermock "github.com/horizoncd/horizon/mock/pkg/terminalrecording/manager"
+	"github.com/horizoncd/horizon/pkg/terminalrecording/models"
+)
+
+func TestRecorder(t *testing.T) {
+	start := time.Now()
+	r := newRecorder(start, "pod/container by tony")
+	assert.Nil(t, r.Pending(false))
+	r.Resize(120, 40)
+	r.Input("ls\r")
+	r.Output([]byte("README.md\r\n"))
+	r.Resize(100, 30)
+
+	content := r.Pending(false)
+	lines := bytes.Split(bytes.TrimRight(content, "\n"), []byte("\n"))
+	assert.Equal(t, 4, len(lines))
+
+	var header castHeader
+	assert.Nil(t, json.Unmarshal(lines[0], &header))
+	assert.Equal(t, 2, header.Version)
+	assert.Equal(t, uint16(120), header.Width)
+	assert.Equal(t, uint16(40), header.Height)
+	assert.Equal(t, start.Unix(), header.Timestamp)
+	assert.Equal(t, "pod/container by tony", header.Title)
+
+	for i, expected := range [][2]string{
+		{_eventInput, "ls\r"},
+		{_eventOutput, "README.md\r\n"},
+		{_eventResize, "100x30"},
+	} {
+		var event []interface{}
+		assert.Nil(t, json.Unmarshal(lines[i+1], &event))
+		assert.Equal(t, 3, len(event))
+		assert.IsType(t, float64(0), event[0])
+		assert.Equal(t, expected[0], event[1])
+		assert.Equal(t, expected[1], event[2])
+	}
+
+	// the events recorded during a flush are kept, and the header is not flushed again
+	r.Input("pwd\r")
+	r.Flushed(content)
+	lines = bytes.Split(bytes.TrimRight(r.Pending(true), "\n"), []byte("\n"))
+	assert.Equal(t, 1, len(lines))
+	var event []interface{}
+	assert.Nil(t, json.Unmarshal(lines[0], &event))
+	assert.Equal(t, "pwd\r", event[2])
+}
+
+type fakeStorage struct {
+	s3.Interface
+	lock    sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeStorage) PutObject(_ context.Context, path string, content io.ReadSeeker, _ map[string]string) error {
+	data, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.objects[path] = data
+	return nil
+}
+
+func TestSessionRecording(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	recordingMgr := recordingmanagermock.NewMockManager(mockCtl)
+	storage := &fakeStorage{objects: map[string][]byte{}}
+
+	// a session never bound is recorded with the header only
+	recording := &models.TerminalRecording{ID: 1, SessionID: "1:pod:container:abc", Pod: "pod",
+		Container: "container", UserName: "tony", ObjectPath: "1.cast", StartedAt: time.Now()}
+	recordingMgr.EXPECT().UpdateEnded(gomock.Any(), uint(1), gomock.Any(), gomock.Any(), 1).
+		DoAndReturn(func(_ context.Context, _ uint, _ time.Time, size int64, _ int) error {
+			assert.Equal(t, int64(len(storage.objects["1.cast.0"])), size)
+			return nil
+		})
+	newSessionRecording(storage, recordingMgr, recording, true).save()
+	var header castHeader
+	assert.Nil(t, json.Unmarshal(storage.objects["1.cast.0"], &header))
+	assert.Equal(t, "pod/container by tony", header.Title)
+
+	// a session which must be recorded ends once the recording is full
+	recording = &models.TerminalRecording{ID: 2, SessionID: "1:pod:container:def", Pod: "pod",
+		Container: "container", UserName: "tony", ObjectPath: "2.cast", StartedAt: time.Now()}
+	terminalSessions.Set(recording.SessionID, Session{id: recording.SessionID})
+	var chunks int
+	recordingMgr.EXPECT().UpdateEnded(gomock.Any(), uint(2), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uint, _ time.Time, _ int64, n int) error {
+			chunks = n
+			return nil
+		})
+	sessionRecording := newSessionRecording(storage, recordingMgr, recording, true)
+	output := bytes.Repeat([]byte("a"), _recordingChunkSize/4)
+	for !sessionRecording.Full() {
+		sessionRecording.Output(output)
+	}
+	assert.Eventually(t, func() bool {
+		return terminalSessions.Get(recording.SessionID).id == ""
+	}, 10*time.Second, 10*time.Millisecond)
+	sessionRecording.save()
+
+	assert.Greater(t, chunks, 1)
+	var content []byte
+	for i := 0; i < chunks; i++ {
+		chunk, ok := storage.objects[recordingChunkPath(recording.ObjectPath, i)]
+		assert.True(t, ok)
+		content = append(content, chunk...)
+	}
+	lines := bytes.Split(bytes.TrimRight(content, "\n"), []byte("\n"))
+	assert.Nil(t, json.Unmarshal(lines[0], &header))
+	for _, line := range lines[1:] {
+		var event []interface{}
+		assert.Nil(t, json.Unmarshal(line, &event))
+		assert.Equal(t, string(output), event[2])
+	}
+	assert.LessOrEqual(t, len(content)-len(lines[0])-1, _maxRecordingSize)
+}
+
+func TestRecordingPath(t *testing.T) {
+	startedAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	ref := ContainerRef{ClusterID: 1, Pod: "pod", Container: "container", RandomID: "abc"}
+	assert.Equal(t, "terminalrecordings/test/1/pod/20230102/2-abc.cast", recordingPath("test", ref, 2, startedAt))
+}
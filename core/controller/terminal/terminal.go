@@ -27,6 +27,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	utillog "github.com/horizoncd/horizon/pkg/util/log"
 	"gopkg.in/igm/sockjs-go.v3/sockjs"
@@ -39,6 +40,9 @@ import (
 
 const EndOfTransmission = "\u0004"
 
+// _bindTimeout is how long a session waits for the client to bind, the session is discarded after it
+const _bindTimeout = time.Minute
+
 // PtyHandler is what remotecommand expects from a pty
 type PtyHandler interface {
 	io.Reader
@@ -53,6 +57,8 @@ type Session struct {
 	sockJSSession sockjs.Session
 	sizeChan      chan remotecommand.TerminalSize
 	doneChan      chan struct{}
+	// recording is nil if the session is not recorded
+	recording *sessionRecording
 }
 
 // Message is the messaging protocol between ShellController and TerminalSession.
@@ -96,8 +102,14 @@ func (t Session) Read(p []byte) (int, error) {
 
 	switch msg.Op {
 	case "stdin":
+		if t.recording != nil {
+			t.recording.Input(msg.Data)
+		}
 		return copy(p, msg.Data), nil
 	case "resize":
+		if t.recording != nil {
+			t.recording.Resize(msg.Cols, msg.Rows)
+		}
 		t.sizeChan <- remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows}
 		return 0, nil
 	default:
@@ -119,6 +131,9 @@ func (t Session) Write(p []byte) (int, error) {
 	if err = t.sockJSSession.Send(string(msg)); err != nil {
 		return 0, err
 	}
+	if t.recording != nil {
+		t.recording.Output(p)
+	}
 	return len(p), nil
 }
 
@@ -299,8 +314,19 @@ func startProcess(k8sClient kubernetes.Interface, cfg *rest.Config,
 // WaitForTerminal is called from apihandler.handleAttach as a goroutine
 // Waits for the SockJS connection to be opened by the client the session to be bound in handleTerminalSession
 func WaitForTerminal(k8sClient kubernetes.Interface, cfg *rest.Config, ref ContainerRef) {
-	<-terminalSessions.Get(ref.String()).bound
-	close(terminalSessions.Get(ref.String()).bound)
+	session := terminalSessions.Get(ref.String())
+	// save the recording after the process exits, or the session is discarded
+	if session.recording != nil {
+		defer session.recording.save()
+	}
+
+	select {
+	case <-session.bound:
+	case <-time.After(_bindTimeout):
+		terminalSessions.Close(ref.String(), 2, "Session is not bound in time")
+		return
+	}
+	close(session.bound)
 
 	var err error
 	validShells := []string{"bash", "sh"}
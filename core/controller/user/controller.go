@@ -87,6 +87,7 @@ func (c *controller) UpdateByID(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	common.SetAuditBefore(ctx, ofUser(userInDB))
 
 	if u.IsAdmin == nil && u.IsBanned == nil {
 		return ofUser(userInDB), nil
@@ -0,0 +1,84 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usergroup
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/param"
+	usergroupmanager "github.com/horizoncd/horizon/pkg/usergroup/manager"
+	"github.com/horizoncd/horizon/pkg/usergroup/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type UserGroup struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	IdpID     uint      `json:"idpID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Controller interface {
+	// List lists user groups synced from identity providers, whose name contains filter
+	List(ctx context.Context, filter string) ([]*UserGroup, error)
+	// ListByUser lists user groups which the user belongs to
+	ListByUser(ctx context.Context, userID uint) ([]*UserGroup, error)
+}
+
+type controller struct {
+	userGroupMgr usergroupmanager.Manager
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		userGroupMgr: param.UserGroupMgr,
+	}
+}
+
+func (c *controller) List(ctx context.Context, filter string) ([]*UserGroup, error) {
+	const op = "user group controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	userGroups, err := c.userGroupMgr.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return ofUserGroups(userGroups), nil
+}
+
+func (c *controller) ListByUser(ctx context.Context, userID uint) ([]*UserGroup, error) {
+	const op = "user group controller: list by user"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	userGroups, err := c.userGroupMgr.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return ofUserGroups(userGroups), nil
+}
+
+func ofUserGroups(userGroups []*models.UserGroup) []*UserGroup {
+	res := make([]*UserGroup, 0, len(userGroups))
+	for _, userGroup := range userGroups {
+		res = append(res, &UserGroup{
+			ID:        userGroup.ID,
+			Name:      userGroup.Name,
+			IdpID:     userGroup.IdpID,
+			CreatedAt: userGroup.CreatedAt,
+		})
+	}
+	return res
+}
@@ -16,6 +16,7 @@ package webhook
 
 import (
 	"context"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/lib/q"
@@ -43,8 +44,12 @@ type Controller interface {
 	ListWebhookLogs(ctx context.Context, wID uint, query *q.Query) ([]*LogSummary, int64, error)
 	GetWebhookLog(ctx context.Context, id uint) (*Log, error)
 	ResendWebhook(ctx context.Context, id uint) (*models.WebhookLog, error)
+	// GetWebhookStats returns delivery statistics of logs finished in the last day
+	GetWebhookStats(ctx context.Context, id uint) (*Stats, error)
 }
 
+const _statsWindow = 24 * time.Hour
+
 type controller struct {
 	webhookMgr     wmanager.Manager
 	userMgr        usermanager.Manager
@@ -116,6 +121,7 @@ func (c *controller) UpdateWebhook(ctx context.Context, id uint,
 	if err != nil {
 		return nil, err
 	}
+	common.SetAuditBefore(ctx, ofWebhookModel(wm))
 	wm = w.toModel(wm)
 
 	// 3. update webhook
@@ -237,6 +243,17 @@ func (c *controller) GetWebhookLog(ctx context.Context, id uint) (*Log, error) {
 	return webhookLog, nil
 }
 
+func (c *controller) GetWebhookStats(ctx context.Context, id uint) (*Stats, error) {
+	const op = "wehook controller: get stats"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	stats, err := c.webhookMgr.GetWebhookStats(ctx, id, time.Now().Add(-_statsWindow))
+	if err != nil {
+		return nil, err
+	}
+	return ofWebhookStatsModel(stats), nil
+}
+
 func (c *controller) ResendWebhook(ctx context.Context, id uint) (*models.WebhookLog, error) {
 	const op = "wehook controller: resend"
 	defer wlog.Start(ctx, op).StopPrint()
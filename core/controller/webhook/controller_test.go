@@ -22,11 +22,13 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/lib/q"
 	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/param"
@@ -139,6 +141,49 @@ func Test(t *testing.T) {
 	_, _, err = c.ListWebhookLogs(ctx, w.ID, query)
 	assert.Nil(t, err)
 
+	// max attempts is limited
+	invalidReq := createWebhookReq
+	invalidReq.MaxAttempts = _maxAttemptsLimit + 1
+	_, err = c.CreateWebhook(ctx, resourceType, resourceID, &invalidReq)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// format should be supported
+	invalidReq = createWebhookReq
+	invalidReq.Format = "xml"
+	_, err = c.CreateWebhook(ctx, resourceType, resourceID, &invalidReq)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// enabling an auto-disabled webhook resets consecutive failures
+	err = c.webhookMgr.UpdateWebhookDeliveryState(ctx, w.ID, 50, false)
+	assert.Nil(t, err)
+	w, err = c.GetWebhook(ctx, w.ID)
+	assert.Nil(t, err)
+	assert.False(t, w.Enabled)
+	assert.Equal(t, uint(50), w.ConsecutiveFailures)
+	w, err = c.UpdateWebhook(ctx, w.ID, &UpdateWebhookRequest{
+		Enabled:     utilcommon.BoolPtr(true),
+		MaxAttempts: &[]uint{3}[0],
+		Format:      &[]string{webhookmodels.FormatCloudEventsBinary}[0],
+	})
+	assert.Nil(t, err)
+	w, err = c.GetWebhook(ctx, w.ID)
+	assert.Nil(t, err)
+	assert.True(t, w.Enabled)
+	assert.Equal(t, uint(0), w.ConsecutiveFailures)
+	assert.Equal(t, uint(3), w.MaxAttempts)
+	assert.Equal(t, webhookmodels.FormatCloudEventsBinary, w.Format)
+
+	wl.Status = webhookmodels.StatusDead
+	wl.LatencyMs = 100
+	_, err = c.webhookMgr.UpdateWebhookLog(ctx, wl)
+	assert.Nil(t, err)
+	stats, err := c.GetWebhookStats(ctx, w.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), stats.Total)
+	assert.Equal(t, uint(1), stats.Dead)
+	assert.Equal(t, float64(0), stats.SuccessRate)
+	assert.Equal(t, uint(100), stats.P95LatencyMs)
+
 	err = c.DeleteWebhook(ctx, w.ID)
 	assert.Nil(t, err)
 
@@ -31,6 +31,7 @@ import (
 
 const (
 	_triggerSeparator = ","
+	_maxAttemptsLimit = 20
 )
 
 type UpdateWebhookRequest struct {
@@ -40,6 +41,8 @@ type UpdateWebhookRequest struct {
 	Description      *string  `json:"description"`
 	Secret           *string  `json:"secret"`
 	Triggers         []string `json:"triggers"`
+	Format           *string  `json:"format"`
+	MaxAttempts      *uint    `json:"maxAttempts"`
 }
 
 type CreateWebhookRequest struct {
@@ -49,15 +52,22 @@ type CreateWebhookRequest struct {
 	Description      string   `json:"description"`
 	Secret           string   `json:"secret"`
 	Triggers         []string `json:"triggers"`
+	// Format is the format of request body: json, cloudevents-structured or cloudevents-binary
+	Format string `json:"format"`
+	// MaxAttempts is the max attempts to send a log, 0 means using the default
+	MaxAttempts uint `json:"maxAttempts"`
 }
 
 type Webhook struct {
 	CreateWebhookRequest
-	ID        uint                  `json:"id"`
-	CreatedAt time.Time             `json:"createdAt"`
-	CreatedBy *usermodels.UserBasic `json:"createdBy,omitempty"`
-	UpdatedAt time.Time             `json:"updatedAt"`
-	UpdatedBy *usermodels.UserBasic `json:"updatedBy,omitempty"`
+	ID uint `json:"id"`
+	// ConsecutiveFailures is the number of failed attempts since last success,
+	// webhook is disabled automatically if it reaches the threshold
+	ConsecutiveFailures uint                  `json:"consecutiveFailures"`
+	CreatedAt           time.Time             `json:"createdAt"`
+	CreatedBy           *usermodels.UserBasic `json:"createdBy,omitempty"`
+	UpdatedAt           time.Time             `json:"updatedAt"`
+	UpdatedBy           *usermodels.UserBasic `json:"updatedBy,omitempty"`
 }
 
 type LogSummary struct {
@@ -72,12 +82,27 @@ type LogSummary struct {
 	EventType    string                `json:"eventType"`
 	Extra        *string               `json:"extra"`
 	ErrorMessage string                `json:"errorMessage"`
+	Attempts     uint                  `json:"attempts"`
+	NextRetryAt  *time.Time            `json:"nextRetryAt,omitempty"`
+	LatencyMs    uint                  `json:"latencyMs"`
 	CreatedAt    time.Time             `json:"createdAt"`
 	CreatedBy    *usermodels.UserBasic `json:"createdBy,omitempty"`
 	UpdatedAt    time.Time             `json:"updatedAt"`
 	UpdatedBy    *usermodels.UserBasic `json:"updatedBy,omitempty"`
 }
 
+type Stats struct {
+	// Since is the start time of statistics
+	Since time.Time `json:"since"`
+	// Total is the number of logs finished, succeeded or dead
+	Total               uint    `json:"total"`
+	Succeeded           uint    `json:"succeeded"`
+	Dead                uint    `json:"dead"`
+	SuccessRate         float64 `json:"successRate"`
+	P95LatencyMs        uint    `json:"p95LatencyMs"`
+	ConsecutiveFailures uint    `json:"consecutiveFailures"`
+}
+
 type Log struct {
 	LogSummary
 	RequestHeaders  string `json:"requestHeaders"`
@@ -88,6 +113,10 @@ type Log struct {
 
 func (w *UpdateWebhookRequest) toModel(wm *wmodels.Webhook) *wmodels.Webhook {
 	if w.Enabled != nil {
+		// give the webhook a fresh start if it is enabled again
+		if *w.Enabled && !wm.Enabled {
+			wm.ConsecutiveFailures = 0
+		}
 		wm.Enabled = *w.Enabled
 	}
 	if w.URL != nil {
@@ -105,6 +134,12 @@ func (w *UpdateWebhookRequest) toModel(wm *wmodels.Webhook) *wmodels.Webhook {
 	if len(w.Triggers) > 0 {
 		wm.Triggers = JoinTriggers(w.Triggers)
 	}
+	if w.Format != nil {
+		wm.Format = *w.Format
+	}
+	if w.MaxAttempts != nil {
+		wm.MaxAttempts = *w.MaxAttempts
+	}
 	return wm
 }
 
@@ -114,6 +149,16 @@ func (c *controller) validateUpdateRequest(w *UpdateWebhookRequest) error {
 			return err
 		}
 	}
+	if w.Format != nil {
+		if err := validateFormat(*w.Format); err != nil {
+			return err
+		}
+	}
+	if w.MaxAttempts != nil {
+		if err := validateMaxAttempts(*w.MaxAttempts); err != nil {
+			return err
+		}
+	}
 	if len(w.Triggers) > 0 {
 		return c.validateEvents(w.Triggers)
 	}
@@ -131,6 +176,8 @@ func (w *CreateWebhookRequest) toModel(ctx context.Context,
 		Description:      w.Description,
 		Secret:           w.Secret,
 		Triggers:         JoinTriggers(w.Triggers),
+		Format:           w.Format,
+		MaxAttempts:      w.MaxAttempts,
 	}
 	return wm, nil
 }
@@ -146,10 +193,33 @@ func (c *controller) validateCreateRequest(resourceType string, w *CreateWebhook
 	if (!strings.HasPrefix(w.URL, "https")) && w.SSLVerifyEnabled {
 		return perror.Wrapf(herrors.ErrParamInvalid, "sslVerifyEnabled is only valid for https")
 	}
+	if err := validateFormat(w.Format); err != nil {
+		return err
+	}
+	if err := validateMaxAttempts(w.MaxAttempts); err != nil {
+		return err
+	}
 
 	return c.validateEvents(w.Triggers)
 }
 
+func validateFormat(format string) error {
+	switch format {
+	case "", wmodels.FormatJSON, wmodels.FormatCloudEventsStructured, wmodels.FormatCloudEventsBinary:
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid format %s", format)
+	}
+	return nil
+}
+
+func validateMaxAttempts(maxAttempts uint) error {
+	if maxAttempts > _maxAttemptsLimit {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"maxAttempts should not be greater than %d", _maxAttemptsLimit)
+	}
+	return nil
+}
+
 func (c *controller) validateResourceType(resource string) error {
 	switch resource {
 	case common.ResourceGroup, common.ResourceApplication, common.ResourceCluster:
@@ -204,10 +274,13 @@ func ofWebhookModel(wm *wmodels.Webhook) *Webhook {
 			Description:      wm.Description,
 			Secret:           wm.Secret,
 			Triggers:         ParseTriggerStr(wm.Triggers),
+			Format:           wm.Format,
+			MaxAttempts:      wm.MaxAttempts,
 		},
-		ID:        wm.ID,
-		CreatedAt: wm.CreatedAt,
-		UpdatedAt: wm.UpdatedAt,
+		ID:                  wm.ID,
+		ConsecutiveFailures: wm.ConsecutiveFailures,
+		CreatedAt:           wm.CreatedAt,
+		UpdatedAt:           wm.UpdatedAt,
 	}
 
 	return w
@@ -225,12 +298,27 @@ func ofWebhookLogSummaryModel(wm *wmodels.WebhookLogWithEventInfo) *LogSummary {
 		EventType:    wm.EventType,
 		Status:       wm.Status,
 		ErrorMessage: wm.ErrorMessage,
+		Attempts:     wm.Attempts,
+		NextRetryAt:  wm.NextRetryAt,
+		LatencyMs:    wm.LatencyMs,
 		CreatedAt:    wm.CreatedAt,
 		UpdatedAt:    wm.UpdatedAt,
 	}
 	return wl
 }
 
+func ofWebhookStatsModel(stats *wmodels.WebhookStats) *Stats {
+	return &Stats{
+		Since:               stats.Since,
+		Total:               stats.Total,
+		Succeeded:           stats.Succeeded,
+		Dead:                stats.Dead,
+		SuccessRate:         stats.SuccessRate,
+		P95LatencyMs:        stats.P95LatencyMs,
+		ConsecutiveFailures: stats.ConsecutiveFailures,
+	}
+}
+
 func ofWebhookLogModel(wm *wmodels.WebhookLog) *Log {
 	wl := &Log{
 		LogSummary: LogSummary{
@@ -240,6 +328,9 @@ func ofWebhookLogModel(wm *wmodels.WebhookLog) *Log {
 			URL:          wm.URL,
 			Status:       wm.Status,
 			ErrorMessage: wm.ErrorMessage,
+			Attempts:     wm.Attempts,
+			NextRetryAt:  wm.NextRetryAt,
+			LatencyMs:    wm.LatencyMs,
 			CreatedAt:    wm.CreatedAt,
 			UpdatedAt:    wm.UpdatedAt,
 		},
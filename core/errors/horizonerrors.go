@@ -29,6 +29,7 @@ var (
 	GitlabClient              = sourceType{name: "GitlabClient"}
 	GitlabResource            = sourceType{name: "GitlabResource"}
 	GithubResource            = sourceType{name: "GithubResource"}
+	GitResource               = sourceType{name: "GitResource"}
 	ClusterInDB               = sourceType{name: "ClusterInDB"}
 	CollectionInDB            = sourceType{name: "CollectionInDB"}
 	ClusterStateInArgo        = sourceType{name: "ClusterStateInArgo"}
@@ -71,10 +72,20 @@ var (
 	WebhookInDB               = sourceType{name: "WebhookInDB"}
 	WebhookLogInDB            = sourceType{name: "WebhookLogInDB"}
 	MetatagInDB               = sourceType{name: "MetatagInDB"}
+	CanaryRuleInDB            = sourceType{name: "CanaryRuleInDB"}
+	ScheduledDeployInDB       = sourceType{name: "ScheduledDeployInDB"}
+	ApprovalInDB              = sourceType{name: "ApprovalInDB"}
+	ReleasePipelineInDB       = sourceType{name: "ReleasePipelineInDB"}
+	ClusterDriftInDB          = sourceType{name: "ClusterDriftInDB"}
+	UserGroupInDB             = sourceType{name: "UserGroupInDB"}
+	AuditLogInDB              = sourceType{name: "AuditLogInDB"}
+	TerminalRecordingInDB     = sourceType{name: "TerminalRecordingInDB"}
+	AlertRuleInDB             = sourceType{name: "AlertRuleInDB"}
 
 	// S3
 	PipelinerunLog = sourceType{name: "PipelinerunLog"}
 	PipelinerunObj = sourceType{name: "PipelinerunObj"}
+	LogStorage     = sourceType{name: "LogStorage"}
 
 	ArgoCD = sourceType{name: "ArgoCD"}
 
@@ -93,6 +104,8 @@ var (
 
 	StepInWorkload = sourceType{name: "StepInWorkload"}
 
+	Prometheus = sourceType{name: "Prometheus"}
+
 	EnvValueInGit = sourceType{name: "EnvValueInGit"}
 )
 
@@ -218,6 +231,10 @@ var (
 	// cluster
 	ErrClusterNoChange        = errors.New("no change to cluster")
 	ErrShouldBuildDeployFirst = errors.New("clusters with build config should build and deploy first")
+	ErrDeployWindowClosed     = errors.New("not in deploy windows of the environment")
+	ErrClusterConfigChanged   = errors.New("config of cluster has changed since the request")
+	ErrHealthGateNotPassed    = errors.New("health gate of the stage is not passed")
+	ErrShellDisabled          = errors.New("shell access is disabled in the environment")
 
 	// pipelinerun
 
@@ -231,9 +248,10 @@ var (
 	ErrKubeExecFailed              = errors.New("kube exec failed")
 
 	// S3
-	ErrS3SignFailed   = errors.New("s3 sign failed")
-	ErrS3PutObjFailed = errors.New("s3 put obj failed")
-	ErrS3GetObjFailed = errors.New("s3 get obj failed")
+	ErrS3SignFailed      = errors.New("s3 sign failed")
+	ErrS3PutObjFailed    = errors.New("s3 put obj failed")
+	ErrS3GetObjFailed    = errors.New("s3 get obj failed")
+	ErrS3DeleteObjFailed = errors.New("s3 delete obj failed")
 
 	ErrGitlabInternal              = errors.New("gitlab internal")
 	ErrGitlabMRNotReady            = errors.New("gitlab mr is not ready and cannot be merged")
@@ -241,6 +259,8 @@ var (
 	ErrGitLabDefaultBranchNotMatch = errors.New("gitlab default branch do not match")
 
 	// git
+	ErrGitInternal               = errors.New("git internal")
+	ErrGitMergeConflict          = errors.New("git merge conflict")
 	ErrBranchAndCommitEmpty      = errors.New("branch and commit cannot be empty at the same time")
 	ErrGitlabInterfaceCallFailed = errors.New("failed to call gitlab interface")
 
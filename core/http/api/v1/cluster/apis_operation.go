@@ -54,6 +54,10 @@ func (a *API) BuildDeploy(c *gin.Context) {
 
 	resp, err := a.clusterCtl.BuildDeploy(c, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			if e.Source == herrors.ClusterInDB {
 				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
@@ -213,6 +217,10 @@ func (a *API) Restart(c *gin.Context) {
 
 	resp, err := a.clusterCtl.Restart(c, uint(clusterID))
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
@@ -241,6 +249,10 @@ func (a *API) Deploy(c *gin.Context) {
 
 	resp, err := a.clusterCtl.Deploy(c, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		switch e := perror.Cause(err).(type) {
 		case *herrors.HorizonErrNotFound:
 			if e.Source == herrors.ClusterInDB {
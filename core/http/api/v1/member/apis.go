@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/member"
@@ -281,7 +282,14 @@ func (a *API) UpdateMember(c *gin.Context) {
 		return
 	}
 
-	retMember, err := a.memberCtrl.UpdateMember(c, updateMember.ID, updateMember.Role)
+	if err := validExpiredAt(updateMember.ExpiredAt, updateMember.ClearExpiredAt); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam,
+			err.Error())
+		return
+	}
+
+	retMember, err := a.memberCtrl.UpdateMember(c, updateMember.ID, updateMember.Role, updateMember.ExpiredAt,
+		updateMember.ClearExpiredAt)
 	if err != nil {
 		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, updateMember)
@@ -433,17 +441,28 @@ func (a *API) validatePostMember(ctx context.Context,
 	if err := validMemberType(postMember.MemberType); err != nil {
 		return err
 	}
+	if err := validExpiredAt(postMember.ExpiredAt, postMember.ClearExpiredAt); err != nil {
+		return err
+	}
 
 	return a.validRole(ctx, postMember.Role)
 }
 
 func validMemberType(memberType membermodels.MemberType) error {
 	switch memberType {
-	case membermodels.MemberUser:
-	case membermodels.MemberGroup:
-		return fmt.Errorf("this type of member is not supported yet")
+	case membermodels.MemberUser, membermodels.MemberGroup:
 	default:
 		return fmt.Errorf("invalid memberType")
 	}
 	return nil
 }
+
+func validExpiredAt(expiredAt *time.Time, clearExpiredAt bool) error {
+	if expiredAt != nil && clearExpiredAt {
+		return fmt.Errorf("expiredAt and clearExpiredAt cannot be both set")
+	}
+	if expiredAt != nil && !expiredAt.After(time.Now()) {
+		return fmt.Errorf("expiredAt must be later than now")
+	}
+	return nil
+}
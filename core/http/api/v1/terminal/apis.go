@@ -106,6 +106,10 @@ func (a *API) ConnectTerminal(c *gin.Context) {
 	sessionID := c.Param(_terminalIDParam)
 	sockJS, err := a.terminalCtl.GetSockJSHandler(c, sessionID)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrShellDisabled {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			if e.Source == herrors.ClusterInDB || e.Source == herrors.PodsInK8S {
 				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
@@ -0,0 +1,121 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/audit"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type API struct {
+	auditCtl audit.Controller
+}
+
+func NewAPI(auditCtl audit.Controller) *API {
+	return &API{
+		auditCtl: auditCtl,
+	}
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "audit: list"
+	keywords, err := parseKeywords(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	query := q.New(keywords).WithPagination(c)
+	items, total, err := a.auditCtl.List(c, query)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: items,
+		Total: total,
+	})
+}
+
+func (a *API) Export(c *gin.Context) {
+	const op = "audit: export"
+	keywords, err := parseKeywords(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", "attachment; filename=auditlogs.jsonl")
+	if err := a.auditCtl.Export(c, q.New(keywords), c.Writer); err != nil {
+		if c.Writer.Written() {
+			// the response has been partly sent, nothing more can be done
+			log.WithFiled(c, "op", op).Errorf("%+v", err)
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func parseKeywords(c *gin.Context) (q.KeyWords, error) {
+	keywords := q.KeyWords{}
+	if userIDStr := c.Query(common.AuditLogQueryUserID); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 0)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid userID: %s", userIDStr)
+		}
+		keywords[common.AuditLogQueryUserID] = uint(userID)
+	}
+	if resource := c.Query(common.AuditLogQueryResource); resource != "" {
+		keywords[common.AuditLogQueryResource] = resource
+	}
+	if resourceName := c.Query(common.AuditLogQueryResourceName); resourceName != "" {
+		keywords[common.AuditLogQueryResourceName] = resourceName
+	}
+	for _, key := range []string{common.AuditLogQueryStartTime, common.AuditLogQueryEndTime} {
+		if timeStr := c.Query(key); timeStr != "" {
+			t, err := time.Parse(time.RFC3339, timeStr)
+			if err != nil {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid %s: %s", key, timeStr)
+			}
+			keywords[key] = t
+		}
+	}
+	return keywords, nil
+}
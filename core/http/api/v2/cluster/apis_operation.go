@@ -17,7 +17,10 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"io"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/horizoncd/horizon/core/common"
@@ -51,6 +54,10 @@ func (a *API) BuildDeploy(c *gin.Context) {
 
 	resp, err := a.clusterCtl.BuildDeploy(c, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			if e.Source == herrors.ClusterInDB {
 				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
@@ -107,6 +114,92 @@ func (a *API) GetDiff(c *gin.Context) {
 	response.SuccessWithData(c, resp)
 }
 
+func (a *API) GetManifestDiff(c *gin.Context) {
+	const op = "cluster: get manifest diff"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	resp, err := a.clusterCtl.GetManifestDiff(c, uint(clusterID))
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			if e.Source == herrors.ClusterInDB {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) ListImageTags(c *gin.Context) {
+	const op = "cluster: list image tags"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	resp, err := a.clusterCtl.ListImageTags(c, uint(clusterID))
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) DeleteImageTags(c *gin.Context) {
+	const op = "cluster: delete image tags"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	olderThan, err := time.ParseDuration(c.Query(common.ClusterQueryOlderThan))
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(
+			fmt.Sprintf("invalid %s, err: %v", common.ClusterQueryOlderThan, err)))
+		return
+	}
+
+	resp, err := a.clusterCtl.DeleteImageTags(c, uint(clusterID), olderThan)
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
 func (a *API) GetResourceTree(c *gin.Context) {
 	op := "cluster: get resource tree"
 	clusterIDStr := c.Param(common.ParamClusterID)
@@ -330,6 +423,10 @@ func (a *API) Restart(c *gin.Context) {
 
 	resp, err := a.clusterCtl.Restart(c, uint(clusterID))
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
@@ -381,6 +478,57 @@ func (a *API) ExecuteAction(c *gin.Context) {
 	response.Success(c)
 }
 
+func (a *API) TriggerCronJob(c *gin.Context) {
+	const op = "cluster: trigger cronjob"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("invalid cluster id"))
+		return
+	}
+
+	run, err := a.clusterCtl.TriggerCronJob(c, uint(clusterID), c.Query(common.ClusterQueryCronJob))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, run)
+}
+
+func (a *API) ListCronJobRuns(c *gin.Context) {
+	const op = "cluster: list cronjob runs"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("invalid cluster id"))
+		return
+	}
+
+	runs, err := a.clusterCtl.ListCronJobRuns(c, uint(clusterID), c.Query(common.ClusterQueryCronJob))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: runs,
+		Total: int64(len(runs)),
+	})
+}
+
 func (a *API) Deploy(c *gin.Context) {
 	op := "cluster: deploy"
 	clusterIDStr := c.Param(common.ParamClusterID)
@@ -398,6 +546,10 @@ func (a *API) Deploy(c *gin.Context) {
 
 	resp, err := a.clusterCtl.Deploy(c, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		switch e := perror.Cause(err).(type) {
 		case *herrors.HorizonErrNotFound:
 			if e.Source == herrors.ClusterInDB {
@@ -460,6 +612,111 @@ func (a *API) GetContainerLog(c *gin.Context) {
 	}
 }
 
+// StreamContainerLogs streams logs as server-sent events, every line is sent as a log event,
+// and an end event is sent when all the streams end, so that clients would not reconnect
+func (a *API) StreamContainerLogs(c *gin.Context) {
+	const op = "cluster: stream container logs"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	request, err := parseStreamContainerLogsRequest(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+	logC, err := a.clusterCtl.StreamContainerLogs(ctx, uint(clusterID), request)
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			if e.Source == herrors.ClusterInDB || e.Source == herrors.PodsInK8S {
+				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+				return
+			}
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case line, ok := <-logC:
+			if !ok {
+				c.SSEvent("end", "")
+				return false
+			}
+			c.SSEvent("log", strings.TrimSuffix(line, "\n"))
+			return true
+		}
+	})
+}
+
+func parseStreamContainerLogsRequest(c *gin.Context) (*cluster.StreamContainerLogsRequest, error) {
+	request := &cluster.StreamContainerLogsRequest{
+		Pods:      c.QueryArray(common.ClusterQueryPodName),
+		Container: c.Query(common.ClusterQueryContainerName),
+		Follow:    true,
+	}
+	parseInt := func(key string) (*int64, error) {
+		str := c.Query(key)
+		if str == "" {
+			return nil, nil
+		}
+		value, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", key, str)
+		}
+		return &value, nil
+	}
+	parseBool := func(key string, value *bool) error {
+		str := c.Query(key)
+		if str == "" {
+			return nil
+		}
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %s", key, str)
+		}
+		*value = b
+		return nil
+	}
+
+	var err error
+	if request.TailLines, err = parseInt(common.ClusterQueryTailLines); err != nil {
+		return nil, err
+	}
+	if request.SinceSeconds, err = parseInt(common.ClusterQuerySinceSeconds); err != nil {
+		return nil, err
+	}
+	if sinceTimeStr := c.Query(common.ClusterQuerySinceTime); sinceTimeStr != "" {
+		sinceTime, err := time.Parse(time.RFC3339, sinceTimeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", common.ClusterQuerySinceTime, sinceTimeStr)
+		}
+		request.SinceTime = &sinceTime
+	}
+	if err := parseBool(common.ClusterQueryPrevious, &request.Previous); err != nil {
+		return nil, err
+	}
+	if err := parseBool(common.ClusterQueryFollow, &request.Follow); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
 func (a *API) Exec(c *gin.Context) {
 	op := "cluster: exec"
 	clusterIDStr := c.Param(common.ParamClusterID)
@@ -520,6 +777,71 @@ func (a *API) Rollback(c *gin.Context) {
 	response.SuccessWithData(c, resp)
 }
 
+func (a *API) ApprovePipelinerun(c *gin.Context) {
+	op := "cluster: approve pipelinerun"
+	request, pipelinerunID, ok := parseApprovalRequest(c)
+	if !ok {
+		return
+	}
+
+	resp, err := a.clusterCtl.ApprovePipelinerun(c, pipelinerunID, request)
+	if err != nil {
+		abortWithApprovalError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) RejectPipelinerun(c *gin.Context) {
+	op := "cluster: reject pipelinerun"
+	request, pipelinerunID, ok := parseApprovalRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := a.clusterCtl.RejectPipelinerun(c, pipelinerunID, request); err != nil {
+		abortWithApprovalError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parseApprovalRequest(c *gin.Context) (*cluster.ApprovalRequest, uint, bool) {
+	pipelinerunIDStr := c.Param(common.ParamPipelinerunID)
+	pipelinerunID, err := strconv.ParseUint(pipelinerunIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return nil, 0, false
+	}
+	request := &cluster.ApprovalRequest{}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(request); err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestBody,
+				fmt.Sprintf("request body is invalid, err: %v", err))
+			return nil, 0, false
+		}
+	}
+	return request, uint(pipelinerunID), true
+}
+
+func abortWithApprovalError(c *gin.Context, op string, err error) {
+	if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok &&
+		(e.Source == herrors.PipelinerunInDB || e.Source == herrors.ClusterInDB) {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrForbidden, herrors.ErrDeployWindowClosed:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrParamInvalid, herrors.ErrClusterNoChange, herrors.ErrClusterConfigChanged:
+		response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
+
 func (a *API) GetGrafanaDashBoard(c *gin.Context) {
 	op := "cluster: get dashboard"
 	clusterIDStr := c.Param(common.ParamClusterID)
@@ -567,3 +889,87 @@ func (a *API) DeleteClusterPods(c *gin.Context) {
 	}
 	response.SuccessWithData(c, resp)
 }
+
+func (a *API) UpgradeTemplate(c *gin.Context) {
+	op := "cluster: upgrade template"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	var request *cluster.UpgradeTemplateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+	dryRun, ok := parseDryRun(c)
+	if !ok {
+		return
+	}
+
+	resp, err := a.clusterCtl.UpgradeTemplate(c, uint(clusterID), request, dryRun)
+	if err != nil {
+		abortWithUpgradeError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) BulkUpgradeTemplate(c *gin.Context) {
+	op := "cluster: bulk upgrade template"
+	releaseIDStr := c.Param(common.ParamReleaseID)
+	releaseID, err := strconv.ParseUint(releaseIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	var request *cluster.BulkUpgradeTemplateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+	dryRun, ok := parseDryRun(c)
+	if !ok {
+		return
+	}
+
+	resp, err := a.clusterCtl.BulkUpgradeTemplate(c, uint(releaseID), request, dryRun)
+	if err != nil {
+		abortWithUpgradeError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func parseDryRun(c *gin.Context) (bool, bool) {
+	dryRunStr, ok := c.GetQuery(common.ClusterQueryDryRun)
+	if !ok {
+		return false, true
+	}
+	dryRun, err := strconv.ParseBool(dryRunStr)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return false, false
+	}
+	return dryRun, true
+}
+
+func abortWithUpgradeError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	if perror.Cause(err) == herrors.ErrForbidden {
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -58,6 +58,18 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/diffs", common.ParamClusterID),
 			HandlerFunc: api.GetDiff,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/manifestdiffs", common.ParamClusterID),
+			HandlerFunc: api.GetManifestDiff,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/imagetags", common.ParamClusterID),
+			HandlerFunc: api.ListImageTags,
+		}, {
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/clusters/:%v/imagetags", common.ParamClusterID),
+			HandlerFunc: api.DeleteImageTags,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/step", common.ParamClusterID),
@@ -86,14 +98,34 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/rollback", common.ParamClusterID),
 			HandlerFunc: api.Rollback,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/approve", common.ParamPipelinerunID),
+			HandlerFunc: api.ApprovePipelinerun,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/reject", common.ParamPipelinerunID),
+			HandlerFunc: api.RejectPipelinerun,
 		}, {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/action", common.ParamClusterID),
 			HandlerFunc: api.ExecuteAction,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/cronjobruns", common.ParamClusterID),
+			HandlerFunc: api.TriggerCronJob,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/cronjobruns", common.ParamClusterID),
+			HandlerFunc: api.ListCronJobRuns,
 		}, {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/clusters/:%v/containerlog", common.ParamClusterID),
 			HandlerFunc: api.GetContainerLog,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/containerlog/stream", common.ParamClusterID),
+			HandlerFunc: api.StreamContainerLogs,
 		}, {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/exec", common.ParamClusterID),
@@ -134,6 +166,14 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodDelete,
 			Pattern:     fmt.Sprintf("/clusters/:%v/favorite", common.ParamClusterID),
 			HandlerFunc: api.DeleteFavorite,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/upgrade", common.ParamClusterID),
+			HandlerFunc: api.UpgradeTemplate,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/templatereleases/:%v/upgrade", common.ParamReleaseID),
+			HandlerFunc: api.BulkUpgradeTemplate,
 		},
 	}
 
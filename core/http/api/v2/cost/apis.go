@@ -0,0 +1,103 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/cost"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type API struct {
+	costCtl cost.Controller
+}
+
+func NewAPI(costCtl cost.Controller) *API {
+	return &API{
+		costCtl: costCtl,
+	}
+}
+
+func (a *API) GetClusterReport(c *gin.Context) {
+	const op = "cost: get cluster report"
+	a.getReport(c, op, common.ParamClusterID, a.costCtl.GetClusterReport)
+}
+
+func (a *API) GetApplicationReport(c *gin.Context) {
+	const op = "cost: get application report"
+	a.getReport(c, op, common.ParamApplicationID, a.costCtl.GetApplicationReport)
+}
+
+func (a *API) GetGroupReport(c *gin.Context) {
+	const op = "cost: get group report"
+	a.getReport(c, op, common.ParamGroupID, a.costCtl.GetGroupReport)
+}
+
+func (a *API) getReport(c *gin.Context, op, idParam string,
+	getReport func(ctx context.Context, id uint, r *cost.TimeRange) (*cost.Report, error)) {
+	idStr := c.Param(idParam)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid %s: %s", idParam, idStr))
+		return
+	}
+	timeRange, err := parseTimeRange(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	report, err := getReport(c, uint(id), timeRange)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, report)
+}
+
+func parseTimeRange(c *gin.Context) (*cost.TimeRange, error) {
+	timeRange := &cost.TimeRange{}
+	for key, t := range map[string]*time.Time{
+		common.CostQueryStartTime: &timeRange.StartTime,
+		common.CostQueryEndTime:   &timeRange.EndTime,
+	} {
+		if timeStr := c.Query(key); timeStr != "" {
+			parsed, err := time.Parse(time.RFC3339, timeStr)
+			if err != nil {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid %s: %s", key, timeStr)
+			}
+			*t = parsed
+		}
+	}
+	return timeRange, nil
+}
@@ -20,11 +20,15 @@ import (
 
 	"github.com/horizoncd/horizon/core/common"
 	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/request"
 	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
 	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
 
 	"github.com/gin-gonic/gin"
 )
@@ -33,6 +37,8 @@ const (
 	_pipelinerunIDParam = "pipelinerunID"
 	_clusterIDParam     = "clusterID"
 	_canRollbackParam   = "canRollback"
+	_patternParam       = "pattern"
+	_regexParam         = "regex"
 )
 
 type API struct {
@@ -172,3 +178,40 @@ func (a *API) Stop(c *gin.Context) {
 	}
 	response.Success(c)
 }
+
+func (a *API) SearchLogs(c *gin.Context) {
+	const op = "pipelinerun: search logs"
+	clusterIDStr := c.Param(_clusterIDParam)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("invalid cluster id"))
+		return
+	}
+	regex := false
+	if regexStr := c.Query(_regexParam); regexStr != "" {
+		regex, err = strconv.ParseBool(regexStr)
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("invalid regex"))
+			return
+		}
+	}
+
+	resp, err := a.prCtl.SearchLogs(c, uint(clusterID), &prctl.SearchLogsRequest{
+		Pattern: c.Query(_patternParam),
+		Regex:   regex,
+	})
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
@@ -49,6 +49,11 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/clusters/:%v/pipelineruns", _clusterIDParam),
 			HandlerFunc: api.List,
 		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/pipelinerunlogs", _clusterIDParam),
+			HandlerFunc: api.SearchLogs,
+		},
 	}
 
 	route.RegisterRoutes(apiGroup, routes)
@@ -0,0 +1,189 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasepipeline
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/releasepipeline"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const _releasePipelineIDParam = "releasePipelineID"
+
+type API struct {
+	releasePipelineCtl releasepipeline.Controller
+}
+
+func NewAPI(releasePipelineCtl releasepipeline.Controller) *API {
+	return &API{
+		releasePipelineCtl: releasePipelineCtl,
+	}
+}
+
+func (a *API) Create(c *gin.Context) {
+	const op = "release pipeline: create"
+	applicationID, ok := parseApplicationID(c)
+	if !ok {
+		return
+	}
+
+	var request releasepipeline.CreateReleasePipelineRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	resp, err := a.releasePipelineCtl.Create(c, applicationID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "release pipeline: list"
+	applicationID, ok := parseApplicationID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := a.releasePipelineCtl.List(c, applicationID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Get(c *gin.Context) {
+	const op = "release pipeline: get"
+	applicationID, id, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+
+	resp, err := a.releasePipelineCtl.Get(c, applicationID, id)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Update(c *gin.Context) {
+	const op = "release pipeline: update"
+	applicationID, id, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var request releasepipeline.UpdateReleasePipelineRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	resp, err := a.releasePipelineCtl.Update(c, applicationID, id, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Delete(c *gin.Context) {
+	const op = "release pipeline: delete"
+	applicationID, id, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := a.releasePipelineCtl.Delete(c, applicationID, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) Promote(c *gin.Context) {
+	const op = "release pipeline: promote"
+	applicationID, id, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var request releasepipeline.PromoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	resp, err := a.releasePipelineCtl.Promote(c, applicationID, id, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func parseApplicationID(c *gin.Context) (uint, bool) {
+	applicationIDStr := c.Param(common.ParamApplicationID)
+	applicationID, err := strconv.ParseUint(applicationIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("invalid application id"))
+		return 0, false
+	}
+	return uint(applicationID), true
+}
+
+func parseIDs(c *gin.Context) (uint, uint, bool) {
+	applicationID, ok := parseApplicationID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	idStr := c.Param(_releasePipelineIDParam)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("invalid release pipeline id"))
+		return 0, 0, false
+	}
+	return applicationID, uint(id), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid, herrors.ErrHealthGateNotPassed, herrors.ErrClusterNoChange,
+		herrors.ErrPipelineOutputEmpty:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrForbidden, herrors.ErrDeployWindowClosed:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -0,0 +1,60 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasepipeline
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+func (api *API) RegisterRoute(engine *gin.Engine) {
+	group := engine.Group("/apis/core/v2")
+	var routes = route.Routes{
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/applications/:%v/releasepipelines", common.ParamApplicationID),
+			HandlerFunc: api.List,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/applications/:%v/releasepipelines", common.ParamApplicationID),
+			HandlerFunc: api.Create,
+		}, {
+			Method: http.MethodGet,
+			Pattern: fmt.Sprintf("/applications/:%v/releasepipelines/:%v",
+				common.ParamApplicationID, _releasePipelineIDParam),
+			HandlerFunc: api.Get,
+		}, {
+			Method: http.MethodPut,
+			Pattern: fmt.Sprintf("/applications/:%v/releasepipelines/:%v",
+				common.ParamApplicationID, _releasePipelineIDParam),
+			HandlerFunc: api.Update,
+		}, {
+			Method: http.MethodDelete,
+			Pattern: fmt.Sprintf("/applications/:%v/releasepipelines/:%v",
+				common.ParamApplicationID, _releasePipelineIDParam),
+			HandlerFunc: api.Delete,
+		}, {
+			Method: http.MethodPost,
+			Pattern: fmt.Sprintf("/applications/:%v/releasepipelines/:%v/promote",
+				common.ParamApplicationID, _releasePipelineIDParam),
+			HandlerFunc: api.Promote,
+		},
+	}
+	route.RegisterRoutes(group, routes)
+}
@@ -16,11 +16,15 @@ package terminal
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/terminal"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
@@ -31,6 +35,7 @@ const (
 	_clusterIDParam     = "clusterID"
 	_podNameQuery       = "podName"
 	_containerNameQuery = "containerName"
+	_recordingIDParam   = "recordingID"
 )
 
 type API struct {
@@ -57,6 +62,10 @@ func (a *API) CreateShell(c *gin.Context) {
 
 	sessionID, sockJS, err := a.terminalCtl.CreateShell(c, uint(clusterID), podName, containerName)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrShellDisabled {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			if e.Source == herrors.ClusterInDB || e.Source == herrors.PodsInK8S {
 				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
@@ -78,3 +87,88 @@ func (a *API) CreateShell(c *gin.Context) {
 	c.Request.URL.Path = fmt.Sprintf("/apis/core/v2/0/%s/websocket", sessionID)
 	sockJS.ServeHTTP(c.Writer, c.Request)
 }
+
+func (a *API) ListRecordings(c *gin.Context) {
+	const op = "terminal: list recordings"
+	clusterIDStr := c.Param(_clusterIDParam)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid cluster id: %s, "+
+			"err: %s", clusterIDStr, err.Error())))
+		return
+	}
+	keywords, err := parseRecordingKeywords(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	items, total, err := a.terminalCtl.ListRecordings(c, uint(clusterID), q.New(keywords).WithPagination(c))
+	if err != nil {
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: items,
+		Total: total,
+	})
+}
+
+func (a *API) GetRecording(c *gin.Context) {
+	const op = "terminal: get recording"
+	clusterIDStr := c.Param(_clusterIDParam)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid cluster id: %s, "+
+			"err: %s", clusterIDStr, err.Error())))
+		return
+	}
+	recordingIDStr := c.Param(_recordingIDParam)
+	recordingID, err := strconv.ParseUint(recordingIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid recording id: %s, "+
+			"err: %s", recordingIDStr, err.Error())))
+		return
+	}
+
+	content, err := a.terminalCtl.GetRecording(c, uint(clusterID), uint(recordingID))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	c.Data(http.StatusOK, "application/x-asciicast", content)
+}
+
+func parseRecordingKeywords(c *gin.Context) (q.KeyWords, error) {
+	keywords := q.KeyWords{}
+	if pod := c.Query(common.TerminalRecordingQueryPod); pod != "" {
+		keywords[common.TerminalRecordingQueryPod] = pod
+	}
+	if userIDStr := c.Query(common.TerminalRecordingQueryUserID); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 0)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid userID: %s", userIDStr)
+		}
+		keywords[common.TerminalRecordingQueryUserID] = uint(userID)
+	}
+	for _, key := range []string{common.TerminalRecordingQueryStartTime, common.TerminalRecordingQueryEndTime} {
+		if timeStr := c.Query(key); timeStr != "" {
+			t, err := time.Parse(time.RFC3339, timeStr)
+			if err != nil {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid %s: %s", key, timeStr)
+			}
+			keywords[key] = t
+		}
+	}
+	return keywords, nil
+}
@@ -32,6 +32,16 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/clusters/:%v/shell", _clusterIDParam),
 			HandlerFunc: api.CreateShell,
 		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/terminalrecordings", _clusterIDParam),
+			HandlerFunc: api.ListRecordings,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/terminalrecordings/:%v", _clusterIDParam, _recordingIDParam),
+			HandlerFunc: api.GetRecording,
+		},
 	}
 	route.RegisterRoutes(coreGroup, coreRoutes)
 }
@@ -63,6 +63,11 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/webhooks/:%v/logs", _webhookIDParam),
 			HandlerFunc: api.ListWebhookLogs,
 		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/webhooks/:%v/stats", _webhookIDParam),
+			HandlerFunc: api.GetWebhookStats,
+		},
 		{
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/webhooklogs/:%v", _webhookLogIDParam),
@@ -20,6 +20,7 @@ import (
 	"github.com/horizoncd/horizon/core/cmd"
 
 	// for image registry
+	_ "github.com/horizoncd/horizon/pkg/cluster/registry/distribution"
 	_ "github.com/horizoncd/horizon/pkg/cluster/registry/harbor/v1"
 	_ "github.com/horizoncd/horizon/pkg/cluster/registry/harbor/v2"
 
@@ -29,12 +30,18 @@ import (
 
 	// for template repo
 	_ "github.com/horizoncd/horizon/pkg/templaterepo/chartmuseumbase"
+	_ "github.com/horizoncd/horizon/pkg/templaterepo/oci"
 
 	// for k8s workload
+	_ "github.com/horizoncd/horizon/pkg/workload/cronjob"
+	_ "github.com/horizoncd/horizon/pkg/workload/daemonset"
 	_ "github.com/horizoncd/horizon/pkg/workload/deployment"
+	_ "github.com/horizoncd/horizon/pkg/workload/job"
+	_ "github.com/horizoncd/horizon/pkg/workload/kruise"
 	_ "github.com/horizoncd/horizon/pkg/workload/kservice"
 	_ "github.com/horizoncd/horizon/pkg/workload/pod"
 	_ "github.com/horizoncd/horizon/pkg/workload/rollout"
+	_ "github.com/horizoncd/horizon/pkg/workload/statefulset"
 )
 
 func main() {
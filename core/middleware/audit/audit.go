@@ -0,0 +1,305 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/pkg/audit/manager"
+	"github.com/horizoncd/horizon/pkg/audit/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	"github.com/horizoncd/horizon/pkg/config/audit"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/sets"
+)
+
+const (
+	_redacted  = "******"
+	_truncated = "...(truncated)"
+
+	_defaultMaxBodySize = 64 * 1024
+)
+
+var RequestInfoFty auth.RequestInfoFactory
+
+func init() {
+	RequestInfoFty = auth.RequestInfoFactory{
+		APIPrefixes: sets.NewString("apis"),
+	}
+}
+
+// sensitiveKeys are redacted from the request body if any of them is contained in the field name
+var sensitiveKeys = []string{"password", "secret", "token", "privatekey", "credential"}
+
+// sensitiveFields are redacted from the request body if the field name equals to any of them,
+// such as the authorization code of oauth
+var sensitiveFields = sets.NewString("code")
+
+// credentialPaths exchange or create credentials, whose bodies are never recorded
+var credentialPaths = []*regexp.Regexp{
+	regexp.MustCompile(`^/login/oauth/`),
+	regexp.MustCompile(`/login$`),
+	regexp.MustCompile(`/accesstokens$`),
+}
+
+// Change is the change of a field in the request body
+type Change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Middleware records every mutating request into the audit log,
+// including the actor, auth method, resource, request body and the result code.
+func Middleware(mgr manager.Manager, config audit.Config, skippers ...middleware.Skipper) gin.HandlerFunc {
+	maxBodySize := config.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = _defaultMaxBodySize
+	}
+	return middleware.New(func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		// only the recorded part of body is read ahead, the rest is streamed to the handler
+		var body []byte
+		truncated := false
+		if c.Request.Body != nil {
+			var err error
+			body, err = ioutil.ReadAll(io.LimitReader(c.Request.Body, int64(maxBodySize)+1))
+			if err != nil {
+				log.Warningf(c, "failed to read request body for audit: %v", err)
+			}
+			if len(body) > maxBodySize {
+				truncated = true
+			}
+			c.Request.Body = readCloser{
+				Reader: io.MultiReader(bytes.NewReader(body), c.Request.Body),
+				Closer: c.Request.Body,
+			}
+		}
+		var before *common.AuditBefore
+		if !config.SkipDiff && !truncated &&
+			(c.Request.Method == http.MethodPut || c.Request.Method == http.MethodPatch) {
+			before = common.WithAuditBefore(c)
+		}
+		requestInfo, _ := RequestInfoFty.NewRequestInfo(c.Request)
+
+		c.Next()
+
+		auditLog := &models.AuditLog{
+			AuthMethod:  common.AuthMethodFromContext(c),
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			RequestBody: formatBody(body, c.GetHeader("Content-Type"), maxBodySize),
+			StatusCode:  c.Writer.Status(),
+		}
+		if before != nil {
+			auditLog.Diff = formatDiff(before.Value, body)
+		}
+		if isCredentialPath(c.Request.URL.Path) {
+			auditLog.RequestBody = ""
+		}
+		auditLog.RequestID, _ = requestid.FromContext(c)
+		if currentUser, err := common.UserFromContext(c); err == nil {
+			auditLog.UserID = currentUser.GetID()
+			auditLog.UserName = currentUser.GetName()
+		} else {
+			auditLog.AuthMethod = models.AuthMethodNone
+		}
+		if auditLog.AuthMethod == "" {
+			auditLog.AuthMethod = models.AuthMethodNone
+		}
+		// the auth record is preferred, as names of resources have been replaced by ids in it
+		if record, ok := c.Get(common.ContextAuthRecord); ok {
+			authRecord := record.(auth.AttributesRecord)
+			auditLog.Verb = authRecord.Verb
+			auditLog.Resource = authRecord.Resource
+			auditLog.ResourceName = authRecord.Name
+			auditLog.SubResource = authRecord.SubResource
+		} else if requestInfo != nil {
+			auditLog.Verb = requestInfo.Verb
+			auditLog.Resource = requestInfo.Resource
+			auditLog.ResourceName = requestInfo.Name
+			auditLog.SubResource = requestInfo.Subresource
+		}
+
+		if _, err := mgr.Create(c, auditLog); err != nil {
+			log.Errorf(c, "failed to create audit log, err: %+v", err)
+		}
+	}, skippers...)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// formatBody redacts sensitive fields of the json, form or multipart body and truncates it to maxSize.
+// A body longer than maxSize has been truncated when read, which can't be parsed,
+// so it's recorded only if it's neither a form nor contains sensitive keys.
+func formatBody(body []byte, contentType string, maxSize int) string {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if len(body) > maxSize {
+		if isSensitive(string(body)) || mediaType == binding.MIMEPOSTForm ||
+			mediaType == binding.MIMEMultipartPOSTForm {
+			return _truncated
+		}
+		return string(body[:maxSize]) + _truncated
+	}
+	switch mediaType {
+	case binding.MIMEPOSTForm:
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			body = []byte(redactForm(form).Encode())
+		}
+	case binding.MIMEMultipartPOSTForm:
+		body = []byte(formatMultipart(body, params["boundary"]))
+	default:
+		var content interface{}
+		if err := json.Unmarshal(body, &content); err == nil {
+			if redacted, err := json.Marshal(redact(content)); err == nil {
+				body = redacted
+			}
+		}
+	}
+	if len(body) > maxSize {
+		return string(body[:maxSize]) + _truncated
+	}
+	return string(body)
+}
+
+// formatMultipart records the fields of multipart body as a form, files are recorded by their names,
+// and the fields after a malformed part are dropped
+func formatMultipart(body []byte, boundary string) string {
+	form := url.Values{}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		if part.FileName() != "" {
+			form.Add(part.FormName(), part.FileName())
+			continue
+		}
+		value, err := ioutil.ReadAll(part)
+		if err != nil {
+			break
+		}
+		form.Add(part.FormName(), string(value))
+	}
+	return redactForm(form).Encode()
+}
+
+func redactForm(form url.Values) url.Values {
+	for key, values := range form {
+		if isSensitive(key) {
+			for i := range values {
+				values[i] = _redacted
+			}
+		}
+	}
+	return form
+}
+
+func isCredentialPath(path string) bool {
+	for _, pattern := range credentialPaths {
+		if pattern.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatDiff returns the json of fields in the request body which differ from the resource before the request
+func formatDiff(before, body []byte) string {
+	if before == nil {
+		return ""
+	}
+	var beforeMap, after map[string]interface{}
+	if err := json.Unmarshal(before, &beforeMap); err != nil {
+		return ""
+	}
+	if err := json.Unmarshal(body, &after); err != nil {
+		return ""
+	}
+	changes := diff(redact(beforeMap).(map[string]interface{}), redact(after).(map[string]interface{}))
+	if len(changes) == 0 {
+		return ""
+	}
+	content, err := json.Marshal(changes)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+func diff(before, after map[string]interface{}) map[string]Change {
+	changes := map[string]Change{}
+	for k, v := range after {
+		if !reflect.DeepEqual(before[k], v) {
+			changes[k] = Change{Old: before[k], New: v}
+		}
+	}
+	return changes
+}
+
+func redact(content interface{}) interface{} {
+	switch v := content.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if isSensitive(key) {
+				v[key] = _redacted
+				continue
+			}
+			v[key] = redact(value)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redact(v[i])
+		}
+	}
+	return content
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	if sensitiveFields.Has(key) {
+		return true
+	}
+	for _, sensitiveKey := range sensitiveKeys {
+		if strings.Contains(key, sensitiveKey) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	mockmanager "github.com/horizoncd/horizon/mock/pkg/audit/manager"
+	"github.com/horizoncd/horizon/pkg/audit/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/config/audit"
+	"github.com/horizoncd/horizon/pkg/server/response"
+)
+
+func TestMiddleware(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mgr := mockmanager.NewMockManager(mockCtl)
+
+	var auditLogs []*models.AuditLog
+	mgr.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, auditLog *models.AuditLog) (*models.AuditLog, error) {
+			auditLogs = append(auditLogs, auditLog)
+			return auditLog, nil
+		}).AnyTimes()
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		common.SetUser(c, &userauth.DefaultInfo{Name: "tony", ID: 1})
+		if c.GetHeader(common.AuthorizationHeaderKey) != "" {
+			common.SetAuthMethod(c, models.AuthMethodAccessToken)
+		}
+	}, Middleware(mgr, audit.Config{MaxBodySize: 1024}))
+	r.GET("/apis/core/v2/applications/:id", func(c *gin.Context) {
+		response.Success(c)
+	})
+	r.PUT("/apis/core/v2/applications/:id", func(c *gin.Context) {
+		before := map[string]interface{}{
+			"description": "old",
+			"priority":    "P0",
+			"password":    "old-password",
+		}
+		common.SetAuditBefore(c, before)
+		// changes after recorded are ignored
+		before["priority"] = "P1"
+		var body map[string]interface{}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestBody, err.Error())
+			return
+		}
+		response.Success(c)
+	})
+	r.POST("/apis/core/v2/applications/:id/transfer", func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusForbidden)
+	})
+	r.POST("/login/oauth/access_token", func(c *gin.Context) {
+		response.Success(c)
+	})
+	var uploaded []byte
+	r.POST("/apis/core/v2/applications/:id/upload", func(c *gin.Context) {
+		uploaded, _ = ioutil.ReadAll(c.Request.Body)
+		response.Success(c)
+	})
+
+	// get is not audited
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/apis/core/v2/applications/1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0, len(auditLogs))
+
+	// update records the redacted body and the diff
+	body, _ := json.Marshal(map[string]interface{}{
+		"description": "new",
+		"priority":    "P0",
+		"password":    "new-password",
+	})
+	req := httptest.NewRequest(http.MethodPut, "/apis/core/v2/applications/1", bytes.NewReader(body))
+	req.Header.Set(common.AuthorizationHeaderKey, "Bearer ha_xxx")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, len(auditLogs))
+	auditLog := auditLogs[0]
+	assert.Equal(t, uint(1), auditLog.UserID)
+	assert.Equal(t, "tony", auditLog.UserName)
+	assert.Equal(t, models.AuthMethodAccessToken, auditLog.AuthMethod)
+	assert.Equal(t, "update", auditLog.Verb)
+	assert.Equal(t, "applications", auditLog.Resource)
+	assert.Equal(t, "1", auditLog.ResourceName)
+	assert.Equal(t, http.StatusOK, auditLog.StatusCode)
+	assert.NotContains(t, auditLog.RequestBody, "new-password")
+	assert.Contains(t, auditLog.RequestBody, _redacted)
+	var changes map[string]Change
+	assert.Nil(t, json.Unmarshal([]byte(auditLog.Diff), &changes))
+	assert.Equal(t, map[string]Change{
+		"description": {Old: "old", New: "new"},
+	}, changes)
+
+	// rejected requests are recorded too
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/apis/core/v2/applications/1/transfer", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, 2, len(auditLogs))
+	assert.Equal(t, models.AuthMethodNone, auditLogs[1].AuthMethod)
+	assert.Equal(t, "transfer", auditLogs[1].SubResource)
+	assert.Equal(t, http.StatusForbidden, auditLogs[1].StatusCode)
+
+	// large bodies are streamed to the handler, and only the prefix is recorded
+	large := strings.Repeat("a", 4096)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/apis/core/v2/applications/1/upload",
+		strings.NewReader(large)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, large, string(uploaded))
+	assert.Equal(t, 3, len(auditLogs))
+	assert.Equal(t, large[:1024]+_truncated, auditLogs[2].RequestBody)
+
+	// sensitive fields of forms are redacted
+	form := url.Values{"client_id": {"id"}, "client_secret": {"plain-secret"}}
+	req = httptest.NewRequest(http.MethodPost, "/apis/core/v2/applications/1/upload",
+		strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", binding.MIMEPOSTForm)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, form.Encode(), string(uploaded))
+	assert.Equal(t, 4, len(auditLogs))
+	assert.NotContains(t, auditLogs[3].RequestBody, "plain-secret")
+	assert.Contains(t, auditLogs[3].RequestBody, "client_id=id")
+
+	// bodies of credential endpoints are not recorded
+	req = httptest.NewRequest(http.MethodPost, "/login/oauth/access_token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", binding.MIMEPOSTForm)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 5, len(auditLogs))
+	assert.Empty(t, auditLogs[4].RequestBody)
+}
+
+func TestFormatBody(t *testing.T) {
+	body := formatBody([]byte(`{"name":"a","git":{"token":"t","url":"u"},"items":[{"secretKey":"s"}]}`),
+		binding.MIMEJSON, 1024)
+	assert.Equal(t, `{"git":{"token":"******","url":"u"},"items":[{"secretKey":"******"}],"name":"a"}`, body)
+
+	body = formatBody([]byte(`{"a":"b"}`), binding.MIMEJSON, 3)
+	assert.Equal(t, `{"a`+_truncated, body)
+
+	// truncated bodies can't be redacted, so they are dropped if sensitive keys are contained
+	body = formatBody([]byte(`{"token":"t"}`), binding.MIMEJSON, 5)
+	assert.Equal(t, _truncated, body)
+
+	body = formatBody([]byte(`client_id=c&client_secret=s&code=x`), binding.MIMEPOSTForm, 1024)
+	assert.Equal(t, `client_id=c&client_secret=%2A%2A%2A%2A%2A%2A&code=%2A%2A%2A%2A%2A%2A`, body)
+	body = formatBody([]byte(`client_id=c&client_secret=s`), binding.MIMEPOSTForm, 5)
+	assert.Equal(t, _truncated, body)
+
+	var multipartBody bytes.Buffer
+	writer := multipart.NewWriter(&multipartBody)
+	assert.Nil(t, writer.WriteField("name", "a"))
+	assert.Nil(t, writer.WriteField("password", "p"))
+	file, err := writer.CreateFormFile("file", "values.yaml")
+	assert.Nil(t, err)
+	_, _ = file.Write([]byte("content"))
+	assert.Nil(t, writer.Close())
+	body = formatBody(multipartBody.Bytes(), writer.FormDataContentType(), 1024)
+	assert.Equal(t, `file=values.yaml&name=a&password=%2A%2A%2A%2A%2A%2A`, body)
+}
@@ -45,10 +45,12 @@ func Middleware(authorizer rbac.Authorizer, skipMatchers ...middleware.Skipper)
 		authRecord := record.(auth.AttributesRecord)
 		authRecord.User = currentUser
 
-		// for routes like /apis/core/v1/applications
+		// for routes like /apis/core/v1/applications, unless the token is restricted to resources
 		if authRecord.Name == "" && authRecord.IsReadOnly() {
-			c.Next()
-			return
+			if restrictions, ok := common.TokenRestrictionsFromContext(c); !ok || len(restrictions.Resources) == 0 {
+				c.Next()
+				return
+			}
 		}
 
 		decision, reason, err := authorizer.Authorize(c, authRecord)
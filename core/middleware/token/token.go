@@ -15,11 +15,14 @@
 package token
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/oauthcheck"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware"
+	auditmodels "github.com/horizoncd/horizon/pkg/audit/models"
 	"github.com/horizoncd/horizon/pkg/auth"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
@@ -42,7 +45,7 @@ const (
 func MiddleWare(oauthCtl oauthcheck.Controller, skipMatchers ...middleware.Skipper) gin.HandlerFunc {
 	return middleware.New(func(c *gin.Context) {
 		// 1. get user from token and set user context
-		token, err := common.GetToken(c)
+		code, err := common.GetToken(c)
 		if err != nil {
 			log.Warning(c, "Have not got token")
 			c.Next()
@@ -50,6 +53,15 @@ func MiddleWare(oauthCtl oauthcheck.Controller, skipMatchers ...middleware.Skipp
 		}
 
 		// 2. check token valid
+		token, err := oauthCtl.LoadToken(c, code)
+		if err != nil {
+			if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				response.AbortWithUnauthorized(c, common.Unauthorized, e.Error())
+				return
+			}
+			response.AbortWithUnauthorized(c, common.InternalError, err.Error())
+			return
+		}
 		if err := oauthCtl.ValidateToken(c, token); err != nil {
 			if perror.Cause(err) == herrors.ErrOAuthAccessTokenExpired {
 				response.AbortWithUnauthorized(c, common.CodeExpired, err.Error())
@@ -74,13 +86,19 @@ func MiddleWare(oauthCtl oauthcheck.Controller, skipMatchers ...middleware.Skipp
 			return
 		}
 		common.SetUser(c, user)
+		// access tokens are not granted to any oauth app
+		if token.ClientID == "" {
+			common.SetAuthMethod(c, auditmodels.AuthMethodAccessToken)
+		} else {
+			common.SetAuthMethod(c, auditmodels.AuthMethodOAuthApp)
+		}
 
 		requestInfo, err := RequestInfoFty.NewRequestInfo(c.Request)
 		if err != nil {
 			response.AbortWithRequestError(c, common.RequestInfoError, err.Error())
 			return
 		}
-		result, reason, err := oauthCtl.CheckScopePermission(c, token, *requestInfo)
+		result, reason, err := oauthCtl.CheckScopePermission(c, token, user, *requestInfo)
 		if err != nil {
 			if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 				response.AbortWithUnauthorized(c, common.Unauthorized, e.Error())
@@ -93,6 +111,26 @@ func MiddleWare(oauthCtl oauthcheck.Controller, skipMatchers ...middleware.Skipp
 			return
 		}
 		log.WithFiled(c, CheckResult, result).Infof("reason = %s", reason)
+
+		// 4. check restrictions of token, and leave restrictions of resources to the authorizer
+		// X-Forwarded-For and X-Real-IP are only respected when the peer is one of the trusted proxies of engine
+		restrictions := token.GetRestrictions()
+		clientIP := c.ClientIP()
+		if !restrictions.AllowIP(clientIP) {
+			log.WithFiled(c, CheckResult, false).Warningf("reason = ip %s is not allowed", clientIP)
+			response.AbortWithForbiddenError(c, common.Forbidden,
+				fmt.Sprintf("token is not allowed to be used from %s", clientIP))
+			return
+		}
+		if !restrictions.AllowVerb(requestInfo.Verb, requestInfo.Subresource) {
+			log.WithFiled(c, CheckResult, false).Warningf("reason = verb %s is not allowed", requestInfo.Verb)
+			response.AbortWithForbiddenError(c, common.Forbidden, "token is not allowed to perform this request")
+			return
+		}
+		common.SetTokenRestrictions(c, restrictions)
+		if err := oauthCtl.RecordUsage(c, token, clientIP); err != nil {
+			log.Warningf(c, "failed to record usage of token, err: %v", err)
+		}
 		c.Next()
 	}, skipMatchers...)
 }
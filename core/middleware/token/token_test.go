@@ -0,0 +1,116 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/oauthcheck"
+	"github.com/horizoncd/horizon/lib/orm"
+	auditmodels "github.com/horizoncd/horizon/pkg/audit/models"
+	oauthconfig "github.com/horizoncd/horizon/pkg/config/oauth"
+	"github.com/horizoncd/horizon/pkg/oauth/scope"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	ctx     = context.Background()
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&tokenmodels.Token{}, &usermodels.User{}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestAllowedCIDRs(t *testing.T) {
+	user, err := manager.UserManager.Create(ctx, &usermodels.User{Name: "token-user"})
+	assert.Nil(t, err)
+	_, err = manager.TokenManager.CreateToken(ctx, &tokenmodels.Token{
+		Name:         "restricted",
+		Code:         "restricted-code",
+		Scope:        "clusters:read-only",
+		UserID:       user.ID,
+		AllowedCIDRs: "10.0.0.0/8",
+	})
+	assert.Nil(t, err)
+
+	scopeService, err := scope.NewFileScopeService(oauthconfig.Scopes{
+		Roles: []types.Role{{
+			Name: "clusters:read-only",
+			PolicyRules: []types.PolicyRule{{
+				Verbs:     []string{"get"},
+				APIGroups: []string{"core"},
+				Resources: []string{"clusters"},
+				Scopes:    []string{"*"},
+			}},
+		}},
+	})
+	assert.Nil(t, err)
+	oauthCtl := oauthcheck.NewOauthChecker(&param.Param{Manager: manager, ScopeService: scopeService})
+
+	newEngine := func(trustedProxies []string) *gin.Engine {
+		r := gin.New()
+		assert.Nil(t, r.SetTrustedProxies(trustedProxies))
+		r.Use(MiddleWare(oauthCtl))
+		r.GET("/apis/core/v2/clusters/:clusterID", func(c *gin.Context) {
+			// the token is not granted to any oauth app
+			assert.Equal(t, auditmodels.AuthMethodAccessToken, common.AuthMethodFromContext(c))
+			c.Status(http.StatusOK)
+		})
+		return r
+	}
+	serve := func(r *gin.Engine, remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/apis/core/v2/clusters/1", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(common.AuthorizationHeaderKey, common.TokenHeaderValuePrefix+" restricted-code")
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	r := newEngine(nil)
+	assert.Equal(t, http.StatusOK, serve(r, "10.0.0.1:12345", ""))
+	assert.Equal(t, http.StatusForbidden, serve(r, "192.0.2.1:12345", ""))
+	// forged X-Forwarded-For from an untrusted peer is ignored
+	assert.Equal(t, http.StatusForbidden, serve(r, "192.0.2.1:12345", "10.0.0.1"))
+	assert.Equal(t, http.StatusForbidden, serve(r, "192.0.2.1:12345", "10.0.0.1, 192.0.2.2"))
+
+	// X-Forwarded-For is respected when the peer is a trusted proxy
+	r = newEngine([]string{"192.0.2.1"})
+	assert.Equal(t, http.StatusOK, serve(r, "192.0.2.1:12345", "10.0.0.1"))
+	assert.Equal(t, http.StatusForbidden, serve(r, "192.0.2.1:12345", "192.0.2.3"))
+
+	token, err := manager.TokenManager.LoadTokenByCode(ctx, "restricted-code")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.1", token.LastUsedIP)
+}
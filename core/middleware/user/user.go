@@ -34,6 +34,7 @@ import (
 	coreconfig "github.com/horizoncd/horizon/core/config"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware"
+	auditmodels "github.com/horizoncd/horizon/pkg/audit/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/config/authenticate"
 	perror "github.com/horizoncd/horizon/pkg/errors"
@@ -77,6 +78,7 @@ func Middleware(param *param.Param, store sessions.Store,
 				Email:    user.Email,
 				Admin:    user.Admin,
 			})
+			common.SetAuthMethod(c, auditmodels.AuthMethodSignature)
 			c.Next()
 			return
 		}
@@ -100,6 +102,7 @@ func Middleware(param *param.Param, store sessions.Store,
 		if user, ok := u.(*userauth.DefaultInfo); ok && user != nil {
 			// attach user to context
 			common.SetUser(c, user)
+			common.SetAuthMethod(c, auditmodels.AuthMethodSession)
 			c.Next()
 			return
 		}
@@ -0,0 +1,36 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- alert rule table
+CREATE TABLE `tb_alert_rule`
+(
+    `id`          bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`  bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `name`        varchar(64)         NOT NULL DEFAULT '' COMMENT 'name of the rule',
+    `type`        varchar(32)         NOT NULL DEFAULT '' COMMENT 'errorRate, restarts or query',
+    `query`       varchar(1024)       NOT NULL DEFAULT '' COMMENT 'PromQL query in go template format',
+    `operator`    varchar(8)          NOT NULL DEFAULT '' COMMENT 'firing when value < or > threshold',
+    `threshold`   double              NOT NULL DEFAULT '0',
+    `duration`    varchar(32)         NOT NULL DEFAULT '' COMMENT 'how long the condition lasts before firing',
+    `severity`    varchar(32)         NOT NULL DEFAULT '' COMMENT 'critical, warning or info',
+    `description` varchar(256)        NOT NULL DEFAULT '' COMMENT 'description of the alert',
+    `created_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`  bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`  bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_name` (`cluster_id`, `name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,40 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+-- audit log table, recording mutating api calls
+CREATE TABLE `tb_audit_log`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `request_id`    varchar(64)         NOT NULL DEFAULT '' COMMENT 'id of request',
+    `user_id`       bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of user, 0 if not authenticated',
+    `user_name`     varchar(128)        NOT NULL DEFAULT '' COMMENT 'name of user',
+    `auth_method`   varchar(32)         NOT NULL DEFAULT '' COMMENT 'session, accesstoken, oauthapp, signature or none',
+    `method`        varchar(16)         NOT NULL DEFAULT '' COMMENT 'http method',
+    `path`          varchar(1024)       NOT NULL DEFAULT '' COMMENT 'http path',
+    `verb`          varchar(32)         NOT NULL DEFAULT '' COMMENT 'verb of request',
+    `resource`      varchar(64)         NOT NULL DEFAULT '' COMMENT 'resource of request',
+    `resource_name` varchar(256)        NOT NULL DEFAULT '' COMMENT 'name or id of resource',
+    `sub_resource`  varchar(64)         NOT NULL DEFAULT '' COMMENT 'sub resource of request',
+    `request_body`  mediumtext COMMENT 'request body with sensitive fields redacted',
+    `diff`          mediumtext COMMENT 'changed fields of resource',
+    `status_code`   int(11)             NOT NULL DEFAULT 0 COMMENT 'http status code of response',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_user_id` (`user_id`),
+    KEY `idx_resource` (`resource`, `resource_name`),
+    KEY `idx_created_at` (`created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
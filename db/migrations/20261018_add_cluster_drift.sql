@@ -0,0 +1,35 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE tb_environment
+    ADD auto_resync tinyint(1) NOT NULL DEFAULT 0 COMMENT 'whether drifted clusters are resynced automatically' AFTER approvers;
+
+-- cluster drift table
+CREATE TABLE `tb_cluster_drift`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`    bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `sync_status`   varchar(32)         NOT NULL DEFAULT '' COMMENT 'sync status in argo',
+    `health_status` varchar(32)         NOT NULL DEFAULT '' COMMENT 'health status in argo',
+    `revision`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'gitops commit which the cluster was synced to',
+    `resources`     text                NULL COMMENT 'resources differing from the gitops repo in json',
+    `resynced`      tinyint(1)          NOT NULL DEFAULT 0 COMMENT 'whether the cluster is resynced automatically',
+    `resolved_at`   datetime            NULL COMMENT 'time when the cluster is synced again',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_cluster_id` (`cluster_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
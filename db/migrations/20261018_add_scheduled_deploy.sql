@@ -0,0 +1,38 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE tb_environment
+    ADD deploy_windows text NULL COMMENT 'deploy windows in json, empty means no limit' AFTER display_name;
+
+-- scheduled deploy table
+CREATE TABLE `tb_scheduled_deploy`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `action`         varchar(32)         NOT NULL DEFAULT '' COMMENT 'builddeploy, deploy or restart',
+    `request`        text                NULL COMMENT 'request body of the action in json',
+    `scheduled_at`   datetime            NOT NULL COMMENT 'time to execute',
+    `status`         varchar(32)         NOT NULL DEFAULT '' COMMENT 'pending, running, succeeded, failed or cancelled',
+    `pipelinerun_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'pipelinerun created by the execution',
+    `message`        varchar(2048)       NOT NULL DEFAULT '' COMMENT 'failure reason',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    KEY `idx_cluster_id` (`cluster_id`),
+    KEY `idx_status_scheduled_at` (`status`, `scheduled_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
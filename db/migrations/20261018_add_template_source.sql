@@ -0,0 +1,22 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE tb_template
+    ADD source_kind varchar(32) NOT NULL DEFAULT '' COMMENT 'kind of repository: git, oci or helm, empty means git' AFTER repository;
+
+ALTER TABLE tb_template_release
+    ADD digest varchar(256) NOT NULL DEFAULT '' COMMENT 'digest of chart at last sync, commit id for git' AFTER commit_id;
+
+-- releases synced from git are identified by commit id
+UPDATE tb_template_release SET digest = commit_id WHERE digest = '';
@@ -0,0 +1,42 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+-- terminal recording table, indexing shell sessions recorded in the log storage
+CREATE TABLE `tb_terminal_recording`
+(
+    `id`          bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `session_id`  varchar(256)        NOT NULL DEFAULT '' COMMENT 'id of terminal session',
+    `cluster_id`  bigint(20) unsigned NOT NULL COMMENT 'id of cluster',
+    `environment` varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment of cluster',
+    `pod`         varchar(256)        NOT NULL DEFAULT '' COMMENT 'name of pod',
+    `container`   varchar(256)        NOT NULL DEFAULT '' COMMENT 'name of container',
+    `user_id`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of user who opened the shell',
+    `user_name`   varchar(128)        NOT NULL DEFAULT '' COMMENT 'name of user who opened the shell',
+    `object_path` varchar(1024)       NOT NULL DEFAULT '' COMMENT 'path prefix of recording chunks in the log storage',
+    `size`        bigint(20)          NOT NULL DEFAULT 0 COMMENT 'bytes of recording, 0 before the session ends',
+    `chunks`      int(11)             NOT NULL DEFAULT 0 COMMENT 'number of recording chunks, 0 before the session ends',
+    `started_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `ended_at`    datetime                     DEFAULT NULL,
+    `created_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_cluster_pod` (`cluster_id`, `pod`),
+    KEY `idx_user_id` (`user_id`),
+    KEY `idx_started_at` (`started_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+ALTER TABLE tb_environment
+    ADD `terminal_policy` varchar(32) NOT NULL DEFAULT '' COMMENT 'terminal policy: empty, record or disabled';
@@ -0,0 +1,21 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+ALTER TABLE tb_token
+    ADD `resources`     varchar(1024) NOT NULL DEFAULT '' COMMENT 'resources the token is restricted to, joined by space',
+    ADD `verbs`         varchar(256)  NOT NULL DEFAULT '' COMMENT 'verbs the token is restricted to, joined by space',
+    ADD `allowed_cidrs` varchar(1024) NOT NULL DEFAULT '' COMMENT 'source cidrs the token could be used from, joined by space',
+    ADD `last_used_at`  datetime               DEFAULT NULL COMMENT 'last time the token is used',
+    ADD `last_used_ip`  varchar(64)   NOT NULL DEFAULT '' COMMENT 'source ip the token is used from last time';
@@ -0,0 +1,44 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE tb_identity_provider
+    ADD groups_claim varchar(256) NOT NULL DEFAULT '' COMMENT 'claim of userinfo containing groups of user' AFTER client_secret;
+
+-- user group table, synced from groups claim of idp
+CREATE TABLE `tb_user_group`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `idp_id`     bigint(20) unsigned NOT NULL COMMENT 'id of identity provider',
+    `name`       varchar(256)        NOT NULL DEFAULT '' COMMENT 'name of group in identity provider',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_idp_name` (`idp_id`, `name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- user group member table
+CREATE TABLE `tb_user_group_member`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `user_group_id` bigint(20) unsigned NOT NULL COMMENT 'id of user group',
+    `user_id`       bigint(20) unsigned NOT NULL COMMENT 'id of user',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_group_user` (`user_group_id`, `user_id`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,23 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE tb_webhook
+    ADD max_attempts int unsigned NOT NULL DEFAULT 0 COMMENT 'max attempts to send a log, 0 means the default of webhook service' AFTER resource_id,
+    ADD consecutive_failures int unsigned NOT NULL DEFAULT 0 COMMENT 'failed attempts since last success' AFTER max_attempts;
+
+ALTER TABLE tb_webhook_log
+    ADD attempts int unsigned NOT NULL DEFAULT 0 COMMENT 'attempts made to send the log' AFTER error_message,
+    ADD next_retry_at datetime NULL COMMENT 'time to send the log again' AFTER attempts,
+    ADD latency_ms int unsigned NOT NULL DEFAULT 0 COMMENT 'latency of the last attempt in milliseconds' AFTER next_retry_at,
+    ADD KEY `idx_webhook_id_updated_at` (`webhook_id`, `updated_at`);
@@ -13,6 +13,7 @@ require (
 	github.com/go-redis/redis/v8 v8.3.3
 	github.com/golang-jwt/jwt/v4 v4.4.3
 	github.com/golang/mock v1.6.0
+	github.com/golang/protobuf v1.4.3
 	github.com/google/go-github/v41 v41.0.0
 	github.com/google/uuid v1.2.0
 	github.com/gorilla/mux v1.8.0
@@ -22,6 +23,7 @@ require (
 	github.com/johannesboyne/gofakes3 v0.0.0-20210819161434-5c8dfcfe5310
 	github.com/mozillazg/go-pinyin v0.18.0
 	github.com/pkg/errors v0.9.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/prometheus/client_golang v1.11.0
 	github.com/rbcervilla/redisstore/v8 v8.1.0
 	github.com/santhosh-tekuri/jsonschema/v5 v5.0.0
@@ -43,7 +45,7 @@ require (
 	gorm.io/gorm v1.21.15
 	gorm.io/plugin/prometheus v0.0.0-20210820101226-2a49866f83ee
 	gorm.io/plugin/soft_delete v1.0.3
-	helm.sh/helm/v3 v3.1.1
+	helm.sh/helm/v3 v3.2.4
 	k8s.io/api v0.23.5
 	k8s.io/apimachinery v0.23.5
 	k8s.io/cli-runtime v0.23.5
@@ -0,0 +1,833 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// Interface to interact with repositories hosted by any git server, it only depends on the git protocol.
+// Repositories are addressed by a slash separated path relative to the root URL, such as first/second.
+// nolint
+//
+//go:generate mockgen -source=$GOFILE -destination=../../mock/lib/git/mock_git.go -package=mock_git
+type Interface interface {
+	// CreateRepo creates a repository with the given path, the default branch is initialized
+	// with a README.md file. For a remote root, the server must support push-to-create.
+	CreateRepo(ctx context.Context, repo string) error
+
+	// DeleteRepo deletes a repository with the given path.
+	// For a remote root, all branches of the repository are deleted.
+	DeleteRepo(ctx context.Context, repo string) error
+
+	// MoveRepo moves a repository to a new path.
+	// For a remote root, all branches are pushed to the new path and then deleted from the old one.
+	MoveRepo(ctx context.Context, from, to string) error
+
+	// GetBranch returns the latest commit of a branch.
+	GetBranch(ctx context.Context, repo, branch string) (*Commit, error)
+
+	// CreateBranch creates a branch from fromRef, the fromRef can be the name of branch, tag or commit.
+	CreateBranch(ctx context.Context, repo, branch, fromRef string) (*Commit, error)
+
+	// GetFile gets a file content for specified filepath with the ref.
+	// The ref can be the name of branch, tag or commit.
+	GetFile(ctx context.Context, repo, ref, filepath string) ([]byte, error)
+
+	// WriteFiles writes including create, delete, update and move multiple files in one commit.
+	// If the branch does not exist, it will be created from startBranch.
+	WriteFiles(ctx context.Context, repo, branch, commitMsg string,
+		startBranch *string, actions []CommitAction) (*Commit, error)
+
+	// Compare branches, tags or commits. If straight is false, the diffs are
+	// computed from the merge base of from and to, just like 'git diff from...to'.
+	Compare(ctx context.Context, repo, from, to string, straight bool) ([]*Diff, error)
+
+	// MergeBranch merges source branch into target branch and returns the newest commit of target branch.
+	MergeBranch(ctx context.Context, repo, source, target, commitMsg string) (*Commit, error)
+
+	// GetRepoURL returns the URL which can be used to clone the repository.
+	GetRepoURL(repo string) string
+}
+
+type FileAction string
+
+// The available file actions.
+const (
+	FileCreate FileAction = "create"
+	FileUpdate FileAction = "update"
+	FileDelete FileAction = "delete"
+	FileMove   FileAction = "move"
+)
+
+// CommitAction represents a single file action within a commit.
+type CommitAction struct {
+	Action       FileAction
+	FilePath     string
+	Content      string
+	PreviousPath string
+}
+
+type Commit struct {
+	ID      string
+	Message string
+}
+
+// Diff represents a file diff between two revisions.
+type Diff struct {
+	OldPath     string
+	NewPath     string
+	Diff        string
+	NewFile     bool
+	RenamedFile bool
+	DeletedFile bool
+}
+
+type Config struct {
+	// URL is the root URL of repositories, such as https://gitea.com/horizon,
+	// ssh://git@git.com/horizon or a local directory like /data/gitops.
+	URL string
+	// Token is sent as a bearer token when URL is a http(s) URL.
+	Token string
+	// CacheDir is the local directory used to cache repositories.
+	CacheDir string
+	// DefaultBranch is the default branch of created repositories.
+	DefaultBranch string
+	// AuthorName and AuthorEmail are used for commits created by horizon.
+	AuthorName  string
+	AuthorEmail string
+}
+
+var _ Interface = (*helper)(nil)
+
+type helper struct {
+	root          string
+	local         bool
+	token         string
+	cacheDir      string
+	defaultBranch string
+	authorName    string
+	authorEmail   string
+
+	locks sync.Map
+}
+
+const (
+	_defaultBranch      = "master"
+	_defaultAuthorName  = "horizon"
+	_defaultAuthorEmail = "horizon@horizoncd.github.io"
+	_remote             = "origin"
+	_readme             = "README.md"
+
+	// _minAuthMajor and _minAuthMinor are the minimum version of git which reads configs from environment,
+	// the token is passed to git by them
+	_minAuthMajor = 2
+	_minAuthMinor = 31
+)
+
+var _gitVersionPattern = regexp.MustCompile(`^git version (\d+)\.(\d+)`)
+
+// New an instance of git
+func New(config Config) (Interface, error) {
+	if config.URL == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "git url cannot be empty")
+	}
+	h := &helper{
+		root:          strings.TrimSuffix(config.URL, "/"),
+		token:         config.Token,
+		cacheDir:      config.CacheDir,
+		defaultBranch: config.DefaultBranch,
+		authorName:    config.AuthorName,
+		authorEmail:   config.AuthorEmail,
+	}
+	if strings.HasPrefix(h.root, "file://") {
+		h.root = strings.TrimPrefix(h.root, "file://")
+		h.local = true
+	} else if filepath.IsAbs(h.root) {
+		h.local = true
+	}
+	if h.cacheDir == "" {
+		h.cacheDir = filepath.Join(os.TempDir(), "horizon-git-cache")
+	}
+	if h.defaultBranch == "" {
+		h.defaultBranch = _defaultBranch
+	}
+	if h.authorName == "" {
+		h.authorName = _defaultAuthorName
+	}
+	if h.authorEmail == "" {
+		h.authorEmail = _defaultAuthorEmail
+	}
+	if err := os.MkdirAll(h.cacheDir, 0755); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitInternal, err.Error())
+	}
+	if h.local {
+		if err := os.MkdirAll(h.root, 0755); err != nil {
+			return nil, perror.Wrap(herrors.ErrGitInternal, err.Error())
+		}
+	}
+	// an older git ignores the configs from environment, and the token would be dropped silently
+	if h.authEnv() != nil {
+		out, err := h.run(context.Background(), h.cacheDir, nil, "version")
+		if err != nil {
+			return nil, err
+		}
+		if err := checkAuthVersion(string(out)); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// checkAuthVersion checks that the output of git version is at least _minAuthMajor._minAuthMinor
+func checkAuthVersion(version string) error {
+	matches := _gitVersionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if matches == nil {
+		return perror.Wrapf(herrors.ErrGitInternal, "failed to parse git version: %s", version)
+	}
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	if major < _minAuthMajor || (major == _minAuthMajor && minor < _minAuthMinor) {
+		return perror.Wrapf(herrors.ErrGitInternal, "git %d.%d or later is required to authenticate by token, "+
+			"but got %s", _minAuthMajor, _minAuthMinor, strings.TrimSpace(version))
+	}
+	return nil
+}
+
+func (h *helper) CreateRepo(ctx context.Context, repo string) (err error) {
+	const op = "git: create repo"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	unlock := h.lock(repo)
+	defer unlock()
+
+	if h.local {
+		dir := h.GetRepoURL(repo)
+		if _, err := os.Stat(dir); err == nil {
+			return perror.Wrapf(herrors.ErrNameConflict, "repo %s already exists", repo)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return perror.Wrap(herrors.ErrGitInternal, err.Error())
+		}
+		if _, err := h.run(ctx, dir, nil, "init", "--bare"); err != nil {
+			return err
+		}
+		if _, err := h.run(ctx, dir, nil, "symbolic-ref", "HEAD", "refs/heads/"+h.defaultBranch); err != nil {
+			return err
+		}
+	}
+
+	// start from a clean cache, a stale one may be left by a deleted repo with the same path
+	if err := os.RemoveAll(h.cachePath(repo)); err != nil {
+		return perror.Wrap(herrors.ErrGitInternal, err.Error())
+	}
+	cache, err := h.initCache(ctx, repo)
+	if err != nil {
+		return err
+	}
+	_, err = h.commit(ctx, cache, h.defaultBranch, "", "Initial commit", []CommitAction{
+		{
+			Action:   FileCreate,
+			FilePath: _readme,
+			Content:  fmt.Sprintf("# %s\n", path.Base(repo)),
+		},
+	})
+	return err
+}
+
+func (h *helper) DeleteRepo(ctx context.Context, repo string) (err error) {
+	const op = "git: delete repo"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	unlock := h.lock(repo)
+	defer unlock()
+
+	if h.local {
+		dir := h.GetRepoURL(repo)
+		if _, err := os.Stat(dir); err != nil {
+			return herrors.NewErrNotFound(herrors.GitResource, err.Error())
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return perror.Wrap(herrors.ErrGitInternal, err.Error())
+		}
+	} else {
+		cache, err := h.sync(ctx, repo)
+		if err != nil {
+			return err
+		}
+		if err := h.deleteBranches(ctx, cache); err != nil {
+			return err
+		}
+	}
+	return h.removeCache(repo)
+}
+
+func (h *helper) MoveRepo(ctx context.Context, from, to string) (err error) {
+	const op = "git: move repo"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	unlockFrom := h.lock(from)
+	defer unlockFrom()
+	unlockTo := h.lock(to)
+	defer unlockTo()
+
+	if h.local {
+		src, dst := h.GetRepoURL(from), h.GetRepoURL(to)
+		if _, err := os.Stat(src); err != nil {
+			return herrors.NewErrNotFound(herrors.GitResource, err.Error())
+		}
+		if _, err := os.Stat(dst); err == nil {
+			return perror.Wrapf(herrors.ErrNameConflict, "repo %s already exists", to)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return perror.Wrap(herrors.ErrGitInternal, err.Error())
+		}
+		if err := os.Rename(src, dst); err != nil {
+			return perror.Wrap(herrors.ErrGitInternal, err.Error())
+		}
+	} else {
+		cache, err := h.sync(ctx, from)
+		if err != nil {
+			return err
+		}
+		if _, err := h.run(ctx, cache, nil, "push", h.GetRepoURL(to),
+			"refs/heads/*:refs/heads/*"); err != nil {
+			return err
+		}
+		if err := h.deleteBranches(ctx, cache); err != nil {
+			return err
+		}
+	}
+	if err := h.removeCache(from); err != nil {
+		return err
+	}
+	return h.removeCache(to)
+}
+
+func (h *helper) GetBranch(ctx context.Context, repo, branch string) (_ *Commit, err error) {
+	const op = "git: get branch"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	unlock := h.lock(repo)
+	defer unlock()
+
+	cache, err := h.sync(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return h.getCommit(ctx, cache, "refs/heads/"+branch)
+}
+
+func (h *helper) CreateBranch(ctx context.Context, repo, branch, fromRef string) (_ *Commit, err error) {
+	const op = "git: create branch"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	unlock := h.lock(repo)
+	defer unlock()
+
+	cache, err := h.sync(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.revParse(ctx, cache, "refs/heads/"+branch); err == nil {
+		return nil, perror.Wrapf(herrors.ErrNameConflict, "branch %s already exists", branch)
+	}
+	commit, err := h.getCommit(ctx, cache, fromRef)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.push(ctx, cache, commit.ID, branch); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+func (h *helper) GetFile(ctx context.Context, repo, ref, filepath string) (_ []byte, err error) {
+	const op = "git: get file"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	unlock := h.lock(repo)
+	defer unlock()
+
+	cache, err := h.sync(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return h.readFile(ctx, cache, ref, filepath)
+}
+
+func (h *helper) WriteFiles(ctx context.Context, repo, branch, commitMsg string,
+	startBranch *string, actions []CommitAction) (_ *Commit, err error) {
+	const op = "git: write files"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	unlock := h.lock(repo)
+	defer unlock()
+
+	cache, err := h.sync(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := h.revParse(ctx, cache, "refs/heads/"+branch)
+	if err != nil {
+		if startBranch == nil {
+			return nil, err
+		}
+		parent, err = h.revParse(ctx, cache, "refs/heads/"+*startBranch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return h.commit(ctx, cache, branch, parent, commitMsg, actions)
+}
+
+func (h *helper) Compare(ctx context.Context, repo, from, to string,
+	straight bool) (_ []*Diff, err error) {
+	const op = "git: compare"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	unlock := h.lock(repo)
+	defer unlock()
+
+	cache, err := h.sync(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	fromCommit, err := h.revParse(ctx, cache, from)
+	if err != nil {
+		return nil, err
+	}
+	toCommit, err := h.revParse(ctx, cache, to)
+	if err != nil {
+		return nil, err
+	}
+	if !straight {
+		out, err := h.run(ctx, cache, nil, "merge-base", fromCommit, toCommit)
+		if err != nil {
+			return nil, err
+		}
+		fromCommit = strings.TrimSpace(string(out))
+	}
+
+	out, err := h.run(ctx, cache, nil, "diff", "--no-color", "--no-renames",
+		"--name-status", "-z", fromCommit, toCommit)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	diffs := make([]*Diff, 0)
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, name := fields[i], fields[i+1]
+		diff := &Diff{
+			OldPath:     name,
+			NewPath:     name,
+			NewFile:     strings.HasPrefix(status, "A"),
+			DeletedFile: strings.HasPrefix(status, "D"),
+		}
+		patch, err := h.run(ctx, cache, nil, "diff", "--no-color", fromCommit, toCommit, "--", name)
+		if err != nil {
+			return nil, err
+		}
+		diff.Diff = trimPatchHeader(string(patch))
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+func (h *helper) MergeBranch(ctx context.Context, repo, source, target,
+	commitMsg string) (_ *Commit, err error) {
+	const op = "git: merge branch"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	unlock := h.lock(repo)
+	defer unlock()
+
+	cache, err := h.sync(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	sourceCommit, err := h.revParse(ctx, cache, "refs/heads/"+source)
+	if err != nil {
+		return nil, err
+	}
+	targetCommit, err := h.revParse(ctx, cache, "refs/heads/"+target)
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing to merge if source is already reachable from target
+	if _, err := h.run(ctx, cache, nil, "merge-base", "--is-ancestor",
+		sourceCommit, targetCommit); err == nil {
+		return h.getCommit(ctx, cache, targetCommit)
+	}
+
+	worktree, err := ioutil.TempDir(h.cacheDir, "worktree-")
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitInternal, err.Error())
+	}
+	defer func() {
+		if _, err := h.run(ctx, cache, nil, "worktree", "remove", "--force", worktree); err != nil {
+			log.Warningf(ctx, "failed to remove worktree %s, err: %s", worktree, err.Error())
+			_ = os.RemoveAll(worktree)
+			_, _ = h.run(ctx, cache, nil, "worktree", "prune")
+		}
+	}()
+	if _, err := h.run(ctx, cache, nil, "worktree", "add", "--detach", worktree, targetCommit); err != nil {
+		return nil, err
+	}
+	if _, err := h.run(ctx, worktree, h.authorEnv(), "merge", "--no-ff", "--no-edit",
+		"-m", commitMsg, sourceCommit); err != nil {
+		_, _ = h.run(ctx, worktree, nil, "merge", "--abort")
+		return nil, perror.Wrap(herrors.ErrGitMergeConflict, err.Error())
+	}
+	mergeCommit, err := h.revParse(ctx, worktree, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if err := h.push(ctx, cache, mergeCommit, target); err != nil {
+		return nil, err
+	}
+	return h.getCommit(ctx, cache, mergeCommit)
+}
+
+func (h *helper) GetRepoURL(repo string) string {
+	if h.local {
+		return filepath.Join(h.root, filepath.FromSlash(repo)+".git")
+	}
+	return fmt.Sprintf("%s/%s.git", h.root, repo)
+}
+
+// commit creates a commit on top of parent with actions applied and pushes it to branch.
+// The commit is created with plumbing commands, so that no worktree is needed.
+func (h *helper) commit(ctx context.Context, cache, branch, parent, commitMsg string,
+	actions []CommitAction) (*Commit, error) {
+	index, err := ioutil.TempFile(h.cacheDir, "index-")
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitInternal, err.Error())
+	}
+	_ = index.Close()
+	// git refuses to read an empty index file
+	_ = os.Remove(index.Name())
+	defer func() { _ = os.Remove(index.Name()) }()
+	env := append(h.authorEnv(), "GIT_INDEX_FILE="+index.Name())
+
+	if parent != "" {
+		if _, err := h.run(ctx, cache, env, "read-tree", parent); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := h.run(ctx, cache, env, "read-tree", "--empty"); err != nil {
+			return nil, err
+		}
+	}
+
+	exists := func(file string) (string, bool) {
+		out, err := h.run(ctx, cache, env, "ls-files", "--stage", "--", file)
+		if err != nil || len(out) == 0 {
+			return "", false
+		}
+		// output looks like: <mode> <object> <stage>\t<file>
+		parts := strings.Fields(string(out))
+		if len(parts) < 2 {
+			return "", false
+		}
+		return parts[1], true
+	}
+	// entries with mode 0 are removed from index, update-index --force-remove needs a worktree
+	remove := func(file string) error {
+		_, err := h.runWithInput(ctx, cache, env,
+			[]byte(fmt.Sprintf("0 %s\t%s\n", strings.Repeat("0", 40), file)), "update-index", "--index-info")
+		return err
+	}
+	add := func(file, content string) error {
+		out, err := h.runWithInput(ctx, cache, env, []byte(content), "hash-object", "-w", "--stdin")
+		if err != nil {
+			return err
+		}
+		_, err = h.run(ctx, cache, env, "update-index", "--add", "--cacheinfo",
+			fmt.Sprintf("100644,%s,%s", strings.TrimSpace(string(out)), file))
+		return err
+	}
+
+	for _, action := range actions {
+		_, ok := exists(action.FilePath)
+		switch action.Action {
+		case FileCreate:
+			if ok {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid,
+					"a file with this name already exists, file = %s", action.FilePath)
+			}
+			err = add(action.FilePath, action.Content)
+		case FileUpdate:
+			if !ok {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid,
+					"a file with this name doesn't exist, file = %s", action.FilePath)
+			}
+			err = add(action.FilePath, action.Content)
+		case FileDelete:
+			if !ok {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid,
+					"a file with this name doesn't exist, file = %s", action.FilePath)
+			}
+			err = remove(action.FilePath)
+		case FileMove:
+			previous, previousOK := exists(action.PreviousPath)
+			if !previousOK {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid,
+					"a file with this name doesn't exist, file = %s", action.PreviousPath)
+			}
+			if err = remove(action.PreviousPath); err != nil {
+				break
+			}
+			if action.Content != "" {
+				err = add(action.FilePath, action.Content)
+			} else {
+				_, err = h.run(ctx, cache, env, "update-index", "--add", "--cacheinfo",
+					fmt.Sprintf("100644,%s,%s", previous, action.FilePath))
+			}
+		default:
+			err = perror.Wrapf(herrors.ErrParamInvalid, "unsupported file action %s", action.Action)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := h.run(ctx, cache, env, "write-tree")
+	if err != nil {
+		return nil, err
+	}
+	tree := strings.TrimSpace(string(out))
+	args := []string{"commit-tree", tree, "-m", commitMsg}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	out, err = h.run(ctx, cache, env, args...)
+	if err != nil {
+		return nil, err
+	}
+	commitID := strings.TrimSpace(string(out))
+	if err := h.push(ctx, cache, commitID, branch); err != nil {
+		return nil, err
+	}
+	return &Commit{
+		ID:      commitID,
+		Message: commitMsg,
+	}, nil
+}
+
+// push pushes commit to branch of remote, and updates the cached branch after succeeded.
+func (h *helper) push(ctx context.Context, cache, commit, branch string) error {
+	if _, err := h.run(ctx, cache, nil, "push", _remote,
+		fmt.Sprintf("%s:refs/heads/%s", commit, branch)); err != nil {
+		return err
+	}
+	_, err := h.run(ctx, cache, nil, "update-ref", "refs/heads/"+branch, commit)
+	return err
+}
+
+func (h *helper) deleteBranches(ctx context.Context, cache string) error {
+	out, err := h.run(ctx, cache, nil, "for-each-ref", "--format=%(refname)", "refs/heads/")
+	if err != nil {
+		return err
+	}
+	refs := strings.Fields(string(out))
+	if len(refs) == 0 {
+		return nil
+	}
+	args := append([]string{"push", _remote, "--delete"}, refs...)
+	_, err = h.run(ctx, cache, nil, args...)
+	return err
+}
+
+func (h *helper) readFile(ctx context.Context, cache, ref, file string) ([]byte, error) {
+	if _, err := h.revParse(ctx, cache, ref); err != nil {
+		return nil, err
+	}
+	out, err := h.run(ctx, cache, nil, "cat-file", "blob", fmt.Sprintf("%s:%s", ref, file))
+	if err != nil {
+		return nil, herrors.NewErrNotFound(herrors.GitResource,
+			fmt.Sprintf("file %s not found in %s", file, ref))
+	}
+	// keep the same as gitlab, content of an empty file is nil
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+func (h *helper) getCommit(ctx context.Context, cache, ref string) (*Commit, error) {
+	commitID, err := h.revParse(ctx, cache, ref)
+	if err != nil {
+		return nil, err
+	}
+	out, err := h.run(ctx, cache, nil, "log", "-1", "--format=%B", commitID)
+	if err != nil {
+		return nil, err
+	}
+	return &Commit{
+		ID:      commitID,
+		Message: strings.TrimSpace(string(out)),
+	}, nil
+}
+
+func (h *helper) revParse(ctx context.Context, dir, ref string) (string, error) {
+	out, err := h.run(ctx, dir, nil, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", herrors.NewErrNotFound(herrors.GitResource, fmt.Sprintf("revision %s not found", ref))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// sync makes sure the cache of repo exists and has the latest branches of remote.
+func (h *helper) sync(ctx context.Context, repo string) (string, error) {
+	if h.local {
+		if _, err := os.Stat(h.GetRepoURL(repo)); err != nil {
+			return "", herrors.NewErrNotFound(herrors.GitResource,
+				fmt.Sprintf("repo %s not found", repo))
+		}
+	}
+	cache, err := h.initCache(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+	if _, err := h.run(ctx, cache, nil, "fetch", "--prune", "--force", _remote,
+		"refs/heads/*:refs/heads/*"); err != nil {
+		if isRemoteNotFound(err) {
+			return "", herrors.NewErrNotFound(herrors.GitResource, err.Error())
+		}
+		return "", err
+	}
+	return cache, nil
+}
+
+func (h *helper) initCache(ctx context.Context, repo string) (string, error) {
+	cache := h.cachePath(repo)
+	if _, err := os.Stat(filepath.Join(cache, "HEAD")); err == nil {
+		return cache, nil
+	}
+	if err := os.MkdirAll(cache, 0755); err != nil {
+		return "", perror.Wrap(herrors.ErrGitInternal, err.Error())
+	}
+	if _, err := h.run(ctx, cache, nil, "init", "--bare"); err != nil {
+		return "", err
+	}
+	if _, err := h.run(ctx, cache, nil, "remote", "add", _remote, h.GetRepoURL(repo)); err != nil {
+		return "", err
+	}
+	return cache, nil
+}
+
+func (h *helper) removeCache(repo string) error {
+	if err := os.RemoveAll(h.cachePath(repo)); err != nil {
+		return perror.Wrap(herrors.ErrGitInternal, err.Error())
+	}
+	return nil
+}
+
+func (h *helper) cachePath(repo string) string {
+	return filepath.Join(h.cacheDir, url.PathEscape(repo)+".git")
+}
+
+// lock serializes operations on the same repo within this process.
+func (h *helper) lock(repo string) func() {
+	value, _ := h.locks.LoadOrStore(repo, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+func (h *helper) authorEnv() []string {
+	return []string{
+		"GIT_AUTHOR_NAME=" + h.authorName,
+		"GIT_AUTHOR_EMAIL=" + h.authorEmail,
+		"GIT_COMMITTER_NAME=" + h.authorName,
+		"GIT_COMMITTER_EMAIL=" + h.authorEmail,
+	}
+}
+
+// authEnv passes the token by environment variables rather than arguments,
+// so that it can't be seen by other processes, it requires git 2.31 or later which is checked by New.
+func (h *helper) authEnv() []string {
+	if h.token == "" || h.local {
+		return nil
+	}
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Bearer " + h.token,
+	}
+}
+
+func (h *helper) run(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+	return h.runWithInput(ctx, dir, env, nil, args...)
+}
+
+func (h *helper) runWithInput(ctx context.Context, dir string, env []string,
+	input []byte, args ...string) ([]byte, error) {
+	subCommand := args[0]
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+	cmd.Env = append(cmd.Env, h.authEnv()...)
+	cmd.Env = append(cmd.Env, env...)
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, perror.Wrapf(herrors.ErrGitInternal, "git %s: %s, %s",
+			subCommand, err.Error(), strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+func isRemoteNotFound(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"not found", "does not exist", "does not appear to be a git repository"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// trimPatchHeader removes header lines of a patch, keeps hunks only
+func trimPatchHeader(patch string) string {
+	idx := strings.Index(patch, "@@")
+	if idx < 0 {
+		return ""
+	}
+	return patch[idx:]
+}
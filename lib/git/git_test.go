@@ -0,0 +1,186 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGit(t *testing.T) Interface {
+	root, err := ioutil.TempDir("", "horizon-git-root")
+	assert.Nil(t, err)
+	cache, err := ioutil.TempDir("", "horizon-git-cache")
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(root)
+		_ = os.RemoveAll(cache)
+	})
+
+	g, err := New(Config{
+		URL:      root,
+		CacheDir: cache,
+	})
+	assert.Nil(t, err)
+	return g
+}
+
+func isNotFound(err error) bool {
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	return ok
+}
+
+func TestRepo(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGit(t)
+
+	repo := "clusters/app/cluster"
+	_, err := g.GetFile(ctx, repo, "master", "README.md")
+	assert.True(t, isNotFound(err))
+
+	assert.Nil(t, g.CreateRepo(ctx, repo))
+	assert.NotNil(t, g.CreateRepo(ctx, repo))
+
+	readme, err := g.GetFile(ctx, repo, "master", "README.md")
+	assert.Nil(t, err)
+	assert.Equal(t, "# cluster\n", string(readme))
+
+	master, err := g.GetBranch(ctx, repo, "master")
+	assert.Nil(t, err)
+	assert.Equal(t, "Initial commit", master.Message)
+
+	// write files
+	_, err = g.CreateBranch(ctx, repo, "gitops", "master")
+	assert.Nil(t, err)
+	_, err = g.CreateBranch(ctx, repo, "gitops", "master")
+	assert.NotNil(t, err)
+
+	commit, err := g.WriteFiles(ctx, repo, "gitops", "create files", nil, []CommitAction{
+		{Action: FileCreate, FilePath: "a.yaml", Content: "a: 1\n"},
+		{Action: FileCreate, FilePath: "b.yaml", Content: "b: 1\n"},
+		{Action: FileDelete, FilePath: "README.md"},
+	})
+	assert.Nil(t, err)
+	_, err = g.WriteFiles(ctx, repo, "gitops", "create files", nil, []CommitAction{
+		{Action: FileCreate, FilePath: "a.yaml", Content: "a: 1\n"},
+	})
+	assert.NotNil(t, err)
+	_, err = g.WriteFiles(ctx, repo, "gitops", "update files", nil, []CommitAction{
+		{Action: FileUpdate, FilePath: "c.yaml", Content: "c: 1\n"},
+	})
+	assert.NotNil(t, err)
+
+	_, err = g.WriteFiles(ctx, repo, "gitops", "update files", nil, []CommitAction{
+		{Action: FileUpdate, FilePath: "a.yaml", Content: "a: 2\n"},
+		{Action: FileMove, FilePath: "c.yaml", PreviousPath: "b.yaml"},
+	})
+	assert.Nil(t, err)
+
+	content, err := g.GetFile(ctx, repo, commit.ID, "a.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, "a: 1\n", string(content))
+	content, err = g.GetFile(ctx, repo, "gitops", "a.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, "a: 2\n", string(content))
+	content, err = g.GetFile(ctx, repo, "gitops", "c.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, "b: 1\n", string(content))
+	_, err = g.GetFile(ctx, repo, "gitops", "b.yaml")
+	assert.True(t, isNotFound(err))
+
+	// compare
+	diffs, err := g.Compare(ctx, repo, "master", "gitops", false)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(diffs))
+	for _, diff := range diffs {
+		switch diff.NewPath {
+		case "README.md":
+			assert.True(t, diff.DeletedFile)
+		case "a.yaml":
+			assert.True(t, diff.NewFile)
+			assert.Contains(t, diff.Diff, "+a: 2")
+		case "c.yaml":
+			assert.True(t, diff.NewFile)
+		default:
+			t.Errorf("unexpected diff %s", diff.NewPath)
+		}
+	}
+	diffs, err = g.Compare(ctx, repo, "gitops", commit.ID, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(diffs))
+
+	// merge with a diverged target
+	_, err = g.WriteFiles(ctx, repo, "master", "restart", nil, []CommitAction{
+		{Action: FileCreate, FilePath: "restart.yaml", Content: "time: now\n"},
+	})
+	assert.Nil(t, err)
+	merged, err := g.MergeBranch(ctx, repo, "gitops", "master", "merge gitops into master")
+	assert.Nil(t, err)
+	master, err = g.GetBranch(ctx, repo, "master")
+	assert.Nil(t, err)
+	assert.Equal(t, merged.ID, master.ID)
+	for _, file := range []string{"a.yaml", "c.yaml", "restart.yaml"} {
+		_, err = g.GetFile(ctx, repo, "master", file)
+		assert.Nil(t, err)
+	}
+
+	// merge again is a no-op
+	mergedAgain, err := g.MergeBranch(ctx, repo, "gitops", "master", "merge gitops into master")
+	assert.Nil(t, err)
+	assert.Equal(t, merged.ID, mergedAgain.ID)
+
+	// move and delete
+	recycled := "recycling-clusters/app/cluster-1"
+	assert.Nil(t, g.MoveRepo(ctx, repo, recycled))
+	_, err = g.GetBranch(ctx, repo, "master")
+	assert.True(t, isNotFound(err))
+	content, err = g.GetFile(ctx, recycled, "gitops", "a.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, "a: 2\n", string(content))
+
+	assert.Nil(t, g.DeleteRepo(ctx, recycled))
+	_, err = g.GetBranch(ctx, recycled, "master")
+	assert.True(t, isNotFound(err))
+	assert.True(t, isNotFound(g.DeleteRepo(ctx, recycled)))
+}
+
+func TestAuthEnv(t *testing.T) {
+	ctx := context.Background()
+	h := &helper{root: "https://git.com/horizon", token: "secret"}
+	out, err := h.run(ctx, os.TempDir(), nil, "config", "--get", "http.extraHeader")
+	assert.Nil(t, err)
+	assert.Equal(t, "Authorization: Bearer secret\n", string(out))
+
+	h = &helper{root: "/data/gitops", local: true, token: "secret"}
+	assert.Nil(t, h.authEnv())
+
+	_, err = New(Config{URL: "https://git.com/horizon", Token: "secret", CacheDir: t.TempDir()})
+	assert.Nil(t, err)
+}
+
+func TestCheckAuthVersion(t *testing.T) {
+	assert.Nil(t, checkAuthVersion("git version 2.31.0\n"))
+	assert.Nil(t, checkAuthVersion("git version 2.39.5 (Apple Git-143)"))
+	assert.Nil(t, checkAuthVersion("git version 3.0.0"))
+	assert.NotNil(t, checkAuthVersion("git version 2.20.4\n"))
+	assert.NotNil(t, checkAuthVersion("git version 1.8.3.1"))
+	assert.NotNil(t, checkAuthVersion("unknown"))
+}
@@ -39,6 +39,8 @@ type Interface interface {
 	// Ref: https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListObjects.html
 	ListObjects(ctx context.Context, prefix string, maxKeys int64) ([]*awss3.Object, error)
 	DeleteObjects(ctx context.Context, prefix string) error
+	// DeleteObject deletes the object of path, it's not an error if the object does not exist
+	DeleteObject(ctx context.Context, path string) error
 	GetSignedObjectURL(path string, expire time.Duration) (string, error)
 	GetBucket(ctx context.Context) string
 }
@@ -218,6 +220,14 @@ func (d *Driver) DeleteObjects(ctx context.Context, prefix string) error {
 	}
 }
 
+func (d *Driver) DeleteObject(ctx context.Context, path string) error {
+	_, err := d.S3.DeleteObjectWithContext(ctx, &awss3.DeleteObjectInput{
+		Bucket: aws.String(d.Bucket),
+		Key:    aws.String(path),
+	})
+	return err
+}
+
 func (d *Driver) GetBucket(ctx context.Context) string {
 	return d.Bucket
 }
@@ -101,11 +101,24 @@ func Test(t *testing.T) {
 	}
 	assert.Equal(t, content, string(res))
 
+	if err := d.DeleteObject(ctx, "pr-log/20210714/2"); err != nil {
+		t.Fatal(err)
+	}
+	l, err := d.ListObjects(ctx, "pr-log/20210714", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(l))
+	// deleting an object which does not exist is ok
+	if err := d.DeleteObject(ctx, "pr-log/20210714/2"); err != nil {
+		t.Fatal(err)
+	}
+
 	if err := d.DeleteObjects(ctx, "pr-log/20210714"); err != nil {
 		t.Fatal(err)
 	}
 
-	l, err := d.ListObjects(ctx, "pr-log/20210714", 10)
+	l, err = d.ListObjects(ctx, "pr-log/20210714", 10)
 	if err != nil {
 		t.Fatal(err)
 	}
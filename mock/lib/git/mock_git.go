@@ -0,0 +1,182 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: git.go
+
+// Package mock_git is a generated GoMock package.
+package mock_git
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	git "github.com/horizoncd/horizon/lib/git"
+)
+
+// MockInterface is a mock of Interface interface.
+type MockInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInterfaceMockRecorder
+}
+
+// MockInterfaceMockRecorder is the mock recorder for MockInterface.
+type MockInterfaceMockRecorder struct {
+	mock *MockInterface
+}
+
+// NewMockInterface creates a new mock instance.
+func NewMockInterface(ctrl *gomock.Controller) *MockInterface {
+	mock := &MockInterface{ctrl: ctrl}
+	mock.recorder = &MockInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Compare mocks base method.
+func (m *MockInterface) Compare(ctx context.Context, repo, from, to string, straight bool) ([]*git.Diff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compare", ctx, repo, from, to, straight)
+	ret0, _ := ret[0].([]*git.Diff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Compare indicates an expected call of Compare.
+func (mr *MockInterfaceMockRecorder) Compare(ctx, repo, from, to, straight interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compare", reflect.TypeOf((*MockInterface)(nil).Compare), ctx, repo, from, to, straight)
+}
+
+// CreateBranch mocks base method.
+func (m *MockInterface) CreateBranch(ctx context.Context, repo, branch, fromRef string) (*git.Commit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBranch", ctx, repo, branch, fromRef)
+	ret0, _ := ret[0].(*git.Commit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBranch indicates an expected call of CreateBranch.
+func (mr *MockInterfaceMockRecorder) CreateBranch(ctx, repo, branch, fromRef interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBranch", reflect.TypeOf((*MockInterface)(nil).CreateBranch), ctx, repo, branch, fromRef)
+}
+
+// CreateRepo mocks base method.
+func (m *MockInterface) CreateRepo(ctx context.Context, repo string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRepo", ctx, repo)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRepo indicates an expected call of CreateRepo.
+func (mr *MockInterfaceMockRecorder) CreateRepo(ctx, repo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRepo", reflect.TypeOf((*MockInterface)(nil).CreateRepo), ctx, repo)
+}
+
+// DeleteRepo mocks base method.
+func (m *MockInterface) DeleteRepo(ctx context.Context, repo string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRepo", ctx, repo)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRepo indicates an expected call of DeleteRepo.
+func (mr *MockInterfaceMockRecorder) DeleteRepo(ctx, repo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRepo", reflect.TypeOf((*MockInterface)(nil).DeleteRepo), ctx, repo)
+}
+
+// GetBranch mocks base method.
+func (m *MockInterface) GetBranch(ctx context.Context, repo, branch string) (*git.Commit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBranch", ctx, repo, branch)
+	ret0, _ := ret[0].(*git.Commit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBranch indicates an expected call of GetBranch.
+func (mr *MockInterfaceMockRecorder) GetBranch(ctx, repo, branch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBranch", reflect.TypeOf((*MockInterface)(nil).GetBranch), ctx, repo, branch)
+}
+
+// GetFile mocks base method.
+func (m *MockInterface) GetFile(ctx context.Context, repo, ref, filepath string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFile", ctx, repo, ref, filepath)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFile indicates an expected call of GetFile.
+func (mr *MockInterfaceMockRecorder) GetFile(ctx, repo, ref, filepath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFile", reflect.TypeOf((*MockInterface)(nil).GetFile), ctx, repo, ref, filepath)
+}
+
+// GetRepoURL mocks base method.
+func (m *MockInterface) GetRepoURL(repo string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRepoURL", repo)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetRepoURL indicates an expected call of GetRepoURL.
+func (mr *MockInterfaceMockRecorder) GetRepoURL(repo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepoURL", reflect.TypeOf((*MockInterface)(nil).GetRepoURL), repo)
+}
+
+// MergeBranch mocks base method.
+func (m *MockInterface) MergeBranch(ctx context.Context, repo, source, target, commitMsg string) (*git.Commit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeBranch", ctx, repo, source, target, commitMsg)
+	ret0, _ := ret[0].(*git.Commit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeBranch indicates an expected call of MergeBranch.
+func (mr *MockInterfaceMockRecorder) MergeBranch(ctx, repo, source, target, commitMsg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeBranch", reflect.TypeOf((*MockInterface)(nil).MergeBranch), ctx, repo, source, target, commitMsg)
+}
+
+// MoveRepo mocks base method.
+func (m *MockInterface) MoveRepo(ctx context.Context, from, to string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveRepo", ctx, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveRepo indicates an expected call of MoveRepo.
+func (mr *MockInterfaceMockRecorder) MoveRepo(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveRepo", reflect.TypeOf((*MockInterface)(nil).MoveRepo), ctx, from, to)
+}
+
+// WriteFiles mocks base method.
+func (m *MockInterface) WriteFiles(ctx context.Context, repo, branch, commitMsg string, startBranch *string, actions []git.CommitAction) (*git.Commit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteFiles", ctx, repo, branch, commitMsg, startBranch, actions)
+	ret0, _ := ret[0].(*git.Commit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteFiles indicates an expected call of WriteFiles.
+func (mr *MockInterfaceMockRecorder) WriteFiles(ctx, repo, branch, commitMsg, startBranch, actions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteFiles", reflect.TypeOf((*MockInterface)(nil).WriteFiles), ctx, repo, branch, commitMsg, startBranch, actions)
+}
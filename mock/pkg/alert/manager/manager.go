@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: manager.go
+
+// Package mock_manager is a generated GoMock package.
+package mock_manager
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/horizoncd/horizon/pkg/alert/models"
+)
+
+// MockManager is a mock of Manager interface.
+type MockManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockManagerMockRecorder
+}
+
+// MockManagerMockRecorder is the mock recorder for MockManager.
+type MockManagerMockRecorder struct {
+	mock *MockManager
+}
+
+// NewMockManager creates a new mock instance.
+func NewMockManager(ctrl *gomock.Controller) *MockManager {
+	mock := &MockManager{ctrl: ctrl}
+	mock.recorder = &MockManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockManager) EXPECT() *MockManagerMockRecorder {
+	return m.recorder
+}
+
+// ListByClusterID mocks base method.
+func (m *MockManager) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.AlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByClusterID", ctx, clusterID)
+	ret0, _ := ret[0].([]*models.AlertRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByClusterID indicates an expected call of ListByClusterID.
+func (mr *MockManagerMockRecorder) ListByClusterID(ctx, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByClusterID", reflect.TypeOf((*MockManager)(nil).ListByClusterID), ctx, clusterID)
+}
+
+// UpdateByClusterID mocks base method.
+func (m *MockManager) UpdateByClusterID(ctx context.Context, clusterID uint, rules []*models.AlertRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateByClusterID", ctx, clusterID, rules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateByClusterID indicates an expected call of UpdateByClusterID.
+func (mr *MockManagerMockRecorder) UpdateByClusterID(ctx, clusterID, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByClusterID", reflect.TypeOf((*MockManager)(nil).UpdateByClusterID), ctx, clusterID, rules)
+}
@@ -170,6 +170,21 @@ func (mr *MockManagerMockRecorder) GetByNameFuzzilyIncludeSoftDelete(ctx, name i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByNameFuzzilyIncludeSoftDelete", reflect.TypeOf((*MockManager)(nil).GetByNameFuzzilyIncludeSoftDelete), ctx, name)
 }
 
+// GetByVisibilityLevel mocks base method.
+func (m *MockManager) GetByVisibilityLevel(ctx context.Context, visibilityLevel string) ([]*models.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByVisibilityLevel", ctx, visibilityLevel)
+	ret0, _ := ret[0].([]*models.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByVisibilityLevel indicates an expected call of GetByVisibilityLevel.
+func (mr *MockManagerMockRecorder) GetByVisibilityLevel(ctx, visibilityLevel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByVisibilityLevel", reflect.TypeOf((*MockManager)(nil).GetByVisibilityLevel), ctx, visibilityLevel)
+}
+
 // List mocks base method.
 func (m *MockManager) List(ctx context.Context, groupIDs []uint, query *q.Query) (int, []*models.Application, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: manager.go
+
+// Package mock_manager is a generated GoMock package.
+package mock_manager
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/horizoncd/horizon/pkg/approval/models"
+)
+
+// MockManager is a mock of Manager interface.
+type MockManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockManagerMockRecorder
+}
+
+// MockManagerMockRecorder is the mock recorder for MockManager.
+type MockManagerMockRecorder struct {
+	mock *MockManager
+}
+
+// NewMockManager creates a new mock instance.
+func NewMockManager(ctrl *gomock.Controller) *MockManager {
+	mock := &MockManager{ctrl: ctrl}
+	mock.recorder = &MockManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockManager) EXPECT() *MockManagerMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockManager) Create(ctx context.Context, approval *models.Approval) (*models.Approval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, approval)
+	ret0, _ := ret[0].(*models.Approval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockManagerMockRecorder) Create(ctx, approval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockManager)(nil).Create), ctx, approval)
+}
+
+// GetByPipelinerunID mocks base method.
+func (m *MockManager) GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.Approval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByPipelinerunID", ctx, pipelinerunID)
+	ret0, _ := ret[0].(*models.Approval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByPipelinerunID indicates an expected call of GetByPipelinerunID.
+func (mr *MockManagerMockRecorder) GetByPipelinerunID(ctx, pipelinerunID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPipelinerunID", reflect.TypeOf((*MockManager)(nil).GetByPipelinerunID), ctx, pipelinerunID)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/horizoncd/horizon/pkg/cluster/models"
+	models0 "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CheckApprover mocks base method.
+func (m *MockService) CheckApprover(ctx context.Context, cluster *models.Cluster, pipelinerun *models0.Pipelinerun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckApprover", ctx, cluster, pipelinerun)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckApprover indicates an expected call of CheckApprover.
+func (mr *MockServiceMockRecorder) CheckApprover(ctx, cluster, pipelinerun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckApprover", reflect.TypeOf((*MockService)(nil).CheckApprover), ctx, cluster, pipelinerun)
+}
+
+// RequireApproval mocks base method.
+func (m *MockService) RequireApproval(ctx context.Context, cluster *models.Cluster) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequireApproval", ctx, cluster)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequireApproval indicates an expected call of RequireApproval.
+func (mr *MockServiceMockRecorder) RequireApproval(ctx, cluster interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequireApproval", reflect.TypeOf((*MockService)(nil).RequireApproval), ctx, cluster)
+}
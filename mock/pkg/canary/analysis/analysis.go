@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: analysis.go
+
+// Package mock_analysis is a generated GoMock package.
+package mock_analysis
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	analysis "github.com/horizoncd/horizon/pkg/canary/analysis"
+	models "github.com/horizoncd/horizon/pkg/canary/models"
+)
+
+// MockAnalyzer is a mock of Analyzer interface.
+type MockAnalyzer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnalyzerMockRecorder
+}
+
+// MockAnalyzerMockRecorder is the mock recorder for MockAnalyzer.
+type MockAnalyzerMockRecorder struct {
+	mock *MockAnalyzer
+}
+
+// NewMockAnalyzer creates a new mock instance.
+func NewMockAnalyzer(ctrl *gomock.Controller) *MockAnalyzer {
+	mock := &MockAnalyzer{ctrl: ctrl}
+	mock.recorder = &MockAnalyzerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnalyzer) EXPECT() *MockAnalyzerMockRecorder {
+	return m.recorder
+}
+
+// Analyze mocks base method.
+func (m *MockAnalyzer) Analyze(ctx context.Context, prometheusURL string, rules []*models.CanaryRule, vars *analysis.QueryVars) (*analysis.Verdict, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Analyze", ctx, prometheusURL, rules, vars)
+	ret0, _ := ret[0].(*analysis.Verdict)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Analyze indicates an expected call of Analyze.
+func (mr *MockAnalyzerMockRecorder) Analyze(ctx, prometheusURL, rules, vars interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Analyze", reflect.TypeOf((*MockAnalyzer)(nil).Analyze), ctx, prometheusURL, rules, vars)
+}
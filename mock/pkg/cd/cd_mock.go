@@ -136,3 +136,33 @@ func (mr *MockCDMockRecorder) GetStep(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStep", reflect.TypeOf((*MockCD)(nil).GetStep), ctx, params)
 }
+
+// ListCronJobRuns mocks base method.
+func (m *MockCD) ListCronJobRuns(ctx context.Context, params *cd.CronJobParams) ([]*cd.CronJobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCronJobRuns", ctx, params)
+	ret0, _ := ret[0].([]*cd.CronJobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCronJobRuns indicates an expected call of ListCronJobRuns.
+func (mr *MockCDMockRecorder) ListCronJobRuns(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCronJobRuns", reflect.TypeOf((*MockCD)(nil).ListCronJobRuns), ctx, params)
+}
+
+// TriggerCronJob mocks base method.
+func (m *MockCD) TriggerCronJob(ctx context.Context, params *cd.CronJobParams) (*cd.CronJobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TriggerCronJob", ctx, params)
+	ret0, _ := ret[0].(*cd.CronJobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TriggerCronJob indicates an expected call of TriggerCronJob.
+func (mr *MockCDMockRecorder) TriggerCronJob(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TriggerCronJob", reflect.TypeOf((*MockCD)(nil).TriggerCronJob), ctx, params)
+}
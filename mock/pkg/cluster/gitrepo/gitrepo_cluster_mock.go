@@ -199,6 +199,21 @@ func (mr *MockClusterGitRepoMockRecorder) GetPipelineOutput(ctx, application, cl
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPipelineOutput", reflect.TypeOf((*MockClusterGitRepo)(nil).GetPipelineOutput), ctx, application, cluster, template)
 }
 
+// GetRenderFiles mocks base method.
+func (m *MockClusterGitRepo) GetRenderFiles(ctx context.Context, application, cluster, revision string) (*gitrepo.RenderFiles, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRenderFiles", ctx, application, cluster, revision)
+	ret0, _ := ret[0].(*gitrepo.RenderFiles)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRenderFiles indicates an expected call of GetRenderFiles.
+func (mr *MockClusterGitRepoMockRecorder) GetRenderFiles(ctx, application, cluster, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRenderFiles", reflect.TypeOf((*MockClusterGitRepo)(nil).GetRenderFiles), ctx, application, cluster, revision)
+}
+
 // GetRepoInfo mocks base method.
 func (m *MockClusterGitRepo) GetRepoInfo(ctx context.Context, application, cluster string) *gitrepo.RepoInfo {
 	m.ctrl.T.Helper()
@@ -272,6 +287,20 @@ func (mr *MockClusterGitRepoMockRecorder) Rollback(ctx, application, cluster, co
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockClusterGitRepo)(nil).Rollback), ctx, application, cluster, commit)
 }
 
+// UpdateAlertRules mocks base method.
+func (m *MockClusterGitRepo) UpdateAlertRules(ctx context.Context, application, cluster string, manifests []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAlertRules", ctx, application, cluster, manifests)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAlertRules indicates an expected call of UpdateAlertRules.
+func (mr *MockClusterGitRepoMockRecorder) UpdateAlertRules(ctx, application, cluster, manifests interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAlertRules", reflect.TypeOf((*MockClusterGitRepo)(nil).UpdateAlertRules), ctx, application, cluster, manifests)
+}
+
 // UpdateCluster mocks base method.
 func (m *MockClusterGitRepo) UpdateCluster(ctx context.Context, params *gitrepo.UpdateClusterParams) error {
 	m.ctrl.T.Helper()
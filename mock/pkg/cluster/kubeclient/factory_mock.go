@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: factory.go
+
+// Package mock_kubeclient is a generated GoMock package.
+package mock_kubeclient
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	kube "github.com/horizoncd/horizon/pkg/util/kube"
+	rest "k8s.io/client-go/rest"
+)
+
+// MockFactory is a mock of Factory interface.
+type MockFactory struct {
+	ctrl     *gomock.Controller
+	recorder *MockFactoryMockRecorder
+}
+
+// MockFactoryMockRecorder is the mock recorder for MockFactory.
+type MockFactoryMockRecorder struct {
+	mock *MockFactory
+}
+
+// NewMockFactory creates a new mock instance.
+func NewMockFactory(ctrl *gomock.Controller) *MockFactory {
+	mock := &MockFactory{ctrl: ctrl}
+	mock.recorder = &MockFactoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFactory) EXPECT() *MockFactoryMockRecorder {
+	return m.recorder
+}
+
+// GetByK8SServer mocks base method.
+func (m *MockFactory) GetByK8SServer(server, certificate string) (*rest.Config, *kube.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByK8SServer", server, certificate)
+	ret0, _ := ret[0].(*rest.Config)
+	ret1, _ := ret[1].(*kube.Client)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetByK8SServer indicates an expected call of GetByK8SServer.
+func (mr *MockFactoryMockRecorder) GetByK8SServer(server, certificate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByK8SServer", reflect.TypeOf((*MockFactory)(nil).GetByK8SServer), server, certificate)
+}
@@ -7,8 +7,10 @@ package mock_registry
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	registry "github.com/horizoncd/horizon/pkg/cluster/registry"
 )
 
 // MockRegistry is a mock of Registry interface.
@@ -47,3 +49,33 @@ func (mr *MockRegistryMockRecorder) DeleteImage(ctx, appName, clusterName interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImage", reflect.TypeOf((*MockRegistry)(nil).DeleteImage), ctx, appName, clusterName)
 }
+
+// DeleteTagsOlderThan mocks base method.
+func (m *MockRegistry) DeleteTagsOlderThan(ctx context.Context, appName, clusterName string, before time.Time, inUse []string) ([]*registry.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTagsOlderThan", ctx, appName, clusterName, before, inUse)
+	ret0, _ := ret[0].([]*registry.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTagsOlderThan indicates an expected call of DeleteTagsOlderThan.
+func (mr *MockRegistryMockRecorder) DeleteTagsOlderThan(ctx, appName, clusterName, before, inUse interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTagsOlderThan", reflect.TypeOf((*MockRegistry)(nil).DeleteTagsOlderThan), ctx, appName, clusterName, before, inUse)
+}
+
+// ListImageTags mocks base method.
+func (m *MockRegistry) ListImageTags(ctx context.Context, appName, clusterName string) ([]*registry.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListImageTags", ctx, appName, clusterName)
+	ret0, _ := ret[0].([]*registry.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListImageTags indicates an expected call of ListImageTags.
+func (mr *MockRegistryMockRecorder) ListImageTags(ctx, appName, clusterName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListImageTags", reflect.TypeOf((*MockRegistry)(nil).ListImageTags), ctx, appName, clusterName)
+}
@@ -7,6 +7,7 @@ package mock_collector
 import (
 	context "context"
 	reflect "reflect"
+	regexp "regexp"
 
 	gomock "github.com/golang/mock/gomock"
 	collector "github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
@@ -53,6 +54,20 @@ func (mr *MockInterfaceMockRecorder) Collect(ctx, pr, horizonMetaData interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Collect", reflect.TypeOf((*MockInterface)(nil).Collect), ctx, pr, horizonMetaData)
 }
 
+// DeleteCollected mocks base method.
+func (m *MockInterface) DeleteCollected(ctx context.Context, pr *models.Pipelinerun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollected", ctx, pr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCollected indicates an expected call of DeleteCollected.
+func (mr *MockInterfaceMockRecorder) DeleteCollected(ctx, pr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollected", reflect.TypeOf((*MockInterface)(nil).DeleteCollected), ctx, pr)
+}
+
 // GetPipelineRun mocks base method.
 func (m *MockInterface) GetPipelineRun(ctx context.Context, pr *models.Pipelinerun) (*v1beta1.PipelineRun, error) {
 	m.ctrl.T.Helper()
@@ -97,3 +112,18 @@ func (mr *MockInterfaceMockRecorder) GetPipelineRunObject(ctx, object interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPipelineRunObject", reflect.TypeOf((*MockInterface)(nil).GetPipelineRunObject), ctx, object)
 }
+
+// SearchPipelineRunLog mocks base method.
+func (m *MockInterface) SearchPipelineRunLog(ctx context.Context, pr *models.Pipelinerun, pattern *regexp.Regexp) ([]*collector.LogMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPipelineRunLog", ctx, pr, pattern)
+	ret0, _ := ret[0].([]*collector.LogMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPipelineRunLog indicates an expected call of SearchPipelineRunLog.
+func (mr *MockInterfaceMockRecorder) SearchPipelineRunLog(ctx, pr, pattern interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPipelineRunLog", reflect.TypeOf((*MockInterface)(nil).SearchPipelineRunLog), ctx, pr, pattern)
+}
@@ -8,34 +8,50 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	s3 "github.com/horizoncd/horizon/lib/s3"
 	tekton "github.com/horizoncd/horizon/pkg/cluster/tekton"
 	collector "github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
 )
 
-// MockFactory is a mock of Factory interface
+// MockFactory is a mock of Factory interface.
 type MockFactory struct {
 	ctrl     *gomock.Controller
 	recorder *MockFactoryMockRecorder
 }
 
-// MockFactoryMockRecorder is the mock recorder for MockFactory
+// MockFactoryMockRecorder is the mock recorder for MockFactory.
 type MockFactoryMockRecorder struct {
 	mock *MockFactory
 }
 
-// NewMockFactory creates a new mock instance
+// NewMockFactory creates a new mock instance.
 func NewMockFactory(ctrl *gomock.Controller) *MockFactory {
 	mock := &MockFactory{ctrl: ctrl}
 	mock.recorder = &MockFactoryMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockFactory) EXPECT() *MockFactoryMockRecorder {
 	return m.recorder
 }
 
-// GetTekton mocks base method
+// GetLogStorage mocks base method.
+func (m *MockFactory) GetLogStorage(environment string) (s3.Interface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLogStorage", environment)
+	ret0, _ := ret[0].(s3.Interface)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLogStorage indicates an expected call of GetLogStorage.
+func (mr *MockFactoryMockRecorder) GetLogStorage(environment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogStorage", reflect.TypeOf((*MockFactory)(nil).GetLogStorage), environment)
+}
+
+// GetTekton mocks base method.
 func (m *MockFactory) GetTekton(environment string) (tekton.Interface, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetTekton", environment)
@@ -44,13 +60,13 @@ func (m *MockFactory) GetTekton(environment string) (tekton.Interface, error) {
 	return ret0, ret1
 }
 
-// GetTekton indicates an expected call of GetTekton
+// GetTekton indicates an expected call of GetTekton.
 func (mr *MockFactoryMockRecorder) GetTekton(environment interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTekton", reflect.TypeOf((*MockFactory)(nil).GetTekton), environment)
 }
 
-// GetTektonCollector mocks base method
+// GetTektonCollector mocks base method.
 func (m *MockFactory) GetTektonCollector(environment string) (collector.Interface, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetTektonCollector", environment)
@@ -59,7 +75,7 @@ func (m *MockFactory) GetTektonCollector(environment string) (collector.Interfac
 	return ret0, ret1
 }
 
-// GetTektonCollector indicates an expected call of GetTektonCollector
+// GetTektonCollector indicates an expected call of GetTektonCollector.
 func (mr *MockFactoryMockRecorder) GetTektonCollector(environment interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTektonCollector", reflect.TypeOf((*MockFactory)(nil).GetTektonCollector), environment)
@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cost.go
+
+// Package mock_cost is a generated GoMock package.
+package mock_cost
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	cost "github.com/horizoncd/horizon/pkg/cost"
+	models "github.com/horizoncd/horizon/pkg/region/models"
+)
+
+// MockReporter is a mock of Reporter interface.
+type MockReporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockReporterMockRecorder
+}
+
+// MockReporterMockRecorder is the mock recorder for MockReporter.
+type MockReporterMockRecorder struct {
+	mock *MockReporter
+}
+
+// NewMockReporter creates a new mock instance.
+func NewMockReporter(ctrl *gomock.Controller) *MockReporter {
+	mock := &MockReporter{ctrl: ctrl}
+	mock.recorder = &MockReporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReporter) EXPECT() *MockReporterMockRecorder {
+	return m.recorder
+}
+
+// Currency mocks base method.
+func (m *MockReporter) Currency() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Currency")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Currency indicates an expected call of Currency.
+func (mr *MockReporterMockRecorder) Currency() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Currency", reflect.TypeOf((*MockReporter)(nil).Currency))
+}
+
+// GetUsages mocks base method.
+func (m *MockReporter) GetUsages(ctx context.Context, region *models.RegionEntity, clusters []string, start, end time.Time) (map[string]*cost.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsages", ctx, region, clusters, start, end)
+	ret0, _ := ret[0].(map[string]*cost.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsages indicates an expected call of GetUsages.
+func (mr *MockReporterMockRecorder) GetUsages(ctx, region, clusters, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsages", reflect.TypeOf((*MockReporter)(nil).GetUsages), ctx, region, clusters, start, end)
+}
+
+// Sum mocks base method.
+func (m *MockReporter) Sum(usages ...*cost.Usage) *cost.Usage {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range usages {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Sum", varargs...)
+	ret0, _ := ret[0].(*cost.Usage)
+	return ret0
+}
+
+// Sum indicates an expected call of Sum.
+func (mr *MockReporterMockRecorder) Sum(usages ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sum", reflect.TypeOf((*MockReporter)(nil).Sum), usages...)
+}
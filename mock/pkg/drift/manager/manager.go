@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: manager.go
+
+// Package mock_manager is a generated GoMock package.
+package mock_manager
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/horizoncd/horizon/pkg/drift/models"
+)
+
+// MockManager is a mock of Manager interface.
+type MockManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockManagerMockRecorder
+}
+
+// MockManagerMockRecorder is the mock recorder for MockManager.
+type MockManagerMockRecorder struct {
+	mock *MockManager
+}
+
+// NewMockManager creates a new mock instance.
+func NewMockManager(ctrl *gomock.Controller) *MockManager {
+	mock := &MockManager{ctrl: ctrl}
+	mock.recorder = &MockManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockManager) EXPECT() *MockManagerMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockManager) Create(ctx context.Context, drift *models.ClusterDrift) (*models.ClusterDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, drift)
+	ret0, _ := ret[0].(*models.ClusterDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockManagerMockRecorder) Create(ctx, drift interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockManager)(nil).Create), ctx, drift)
+}
+
+// GetUnresolvedByClusterID mocks base method.
+func (m *MockManager) GetUnresolvedByClusterID(ctx context.Context, clusterID uint) (*models.ClusterDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnresolvedByClusterID", ctx, clusterID)
+	ret0, _ := ret[0].(*models.ClusterDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnresolvedByClusterID indicates an expected call of GetUnresolvedByClusterID.
+func (mr *MockManagerMockRecorder) GetUnresolvedByClusterID(ctx, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnresolvedByClusterID", reflect.TypeOf((*MockManager)(nil).GetUnresolvedByClusterID), ctx, clusterID)
+}
+
+// ListByClusterID mocks base method.
+func (m *MockManager) ListByClusterID(ctx context.Context, clusterID uint, limit int) ([]*models.ClusterDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByClusterID", ctx, clusterID, limit)
+	ret0, _ := ret[0].([]*models.ClusterDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByClusterID indicates an expected call of ListByClusterID.
+func (mr *MockManagerMockRecorder) ListByClusterID(ctx, clusterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByClusterID", reflect.TypeOf((*MockManager)(nil).ListByClusterID), ctx, clusterID, limit)
+}
+
+// Resolve mocks base method.
+func (m *MockManager) Resolve(ctx context.Context, id uint, resolvedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", ctx, id, resolvedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockManagerMockRecorder) Resolve(ctx, id, resolvedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockManager)(nil).Resolve), ctx, id, resolvedAt)
+}
+
+// UpdateResynced mocks base method.
+func (m *MockManager) UpdateResynced(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateResynced", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateResynced indicates an expected call of UpdateResynced.
+func (mr *MockManagerMockRecorder) UpdateResynced(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateResynced", reflect.TypeOf((*MockManager)(nil).UpdateResynced), ctx, id)
+}
@@ -188,9 +188,9 @@ func (mr *MockManagerMockRecorder) GetByPaths(ctx, paths interface{}) *gomock.Ca
 }
 
 // GetChildren mocks base method.
-func (m *MockManager) GetChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) ([]*models0.GroupOrApplication, int64, error) {
+func (m *MockManager) GetChildren(ctx context.Context, parentID uint, invisible *models0.Invisible, pageNumber, pageSize int) ([]*models0.GroupOrApplication, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetChildren", ctx, parentID, pageNumber, pageSize)
+	ret := m.ctrl.Call(m, "GetChildren", ctx, parentID, invisible, pageNumber, pageSize)
 	ret0, _ := ret[0].([]*models0.GroupOrApplication)
 	ret1, _ := ret[1].(int64)
 	ret2, _ := ret[2].(error)
@@ -198,9 +198,9 @@ func (m *MockManager) GetChildren(ctx context.Context, parentID uint, pageNumber
 }
 
 // GetChildren indicates an expected call of GetChildren.
-func (mr *MockManagerMockRecorder) GetChildren(ctx, parentID, pageNumber, pageSize interface{}) *gomock.Call {
+func (mr *MockManagerMockRecorder) GetChildren(ctx, parentID, invisible, pageNumber, pageSize interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChildren", reflect.TypeOf((*MockManager)(nil).GetChildren), ctx, parentID, pageNumber, pageSize)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChildren", reflect.TypeOf((*MockManager)(nil).GetChildren), ctx, parentID, invisible, pageNumber, pageSize)
 }
 
 // GetDefaultRegions mocks base method.
@@ -249,9 +249,9 @@ func (mr *MockManagerMockRecorder) GetSelectableRegionsByEnv(ctx, id, env interf
 }
 
 // GetSubGroups mocks base method.
-func (m *MockManager) GetSubGroups(ctx context.Context, id uint, pageNumber, pageSize int) ([]*models0.Group, int64, error) {
+func (m *MockManager) GetSubGroups(ctx context.Context, id uint, invisible *models0.Invisible, pageNumber, pageSize int) ([]*models0.Group, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSubGroups", ctx, id, pageNumber, pageSize)
+	ret := m.ctrl.Call(m, "GetSubGroups", ctx, id, invisible, pageNumber, pageSize)
 	ret0, _ := ret[0].([]*models0.Group)
 	ret1, _ := ret[1].(int64)
 	ret2, _ := ret[2].(error)
@@ -259,9 +259,9 @@ func (m *MockManager) GetSubGroups(ctx context.Context, id uint, pageNumber, pag
 }
 
 // GetSubGroups indicates an expected call of GetSubGroups.
-func (mr *MockManagerMockRecorder) GetSubGroups(ctx, id, pageNumber, pageSize interface{}) *gomock.Call {
+func (mr *MockManagerMockRecorder) GetSubGroups(ctx, id, invisible, pageNumber, pageSize interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubGroups", reflect.TypeOf((*MockManager)(nil).GetSubGroups), ctx, id, pageNumber, pageSize)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubGroups", reflect.TypeOf((*MockManager)(nil).GetSubGroups), ctx, id, invisible, pageNumber, pageSize)
 }
 
 // GetSubGroupsByGroupIDs mocks base method.
@@ -7,6 +7,7 @@ package mock_manager
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/horizoncd/horizon/pkg/member/models"
@@ -152,6 +153,21 @@ func (mr *MockManagerMockRecorder) ListDirectMemberOnCondition(ctx, resourceType
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDirectMemberOnCondition", reflect.TypeOf((*MockManager)(nil).ListDirectMemberOnCondition), ctx, resourceType, resourceID)
 }
 
+// ListExpired mocks base method.
+func (m *MockManager) ListExpired(ctx context.Context, now time.Time, limit int) ([]models.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpired", ctx, now, limit)
+	ret0, _ := ret[0].([]models.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpired indicates an expected call of ListExpired.
+func (mr *MockManagerMockRecorder) ListExpired(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpired", reflect.TypeOf((*MockManager)(nil).ListExpired), ctx, now, limit)
+}
+
 // ListMembersByUserID mocks base method.
 func (m *MockManager) ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error) {
 	m.ctrl.T.Helper()
@@ -198,16 +214,16 @@ func (mr *MockManagerMockRecorder) ListResourceOfMemberInfoByRole(ctx, resourceT
 }
 
 // UpdateByID mocks base method.
-func (m *MockManager) UpdateByID(ctx context.Context, id uint, role string) (*models.Member, error) {
+func (m *MockManager) UpdateByID(ctx context.Context, id uint, role string, expiredAt *time.Time, clearExpiredAt bool) (*models.Member, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateByID", ctx, id, role)
+	ret := m.ctrl.Call(m, "UpdateByID", ctx, id, role, expiredAt, clearExpiredAt)
 	ret0, _ := ret[0].(*models.Member)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateByID indicates an expected call of UpdateByID.
-func (mr *MockManagerMockRecorder) UpdateByID(ctx, id, role interface{}) *gomock.Call {
+func (mr *MockManagerMockRecorder) UpdateByID(ctx, id, role, expiredAt, clearExpiredAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockManager)(nil).UpdateByID), ctx, id, role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockManager)(nil).UpdateByID), ctx, id, role, expiredAt, clearExpiredAt)
 }
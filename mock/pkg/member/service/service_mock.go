@@ -7,6 +7,7 @@ package mock_service
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/horizoncd/horizon/pkg/member/models"
@@ -125,16 +126,16 @@ func (mr *MockServiceMockRecorder) RequirePermissionEqualOrHigher(ctx, role, res
 }
 
 // UpdateMember mocks base method.
-func (m *MockService) UpdateMember(ctx context.Context, memberID uint, role string) (*models.Member, error) {
+func (m *MockService) UpdateMember(ctx context.Context, memberID uint, role string, expiredAt *time.Time, clearExpiredAt bool) (*models.Member, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateMember", ctx, memberID, role)
+	ret := m.ctrl.Call(m, "UpdateMember", ctx, memberID, role, expiredAt, clearExpiredAt)
 	ret0, _ := ret[0].(*models.Member)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateMember indicates an expected call of UpdateMember.
-func (mr *MockServiceMockRecorder) UpdateMember(ctx, memberID, role interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) UpdateMember(ctx, memberID, role, expiredAt, clearExpiredAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMember", reflect.TypeOf((*MockService)(nil).UpdateMember), ctx, memberID, role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMember", reflect.TypeOf((*MockService)(nil).UpdateMember), ctx, memberID, role, expiredAt, clearExpiredAt)
 }
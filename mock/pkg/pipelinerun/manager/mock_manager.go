@@ -36,6 +36,20 @@ func (m *MockManager) EXPECT() *MockManagerMockRecorder {
 	return m.recorder
 }
 
+// ClearCollectedByID mocks base method.
+func (m *MockManager) ClearCollectedByID(ctx context.Context, pipelinerunID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCollectedByID", ctx, pipelinerunID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCollectedByID indicates an expected call of ClearCollectedByID.
+func (mr *MockManagerMockRecorder) ClearCollectedByID(ctx, pipelinerunID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCollectedByID", reflect.TypeOf((*MockManager)(nil).ClearCollectedByID), ctx, pipelinerunID)
+}
+
 // Create mocks base method.
 func (m *MockManager) Create(ctx context.Context, pipelinerun *models.Pipelinerun) (*models.Pipelinerun, error) {
 	m.ctrl.T.Helper()
@@ -185,6 +199,36 @@ func (mr *MockManagerMockRecorder) GetLatestSuccessByClusterID(ctx, clusterID in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestSuccessByClusterID", reflect.TypeOf((*MockManager)(nil).GetLatestSuccessByClusterID), ctx, clusterID)
 }
 
+// ListClusterIDsWithCollected mocks base method.
+func (m *MockManager) ListClusterIDsWithCollected(ctx context.Context) ([]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClusterIDsWithCollected", ctx)
+	ret0, _ := ret[0].([]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClusterIDsWithCollected indicates an expected call of ListClusterIDsWithCollected.
+func (mr *MockManagerMockRecorder) ListClusterIDsWithCollected(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusterIDsWithCollected", reflect.TypeOf((*MockManager)(nil).ListClusterIDsWithCollected), ctx)
+}
+
+// ListCollectedByClusterID mocks base method.
+func (m *MockManager) ListCollectedByClusterID(ctx context.Context, clusterID uint, offset, limit int) ([]*models.Pipelinerun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCollectedByClusterID", ctx, clusterID, offset, limit)
+	ret0, _ := ret[0].([]*models.Pipelinerun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCollectedByClusterID indicates an expected call of ListCollectedByClusterID.
+func (mr *MockManagerMockRecorder) ListCollectedByClusterID(ctx, clusterID, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCollectedByClusterID", reflect.TypeOf((*MockManager)(nil).ListCollectedByClusterID), ctx, clusterID, offset, limit)
+}
+
 // UpdateCIEventIDByID mocks base method.
 func (m *MockManager) UpdateCIEventIDByID(ctx context.Context, pipelinerunID uint, ciEventID string) error {
 	m.ctrl.T.Helper()
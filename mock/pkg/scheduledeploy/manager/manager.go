@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: manager.go
+
+// Package mock_manager is a generated GoMock package.
+package mock_manager
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/horizoncd/horizon/pkg/scheduledeploy/models"
+)
+
+// MockManager is a mock of Manager interface.
+type MockManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockManagerMockRecorder
+}
+
+// MockManagerMockRecorder is the mock recorder for MockManager.
+type MockManagerMockRecorder struct {
+	mock *MockManager
+}
+
+// NewMockManager creates a new mock instance.
+func NewMockManager(ctrl *gomock.Controller) *MockManager {
+	mock := &MockManager{ctrl: ctrl}
+	mock.recorder = &MockManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockManager) EXPECT() *MockManagerMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockManager) Create(ctx context.Context, scheduledDeploy *models.ScheduledDeploy) (*models.ScheduledDeploy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, scheduledDeploy)
+	ret0, _ := ret[0].(*models.ScheduledDeploy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockManagerMockRecorder) Create(ctx, scheduledDeploy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockManager)(nil).Create), ctx, scheduledDeploy)
+}
+
+// GetByID mocks base method.
+func (m *MockManager) GetByID(ctx context.Context, id uint) (*models.ScheduledDeploy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.ScheduledDeploy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockManagerMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockManager)(nil).GetByID), ctx, id)
+}
+
+// ListByClusterID mocks base method.
+func (m *MockManager) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.ScheduledDeploy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByClusterID", ctx, clusterID)
+	ret0, _ := ret[0].([]*models.ScheduledDeploy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByClusterID indicates an expected call of ListByClusterID.
+func (mr *MockManagerMockRecorder) ListByClusterID(ctx, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByClusterID", reflect.TypeOf((*MockManager)(nil).ListByClusterID), ctx, clusterID)
+}
+
+// ListDue mocks base method.
+func (m *MockManager) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledDeploy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDue", ctx, now, limit)
+	ret0, _ := ret[0].([]*models.ScheduledDeploy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDue indicates an expected call of ListDue.
+func (mr *MockManagerMockRecorder) ListDue(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDue", reflect.TypeOf((*MockManager)(nil).ListDue), ctx, now, limit)
+}
+
+// UpdateResult mocks base method.
+func (m *MockManager) UpdateResult(ctx context.Context, id uint, status string, pipelinerunID uint, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateResult", ctx, id, status, pipelinerunID, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateResult indicates an expected call of UpdateResult.
+func (mr *MockManagerMockRecorder) UpdateResult(ctx, id, status, pipelinerunID, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateResult", reflect.TypeOf((*MockManager)(nil).UpdateResult), ctx, id, status, pipelinerunID, message)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockManager) UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, id, fromStatus, toStatus)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockManagerMockRecorder) UpdateStatus(ctx, id, fromStatus, toStatus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockManager)(nil).UpdateStatus), ctx, id, fromStatus, toStatus)
+}
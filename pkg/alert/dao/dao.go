@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/alert/models"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	// ListByClusterID lists alert rules of cluster
+	ListByClusterID(ctx context.Context, clusterID uint) ([]*models.AlertRule, error)
+	// UpdateByClusterID replaces all alert rules of cluster with rules
+	UpdateByClusterID(ctx context.Context, clusterID uint, rules []*models.AlertRule) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.AlertRule, error) {
+	var rules []*models.AlertRule
+	result := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Order("id asc").Find(&rules)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.AlertRuleInDB, result.Error.Error())
+	}
+	return rules, nil
+}
+
+func (d *dao) UpdateByClusterID(ctx context.Context, clusterID uint, rules []*models.AlertRule) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cluster_id = ?", clusterID).
+			Delete(&models.AlertRule{}).Error; err != nil {
+			return herrors.NewErrDeleteFailed(herrors.AlertRuleInDB, err.Error())
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		// rules are recreated, so the ids are regenerated
+		for _, rule := range rules {
+			rule.ID = 0
+			rule.ClusterID = clusterID
+		}
+		if err := tx.Create(rules).Error; err != nil {
+			return herrors.NewErrInsertFailed(herrors.AlertRuleInDB, err.Error())
+		}
+		return nil
+	})
+}
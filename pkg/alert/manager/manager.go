@@ -0,0 +1,112 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"regexp"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/alert/dao"
+	"github.com/horizoncd/horizon/pkg/alert/manifest"
+	"github.com/horizoncd/horizon/pkg/alert/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// durationPattern matches the duration format of prometheus, such as 30s, 5m or 1h30m
+var durationPattern = regexp.MustCompile(`^([0-9]+(ms|s|m|h|d|w|y))+$`)
+
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/alert/manager/manager.go -package=mock_manager
+type Manager interface {
+	// ListByClusterID lists alert rules of cluster
+	ListByClusterID(ctx context.Context, clusterID uint) ([]*models.AlertRule, error)
+	// UpdateByClusterID replaces all alert rules of cluster with rules
+	UpdateByClusterID(ctx context.Context, clusterID uint, rules []*models.AlertRule) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.AlertRule, error) {
+	return m.dao.ListByClusterID(ctx, clusterID)
+}
+
+func (m *manager) UpdateByClusterID(ctx context.Context, clusterID uint, rules []*models.AlertRule) error {
+	return m.dao.UpdateByClusterID(ctx, clusterID, rules)
+}
+
+// ValidateUpdate validates alert rules before update
+func ValidateUpdate(rules []*models.AlertRule) error {
+	if len(rules) > 20 {
+		return perror.Wrap(herrors.ErrParamInvalid, "the count of alert rules must be less than 20")
+	}
+	names := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		if len(rule.Name) == 0 || len(rule.Name) > 64 {
+			return perror.Wrap(herrors.ErrParamInvalid, "alert rule name must be 1 to 64 characters")
+		}
+		if _, ok := names[rule.Name]; ok {
+			return perror.Wrapf(herrors.ErrParamInvalid, "alert rule name %v is duplicated", rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+
+		switch rule.Type {
+		case models.RuleTypeErrorRate, models.RuleTypeRestarts, models.RuleTypeQuery:
+		default:
+			return perror.Wrapf(herrors.ErrParamInvalid, "alert rule type %v is not supported", rule.Type)
+		}
+		switch rule.Operator {
+		case models.OperatorLessThan, models.OperatorGreaterThan:
+		default:
+			return perror.Wrapf(herrors.ErrParamInvalid, "alert rule operator %v is not supported", rule.Operator)
+		}
+		switch rule.Severity {
+		case models.SeverityCritical, models.SeverityWarning, models.SeverityInfo:
+		default:
+			return perror.Wrapf(herrors.ErrParamInvalid, "alert rule severity %v is not supported", rule.Severity)
+		}
+		if !durationPattern.MatchString(rule.Duration) {
+			return perror.Wrapf(herrors.ErrParamInvalid, "duration %v of alert rule %v is invalid",
+				rule.Duration, rule.Name)
+		}
+		if len(rule.Description) > 256 {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"description of alert rule %v must be less than 256 characters", rule.Name)
+		}
+		// query of errorRate and restarts can be empty, the default query will be used
+		if len(rule.Query) == 0 && rule.Type == models.RuleTypeQuery {
+			return perror.Wrapf(herrors.ErrParamInvalid, "query of alert rule %v cannot be empty", rule.Name)
+		}
+		if len(rule.Query) > 1024 {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"query of alert rule %v must be less than 1024 characters", rule.Name)
+		}
+		// custom queries must be scoped to the cluster
+		if len(rule.Query) > 0 {
+			if err := manifest.ValidateQuery(rule.Name, rule.Query); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
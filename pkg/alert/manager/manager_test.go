@@ -0,0 +1,91 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/alert/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.AlertRule{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	rules := []*models.AlertRule{
+		{
+			Name:      "5xx",
+			Type:      models.RuleTypeErrorRate,
+			Operator:  models.OperatorGreaterThan,
+			Threshold: 0.05,
+			Duration:  "5m",
+			Severity:  models.SeverityCritical,
+		}, {
+			Name:      "qps",
+			Type:      models.RuleTypeQuery,
+			Query:     `sum(rate(http_requests_total{cluster="{{.Cluster}}"}[1m]))`,
+			Operator:  models.OperatorLessThan,
+			Threshold: 1,
+			Duration:  "1h30m",
+			Severity:  models.SeverityInfo,
+		},
+	}
+	assert.Nil(t, ValidateUpdate(rules))
+	assert.Nil(t, mgr.UpdateByClusterID(ctx, 1, rules))
+	assert.Nil(t, mgr.UpdateByClusterID(ctx, 2, rules[:1]))
+
+	ret, err := mgr.ListByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ret))
+	assert.Equal(t, "qps", ret[1].Name)
+	assert.Equal(t, "1h30m", ret[1].Duration)
+
+	assert.Nil(t, mgr.UpdateByClusterID(ctx, 1, nil))
+	ret, err = mgr.ListByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ret))
+	ret, err = mgr.ListByClusterID(ctx, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ret))
+
+	// invalid rules
+	for _, rule := range []*models.AlertRule{
+		{Name: "", Type: models.RuleTypeErrorRate, Operator: ">", Duration: "5m", Severity: "info"},
+		{Name: "a", Type: "unknown", Operator: ">", Duration: "5m", Severity: "info"},
+		{Name: "a", Type: models.RuleTypeErrorRate, Operator: "=", Duration: "5m", Severity: "info"},
+		{Name: "a", Type: models.RuleTypeErrorRate, Operator: ">", Duration: "5m", Severity: "fatal"},
+		{Name: "a", Type: models.RuleTypeErrorRate, Operator: ">", Duration: "5 min", Severity: "info"},
+		{Name: "a", Type: models.RuleTypeQuery, Operator: ">", Duration: "5m", Severity: "info"},
+		{Name: "a", Type: models.RuleTypeQuery, Query: "{{.Cluster", Operator: ">", Duration: "5m", Severity: "info"},
+	} {
+		assert.NotNil(t, ValidateUpdate([]*models.AlertRule{rule}))
+	}
+	assert.NotNil(t, ValidateUpdate([]*models.AlertRule{rules[0], rules[0]}))
+}
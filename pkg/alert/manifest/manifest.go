@@ -0,0 +1,262 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"text/template"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/alert/models"
+	alertconfig "github.com/horizoncd/horizon/pkg/config/alert"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/prometheus"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// LabelCluster, LabelApplication and LabelEnvironment are added to each alert,
+	// notifications are routed to the webhooks of cluster by LabelCluster
+	LabelCluster     = "horizon_cluster"
+	LabelApplication = "horizon_application"
+	LabelEnvironment = "horizon_environment"
+	LabelSeverity    = "severity"
+
+	_receiverName = "horizon"
+)
+
+// _podSelector selects pods of the cluster
+var _podSelector = prometheus.ClusterPodSelector(`="{{.Cluster}}"`)
+
+// defaultQueries are used by rules whose query is empty, they can be overridden in alert config
+var defaultQueries = map[string]string{
+	models.RuleTypeErrorRate: `sum(rate(nginx_ingress_controller_requests{service="{{.Cluster}}",status=~"5.."}[5m]))` +
+		` / sum(rate(nginx_ingress_controller_requests{service="{{.Cluster}}"}[5m]))`,
+	models.RuleTypeRestarts: `sum(increase(kube_pod_container_status_restarts_total[10m])` + _podSelector + `)`,
+}
+
+// QueryVars are the variables which can be referenced in the query of alert rule
+type QueryVars struct {
+	Application string
+	Cluster     string
+	Environment string
+	Region      string
+}
+
+type metadata struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type prometheusRule struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Metadata   metadata           `json:"metadata"`
+	Spec       prometheusRuleSpec `json:"spec"`
+}
+
+type prometheusRuleSpec struct {
+	Groups []ruleGroup `json:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string `json:"name"`
+	Rules []rule `json:"rules"`
+}
+
+type rule struct {
+	Alert       string            `json:"alert"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type alertmanagerConfig struct {
+	APIVersion string                 `json:"apiVersion"`
+	Kind       string                 `json:"kind"`
+	Metadata   metadata               `json:"metadata"`
+	Spec       alertmanagerConfigSpec `json:"spec"`
+}
+
+type alertmanagerConfigSpec struct {
+	Route     route      `json:"route"`
+	Receivers []receiver `json:"receivers"`
+}
+
+type route struct {
+	Receiver string    `json:"receiver"`
+	GroupBy  []string  `json:"groupBy"`
+	Matchers []matcher `json:"matchers"`
+}
+
+type matcher struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type receiver struct {
+	Name           string          `json:"name"`
+	WebhookConfigs []webhookConfig `json:"webhookConfigs"`
+}
+
+type webhookConfig struct {
+	URL          string      `json:"url"`
+	SendResolved bool        `json:"sendResolved"`
+	HTTPConfig   *httpConfig `json:"httpConfig,omitempty"`
+}
+
+type httpConfig struct {
+	Authorization authorization `json:"authorization"`
+}
+
+type authorization struct {
+	Type        string            `json:"type"`
+	Credentials secretKeySelector `json:"credentials"`
+}
+
+type secretKeySelector struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// Render renders alert rules of cluster into a PrometheusRule, and an AlertmanagerConfig
+// which routes the alerts of cluster to the receiver of horizon.
+// It returns nil when there are no rules, which means the manifests should be removed.
+func Render(config *alertconfig.Config, rules []*models.AlertRule, vars *QueryVars) ([]byte, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	labels := map[string]string{common.ClusterClusterLabelKey: vars.Cluster}
+	for k, v := range config.RuleLabels {
+		labels[k] = v
+	}
+	meta := metadata{
+		Name:   fmt.Sprintf("%s-horizon-alerts", vars.Cluster),
+		Labels: labels,
+	}
+
+	group := ruleGroup{Name: vars.Cluster}
+	for _, r := range rules {
+		queryTemplate := r.Query
+		if queryTemplate == "" {
+			queryTemplate = config.DefaultQueries[r.Type]
+		}
+		if queryTemplate == "" {
+			queryTemplate = defaultQueries[r.Type]
+		}
+		if queryTemplate == "" {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"query of alert rule %v is empty and no default query for type %v", r.Name, r.Type)
+		}
+		query, err := RenderQuery(r.Name, queryTemplate, vars)
+		if err != nil {
+			return nil, err
+		}
+		// rules saved before custom queries are scoped may select metrics of other clusters
+		if r.Query != "" {
+			if err := checkScope(r.Name, query, vars.Cluster); err != nil {
+				return nil, err
+			}
+		}
+		summary := r.Description
+		if summary == "" {
+			summary = fmt.Sprintf("%s of cluster %s is {{ $value }}, threshold: %s %s",
+				r.Name, vars.Cluster, r.Operator, formatFloat(r.Threshold))
+		}
+		group.Rules = append(group.Rules, rule{
+			Alert: r.Name,
+			Expr:  fmt.Sprintf("(%s) %s %s", query, r.Operator, formatFloat(r.Threshold)),
+			For:   r.Duration,
+			Labels: map[string]string{
+				LabelSeverity:    r.Severity,
+				LabelCluster:     vars.Cluster,
+				LabelApplication: vars.Application,
+				LabelEnvironment: vars.Environment,
+			},
+			Annotations: map[string]string{
+				"summary": summary,
+			},
+		})
+	}
+	docs := []interface{}{
+		&prometheusRule{
+			APIVersion: "monitoring.coreos.com/v1",
+			Kind:       "PrometheusRule",
+			Metadata:   meta,
+			Spec:       prometheusRuleSpec{Groups: []ruleGroup{group}},
+		},
+	}
+
+	if config.Receiver.URL != "" {
+		c := webhookConfig{URL: config.Receiver.URL, SendResolved: true}
+		if config.Receiver.TokenSecretName != "" {
+			c.HTTPConfig = &httpConfig{Authorization: authorization{
+				Type: "Bearer",
+				Credentials: secretKeySelector{
+					Name: config.Receiver.TokenSecretName,
+					Key:  config.Receiver.TokenSecretKey,
+				},
+			}}
+		}
+		docs = append(docs, &alertmanagerConfig{
+			APIVersion: "monitoring.coreos.com/v1alpha1",
+			Kind:       "AlertmanagerConfig",
+			Metadata:   meta,
+			Spec: alertmanagerConfigSpec{
+				Route: route{
+					Receiver: _receiverName,
+					GroupBy:  []string{"alertname"},
+					Matchers: []matcher{{Name: LabelCluster, Value: vars.Cluster}},
+				},
+				Receivers: []receiver{{Name: _receiverName, WebhookConfigs: []webhookConfig{c}}},
+			},
+		})
+	}
+
+	var buf bytes.Buffer
+	for i, doc := range docs {
+		content, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to marshal alert manifests: %v", err)
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(content)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderQuery renders the query template of alert rule with vars
+func RenderQuery(name, query string, vars *QueryVars) (string, error) {
+	tpl, err := template.New(name).Option("missingkey=error").Parse(query)
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "query of alert rule %v is invalid: %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, vars); err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "failed to render query of alert rule %v: %v", name, err)
+	}
+	return buf.String(), nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
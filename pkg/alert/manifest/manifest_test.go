@@ -0,0 +1,131 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/horizoncd/horizon/pkg/alert/models"
+	alertconfig "github.com/horizoncd/horizon/pkg/config/alert"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestRender(t *testing.T) {
+	config := &alertconfig.Config{
+		DefaultQueries: map[string]string{
+			models.RuleTypeErrorRate: `sum(rate(http_errors{cluster="{{.Cluster}}"}[5m]))`,
+		},
+		RuleLabels: map[string]string{"release": "prometheus"},
+	}
+	vars := &QueryVars{
+		Application: "app",
+		Cluster:     "app-test",
+		Environment: "test",
+		Region:      "hz",
+	}
+	rules := []*models.AlertRule{
+		{
+			Name:      "5xx",
+			Type:      models.RuleTypeErrorRate,
+			Operator:  models.OperatorGreaterThan,
+			Threshold: 0.05,
+			Duration:  "5m",
+			Severity:  models.SeverityCritical,
+		}, {
+			Name:        "restarts",
+			Type:        models.RuleTypeRestarts,
+			Operator:    models.OperatorGreaterThan,
+			Threshold:   3,
+			Duration:    "1m",
+			Severity:    models.SeverityWarning,
+			Description: "pods restart frequently",
+		}, {
+			Name:      "qps",
+			Type:      models.RuleTypeQuery,
+			Query:     `sum(rate(http_requests{service="{{.Cluster}}",region="{{.Region}}"}[1m]))`,
+			Operator:  models.OperatorLessThan,
+			Threshold: 1,
+			Duration:  "10m",
+			Severity:  models.SeverityInfo,
+		},
+	}
+
+	// no rules
+	content, err := Render(config, nil, vars)
+	assert.Nil(t, err)
+	assert.Nil(t, content)
+
+	// no receiver
+	content, err = Render(config, rules, vars)
+	assert.Nil(t, err)
+	docs := strings.Split(string(content), "---\n")
+	assert.Equal(t, 1, len(docs))
+
+	var rule prometheusRule
+	assert.Nil(t, yaml.Unmarshal([]byte(docs[0]), &rule))
+	assert.Equal(t, "PrometheusRule", rule.Kind)
+	assert.Equal(t, "app-test-horizon-alerts", rule.Metadata.Name)
+	assert.Equal(t, "prometheus", rule.Metadata.Labels["release"])
+	assert.Equal(t, 1, len(rule.Spec.Groups))
+	alerts := rule.Spec.Groups[0].Rules
+	assert.Equal(t, 3, len(alerts))
+	assert.Equal(t, `(sum(rate(http_errors{cluster="app-test"}[5m]))) > 0.05`, alerts[0].Expr)
+	assert.Equal(t, "5m", alerts[0].For)
+	assert.Equal(t, models.SeverityCritical, alerts[0].Labels[LabelSeverity])
+	assert.Equal(t, "app-test", alerts[0].Labels[LabelCluster])
+	assert.Equal(t, "app", alerts[0].Labels[LabelApplication])
+	assert.Equal(t, "test", alerts[0].Labels[LabelEnvironment])
+	assert.Contains(t, alerts[0].Annotations["summary"], "{{ $value }}")
+	assert.Contains(t, alerts[1].Expr, `label_cloudnative_music_netease_com_cluster="app-test"`)
+	assert.Equal(t, "pods restart frequently", alerts[1].Annotations["summary"])
+	assert.Equal(t, `(sum(rate(http_requests{service="app-test",region="hz"}[1m]))) < 1`, alerts[2].Expr)
+
+	// with receiver
+	config.Receiver = alertconfig.Receiver{
+		URL:             "https://horizon/apis/internal/v2/alerts",
+		Token:           "token",
+		TokenSecretName: "horizon-alert",
+		TokenSecretKey:  "token",
+	}
+	content, err = Render(config, rules, vars)
+	assert.Nil(t, err)
+	docs = strings.Split(string(content), "---\n")
+	assert.Equal(t, 2, len(docs))
+
+	var amConfig alertmanagerConfig
+	assert.Nil(t, yaml.Unmarshal([]byte(docs[1]), &amConfig))
+	assert.Equal(t, "AlertmanagerConfig", amConfig.Kind)
+	assert.Equal(t, []matcher{{Name: LabelCluster, Value: "app-test"}}, amConfig.Spec.Route.Matchers)
+	assert.Equal(t, 1, len(amConfig.Spec.Receivers))
+	configs := amConfig.Spec.Receivers[0].WebhookConfigs
+	assert.Equal(t, 1, len(configs))
+	assert.Equal(t, "https://horizon/apis/internal/v2/alerts", configs[0].URL)
+	assert.Equal(t, "Bearer", configs[0].HTTPConfig.Authorization.Type)
+	assert.Equal(t, secretKeySelector{Name: "horizon-alert", Key: "token"},
+		configs[0].HTTPConfig.Authorization.Credentials)
+
+	// invalid query
+	_, err = Render(config, []*models.AlertRule{{Name: "a", Type: models.RuleTypeQuery,
+		Query: "{{.Namespace}}"}}, vars)
+	assert.NotNil(t, err)
+	// query not scoped to the cluster
+	_, err = Render(config, []*models.AlertRule{{Name: "a", Type: models.RuleTypeQuery,
+		Query: `sum(rate(http_requests{env="{{.Environment}}"}[1m]))`}}, vars)
+	assert.NotNil(t, err)
+	_, err = Render(&alertconfig.Config{}, []*models.AlertRule{{Name: "a", Type: models.RuleTypeQuery}}, vars)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,208 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// _placeholderCluster is the cluster name to render query templates with when validating them
+const _placeholderCluster = "__horizon_cluster__"
+
+// groupingKeywords are followed by a list of labels rather than an expression
+var groupingKeywords = map[string]struct{}{
+	"by": {}, "without": {}, "on": {}, "ignoring": {}, "group_left": {}, "group_right": {},
+}
+
+// keywords are the identifiers which are not metric names when they are not followed by a selector
+var keywords = map[string]struct{}{
+	"and": {}, "or": {}, "unless": {}, "bool": {}, "offset": {}, "atan2": {}, "inf": {}, "nan": {},
+	"by": {}, "without": {}, "on": {}, "ignoring": {}, "group_left": {}, "group_right": {},
+}
+
+// ValidateQuery checks that the query template is valid and scoped to the cluster
+func ValidateQuery(name, query string) error {
+	rendered, err := RenderQuery(name, query, &QueryVars{
+		Application: "__horizon_application__",
+		Cluster:     _placeholderCluster,
+		Environment: "__horizon_environment__",
+		Region:      "__horizon_region__",
+	})
+	if err != nil {
+		return err
+	}
+	return checkScope(name, rendered, _placeholderCluster)
+}
+
+// checkScope checks that every vector selector of the query has a matcher which equals to the cluster,
+// so that a rule of one cluster never selects metrics of the others
+func checkScope(name, query, cluster string) error {
+	errUnscoped := func(selector string) error {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"selector %s in query of alert rule %v must match the cluster, such as service=\"{{.Cluster}}\"",
+			selector, name)
+	}
+	for i := 0; i < len(query); {
+		ch := query[i]
+		switch {
+		case ch == '"' || ch == '\'' || ch == '`':
+			i = skipString(query, i)
+		case ch == '[':
+			i = skipUntil(query, i, ']')
+		case ch == '{':
+			end, scoped := scanMatchers(query, i, cluster)
+			if !scoped {
+				return errUnscoped(query[i:end])
+			}
+			i = end
+		case isIdentStart(ch):
+			start := i
+			for i < len(query) && isIdentChar(query[i]) {
+				i++
+			}
+			ident := query[start:i]
+			next := skipSpaces(query, i)
+			switch {
+			case next < len(query) && query[next] == '(':
+				if _, ok := groupingKeywords[ident]; ok {
+					i = skipUntil(query, next, ')')
+				}
+			case next < len(query) && query[next] == '{':
+				end, scoped := scanMatchers(query, next, cluster)
+				if !scoped {
+					return errUnscoped(query[start:end])
+				}
+				i = end
+			case hasIdentPrefix(query[next:], "by") || hasIdentPrefix(query[next:], "without"):
+				// aggregation with grouping before the expression, such as sum by (pod) (...)
+			default:
+				if _, ok := keywords[strings.ToLower(ident)]; !ok {
+					return errUnscoped(ident)
+				}
+			}
+		case ch >= '0' && ch <= '9' || ch == '.':
+			// numbers and durations
+			for i < len(query) && (isIdentChar(query[i]) || query[i] == '.') {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
+// scanMatchers scans the label matchers starting at the '{' at start, returns the position
+// after the closing '}', and whether there is a matcher which equals to the cluster
+func scanMatchers(query string, start int, cluster string) (int, bool) {
+	scoped := false
+	i := start + 1
+	for i < len(query) && query[i] != '}' {
+		i = skipSpaces(query, i)
+		if i >= len(query) || query[i] == '}' {
+			break
+		}
+		if query[i] == ',' {
+			i++
+			continue
+		}
+		// label name
+		for i < len(query) && isIdentChar(query[i]) {
+			i++
+		}
+		i = skipSpaces(query, i)
+		// match operator
+		opStart := i
+		for i < len(query) && strings.IndexByte("=!~", query[i]) >= 0 {
+			i++
+		}
+		op := query[opStart:i]
+		i = skipSpaces(query, i)
+		if i >= len(query) || strings.IndexByte("\"'`", query[i]) < 0 {
+			// malformed matcher, leave it to prometheus
+			i++
+			continue
+		}
+		end := skipString(query, i)
+		if op == "=" && unquote(query[i:end]) == cluster {
+			scoped = true
+		}
+		i = end
+	}
+	if i < len(query) {
+		i++
+	}
+	return i, scoped
+}
+
+// skipString returns the position after the string literal starting at start
+func skipString(query string, start int) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		if query[i] == '\\' && quote != '`' {
+			i++
+			continue
+		}
+		if query[i] == quote {
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// skipUntil returns the position after the first close after start, string literals are skipped
+func skipUntil(query string, start int, closeCh byte) int {
+	for i := start + 1; i < len(query); {
+		switch query[i] {
+		case '"', '\'', '`':
+			i = skipString(query, i)
+		case closeCh:
+			return i + 1
+		default:
+			i++
+		}
+	}
+	return len(query)
+}
+
+func skipSpaces(query string, i int) int {
+	for i < len(query) && strings.IndexByte(" \t\r\n", query[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// hasIdentPrefix returns whether s starts with the identifier ident
+func hasIdentPrefix(s, ident string) bool {
+	return strings.HasPrefix(s, ident) && (len(s) == len(ident) || !isIdentChar(s[len(ident)]))
+}
+
+func unquote(literal string) string {
+	if len(literal) < 2 {
+		return ""
+	}
+	return literal[1 : len(literal)-1]
+}
+
+func isIdentStart(ch byte) bool {
+	return ch == '_' || ch == ':' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isIdentChar(ch byte) bool {
+	return isIdentStart(ch) || (ch >= '0' && ch <= '9')
+}
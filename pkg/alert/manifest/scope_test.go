@@ -0,0 +1,50 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateQuery(t *testing.T) {
+	scoped := []string{
+		`sum(rate(http_requests{service="{{.Cluster}}",status=~"5.."}[5m]))`,
+		`sum by (pod) (rate(http_requests{ service = "{{.Cluster}}" }[5m])) / 2`,
+		`sum(rate({__name__="http_requests", cluster="{{.Cluster}}"}[1m] offset 5m)) > bool 1`,
+		`max(a{cluster="{{.Cluster}}"}) by (pod) * on(pod) group_left(node) b{cluster='{{.Cluster}}'}`,
+		`histogram_quantile(0.99, sum(rate(latency_bucket{cluster="{{.Cluster}}"}[5m])) by (le))`,
+		`vector(1)`,
+	}
+	for _, query := range scoped {
+		assert.Nil(t, ValidateQuery("a", query), query)
+	}
+
+	unscoped := []string{
+		`sum(rate(http_requests[5m]))`,
+		`sum(rate(http_requests{env="{{.Environment}}"}[5m]))`,
+		`sum(rate(http_requests{cluster!="{{.Cluster}}"}[5m]))`,
+		`sum(rate(http_requests{cluster=~"{{.Cluster}}|.*"}[5m]))`,
+		`sum(rate(http_requests{cluster="{{.Cluster}}"}[5m])) or up`,
+		`a{cluster="{{.Cluster}}"} * on(pod) group_left() b{namespace="default"}`,
+		`{{.Cluster`,
+	}
+	for _, query := range unscoped {
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(ValidateQuery("a", query)), query)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	// RuleTypeErrorRate alerts on the 5xx rate of cluster
+	RuleTypeErrorRate = "errorRate"
+	// RuleTypeRestarts alerts on the container restarts of cluster
+	RuleTypeRestarts = "restarts"
+	// RuleTypeQuery alerts on the value of a custom PromQL query
+	RuleTypeQuery = "query"
+
+	// OperatorLessThan means the alert fires when value < threshold
+	OperatorLessThan = "<"
+	// OperatorGreaterThan means the alert fires when value > threshold
+	OperatorGreaterThan = ">"
+
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// AlertRule is an alerting rule of cluster, rules of a cluster are rendered into
+// a PrometheusRule which is committed to the gitops repo of cluster.
+type AlertRule struct {
+	ID        uint
+	ClusterID uint
+	Name      string
+	Type      string
+	// Query is a PromQL query in go template format,
+	// {{.Application}}, {{.Cluster}}, {{.Environment}} and {{.Region}} are available.
+	// If it is empty, the default query of Type is used.
+	Query     string
+	Operator  string
+	Threshold float64
+	// Duration is how long the condition lasts before the alert fires, such as 5m
+	Duration    string
+	Severity    string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CreatedBy   uint
+	UpdatedBy   uint
+}
@@ -40,6 +40,7 @@ type DAO interface {
 	GetByID(ctx context.Context, id uint, includeSoftDelete bool) (*models.Application, error)
 	GetByIDs(ctx context.Context, ids []uint) ([]*models.Application, error)
 	GetByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.Application, error)
+	GetByVisibilityLevel(ctx context.Context, visibilityLevel string) ([]*models.Application, error)
 	GetByName(ctx context.Context, name string) (*models.Application, error)
 	GetByNamesUnderGroup(ctx context.Context, groupID uint, names []string) ([]*models.Application, error)
 	// GetByNameFuzzily get applications that fuzzily matching the given name
@@ -125,6 +126,17 @@ func (d *dao) GetByIDs(ctx context.Context, ids []uint) ([]*models.Application,
 	return applications, nil
 }
 
+func (d *dao) GetByVisibilityLevel(ctx context.Context, visibilityLevel string) ([]*models.Application, error) {
+	var applications []*models.Application
+	result := d.db.WithContext(ctx).Raw(common.ApplicationQueryByVisibilityLevel, visibilityLevel).Scan(&applications)
+
+	if result.Error != nil {
+		return applications, herrors.NewErrGetFailed(herrors.ApplicationInDB, result.Error.Error())
+	}
+
+	return applications, nil
+}
+
 func (d *dao) GetByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.Application, error) {
 	var applications []*models.Application
 	result := d.db.WithContext(ctx).Raw(common.ApplicationQueryByGroupIDs, groupIDs).Scan(&applications)
@@ -239,6 +251,7 @@ func (d *dao) UpdateByID(ctx context.Context, id uint, application *models.Appli
 		applicationInDB.GitRef = application.GitRef
 		applicationInDB.Template = application.Template
 		applicationInDB.TemplateRelease = application.TemplateRelease
+		applicationInDB.VisibilityLevel = application.VisibilityLevel
 		// 3. save application after updated
 		tx.Save(&applicationInDB)
 
@@ -315,6 +328,10 @@ func (d *dao) List(ctx context.Context, groupIDs []uint,
 				statement = statement.Where("a.template = ?", v)
 			case corecommon.ApplicationQueryByRelease:
 				statement = statement.Where("a.template_release = ?", v)
+			case corecommon.ApplicationQueryExcludeGroups:
+				statement = statement.Where("a.group_id not in ?", v)
+			case corecommon.ApplicationQueryExcludeIDs:
+				statement = statement.Where("a.id not in ?", v)
 			}
 		}
 		statement = statement.Where("a.deleted_ts = 0")
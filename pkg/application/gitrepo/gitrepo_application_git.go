@@ -0,0 +1,172 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitrepo
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitlib "github.com/horizoncd/horizon/lib/git"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// appGitRepo stores applications in any git server through lib/git.
+// Git servers can't list repos, so all environments of an application are stored in one repo
+// at {rootPath}/applications/{application}, and files of each environment are placed in the directory
+// named by the environment. If flat is true, the path segments of repo are joined by '.' instead.
+type appGitRepo struct {
+	gitLib        gitlib.Interface
+	rootPath      string
+	flat          bool
+	defaultBranch string
+}
+
+type ApplicationGitRepoGitConfig struct {
+	// RootPath is the path under which application repos are placed
+	RootPath string
+	// FlatRepoPath joins the path segments of repos by '.',
+	// for git servers which don't support nested paths, such as gitea
+	FlatRepoPath  bool
+	DefaultBranch string
+}
+
+var _ ApplicationGitRepo = &appGitRepo{}
+
+// NewApplicationGitRepo returns an ApplicationGitRepo which stores applications in any git server
+// or a local directory of bare repos, see lib/git for more information.
+func NewApplicationGitRepo(gitLib gitlib.Interface, config ApplicationGitRepoGitConfig) ApplicationGitRepo {
+	return &appGitRepo{
+		gitLib:        gitLib,
+		rootPath:      strings.Trim(config.RootPath, "/"),
+		flat:          config.FlatRepoPath,
+		defaultBranch: config.DefaultBranch,
+	}
+}
+
+func (g *appGitRepo) repoPID(application string) string {
+	segments := []string{_applications, application}
+	if g.rootPath != "" {
+		segments = append([]string{g.rootPath}, segments...)
+	}
+	if g.flat {
+		return strings.Join(segments, ".")
+	}
+	return path.Join(segments...)
+}
+
+func (g *appGitRepo) CreateOrUpdateApplication(ctx context.Context,
+	application string, req CreateOrUpdateRequest) error {
+	const op = "git repo: create or update application"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	environment := common.ApplicationRepoDefaultEnv
+	if req.Environment != "" {
+		environment = req.Environment
+	}
+
+	// 1. create the repo of application if not exists
+	pid := g.repoPID(application)
+	commitAction := gitlib.FileUpdate
+	if _, err := g.gitLib.GetBranch(ctx, pid, g.defaultBranch); err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+		if err := g.gitLib.CreateRepo(ctx, pid); err != nil {
+			return err
+		}
+		commitAction = gitlib.FileCreate
+	}
+
+	// 2. write files, the action of each file depends on whether it exists
+	files, err := applicationFiles(ctx, req)
+	if err != nil {
+		return err
+	}
+	actions := make([]gitlib.CommitAction, 0, len(files))
+	for _, file := range files {
+		filePath := path.Join(environment, file.path)
+		action := gitlib.FileUpdate
+		if _, err := g.gitLib.GetFile(ctx, pid, g.defaultBranch, filePath); err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return err
+			}
+			action = gitlib.FileCreate
+		}
+		actions = append(actions, gitlib.CommitAction{
+			Action:   action,
+			FilePath: filePath,
+			Content:  file.content,
+		})
+	}
+
+	commitMsg := applicationCommitMessage(currentUser.GetName(), string(commitAction), environment, application, req)
+	_, err = g.gitLib.WriteFiles(ctx, pid, g.defaultBranch, commitMsg, nil, actions)
+	return err
+}
+
+func (g *appGitRepo) GetApplication(ctx context.Context, application, environment string) (*GetResponse, error) {
+	const op = "git repo: get application"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if environment == "" {
+		environment = common.ApplicationRepoDefaultEnv
+	}
+	pid := g.repoPID(application)
+	getFiles := func(environment string) ([][]byte, bool, error) {
+		contents := make([][]byte, 0, 3)
+		found := false
+		for _, file := range []string{_filePathManifest, _filePathPipeline, _filePathApplication} {
+			content, err := g.gitLib.GetFile(ctx, pid, g.defaultBranch, path.Join(environment, file))
+			if err != nil {
+				if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+					return nil, false, err
+				}
+			} else {
+				found = true
+			}
+			contents = append(contents, content)
+		}
+		return contents, found, nil
+	}
+
+	// if env template not exist, use the default one
+	contents, found, err := getFiles(environment)
+	if err != nil {
+		return nil, err
+	}
+	if !found && environment != common.ApplicationRepoDefaultEnv {
+		contents, _, err = getFiles(common.ApplicationRepoDefaultEnv)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return parseApplicationFiles(contents[0], contents[1], contents[2])
+}
+
+func (g *appGitRepo) HardDeleteApplication(ctx context.Context, application string) error {
+	const op = "git repo: hard delete application"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return g.gitLib.DeleteRepo(ctx, g.repoPID(application))
+}
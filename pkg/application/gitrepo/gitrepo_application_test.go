@@ -18,10 +18,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/horizoncd/horizon/core/common"
+	gitlib "github.com/horizoncd/horizon/lib/git"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/stretchr/testify/assert"
@@ -274,3 +277,65 @@ func TestV2(t *testing.T) {
 	err = r.HardDeleteApplication(ctx, app)
 	assert.Nil(t, err)
 }
+
+func TestGitRepo(t *testing.T) {
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tony",
+	})
+	gitRoot, err := ioutil.TempDir("", "horizon-gitops")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(gitRoot) }()
+
+	gitLib, err := gitlib.New(gitlib.Config{
+		URL:      filepath.Join(gitRoot, "repos"),
+		CacheDir: filepath.Join(gitRoot, "cache"),
+	})
+	assert.Nil(t, err)
+	r := NewApplicationGitRepo(gitLib, ApplicationGitRepoGitConfig{
+		RootPath:      "horizon",
+		DefaultBranch: "master",
+	})
+
+	pipeline := map[string]interface{}{"buildType": "netease-normal"}
+	application := map[string]interface{}{"app": map[string]interface{}{"resource": "x-small"}}
+	err = r.CreateOrUpdateApplication(ctx, app, CreateOrUpdateRequest{
+		Version:      "0.0.2",
+		BuildConf:    pipeline,
+		TemplateConf: application,
+	})
+	assert.Nil(t, err)
+
+	// environments without their own files fall back to the default one
+	resp, err := r.GetApplication(ctx, app, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, pipeline, resp.BuildConf)
+	assert.Equal(t, application, resp.TemplateConf)
+	assert.Equal(t, "0.0.2", resp.Manifest["version"])
+
+	pipeline2 := map[string]interface{}{"buildType": "netease-go"}
+	err = r.CreateOrUpdateApplication(ctx, app, CreateOrUpdateRequest{
+		Environment: "test",
+		BuildConf:   pipeline2,
+	})
+	assert.Nil(t, err)
+	err = r.CreateOrUpdateApplication(ctx, app, CreateOrUpdateRequest{
+		Environment:  "test",
+		BuildConf:    pipeline2,
+		TemplateConf: application,
+	})
+	assert.Nil(t, err)
+	resp, err = r.GetApplication(ctx, app, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, pipeline2, resp.BuildConf)
+	assert.Equal(t, application, resp.TemplateConf)
+	assert.Nil(t, resp.Manifest)
+	resp, err = r.GetApplication(ctx, app, "")
+	assert.Nil(t, err)
+	assert.Equal(t, pipeline, resp.BuildConf)
+
+	err = r.HardDeleteApplication(ctx, app)
+	assert.Nil(t, err)
+	resp, err = r.GetApplication(ctx, app, "")
+	assert.Nil(t, err)
+	assert.Nil(t, resp.BuildConf)
+}
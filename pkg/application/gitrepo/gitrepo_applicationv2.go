@@ -154,67 +154,21 @@ func (g appGitopsRepo) CreateOrUpdateApplication(ctx context.Context,
 	}
 
 	// 3. write files
-	var templateConfYaml, buildConfYaml, manifestYaml []byte
-	if req.TemplateConf != nil {
-		templateConfYaml, err = yaml.Marshal(req.TemplateConf)
-		if err != nil {
-			log.Warningf(ctx, "templateConf marshal error, %v", req.TemplateConf)
-			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
-		}
-	}
-	if req.BuildConf != nil {
-		buildConfYaml, err = yaml.Marshal(req.BuildConf)
-		if err != nil {
-			log.Warningf(ctx, "buildConf marshal error, %v", req.BuildConf)
-			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
-		}
+	files, err := applicationFiles(ctx, req)
+	if err != nil {
+		return err
 	}
-	if req.Version != "" {
-		manifest := pkgcommon.Manifest{Version: req.Version}
-		manifestYaml, err = yaml.Marshal(manifest)
-		if err != nil {
-			log.Warningf(ctx, "Manifest marshal error, %+v", manifest)
-			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
-		}
+	actions := make([]gitlablib.CommitAction, 0, len(files))
+	for _, file := range files {
+		actions = append(actions, gitlablib.CommitAction{
+			Action:   action,
+			FilePath: file.path,
+			Content:  file.content,
+		})
 	}
 
-	actions := func() []gitlablib.CommitAction {
-		actions := make([]gitlablib.CommitAction, 0)
-		if req.BuildConf != nil {
-			actions = append(actions, gitlablib.CommitAction{
-				Action:   action,
-				FilePath: _filePathPipeline,
-				Content:  string(buildConfYaml),
-			})
-		}
-		if req.TemplateConf != nil {
-			actions = append(actions, gitlablib.CommitAction{
-				Action:   action,
-				FilePath: _filePathApplication,
-				Content:  string(templateConfYaml),
-			})
-		}
-		if req.Version != "" {
-			actions = append(actions, gitlablib.CommitAction{
-				Action:   action,
-				FilePath: _filePathManifest,
-				Content:  string(manifestYaml),
-			})
-		}
-		return actions
-	}()
-
-	commitMsg := angular.CommitMessage("application", angular.Subject{
-		Operator:    currentUser.GetName(),
-		Action:      fmt.Sprintf("%s application %s configure", string(action), environmentRepoName),
-		Application: angular.StringPtr(application),
-	}, struct {
-		Application map[string]interface{} `json:"application"`
-		Pipeline    map[string]interface{} `json:"pipeline"`
-	}{
-		Application: req.TemplateConf,
-		Pipeline:    req.BuildConf,
-	})
+	commitMsg := applicationCommitMessage(currentUser.GetName(), string(action),
+		environmentRepoName, application, req)
 	if _, err := g.gitlabLib.WriteFiles(ctx, pid, g.defaultBranch, commitMsg, nil, actions); err != nil {
 		return err
 	}
@@ -254,46 +208,87 @@ func (g appGitopsRepo) GetApplication(ctx context.Context, application, environm
 	}
 
 	// 2. process data
-	res := GetResponse{}
-	TransformData := func(bytes []byte) (map[string]interface{}, error) {
-		var entity map[string]interface{}
-		err = yaml.Unmarshal(bytes, &entity)
+	return parseApplicationFiles(manifestBytes, buildConfBytes, templateConfBytes)
+}
+
+func (g appGitopsRepo) HardDeleteApplication(ctx context.Context, application string) error {
+	const op = "gitlab repo: hard delete application"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	gid := fmt.Sprintf("%v/%v", g.applicationsGroup.FullPath, application)
+	return g.gitlabLib.DeleteGroup(ctx, gid)
+}
+
+type applicationFile struct {
+	path    string
+	content string
+}
+
+// applicationFiles marshals the files of application in request, files which are nil in request are skipped
+func applicationFiles(ctx context.Context, req CreateOrUpdateRequest) ([]applicationFile, error) {
+	files := make([]applicationFile, 0)
+	if req.BuildConf != nil {
+		buildConfYaml, err := yaml.Marshal(req.BuildConf)
 		if err != nil {
+			log.Warningf(ctx, "buildConf marshal error, %v", req.BuildConf)
 			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
 		}
-		return entity, nil
+		files = append(files, applicationFile{path: _filePathPipeline, content: string(buildConfYaml)})
 	}
-
-	if manifestBytes != nil {
-		entity, err := TransformData(manifestBytes)
+	if req.TemplateConf != nil {
+		templateConfYaml, err := yaml.Marshal(req.TemplateConf)
 		if err != nil {
-			return nil, err
+			log.Warningf(ctx, "templateConf marshal error, %v", req.TemplateConf)
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
 		}
-		res.Manifest = entity
+		files = append(files, applicationFile{path: _filePathApplication, content: string(templateConfYaml)})
 	}
-
-	if buildConfBytes != nil {
-		entity, err := TransformData(buildConfBytes)
+	if req.Version != "" {
+		manifest := pkgcommon.Manifest{Version: req.Version}
+		manifestYaml, err := yaml.Marshal(manifest)
 		if err != nil {
-			return nil, err
+			log.Warningf(ctx, "Manifest marshal error, %+v", manifest)
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
 		}
-		res.BuildConf = entity
+		files = append(files, applicationFile{path: _filePathManifest, content: string(manifestYaml)})
 	}
+	return files, nil
+}
 
-	if templateConfBytes != nil {
-		entity, err := TransformData(templateConfBytes)
-		if err != nil {
-			return nil, err
+func applicationCommitMessage(operator, action, environment, application string,
+	req CreateOrUpdateRequest) string {
+	return angular.CommitMessage("application", angular.Subject{
+		Operator:    operator,
+		Action:      fmt.Sprintf("%s application %s configure", action, environment),
+		Application: angular.StringPtr(application),
+	}, struct {
+		Application map[string]interface{} `json:"application"`
+		Pipeline    map[string]interface{} `json:"pipeline"`
+	}{
+		Application: req.TemplateConf,
+		Pipeline:    req.BuildConf,
+	})
+}
+
+// parseApplicationFiles unmarshals the files of application, files which are nil are skipped
+func parseApplicationFiles(manifestBytes, buildConfBytes, templateConfBytes []byte) (*GetResponse, error) {
+	res := GetResponse{}
+	for _, file := range []struct {
+		content []byte
+		target  *map[string]interface{}
+	}{
+		{manifestBytes, &res.Manifest},
+		{buildConfBytes, &res.BuildConf},
+		{templateConfBytes, &res.TemplateConf},
+	} {
+		if file.content == nil {
+			continue
 		}
-		res.TemplateConf = entity
+		var entity map[string]interface{}
+		if err := yaml.Unmarshal(file.content, &entity); err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		*file.target = entity
 	}
 	return &res, nil
 }
-
-func (g appGitopsRepo) HardDeleteApplication(ctx context.Context, application string) error {
-	const op = "gitlab repo: hard delete application"
-	defer wlog.Start(ctx, op).StopPrint()
-
-	gid := fmt.Sprintf("%v/%v", g.applicationsGroup.FullPath, application)
-	return g.gitlabLib.DeleteGroup(ctx, gid)
-}
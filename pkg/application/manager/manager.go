@@ -34,6 +34,8 @@ type Manager interface {
 	GetByIDIncludeSoftDelete(ctx context.Context, id uint) (*models.Application, error)
 	GetByIDs(ctx context.Context, ids []uint) ([]*models.Application, error)
 	GetByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.Application, error)
+	// GetByVisibilityLevel get applications of the given visibility level
+	GetByVisibilityLevel(ctx context.Context, visibilityLevel string) ([]*models.Application, error)
 	GetByName(ctx context.Context, name string) (*models.Application, error)
 	GetByNameFuzzily(ctx context.Context, name string) ([]*models.Application, error)
 	// GetByNameFuzzily get applications that fuzzily matching the given name
@@ -96,6 +98,10 @@ func (m *manager) GetByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models
 	return m.applicationDAO.GetByGroupIDs(ctx, groupIDs)
 }
 
+func (m *manager) GetByVisibilityLevel(ctx context.Context, visibilityLevel string) ([]*models.Application, error) {
+	return m.applicationDAO.GetByVisibilityLevel(ctx, visibilityLevel)
+}
+
 func (m *manager) GetByName(ctx context.Context, name string) (*models.Application, error) {
 	application, err := m.applicationDAO.GetByName(ctx, name)
 	if err != nil {
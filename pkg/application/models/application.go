@@ -39,6 +39,7 @@ type Application struct {
 	GitRefType      string
 	Template        string
 	TemplateRelease string
+	VisibilityLevel string
 	CreatedBy       uint
 	UpdatedBy       uint
 }
@@ -0,0 +1,58 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/approval/models"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	// Create creates an approval, fails if the pipelinerun has been decided
+	Create(ctx context.Context, approval *models.Approval) (*models.Approval, error)
+	// GetByPipelinerunID gets the approval of pipelinerun
+	GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.Approval, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, approval *models.Approval) (*models.Approval, error) {
+	result := d.db.WithContext(ctx).Create(approval)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ApprovalInDB, result.Error.Error())
+	}
+	return approval, nil
+}
+
+func (d *dao) GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.Approval, error) {
+	var approval models.Approval
+	result := d.db.WithContext(ctx).Where("pipelinerun_id = ?", pipelinerunID).First(&approval)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.ApprovalInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.ApprovalInDB, result.Error.Error())
+	}
+	return &approval, nil
+}
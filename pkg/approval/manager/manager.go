@@ -0,0 +1,49 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/approval/dao"
+	"github.com/horizoncd/horizon/pkg/approval/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/approval/manager/manager.go -package=mock_manager
+type Manager interface {
+	// Create creates an approval, fails if the pipelinerun has been decided
+	Create(ctx context.Context, approval *models.Approval) (*models.Approval, error)
+	// GetByPipelinerunID gets the approval of pipelinerun
+	GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.Approval, error)
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context, approval *models.Approval) (*models.Approval, error) {
+	return m.dao.Create(ctx, approval)
+}
+
+func (m *manager) GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.Approval, error) {
+	return m.dao.GetByPipelinerunID(ctx, pipelinerunID)
+}
@@ -0,0 +1,67 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/approval/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.Approval{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByPipelinerunID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	approval, err := mgr.Create(ctx, &models.Approval{
+		PipelinerunID: 1,
+		Decision:      models.DecisionApproved,
+		Comment:       "lgtm",
+	})
+	assert.Nil(t, err)
+
+	ret, err := mgr.GetByPipelinerunID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, approval.ID, ret.ID)
+	assert.Equal(t, models.DecisionApproved, ret.Decision)
+	assert.Equal(t, "lgtm", ret.Comment)
+
+	// a pipelinerun can only be decided once
+	_, err = mgr.Create(ctx, &models.Approval{
+		PipelinerunID: 1,
+		Decision:      models.DecisionRejected,
+	})
+	assert.NotNil(t, err)
+}
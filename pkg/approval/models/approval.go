@@ -0,0 +1,36 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	DecisionApproved = "approved"
+	DecisionRejected = "rejected"
+)
+
+// Approval is the decision on a pipelinerun which is pending approval,
+// each pipelinerun can only be decided once.
+type Approval struct {
+	ID            uint
+	PipelinerunID uint `gorm:"uniqueIndex"`
+	// Decision is approved or rejected
+	Decision  string
+	Comment   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+	UpdatedBy uint
+}
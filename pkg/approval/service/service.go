@@ -0,0 +1,99 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+)
+
+// Service decides which pipelineruns need approval and who can approve them
+//
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/approval/service/service.go -package=mock_service
+type Service interface {
+	// RequireApproval returns whether pipelineruns of the cluster need approval before being executed
+	RequireApproval(ctx context.Context, cluster *clustermodels.Cluster) (bool, error)
+	// CheckApprover checks whether the current user can approve or reject the pipelinerun
+	CheckApprover(ctx context.Context, cluster *clustermodels.Cluster, pipelinerun *prmodels.Pipelinerun) error
+}
+
+type service struct {
+	envMgr    envmanager.Manager
+	memberSvc memberservice.Service
+}
+
+func NewService(memberSvc memberservice.Service, manager *managerparam.Manager) Service {
+	return &service{
+		envMgr:    manager.EnvMgr,
+		memberSvc: memberSvc,
+	}
+}
+
+func (s *service) RequireApproval(ctx context.Context, cluster *clustermodels.Cluster) (bool, error) {
+	env, err := s.envMgr.GetByName(ctx, cluster.EnvironmentName)
+	if err != nil {
+		return false, err
+	}
+	return env.Protected, nil
+}
+
+func (s *service) CheckApprover(ctx context.Context, cluster *clustermodels.Cluster,
+	pipelinerun *prmodels.Pipelinerun) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if currentUser.IsAdmin() {
+		return nil
+	}
+	if pipelinerun.CreatedBy == currentUser.GetID() {
+		return perror.Wrap(herrors.ErrForbidden, "you cannot approve or reject your own pipelinerun")
+	}
+
+	env, err := s.envMgr.GetByName(ctx, cluster.EnvironmentName)
+	if err != nil {
+		return err
+	}
+	for _, user := range env.Approvers.Users {
+		if user == currentUser.GetEmail() {
+			return nil
+		}
+	}
+	if len(env.Approvers.Roles) > 0 {
+		member, err := s.memberSvc.GetMemberOfResource(ctx, common.ResourceCluster,
+			strconv.Itoa(int(cluster.ID)))
+		if err != nil {
+			return err
+		}
+		if member != nil {
+			for _, role := range env.Approvers.Roles {
+				if role == member.Role {
+					return nil
+				}
+			}
+		}
+	}
+	return perror.Wrapf(herrors.ErrForbidden,
+		"you are not an approver of environment %s", cluster.EnvironmentName)
+}
@@ -0,0 +1,99 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/audit/models"
+)
+
+type DAO interface {
+	// Create creates an audit log
+	Create(ctx context.Context, auditLog *models.AuditLog) (*models.AuditLog, error)
+	// List lists audit logs matching the query with paging, the latest first
+	List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int64, error)
+	// ListAfterID lists audit logs matching the query whose id is greater than afterID, in the order of id
+	ListAfterID(ctx context.Context, query *q.Query, afterID uint, limit int) ([]*models.AuditLog, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, auditLog *models.AuditLog) (*models.AuditLog, error) {
+	result := d.db.WithContext(ctx).Create(auditLog)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.AuditLogInDB, result.Error.Error())
+	}
+	return auditLog, nil
+}
+
+func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int64, error) {
+	var (
+		auditLogs []*models.AuditLog
+		total     int64
+	)
+	statement := d.filter(ctx, query)
+	if result := statement.Count(&total); result.Error != nil {
+		return nil, 0, herrors.NewErrListFailed(herrors.AuditLogInDB, result.Error.Error())
+	}
+	result := statement.Order("id desc").Limit(query.Limit()).Offset(query.Offset()).Find(&auditLogs)
+	if result.Error != nil {
+		return nil, 0, herrors.NewErrListFailed(herrors.AuditLogInDB, result.Error.Error())
+	}
+	return auditLogs, total, nil
+}
+
+func (d *dao) ListAfterID(ctx context.Context, query *q.Query, afterID uint,
+	limit int) ([]*models.AuditLog, error) {
+	var auditLogs []*models.AuditLog
+	result := d.filter(ctx, query).Where("id > ?", afterID).Order("id").Limit(limit).Find(&auditLogs)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.AuditLogInDB, result.Error.Error())
+	}
+	return auditLogs, nil
+}
+
+func (d *dao) filter(ctx context.Context, query *q.Query) *gorm.DB {
+	statement := d.db.WithContext(ctx).Model(&models.AuditLog{})
+	if query == nil {
+		return statement
+	}
+	for k, v := range query.Keywords {
+		switch k {
+		case common.AuditLogQueryUserID:
+			statement = statement.Where("user_id = ?", v)
+		case common.AuditLogQueryResource:
+			statement = statement.Where("resource = ?", v)
+		case common.AuditLogQueryResourceName:
+			statement = statement.Where("resource_name = ?", v)
+		case common.AuditLogQueryStartTime:
+			statement = statement.Where("created_at >= ?", v)
+		case common.AuditLogQueryEndTime:
+			statement = statement.Where("created_at < ?", v)
+		}
+	}
+	return statement
+}
@@ -0,0 +1,58 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/audit/dao"
+	"github.com/horizoncd/horizon/pkg/audit/models"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/audit/manager/manager.go -package=mock_manager
+type Manager interface {
+	// Create creates an audit log
+	Create(ctx context.Context, auditLog *models.AuditLog) (*models.AuditLog, error)
+	// List lists audit logs matching the query with paging, the latest first
+	List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int64, error)
+	// ListAfterID lists audit logs matching the query whose id is greater than afterID, in the order of id
+	ListAfterID(ctx context.Context, query *q.Query, afterID uint, limit int) ([]*models.AuditLog, error)
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context, auditLog *models.AuditLog) (*models.AuditLog, error) {
+	return m.dao.Create(ctx, auditLog)
+}
+
+func (m *manager) List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int64, error) {
+	return m.dao.List(ctx, query)
+}
+
+func (m *manager) ListAfterID(ctx context.Context, query *q.Query, afterID uint,
+	limit int) ([]*models.AuditLog, error) {
+	return m.dao.ListAfterID(ctx, query, afterID, limit)
+}
@@ -0,0 +1,90 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/audit/models"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	now := time.Now()
+	for i, auditLog := range []*models.AuditLog{
+		{UserID: 1, Resource: "applications", ResourceName: "1", Verb: "update"},
+		{UserID: 1, Resource: "clusters", ResourceName: "2", Verb: "create"},
+		{UserID: 2, Resource: "applications", ResourceName: "1", Verb: "delete"},
+	} {
+		auditLog.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		_, err := mgr.Create(ctx, auditLog)
+		assert.Nil(t, err)
+	}
+
+	auditLogs, total, err := mgr.List(ctx, q.New(q.KeyWords{common.AuditLogQueryUserID: uint(1)}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "clusters", auditLogs[0].Resource)
+
+	auditLogs, total, err = mgr.List(ctx, q.New(q.KeyWords{
+		common.AuditLogQueryResource:     "applications",
+		common.AuditLogQueryResourceName: "1",
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "delete", auditLogs[0].Verb)
+
+	_, total, err = mgr.List(ctx, q.New(q.KeyWords{
+		common.AuditLogQueryStartTime: now.Add(30 * time.Second),
+		common.AuditLogQueryEndTime:   now.Add(90 * time.Second),
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+
+	query := &q.Query{PageNumber: 1, PageSize: 2}
+	auditLogs, total, err = mgr.List(ctx, query)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, 2, len(auditLogs))
+
+	auditLogs, err = mgr.ListAfterID(ctx, q.New(nil), 0, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(auditLogs))
+	auditLogs, err = mgr.ListAfterID(ctx, q.New(nil), auditLogs[1].ID, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(auditLogs))
+	assert.Equal(t, uint(2), auditLogs[0].UserID)
+}
@@ -0,0 +1,48 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	AuthMethodNone        = "none"
+	AuthMethodSession     = "session"
+	AuthMethodAccessToken = "accesstoken"
+	AuthMethodOAuthApp    = "oauthapp"
+	AuthMethodSignature   = "signature"
+)
+
+// AuditLog records a mutating api call
+type AuditLog struct {
+	ID         uint
+	RequestID  string
+	UserID     uint
+	UserName   string
+	AuthMethod string
+	// Method and Path are the http method and path of the request
+	Method string
+	Path   string
+	// Verb, Resource, ResourceName and SubResource are parsed from the path as in rbac
+	Verb         string
+	Resource     string
+	ResourceName string
+	SubResource  string
+	// RequestBody is the request body with sensitive fields redacted
+	RequestBody string
+	// Diff is the json of fields in request body which differ from the resource before the request
+	Diff       string
+	StatusCode int
+	CreatedAt  time.Time
+}
@@ -0,0 +1,184 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"text/template"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/canary/models"
+	canaryconfig "github.com/horizoncd/horizon/pkg/config/canary"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/prometheus"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	// PhaseHealthy means all rules are healthy, the rollout can be promoted
+	PhaseHealthy = "healthy"
+	// PhaseBreached means at least one rule is breached, the rollout should be aborted
+	PhaseBreached = "breached"
+	// PhaseInconclusive means some rules have no data yet, wait for the next analysis
+	PhaseInconclusive = "inconclusive"
+)
+
+// QueryVars are the variables which can be referenced in the query of canary rule
+type QueryVars struct {
+	Application string
+	Cluster     string
+	Environment string
+	Region      string
+}
+
+// Result is the analysis result of a canary rule
+type Result struct {
+	Name      string   `json:"name"`
+	Query     string   `json:"query"`
+	Operator  string   `json:"operator"`
+	Threshold float64  `json:"threshold"`
+	Value     *float64 `json:"value,omitempty"`
+	Phase     string   `json:"phase"`
+}
+
+// Verdict is the analysis result of all canary rules of cluster
+type Verdict struct {
+	Phase   string    `json:"phase"`
+	Results []*Result `json:"results"`
+}
+
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/canary/analysis/analysis.go -package=mock_analysis
+type Analyzer interface {
+	// Analyze checks rules against the prometheus at prometheusURL
+	Analyze(ctx context.Context, prometheusURL string,
+		rules []*models.CanaryRule, vars *QueryVars) (*Verdict, error)
+}
+
+type analyzer struct {
+	client         *prometheus.Client
+	defaultQueries map[string]string
+}
+
+func NewAnalyzer(config *canaryconfig.Config) Analyzer {
+	return &analyzer{
+		client:         prometheus.NewClient(config.QueryTimeout),
+		defaultQueries: config.DefaultQueries,
+	}
+}
+
+func (a *analyzer) Analyze(ctx context.Context, prometheusURL string,
+	rules []*models.CanaryRule, vars *QueryVars) (*Verdict, error) {
+	const op = "canary analyzer: analyze"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if prometheusURL == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "prometheus url of region is empty")
+	}
+
+	verdict := &Verdict{Phase: PhaseHealthy}
+	for _, rule := range rules {
+		result, err := a.analyzeRule(ctx, prometheusURL, rule, vars)
+		if err != nil {
+			return nil, err
+		}
+		verdict.Results = append(verdict.Results, result)
+		switch result.Phase {
+		case PhaseBreached:
+			verdict.Phase = PhaseBreached
+		case PhaseInconclusive:
+			if verdict.Phase == PhaseHealthy {
+				verdict.Phase = PhaseInconclusive
+			}
+		}
+	}
+	return verdict, nil
+}
+
+func (a *analyzer) analyzeRule(ctx context.Context, prometheusURL string,
+	rule *models.CanaryRule, vars *QueryVars) (*Result, error) {
+	queryTemplate := rule.Query
+	if queryTemplate == "" {
+		queryTemplate = a.defaultQueries[rule.Type]
+	}
+	if queryTemplate == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"query of canary rule %v is empty and no default query for type %v", rule.Name, rule.Type)
+	}
+	query, err := RenderQuery(rule.Name, queryTemplate, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Name:      rule.Name,
+		Query:     query,
+		Operator:  rule.Operator,
+		Threshold: rule.Threshold,
+		Phase:     PhaseInconclusive,
+	}
+	values, err := a.client.Query(ctx, prometheusURL, query, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return result, nil
+	}
+
+	// take the worst value when the query returns more than one series
+	value := values[0]
+	for _, v := range values[1:] {
+		if (rule.Operator == models.OperatorLessThan && v > value) ||
+			(rule.Operator == models.OperatorGreaterThan && v < value) {
+			value = v
+		}
+	}
+	if math.IsNaN(value) {
+		return result, nil
+	}
+	result.Value = &value
+
+	healthy := false
+	switch rule.Operator {
+	case models.OperatorLessThan:
+		healthy = value < rule.Threshold
+	case models.OperatorGreaterThan:
+		healthy = value > rule.Threshold
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"canary rule operator %v is not supported", rule.Operator)
+	}
+	if healthy {
+		result.Phase = PhaseHealthy
+	} else {
+		result.Phase = PhaseBreached
+	}
+	return result, nil
+}
+
+// RenderQuery renders the query template of canary rule with vars
+func RenderQuery(name, query string, vars *QueryVars) (string, error) {
+	tpl, err := template.New(name).Option("missingkey=error").Parse(query)
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "query of canary rule %v is invalid: %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, vars); err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "failed to render query of canary rule %v: %v", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,131 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/horizoncd/horizon/pkg/canary/models"
+	canaryconfig "github.com/horizoncd/horizon/pkg/config/canary"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakePrometheus returns a fake prometheus server, results is the result of each query
+func newFakePrometheus(t *testing.T, results map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query", r.URL.Path)
+		result, ok := results[r.URL.Query().Get("query")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"unknown query"}`))
+			return
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"status":"success","data":{"resultType":"vector","result":%s}}`,
+			result)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAnalyze(t *testing.T) {
+	ctx := context.Background()
+	server := newFakePrometheus(t, map[string]string{
+		`error_rate{cluster="c1"}`: `[{"metric":{"pod":"a"},"value":[1680000000,"0.01"]},` +
+			`{"metric":{"pod":"b"},"value":[1680000000,"0.02"]}]`,
+		`latency{cluster="c1"}`:   `[{"metric":{},"value":[1680000000,"350"]}]`,
+		`qps{cluster="c1"}`:       `[]`,
+		`qps{cluster="c1",a="1"}`: `[{"metric":{},"value":[1680000000,"NaN"]}]`,
+	})
+	a := NewAnalyzer(&canaryconfig.Config{
+		DefaultQueries: map[string]string{
+			models.RuleTypeErrorRate: `error_rate{cluster="{{.Cluster}}"}`,
+		},
+	})
+	vars := &QueryVars{Cluster: "c1"}
+
+	errorRate := &models.CanaryRule{
+		Name:      "errorRate",
+		Type:      models.RuleTypeErrorRate,
+		Operator:  models.OperatorLessThan,
+		Threshold: 0.05,
+	}
+	latency := &models.CanaryRule{
+		Name:      "latency",
+		Type:      models.RuleTypeLatency,
+		Query:     `latency{cluster="{{.Cluster}}"}`,
+		Operator:  models.OperatorLessThan,
+		Threshold: 300,
+	}
+	noData := &models.CanaryRule{
+		Name:      "qps",
+		Type:      models.RuleTypeQuery,
+		Query:     `qps{cluster="{{.Cluster}}"}`,
+		Operator:  models.OperatorGreaterThan,
+		Threshold: 1,
+	}
+	nan := &models.CanaryRule{
+		Name:      "nan",
+		Type:      models.RuleTypeQuery,
+		Query:     `qps{cluster="{{.Cluster}}",a="1"}`,
+		Operator:  models.OperatorGreaterThan,
+		Threshold: 1,
+	}
+
+	// healthy
+	verdict, err := a.Analyze(ctx, server.URL, []*models.CanaryRule{errorRate}, vars)
+	assert.Nil(t, err)
+	assert.Equal(t, PhaseHealthy, verdict.Phase)
+	assert.Equal(t, `error_rate{cluster="c1"}`, verdict.Results[0].Query)
+	// the worst value is taken
+	assert.Equal(t, 0.02, *verdict.Results[0].Value)
+
+	// inconclusive
+	verdict, err = a.Analyze(ctx, server.URL, []*models.CanaryRule{errorRate, noData, nan}, vars)
+	assert.Nil(t, err)
+	assert.Equal(t, PhaseInconclusive, verdict.Phase)
+	assert.Nil(t, verdict.Results[1].Value)
+	assert.Equal(t, PhaseInconclusive, verdict.Results[2].Phase)
+
+	// breached
+	verdict, err = a.Analyze(ctx, server.URL, []*models.CanaryRule{errorRate, noData, latency}, vars)
+	assert.Nil(t, err)
+	assert.Equal(t, PhaseBreached, verdict.Phase)
+	assert.Equal(t, PhaseBreached, verdict.Results[2].Phase)
+
+	// query error
+	_, err = a.Analyze(ctx, server.URL, []*models.CanaryRule{{
+		Name:     "unknown",
+		Type:     models.RuleTypeQuery,
+		Query:    "unknown",
+		Operator: models.OperatorLessThan,
+	}}, vars)
+	assert.NotNil(t, err)
+
+	// no default query
+	_, err = a.Analyze(ctx, server.URL, []*models.CanaryRule{{
+		Name:     "latency",
+		Type:     models.RuleTypeLatency,
+		Operator: models.OperatorLessThan,
+	}}, vars)
+	assert.NotNil(t, err)
+
+	// no prometheus
+	_, err = a.Analyze(ctx, "", []*models.CanaryRule{errorRate}, vars)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"text/template"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/canary/dao"
+	"github.com/horizoncd/horizon/pkg/canary/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/canary/manager/manager.go -package=mock_manager
+type Manager interface {
+	// ListByClusterID lists canary rules of cluster
+	ListByClusterID(ctx context.Context, clusterID uint) ([]*models.CanaryRule, error)
+	// ListClusterIDs lists ids of clusters which have canary rules
+	ListClusterIDs(ctx context.Context) ([]uint, error)
+	// UpdateByClusterID replaces all canary rules of cluster with rules
+	UpdateByClusterID(ctx context.Context, clusterID uint, rules []*models.CanaryRule) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.CanaryRule, error) {
+	return m.dao.ListByClusterID(ctx, clusterID)
+}
+
+func (m *manager) ListClusterIDs(ctx context.Context) ([]uint, error) {
+	return m.dao.ListClusterIDs(ctx)
+}
+
+func (m *manager) UpdateByClusterID(ctx context.Context, clusterID uint, rules []*models.CanaryRule) error {
+	return m.dao.UpdateByClusterID(ctx, clusterID, rules)
+}
+
+// ValidateUpdate validates canary rules before update
+func ValidateUpdate(rules []*models.CanaryRule) error {
+	if len(rules) > 10 {
+		return perror.Wrap(herrors.ErrParamInvalid, "the count of canary rules must be less than 10")
+	}
+	names := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		if len(rule.Name) == 0 || len(rule.Name) > 64 {
+			return perror.Wrap(herrors.ErrParamInvalid, "canary rule name must be 1 to 64 characters")
+		}
+		if _, ok := names[rule.Name]; ok {
+			return perror.Wrapf(herrors.ErrParamInvalid, "canary rule name %v is duplicated", rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+
+		switch rule.Type {
+		case models.RuleTypeErrorRate, models.RuleTypeLatency, models.RuleTypeQuery:
+		default:
+			return perror.Wrapf(herrors.ErrParamInvalid, "canary rule type %v is not supported", rule.Type)
+		}
+		switch rule.Operator {
+		case models.OperatorLessThan, models.OperatorGreaterThan:
+		default:
+			return perror.Wrapf(herrors.ErrParamInvalid, "canary rule operator %v is not supported", rule.Operator)
+		}
+		// query of errorRate and latency can be empty, the default query in config will be used
+		if len(rule.Query) == 0 && rule.Type == models.RuleTypeQuery {
+			return perror.Wrapf(herrors.ErrParamInvalid, "query of canary rule %v cannot be empty", rule.Name)
+		}
+		if _, err := template.New(rule.Name).Parse(rule.Query); err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "query of canary rule %v is invalid: %v", rule.Name, err)
+		}
+	}
+	return nil
+}
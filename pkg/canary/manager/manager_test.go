@@ -0,0 +1,88 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/canary/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.CanaryRule{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	rules := []*models.CanaryRule{
+		{
+			Name:      "errorRate",
+			Type:      models.RuleTypeErrorRate,
+			Operator:  models.OperatorLessThan,
+			Threshold: 0.01,
+		}, {
+			Name:      "qps",
+			Type:      models.RuleTypeQuery,
+			Query:     `sum(rate(http_requests_total{cluster="{{.Cluster}}"}[1m]))`,
+			Operator:  models.OperatorGreaterThan,
+			Threshold: 10,
+		},
+	}
+	assert.Nil(t, ValidateUpdate(rules))
+	assert.Nil(t, mgr.UpdateByClusterID(ctx, 1, rules))
+	assert.Nil(t, mgr.UpdateByClusterID(ctx, 2, rules[:1]))
+
+	ret, err := mgr.ListByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ret))
+	assert.Equal(t, "qps", ret[1].Name)
+
+	clusterIDs, err := mgr.ListClusterIDs(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{1, 2}, clusterIDs)
+
+	assert.Nil(t, mgr.UpdateByClusterID(ctx, 1, nil))
+	ret, err = mgr.ListByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ret))
+	clusterIDs, err = mgr.ListClusterIDs(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{2}, clusterIDs)
+
+	// invalid rules
+	for _, rule := range []*models.CanaryRule{
+		{Name: "", Type: models.RuleTypeErrorRate, Operator: models.OperatorLessThan},
+		{Name: "a", Type: "unknown", Operator: models.OperatorLessThan},
+		{Name: "a", Type: models.RuleTypeErrorRate, Operator: "="},
+		{Name: "a", Type: models.RuleTypeQuery, Operator: models.OperatorLessThan},
+		{Name: "a", Type: models.RuleTypeQuery, Query: "{{.Cluster", Operator: models.OperatorLessThan},
+	} {
+		assert.NotNil(t, ValidateUpdate([]*models.CanaryRule{rule}))
+	}
+	assert.NotNil(t, ValidateUpdate([]*models.CanaryRule{rules[0], rules[0]}))
+}
@@ -0,0 +1,50 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	// RuleTypeErrorRate checks the error rate of canary pods
+	RuleTypeErrorRate = "errorRate"
+	// RuleTypeLatency checks the latency of canary pods
+	RuleTypeLatency = "latency"
+	// RuleTypeQuery checks the value of a custom PromQL query
+	RuleTypeQuery = "query"
+
+	// OperatorLessThan means the rule is healthy when value < threshold
+	OperatorLessThan = "<"
+	// OperatorGreaterThan means the rule is healthy when value > threshold
+	OperatorGreaterThan = ">"
+)
+
+// CanaryRule is an analysis rule of cluster, it is checked against
+// the prometheus of cluster's region each time the rollout pauses at a step.
+type CanaryRule struct {
+	ID        uint
+	ClusterID uint
+	Name      string
+	Type      string
+	// Query is a PromQL query in go template format,
+	// {{.Application}}, {{.Cluster}}, {{.Environment}} and {{.Region}} are available.
+	// If it is empty, the default query of Type in canary config is used.
+	Query     string
+	Operator  string
+	Threshold float64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+	UpdatedBy uint
+}
@@ -21,6 +21,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 	"sync"
 
 	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
@@ -38,13 +40,16 @@ import (
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 	"github.com/horizoncd/horizon/pkg/workload"
+	"github.com/horizoncd/horizon/pkg/workload/cronjob"
 	"github.com/horizoncd/horizon/pkg/workload/getter"
+	"github.com/horizoncd/horizon/pkg/workload/kruise"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
 )
 
 const (
@@ -52,6 +57,8 @@ const (
 	DeploymentPodTemplateHash = "pod-template-hash"
 	_rolloutRevision          = "rollout.argoproj.io/revision"
 	RolloutPodTemplateHash    = "rollouts-pod-template-hash"
+	// ControllerRevisionHash is the revision label of pods managed by OpenKruise CloneSet or Advanced StatefulSet
+	ControllerRevisionHash = appsv1.ControllerRevisionHashLabelKey
 )
 
 const (
@@ -85,6 +92,10 @@ type CD interface {
 	GetResourceTree(ctx context.Context, params *GetResourceTreeParams) ([]ResourceNode, error)
 	GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 	GetPodEvents(ctx context.Context, params *GetPodEventsParams) ([]Event, error)
+	// TriggerCronJob creates a job from the cronjob of the cluster immediately
+	TriggerCronJob(ctx context.Context, params *CronJobParams) (*CronJobRun, error)
+	// ListCronJobRuns lists jobs created by the cronjobs of the cluster, the latest first
+	ListCronJobRuns(ctx context.Context, params *CronJobParams) ([]*CronJobRun, error)
 }
 
 type cd struct {
@@ -295,9 +306,97 @@ func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 		Replicas:     step.Replicas,
 		ManualPaused: step.ManualPaused,
 		AutoPromote:  step.AutoPromote,
+		StepPaused:   step.StepPaused,
+		StepPausedAt: step.StepPausedAt,
 	}, nil
 }
 
+func (c *cd) TriggerCronJob(ctx context.Context, params *CronJobParams) (*CronJobRun, error) {
+	const op = "cd: trigger cronjob"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	nodes, kubeClient, err := c.getCronJobNodes(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	var node *applicationV1alpha1.ResourceNode
+	for i := range nodes {
+		if params.CronJob == "" || nodes[i].Name == params.CronJob {
+			if node != nil {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid,
+					"cluster %s has multiple cronjobs, please specify one", params.Cluster)
+			}
+			node = &nodes[i]
+		}
+	}
+	if node == nil {
+		return nil, herrors.NewErrNotFound(herrors.ResourceInK8S,
+			fmt.Sprintf("cronjob %s not found in cluster %s", params.CronJob, params.Cluster))
+	}
+
+	job, err := cronjob.Trigger(node, kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	return ofCronJobRun(node.Name, job), nil
+}
+
+func (c *cd) ListCronJobRuns(ctx context.Context, params *CronJobParams) ([]*CronJobRun, error) {
+	const op = "cd: list cronjob runs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	nodes, kubeClient, err := c.getCronJobNodes(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*CronJobRun, 0)
+	for i := range nodes {
+		if params.CronJob != "" && nodes[i].Name != params.CronJob {
+			continue
+		}
+		jobs, err := cronjob.ListJobs(&nodes[i], kubeClient)
+		if err != nil {
+			return nil, err
+		}
+		for j := range jobs {
+			runs = append(runs, ofCronJobRun(nodes[i].Name, &jobs[j]))
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].CreatedAt.After(runs[j].CreatedAt)
+	})
+	return runs, nil
+}
+
+// getCronJobNodes gets cronjobs of the cluster from its resource tree in argo
+func (c *cd) getCronJobNodes(ctx context.Context,
+	params *CronJobParams) ([]applicationV1alpha1.ResourceNode, *kube.Client, error) {
+	argo, err := c.factory.GetArgoCD(params.Environment)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, kubeClient, err := c.kubeClientFty.GetByK8SServer(params.RegionEntity.Server, params.RegionEntity.Certificate)
+	if err != nil {
+		return nil, nil, err
+	}
+	resourceTreeInArgo, err := argo.GetApplicationTree(ctx, params.Cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes := make([]applicationV1alpha1.ResourceNode, 0)
+	for _, node := range resourceTreeInArgo.Nodes {
+		if node.Group == "batch" && node.Kind == "CronJob" {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, nil, herrors.NewErrNotFound(herrors.ResourceInK8S,
+			fmt.Sprintf("no cronjob found in cluster %s", params.Cluster))
+	}
+	return nodes, kubeClient, nil
+}
+
 // GetClusterState fetches status of cluster
 func (c *cd) GetClusterState(ctx context.Context,
 	params *GetClusterStateV2Params) (*ClusterStateV2, error) {
@@ -322,7 +421,22 @@ func (c *cd) GetClusterState(ctx context.Context,
 	}
 
 	status := &ClusterStateV2{
-		Status: string(argoApp.Status.Health.Status),
+		Status:     string(argoApp.Status.Health.Status),
+		SyncStatus: string(argoApp.Status.Sync.Status),
+		Revision:   argoApp.Status.Sync.Revision,
+	}
+	for _, resource := range argoApp.Status.Resources {
+		if resource.Status != applicationV1alpha1.SyncStatusCodeOutOfSync {
+			continue
+		}
+		status.OutOfSyncResources = append(status.OutOfSyncResources, OutOfSyncResource{
+			Group:           resource.Group,
+			Version:         resource.Version,
+			Kind:            resource.Kind,
+			Namespace:       resource.Namespace,
+			Name:            resource.Name,
+			RequiresPruning: resource.RequiresPruning,
+		})
 	}
 
 	if status.Status != string(health.HealthStatusHealthy) {
@@ -444,6 +558,17 @@ func (c *cd) GetClusterStateV1(ctx context.Context,
 			if err != nil {
 				return nil, err
 			}
+			// kruise workloads are updated in place, revisions are tracked by ControllerRevisions
+			if node := kruiseWorkloadNode(resourceTree); node != nil {
+				if err := fillKruiseClusterState(ctx, kubeClient, node, clusterState); err != nil {
+					return nil, err
+				}
+				if err := c.paddingPodAndEventInfo(ctx, params.Cluster, namespace,
+					kubeClient.Basic, clusterState); err != nil {
+					return nil, err
+				}
+				return clusterState, nil
+			}
 			// application with deployment may be serverless
 			if !resourceTreeContains(resourceTree, kubeutil.DeploymentKind) {
 				allPods, err := kube.GetPods(ctx, kubeClient.Basic, namespace, labelSelector.String())
@@ -605,6 +730,59 @@ func (c *cd) GetPodEvents(ctx context.Context,
 	return nil, herrors.NewErrNotFound(herrors.PodsInK8S, "pod does not exist")
 }
 
+// fillKruiseClusterState sets revisions of OpenKruise CloneSet or Advanced StatefulSet like ReplicaSets,
+// the label controller-revision-hash of pods is changed by in-place updates
+func fillKruiseClusterState(ctx context.Context, kubeClient *kube.Client,
+	node *applicationV1alpha1.ResourceNode, clusterState *ClusterState) error {
+	instance, err := kruise.GetByNode(node, kubeClient)
+	if err != nil {
+		return err
+	}
+	if instance.UpdateRevision == "" {
+		return herrors.NewErrNotFound(herrors.ClusterStateInArgo, "clusterState.PodTemplateHash == ''")
+	}
+
+	revisions, err := kubeClient.Basic.AppsV1().ControllerRevisions(instance.GetNamespace()).
+		List(ctx, metav1.ListOptions{LabelSelector: polymorphichelpers.MakeLabels(instance.Selector)})
+	if err != nil {
+		return perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S, "failed to list controllerrevisions in k8s"),
+			"failed to list controllerrevisions of %s %s: err = %v", node.Kind, node.Name, err)
+	}
+	for i := range revisions.Items {
+		revision := &revisions.Items[i]
+		if !metav1.IsControlledBy(revision, instance) {
+			continue
+		}
+		clusterState.Versions[revision.Name] = &ClusterVersion{
+			Pods:     map[string]*ClusterPod{},
+			Revision: strconv.FormatInt(revision.Revision, 10),
+		}
+	}
+	if clusterState.Versions[instance.UpdateRevision] == nil {
+		clusterState.Versions[instance.UpdateRevision] = &ClusterVersion{Pods: map[string]*ClusterPod{}}
+	}
+
+	clusterState.PodTemplateHashKey = ControllerRevisionHash
+	clusterState.PodTemplateHash = instance.UpdateRevision
+	clusterState.Revision = clusterState.Versions[instance.UpdateRevision].Revision
+	desiredReplicas := instance.Replicas
+	clusterState.DesiredReplicas = &desiredReplicas
+	clusterState.ManualPaused = instance.Paused
+	step := instance.Step()
+	clusterState.Step = &Step{
+		Index:        step.Index,
+		Total:        step.Total,
+		Replicas:     step.Replicas,
+		ManualPaused: step.ManualPaused,
+		StepPaused:   step.StepPaused,
+	}
+	if instance.GetGeneration() > instance.ObservedGeneration {
+		clusterState.Status = health.HealthStatusProgressing
+	}
+	return nil
+}
+
 // Deprecated
 func (c *cd) paddingPodAndEventInfo(ctx context.Context, cluster, namespace string,
 	kubeClient kubernetes.Interface, clusterState *ClusterState) error {
@@ -16,6 +16,7 @@ package cd
 
 import (
 	"context"
+	"time"
 
 	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/gitops-engine/pkg/health"
@@ -32,6 +33,14 @@ type GetStepParams struct {
 	RegionEntity *regionmodels.RegionEntity
 }
 
+type CronJobParams struct {
+	Environment  string
+	Cluster      string
+	RegionEntity *regionmodels.RegionEntity
+	// CronJob is the name of cronjob, it could be omitted if the cluster has only one cronjob
+	CronJob string
+}
+
 type GetClusterStateParams struct {
 	Environment  string
 	Cluster      string
@@ -208,6 +217,23 @@ type Revision struct {
 
 type ClusterStateV2 struct {
 	Status string `json:"status"`
+	// SyncStatus is the sync status of the cluster in argo, such as Synced and OutOfSync
+	SyncStatus string `json:"syncStatus,omitempty"`
+	// Revision is the gitops commit which the cluster is synced to
+	Revision string `json:"revision,omitempty"`
+	// OutOfSyncResources are the resources differing from the gitops repo,
+	// which are changed by the gitops repo or edited in k8s manually
+	OutOfSyncResources []OutOfSyncResource `json:"outOfSyncResources,omitempty"`
+}
+
+type OutOfSyncResource struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// RequiresPruning indicates the resource is not in the gitops repo any more
+	RequiresPruning bool `json:"requiresPruning,omitempty"`
 }
 
 // ClusterState cluster state
@@ -253,6 +279,9 @@ type Step struct {
 	Replicas     []int `json:"replicas"`
 	ManualPaused bool  `json:"manualPaused"`
 	AutoPromote  bool  `json:"autoPromote"`
+	StepPaused   bool  `json:"stepPaused"`
+	// StepPausedAt is the time when the cluster is paused by the pause step
+	StepPausedAt *time.Time `json:"stepPausedAt,omitempty"`
 }
 
 // ClusterVersion version information
@@ -469,3 +498,23 @@ func ContainerCompact(container corev1.Container) CompactContainer {
 		ReadinessProbe: container.ReadinessProbe,
 	}
 }
+
+const (
+	CronJobRunStatusRunning   = "Running"
+	CronJobRunStatusSucceeded = "Succeeded"
+	CronJobRunStatusFailed    = "Failed"
+)
+
+// CronJobRun is a job created by cronjob on schedule or triggered manually
+type CronJobRun struct {
+	Name           string     `json:"name"`
+	CronJob        string     `json:"cronJob"`
+	Manual         bool       `json:"manual"`
+	Status         string     `json:"status"`
+	Active         int32      `json:"active"`
+	Succeeded      int32      `json:"succeeded"`
+	Failed         int32      `json:"failed"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	StartTime      *time.Time `json:"startTime,omitempty"`
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+}
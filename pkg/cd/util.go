@@ -29,15 +29,31 @@ import (
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/pkg/util/kube"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/workload/cronjob"
+	workloadjob "github.com/horizoncd/horizon/pkg/workload/job"
+	"github.com/horizoncd/horizon/pkg/workload/kruise"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/kubectl/pkg/cmd/exec"
 	"k8s.io/kubectl/pkg/describe"
 	kubectlresource "k8s.io/kubectl/pkg/util/resource"
 )
 
+// kruiseWorkloadNode returns the node of OpenKruise CloneSet or Advanced StatefulSet in resourceTree
+func kruiseWorkloadNode(resourceTree *applicationV1alpha1.ApplicationTree) *applicationV1alpha1.ResourceNode {
+	for i := range resourceTree.Nodes {
+		node := &resourceTree.Nodes[i]
+		if kruise.MatchKruiseWorkload(schema.GroupKind{Group: node.Group, Kind: node.Kind}) {
+			return node
+		}
+	}
+	return nil
+}
+
 func resourceTreeContains(resourceTree *applicationV1alpha1.ApplicationTree, resourceKind string) bool {
 	for _, node := range resourceTree.Nodes {
 		if node.Kind == resourceKind {
@@ -49,17 +65,17 @@ func resourceTreeContains(resourceTree *applicationV1alpha1.ApplicationTree, res
 
 func parsePod(ctx context.Context, clusterInfo *ClusterState,
 	pod *corev1.Pod, events []*corev1.Event) (err error) {
-	const deploymentPodTemplateHash = "pod-template-hash"
-	const rolloutPodTemplateHash = "rollouts-pod-template-hash"
-
-	podTemplateHash := pod.Labels[deploymentPodTemplateHash]
+	podTemplateHash := pod.Labels[clusterInfo.PodTemplateHashKey]
+	if podTemplateHash == "" {
+		podTemplateHash = pod.Labels[DeploymentPodTemplateHash]
+	}
 	if podTemplateHash == "" {
-		podTemplateHash = pod.Labels[rolloutPodTemplateHash]
+		podTemplateHash = pod.Labels[RolloutPodTemplateHash]
 	}
 
 	if podTemplateHash == "" {
-		log.Errorf(ctx, "pod<%s> has no %v or %v label",
-			pod.Name, deploymentPodTemplateHash, rolloutPodTemplateHash)
+		log.Errorf(ctx, "pod<%s> has no %v, %v or %v label", pod.Name,
+			clusterInfo.PodTemplateHashKey, DeploymentPodTemplateHash, RolloutPodTemplateHash)
 		return nil
 	}
 
@@ -693,3 +709,28 @@ func extractEnv(pod *corev1.Pod, container corev1.Container) []corev1.EnvVar {
 	}
 	return env
 }
+
+func ofCronJobRun(cronJob string, job *batchv1.Job) *CronJobRun {
+	run := &CronJobRun{
+		Name:      job.Name,
+		CronJob:   cronJob,
+		Manual:    cronjob.IsManual(job),
+		Status:    CronJobRunStatusRunning,
+		Active:    job.Status.Active,
+		Succeeded: job.Status.Succeeded,
+		Failed:    job.Status.Failed,
+		CreatedAt: job.CreationTimestamp.Time,
+	}
+	if workloadjob.IsComplete(job) {
+		run.Status = CronJobRunStatusSucceeded
+	} else if workloadjob.IsFailed(job) {
+		run.Status = CronJobRunStatusFailed
+	}
+	if job.Status.StartTime != nil {
+		run.StartTime = &job.Status.StartTime.Time
+	}
+	if job.Status.CompletionTime != nil {
+		run.CompletionTime = &job.Status.CompletionTime.Time
+	}
+	return run
+}
@@ -268,6 +268,14 @@ func (d *dao) List(ctx context.Context, query *q.Query, userID uint,
 				statement = statement.Where("c.template_release = ?", v)
 			case common.ClusterQueryByRegion:
 				statement = statement.Where("c.region_name = ?", v)
+			case common.ClusterQueryExcludeApplications:
+				statement = statement.Where("c.application_id not in ?", v)
+			case common.ClusterQueryExcludeGroups:
+				statement = statement.Where("c.application_id not in (?)",
+					d.db.Table("tb_application").Select("id").Where("group_id in ?", v))
+			case common.ClusterQueryByGroups:
+				statement = statement.Where("c.application_id in (?)",
+					d.db.Table("tb_application").Select("id").Where("group_id in ?", v).Where("deleted_ts = 0"))
 			case common.ClusterQueryIsFavorite:
 				isFavoriteInter := query.Keywords[common.ClusterQueryIsFavorite]
 				isFavorite := isFavoriteInter.(bool)
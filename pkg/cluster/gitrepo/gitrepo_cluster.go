@@ -25,6 +25,7 @@ import (
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	gitlib "github.com/horizoncd/horizon/lib/git"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
 	"github.com/horizoncd/horizon/pkg/application/models"
 	pkgcommon "github.com/horizoncd/horizon/pkg/common"
@@ -71,6 +72,9 @@ type CreateClusterParams struct {
 
 type UpdateClusterParams struct {
 	*BaseParams
+	// SourceTemplate is the template values of cluster are keyed by before the update,
+	// values files not rewritten by the update are re-keyed if the template is switched
+	SourceTemplate string
 }
 
 type RepoInfo struct {
@@ -108,6 +112,18 @@ type UpgradeValuesParam struct {
 	BuildConfig   *template.BuildConfig
 }
 
+// RenderFiles are files in cluster repo which are required to render manifests of the cluster
+type RenderFiles struct {
+	Chart *Chart
+	// ValueFiles are value files in the order of RepoInfo.ValueFiles, absent files are skipped
+	ValueFiles []RenderValueFile
+}
+
+type RenderValueFile struct {
+	FileName string
+	Content  []byte
+}
+
 type ReadFileParam struct {
 	Bytes    []byte
 	Err      error
@@ -148,20 +164,22 @@ type ClusterGitRepo interface {
 	Rollback(ctx context.Context, application, cluster, commit string) (string, error)
 	UpdateTags(ctx context.Context, application, cluster, templateName string,
 		tags []*tagmodels.Tag) error
+	// UpdateAlertRules writes the alert manifests into gitOps branch,
+	// the alert files are deleted if manifests is empty
+	UpdateAlertRules(ctx context.Context, application, cluster string, manifests []byte) error
 	DefaultBranch() string
 	// Deprecated: for internal usage, v1 to v2
 	UpgradeCluster(ctx context.Context, param *UpgradeValuesParam) (string, error)
 	// GetManifest returns manifest with specific revision, defaults to gitops branch
 	GetManifest(ctx context.Context, application,
 		cluster string, commit *string) (*pkgcommon.Manifest, error)
+	// GetRenderFiles returns chart and value files of the specific revision for rendering manifests
+	GetRenderFiles(ctx context.Context, application, cluster, revision string) (*RenderFiles, error)
 }
 type clusterGitopsRepo struct {
-	gitlabLib              gitlablib.Interface
-	clustersGroup          *gitlab.Group
-	recyclingClustersGroup *gitlab.Group
-	templateRepo           templaterepo.TemplateRepo
-	defaultBranch          string
-	defaultVisibility      string
+	storage       repoStorage
+	templateRepo  templaterepo.TemplateRepo
+	defaultBranch string
 }
 
 func NewClusterGitlabRepo(ctx context.Context, rootGroup *gitlab.Group,
@@ -178,12 +196,41 @@ func NewClusterGitlabRepo(ctx context.Context, rootGroup *gitlab.Group,
 		return nil, err
 	}
 	return &clusterGitopsRepo{
-		gitlabLib:              gitlabLib,
-		clustersGroup:          clustersGroup,
-		recyclingClustersGroup: recyclingClustersGroup,
-		templateRepo:           templateRepo,
-		defaultBranch:          defaultBranch,
-		defaultVisibility:      defaultVisibility,
+		storage: &gitlabStorage{
+			gitlabLib:              gitlabLib,
+			clustersGroup:          clustersGroup,
+			recyclingClustersGroup: recyclingClustersGroup,
+			defaultVisibility:      defaultVisibility,
+		},
+		templateRepo:  templateRepo,
+		defaultBranch: defaultBranch,
+	}, nil
+}
+
+type ClusterGitRepoConfig struct {
+	// RootPath is the path under which cluster repos are placed
+	RootPath string
+	// FlatRepoPath joins the path segments of repos by '.',
+	// for git servers which don't support nested paths, such as gitea
+	FlatRepoPath bool
+	// CloneURL is the root URL for argocd to clone repos, defaults to the URL of gitLib
+	CloneURL      string
+	DefaultBranch string
+}
+
+// NewClusterGitRepo returns a ClusterGitRepo which stores cluster repos in any git server
+// or a local directory of bare repos, see lib/git for more information.
+func NewClusterGitRepo(ctx context.Context, templateRepo templaterepo.TemplateRepo,
+	gitLib gitlib.Interface, config ClusterGitRepoConfig) (ClusterGitRepo, error) {
+	return &clusterGitopsRepo{
+		storage: &gitStorage{
+			gitLib:   gitLib,
+			rootPath: strings.Trim(config.RootPath, "/"),
+			flat:     config.FlatRepoPath,
+			cloneURL: config.CloneURL,
+		},
+		templateRepo:  templateRepo,
+		defaultBranch: config.DefaultBranch,
 	}, nil
 }
 
@@ -193,7 +240,7 @@ func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 	defer wlog.Start(ctx, op).StopPrint()
 
 	// 1. get template and pipeline from gitlab
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	pid := g.storage.repoPID(application, cluster)
 	var applicationBytes, pipelineBytes, manifestBytes []byte
 	var err1, err2, err3 error
 
@@ -201,7 +248,7 @@ func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 	wg.Add(3)
 	go func() {
 		defer wg.Done()
-		pipelineBytes, err1 = g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFilePipeline)
+		pipelineBytes, err1 = g.storage.getFile(ctx, pid, GitOpsBranch, common.GitopsFilePipeline)
 		if err1 != nil {
 			return
 		}
@@ -212,7 +259,7 @@ func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 	}()
 	go func() {
 		defer wg.Done()
-		applicationBytes, err2 = g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFileApplication)
+		applicationBytes, err2 = g.storage.getFile(ctx, pid, GitOpsBranch, common.GitopsFileApplication)
 		if err2 != nil {
 			return
 		}
@@ -223,7 +270,7 @@ func (g *clusterGitopsRepo) GetCluster(ctx context.Context,
 	}()
 	go func() {
 		defer wg.Done()
-		manifestBytes, err3 = g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFileManifest)
+		manifestBytes, err3 = g.storage.getFile(ctx, pid, GitOpsBranch, common.GitopsFileManifest)
 		if err3 != nil {
 			return
 		}
@@ -326,7 +373,7 @@ func (g *clusterGitopsRepo) GetClusterValueFiles(ctx context.Context,
 	defer wlog.Start(ctx, op).StopPrint()
 
 	// 1. get  value file from git
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	pid := g.storage.repoPID(application, cluster)
 	cases := []ReadFileParam{
 		{
 			FileName: common.GitopsFileBase,
@@ -346,7 +393,7 @@ func (g *clusterGitopsRepo) GetClusterValueFiles(ctx context.Context,
 	for i := 0; i < len(cases); i++ {
 		go func(index int) {
 			defer wg.Done()
-			cases[index].Bytes, cases[index].Err = g.gitlabLib.GetFile(ctx, pid,
+			cases[index].Bytes, cases[index].Err = g.storage.getFile(ctx, pid,
 				g.defaultBranch, cases[index].FileName)
 			if cases[index].Err != nil {
 				log.Warningf(ctx, "get file %s error, err = %s",
@@ -398,8 +445,8 @@ func (g *clusterGitopsRepo) GetClusterTemplate(ctx context.Context, application,
 	defer wlog.Start(ctx, op).StopPrint()
 
 	// 1. get Chart file from git
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
-	file, err := g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFileChart)
+	pid := g.storage.repoPID(application, cluster)
+	file, err := g.storage.getFile(ctx, pid, GitOpsBranch, common.GitopsFileChart)
 	if err != nil {
 		return nil, err
 	}
@@ -430,25 +477,18 @@ func (g *clusterGitopsRepo) CreateCluster(ctx context.Context, params *CreateClu
 		return err
 	}
 
-	// 1. create application group if necessary
-	appGroup, err := g.gitlabLib.GetCreatedGroup(ctx, g.clustersGroup.ID,
-		g.clustersGroup.FullPath, params.Application.Name, g.defaultVisibility)
-	if err != nil {
+	// 1. create cluster repo
+	if err := g.storage.createRepo(ctx, params.Application.Name, params.Cluster); err != nil {
 		return err
 	}
 
-	// 3. create cluster repo under appGroup
-	if _, err := g.gitlabLib.CreateProject(ctx, params.Cluster, appGroup.ID, g.defaultVisibility); err != nil {
+	// 2. create gitops branch from master
+	pid := g.storage.repoPID(params.Application.Name, params.Cluster)
+	if err := g.storage.createBranch(ctx, pid, GitOpsBranch, g.defaultBranch); err != nil {
 		return err
 	}
 
-	// 3. create gitops branch from master
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, params.Application.Name, params.Cluster)
-	if _, err := g.gitlabLib.CreateBranch(ctx, pid, GitOpsBranch, g.defaultBranch); err != nil {
-		return err
-	}
-
-	// 4. write files to repo, to gitops branch
+	// 3. write files to repo, to gitops branch
 	var applicationYAML, pipelineYAML, baseValueYAML []byte
 	var envValueYAML, sreValueYAML, chartYAML, restartYAML, tagsYAML, manifestValueYAML []byte
 	var err1, err2, err3, err4, err5, err6, err7, err8, err9 error
@@ -552,7 +592,7 @@ func (g *clusterGitopsRepo) CreateCluster(ctx context.Context, params *CreateClu
 		Pipeline:    params.PipelineJSONBlob,
 	})
 
-	if _, err := g.gitlabLib.WriteFiles(ctx, pid, GitOpsBranch, commitMsg, nil, actions); err != nil {
+	if _, err := g.storage.writeFiles(ctx, pid, GitOpsBranch, commitMsg, actions); err != nil {
 		return err
 	}
 
@@ -569,7 +609,7 @@ func (g *clusterGitopsRepo) UpdateCluster(ctx context.Context, params *UpdateClu
 	}
 
 	// 1. write files to repo
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, params.Application.Name, params.Cluster)
+	pid := g.storage.repoPID(params.Application.Name, params.Cluster)
 	var applicationYAML, pipelineYAML, baseValueYAML, envValueYAML, chartYAML []byte
 	var err1, err2, err3, err4, err5 error
 	if params.Application != nil {
@@ -611,8 +651,11 @@ func (g *clusterGitopsRepo) UpdateCluster(ctx context.Context, params *UpdateClu
 		templateUpdate, pipelineUpdate, err := func() (gitlablib.FileAction, gitlablib.FileAction, error) {
 			applicationUpdate, pipelineUpdate := gitlablib.FileCreate, gitlablib.FileCreate
 			if applicationYAML != nil || pipelineYAML != nil {
-				files, err := g.GetCluster(ctx, params.Application.Name, params.Cluster,
-					params.TemplateRelease.TemplateName)
+				template := params.TemplateRelease.TemplateName
+				if params.SourceTemplate != "" {
+					template = params.SourceTemplate
+				}
+				files, err := g.GetCluster(ctx, params.Application.Name, params.Cluster, template)
 				if err != nil {
 					return applicationUpdate, pipelineUpdate, err
 				}
@@ -650,6 +693,27 @@ func (g *clusterGitopsRepo) UpdateCluster(ctx context.Context, params *UpdateClu
 				Content:  string(envValueYAML),
 			})
 		}
+
+		// switching template, values files not rewritten above are re-keyed to the target template
+		if params.SourceTemplate != "" && params.SourceTemplate != params.TemplateRelease.ChartName {
+			fileNames := []string{common.GitopsFileTags, common.GitopsFileSRE,
+				common.GitopsFilePipelineOutput, common.GitopsFileRestart}
+			if applicationYAML == nil {
+				fileNames = append(fileNames, common.GitopsFileApplication)
+			}
+			if pipelineYAML == nil {
+				fileNames = append(fileNames, common.GitopsFilePipeline)
+			}
+			if envValueYAML == nil {
+				fileNames = append(fileNames, common.GitopsFileEnv)
+			}
+			rekeyActions, err := g.rekeyValueFiles(ctx, params.Application.Name, params.Cluster,
+				params.SourceTemplate, params.TemplateRelease.ChartName, fileNames)
+			if err != nil {
+				return nil, err
+			}
+			gitActions = append(gitActions, rekeyActions...)
+		}
 		return gitActions, nil
 	}()
 	if err != nil {
@@ -667,44 +731,58 @@ func (g *clusterGitopsRepo) UpdateCluster(ctx context.Context, params *UpdateClu
 		Application: params.ApplicationJSONBlob,
 		Pipeline:    params.PipelineJSONBlob,
 	})
-	if _, err := g.gitlabLib.WriteFiles(ctx, pid, GitOpsBranch, commitMsg, nil, actions); err != nil {
+	if _, err := g.storage.writeFiles(ctx, pid, GitOpsBranch, commitMsg, actions); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (g *clusterGitopsRepo) DeleteCluster(ctx context.Context,
-	application, cluster string, clusterID uint) (err error) {
-	const op = "cluster git repo: delete cluster"
-	defer wlog.Start(ctx, op).StopPrint()
-
-	// 1. create application group if necessary
-	_, err = g.gitlabLib.GetGroup(ctx, fmt.Sprintf("%v/%v", g.recyclingClustersGroup.FullPath, application))
-	if err != nil {
-		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
-			return err
+// rekeyValueFiles moves values in files from the parent key of source template to the one of target template,
+// absent files and files without values of source template are skipped
+func (g *clusterGitopsRepo) rekeyValueFiles(ctx context.Context, application, cluster,
+	source, target string, fileNames []string) ([]gitlablib.CommitAction, error) {
+	var actions []gitlablib.CommitAction
+	for _, fileName := range fileNames {
+		content, err := g.readFile(ctx, application, cluster, fileName, nil)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				continue
+			}
+			return nil, err
+		}
+		var valueMap map[string]interface{}
+		if err := yaml.Unmarshal(content, &valueMap); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"yaml Unmarshal err, file = %s, err = %s", fileName, err.Error())
 		}
-		_, err = g.gitlabLib.CreateGroup(ctx, application, application,
-			&g.recyclingClustersGroup.ID, g.defaultVisibility)
+		value, ok := valueMap[source]
+		if !ok {
+			continue
+		}
+		delete(valueMap, source)
+		valueMap[target] = value
+
+		var rekeyedBytes []byte
+		marshal(&rekeyedBytes, &err, valueMap)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		actions = append(actions, gitlablib.CommitAction{
+			Action:   gitlablib.FileUpdate,
+			FilePath: fileName,
+			Content:  string(rekeyedBytes),
+		})
 	}
+	return actions, nil
+}
 
-	// 1. delete gitlab project
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
-	// 1.1 edit project's name and path to {cluster}-{clusterID}
-	newName := fmt.Sprintf("%v-%d", cluster, clusterID)
-	newPath := newName
-	if err := g.gitlabLib.EditNameAndPathForProject(ctx, pid, &newName, &newPath); err != nil {
-		return err
-	}
+func (g *clusterGitopsRepo) DeleteCluster(ctx context.Context,
+	application, cluster string, clusterID uint) (err error) {
+	const op = "cluster git repo: delete cluster"
+	defer wlog.Start(ctx, op).StopPrint()
 
-	// 1.2 transfer project to RecyclingParent
-	newPid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, newPath)
-	return g.gitlabLib.TransferProject(ctx, newPid,
-		fmt.Sprintf("%v/%v", g.recyclingClustersGroup.FullPath, application))
+	return g.storage.recycleRepo(ctx, application, cluster, clusterID)
 }
 
 func (g *clusterGitopsRepo) HardDeleteCluster(ctx context.Context, application,
@@ -712,8 +790,7 @@ func (g *clusterGitopsRepo) HardDeleteCluster(ctx context.Context, application,
 	const op = "cluster git repo: hard delete cluster"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
-	return g.gitlabLib.DeleteProject(ctx, pid)
+	return g.storage.deleteRepo(ctx, application, cluster)
 }
 
 func (g *clusterGitopsRepo) CompareConfig(ctx context.Context, application,
@@ -721,22 +798,22 @@ func (g *clusterGitopsRepo) CompareConfig(ctx context.Context, application,
 	const op = "cluster git repo: compare config"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	pid := g.storage.repoPID(application, cluster)
 
-	var compare *gitlab.Compare
+	var diffs []*gitlab.Diff
 	if from == nil || to == nil {
-		compare, err = g.gitlabLib.Compare(ctx, pid, g.defaultBranch, GitOpsBranch, nil)
+		diffs, err = g.storage.compare(ctx, pid, g.defaultBranch, GitOpsBranch, nil)
 	} else {
-		compare, err = g.gitlabLib.Compare(ctx, pid, *from, *to, nil)
+		diffs, err = g.storage.compare(ctx, pid, *from, *to, nil)
 	}
 	if err != nil {
 		return "", err
 	}
-	if compare.Diffs == nil {
+	if len(diffs) == 0 {
 		return "", nil
 	}
 	diffStr := ""
-	for _, diff := range compare.Diffs {
+	for _, diff := range diffs {
 		diffStr += "--- " + diff.OldPath + "\n"
 		diffStr += "+++ " + diff.NewPath + "\n"
 		diffStr += diff.Diff + "\n"
@@ -746,8 +823,7 @@ func (g *clusterGitopsRepo) CompareConfig(ctx context.Context, application,
 
 func (g *clusterGitopsRepo) MergeBranch(ctx context.Context, application, cluster,
 	sourceBranch, targetBranch string, pipelineRunID *uint) (_ string, err error) {
-	removeSourceBranch := false
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	pid := g.storage.repoPID(application, cluster)
 
 	var title string
 	if pipelineRunID != nil {
@@ -757,42 +833,7 @@ func (g *clusterGitopsRepo) MergeBranch(ctx context.Context, application, cluste
 		title = fmt.Sprintf("git merge %v into %v", sourceBranch, targetBranch)
 	}
 
-	var mr *gitlab.MergeRequest
-	mrs, err := g.gitlabLib.ListMRs(ctx, pid, sourceBranch,
-		targetBranch, common.GitopsMergeRequestStateOpen)
-	if err != nil {
-		return "", perror.WithMessage(err, "failed to list merge requests")
-	}
-	if len(mrs) > 0 {
-		// merge old mr when it is existed, because given specified source and target, gitlab only allows 1 mr to exist
-		mr = mrs[0]
-
-		// close the redundant mrs
-		// gitlab has a bug for when concurrency create merge request(will exist 2 more merge request for the same
-		// (source,target), caused we can't merge anymore)
-		if len(mrs) >= 2 {
-			log.Warningf(ctx, "there %d mrs for (src:%s, des:%s), here will kill redundant mrs",
-				len(mrs), sourceBranch, targetBranch)
-			for i := 1; i < len(mrs); i++ {
-				_, err := g.gitlabLib.CloseMR(ctx, pid, mrs[i].IID)
-				if err != nil {
-					return "", err
-				}
-			}
-		}
-	} else {
-		// create new mr
-		mr, err = g.gitlabLib.CreateMR(ctx, pid, sourceBranch, targetBranch, title)
-		if err != nil {
-			return "", perror.WithMessage(err, "failed to create new merge request")
-		}
-	}
-
-	mr, err = g.gitlabLib.AcceptMR(ctx, pid, mr.IID, &title, &removeSourceBranch)
-	if err != nil {
-		return "", perror.WithMessage(err, "failed to accept merge request")
-	}
-	return mr.MergeCommitSHA, nil
+	return g.storage.mergeBranch(ctx, pid, sourceBranch, targetBranch, title)
 }
 
 func (g *clusterGitopsRepo) GetManifest(ctx context.Context, application,
@@ -800,13 +841,13 @@ func (g *clusterGitopsRepo) GetManifest(ctx context.Context, application,
 	const op = "cluster git repo: get manifest"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	pid := g.storage.repoPID(application, cluster)
 	var content []byte
 	var err error
 	if commit != nil {
-		content, err = g.gitlabLib.GetFile(ctx, pid, *commit, common.GitopsFileManifest)
+		content, err = g.storage.getFile(ctx, pid, *commit, common.GitopsFileManifest)
 	} else {
-		content, err = g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFileManifest)
+		content, err = g.storage.getFile(ctx, pid, GitOpsBranch, common.GitopsFileManifest)
 	}
 	if err != nil {
 		return nil, err
@@ -831,8 +872,8 @@ func (g *clusterGitopsRepo) GetManifest(ctx context.Context, application,
 func (g *clusterGitopsRepo) GetPipelineOutput(ctx context.Context, application, cluster string,
 	template string) (interface{}, error) {
 	ret := make(map[string]interface{})
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
-	content, err := g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFilePipelineOutput)
+	pid := g.storage.repoPID(application, cluster)
+	content, err := g.storage.getFile(ctx, pid, GitOpsBranch, common.GitopsFilePipelineOutput)
 	if err != nil {
 		return nil, perror.WithMessage(err, "failed to get gitlab file")
 	}
@@ -854,8 +895,8 @@ func (g *clusterGitopsRepo) GetPipelineOutput(ctx context.Context, application,
 
 func (g *clusterGitopsRepo) getPipelineOutput(ctx context.Context,
 	application, cluster string) (map[string]map[string]interface{}, error) {
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
-	content, err := g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFilePipelineOutput)
+	pid := g.storage.repoPID(application, cluster)
+	content, err := g.storage.getFile(ctx, pid, GitOpsBranch, common.GitopsFilePipelineOutput)
 	if err != nil {
 		return nil, err
 	}
@@ -941,19 +982,19 @@ func (g *clusterGitopsRepo) UpdatePipelineOutput(ctx context.Context, applicatio
 		Cluster:  angular.StringPtr(cluster),
 	}, pipelineOutput)
 
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
-	commit, err := g.gitlabLib.WriteFiles(ctx, pid, GitOpsBranch, commitMsg, nil, actions)
+	pid := g.storage.repoPID(application, cluster)
+	commit, err := g.storage.writeFiles(ctx, pid, GitOpsBranch, commitMsg, actions)
 	if err != nil {
 		return "", perror.WithMessage(err, "failed to write gitlab files")
 	}
-	return commit.ID, nil
+	return commit, nil
 }
 
 func (g *clusterGitopsRepo) GetRestartTime(ctx context.Context, application, cluster string,
 	template string) (string, error) {
 	ret := make(map[string]map[string]string)
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
-	content, err := g.gitlabLib.GetFile(ctx, pid, g.defaultBranch, common.GitopsFileRestart)
+	pid := g.storage.repoPID(application, cluster)
+	content, err := g.storage.getFile(ctx, pid, g.defaultBranch, common.GitopsFileRestart)
 	if err != nil {
 		return "", perror.WithMessage(err, "failed to get gitlab file")
 	}
@@ -984,7 +1025,7 @@ func (g *clusterGitopsRepo) UpdateRestartTime(ctx context.Context,
 		return "", err
 	}
 
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	pid := g.storage.repoPID(application, cluster)
 
 	var restartYAML []byte
 	var err1 error
@@ -1008,12 +1049,12 @@ func (g *clusterGitopsRepo) UpdateRestartTime(ctx context.Context,
 	}, nil)
 
 	// update in defaultBranch directly
-	commit, err := g.gitlabLib.WriteFiles(ctx, pid, g.defaultBranch, commitMsg, nil, actions)
+	commit, err := g.storage.writeFiles(ctx, pid, g.defaultBranch, commitMsg, actions)
 	if err != nil {
 		return "", err
 	}
 
-	return commit.ID, nil
+	return commit, nil
 }
 
 func (g *clusterGitopsRepo) GetConfigCommit(ctx context.Context,
@@ -1021,19 +1062,19 @@ func (g *clusterGitopsRepo) GetConfigCommit(ctx context.Context,
 	const op = "cluster git repo: get config commit"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	pid := g.storage.repoPID(application, cluster)
 
-	var branchMaster, branchGitops *gitlab.Branch
+	var commitMaster, commitGitops string
 	var err1, err2 error
 	var wg sync.WaitGroup
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		branchMaster, err1 = g.gitlabLib.GetBranch(ctx, pid, g.defaultBranch)
+		commitMaster, err1 = g.storage.getBranchCommit(ctx, pid, g.defaultBranch)
 	}()
 	go func() {
 		defer wg.Done()
-		branchGitops, err2 = g.gitlabLib.GetBranch(ctx, pid, GitOpsBranch)
+		commitGitops, err2 = g.storage.getBranchCommit(ctx, pid, GitOpsBranch)
 	}()
 	wg.Wait()
 
@@ -1044,28 +1085,60 @@ func (g *clusterGitopsRepo) GetConfigCommit(ctx context.Context,
 	}
 
 	return &ClusterCommit{
-		Master: branchMaster.Commit.ID,
-		Gitops: branchGitops.Commit.ID,
+		Master: commitMaster,
+		Gitops: commitGitops,
 	}, nil
 }
 
 func (g *clusterGitopsRepo) GetRepoInfo(ctx context.Context, application, cluster string) *RepoInfo {
-	repoURL := g.gitlabLib.GetHTTPURL(ctx)
 	return &RepoInfo{
-		GitRepoURL: fmt.Sprintf("%v/%v/%v/%v.git", repoURL, g.clustersGroup.FullPath, application, cluster),
+		GitRepoURL: g.storage.repoURL(ctx, g.storage.repoPID(application, cluster)),
 		ValueFiles: []string{common.GitopsFileApplication, common.GitopsFilePipelineOutput,
 			common.GitopsFileEnv, common.GitopsFileBase, common.GitopsFileTags, common.GitopsFileRestart, common.GitopsFileSRE},
 	}
 }
 
+func (g *clusterGitopsRepo) GetRenderFiles(ctx context.Context,
+	application, cluster, revision string) (_ *RenderFiles, err error) {
+	const op = "cluster git repo: get render files"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pid := g.storage.repoPID(application, cluster)
+	chartBytes, err := g.storage.getFile(ctx, pid, revision, common.GitopsFileChart)
+	if err != nil {
+		return nil, err
+	}
+	var chart Chart
+	if err := yaml.Unmarshal(chartBytes, &chart); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"yaml Unmarshal err, file = %s", common.GitopsFileChart)
+	}
+
+	renderFiles := &RenderFiles{Chart: &chart}
+	for _, fileName := range g.GetRepoInfo(ctx, application, cluster).ValueFiles {
+		content, err := g.storage.getFile(ctx, pid, revision, fileName)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				continue
+			}
+			return nil, err
+		}
+		renderFiles.ValueFiles = append(renderFiles.ValueFiles, RenderValueFile{
+			FileName: fileName,
+			Content:  content,
+		})
+	}
+	return renderFiles, nil
+}
+
 func (g *clusterGitopsRepo) GetEnvValue(ctx context.Context,
 	application, cluster, templateName string) (_ *EnvValue, err error) {
 	const op = "cluster git repo: get config commit"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	pid := g.storage.repoPID(application, cluster)
 
-	bytes, err := g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFileEnv)
+	bytes, err := g.storage.getFile(ctx, pid, GitOpsBranch, common.GitopsFileEnv)
 	if err != nil {
 		return nil, err
 	}
@@ -1088,14 +1161,14 @@ func (g *clusterGitopsRepo) Rollback(ctx context.Context, application, cluster,
 		return "", err
 	}
 
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	pid := g.storage.repoPID(application, cluster)
 
 	// compare commit straight diffs
-	compare, err := g.gitlabLib.Compare(ctx, pid, GitOpsBranch, commit, utilcommon.BoolPtr(true))
+	diffs, err := g.storage.compare(ctx, pid, GitOpsBranch, commit, utilcommon.BoolPtr(true))
 	if err != nil {
 		return "", err
 	}
-	if compare.Diffs == nil {
+	if len(diffs) == 0 {
 		return "", perror.Wrapf(herrors.ErrParamInvalid,
 			"dose not support empty rollback, rollback commit = %s", commit)
 	}
@@ -1104,15 +1177,15 @@ func (g *clusterGitopsRepo) Rollback(ctx context.Context, application, cluster,
 		action *gitlablib.CommitAction
 		err    error
 	}
-	cases := make([]actionCase, len(compare.Diffs))
+	cases := make([]actionCase, len(diffs))
 	var wg sync.WaitGroup
-	for i := range compare.Diffs {
+	for i := range diffs {
 		i := i
 		wg.Add(1)
 		// generate a commit action for rollback based on diff
 		go func() {
 			defer wg.Done()
-			action, err := g.revertAction(ctx, application, cluster, commit, *compare.Diffs[i])
+			action, err := g.revertAction(ctx, application, cluster, commit, *diffs[i])
 			cases[i] = actionCase{
 				action: action,
 				err:    err,
@@ -1139,12 +1212,12 @@ func (g *clusterGitopsRepo) Rollback(ctx context.Context, application, cluster,
 		Commit: commit,
 	})
 
-	newCommit, err := g.gitlabLib.WriteFiles(ctx, pid, GitOpsBranch, commitMsg, nil, actions)
+	newCommit, err := g.storage.writeFiles(ctx, pid, GitOpsBranch, commitMsg, actions)
 	if err != nil {
 		return "", err
 	}
 
-	return newCommit.ID, nil
+	return newCommit, nil
 }
 
 func (g *clusterGitopsRepo) UpdateTags(ctx context.Context, application, cluster, templateName string,
@@ -1157,7 +1230,7 @@ func (g *clusterGitopsRepo) UpdateTags(ctx context.Context, application, cluster
 		return err
 	}
 
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	pid := g.storage.repoPID(application, cluster)
 
 	var tagsYAML []byte
 	marshal(&tagsYAML, &err, assembleTags(templateName, tags))
@@ -1196,11 +1269,67 @@ func (g *clusterGitopsRepo) UpdateTags(ctx context.Context, application, cluster
 		}(tags),
 	})
 
-	_, err = g.gitlabLib.WriteFiles(ctx, pid, GitOpsBranch, commitMsg, nil, actions)
+	_, err = g.storage.writeFiles(ctx, pid, GitOpsBranch, commitMsg, actions)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g *clusterGitopsRepo) UpdateAlertRules(ctx context.Context,
+	application, cluster string, manifests []byte) error {
+	const op = "cluster git repo: update alert rules"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
 		return err
 	}
 
+	pid := g.storage.repoPID(application, cluster)
+
+	exists := true
+	if _, err := g.storage.getFile(ctx, pid, GitOpsBranch, common.GitopsFileAlerts); err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+		exists = false
+	}
+
+	var actions []gitlablib.CommitAction
+	switch {
+	case len(manifests) == 0 && !exists:
+		return nil
+	case len(manifests) == 0:
+		actions = []gitlablib.CommitAction{
+			{Action: gitlablib.FileDelete, FilePath: common.GitopsFileAlerts},
+			{Action: gitlablib.FileDelete, FilePath: common.GitopsFileAlertsTemplate},
+		}
+	case exists:
+		actions = []gitlablib.CommitAction{
+			{Action: gitlablib.FileUpdate, FilePath: common.GitopsFileAlerts, Content: string(manifests)},
+		}
+	default:
+		actions = []gitlablib.CommitAction{
+			{Action: gitlablib.FileCreate, FilePath: common.GitopsFileAlerts, Content: string(manifests)},
+			{
+				Action:   gitlablib.FileCreate,
+				FilePath: common.GitopsFileAlertsTemplate,
+				Content:  fmt.Sprintf("{{ .Files.Get %q }}\n", common.GitopsFileAlerts),
+			},
+		}
+	}
+
+	commitMsg := angular.CommitMessage("cluster", angular.Subject{
+		Operator: currentUser.GetName(),
+		Action:   "update alert rules",
+		Cluster:  angular.StringPtr(cluster),
+	}, nil)
+
+	if _, err := g.storage.writeFiles(ctx, pid, GitOpsBranch, commitMsg, actions); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1217,8 +1346,7 @@ func (g *clusterGitopsRepo) UpgradeCluster(ctx context.Context,
 		return "", err
 	}
 
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath,
-		param.Application, param.Cluster)
+	pid := g.storage.repoPID(param.Application, param.Cluster)
 
 	type upgradeValueBytes struct {
 		fileName      string
@@ -1535,11 +1663,11 @@ func (g *clusterGitopsRepo) UpgradeCluster(ctx context.Context,
 			Release: param.TargetRelease.Name,
 		},
 	})
-	newCommit, err := g.gitlabLib.WriteFiles(ctx, pid, GitOpsBranch, commitMsg, nil, gitActions)
+	newCommit, err := g.storage.writeFiles(ctx, pid, GitOpsBranch, commitMsg, gitActions)
 	if err != nil {
 		return "", err
 	}
-	return newCommit.ID, nil
+	return newCommit, nil
 }
 
 // assembleApplicationValue assemble application.yaml data
@@ -1752,11 +1880,11 @@ func (g *clusterGitopsRepo) revertAction(ctx context.Context, application, clust
 // readFile gets file for specific revision, defaults to gitOps branch
 func (g *clusterGitopsRepo) readFile(ctx context.Context, application, cluster,
 	fileName string, commit *string) ([]byte, error) {
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	pid := g.storage.repoPID(application, cluster)
 	if commit != nil {
-		return g.gitlabLib.GetFile(ctx, pid, *commit, fileName)
+		return g.storage.getFile(ctx, pid, *commit, fileName)
 	}
-	return g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, fileName)
+	return g.storage.getFile(ctx, pid, GitOpsBranch, fileName)
 }
 
 func renameTemplateName(name string) string {
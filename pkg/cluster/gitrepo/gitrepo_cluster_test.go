@@ -19,12 +19,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	gitlib "github.com/horizoncd/horizon/lib/git"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
 	gitlablibmock "github.com/horizoncd/horizon/mock/lib/gitlab"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
@@ -40,12 +43,14 @@ import (
 	utilcommon "github.com/horizoncd/horizon/pkg/util/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/xanzy/go-gitlab"
+	"gopkg.in/yaml.v3"
 )
 
 /*
 go test -v ./pkg/cluster/gitrepo
 
-NOTE: when there is no GITLAB_PARAMS_FOR_TEST environment variable, skip this test.
+NOTE: when there is no GITLAB_PARAMS_FOR_TEST environment variable, only the git implementation
+based on a temp directory is tested.
 
 env name is GITLAB_PARAMS_FOR_TEST, and the value is a json string, look like:
 {
@@ -71,9 +76,7 @@ export GITLAB_PARAMS_FOR_TEST="$(cat <<\EOF
 }
 EOF
 )"
-go test -v ./pkg/application/gitrepo
-
-NOTE: when there is no GITLAB_PARAMS_FOR_TEST environment variable, skip this test.
+go test -v ./pkg/cluster/gitrepo
 
 
 NOTE: if your gitlab default branch is main.
@@ -84,6 +87,7 @@ set the env 'defaultBranch' to main
 var (
 	ctx           context.Context
 	g             gitlablib.Interface
+	gitRoot       string
 	defaultBranch string
 
 	defaultVisibility string
@@ -138,36 +142,41 @@ func TestMain(m *testing.M) {
 		Name: "Tony",
 	})
 
-	param := os.Getenv("GITLAB_PARAMS_FOR_TEST")
-	if param == "" {
-		return
-	}
-
 	defaultBranch = os.Getenv("defaultBranch")
 	if defaultBranch == "" {
 		defaultBranch = "master"
 	}
 
 	defaultVisibility = "public"
+	templateName = "javaapp"
+	rootGroup = &gitlab.Group{}
 
-	var p *Param
-	if err := json.Unmarshal([]byte(param), &p); err != nil {
-		panic(err)
-	}
-
-	sshURL = "ssh://gitlab.com"
-
-	g, err = gitlablib.New(p.Token, p.BaseURL)
-	if err != nil {
-		panic(err)
-	}
-	rootGroup, err = g.GetGroup(ctx, p.RootGroupName)
+	gitRoot, err = ioutil.TempDir("", "horizon-gitops")
 	if err != nil {
 		panic(err)
 	}
+	defer func() { _ = os.RemoveAll(gitRoot) }()
 
-	rootGroupName = p.RootGroupName
-	templateName = "javaapp"
+	param := os.Getenv("GITLAB_PARAMS_FOR_TEST")
+	if param != "" {
+		var p *Param
+		if err := json.Unmarshal([]byte(param), &p); err != nil {
+			panic(err)
+		}
+
+		sshURL = "ssh://gitlab.com"
+
+		g, err = gitlablib.New(p.Token, p.BaseURL)
+		if err != nil {
+			panic(err)
+		}
+		rootGroup, err = g.GetGroup(ctx, p.RootGroupName)
+		if err != nil {
+			panic(err)
+		}
+
+		rootGroupName = p.RootGroupName
+	}
 
 	if err := json.Unmarshal([]byte(pipelineJSONStr), &pipelineJSONBlob); err != nil {
 		panic(err)
@@ -176,15 +185,50 @@ func TestMain(m *testing.M) {
 		panic(err)
 	}
 
-	os.Exit(m.Run())
+	code := m.Run()
+	_ = os.RemoveAll(gitRoot)
+	os.Exit(code)
 }
 
-func Test(t *testing.T) {
+// clusterGitRepos returns the ClusterGitRepos to test, the git one based on a temp directory
+// is always returned, and the gitlab one is returned if GITLAB_PARAMS_FOR_TEST is set
+func clusterGitRepos(t *testing.T) map[string]ClusterGitRepo {
 	repo, _ := chartmuseumbase.NewRepo(config.Repo{Host: "https://harbor.cloudnative.com"})
+	repos := make(map[string]ClusterGitRepo)
 
-	r, err := NewClusterGitlabRepo(ctx, rootGroup, repo, g, defaultBranch, defaultVisibility)
+	root, err := ioutil.TempDir(gitRoot, "root")
 	assert.Nil(t, err)
+	gitLib, err := gitlib.New(gitlib.Config{
+		URL:           root,
+		CacheDir:      filepath.Join(gitRoot, "cache", filepath.Base(root)),
+		DefaultBranch: defaultBranch,
+	})
+	assert.Nil(t, err)
+	r, err := NewClusterGitRepo(ctx, repo, gitLib, ClusterGitRepoConfig{
+		RootPath:      "horizon",
+		DefaultBranch: defaultBranch,
+	})
+	assert.Nil(t, err)
+	repos["git"] = r
+
+	if g != nil {
+		r, err := NewClusterGitlabRepo(ctx, rootGroup, repo, g, defaultBranch, defaultVisibility)
+		assert.Nil(t, err)
+		repos["gitlab"] = r
+	}
+	return repos
+}
+
+func Test(t *testing.T) {
+	for name, r := range clusterGitRepos(t) {
+		name, r := name, r
+		t.Run(name, func(t *testing.T) {
+			testClusterGitRepo(t, name, r)
+		})
+	}
+}
 
+func testClusterGitRepo(t *testing.T, name string, r ClusterGitRepo) {
 	application := "app"
 	cluster := "cluster"
 
@@ -223,10 +267,12 @@ func Test(t *testing.T) {
 
 	defer func() {
 		_ = r.DeleteCluster(ctx, application, cluster, 1)
-		_ = g.DeleteProject(ctx, fmt.Sprintf("%v/%v/%v/%v-%v", rootGroupName,
-			common.GitopsGroupRecyclingClusters, application, cluster, 1))
+		if name == "gitlab" {
+			_ = g.DeleteProject(ctx, fmt.Sprintf("%v/%v/%v/%v-%v", rootGroupName,
+				common.GitopsGroupRecyclingClusters, application, cluster, 1))
+		}
 	}()
-	err = r.CreateCluster(ctx, createParams)
+	err := r.CreateCluster(ctx, createParams)
 	assert.Nil(t, err)
 
 	updateParams.Application.Priority = "P1"
@@ -306,16 +352,40 @@ func Test(t *testing.T) {
 	})
 	assert.Nil(t, err)
 
+	storage := r.(*clusterGitopsRepo).storage
+	pid := storage.repoPID(application, cluster)
+	for _, manifests := range []string{"kind: PrometheusRule\n", "kind: PrometheusRule\n---\n"} {
+		assert.Nil(t, r.UpdateAlertRules(ctx, application, cluster, []byte(manifests)))
+		content, err := storage.getFile(ctx, pid, GitOpsBranch, common.GitopsFileAlerts)
+		assert.Nil(t, err)
+		assert.Equal(t, manifests, string(content))
+		content, err = storage.getFile(ctx, pid, GitOpsBranch, common.GitopsFileAlertsTemplate)
+		assert.Nil(t, err)
+		assert.Equal(t, "{{ .Files.Get \"alerts/prometheusrule.yaml\" }}\n", string(content))
+	}
+	// delete twice, the second one is a no-op
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, r.UpdateAlertRules(ctx, application, cluster, nil))
+		_, err = storage.getFile(ctx, pid, GitOpsBranch, common.GitopsFileAlertsTemplate)
+		_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+		assert.True(t, ok)
+	}
+
 	restartTime, err := r.GetRestartTime(ctx, application, cluster, templateName)
 	assert.Nil(t, err)
 	assert.NotEmpty(t, restartTime)
 }
 
 func TestV2(t *testing.T) {
-	repo, _ := chartmuseumbase.NewRepo(config.Repo{Host: "https://harbor.cloudnative.com"})
-	r, err := NewClusterGitlabRepo(ctx, rootGroup, repo, g, defaultBranch, defaultVisibility)
-	assert.Nil(t, err)
+	for name, r := range clusterGitRepos(t) {
+		name, r := name, r
+		t.Run(name, func(t *testing.T) {
+			testClusterGitRepoV2(t, name, r)
+		})
+	}
+}
 
+func testClusterGitRepoV2(t *testing.T, name string, r ClusterGitRepo) {
 	application := "appv2"
 	cluster := "clusterv2"
 
@@ -352,10 +422,12 @@ func TestV2(t *testing.T) {
 
 	defer func() {
 		_ = r.DeleteCluster(ctx, application, cluster, 1)
-		_ = g.DeleteProject(ctx, fmt.Sprintf("%v/%v/%v/%v-%v", rootGroupName,
-			"recycling-clusters", application, cluster, 1))
+		if name == "gitlab" {
+			_ = g.DeleteProject(ctx, fmt.Sprintf("%v/%v/%v/%v-%v", rootGroupName,
+				"recycling-clusters", application, cluster, 1))
+		}
 	}()
-	err = r.CreateCluster(ctx, createParams)
+	err := r.CreateCluster(ctx, createParams)
 	assert.Nil(t, err)
 	files, err := r.GetCluster(ctx, application, cluster, templateName)
 	t.Logf("%+v", files)
@@ -369,6 +441,11 @@ func TestV2(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, manifest.Version, common.MetaVersion2)
 
+	renderFiles, err := r.GetRenderFiles(ctx, application, cluster, GitOpsBranch)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.0", renderFiles.Chart.Dependencies[0].Version)
+	assert.Equal(t, common.GitopsFileApplication, renderFiles.ValueFiles[0].FileName)
+
 	// do not update Region \ update Version \ application yaml \ add pipeline
 	baseParams.RegionEntity = nil
 	baseParams.TemplateRelease.ChartVersion = "v2.0.0"
@@ -416,10 +493,15 @@ func TestV2(t *testing.T) {
 }
 
 func TestUpgradeToV2(t *testing.T) {
-	repo, _ := chartmuseumbase.NewRepo(config.Repo{Host: "https://harbor.cloudnative.com"})
-	r, err := NewClusterGitlabRepo(ctx, rootGroup, repo, g, defaultBranch, defaultVisibility)
-	assert.Nil(t, err)
+	for name, r := range clusterGitRepos(t) {
+		name, r := name, r
+		t.Run(name, func(t *testing.T) {
+			testClusterGitRepoUpgradeToV2(t, name, r)
+		})
+	}
+}
 
+func testClusterGitRepoUpgradeToV2(t *testing.T, name string, r ClusterGitRepo) {
 	application := "appUpgrade"
 	cluster := "clusterUpgrade"
 
@@ -454,11 +536,13 @@ func TestUpgradeToV2(t *testing.T) {
 	}
 	defer func() {
 		_ = r.DeleteCluster(ctx, application, cluster, 1)
-		_ = g.DeleteProject(ctx, fmt.Sprintf("%v/%v/%v/%v-%v", rootGroupName,
-			"recycling-clusters", application, cluster, 1))
+		if name == "gitlab" {
+			_ = g.DeleteProject(ctx, fmt.Sprintf("%v/%v/%v/%v-%v", rootGroupName,
+				"recycling-clusters", application, cluster, 1))
+		}
 	}()
 
-	err = r.CreateCluster(ctx, createParams)
+	err := r.CreateCluster(ctx, createParams)
 	assert.Nil(t, err)
 	files, err := r.GetCluster(ctx, application, cluster, templateName)
 	t.Logf("%+v", files)
@@ -489,7 +573,7 @@ func TestUpgradeToV2(t *testing.T) {
 	})
 	assert.Nil(t, err)
 	assert.NotNil(t, upgradeCommit)
-	files, err = r.GetCluster(ctx, application, cluster, templateName)
+	files, err = r.GetCluster(ctx, application, cluster, targetTemplate)
 	t.Logf("%+v", files)
 	assert.Nil(t, err)
 	assert.NotNil(t, files.Manifest)
@@ -502,6 +586,109 @@ func TestUpgradeToV2(t *testing.T) {
 	assert.Equal(t, template.Release, targetRelease)
 }
 
+func TestSwitchTemplate(t *testing.T) {
+	for name, r := range clusterGitRepos(t) {
+		name, r := name, r
+		t.Run(name, func(t *testing.T) {
+			testClusterGitRepoSwitchTemplate(t, name, r)
+		})
+	}
+}
+
+func testClusterGitRepoSwitchTemplate(t *testing.T, name string, r ClusterGitRepo) {
+	application := "appSwitch"
+	cluster := "clusterSwitch"
+
+	baseParams := &BaseParams{
+		Cluster:             cluster,
+		PipelineJSONBlob:    pipelineJSONBlob,
+		ApplicationJSONBlob: applicationJSONBlob,
+		TemplateRelease: &trmodels.TemplateRelease{
+			TemplateName: templateName,
+			ChartName:    templateName,
+			ChartVersion: "v1.0.0",
+		},
+		Application: &appmodels.Application{
+			GroupID:  10,
+			Name:     application,
+			Priority: "P0",
+		},
+		Environment: "test",
+		RegionEntity: &regionmodels.RegionEntity{
+			Region: &regionmodels.Region{
+				Name:        "hz",
+				DisplayName: "HZ",
+				Server:      "https://k8s.com",
+			},
+			Registry: &registrymodels.Registry{
+				Server: "https://harbor.com",
+			},
+		},
+		Version: common.MetaVersion2,
+	}
+	defer func() {
+		_ = r.DeleteCluster(ctx, application, cluster, 1)
+		if name == "gitlab" {
+			_ = g.DeleteProject(ctx, fmt.Sprintf("%v/%v/%v/%v-%v", rootGroupName,
+				"recycling-clusters", application, cluster, 1))
+		}
+	}()
+	err := r.CreateCluster(ctx, &CreateClusterParams{
+		BaseParams: baseParams,
+		Tags:       []*tagmodels.Tag{{Key: "k", Value: "v"}},
+	})
+	assert.Nil(t, err)
+	image := "harbor.com/app/cluster:v1"
+	_, err = r.UpdatePipelineOutput(ctx, application, cluster, templateName, PipelineOutput{Image: &image})
+	assert.Nil(t, err)
+
+	// switch template without region, env and other values files are kept under the target template
+	targetTemplate := "rollout"
+	err = r.UpdateCluster(ctx, &UpdateClusterParams{
+		BaseParams: &BaseParams{
+			Cluster:             cluster,
+			PipelineJSONBlob:    pipelineJSONBlob,
+			ApplicationJSONBlob: applicationJSONBlob,
+			TemplateRelease: &trmodels.TemplateRelease{
+				TemplateName: targetTemplate,
+				ChartName:    targetTemplate,
+				ChartVersion: "v1.0.0",
+			},
+			Application: baseParams.Application,
+			Environment: baseParams.Environment,
+			Version:     common.MetaVersion2,
+		},
+		SourceTemplate: templateName,
+	})
+	assert.Nil(t, err)
+
+	files, err := r.GetCluster(ctx, application, cluster, targetTemplate)
+	assert.Nil(t, err)
+	assert.NotNil(t, files.ApplicationJSONBlob)
+	assert.NotNil(t, files.PipelineJSONBlob)
+	envValue, err := r.GetEnvValue(ctx, application, cluster, targetTemplate)
+	assert.Nil(t, err)
+	assert.NotNil(t, envValue)
+	assert.Equal(t, "hz", envValue.Region)
+	output, err := r.GetPipelineOutput(ctx, application, cluster, targetTemplate)
+	assert.Nil(t, err)
+	assert.Equal(t, image, output.(map[string]interface{})["image"])
+	template, err := r.GetClusterTemplate(ctx, application, cluster)
+	assert.Nil(t, err)
+	assert.Equal(t, targetTemplate, template.Name)
+
+	storage := r.(*clusterGitopsRepo).storage
+	pid := storage.repoPID(application, cluster)
+	for _, fileName := range []string{common.GitopsFileTags, common.GitopsFileSRE, common.GitopsFileRestart} {
+		content, err := storage.getFile(ctx, pid, GitOpsBranch, fileName)
+		assert.Nil(t, err)
+		var valueMap map[string]interface{}
+		assert.Nil(t, yaml.Unmarshal(content, &valueMap))
+		assert.Contains(t, valueMap, targetTemplate, fileName)
+		assert.NotContains(t, valueMap, templateName, fileName)
+	}
+}
+
 func TestHardDeleteCluster(t *testing.T) {
 	application := "app"
 	cluster := "cluster"
@@ -534,14 +721,20 @@ func TestHardDeleteCluster(t *testing.T) {
 	createParams := &CreateClusterParams{
 		BaseParams: baseParams,
 	}
-	repo, _ := chartmuseumbase.NewRepo(config.Repo{Host: "https://harbor.cloudnative.com"})
-	r, err := NewClusterGitlabRepo(ctx, rootGroup, repo, g, defaultBranch, defaultVisibility)
-	assert.Nil(t, err)
-	err = r.CreateCluster(ctx, createParams)
-	assert.Nil(t, err)
-
-	err = r.HardDeleteCluster(ctx, application, cluster)
-	assert.Nil(t, err)
+	for name, r := range clusterGitRepos(t) {
+		r := r
+		t.Run(name, func(t *testing.T) {
+			err := r.CreateCluster(ctx, createParams)
+			assert.Nil(t, err)
+
+			err = r.HardDeleteCluster(ctx, application, cluster)
+			assert.Nil(t, err)
+
+			_, err = r.GetConfigCommit(ctx, application, cluster)
+			_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+			assert.True(t, ok)
+		})
+	}
 }
 
 func TestGetClusterValueFile(t *testing.T) {
@@ -603,9 +796,9 @@ java:
 		[]byte(output), nil).AnyTimes()
 	gitlabmockLib.EXPECT().WriteFiles(gomock.Any(), gomock.Any(),
 		gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
-		func(ctx, pid, branch, commitMsg, startBranch, actions interface{}) (string, error) {
+		func(ctx, pid, branch, commitMsg, startBranch, actions interface{}) (*gitlab.Commit, error) {
 			output = actions.([]gitlablib.CommitAction)[0].Content
-			return "", nil
+			return &gitlab.Commit{}, nil
 		}).AnyTimes()
 	gitlabmockLib.EXPECT().GetCreatedGroup(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(&gitlab.Group{}, nil).AnyTimes()
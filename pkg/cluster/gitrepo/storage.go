@@ -0,0 +1,302 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitrepo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitlib "github.com/horizoncd/horizon/lib/git"
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/xanzy/go-gitlab"
+)
+
+// repoStorage is where the gitops repos of clusters are stored,
+// a repo is identified by the pid returned from repoPID.
+type repoStorage interface {
+	repoPID(application, cluster string) string
+	// createRepo creates the repo of cluster, the default branch must be initialized
+	createRepo(ctx context.Context, application, cluster string) error
+	// recycleRepo moves the repo of cluster to recycling place and renames it to {cluster}-{clusterID}
+	recycleRepo(ctx context.Context, application, cluster string, clusterID uint) error
+	deleteRepo(ctx context.Context, application, cluster string) error
+	getFile(ctx context.Context, pid, ref, filepath string) ([]byte, error)
+	writeFiles(ctx context.Context, pid, branch, commitMsg string,
+		actions []gitlablib.CommitAction) (string, error)
+	getBranchCommit(ctx context.Context, pid, branch string) (string, error)
+	createBranch(ctx context.Context, pid, branch, fromRef string) error
+	compare(ctx context.Context, pid, from, to string, straight *bool) ([]*gitlab.Diff, error)
+	mergeBranch(ctx context.Context, pid, sourceBranch, targetBranch, title string) (string, error)
+	repoURL(ctx context.Context, pid string) string
+}
+
+type gitlabStorage struct {
+	gitlabLib              gitlablib.Interface
+	clustersGroup          *gitlab.Group
+	recyclingClustersGroup *gitlab.Group
+	defaultVisibility      string
+}
+
+var _ repoStorage = (*gitlabStorage)(nil)
+
+func (s *gitlabStorage) repoPID(application, cluster string) string {
+	return fmt.Sprintf("%v/%v/%v", s.clustersGroup.FullPath, application, cluster)
+}
+
+func (s *gitlabStorage) createRepo(ctx context.Context, application, cluster string) error {
+	// 1. create application group if necessary
+	appGroup, err := s.gitlabLib.GetCreatedGroup(ctx, s.clustersGroup.ID,
+		s.clustersGroup.FullPath, application, s.defaultVisibility)
+	if err != nil {
+		return err
+	}
+
+	// 2. create cluster repo under appGroup
+	_, err = s.gitlabLib.CreateProject(ctx, cluster, appGroup.ID, s.defaultVisibility)
+	return err
+}
+
+func (s *gitlabStorage) recycleRepo(ctx context.Context, application, cluster string, clusterID uint) error {
+	// 1. create application group if necessary
+	_, err := s.gitlabLib.GetGroup(ctx, fmt.Sprintf("%v/%v", s.recyclingClustersGroup.FullPath, application))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+		_, err = s.gitlabLib.CreateGroup(ctx, application, application,
+			&s.recyclingClustersGroup.ID, s.defaultVisibility)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 2. delete gitlab project
+	pid := s.repoPID(application, cluster)
+	// 2.1 edit project's name and path to {cluster}-{clusterID}
+	newName := fmt.Sprintf("%v-%d", cluster, clusterID)
+	newPath := newName
+	if err := s.gitlabLib.EditNameAndPathForProject(ctx, pid, &newName, &newPath); err != nil {
+		return err
+	}
+
+	// 2.2 transfer project to RecyclingParent
+	newPid := s.repoPID(application, newPath)
+	return s.gitlabLib.TransferProject(ctx, newPid,
+		fmt.Sprintf("%v/%v", s.recyclingClustersGroup.FullPath, application))
+}
+
+func (s *gitlabStorage) deleteRepo(ctx context.Context, application, cluster string) error {
+	return s.gitlabLib.DeleteProject(ctx, s.repoPID(application, cluster))
+}
+
+func (s *gitlabStorage) getFile(ctx context.Context, pid, ref, filepath string) ([]byte, error) {
+	return s.gitlabLib.GetFile(ctx, pid, ref, filepath)
+}
+
+func (s *gitlabStorage) writeFiles(ctx context.Context, pid, branch, commitMsg string,
+	actions []gitlablib.CommitAction) (string, error) {
+	commit, err := s.gitlabLib.WriteFiles(ctx, pid, branch, commitMsg, nil, actions)
+	if err != nil {
+		return "", err
+	}
+	return commit.ID, nil
+}
+
+func (s *gitlabStorage) getBranchCommit(ctx context.Context, pid, branch string) (string, error) {
+	b, err := s.gitlabLib.GetBranch(ctx, pid, branch)
+	if err != nil {
+		return "", err
+	}
+	return b.Commit.ID, nil
+}
+
+func (s *gitlabStorage) createBranch(ctx context.Context, pid, branch, fromRef string) error {
+	_, err := s.gitlabLib.CreateBranch(ctx, pid, branch, fromRef)
+	return err
+}
+
+func (s *gitlabStorage) compare(ctx context.Context, pid, from, to string,
+	straight *bool) ([]*gitlab.Diff, error) {
+	compare, err := s.gitlabLib.Compare(ctx, pid, from, to, straight)
+	if err != nil {
+		return nil, err
+	}
+	return compare.Diffs, nil
+}
+
+func (s *gitlabStorage) mergeBranch(ctx context.Context, pid, sourceBranch,
+	targetBranch, title string) (string, error) {
+	removeSourceBranch := false
+
+	var mr *gitlab.MergeRequest
+	mrs, err := s.gitlabLib.ListMRs(ctx, pid, sourceBranch,
+		targetBranch, common.GitopsMergeRequestStateOpen)
+	if err != nil {
+		return "", perror.WithMessage(err, "failed to list merge requests")
+	}
+	if len(mrs) > 0 {
+		// merge old mr when it is existed, because given specified source and target, gitlab only allows 1 mr to exist
+		mr = mrs[0]
+
+		// close the redundant mrs
+		// gitlab has a bug for when concurrency create merge request(will exist 2 more merge request for the same
+		// (source,target), caused we can't merge anymore)
+		if len(mrs) >= 2 {
+			log.Warningf(ctx, "there %d mrs for (src:%s, des:%s), here will kill redundant mrs",
+				len(mrs), sourceBranch, targetBranch)
+			for i := 1; i < len(mrs); i++ {
+				_, err := s.gitlabLib.CloseMR(ctx, pid, mrs[i].IID)
+				if err != nil {
+					return "", err
+				}
+			}
+		}
+	} else {
+		// create new mr
+		mr, err = s.gitlabLib.CreateMR(ctx, pid, sourceBranch, targetBranch, title)
+		if err != nil {
+			return "", perror.WithMessage(err, "failed to create new merge request")
+		}
+	}
+
+	mr, err = s.gitlabLib.AcceptMR(ctx, pid, mr.IID, &title, &removeSourceBranch)
+	if err != nil {
+		return "", perror.WithMessage(err, "failed to accept merge request")
+	}
+	return mr.MergeCommitSHA, nil
+}
+
+func (s *gitlabStorage) repoURL(ctx context.Context, pid string) string {
+	return fmt.Sprintf("%v/%v.git", s.gitlabLib.GetHTTPURL(ctx), pid)
+}
+
+// gitStorage stores repos in any git server through lib/git.
+// Repos are placed at {rootPath}/clusters/{application}/{cluster}, if flat is true,
+// the segments are joined by '.' instead, for servers which don't support nested paths, such as gitea.
+type gitStorage struct {
+	gitLib   gitlib.Interface
+	rootPath string
+	flat     bool
+	cloneURL string
+}
+
+var _ repoStorage = (*gitStorage)(nil)
+
+func (s *gitStorage) join(segments ...string) string {
+	if s.rootPath != "" {
+		segments = append([]string{s.rootPath}, segments...)
+	}
+	if s.flat {
+		return strings.Join(segments, ".")
+	}
+	return path.Join(segments...)
+}
+
+func (s *gitStorage) repoPID(application, cluster string) string {
+	return s.join(common.GitopsGroupClusters, application, cluster)
+}
+
+func (s *gitStorage) createRepo(ctx context.Context, application, cluster string) error {
+	return s.gitLib.CreateRepo(ctx, s.repoPID(application, cluster))
+}
+
+func (s *gitStorage) recycleRepo(ctx context.Context, application, cluster string, clusterID uint) error {
+	return s.gitLib.MoveRepo(ctx, s.repoPID(application, cluster),
+		s.join(common.GitopsGroupRecyclingClusters, application, fmt.Sprintf("%v-%d", cluster, clusterID)))
+}
+
+func (s *gitStorage) deleteRepo(ctx context.Context, application, cluster string) error {
+	return s.gitLib.DeleteRepo(ctx, s.repoPID(application, cluster))
+}
+
+func (s *gitStorage) getFile(ctx context.Context, pid, ref, filepath string) ([]byte, error) {
+	return s.gitLib.GetFile(ctx, pid, ref, filepath)
+}
+
+func (s *gitStorage) writeFiles(ctx context.Context, pid, branch, commitMsg string,
+	actions []gitlablib.CommitAction) (string, error) {
+	gitActions := make([]gitlib.CommitAction, 0, len(actions))
+	for _, action := range actions {
+		gitActions = append(gitActions, gitlib.CommitAction{
+			Action:       gitlib.FileAction(action.Action),
+			FilePath:     action.FilePath,
+			Content:      action.Content,
+			PreviousPath: action.PreviousPath,
+		})
+	}
+	commit, err := s.gitLib.WriteFiles(ctx, pid, branch, commitMsg, nil, gitActions)
+	if err != nil {
+		return "", err
+	}
+	return commit.ID, nil
+}
+
+func (s *gitStorage) getBranchCommit(ctx context.Context, pid, branch string) (string, error) {
+	commit, err := s.gitLib.GetBranch(ctx, pid, branch)
+	if err != nil {
+		return "", err
+	}
+	return commit.ID, nil
+}
+
+func (s *gitStorage) createBranch(ctx context.Context, pid, branch, fromRef string) error {
+	_, err := s.gitLib.CreateBranch(ctx, pid, branch, fromRef)
+	return err
+}
+
+func (s *gitStorage) compare(ctx context.Context, pid, from, to string,
+	straight *bool) ([]*gitlab.Diff, error) {
+	diffs, err := s.gitLib.Compare(ctx, pid, from, to, straight != nil && *straight)
+	if err != nil {
+		return nil, err
+	}
+	if len(diffs) == 0 {
+		return nil, nil
+	}
+	ret := make([]*gitlab.Diff, 0, len(diffs))
+	for _, diff := range diffs {
+		ret = append(ret, &gitlab.Diff{
+			OldPath:     diff.OldPath,
+			NewPath:     diff.NewPath,
+			Diff:        diff.Diff,
+			NewFile:     diff.NewFile,
+			RenamedFile: diff.RenamedFile,
+			DeletedFile: diff.DeletedFile,
+		})
+	}
+	return ret, nil
+}
+
+func (s *gitStorage) mergeBranch(ctx context.Context, pid, sourceBranch,
+	targetBranch, title string) (string, error) {
+	commit, err := s.gitLib.MergeBranch(ctx, pid, sourceBranch, targetBranch, title)
+	if err != nil {
+		return "", perror.WithMessage(err, "failed to merge branch")
+	}
+	return commit.ID, nil
+}
+
+func (s *gitStorage) repoURL(ctx context.Context, pid string) string {
+	if s.cloneURL != "" {
+		return fmt.Sprintf("%v/%v.git", strings.TrimSuffix(s.cloneURL, "/"), pid)
+	}
+	return s.gitLib.GetRepoURL(pid)
+}
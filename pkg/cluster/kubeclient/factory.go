@@ -30,6 +30,7 @@ var (
 	Fty = NewFactory()
 )
 
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/cluster/kubeclient/factory_mock.go -package=mock_kubeclient
 type Factory interface {
 	GetByK8SServer(server, certificate string) (*rest.Config, *kube.Client, error)
 }
@@ -0,0 +1,181 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mockserver
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type Image struct {
+	Digest       string
+	ConfigDigest string
+	Created      time.Time
+}
+
+type RegistryServer struct {
+	R *mux.Router
+	// Repositories maps repository name to tags
+	Repositories map[string]map[string]*Image
+}
+
+func NewRegistryServer() *RegistryServer {
+	r := mux.NewRouter()
+	s := &RegistryServer{
+		R:            r,
+		Repositories: map[string]map[string]*Image{},
+	}
+	r.Path("/v2/{name:.+}/tags/list").Methods(http.MethodGet).HandlerFunc(s.ListTags)
+	r.Path("/v2/{name:.+}/manifests/{reference}").
+		Methods(http.MethodGet, http.MethodHead).HandlerFunc(s.GetManifest)
+	r.Path("/v2/{name:.+}/manifests/{reference}").Methods(http.MethodDelete).HandlerFunc(s.DeleteManifest)
+	r.Path("/v2/{name:.+}/blobs/{digest}").Methods(http.MethodGet).HandlerFunc(s.GetBlob)
+	return s
+}
+
+// PushImage pushes an image created at the specified time, returns its digest
+func (s *RegistryServer) PushImage(repository string, tag string, created time.Time) string {
+	if _, ok := s.Repositories[repository]; !ok {
+		s.Repositories[repository] = map[string]*Image{}
+	}
+	image := &Image{
+		Digest:       digest(repository + tag + created.String()),
+		ConfigDigest: digest(created.String()),
+		Created:      created,
+	}
+	s.Repositories[repository][tag] = image
+	return image.Digest
+}
+
+// TagImage adds a new tag for an existing image
+func (s *RegistryServer) TagImage(repository string, tag string, newTag string) {
+	if image, ok := s.Repositories[repository][tag]; ok {
+		s.Repositories[repository][newTag] = image
+	}
+}
+
+func digest(content string) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(content)))
+}
+
+func (s *RegistryServer) ListTags(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	repo, ok := s.Repositories[name]
+	if !ok {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("repository %s not found", name))
+		return
+	}
+	tags := make([]string, 0, len(repo))
+	for tag := range repo {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	last := r.URL.Query().Get("last")
+	n, err := strconv.Atoi(r.URL.Query().Get("n"))
+	if err != nil || n < 1 {
+		n = len(tags)
+	}
+	start := sort.SearchStrings(tags, last)
+	if last != "" && start < len(tags) && tags[start] == last {
+		start++
+	}
+	end := start + n
+	if end < len(tags) {
+		w.Header().Set("Link", fmt.Sprintf("</v2/%s/tags/list?n=%d&last=%s>; rel=\"next\"", name, n, tags[end-1]))
+	} else {
+		end = len(tags)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"name": name,
+		"tags": tags[start:end],
+	})
+}
+
+func (s *RegistryServer) getImage(name, reference string) *Image {
+	for tag, image := range s.Repositories[name] {
+		if tag == reference || image.Digest == reference {
+			return image
+		}
+	}
+	return nil
+}
+
+func (s *RegistryServer) GetManifest(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	image := s.getImage(vars["name"], vars["reference"])
+	if image == nil {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("manifest %s not found", vars["reference"]))
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+	w.Header().Set("Docker-Content-Digest", image.Digest)
+	if r.Method == http.MethodHead {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.docker.distribution.manifest.v2+json",
+		"config": map[string]interface{}{
+			"mediaType": "application/vnd.docker.container.image.v1+json",
+			"digest":    image.ConfigDigest,
+		},
+	})
+}
+
+// DeleteManifest deletes the manifest referenced by digest, with all tags of it
+func (s *RegistryServer) DeleteManifest(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name, reference := vars["name"], vars["reference"]
+	found := false
+	for tag, image := range s.Repositories[name] {
+		if image.Digest == reference {
+			delete(s.Repositories[name], tag)
+			found = true
+		}
+	}
+	if !found {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("manifest %s not found", reference))
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *RegistryServer) GetBlob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	for _, image := range s.Repositories[vars["name"]] {
+		if image.ConfigDigest == vars["digest"] {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"created": image.Created,
+			})
+			return
+		}
+	}
+	s.responseError(w, http.StatusNotFound, fmt.Errorf("blob %s not found", vars["digest"]))
+}
+
+func (s *RegistryServer) responseError(w http.ResponseWriter, code int, err error) {
+	w.WriteHeader(code)
+	if err != nil {
+		_, _ = w.Write([]byte(err.Error()))
+	}
+}
@@ -0,0 +1,332 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distribution
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// kind registry speaks the OCI distribution API, such as Docker Registry v2 / distribution
+const kind = "registry"
+
+// default params
+const (
+	_backoffDuration = 1 * time.Second
+	_retry           = 3
+	_timeout         = 4 * time.Second
+	_pageSize        = 100
+)
+
+const (
+	_headerContentDigest = "Docker-Content-Digest"
+	_headerLink          = "Link"
+)
+
+var _manifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}
+
+func init() {
+	registry.Register(kind, NewDistributionRegistry)
+}
+
+// Registry implement Registry
+type Registry struct {
+	// registry server address
+	server string
+	// registry token, base64 encoded username:password
+	token string
+	// path prefix
+	path string
+	// retryableClient retryable client
+	retryableClient *retryablehttp.Client
+}
+
+func NewDistributionRegistry(config *registry.Config) (registry.Registry, error) {
+	transport := http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: config.InsecureSkipVerify,
+		},
+	}
+	return &Registry{
+		server: strings.TrimSuffix(config.Server, "/"),
+		token:  config.Token,
+		path:   config.Path,
+		retryableClient: &retryablehttp.Client{
+			HTTPClient: &http.Client{
+				Transport: &transport,
+				Timeout:   _timeout,
+			},
+			RetryMax:   _retry,
+			CheckRetry: retryablehttp.DefaultRetryPolicy,
+			Backoff: func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+				// wait for this duration if failed
+				return _backoffDuration
+			},
+		},
+	}, nil
+}
+
+// DeleteImage deletes all manifests of the repository,
+// distribution API does not support deleting a repository directly
+func (r *Registry) DeleteImage(ctx context.Context, appName string, clusterName string) error {
+	const op = "registry: delete repository"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	names, err := r.listTagNames(ctx, appName, clusterName)
+	if err != nil {
+		return err
+	}
+	deleted := make(map[string]struct{})
+	for _, name := range names {
+		digest, err := r.getDigest(ctx, appName, clusterName, name)
+		if err != nil {
+			return err
+		}
+		if _, ok := deleted[digest]; ok || digest == "" {
+			continue
+		}
+		if err := r.deleteManifest(ctx, appName, clusterName, digest); err != nil {
+			return err
+		}
+		deleted[digest] = struct{}{}
+	}
+	return nil
+}
+
+func (r *Registry) ListImageTags(ctx context.Context, appName string, clusterName string) ([]*registry.Tag, error) {
+	const op = "registry: list image tags"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	names, err := r.listTagNames(ctx, appName, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]*registry.Tag, 0, len(names))
+	for _, name := range names {
+		tag, err := r.getTag(ctx, appName, clusterName, name)
+		if err != nil {
+			return nil, err
+		}
+		if tag != nil {
+			tags = append(tags, tag)
+		}
+	}
+	registry.SortTags(tags)
+	return tags, nil
+}
+
+func (r *Registry) DeleteTagsOlderThan(ctx context.Context, appName string,
+	clusterName string, before time.Time, inUse []string) ([]*registry.Tag, error) {
+	const op = "registry: delete tags older than"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	tags, err := r.ListImageTags(ctx, appName, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	expired := registry.ExpiredTags(tags, before, inUse)
+	deleted := make(map[string]struct{})
+	for _, tag := range expired {
+		if _, ok := deleted[tag.Digest]; ok {
+			continue
+		}
+		if err := r.deleteManifest(ctx, appName, clusterName, tag.Digest); err != nil {
+			return nil, err
+		}
+		deleted[tag.Digest] = struct{}{}
+	}
+	return expired, nil
+}
+
+func (r *Registry) repositoryLink(appName string, clusterName string) string {
+	return fmt.Sprintf("%s%s", r.server, path.Join("/v2", r.path, appName, clusterName))
+}
+
+func (r *Registry) listTagNames(ctx context.Context, appName string, clusterName string) ([]string, error) {
+	names := make([]string, 0)
+	link := fmt.Sprintf("%s/tags/list?n=%d", r.repositoryLink(appName, clusterName), _pageSize)
+	for link != "" {
+		resp, err := r.sendHTTPRequest(ctx, http.MethodGet, link)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			_ = resp.Body.Close()
+			return names, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			msg := common.Response(ctx, resp)
+			_ = resp.Body.Close()
+			return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, msg)
+		}
+		var tagList struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&tagList)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		names = append(names, tagList.Tags...)
+
+		link, err = r.nextLink(resp.Header.Get(_headerLink))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// nextLink parses link header like: </v2/<name>/tags/list?n=<n>&last=<last>>; rel="next"
+func (r *Registry) nextLink(header string) (string, error) {
+	if header == "" {
+		return "", nil
+	}
+	start, end := strings.Index(header, "<"), strings.Index(header, ">")
+	if start == -1 || end < start {
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "invalid link header: %s", header)
+	}
+	next, err := url.Parse(header[start+1 : end])
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "invalid link header: %s", header)
+	}
+	base, err := url.Parse(r.server)
+	if err != nil {
+		return "", perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return base.ResolveReference(next).String(), nil
+}
+
+type manifest struct {
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+}
+
+// getTag resolves the digest and push time of the tag. The creation time of
+// the image config is taken as push time, which is unknown for image indexes.
+func (r *Registry) getTag(ctx context.Context, appName string, clusterName string, name string) (*registry.Tag, error) {
+	link := fmt.Sprintf("%s/manifests/%s", r.repositoryLink(appName, clusterName), url.PathEscape(name))
+	resp, err := r.sendHTTPRequest(ctx, http.MethodGet, link)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		// tag is deleted after listed
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+	var m manifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	tag := &registry.Tag{
+		Name:   name,
+		Digest: resp.Header.Get(_headerContentDigest),
+	}
+	if m.Config.Digest == "" {
+		return tag, nil
+	}
+
+	link = fmt.Sprintf("%s/blobs/%s", r.repositoryLink(appName, clusterName), m.Config.Digest)
+	blobResp, err := r.sendHTTPRequest(ctx, http.MethodGet, link)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = blobResp.Body.Close() }()
+	if blobResp.StatusCode != http.StatusOK {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, blobResp))
+	}
+	var config struct {
+		Created time.Time `json:"created"`
+	}
+	if err := json.NewDecoder(blobResp.Body).Decode(&config); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	tag.PushedAt = config.Created
+	return tag, nil
+}
+
+func (r *Registry) getDigest(ctx context.Context, appName string, clusterName string, name string) (string, error) {
+	link := fmt.Sprintf("%s/manifests/%s", r.repositoryLink(appName, clusterName), url.PathEscape(name))
+	resp, err := r.sendHTTPRequest(ctx, http.MethodHead, link)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"failed to get digest of tag %s, status code = %d", name, resp.StatusCode)
+	}
+	return resp.Header.Get(_headerContentDigest), nil
+}
+
+func (r *Registry) deleteManifest(ctx context.Context, appName string, clusterName string, digest string) error {
+	link := fmt.Sprintf("%s/manifests/%s", r.repositoryLink(appName, clusterName), digest)
+	resp, err := r.sendHTTPRequest(ctx, http.MethodDelete, link)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+}
+
+func (r *Registry) sendHTTPRequest(ctx context.Context, method string, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed, err.Error())
+	}
+	req.Header.Set("Accept", strings.Join(_manifestMediaTypes, ", "))
+	if r.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Basic %s", r.token))
+	}
+	retryableReq, err := retryablehttp.FromRequest(req)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed, err.Error())
+	}
+	resp, err := r.retryableClient.Do(retryableReq)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed, err.Error())
+	}
+	return resp, nil
+}
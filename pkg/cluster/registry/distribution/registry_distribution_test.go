@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distribution
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	"github.com/horizoncd/horizon/pkg/cluster/registry/distribution/mockserver"
+	"github.com/stretchr/testify/assert"
+)
+
+var config = &registry.Config{Kind: kind}
+var server = mockserver.NewRegistryServer()
+
+func TestMain(m *testing.M) {
+	s := httptest.NewServer(http.HandlerFunc(server.R.ServeHTTP))
+	config.Server = "http://" + s.Listener.Addr().String()
+	os.Exit(m.Run())
+}
+
+func TestByMock(t *testing.T) {
+	config.Path = "project1"
+	rg, err := registry.NewRegistry(config)
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	tags, err := rg.ListImageTags(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(tags))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	repository := "project1/horizon-demo/horizon-demo-dev"
+	digests := make([]string, 0)
+	for i := 0; i < _pageSize+1; i++ {
+		digests = append(digests, server.PushImage(repository, fmt.Sprintf("v%03d", i),
+			now.Add(time.Duration(i-_pageSize)*time.Hour)))
+	}
+	server.TagImage(repository, fmt.Sprintf("v%03d", _pageSize), "latest")
+
+	tags, err = rg.ListImageTags(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, _pageSize+2, len(tags))
+	assert.Equal(t, digests[_pageSize], tags[0].Digest)
+	assert.Equal(t, now, tags[0].PushedAt)
+
+	// the image in use is retained
+	deleted, err := rg.DeleteTagsOlderThan(ctx, "horizon-demo", "horizon-demo-dev", now.Add(-time.Hour),
+		[]string{"registry.com/project1/horizon-demo/horizon-demo-dev:v000"})
+	assert.Nil(t, err)
+	assert.Equal(t, _pageSize-2, len(deleted))
+
+	tags, err = rg.ListImageTags(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(tags))
+
+	err = rg.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	tags, err = rg.ListImageTags(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(tags))
+}
@@ -15,9 +15,11 @@
 package mockserver
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -37,7 +39,13 @@ type ProjectMember struct {
 
 type ProjectRepository struct {
 	Name string
-	Tags []string
+	Tags []*RepositoryTag
+}
+
+type RepositoryTag struct {
+	Name     string
+	Digest   string
+	PushTime time.Time
 }
 
 type HarborServer struct {
@@ -54,6 +62,11 @@ func NewHarborServer() *HarborServer {
 		projectID: 1,
 	}
 
+	// tag routes must be registered before repository route, which matches them as well
+	r.Path("/api/repositories/{project}/{repository:[0-9a-zA-Z/-]+}/tags").
+		Methods(http.MethodGet).HandlerFunc(s.ListTags)
+	r.Path("/api/repositories/{project}/{repository:[0-9a-zA-Z/-]+}/tags/{tag}").
+		Methods(http.MethodDelete).HandlerFunc(s.DeleteTag)
 	r.Path("/api/repositories/{project}/{repository:[0-9a-zA-Z/-]+}").
 		Methods(http.MethodDelete).HandlerFunc(s.DeleteRepository)
 	return s
@@ -87,6 +100,11 @@ func (s *HarborServer) CreateProject(projectName string, metadata map[string]str
 }
 
 func (s *HarborServer) PushImage(projectName string, repository string, tag string) {
+	s.PushImageAt(projectName, repository, tag, "sha256:"+tag, time.Now())
+}
+
+func (s *HarborServer) PushImageAt(projectName string, repository string,
+	tag string, digest string, pushTime time.Time) {
 	if projectName == "" || repository == "" || tag == "" {
 		return
 	}
@@ -110,11 +128,12 @@ func (s *HarborServer) PushImage(projectName string, repository string, tag stri
 		}
 	}
 	if repo != nil {
-		s.Projects[projectID].Repositories[index].Tags = append(s.Projects[projectID].Repositories[index].Tags, tag)
+		s.Projects[projectID].Repositories[index].Tags = append(s.Projects[projectID].Repositories[index].Tags,
+			&RepositoryTag{Name: tag, Digest: digest, PushTime: pushTime})
 	} else {
 		s.Projects[projectID].Repositories = append(s.Projects[projectID].Repositories, &ProjectRepository{
 			Name: repository,
-			Tags: []string{tag},
+			Tags: []*RepositoryTag{{Name: tag, Digest: digest, PushTime: pushTime}},
 		})
 	}
 }
@@ -153,6 +172,68 @@ func (s *HarborServer) DeleteRepository(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+func (s *HarborServer) getRepository(project, repository string) *ProjectRepository {
+	for _, p := range s.Projects {
+		if p.Name != project {
+			continue
+		}
+		for _, repo := range p.Repositories {
+			if repo.Name == repository {
+				return repo
+			}
+		}
+	}
+	return nil
+}
+
+func (s *HarborServer) ListTags(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repo := s.getRepository(vars["project"], vars["repository"])
+	if repo == nil {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("repository %s not found", vars["repository"]))
+		return
+	}
+	type tag struct {
+		Name     string    `json:"name"`
+		Digest   string    `json:"digest"`
+		PushTime time.Time `json:"push_time"`
+	}
+	tags := make([]tag, 0, len(repo.Tags))
+	for _, t := range repo.Tags {
+		tags = append(tags, tag{Name: t.Name, Digest: t.Digest, PushTime: t.PushTime})
+	}
+	_ = json.NewEncoder(w).Encode(tags)
+}
+
+// DeleteTag deletes the manifest of the tag, tags sharing the manifest are deleted as well
+func (s *HarborServer) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repo := s.getRepository(vars["project"], vars["repository"])
+	if repo == nil {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("repository %s not found", vars["repository"]))
+		return
+	}
+	digest := ""
+	for _, t := range repo.Tags {
+		if t.Name == vars["tag"] {
+			digest = t.Digest
+			break
+		}
+	}
+	if digest == "" {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("tag %s not found", vars["tag"]))
+		return
+	}
+	tags := make([]*RepositoryTag, 0, len(repo.Tags))
+	for _, t := range repo.Tags {
+		if t.Digest != digest {
+			tags = append(tags, t)
+		}
+	}
+	repo.Tags = tags
+	w.WriteHeader(http.StatusOK)
+}
+
 func (s *HarborServer) responseError(w http.ResponseWriter, code int, err error) {
 	w.WriteHeader(code)
 	if err != nil {
@@ -17,9 +17,11 @@ package v1
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"path"
 	"strconv"
 	"strings"
@@ -95,9 +97,7 @@ func (h *Registry) DeleteImage(ctx context.Context, appName string, clusterName
 	const op = "registry: delete repository"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	link := path.Join("/api/repositories", h.path, appName, clusterName)
-
-	link = fmt.Sprintf("%s%s", strings.TrimSuffix(h.server, "/"), link)
+	link := h.repositoryLink(appName, clusterName)
 
 	resp, err := h.sendHTTPRequest(ctx, http.MethodDelete, link, nil, true, "deleteRepository")
 	if err != nil {
@@ -112,6 +112,77 @@ func (h *Registry) DeleteImage(ctx context.Context, appName string, clusterName
 	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
 }
 
+type tag struct {
+	Name     string    `json:"name"`
+	Digest   string    `json:"digest"`
+	PushTime time.Time `json:"push_time"`
+}
+
+func (h *Registry) repositoryLink(appName string, clusterName string) string {
+	link := path.Join("/api/repositories", h.path, appName, clusterName)
+	return fmt.Sprintf("%s%s", strings.TrimSuffix(h.server, "/"), link)
+}
+
+func (h *Registry) ListImageTags(ctx context.Context, appName string, clusterName string) ([]*registry.Tag, error) {
+	const op = "registry: list image tags"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	link := fmt.Sprintf("%s/tags", h.repositoryLink(appName, clusterName))
+	resp, err := h.sendHTTPRequest(ctx, http.MethodGet, link, nil, true, "listTags")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return []*registry.Tag{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+
+	var harborTags []*tag
+	if err := json.NewDecoder(resp.Body).Decode(&harborTags); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	tags := make([]*registry.Tag, 0, len(harborTags))
+	for _, t := range harborTags {
+		tags = append(tags, &registry.Tag{
+			Name:     t.Name,
+			Digest:   t.Digest,
+			PushedAt: t.PushTime,
+		})
+	}
+	registry.SortTags(tags)
+	return tags, nil
+}
+
+func (h *Registry) DeleteTagsOlderThan(ctx context.Context, appName string,
+	clusterName string, before time.Time, inUse []string) ([]*registry.Tag, error) {
+	const op = "registry: delete tags older than"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	tags, err := h.ListImageTags(ctx, appName, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	expired := registry.ExpiredTags(tags, before, inUse)
+	for _, t := range expired {
+		link := fmt.Sprintf("%s/tags/%s", h.repositoryLink(appName, clusterName), url.PathEscape(t.Name))
+		resp, err := h.sendHTTPRequest(ctx, http.MethodDelete, link, nil, true, "deleteTag")
+		if err != nil {
+			return nil, err
+		}
+		_ = resp.Body.Close()
+		// tags sharing a manifest are deleted together, so not found is expected
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+			return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+				"failed to delete tag %s, status code = %d", t.Name, resp.StatusCode)
+		}
+	}
+	return expired, nil
+}
+
 func (h *Registry) sendHTTPRequest(ctx context.Context, method string,
 	url string, body io.Reader, retry bool, operation string) (*http.Response, error) {
 	begin := time.Now()
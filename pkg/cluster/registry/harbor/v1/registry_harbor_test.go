@@ -20,6 +20,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/horizoncd/horizon/pkg/cluster/registry"
 	"github.com/horizoncd/horizon/pkg/cluster/registry/harbor/v1/mockserver"
@@ -49,3 +50,36 @@ func TestByMock(t *testing.T) {
 	err = h.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
 	assert.Nil(t, err)
 }
+
+func TestTags(t *testing.T) {
+	config.Path = "project2"
+	registry, _ := NewHarborRegistry(config)
+	h := registry.(*Registry)
+	ctx := context.Background()
+
+	tags, err := h.ListImageTags(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(tags))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	server.CreateProject("project2", nil)
+	server.PushImageAt("project2", "horizon-demo/horizon-demo-dev", "v1", "sha256:1", now.Add(-3*time.Hour))
+	server.PushImageAt("project2", "horizon-demo/horizon-demo-dev", "v1-alias", "sha256:1", now.Add(-3*time.Hour))
+	server.PushImageAt("project2", "horizon-demo/horizon-demo-dev", "v2", "sha256:2", now.Add(-2*time.Hour))
+	server.PushImageAt("project2", "horizon-demo/horizon-demo-dev", "v3", "sha256:3", now)
+
+	tags, err = h.ListImageTags(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(tags))
+	assert.Equal(t, "v3", tags[0].Name)
+	assert.Equal(t, now, tags[0].PushedAt)
+
+	deleted, err := h.DeleteTagsOlderThan(ctx, "horizon-demo", "horizon-demo-dev", now.Add(-time.Hour), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(deleted))
+
+	tags, err = h.ListImageTags(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tags))
+	assert.Equal(t, "v3", tags[0].Name)
+}
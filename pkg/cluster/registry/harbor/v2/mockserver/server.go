@@ -15,9 +15,11 @@
 package mockserver
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -37,7 +39,12 @@ type ProjectMember struct {
 
 type ProjectRepository struct {
 	Name string
-	Tags []string
+	Tags []*RepositoryTag
+}
+
+type RepositoryTag struct {
+	Name     string
+	PushTime time.Time
 }
 
 type HarborServer struct {
@@ -53,6 +60,10 @@ func NewHarborServer() *HarborServer {
 		Projects:  map[string]*HarborProject{},
 		projectID: 1,
 	}
+	r.Path("/api/v2.0/projects/{project}/repositories/{repository:.+}/artifacts").
+		Methods(http.MethodGet).HandlerFunc(s.ListArtifacts)
+	r.Path("/api/v2.0/projects/{project}/repositories/{repository:.+}/artifacts/{reference}").
+		Methods(http.MethodDelete).HandlerFunc(s.DeleteArtifact)
 	r.Path("/api/v2.0/projects/{project}/repositories/{repository}").
 		Methods(http.MethodDelete).HandlerFunc(s.DeleteRepository)
 	return s
@@ -86,6 +97,10 @@ func (s *HarborServer) CreateProject(projectName string, metadata map[string]str
 }
 
 func (s *HarborServer) PushImage(projectName string, repository string, tag string) {
+	s.PushImageAt(projectName, repository, tag, time.Now())
+}
+
+func (s *HarborServer) PushImageAt(projectName string, repository string, tag string, pushTime time.Time) {
 	if projectName == "" || repository == "" || tag == "" {
 		return
 	}
@@ -109,11 +124,12 @@ func (s *HarborServer) PushImage(projectName string, repository string, tag stri
 		}
 	}
 	if repo != nil {
-		s.Projects[projectID].Repositories[index].Tags = append(s.Projects[projectID].Repositories[index].Tags, tag)
+		s.Projects[projectID].Repositories[index].Tags = append(s.Projects[projectID].Repositories[index].Tags,
+			&RepositoryTag{Name: tag, PushTime: pushTime})
 	} else {
 		s.Projects[projectID].Repositories = append(s.Projects[projectID].Repositories, &ProjectRepository{
 			Name: repository,
-			Tags: []string{tag},
+			Tags: []*RepositoryTag{{Name: tag, PushTime: pushTime}},
 		})
 	}
 }
@@ -152,6 +168,80 @@ func (s *HarborServer) DeleteRepository(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+func (s *HarborServer) getRepository(project, repository string) *ProjectRepository {
+	for _, p := range s.Projects {
+		if p.Name != project {
+			continue
+		}
+		for _, repo := range p.Repositories {
+			if repo.Name == repository {
+				return repo
+			}
+		}
+	}
+	return nil
+}
+
+// digest every tag is pushed as a distinct artifact
+func digest(tag string) string {
+	return "sha256:" + tag
+}
+
+func (s *HarborServer) ListArtifacts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repo := s.getRepository(vars["project"], vars["repository"])
+	if repo == nil {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("repository %s not found", vars["repository"]))
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	type tag struct {
+		Name     string    `json:"name"`
+		PushTime time.Time `json:"push_time"`
+	}
+	type artifact struct {
+		Digest   string    `json:"digest"`
+		PushTime time.Time `json:"push_time"`
+		Tags     []tag     `json:"tags"`
+	}
+	artifacts := make([]artifact, 0)
+	for i := (page - 1) * pageSize; i < page*pageSize && i < len(repo.Tags); i++ {
+		t := repo.Tags[i]
+		artifacts = append(artifacts, artifact{
+			Digest:   digest(t.Name),
+			PushTime: t.PushTime,
+			Tags:     []tag{{Name: t.Name, PushTime: t.PushTime}},
+		})
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(repo.Tags)))
+	_ = json.NewEncoder(w).Encode(artifacts)
+}
+
+func (s *HarborServer) DeleteArtifact(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repo := s.getRepository(vars["project"], vars["repository"])
+	if repo == nil {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("repository %s not found", vars["repository"]))
+		return
+	}
+	for i, t := range repo.Tags {
+		if digest(t.Name) == vars["reference"] {
+			repo.Tags = append(repo.Tags[:i], repo.Tags[i+1:]...)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+	s.responseError(w, http.StatusNotFound, fmt.Errorf("artifact %s not found", vars["reference"]))
+}
+
 func (s *HarborServer) responseError(w http.ResponseWriter, code int, err error) {
 	w.WriteHeader(code)
 	if err != nil {
@@ -17,6 +17,7 @@ package v2
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -42,6 +43,7 @@ const (
 	_backoffDuration = 1 * time.Second
 	_retry           = 3
 	_timeout         = 4 * time.Second
+	_pageSize        = 100
 )
 
 func init() {
@@ -96,10 +98,7 @@ func (h *Registry) DeleteImage(ctx context.Context, appName string, clusterName
 	const op = "registry: delete repository"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	link := path.Join("/api/v2.0/projects", h.path, "repositories",
-		url.PathEscape(path.Join(appName, clusterName)))
-
-	link = fmt.Sprintf("%s%s", strings.TrimSuffix(h.server, "/"), link)
+	link := h.repositoryLink(appName, clusterName)
 
 	resp, err := h.sendHTTPRequest(ctx, http.MethodDelete, link, nil, true, "deleteRepository")
 	if err != nil {
@@ -114,6 +113,95 @@ func (h *Registry) DeleteImage(ctx context.Context, appName string, clusterName
 	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
 }
 
+type artifact struct {
+	Digest   string    `json:"digest"`
+	PushTime time.Time `json:"push_time"`
+	Tags     []struct {
+		Name     string    `json:"name"`
+		PushTime time.Time `json:"push_time"`
+	} `json:"tags"`
+}
+
+func (h *Registry) repositoryLink(appName string, clusterName string) string {
+	link := path.Join("/api/v2.0/projects", h.path, "repositories",
+		url.PathEscape(path.Join(appName, clusterName)))
+	return fmt.Sprintf("%s%s", strings.TrimSuffix(h.server, "/"), link)
+}
+
+func (h *Registry) ListImageTags(ctx context.Context, appName string, clusterName string) ([]*registry.Tag, error) {
+	const op = "registry: list image tags"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	tags := make([]*registry.Tag, 0)
+	for page := 1; ; page++ {
+		link := fmt.Sprintf("%s/artifacts?with_tag=true&page=%d&page_size=%d",
+			h.repositoryLink(appName, clusterName), page, _pageSize)
+		resp, err := h.sendHTTPRequest(ctx, http.MethodGet, link, nil, true, "listArtifacts")
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			_ = resp.Body.Close()
+			return tags, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			msg := common.Response(ctx, resp)
+			_ = resp.Body.Close()
+			return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, msg)
+		}
+		var artifacts []*artifact
+		err = json.NewDecoder(resp.Body).Decode(&artifacts)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		for _, a := range artifacts {
+			for _, t := range a.Tags {
+				tags = append(tags, &registry.Tag{
+					Name:     t.Name,
+					Digest:   a.Digest,
+					PushedAt: t.PushTime,
+				})
+			}
+		}
+		if len(artifacts) < _pageSize {
+			break
+		}
+	}
+	registry.SortTags(tags)
+	return tags, nil
+}
+
+func (h *Registry) DeleteTagsOlderThan(ctx context.Context, appName string,
+	clusterName string, before time.Time, inUse []string) ([]*registry.Tag, error) {
+	const op = "registry: delete tags older than"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	tags, err := h.ListImageTags(ctx, appName, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	expired := registry.ExpiredTags(tags, before, inUse)
+	deleted := make(map[string]struct{})
+	for _, tag := range expired {
+		if _, ok := deleted[tag.Digest]; ok {
+			continue
+		}
+		link := fmt.Sprintf("%s/artifacts/%s", h.repositoryLink(appName, clusterName), tag.Digest)
+		resp, err := h.sendHTTPRequest(ctx, http.MethodDelete, link, nil, true, "deleteArtifact")
+		if err != nil {
+			return nil, err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+			return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+				"failed to delete artifact %s, status code = %d", tag.Digest, resp.StatusCode)
+		}
+		deleted[tag.Digest] = struct{}{}
+	}
+	return expired, nil
+}
+
 func (h *Registry) sendHTTPRequest(ctx context.Context, method string,
 	url string, body io.Reader, retry bool, operation string) (*http.Response, error) {
 	begin := time.Now()
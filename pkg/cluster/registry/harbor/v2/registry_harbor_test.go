@@ -16,10 +16,12 @@ package v2
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/horizoncd/horizon/pkg/cluster/registry"
 	"github.com/horizoncd/horizon/pkg/cluster/registry/harbor/v2/mockserver"
@@ -49,3 +51,35 @@ func TestByMock(t *testing.T) {
 	err = h.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
 	assert.Nil(t, err)
 }
+
+func TestTags(t *testing.T) {
+	config.Path = "project2"
+	registry, _ := NewHarborRegistry(config)
+	h := registry.(*Registry)
+	ctx := context.Background()
+
+	tags, err := h.ListImageTags(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(tags))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	server.CreateProject("project2", nil)
+	for i := 0; i < _pageSize+1; i++ {
+		server.PushImageAt("project2", "horizon-demo/horizon-demo-dev", fmt.Sprintf("v%d", i),
+			now.Add(time.Duration(i-_pageSize)*time.Hour))
+	}
+
+	tags, err = h.ListImageTags(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, _pageSize+1, len(tags))
+	assert.Equal(t, fmt.Sprintf("v%d", _pageSize), tags[0].Name)
+	assert.Equal(t, now, tags[0].PushedAt)
+
+	deleted, err := h.DeleteTagsOlderThan(ctx, "horizon-demo", "horizon-demo-dev", now.Add(-time.Hour), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, _pageSize-1, len(deleted))
+
+	tags, err = h.ListImageTags(ctx, "horizon-demo", "horizon-demo-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(tags))
+}
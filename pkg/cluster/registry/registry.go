@@ -16,6 +16,9 @@ package registry
 
 import (
 	"context"
+	"sort"
+	"strings"
+	"time"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
@@ -45,6 +48,74 @@ func Register(kind string, constructor Constructor) {
 type Registry interface {
 	// DeleteImage delete repository
 	DeleteImage(ctx context.Context, appName string, clusterName string) error
+	// ListImageTags list tags of the cluster's repository, sorted by push time desc
+	ListImageTags(ctx context.Context, appName string, clusterName string) ([]*Tag, error)
+	// DeleteTagsOlderThan delete tags pushed before the specified time except the tags of images in use,
+	// returns the deleted tags
+	DeleteTagsOlderThan(ctx context.Context, appName string, clusterName string,
+		before time.Time, inUse []string) ([]*Tag, error)
+}
+
+type Tag struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest"`
+	// PushedAt is zero if the registry cannot tell when the tag is pushed
+	PushedAt time.Time `json:"pushedAt"`
+}
+
+// ExpiredTags returns tags pushed before the specified time.
+// Deleting a tag deletes its manifest as well, so tags sharing a digest with
+// any retained tag are never expired, neither are tags with unknown push time or digest,
+// nor tags of the images in use, which are image references like registry.com/app/cluster:tag.
+func ExpiredTags(tags []*Tag, before time.Time, inUse []string) []*Tag {
+	inUseTags := make(map[string]struct{}, len(inUse))
+	inUseDigests := make(map[string]struct{}, len(inUse))
+	for _, image := range inUse {
+		tag, digest := parseImage(image)
+		if tag != "" {
+			inUseTags[tag] = struct{}{}
+		}
+		if digest != "" {
+			inUseDigests[digest] = struct{}{}
+		}
+	}
+
+	retained := make(map[string]struct{})
+	for _, tag := range tags {
+		_, tagInUse := inUseTags[tag.Name]
+		_, digestInUse := inUseDigests[tag.Digest]
+		if tag.PushedAt.IsZero() || !tag.PushedAt.Before(before) || tagInUse || digestInUse {
+			retained[tag.Digest] = struct{}{}
+		}
+	}
+	expired := make([]*Tag, 0)
+	for _, tag := range tags {
+		if tag.Digest == "" {
+			continue
+		}
+		if _, ok := retained[tag.Digest]; !ok {
+			expired = append(expired, tag)
+		}
+	}
+	return expired
+}
+
+// parseImage returns the tag and digest of an image reference, either may be empty
+func parseImage(image string) (tag, digest string) {
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		image, digest = image[:i], image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		tag = image[i+1:]
+	}
+	return tag, digest
+}
+
+// SortTags sorts tags by push time desc
+func SortTags(tags []*Tag) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].PushedAt.After(tags[j].PushedAt)
+	})
 }
 
 type Config struct {
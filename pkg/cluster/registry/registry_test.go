@@ -0,0 +1,57 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiredTags(t *testing.T) {
+	now := time.Now()
+	tags := []*Tag{
+		{Name: "v1", Digest: "sha256:1", PushedAt: now.Add(-48 * time.Hour)},
+		{Name: "v2", Digest: "sha256:2", PushedAt: now.Add(-48 * time.Hour)},
+		// v2-latest shares manifest with v2
+		{Name: "v2-latest", Digest: "sha256:2", PushedAt: now},
+		{Name: "v3", Digest: "sha256:3", PushedAt: now},
+		// push time unknown
+		{Name: "v4", Digest: "sha256:4"},
+		// in use by tag or by digest
+		{Name: "v5", Digest: "sha256:5", PushedAt: now.Add(-48 * time.Hour)},
+		{Name: "v6", Digest: "sha256:6", PushedAt: now.Add(-48 * time.Hour)},
+		// digest unknown
+		{Name: "v7", PushedAt: now.Add(-48 * time.Hour)},
+	}
+	inUse := []string{"registry.com:5000/app/cluster:v5", "registry.com:5000/app/cluster@sha256:6"}
+
+	expired := ExpiredTags(tags, now.Add(-24*time.Hour), inUse)
+	assert.Equal(t, []*Tag{tags[0]}, expired)
+	expired = ExpiredTags(tags, now.Add(-24*time.Hour), nil)
+	assert.Equal(t, []*Tag{tags[0], tags[5], tags[6]}, expired)
+
+	SortTags(tags)
+	assert.Equal(t, "v4", tags[len(tags)-1].Name)
+	assert.Equal(t, "v1", tags[2].Name)
+
+	tag, digest := parseImage("registry.com:5000/app/cluster:v1@sha256:1")
+	assert.Equal(t, "v1", tag)
+	assert.Equal(t, "sha256:1", digest)
+	tag, digest = parseImage("registry.com:5000/app/cluster")
+	assert.Equal(t, "", tag)
+	assert.Equal(t, "", digest)
+}
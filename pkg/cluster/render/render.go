@@ -0,0 +1,311 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	ActionAdded   = "added"
+	ActionRemoved = "removed"
+	ActionChanged = "changed"
+
+	_globalKey        = "global"
+	_notesFile        = "NOTES.txt"
+	_defaultNamespace = "default"
+)
+
+// Object is a kubernetes object rendered from the chart of cluster
+type Object struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	Manifest   string
+}
+
+// ObjectDiff is the difference of an object between two renderings
+type ObjectDiff struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	Action     string `json:"action"`
+	Diff       string `json:"diff"`
+}
+
+type Params struct {
+	TemplateName string
+	ReleaseName  string
+	Namespace    string
+	Files        *gitrepo.RenderFiles
+}
+
+// Renderer renders manifests of clusters locally like helm template, without ArgoCD
+//
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/cluster/render/mock_render.go -package=mock_render
+type Renderer interface {
+	// Render renders the template declared in Chart.yaml of files with value files in order
+	Render(ctx context.Context, params *Params) ([]*Object, error)
+}
+
+type renderer struct {
+	templateRepo       templaterepo.TemplateRepo
+	templateReleaseMgr trmanager.Manager
+}
+
+func NewRenderer(repo templaterepo.TemplateRepo, manager *managerparam.Manager) Renderer {
+	return &renderer{
+		templateRepo:       repo,
+		templateReleaseMgr: manager.TemplateReleaseManager,
+	}
+}
+
+func (r *renderer) Render(ctx context.Context, params *Params) ([]*Object, error) {
+	const op = "cluster renderer: render"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	var dependency *gitrepo.Dependency
+	for i := range params.Files.Chart.Dependencies {
+		if params.Files.Chart.Dependencies[i].Name != "" && params.Files.Chart.Dependencies[i].Version != "" {
+			dependency = &params.Files.Chart.Dependencies[i]
+			break
+		}
+	}
+	if dependency == nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "no dependency in chart of cluster")
+	}
+
+	templateChart, err := r.getChart(ctx, params.TemplateName, dependency)
+	if err != nil {
+		return nil, err
+	}
+	values, err := mergeValues(dependency.Name, params.Files.ValueFiles)
+	if err != nil {
+		return nil, err
+	}
+	return Render(templateChart, values, params.ReleaseName, params.Namespace)
+}
+
+// getChart gets chart of the dependency, the chart name of release is preferred
+// since dependency name is renamed from it
+func (r *renderer) getChart(ctx context.Context, templateName string,
+	dependency *gitrepo.Dependency) (*chart.Chart, error) {
+	releases, err := r.templateReleaseMgr.ListByTemplateName(ctx, templateName)
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range releases {
+		if release.ChartVersion == dependency.Version &&
+			strings.ReplaceAll(release.ChartName, ".", "_") == dependency.Name {
+			return r.templateRepo.GetChart(release.ChartName, release.ChartVersion, release.LastSyncAt)
+		}
+	}
+	return r.templateRepo.GetChart(dependency.Name, dependency.Version, time.Time{})
+}
+
+// mergeValues merges values of the dependency and global values in value files, latter files take precedence
+func mergeValues(dependencyName string, valueFiles []gitrepo.RenderValueFile) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, valueFile := range valueFiles {
+		var content map[string]interface{}
+		if err := yaml.Unmarshal(valueFile.Content, &content); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"yaml Unmarshal err, file = %s: %v", valueFile.FileName, err)
+		}
+		if dependencyValues, ok := content[dependencyName].(map[string]interface{}); ok {
+			mergeMaps(values, dependencyValues)
+		}
+		if globalValues, ok := content[_globalKey].(map[string]interface{}); ok {
+			mergeMaps(values, map[string]interface{}{_globalKey: globalValues})
+		}
+	}
+	return values, nil
+}
+
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, ok := v.(map[string]interface{})
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dstMap, ok := dst[k].(map[string]interface{})
+		if !ok {
+			dstMap = map[string]interface{}{}
+			dst[k] = dstMap
+		}
+		mergeMaps(dstMap, srcMap)
+	}
+}
+
+// Render renders the chart with values like `helm template`
+func Render(c *chart.Chart, values map[string]interface{}, releaseName, namespace string) ([]*Object, error) {
+	if namespace == "" {
+		namespace = _defaultNamespace
+	}
+	if err := chartutil.ProcessDependencies(c, values); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to process dependencies: %v", err)
+	}
+	renderValues, err := chartutil.ToRenderValues(c, values,
+		chartutil.ReleaseOptions{Name: releaseName, Namespace: namespace, IsInstall: true, Revision: 1}, nil)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to compose values: %v", err)
+	}
+	rendered, err := engine.Render(c, renderValues)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to render chart: %v", err)
+	}
+
+	var objects []*Object
+	for name, content := range rendered {
+		base := path.Base(name)
+		if strings.HasPrefix(base, "_") || base == _notesFile {
+			continue
+		}
+		for _, manifest := range releaseutil.SplitManifests(content) {
+			object, err := parseObject(manifest, namespace)
+			if err != nil {
+				return nil, perror.Wrapf(err, "failed to parse manifest in %s", name)
+			}
+			if object != nil {
+				objects = append(objects, object)
+			}
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].key() < objects[j].key()
+	})
+	return objects, nil
+}
+
+func parseObject(manifest, namespace string) (*Object, error) {
+	var head struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal([]byte(manifest), &head); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	// empty document
+	if head.Kind == "" {
+		return nil, nil
+	}
+	if head.Metadata.Namespace != "" {
+		namespace = head.Metadata.Namespace
+	}
+	return &Object{
+		APIVersion: head.APIVersion,
+		Kind:       head.Kind,
+		Namespace:  namespace,
+		Name:       head.Metadata.Name,
+		Manifest:   strings.TrimSpace(manifest) + "\n",
+	}, nil
+}
+
+func (o *Object) key() string {
+	return objectKey(o.APIVersion, o.Kind, o.Namespace, o.Name)
+}
+
+func objectKey(apiVersion, kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", apiVersion, kind, namespace, name)
+}
+
+// Diff compares objects rendered from `from` with `to`, unchanged objects are omitted
+func Diff(from, to []*Object) ([]*ObjectDiff, error) {
+	fromObjects := make(map[string]*Object, len(from))
+	for _, object := range from {
+		fromObjects[object.key()] = object
+	}
+	toObjects := make(map[string]*Object, len(to))
+	for _, object := range to {
+		toObjects[object.key()] = object
+	}
+
+	var diffs []*ObjectDiff
+	appendDiff := func(object *Object, action, fromManifest, toManifest string) error {
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(fromManifest),
+			B:        difflib.SplitLines(toManifest),
+			FromFile: "from/" + object.key(),
+			ToFile:   "to/" + object.key(),
+			Context:  3,
+		})
+		if err != nil {
+			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		diffs = append(diffs, &ObjectDiff{
+			APIVersion: object.APIVersion,
+			Kind:       object.Kind,
+			Namespace:  object.Namespace,
+			Name:       object.Name,
+			Action:     action,
+			Diff:       diff,
+		})
+		return nil
+	}
+
+	for _, object := range from {
+		toObject, ok := toObjects[object.key()]
+		if !ok {
+			if err := appendDiff(object, ActionRemoved, object.Manifest, ""); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if toObject.Manifest != object.Manifest {
+			if err := appendDiff(object, ActionChanged, object.Manifest, toObject.Manifest); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, object := range to {
+		if _, ok := fromObjects[object.key()]; !ok {
+			if err := appendDiff(object, ActionAdded, "", object.Manifest); err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return objectKey(diffs[i].APIVersion, diffs[i].Kind, diffs[i].Namespace, diffs[i].Name) <
+			objectKey(diffs[j].APIVersion, diffs[j].Kind, diffs[j].Namespace, diffs[j].Name)
+	})
+	return diffs, nil
+}
@@ -0,0 +1,203 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	trmock "github.com/horizoncd/horizon/mock/pkg/templaterelease/manager"
+	repomock "github.com/horizoncd/horizon/mock/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+)
+
+const (
+	helpersTpl = `{{- define "javaapp.labels" -}}
+app: {{ .Release.Name }}
+{{- end -}}`
+	deploymentTpl = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+{{ include "javaapp.labels" . | indent 4 }}
+spec:
+  replicas: {{ .Values.app.spec.replicas }}
+  template:
+    spec:
+      containers:
+      - image: {{ .Values.image }}
+        env:
+        - name: ENV
+          value: {{ .Values.global.env | quote }}
+`
+	serviceTpl = `{{- if .Values.app.service.enabled }}
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}
+  namespace: shared
+{{- end }}
+`
+	configMapTpl = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-files
+data:
+  config: {{ .Files.Get "files/config.properties" | quote }}
+`
+)
+
+func javaappChart() *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "javaapp", Version: "v1.0.0-5e5193b3"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(helpersTpl)},
+			{Name: "templates/deployment.yaml", Data: []byte(deploymentTpl)},
+			{Name: "templates/service.yaml", Data: []byte(serviceTpl)},
+			{Name: "templates/configmap.yaml", Data: []byte(configMapTpl)},
+			{Name: "templates/NOTES.txt", Data: []byte("thanks for using javaapp")},
+		},
+		Values: map[string]interface{}{
+			"image": "nginx",
+			"app": map[string]interface{}{
+				"spec":    map[string]interface{}{"replicas": 1},
+				"service": map[string]interface{}{"enabled": false},
+			},
+			"global": map[string]interface{}{"env": "dev"},
+		},
+		Files: []*chart.File{{Name: "files/config.properties", Data: []byte("a=b")}},
+	}
+}
+
+func renderFiles(application string) *gitrepo.RenderFiles {
+	return &gitrepo.RenderFiles{
+		Chart: &gitrepo.Chart{
+			APIVersion: "v2",
+			Name:       "cluster",
+			Version:    "1.0.0",
+			Dependencies: []gitrepo.Dependency{{
+				Name:    "javaapp",
+				Version: "v1.0.0-5e5193b3",
+			}},
+		},
+		ValueFiles: []gitrepo.RenderValueFile{
+			{FileName: "application.yaml", Content: []byte(application)},
+			{FileName: "system/env.yaml", Content: []byte("javaapp:\n  image: tomcat\nglobal:\n  env: test\n")},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	repo := repomock.NewMockTemplateRepo(mockCtl)
+	templateReleaseMgr := trmock.NewMockManager(mockCtl)
+	r := &renderer{
+		templateRepo:       repo,
+		templateReleaseMgr: templateReleaseMgr,
+	}
+
+	now := time.Now()
+	templateReleaseMgr.EXPECT().ListByTemplateName(gomock.Any(), "javaapp").Return([]*trmodels.TemplateRelease{
+		{ChartName: "javaapp", ChartVersion: "v0.0.1", LastSyncAt: now},
+		{ChartName: "javaapp", ChartVersion: "v1.0.0-5e5193b3", LastSyncAt: now},
+	}, nil).Times(2)
+	repo.EXPECT().GetChart("javaapp", "v1.0.0-5e5193b3", now).Return(javaappChart(), nil).Times(2)
+
+	ctx := context.TODO()
+	from, err := r.Render(ctx, &Params{
+		TemplateName: "javaapp",
+		ReleaseName:  "cluster",
+		Namespace:    "ns",
+		Files:        renderFiles("javaapp:\n  app:\n    spec:\n      replicas: 2\n"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(from))
+	assert.Equal(t, "Deployment", from[0].Kind)
+	assert.Equal(t, "ns", from[0].Namespace)
+	assert.Equal(t, "cluster", from[0].Name)
+	assert.True(t, strings.Contains(from[0].Manifest, "replicas: 2"))
+	assert.True(t, strings.Contains(from[0].Manifest, "image: tomcat"))
+	assert.True(t, strings.Contains(from[0].Manifest, `value: "test"`))
+	assert.Equal(t, "ConfigMap", from[1].Kind)
+	assert.True(t, strings.Contains(from[1].Manifest, `config: "a=b"`))
+
+	to, err := r.Render(ctx, &Params{
+		TemplateName: "javaapp",
+		ReleaseName:  "cluster",
+		Namespace:    "ns",
+		Files: renderFiles("javaapp:\n  app:\n    spec:\n      replicas: 3\n" +
+			"    service:\n      enabled: true\n"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(to))
+
+	diffs, err := Diff(from, to)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(diffs))
+	assert.Equal(t, "Deployment", diffs[0].Kind)
+	assert.Equal(t, ActionChanged, diffs[0].Action)
+	assert.True(t, strings.Contains(diffs[0].Diff, "-  replicas: 2"))
+	assert.True(t, strings.Contains(diffs[0].Diff, "+  replicas: 3"))
+	assert.Equal(t, "Service", diffs[1].Kind)
+	assert.Equal(t, "shared", diffs[1].Namespace)
+	assert.Equal(t, ActionAdded, diffs[1].Action)
+
+	diffs, err = Diff(to, from)
+	assert.Nil(t, err)
+	assert.Equal(t, ActionRemoved, diffs[1].Action)
+
+	diffs, err = Diff(from, from)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(diffs))
+}
+
+func TestRenderWithHelmV3(t *testing.T) {
+	c := javaappChart()
+	c.Templates = append(c.Templates, &chart.File{Name: "templates/release.yaml", Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-release
+data:
+  service: {{ .Release.Service }}
+  kubeVersion: {{ .Capabilities.KubeVersion.Version }}
+`)})
+	objects, err := Render(c, map[string]interface{}{}, "cluster", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(objects))
+	assert.Equal(t, "default", objects[2].Namespace)
+	assert.Equal(t, "cluster-release", objects[2].Name)
+	assert.True(t, strings.Contains(objects[2].Manifest, "service: Helm"))
+	assert.True(t, strings.Contains(objects[2].Manifest, "kubeVersion: v1."))
+
+	// values are validated by the schema of chart
+	c.Schema = []byte(`{"type": "object", "properties": {"image": {"type": "string"}}}`)
+	_, err = Render(c, map[string]interface{}{"image": 1}, "cluster", "")
+	assert.NotNil(t, err)
+
+	// templates of library chart are not rendered
+	c.Schema = nil
+	c.Metadata.Type = "library"
+	objects, err = Render(c, map[string]interface{}{}, "cluster", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(objects))
+}
@@ -16,6 +16,7 @@ package collector
 
 import (
 	"context"
+	"regexp"
 	"strconv"
 
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
@@ -60,6 +61,15 @@ type (
 	}
 )
 
+// LogMatch is a line of pipelinerun log matching the searched pattern
+type LogMatch struct {
+	Task string `json:"task"`
+	Step string `json:"step"`
+	// LineNumber is the line number in the whole log, starting from 1
+	LineNumber int    `json:"lineNumber"`
+	Line       string `json:"line"`
+}
+
 type Log struct {
 	LogChannel <-chan log.Log
 	ErrChannel <-chan error
@@ -110,6 +120,12 @@ type Interface interface {
 
 	// GetPipelineRun gets tekton pipelinerun
 	GetPipelineRun(ctx context.Context, pr *prmodels.Pipelinerun) (*v1beta1.PipelineRun, error)
+
+	// DeleteCollected deletes log & object of pipelinerun from collector
+	DeleteCollected(ctx context.Context, pr *prmodels.Pipelinerun) error
+
+	// SearchPipelineRunLog searches lines matching the pattern in pipelinerun log collected
+	SearchPipelineRunLog(ctx context.Context, pr *prmodels.Pipelinerun, pattern *regexp.Regexp) ([]*LogMatch, error)
 }
 
 var _ Interface = (*S3Collector)(nil)
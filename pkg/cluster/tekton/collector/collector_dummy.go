@@ -16,6 +16,7 @@ package collector
 
 import (
 	"context"
+	"regexp"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton"
@@ -89,3 +90,14 @@ func (c *DummyCollector) GetPipelineRun(ctx context.Context,
 	}
 	return tektonPipelineRun, nil
 }
+
+func (c *DummyCollector) DeleteCollected(ctx context.Context, pr *prmodels.Pipelinerun) error {
+	// no storage to delete from
+	return nil
+}
+
+func (c *DummyCollector) SearchPipelineRunLog(ctx context.Context,
+	pr *prmodels.Pipelinerun, pattern *regexp.Regexp) ([]*LogMatch, error) {
+	// no log is collected
+	return nil, nil
+}
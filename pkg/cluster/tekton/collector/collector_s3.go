@@ -15,6 +15,7 @@
 package collector
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -24,6 +25,7 @@ import (
 	"log"
 	"os"
 	"path"
+	"regexp"
 	"strconv"
 	"time"
 
@@ -198,6 +200,10 @@ func (c *S3Collector) GetPipelineRunLog(ctx context.Context, pr *prmodels.Pipeli
 			LogBytes: logBytes,
 		}, nil
 	}
+	// logs collected into s3 have been expired by retention policy
+	if pr.S3Bucket != "" {
+		return nil, herrors.NewErrNotFound(herrors.PipelinerunLog, "pipelineRun log has been expired")
+	}
 
 	// else, get logs from k8s directly
 	logCh, errCh, err := c.tekton.GetPipelineRunLogByID(ctx, pr.CIEventID)
@@ -271,6 +277,66 @@ func (c *S3Collector) GetPipelineRun(ctx context.Context,
 	return tektonPipelineRun, nil
 }
 
+func (c *S3Collector) DeleteCollected(ctx context.Context, pr *prmodels.Pipelinerun) error {
+	const op = "s3Collector: deleteCollected"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	for _, object := range []string{pr.LogObject, pr.PrObject} {
+		if object == "" {
+			continue
+		}
+		if err := c.s3.DeleteObject(ctx, object); err != nil {
+			return perror.Wrap(herrors.ErrS3DeleteObjFailed, err.Error())
+		}
+	}
+	return nil
+}
+
+// _logLinePrefix matches the prefix of log line written by collectLog, such as "[build : compile] "
+var _logLinePrefix = regexp.MustCompile(`^\[(.+?) : (.+?)\] `)
+
+func (c *S3Collector) SearchPipelineRunLog(ctx context.Context,
+	pr *prmodels.Pipelinerun, pattern *regexp.Regexp) ([]*LogMatch, error) {
+	const op = "s3Collector: searchPipelineRunLog"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// only logs collected into s3 are searched
+	if pr.LogObject == "" {
+		return nil, nil
+	}
+	logBytes, err := c.getPipelineRunLog(ctx, pr.LogObject)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	matches := make([]*LogMatch, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(logBytes))
+	scanner.Buffer(make([]byte, 0, 64*1024), _mb)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		task, step, content := "", "", line
+		if groups := _logLinePrefix.FindStringSubmatch(line); groups != nil {
+			task, step, content = groups[1], groups[2], line[len(groups[0]):]
+		}
+		if !pattern.MatchString(content) {
+			continue
+		}
+		matches = append(matches, &LogMatch{
+			Task:       task,
+			Step:       step,
+			LineNumber: lineNumber,
+			Line:       content,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, perror.Wrap(herrors.ErrReadFailed, err.Error())
+	}
+	return matches, nil
+}
+
 type CollectObjectResult struct {
 	PrObject string
 	PrURL    string
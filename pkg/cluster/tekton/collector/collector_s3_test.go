@@ -19,11 +19,14 @@ import (
 	"encoding/json"
 	"net/http/httptest"
 	"reflect"
+	"regexp"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
 	"github.com/horizoncd/horizon/pkg/server/global"
 	"github.com/johannesboyne/gofakes3"
@@ -135,4 +138,30 @@ func TestS3Collector_Collect(t *testing.T) {
 	if !reflect.DeepEqual(tektonPR, pr) {
 		t.Fatalf("pipelineRun objectMeta: expected %v, got %v", objectMeta, obj.Metadata)
 	}
+
+	// 4. searchPipelineRunLog
+	matches, err := c.SearchPipelineRunLog(ctx, prModel, regexp.MustCompile(regexp.QuoteMeta("line3")))
+	assert.Nil(t, err)
+	assert.Equal(t, []*LogMatch{
+		{Task: "test-task", Step: "test-step", LineNumber: 4, Line: "line3"},
+	}, matches)
+	matches, err = c.SearchPipelineRunLog(ctx, prModel, regexp.MustCompile(`^line[5-6]$`))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(matches))
+	assert.Equal(t, 6, matches[0].LineNumber)
+	assert.Equal(t, 7, matches[1].LineNumber)
+
+	// 5. deleteCollected
+	assert.Nil(t, c.DeleteCollected(ctx, prModel))
+	_, err = c.GetPipelineRunObject(ctx, collectResult.PrObject)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	matches, err = c.SearchPipelineRunLog(ctx, prModel, regexp.MustCompile("line"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(matches))
+
+	// log of pipelinerun cleared after deleted is expired
+	_, err = c.GetPipelineRunLog(ctx, &prmodels.Pipelinerun{S3Bucket: collectResult.Bucket})
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
 }
@@ -15,6 +15,7 @@
 package factory
 
 import (
+	"fmt"
 	"sync"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -33,6 +34,8 @@ const (
 type Factory interface {
 	GetTekton(environment string) (tekton.Interface, error)
 	GetTektonCollector(environment string) (collector.Interface, error)
+	// GetLogStorage returns the s3 storage which the tekton collector of environment uses
+	GetLogStorage(environment string) (s3.Interface, error)
 }
 
 type factory struct {
@@ -42,6 +45,7 @@ type factory struct {
 type tektonCache struct {
 	tekton          tekton.Interface
 	tektonCollector collector.Interface
+	logStorage      s3.Interface
 }
 
 func NewFactory(tektonMapper tektonconfig.Mapper) (Factory, error) {
@@ -54,8 +58,9 @@ func NewFactory(tektonMapper tektonconfig.Mapper) (Factory, error) {
 			return nil, errors.E(op, err)
 		}
 		var c collector.Interface
+		var s3Driver s3.Interface
 		if tektonConfig.LogStorage.Type == _s3Storage {
-			s3Driver, err := s3.NewDriver(s3.Params{
+			s3Driver, err = s3.NewDriver(s3.Params{
 				AccessKey:        tektonConfig.LogStorage.AccessKey,
 				SecretKey:        tektonConfig.LogStorage.SecretKey,
 				Region:           tektonConfig.LogStorage.Region,
@@ -76,6 +81,7 @@ func NewFactory(tektonMapper tektonconfig.Mapper) (Factory, error) {
 		cache.Store(env, &tektonCache{
 			tekton:          t,
 			tektonCollector: c,
+			logStorage:      s3Driver,
 		})
 	}
 	return &factory{
@@ -110,3 +116,15 @@ func (f factory) GetFromCache(environment string) (*tektonCache, error) {
 	}
 	return ret.(*tektonCache), nil
 }
+
+func (f factory) GetLogStorage(environment string) (s3.Interface, error) {
+	cache, err := f.GetFromCache(environment)
+	if err != nil {
+		return nil, err
+	}
+	if cache.logStorage == nil {
+		return nil, herrors.NewErrNotFound(herrors.LogStorage,
+			fmt.Sprintf("no s3 log storage for environment %s", environment))
+	}
+	return cache.logStorage, nil
+}
@@ -89,16 +89,19 @@ const (
 	MemberSingleDelete               = "update tb_member set deleted_ts = ? where ID = ?"
 	MemberHardDeleteByResourceTypeID = "delete from tb_member where resource_type = ?" +
 		" and resource_id = ?"
-	MemberHardDeleteByMemberNameID = "delete from tb_member where membername_id = ?"
-	// todo: fix user_type to query condition
-	MemberSelectAll = "select m.* from tb_member m join tb_user u on m.membername_id = u.id" +
-		" where m.resource_type = ? and m.resource_id = ? and m.deleted_ts = 0"
+	MemberHardDeleteByMemberNameID = "delete from tb_member where member_type = 0 and membername_id = ?"
+	// members of user type are joined with users, and members of group type are kept, expired members are ignored
+	MemberSelectAll = "select m.* from tb_member m left join tb_user u on m.member_type = 0 and m.membername_id = u.id" +
+		" where m.resource_type = ? and m.resource_id = ? and m.deleted_ts = 0 and (m.member_type = 1 or u.id is not null)" +
+		" and (m.expired_at is null or m.expired_at > ?)"
 	// todo: fix user_type to query condition
 	MemberSelectByUserEmails = "select tb_member.* from tb_member join tb_user on tb_member.membername_id = tb_user.id" +
 		" where tb_member.resource_type = ? and tb_member.resource_id = ? and tb_user.email in ?" +
-		" and tb_member.member_type = 0 and tb_member.deleted_ts = 0 and tb_user.deleted_ts = 0"
+		" and tb_member.member_type = 0 and tb_member.deleted_ts = 0 and tb_user.deleted_ts = 0" +
+		" and (tb_member.expired_at is null or tb_member.expired_at > ?)"
 	MemberListResource = "select resource_id from tb_member where resource_type = ? and" +
-		" membername_id = ? and deleted_ts = 0"
+		" member_type = 0 and membername_id = ? and deleted_ts = 0 and (expired_at is null or expired_at > ?)"
+	MemberListExpired = "select * from tb_member where deleted_ts = 0 and expired_at <= ? order by id limit ?"
 )
 
 /* sql about group */
@@ -123,23 +126,24 @@ const (
 		"and (name = ? or path = ?) and deleted_ts = 0"
 	GroupQueryGroupChildren = "" +
 		"select * from (select g.id, g.name, g.path, description, updated_at, 'group' as type from tb_group g " +
-		"where g.parent_id=? and g.deleted_ts = 0 " +
+		"where g.parent_id=? and g.id not in ? and g.deleted_ts = 0 " +
 		"union " +
 		"select a.id, a.name, a.name as path, description, updated_at, 'application' as type from tb_application a " +
-		"where a.group_id=? and a.deleted_ts = 0) ga " +
+		"where a.group_id=? and a.id not in ? and a.deleted_ts = 0) ga " +
 		"order by ga.type desc,ga.updated_at desc limit ? offset ?"
 	GroupQueryGroupChildrenCount = "" +
 		"select count(1) from (select g.id, g.name, g.path, description, updated_at, 'group' as type from tb_group g " +
-		"where g.parent_id=? and g.deleted_ts = 0 " +
+		"where g.parent_id=? and g.id not in ? and g.deleted_ts = 0 " +
 		"union " +
 		"select a.id, a.name, a.name as path, description, updated_at, 'application' as type from tb_application a " +
-		"where a.group_id=? and a.deleted_ts = 0) ga"
+		"where a.group_id=? and a.id not in ? and a.deleted_ts = 0) ga"
 )
 
 /* sql about application */
 const (
 	ApplicationQueryByIDs             = "select * from tb_application where id in ? and deleted_ts = 0"
 	ApplicationQueryByGroupIDs        = "select * from tb_application where group_id in ? and deleted_ts = 0"
+	ApplicationQueryByVisibilityLevel = "select * from tb_application where visibility_level = ? and deleted_ts = 0"
 	ApplicationQueryByID              = "select * from tb_application where id = ? and deleted_ts = 0"
 	ApplicationQueryByName            = "select * from tb_application where name = ? and deleted_ts = 0"
 	ApplicationQueryByFuzzily         = "select * from tb_application where name like ? and deleted_ts = 0"
@@ -233,6 +237,11 @@ const (
 
 	PipelinerunGetFirstCanRollbackByClusterID = "select * from tb_pipelinerun where cluster_id = ?" +
 		" and action != 'restart' and status = 'ok' order by created_at desc limit 1 offset 0"
+
+	PipelinerunListClusterIDsWithCollected = "select distinct cluster_id from tb_pipelinerun where pr_object != ''"
+	PipelinerunListCollectedByClusterID    = "select * from tb_pipelinerun where cluster_id = ?" +
+		" and pr_object != '' order by id desc limit ? offset ?"
+	PipelinerunClearCollectedByID = "update tb_pipelinerun set log_object = '', pr_object = '' where id = ?"
 )
 
 /* sql about cluster tag */
@@ -0,0 +1,40 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alert
+
+type Config struct {
+	// DefaultQueries are the default PromQL queries in go template format, key is the rule type,
+	// they override the built-in queries of errorRate and restarts
+	DefaultQueries map[string]string `yaml:"defaultQueries"`
+	// RuleLabels are added to the PrometheusRule and AlertmanagerConfig of clusters,
+	// so that they can be selected by the ruleSelector of prometheus and alertmanager
+	RuleLabels map[string]string `yaml:"ruleLabels"`
+	// Receiver is where alertmanager sends the notifications of clusters, horizon creates events
+	// of the alerts and sends them to the webhooks of clusters, so that the urls of webhooks are
+	// never written into the gitops repo. Notifications are not sent if its url is empty.
+	Receiver Receiver `yaml:"receiver"`
+}
+
+type Receiver struct {
+	// URL is the url of horizon receiving notifications, such as https://horizon.com/apis/internal/v2/alerts
+	URL string `yaml:"url"`
+	// Token authenticates alertmanager, it's read by alertmanager from the key TokenSecretKey
+	// of secret TokenSecretName in the namespace of cluster
+	Token           string `yaml:"token"`
+	TokenSecretName string `yaml:"tokenSecretName"`
+	TokenSecretKey  string `yaml:"tokenSecretKey"`
+	// AccountID is the user who creates the events of alerts
+	AccountID uint `yaml:"accountID"`
+}
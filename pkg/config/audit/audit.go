@@ -0,0 +1,24 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+type Config struct {
+	// MaxBodySize is the max bytes of request body recorded, the exceeding part is truncated,
+	// defaults to 64KiB. Only this part is read ahead by audit, the rest is streamed to handlers.
+	MaxBodySize int `yaml:"maxBodySize"`
+	// SkipDiff skips recording the diff between the request body and the resource before updates,
+	// which is recorded by the controllers of applications, clusters, groups, users and webhooks
+	SkipDiff bool `yaml:"skipDiff"`
+}
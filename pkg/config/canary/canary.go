@@ -0,0 +1,33 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import "time"
+
+type Config struct {
+	// Enabled indicates whether the canary analysis job is enabled
+	Enabled bool `yaml:"enabled"`
+	// AccountID is the user who promotes or aborts rollouts
+	AccountID   uint          `yaml:"accountID"`
+	JobInterval time.Duration `yaml:"jobInterval"`
+	// AnalysisDelay is the time to wait after the rollout is paused at a step,
+	// so that metrics of new canary pods can be collected
+	AnalysisDelay time.Duration `yaml:"analysisDelay"`
+	// QueryTimeout is the timeout of each prometheus query
+	QueryTimeout time.Duration `yaml:"queryTimeout"`
+	// DefaultQueries are the default PromQL queries in go template format, key is the rule type,
+	// used by rules whose query is empty, such as errorRate and latency
+	DefaultQueries map[string]string `yaml:"defaultQueries"`
+}
@@ -0,0 +1,45 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import "time"
+
+type Config struct {
+	// QueryTimeout is the timeout of each prometheus query
+	QueryTimeout time.Duration `yaml:"queryTimeout"`
+	// CacheTTL is how long the usage of a cluster is cached
+	CacheTTL time.Duration `yaml:"cacheTTL"`
+	// Headroom is the ratio reserved above the p95 usage when suggesting requests, such as 0.2
+	Headroom float64 `yaml:"headroom"`
+	// Currency is the currency of unit prices, such as CNY
+	Currency string `yaml:"currency"`
+	// DefaultUnitPrice is used by regions without unit price
+	DefaultUnitPrice UnitPrice `yaml:"defaultUnitPrice"`
+	// UnitPrices are the unit prices of regions, key is the region name
+	UnitPrices map[string]UnitPrice `yaml:"unitPrices"`
+	// Queries overwrite the default PromQL queries in go template format, key is the metric,
+	// such as cpuRequest, cpuUsage, cpuPeak, memoryRequest, memoryUsage and memoryPeak.
+	// A query selects the clusters matched by the regular expression {{.Clusters}},
+	// and reports each cluster by the label label_cloudnative_music_netease_com_cluster
+	Queries map[string]string `yaml:"queries"`
+}
+
+// UnitPrice is the price of resources per hour
+type UnitPrice struct {
+	// CPU is the price of one core per hour
+	CPU float64 `yaml:"cpu"`
+	// Memory is the price of one GiB per hour
+	Memory float64 `yaml:"memory"`
+}
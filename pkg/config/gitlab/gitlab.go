@@ -14,6 +14,13 @@
 
 package gitlab
 
+const (
+	// GitopsRepoKindGitlab stores cluster repos in gitlab groups
+	GitopsRepoKindGitlab = "gitlab"
+	// GitopsRepoKindGit stores cluster repos in any git server or a local directory of bare repos
+	GitopsRepoKindGit = "git"
+)
+
 // GitopsRepoConfig gitops repo config
 type GitopsRepoConfig struct {
 	URL               string `yaml:"url"`
@@ -21,4 +28,21 @@ type GitopsRepoConfig struct {
 	RootGroupPath     string `yaml:"rootGroupPath"`
 	DefaultBranch     string `yaml:"defaultBranch"`
 	DefaultVisibility string `yaml:"defaultVisibility"`
+	// ClusterRepo is the storage of cluster repos, defaults to the gitlab above.
+	// For git kind, application repos are stored there as well, and the gitlab above is not used.
+	ClusterRepo ClusterRepoConfig `yaml:"clusterRepo"`
+}
+
+type ClusterRepoConfig struct {
+	// Kind is gitlab or git, defaults to gitlab
+	Kind string `yaml:"kind"`
+	// URL is the root of repos for git kind, such as https://gitea.com/horizon or /data/gitops
+	URL   string `yaml:"url"`
+	Token string `yaml:"token"`
+	// CloneURL is the root URL for argocd to clone repos, defaults to URL
+	CloneURL string `yaml:"cloneURL"`
+	// CacheDir is the local directory to cache repos
+	CacheDir string `yaml:"cacheDir"`
+	// FlatRepoPath joins path segments of repos by '.', for git servers which don't support nested paths
+	FlatRepoPath bool `yaml:"flatRepoPath"`
 }
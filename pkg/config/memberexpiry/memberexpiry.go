@@ -0,0 +1,24 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memberexpiry
+
+import "time"
+
+type Config struct {
+	// JobInterval is the interval to remove expired members
+	JobInterval time.Duration `yaml:"jobInterval"`
+	// BatchSize is the number of expired members listed from db at a time
+	BatchSize int `yaml:"batchSize"`
+}
@@ -16,4 +16,7 @@ package server
 
 type Config struct {
 	Port int `yaml:"port"`
+	// TrustedProxies are the addresses or CIDRs of proxies in front of horizon,
+	// X-Forwarded-For and X-Real-IP are only respected for requests from them
+	TrustedProxies []string `yaml:"trustedProxies"`
 }
@@ -14,6 +14,8 @@
 
 package tekton
 
+import "time"
+
 type Mapper map[string]*Tekton
 
 type Tekton struct {
@@ -33,4 +35,14 @@ type LogStorage struct {
 	DisableSSL       bool   `yaml:"disableSSL"`
 	SkipVerify       bool   `yaml:"skipVerify"`
 	S3ForcePathStyle bool   `yaml:"s3ForcePathStyle"`
+	// Retention is the retention policy of logs and objects in the storage, nil means keeping them forever
+	Retention *Retention `yaml:"retention"`
+}
+
+// Retention expires logs and objects of pipelineruns which are older than MaxAge,
+// or not in the latest KeepLastN pipelineruns of their clusters.
+// Zero MaxAge or KeepLastN means no limit by it.
+type Retention struct {
+	MaxAge    time.Duration `yaml:"maxAge"`
+	KeepLastN int           `yaml:"keepLastN"`
 }
@@ -26,3 +26,12 @@ type Repo struct {
 	CAFile   string `yaml:"caFile"`
 	RepoName string `yaml:"repoName"`
 }
+
+// Source is an OCI registry or helm repository which template charts are pulled from
+type Source struct {
+	// Host is like https://harbor.example.com, https is used if the scheme is absent
+	Host     string `yaml:"host"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Insecure bool   `yaml:"insecure"`
+}
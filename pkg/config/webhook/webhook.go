@@ -23,4 +23,12 @@ type Config struct {
 	WorkerReconcileInterval uint `yaml:"workerReconcileInterval"`
 	// bytes limit to truncate for response body
 	ResponseBodyTruncateSize uint `yaml:"responseBodyTruncateSize"`
+	// default max attempts to send a log, used when the webhook does not specify it
+	MaxAttempts uint `yaml:"maxAttempts"`
+	// seconds to wait before the first retry, doubled for each later retry
+	RetryBackoffBase uint `yaml:"retryBackoffBase"`
+	// max seconds to wait between retries
+	RetryBackoffMax uint `yaml:"retryBackoffMax"`
+	// consecutive failed attempts to disable a webhook automatically
+	DisableThreshold uint `yaml:"disableThreshold"`
 }
@@ -0,0 +1,338 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	costconfig "github.com/horizoncd/horizon/pkg/config/cost"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/prometheus"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	MetricCPURequest    = "cpuRequest"
+	MetricCPUUsage      = "cpuUsage"
+	MetricCPUPeak       = "cpuPeak"
+	MetricMemoryRequest = "memoryRequest"
+	MetricMemoryUsage   = "memoryUsage"
+	MetricMemoryPeak    = "memoryPeak"
+)
+
+const (
+	// SuggestionIncrease means the peak usage exceeds the request
+	SuggestionIncrease = "increase"
+	// SuggestionDecrease means the request is much more than the peak usage
+	SuggestionDecrease = "decrease"
+	// SuggestionKeep means the request fits the peak usage
+	SuggestionKeep = "keep"
+)
+
+const (
+	// _hoursPerMonth is the average hours of a month
+	_hoursPerMonth = 730
+	_bytesPerGiB   = 1 << 30
+	// _tolerance keeps requests which are at most 10% more than the suggested
+	_tolerance       = 0.1
+	_defaultHeadroom = 0.2
+	_defaultCacheTTL = 10 * time.Minute
+	// _maxClustersPerQuery limits the clusters matched by a query, so that the query url is not too long
+	_maxClustersPerQuery = 50
+)
+
+var (
+	// _sumByCluster aggregates a metric of pods by cluster
+	_sumByCluster = `sum by (` + prometheus.ClusterLabel + `) `
+	// _podSelector selects pods of the clusters
+	_podSelector = prometheus.ClusterPodSelector(`=~"{{.Clusters}}"`)
+)
+
+// defaultQueries are evaluated at the end of time range, cpu is in cores and memory is in bytes
+var defaultQueries = map[string]string{
+	MetricCPURequest: `avg_over_time((` + _sumByCluster + `(kube_pod_container_resource_requests{resource="cpu"}` +
+		_podSelector + `))[{{.Range}}:5m])`,
+	MetricCPUUsage: `avg_over_time((` + _sumByCluster + `(rate(container_cpu_usage_seconds_total{container!=""}[5m])` +
+		_podSelector + `))[{{.Range}}:5m])`,
+	MetricCPUPeak: `quantile_over_time(0.95, (` + _sumByCluster +
+		`(rate(container_cpu_usage_seconds_total{container!=""}[5m])` + _podSelector + `))[{{.Range}}:5m])`,
+	MetricMemoryRequest: `avg_over_time((` + _sumByCluster +
+		`(kube_pod_container_resource_requests{resource="memory"}` + _podSelector + `))[{{.Range}}:5m])`,
+	MetricMemoryUsage: `avg_over_time((` + _sumByCluster + `(container_memory_working_set_bytes{container!=""}` +
+		_podSelector + `))[{{.Range}}:5m])`,
+	MetricMemoryPeak: `quantile_over_time(0.95, (` + _sumByCluster +
+		`(container_memory_working_set_bytes{container!=""}` + _podSelector + `))[{{.Range}}:5m])`,
+}
+
+// QueryVars are the variables which can be referenced in the queries,
+// the queries should report each cluster by the label prometheus.ClusterLabel
+type QueryVars struct {
+	// Clusters is the regular expression matching names of the clusters, such as c1|c2
+	Clusters string
+	// Range is the duration between start and end in PromQL format, such as 604800s
+	Range string
+}
+
+// Resource is the request and usage of cpu in cores or memory in GiB
+type Resource struct {
+	Request float64 `json:"request"`
+	// Usage is the average usage
+	Usage float64 `json:"usage"`
+	// Peak is the p95 usage
+	Peak float64 `json:"peak"`
+	// Suggested is the suggested request, which is the peak usage with headroom
+	Suggested  float64 `json:"suggested"`
+	Suggestion string  `json:"suggestion"`
+}
+
+// Usage is the resource usage and the estimated monthly cost by requests
+type Usage struct {
+	CPU                  Resource `json:"cpu"`
+	Memory               Resource `json:"memory"`
+	MonthlyCost          float64  `json:"monthlyCost"`
+	SuggestedMonthlyCost float64  `json:"suggestedMonthlyCost"`
+}
+
+//go:generate mockgen -source=$GOFILE -destination=../../mock/pkg/cost/cost.go -package=mock_cost
+type Reporter interface {
+	// GetUsages gets the resource usages and costs of clusters between start and end by the prometheus of region,
+	// the usages are keyed by cluster name
+	GetUsages(ctx context.Context, region *regionmodels.RegionEntity,
+		clusters []string, start, end time.Time) (map[string]*Usage, error)
+	// Sum sums usages of clusters up, such as the usage of application or group
+	Sum(usages ...*Usage) *Usage
+	// Currency returns the currency of costs
+	Currency() string
+}
+
+type reporter struct {
+	client     *prometheus.Client
+	queries    map[string]*template.Template
+	headroom   float64
+	currency   string
+	unitPrices map[string]costconfig.UnitPrice
+	defaultUP  costconfig.UnitPrice
+	cacheTTL   time.Duration
+
+	lock  sync.Mutex
+	cache map[string]*cachedUsage
+}
+
+type cachedUsage struct {
+	usage     *Usage
+	expiredAt time.Time
+}
+
+func NewReporter(config *costconfig.Config) (Reporter, error) {
+	r := &reporter{
+		client:     prometheus.NewClient(config.QueryTimeout),
+		queries:    make(map[string]*template.Template, len(defaultQueries)),
+		headroom:   config.Headroom,
+		currency:   config.Currency,
+		unitPrices: config.UnitPrices,
+		defaultUP:  config.DefaultUnitPrice,
+		cacheTTL:   config.CacheTTL,
+		cache:      map[string]*cachedUsage{},
+	}
+	if r.headroom <= 0 {
+		r.headroom = _defaultHeadroom
+	}
+	if r.cacheTTL <= 0 {
+		r.cacheTTL = _defaultCacheTTL
+	}
+	for metric, query := range defaultQueries {
+		if q, ok := config.Queries[metric]; ok && q != "" {
+			query = q
+		}
+		tpl, err := template.New(metric).Option("missingkey=error").Parse(query)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "query of %v is invalid: %v", metric, err)
+		}
+		r.queries[metric] = tpl
+	}
+	return r, nil
+}
+
+func (r *reporter) Currency() string {
+	return r.currency
+}
+
+func (r *reporter) GetUsages(ctx context.Context, region *regionmodels.RegionEntity,
+	clusters []string, start, end time.Time) (map[string]*Usage, error) {
+	const op = "cost reporter: get usages"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if region.PrometheusURL == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "prometheus url of region %v is empty", region.Name)
+	}
+	if !start.Before(end) {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "start time should be before end time")
+	}
+
+	usages := make(map[string]*Usage, len(clusters))
+	uncached := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		if usage := r.load(cacheKey(region.Name, cluster, start, end)); usage != nil {
+			usages[cluster] = usage
+		} else {
+			uncached = append(uncached, cluster)
+		}
+	}
+	unitPrice, ok := r.unitPrices[region.Name]
+	if !ok {
+		unitPrice = r.defaultUP
+	}
+	for i := 0; i < len(uncached); i += _maxClustersPerQuery {
+		batch := uncached[i:]
+		if len(batch) > _maxClustersPerQuery {
+			batch = batch[:_maxClustersPerQuery]
+		}
+		values, err := r.query(ctx, region, batch, start, end)
+		if err != nil {
+			return nil, err
+		}
+		// no data means the cluster has no pods in the time range
+		for _, cluster := range batch {
+			usage := &Usage{
+				CPU: Resource{
+					Request: values[MetricCPURequest][cluster],
+					Usage:   values[MetricCPUUsage][cluster],
+					Peak:    values[MetricCPUPeak][cluster],
+				},
+				Memory: Resource{
+					Request: values[MetricMemoryRequest][cluster] / _bytesPerGiB,
+					Usage:   values[MetricMemoryUsage][cluster] / _bytesPerGiB,
+					Peak:    values[MetricMemoryPeak][cluster] / _bytesPerGiB,
+				},
+			}
+			r.rightsize(usage)
+			usage.MonthlyCost = monthlyCost(unitPrice, usage.CPU.Request, usage.Memory.Request)
+			usage.SuggestedMonthlyCost = monthlyCost(unitPrice, usage.CPU.Suggested, usage.Memory.Suggested)
+
+			r.store(cacheKey(region.Name, cluster, start, end), usage)
+			usages[cluster] = usage
+		}
+	}
+	return usages, nil
+}
+
+// query evaluates the queries of all metrics for the clusters, the values are keyed by metric and then cluster
+func (r *reporter) query(ctx context.Context, region *regionmodels.RegionEntity,
+	clusters []string, start, end time.Time) (map[string]map[string]float64, error) {
+	patterns := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		// the backslashes of regular expression are escaped again in the string literal of PromQL
+		patterns = append(patterns, strings.ReplaceAll(regexp.QuoteMeta(cluster), `\`, `\\`))
+	}
+	vars := &QueryVars{
+		Clusters: strings.Join(patterns, "|"),
+		Range:    fmt.Sprintf("%ds", int64(end.Sub(start).Seconds())),
+	}
+	values := make(map[string]map[string]float64, len(r.queries))
+	for metric, tpl := range r.queries {
+		var buf bytes.Buffer
+		if err := tpl.Execute(&buf, vars); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to render query of %v: %v", metric, err)
+		}
+		result, err := r.client.QueryByLabel(ctx, region.PrometheusURL, buf.String(), end, prometheus.ClusterLabel)
+		if err != nil {
+			return nil, err
+		}
+		values[metric] = result
+	}
+	return values, nil
+}
+
+func (r *reporter) Sum(usages ...*Usage) *Usage {
+	sum := &Usage{}
+	add := func(sum, r *Resource) {
+		sum.Request += r.Request
+		sum.Usage += r.Usage
+		sum.Peak += r.Peak
+		sum.Suggested += r.Suggested
+	}
+	for _, usage := range usages {
+		add(&sum.CPU, &usage.CPU)
+		add(&sum.Memory, &usage.Memory)
+		sum.MonthlyCost += usage.MonthlyCost
+		sum.SuggestedMonthlyCost += usage.SuggestedMonthlyCost
+	}
+	sum.CPU.Suggestion = suggest(&sum.CPU)
+	sum.Memory.Suggestion = suggest(&sum.Memory)
+	return sum
+}
+
+// rightsize suggests requests by the peak usage with headroom
+func (r *reporter) rightsize(usage *Usage) {
+	for _, resource := range []*Resource{&usage.CPU, &usage.Memory} {
+		resource.Suggested = resource.Peak * (1 + r.headroom)
+		resource.Suggestion = suggest(resource)
+	}
+}
+
+func suggest(resource *Resource) string {
+	switch {
+	case resource.Suggested > resource.Request:
+		return SuggestionIncrease
+	case resource.Suggested < resource.Request*(1-_tolerance):
+		return SuggestionDecrease
+	default:
+		return SuggestionKeep
+	}
+}
+
+func monthlyCost(unitPrice costconfig.UnitPrice, cpu, memory float64) float64 {
+	return (cpu*unitPrice.CPU + memory*unitPrice.Memory) * _hoursPerMonth
+}
+
+func cacheKey(region, cluster string, start, end time.Time) string {
+	return fmt.Sprintf("%v/%v/%d/%d", region, cluster, start.Unix(), end.Unix())
+}
+
+func (r *reporter) load(key string) *Usage {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if cached, ok := r.cache[key]; ok && time.Now().Before(cached.expiredAt) {
+		return cached.usage
+	}
+	return nil
+}
+
+func (r *reporter) store(key string, usage *Usage) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := time.Now()
+	for k, cached := range r.cache {
+		if !now.Before(cached.expiredAt) {
+			delete(r.cache, k)
+		}
+	}
+	r.cache[key] = &cachedUsage{usage: usage, expiredAt: now.Add(r.cacheTTL)}
+}
+
+// DefaultTimeRange returns the last 7 days ending at the last full hour, so that reports are cached in the hour
+func DefaultTimeRange() (time.Time, time.Time) {
+	end := time.Now().Truncate(time.Hour)
+	return end.Add(-7 * 24 * time.Hour), end
+}
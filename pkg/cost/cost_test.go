@@ -0,0 +1,159 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	costconfig "github.com/horizoncd/horizon/pkg/config/cost"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakePrometheus returns a fake prometheus server, results are the values of clusters keyed by metric name,
+// and the number of requests is recorded by calls
+func newFakePrometheus(t *testing.T, results map[string]map[string]string, end time.Time, calls *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		assert.Equal(t, "/api/v1/query", r.URL.Path)
+		assert.Equal(t, fmt.Sprintf("%d", end.Unix()), r.URL.Query().Get("time"))
+		query := r.URL.Query().Get("query")
+		matched := regexp.MustCompile(`^(\w+)\{cluster=~"([^"]*)"\}`).FindStringSubmatch(query)
+		assert.NotNil(t, matched)
+		samples := make([]string, 0)
+		for _, cluster := range strings.Split(matched[2], "|") {
+			if value, ok := results[matched[1]][cluster]; ok {
+				samples = append(samples, fmt.Sprintf(`{"metric":{"%s":"%s"},"value":[%d,"%s"]}`,
+					prometheus.ClusterLabel, cluster, end.Unix(), value))
+			}
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"status":"success","data":{"resultType":"vector","result":[%s]}}`,
+			strings.Join(samples, ","))))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetUsages(t *testing.T) {
+	ctx := context.Background()
+	end := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	start := end.Add(-24 * time.Hour)
+	calls := 0
+	server := newFakePrometheus(t, map[string]map[string]string{
+		"cpu_request":    {"c1": "4", "c3": "1"},
+		"cpu_usage":      {"c1": "0.8"},
+		"cpu_peak":       {"c1": "1.5"},
+		"memory_request": {"c1": fmt.Sprintf("%d", 8<<30)},
+		"memory_usage":   {"c1": fmt.Sprintf("%d", 6<<30)},
+		"memory_peak":    {"c1": fmt.Sprintf("%d", 7<<30)},
+	}, end, &calls)
+
+	queries := map[string]string{
+		MetricCPURequest:    `cpu_request{cluster=~"{{.Clusters}}"}[{{.Range}}]`,
+		MetricCPUUsage:      `cpu_usage{cluster=~"{{.Clusters}}"}[{{.Range}}]`,
+		MetricCPUPeak:       `cpu_peak{cluster=~"{{.Clusters}}"}[{{.Range}}]`,
+		MetricMemoryRequest: `memory_request{cluster=~"{{.Clusters}}"}[{{.Range}}]`,
+		MetricMemoryUsage:   `memory_usage{cluster=~"{{.Clusters}}"}[{{.Range}}]`,
+		MetricMemoryPeak:    `memory_peak{cluster=~"{{.Clusters}}"}[{{.Range}}]`,
+	}
+	reporter, err := NewReporter(&costconfig.Config{
+		Currency:         "CNY",
+		DefaultUnitPrice: costconfig.UnitPrice{CPU: 0.1, Memory: 0.01},
+		UnitPrices:       map[string]costconfig.UnitPrice{"hz": {CPU: 0.2, Memory: 0.02}},
+		Queries:          queries,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "CNY", reporter.Currency())
+
+	// clusters are queried together, and the cluster without data has no usage
+	region := &regionmodels.RegionEntity{Region: &regionmodels.Region{Name: "hz", PrometheusURL: server.URL}}
+	usages, err := reporter.GetUsages(ctx, region, []string{"c1", "c2"}, start, end)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, calls)
+	assert.Equal(t, 2, len(usages))
+	usage := usages["c1"]
+	assert.Equal(t, float64(4), usage.CPU.Request)
+	assert.Equal(t, 0.8, usage.CPU.Usage)
+	assert.InDelta(t, 1.8, usage.CPU.Suggested, 1e-9)
+	assert.Equal(t, SuggestionDecrease, usage.CPU.Suggestion)
+	assert.Equal(t, float64(8), usage.Memory.Request)
+	assert.Equal(t, float64(6), usage.Memory.Usage)
+	assert.InDelta(t, 8.4, usage.Memory.Suggested, 1e-9)
+	assert.Equal(t, SuggestionIncrease, usage.Memory.Suggestion)
+	assert.InDelta(t, (4*0.2+8*0.02)*730, usage.MonthlyCost, 1e-9)
+	assert.InDelta(t, (1.8*0.2+8.4*0.02)*730, usage.SuggestedMonthlyCost, 1e-9)
+	empty := usages["c2"]
+	assert.Equal(t, float64(0), empty.CPU.Request)
+	assert.Equal(t, SuggestionKeep, empty.CPU.Suggestion)
+	assert.Equal(t, float64(0), empty.MonthlyCost)
+
+	// the usages are cached, and only the uncached clusters are queried
+	cached, err := reporter.GetUsages(ctx, region, []string{"c1", "c3"}, start, end)
+	assert.Nil(t, err)
+	assert.Equal(t, 12, calls)
+	assert.Equal(t, usage, cached["c1"])
+	assert.Equal(t, float64(1), cached["c3"].CPU.Request)
+
+	// clusters are queried by batches, priced by default unit price
+	clusters := make([]string, 0, _maxClustersPerQuery+1)
+	for i := 0; i <= _maxClustersPerQuery; i++ {
+		clusters = append(clusters, fmt.Sprintf("batch-%d", i))
+	}
+	calls = 0
+	other := &regionmodels.RegionEntity{Region: &regionmodels.Region{Name: "sh", PrometheusURL: server.URL}}
+	usages, err = reporter.GetUsages(ctx, other, clusters, start, end)
+	assert.Nil(t, err)
+	assert.Equal(t, 12, calls)
+	assert.Equal(t, len(clusters), len(usages))
+
+	sum := reporter.Sum(usage, empty)
+	assert.Equal(t, float64(4), sum.CPU.Request)
+	assert.Equal(t, SuggestionDecrease, sum.CPU.Suggestion)
+	assert.Equal(t, usage.MonthlyCost, sum.MonthlyCost)
+
+	_, err = reporter.GetUsages(ctx, region, []string{"c1"}, end, start)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = reporter.GetUsages(ctx, &regionmodels.RegionEntity{Region: &regionmodels.Region{Name: "bj"}},
+		[]string{"c1"}, start, end)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
+
+func TestDefaultQueries(t *testing.T) {
+	r, err := NewReporter(&costconfig.Config{})
+	assert.Nil(t, err)
+	var buf bytes.Buffer
+	err = r.(*reporter).queries[MetricCPURequest].Execute(&buf, &QueryVars{Clusters: "c1|c2", Range: "3600s"})
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), `sum by (label_cloudnative_music_netease_com_cluster)`)
+	assert.Contains(t, buf.String(), `kube_pod_labels{label_cloudnative_music_netease_com_cluster=~"c1|c2"}`)
+}
+
+func TestNewReporter(t *testing.T) {
+	_, err := NewReporter(&costconfig.Config{Queries: map[string]string{MetricCPUUsage: "{{.Cluster"}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = NewReporter(&costconfig.Config{})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/drift/models"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	// Create creates a drift of cluster
+	Create(ctx context.Context, drift *models.ClusterDrift) (*models.ClusterDrift, error)
+	// GetUnresolvedByClusterID gets the unresolved drift of cluster
+	GetUnresolvedByClusterID(ctx context.Context, clusterID uint) (*models.ClusterDrift, error)
+	// ListByClusterID lists drifts of cluster, the latest first
+	ListByClusterID(ctx context.Context, clusterID uint, limit int) ([]*models.ClusterDrift, error)
+	// UpdateResynced marks the drift as resynced
+	UpdateResynced(ctx context.Context, id uint) error
+	// Resolve marks the drift as resolved
+	Resolve(ctx context.Context, id uint, resolvedAt time.Time) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, drift *models.ClusterDrift) (*models.ClusterDrift, error) {
+	result := d.db.WithContext(ctx).Create(drift)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ClusterDriftInDB, result.Error.Error())
+	}
+	return drift, nil
+}
+
+func (d *dao) GetUnresolvedByClusterID(ctx context.Context, clusterID uint) (*models.ClusterDrift, error) {
+	var drift models.ClusterDrift
+	result := d.db.WithContext(ctx).Where("cluster_id = ? and resolved_at is null", clusterID).
+		Order("id desc").First(&drift)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.ClusterDriftInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.ClusterDriftInDB, result.Error.Error())
+	}
+	return &drift, nil
+}
+
+func (d *dao) ListByClusterID(ctx context.Context, clusterID uint, limit int) ([]*models.ClusterDrift, error) {
+	var drifts []*models.ClusterDrift
+	result := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Order("id desc").Limit(limit).Find(&drifts)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ClusterDriftInDB, result.Error.Error())
+	}
+	return drifts, nil
+}
+
+func (d *dao) UpdateResynced(ctx context.Context, id uint) error {
+	result := d.db.WithContext(ctx).Model(&models.ClusterDrift{}).
+		Where("id = ?", id).Update("resynced", true)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ClusterDriftInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) Resolve(ctx context.Context, id uint, resolvedAt time.Time) error {
+	result := d.db.WithContext(ctx).Model(&models.ClusterDrift{}).
+		Where("id = ?", id).Update("resolved_at", resolvedAt)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ClusterDriftInDB, result.Error.Error())
+	}
+	return nil
+}
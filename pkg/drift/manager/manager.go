@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/drift/dao"
+	"github.com/horizoncd/horizon/pkg/drift/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/drift/manager/manager.go -package=mock_manager
+type Manager interface {
+	// Create creates a drift of cluster
+	Create(ctx context.Context, drift *models.ClusterDrift) (*models.ClusterDrift, error)
+	// GetUnresolvedByClusterID gets the unresolved drift of cluster
+	GetUnresolvedByClusterID(ctx context.Context, clusterID uint) (*models.ClusterDrift, error)
+	// ListByClusterID lists drifts of cluster, the latest first
+	ListByClusterID(ctx context.Context, clusterID uint, limit int) ([]*models.ClusterDrift, error)
+	// UpdateResynced marks the drift as resynced
+	UpdateResynced(ctx context.Context, id uint) error
+	// Resolve marks the drift as resolved
+	Resolve(ctx context.Context, id uint, resolvedAt time.Time) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context, drift *models.ClusterDrift) (*models.ClusterDrift, error) {
+	return m.dao.Create(ctx, drift)
+}
+
+func (m *manager) GetUnresolvedByClusterID(ctx context.Context, clusterID uint) (*models.ClusterDrift, error) {
+	return m.dao.GetUnresolvedByClusterID(ctx, clusterID)
+}
+
+func (m *manager) ListByClusterID(ctx context.Context, clusterID uint, limit int) ([]*models.ClusterDrift, error) {
+	return m.dao.ListByClusterID(ctx, clusterID, limit)
+}
+
+func (m *manager) UpdateResynced(ctx context.Context, id uint) error {
+	return m.dao.UpdateResynced(ctx, id)
+}
+
+func (m *manager) Resolve(ctx context.Context, id uint, resolvedAt time.Time) error {
+	return m.dao.Resolve(ctx, id, resolvedAt)
+}
@@ -0,0 +1,73 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/drift/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.ClusterDrift{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetUnresolvedByClusterID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	drift, err := mgr.Create(ctx, &models.ClusterDrift{
+		ClusterID:  1,
+		SyncStatus: "OutOfSync",
+		Resources:  `[{"kind":"Deployment","name":"app"}]`,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, mgr.UpdateResynced(ctx, drift.ID))
+
+	unresolved, err := mgr.GetUnresolvedByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, drift.ID, unresolved.ID)
+	assert.True(t, unresolved.Resynced)
+
+	assert.Nil(t, mgr.Resolve(ctx, drift.ID, time.Now()))
+	_, err = mgr.GetUnresolvedByClusterID(ctx, 1)
+	assert.NotNil(t, err)
+
+	_, err = mgr.Create(ctx, &models.ClusterDrift{ClusterID: 1, SyncStatus: "OutOfSync"})
+	assert.Nil(t, err)
+	drifts, err := mgr.ListByClusterID(ctx, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(drifts))
+	assert.Nil(t, drifts[0].ResolvedAt)
+	assert.NotNil(t, drifts[1].ResolvedAt)
+}
@@ -0,0 +1,36 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// ClusterDrift is a drift between the gitops repo and the live state of cluster in argo,
+// it's resolved once the cluster is synced again.
+type ClusterDrift struct {
+	ID        uint
+	ClusterID uint
+	// SyncStatus and HealthStatus are the status of cluster in argo when the drift is detected
+	SyncStatus   string
+	HealthStatus string
+	// Revision is the gitops commit which the cluster was synced to
+	Revision string
+	// Resources is the json of resources differing from the gitops repo
+	Resources string
+	// Resynced indicates the cluster has been resynced automatically
+	Resynced   bool
+	ResolvedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
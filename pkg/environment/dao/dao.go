@@ -54,8 +54,13 @@ func (d *dao) UpdateByID(ctx context.Context, id uint, environment *models.Envir
 		return err
 	}
 
-	// set displayName and autoFree
+	// set displayName, deployWindows, approval configs, drift policy and terminal policy
 	environmentInDB.DisplayName = environment.DisplayName
+	environmentInDB.DeployWindows = environment.DeployWindows
+	environmentInDB.Protected = environment.Protected
+	environmentInDB.Approvers = environment.Approvers
+	environmentInDB.AutoResync = environment.AutoResync
+	environmentInDB.TerminalPolicy = environment.TerminalPolicy
 	res := d.db.WithContext(ctx).Save(&environmentInDB)
 	if res.Error != nil {
 		return herrors.NewErrUpdateFailed(herrors.EnvironmentInDB, res.Error.Error())
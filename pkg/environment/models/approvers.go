@@ -0,0 +1,60 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Approvers are the users who can approve pipelineruns of a protected environment
+type Approvers struct {
+	// Roles are names of roles in roles.yaml, members of the cluster with these roles are approvers
+	Roles []string `json:"roles,omitempty"`
+	// Users are emails of approvers
+	Users []string `json:"users,omitempty"`
+}
+
+func (a *Approvers) Scan(value interface{}) error {
+	var bts []byte
+	switch v := value.(type) {
+	case nil:
+		*a = Approvers{}
+		return nil
+	case []byte:
+		bts = v
+	case string:
+		bts = []byte(v)
+	default:
+		return fmt.Errorf("failed to unmarshal Approvers from value: %v", value)
+	}
+	if len(bts) == 0 {
+		*a = Approvers{}
+		return nil
+	}
+	return json.Unmarshal(bts, a)
+}
+
+func (a Approvers) Value() (driver.Value, error) {
+	if len(a.Roles) == 0 && len(a.Users) == 0 {
+		return "", nil
+	}
+	bts, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(bts), nil
+}
@@ -0,0 +1,151 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const deployWindowTimeLayout = "15:04"
+
+// DeployWindow is a weekly period during which clusters of the environment can be deployed,
+// e.g. Tuesday to Thursday, 10:00 to 16:00
+type DeployWindow struct {
+	// Weekdays the window applies to, 0 is Sunday, empty means every day
+	Weekdays []time.Weekday `json:"weekdays,omitempty"`
+	// StartTime and EndTime are in format 15:04, the window is [StartTime, EndTime)
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+	// Timezone is the IANA name of the location, such as Asia/Shanghai, empty means local time
+	Timezone string `json:"timezone,omitempty"`
+}
+
+func (w *DeployWindow) Validate() error {
+	for _, weekday := range w.Weekdays {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return fmt.Errorf("invalid weekday %d", weekday)
+		}
+	}
+	start, err := time.Parse(deployWindowTimeLayout, w.StartTime)
+	if err != nil {
+		return fmt.Errorf("invalid startTime %s, format should be 15:04", w.StartTime)
+	}
+	end, err := time.Parse(deployWindowTimeLayout, w.EndTime)
+	if err != nil {
+		return fmt.Errorf("invalid endTime %s, format should be 15:04", w.EndTime)
+	}
+	if !start.Before(end) {
+		return fmt.Errorf("startTime %s should be before endTime %s", w.StartTime, w.EndTime)
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %s", w.Timezone)
+	}
+	return nil
+}
+
+// Contains returns whether t is in the window
+func (w *DeployWindow) Contains(t time.Time) bool {
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return false
+	}
+	t = t.In(loc)
+
+	if len(w.Weekdays) > 0 {
+		matched := false
+		for _, weekday := range w.Weekdays {
+			if weekday == t.Weekday() {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	start, err := time.Parse(deployWindowTimeLayout, w.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(deployWindowTimeLayout, w.EndTime)
+	if err != nil {
+		return false
+	}
+	minutes := t.Hour()*60 + t.Minute()
+	return minutes >= start.Hour()*60+start.Minute() && minutes < end.Hour()*60+end.Minute()
+}
+
+// DeployWindows is stored as json in db, empty means clusters can be deployed at any time
+type DeployWindows []*DeployWindow
+
+func (ws DeployWindows) Validate() error {
+	for _, w := range ws {
+		if w == nil {
+			return fmt.Errorf("deploy window cannot be empty")
+		}
+		if err := w.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Allowed returns whether t is in any of the windows
+func (ws DeployWindows) Allowed(t time.Time) bool {
+	if len(ws) == 0 {
+		return true
+	}
+	for _, w := range ws {
+		if w != nil && w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ws *DeployWindows) Scan(value interface{}) error {
+	var bts []byte
+	switch v := value.(type) {
+	case nil:
+		*ws = nil
+		return nil
+	case []byte:
+		bts = v
+	case string:
+		bts = []byte(v)
+	default:
+		return fmt.Errorf("failed to unmarshal DeployWindows from value: %v", value)
+	}
+	if len(bts) == 0 {
+		*ws = nil
+		return nil
+	}
+	return json.Unmarshal(bts, ws)
+}
+
+func (ws DeployWindows) Value() (driver.Value, error) {
+	if len(ws) == 0 {
+		return "", nil
+	}
+	bts, err := json.Marshal(ws)
+	if err != nil {
+		return nil, err
+	}
+	return string(bts), nil
+}
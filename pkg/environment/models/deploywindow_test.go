@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployWindows(t *testing.T) {
+	windows := DeployWindows{
+		{
+			Weekdays:  []time.Weekday{time.Tuesday, time.Wednesday, time.Thursday},
+			StartTime: "10:00",
+			EndTime:   "16:00",
+			Timezone:  "Asia/Shanghai",
+		},
+	}
+	assert.Nil(t, windows.Validate())
+
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	assert.Nil(t, err)
+	// 2023-06-06 is Tuesday
+	assert.True(t, windows.Allowed(time.Date(2023, 6, 6, 10, 0, 0, 0, loc)))
+	assert.True(t, windows.Allowed(time.Date(2023, 6, 8, 15, 59, 0, 0, loc)))
+	assert.False(t, windows.Allowed(time.Date(2023, 6, 6, 16, 0, 0, 0, loc)))
+	assert.False(t, windows.Allowed(time.Date(2023, 6, 6, 9, 59, 0, 0, loc)))
+	assert.False(t, windows.Allowed(time.Date(2023, 6, 5, 12, 0, 0, 0, loc)))
+	// 2023-06-06 04:00 UTC is 12:00 in Asia/Shanghai
+	assert.True(t, windows.Allowed(time.Date(2023, 6, 6, 4, 0, 0, 0, time.UTC)))
+
+	// empty windows means no limit
+	assert.True(t, DeployWindows(nil).Allowed(time.Now()))
+
+	// invalid windows
+	for _, w := range []*DeployWindow{
+		{StartTime: "10", EndTime: "16:00"},
+		{StartTime: "10:00", EndTime: "25:00"},
+		{StartTime: "16:00", EndTime: "10:00"},
+		{StartTime: "10:00", EndTime: "16:00", Weekdays: []time.Weekday{7}},
+		{StartTime: "10:00", EndTime: "16:00", Timezone: "Mars/Olympus"},
+	} {
+		assert.NotNil(t, DeployWindows{w}.Validate())
+	}
+
+	// stored as json
+	value, err := windows.Value()
+	assert.Nil(t, err)
+	var scanned DeployWindows
+	assert.Nil(t, scanned.Scan(value))
+	assert.Equal(t, windows, scanned)
+	assert.Nil(t, scanned.Scan([]byte("")))
+	assert.Nil(t, scanned)
+}
@@ -15,16 +15,45 @@
 package models
 
 import (
+	"fmt"
+
 	"github.com/horizoncd/horizon/pkg/server/global"
 )
 
+const (
+	// TerminalPolicyDefault records terminal sessions if there is a log storage for the environment
+	TerminalPolicyDefault = ""
+	// TerminalPolicyRecord requires terminal sessions to be recorded, shells are refused without a log storage
+	TerminalPolicyRecord = "record"
+	// TerminalPolicyDisabled disables shell access to clusters of the environment
+	TerminalPolicyDisabled = "disabled"
+)
+
+// ValidateTerminalPolicy checks if policy is one of the terminal policies
+func ValidateTerminalPolicy(policy string) error {
+	switch policy {
+	case TerminalPolicyDefault, TerminalPolicyRecord, TerminalPolicyDisabled:
+		return nil
+	}
+	return fmt.Errorf("invalid terminal policy %s", policy)
+}
+
 type Environment struct {
 	global.Model
 
 	Name        string
 	DisplayName string
-	CreatedBy   uint
-	UpdatedBy   uint
+	// DeployWindows limits when clusters of the environment can be deployed
+	DeployWindows DeployWindows `gorm:"type:text"`
+	// Protected environment requires approval before clusters are deployed
+	Protected bool
+	Approvers Approvers `gorm:"type:text"`
+	// AutoResync allows the drift detection job to resync drifted clusters of the environment
+	AutoResync bool
+	// TerminalPolicy decides whether terminal sessions are recorded, or shell access is disabled
+	TerminalPolicy string
+	CreatedBy      uint
+	UpdatedBy      uint
 }
 
 type EnvironmentList []*Environment
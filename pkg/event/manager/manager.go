@@ -112,19 +112,27 @@ func (m *manager) GetEvent(ctx context.Context, id uint) (*models.Event, error)
 }
 
 var supportedEvents = map[string]string{
-	models.ApplicationCreated:     "New application has been created",
-	models.ApplicationDeleted:     "Application has been deleted",
-	models.ApplicationTransfered:  "Application has been transferred to another group",
-	models.ApplicationUpdated:     "Application has been updated",
-	models.ClusterCreated:         "New cluster has been created",
-	models.ClusterDeleted:         "Cluster has been deleted",
-	models.ClusterUpdated:         "Cluster has been updated",
-	models.ClusterBuildDeployed:   "Cluster has completed a build task and triggered a deploy task",
-	models.ClusterDeployed:        "Cluster has triggered a deploying task",
-	models.ClusterRollbacked:      "Cluster has triggered a rollback task",
-	models.ClusterFreed:           "Cluster has been freed",
-	models.ClusterRestarted:       "Cluster has been restarted",
-	models.ClusterPodsRescheduled: "Pods has been deleted to reschedule",
+	models.ApplicationCreated:           "New application has been created",
+	models.ApplicationDeleted:           "Application has been deleted",
+	models.ApplicationTransfered:        "Application has been transferred to another group",
+	models.ApplicationUpdated:           "Application has been updated",
+	models.ClusterCreated:               "New cluster has been created",
+	models.ClusterDeleted:               "Cluster has been deleted",
+	models.ClusterUpdated:               "Cluster has been updated",
+	models.ClusterBuildDeployed:         "Cluster has completed a build task and triggered a deploy task",
+	models.ClusterDeployed:              "Cluster has triggered a deploying task",
+	models.ClusterRollbacked:            "Cluster has triggered a rollback task",
+	models.ClusterFreed:                 "Cluster has been freed",
+	models.ClusterRestarted:             "Cluster has been restarted",
+	models.ClusterPodsRescheduled:       "Pods has been deleted to reschedule",
+	models.ClusterDrifted:               "Live state of cluster has drifted from the gitops repo",
+	models.ClusterAlerted:               "Alert of cluster is firing or resolved",
+	models.PipelinerunCanaryPromoted:    "Canary analysis is healthy and the rollout has been promoted",
+	models.PipelinerunCanaryAborted:     "Canary analysis is breached and the rollout has been aborted",
+	models.PipelinerunApprovalRequested: "Pipelinerun in protected environment is waiting for approval",
+	models.PipelinerunApproved:          "Pipelinerun has been approved and continues to run",
+	models.PipelinerunRejected:          "Pipelinerun has been rejected and cancelled",
+	models.MemberRemoved:                "Member has been removed after expiry",
 }
 
 func (m *manager) ListSupportEvents() map[string]string {
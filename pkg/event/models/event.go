@@ -36,6 +36,20 @@ const (
 	ClusterPodsRescheduled string = "clusters_rescheduled"
 	ClusterUpdated         string = "clusters_updated"
 	ClusterFreed           string = "clusters_freed"
+	// ClusterDrifted records that live state of cluster differs from the gitops repo
+	ClusterDrifted string = "clusters_drifted"
+	// ClusterAlerted records that alert of cluster is firing or resolved
+	ClusterAlerted string = "clusters_alerted"
+	// PipelinerunCanaryPromoted and PipelinerunCanaryAborted record verdicts of canary analysis
+	PipelinerunCanaryPromoted string = "pipelineruns_canarypromoted"
+	PipelinerunCanaryAborted  string = "pipelineruns_canaryaborted"
+	// PipelinerunApprovalRequested, PipelinerunApproved and PipelinerunRejected record
+	// the approval of pipelineruns in protected environments
+	PipelinerunApprovalRequested string = "pipelineruns_approvalrequested"
+	PipelinerunApproved          string = "pipelineruns_approved"
+	PipelinerunRejected          string = "pipelineruns_rejected"
+	// MemberRemoved records that member binding of resource is removed after expiry
+	MemberRemoved string = "members_removed"
 	// TODO: add group events
 )
 
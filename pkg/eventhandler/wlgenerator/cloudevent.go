@@ -0,0 +1,85 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wlgenerator
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+)
+
+const (
+	CloudEventSpecVersion = "1.0"
+	CloudEventContentType = "application/cloudevents+json;charset=utf-8"
+	// CloudEventTypePrefix is prefixed to event type of horizon, such as io.horizoncd.horizon.clusters_created
+	CloudEventTypePrefix = "io.horizoncd.horizon."
+
+	_cloudEventSourceSystem = "/horizon"
+	_cloudEventHeaderPrefix = "Ce-"
+	_cloudEventDataType     = "application/json"
+)
+
+// CloudEvent is a CloudEvents 1.0 envelope of MessageContent
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            *MessageContent `json:"data"`
+}
+
+// NewCloudEvent wraps the message into a CloudEvent, id of the message identifies the event,
+// so receivers can drop retried deliveries of the same event
+func NewCloudEvent(message *MessageContent, eventTime time.Time) *CloudEvent {
+	ce := &CloudEvent{
+		SpecVersion:     CloudEventSpecVersion,
+		ID:              strconv.FormatUint(uint64(message.ID), 10),
+		Source:          _cloudEventSourceSystem,
+		Type:            CloudEventTypePrefix + message.EventType,
+		Time:            eventTime.UTC(),
+		DataContentType: _cloudEventDataType,
+		Data:            message,
+	}
+	switch {
+	case message.Cluster != nil:
+		ce.Source = fmt.Sprintf("%s/%s/%d", _cloudEventSourceSystem, common.ResourceCluster, message.Cluster.ID)
+		ce.Subject = message.Cluster.Name
+	case message.Application != nil:
+		ce.Source = fmt.Sprintf("%s/%s/%d", _cloudEventSourceSystem,
+			common.ResourceApplication, message.Application.ID)
+		ce.Subject = message.Application.Name
+	}
+	return ce
+}
+
+// SetBinaryHeaders sets attributes of the event as headers in binary content mode,
+// data of the event is sent as request body
+func (e *CloudEvent) SetBinaryHeaders(header http.Header) {
+	header.Set(_cloudEventHeaderPrefix+"Specversion", e.SpecVersion)
+	header.Set(_cloudEventHeaderPrefix+"Id", e.ID)
+	header.Set(_cloudEventHeaderPrefix+"Source", e.Source)
+	header.Set(_cloudEventHeaderPrefix+"Type", e.Type)
+	if e.Subject != "" {
+		header.Set(_cloudEventHeaderPrefix+"Subject", e.Subject)
+	}
+	header.Set(_cloudEventHeaderPrefix+"Time", e.Time.Format(time.RFC3339Nano))
+	header.Set(WebhookContentTypeHeader, e.DataContentType)
+}
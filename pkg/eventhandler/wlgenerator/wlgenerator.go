@@ -33,6 +33,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/event/models"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmanager "github.com/horizoncd/horizon/pkg/pipelinerun/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
@@ -41,6 +42,8 @@ import (
 )
 
 const (
+	// WebhookSecretHeader carried the secret in plaintext, it's no longer sent,
+	// requests are signed by the secret instead, see pkg/webhook/signature
 	WebhookSecretHeader      = "X-Horizon-Webhook-Secret"
 	WebhookContentTypeHeader = "Content-Type"
 	WebhookContentType       = "application/json;charset=utf-8"
@@ -83,6 +86,7 @@ type WebhookLogGenerator struct {
 	groupMgr       groupmanager.Manager
 	applicationMgr applicationmanager.Manager
 	clusterMgr     clustermanager.Manager
+	pipelinerunMgr prmanager.Manager
 	userMgr        usermanager.Manager
 }
 
@@ -93,6 +97,7 @@ func NewWebhookLogGenerator(manager *managerparam.Manager) *WebhookLogGenerator
 		groupMgr:       manager.GroupManager,
 		applicationMgr: manager.ApplicationManager,
 		clusterMgr:     manager.ClusterMgr,
+		pipelinerunMgr: manager.PipelinerunMgr,
 		userMgr:        manager.UserManager,
 	}
 }
@@ -111,6 +116,19 @@ func (w *WebhookLogGenerator) listSystemResources() map[string][]uint {
 	}
 }
 
+// listAssociatedResourcesOfGroup get group by id and list all the parent resources
+func (w *WebhookLogGenerator) listAssociatedResourcesOfGroup(ctx context.Context, id uint) map[string][]uint {
+	resources := w.listSystemResources()
+	group, err := w.groupMgr.GetByID(ctx, id)
+	if err != nil {
+		log.Warningf(ctx, "group %d is not exist", id)
+		return resources
+	}
+	groupIDs := groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs)
+	resources[common.ResourceGroup] = append(resources[common.ResourceGroup], groupIDs...)
+	return resources
+}
+
 // listAssociatedResourcesOfApp get application by id and list all the parent resources
 func (w *WebhookLogGenerator) listAssociatedResourcesOfApp(ctx context.Context,
 	id uint) (*applicationmodels.Application, map[string][]uint) {
@@ -149,6 +167,23 @@ func (w *WebhookLogGenerator) listAssociatedResourcesOfCluster(ctx context.Conte
 	return cluster, app, resources
 }
 
+// listAssociatedResourcesOfPipelinerun get pipelinerun by id and list all the parent resources
+func (w *WebhookLogGenerator) listAssociatedResourcesOfPipelinerun(ctx context.Context, id uint) (*clustermodels.Cluster,
+	*applicationmodels.Application, map[string][]uint) {
+	pipelinerun, err := w.pipelinerunMgr.GetByID(ctx, id)
+	if err != nil {
+		log.Warningf(ctx, "pipelinerun %d is not exist",
+			id)
+		return nil, nil, nil
+	}
+	cluster, app, resources := w.listAssociatedResourcesOfCluster(ctx, pipelinerun.ClusterID)
+	if resources == nil {
+		return nil, nil, nil
+	}
+	resources[common.ResourcePipelinerun] = []uint{pipelinerun.ID}
+	return cluster, app, resources
+}
+
 // listAssociatedResources list all the associated resources of event to find all the webhooks
 func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 	e *models.Event) (*messageDependency, map[string][]uint) {
@@ -160,6 +195,8 @@ func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 	)
 
 	switch e.ResourceType {
+	case common.ResourceGroup:
+		resources = w.listAssociatedResourcesOfGroup(ctx, e.ResourceID)
 	case common.ResourceApplication:
 		application, resources = w.listAssociatedResourcesOfApp(ctx, e.ResourceID)
 		dep.application = application
@@ -167,6 +204,10 @@ func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 		cluster, application, resources = w.listAssociatedResourcesOfCluster(ctx, e.ResourceID)
 		dep.application = application
 		dep.cluster = cluster
+	case common.ResourcePipelinerun:
+		cluster, application, resources = w.listAssociatedResourcesOfPipelinerun(ctx, e.ResourceID)
+		dep.application = application
+		dep.cluster = cluster
 	default:
 		log.Infof(ctx, "resource type %s is unsupported",
 			e.ResourceType)
@@ -175,9 +216,8 @@ func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 }
 
 // makeRequestHeaders assemble headers of webhook request
-func (w *WebhookLogGenerator) makeRequestHeaders(secret string) (string, error) {
+func (w *WebhookLogGenerator) makeRequestHeaders() (string, error) {
 	header := http.Header{}
-	header.Add(WebhookSecretHeader, secret)
 	header.Add(WebhookContentTypeHeader, WebhookContentType)
 	headerByte, err := yaml.Marshal(header)
 	if err != nil {
@@ -212,7 +252,8 @@ func (w *WebhookLogGenerator) makeRequestBody(ctx context.Context, dep *messageD
 		}
 	}
 
-	if dep.event.ResourceType == common.ResourceCluster &&
+	if (dep.event.ResourceType == common.ResourceCluster ||
+		dep.event.ResourceType == common.ResourcePipelinerun) &&
 		dep.cluster != nil {
 		message.Cluster = &ClusterInfo{
 			ResourceCommonInfo: ResourceCommonInfo{
@@ -306,7 +347,7 @@ func (w *WebhookLogGenerator) Process(ctx context.Context, events []*models.Even
 	}
 	for _, dependencyMap := range conditionsToCreate {
 		for _, dependency := range dependencyMap {
-			headers, err := w.makeRequestHeaders(dependency.webhook.Secret)
+			headers, err := w.makeRequestHeaders()
 			if err != nil {
 				log.Errorf(ctx, fmt.Sprintf("failed to make headers, error: %+v", err))
 				continue
@@ -61,9 +61,10 @@ type DAO interface {
 	// ListWithoutPage query groups without paging
 	ListWithoutPage(ctx context.Context, query *q.Query) ([]*models.Group, error)
 	// List query groups with paging
-	List(ctx context.Context, query *q.Query) ([]*models.Group, int64, error)
+	List(ctx context.Context, query *q.Query, excludedGroupIDs []uint) ([]*models.Group, int64, error)
 	// ListChildren children of a group
-	ListChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error)
+	ListChildren(ctx context.Context, parentID uint, invisible *models.Invisible,
+		pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error)
 	// Transfer move a group under another parent group
 	Transfer(ctx context.Context, id, newParentID uint) error
 	// GetByNameOrPathUnderParent get by name or path under a specified parent
@@ -106,18 +107,21 @@ func (d *dao) GetByIDNameFuzzily(ctx context.Context, id uint, name string) ([]*
 	return groups, result.Error
 }
 
-func (d *dao) ListChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) (
-	[]*models.GroupOrApplication, int64, error) {
+func (d *dao) ListChildren(ctx context.Context, parentID uint, invisible *models.Invisible,
+	pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error) {
 	var gas []*models.GroupOrApplication
 	var count int64
 
-	result := d.db.WithContext(ctx).Raw(dbcommon.GroupQueryGroupChildren, parentID, parentID,
-		pageSize, (pageNumber-1)*pageSize).Scan(&gas)
+	excludedGroupIDs := excludedIDs(invisible.GroupIDs())
+	excludedApplicationIDs := excludedIDs(invisible.ApplicationIDs())
+	result := d.db.WithContext(ctx).Raw(dbcommon.GroupQueryGroupChildren, parentID, excludedGroupIDs,
+		parentID, excludedApplicationIDs, pageSize, (pageNumber-1)*pageSize).Scan(&gas)
 	if result.Error != nil {
 		return nil, 0, herrors.NewErrGetFailed(herrors.GroupInDB, result.Error.Error())
 	}
 
-	result = d.db.WithContext(ctx).Raw(dbcommon.GroupQueryGroupChildrenCount, parentID, parentID).Scan(&count)
+	result = d.db.WithContext(ctx).Raw(dbcommon.GroupQueryGroupChildrenCount, parentID, excludedGroupIDs,
+		parentID, excludedApplicationIDs).Scan(&count)
 
 	if result.Error != nil {
 		return nil, 0, herrors.NewErrGetFailed(herrors.GroupInDB, result.Error.Error())
@@ -388,14 +392,14 @@ func (d *dao) ListWithoutPage(ctx context.Context, query *q.Query) ([]*models.Gr
 	return groups, result.Error
 }
 
-func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.Group, int64, error) {
+func (d *dao) List(ctx context.Context, query *q.Query, excludedGroupIDs []uint) ([]*models.Group, int64, error) {
 	var groups []*models.Group
 
 	sort := orm.FormatSortExp(query)
 	offset := (query.PageNumber - 1) * query.PageSize
 	var count int64
-	result := d.db.WithContext(ctx).Order(sort).Where(query.Keywords).Offset(offset).Limit(query.PageSize).Find(&groups).
-		Offset(-1).Count(&count)
+	result := d.db.WithContext(ctx).Order(sort).Where(query.Keywords).Where("id not in ?", excludedIDs(excludedGroupIDs)).
+		Offset(offset).Limit(query.PageSize).Find(&groups).Offset(-1).Count(&count)
 	if result.Error != nil {
 		return nil, 0, herrors.NewErrListFailed(herrors.GroupInDB, result.Error.Error())
 	}
@@ -465,3 +469,11 @@ func (d *dao) ListByTraversalIDsContains(ctx context.Context, ids []uint) ([]*mo
 	}
 	return children, nil
 }
+
+// excludedIDs returns ids for the 'not in' condition, which always matches nothing if ids are empty
+func excludedIDs(ids []uint) []uint {
+	if len(ids) == 0 {
+		return []uint{0}
+	}
+	return ids
+}
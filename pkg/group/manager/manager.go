@@ -72,10 +72,14 @@ type Manager interface {
 	GetSubGroupsUnderParentIDs(ctx context.Context, parentIDs []uint) ([]*models.Group, error)
 	// Transfer move a group under another parent group
 	Transfer(ctx context.Context, id, newParentID uint) error
-	// GetSubGroups get subgroups of a parent group, order by updateTime desc by default with paging
-	GetSubGroups(ctx context.Context, id uint, pageNumber, pageSize int) ([]*models.Group, int64, error)
-	// GetChildren get children of a parent group, order by updateTime desc by default with paging
-	GetChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error)
+	// GetSubGroups get subgroups of a parent group excluding the invisible ones,
+	// order by updateTime desc by default with paging
+	GetSubGroups(ctx context.Context, id uint, invisible *models.Invisible,
+		pageNumber, pageSize int) ([]*models.Group, int64, error)
+	// GetChildren get children of a parent group excluding the invisible ones,
+	// order by updateTime desc by default with paging
+	GetChildren(ctx context.Context, parentID uint, invisible *models.Invisible,
+		pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error)
 	// GetByNameOrPathUnderParent get by name or path under a specified parent
 	GetByNameOrPathUnderParent(ctx context.Context, name, path string, parentID uint) ([]*models.Group, error)
 	// GetSubGroupsByGroupIDs get groups and its subGroups by specified groupIDs
@@ -114,14 +118,15 @@ func New(db *gorm.DB) Manager {
 	}
 }
 
-func (m manager) GetChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) (
-	[]*models.GroupOrApplication, int64, error) {
-	return m.groupDAO.ListChildren(ctx, parentID, pageNumber, pageSize)
+func (m manager) GetChildren(ctx context.Context, parentID uint, invisible *models.Invisible,
+	pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error) {
+	return m.groupDAO.ListChildren(ctx, parentID, invisible, pageNumber, pageSize)
 }
 
-func (m manager) GetSubGroups(ctx context.Context, id uint, pageNumber, pageSize int) ([]*models.Group, int64, error) {
+func (m manager) GetSubGroups(ctx context.Context, id uint, invisible *models.Invisible,
+	pageNumber, pageSize int) ([]*models.Group, int64, error) {
 	query := formatListGroupQuery(id, pageNumber, pageSize)
-	return m.groupDAO.List(ctx, query)
+	return m.groupDAO.List(ctx, query, invisible.GroupIDs())
 }
 
 func (m manager) Transfer(ctx context.Context, id, newParentID uint) error {
@@ -414,7 +414,7 @@ func TestManagerGetChildren(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1, err := Mgr.GetChildren(ctx, tt.args.parentID, tt.args.pageNumber, tt.args.pageSize)
+			got, got1, err := Mgr.GetChildren(ctx, tt.args.parentID, nil, tt.args.pageNumber, tt.args.pageSize)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetChildren() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -70,3 +70,51 @@ type RegionSelector struct {
 }
 
 type RegionSelectors []*RegionSelector
+
+// Invisible holds groups and applications which are invisible to a user
+type Invisible struct {
+	Groups       map[uint]struct{}
+	Applications map[uint]struct{}
+}
+
+// GroupIDs returns ids of the invisible groups
+func (i *Invisible) GroupIDs() []uint {
+	if i == nil {
+		return nil
+	}
+	ids := make([]uint, 0, len(i.Groups))
+	for id := range i.Groups {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// ApplicationIDs returns ids of the invisible applications
+func (i *Invisible) ApplicationIDs() []uint {
+	if i == nil {
+		return nil
+	}
+	ids := make([]uint, 0, len(i.Applications))
+	for id := range i.Applications {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// HasGroup returns whether the group is invisible
+func (i *Invisible) HasGroup(id uint) bool {
+	if i == nil {
+		return false
+	}
+	_, ok := i.Groups[id]
+	return ok
+}
+
+// HasApplication returns whether the application is invisible
+func (i *Invisible) HasApplication(id uint) bool {
+	if i == nil {
+		return false
+	}
+	_, ok := i.Applications[id]
+	return ok
+}
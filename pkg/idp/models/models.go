@@ -38,6 +38,8 @@ type IdentityProvider struct {
 	Jwks                    string
 	ClientID                string
 	ClientSecret            string
+	// GroupsClaim is the claim of userinfo which contains groups of user, groups are not synced if empty
+	GroupsClaim string
 }
 
 type TokenEndpointAuthMethod uint8
@@ -71,11 +73,15 @@ func (t *TokenEndpointAuthMethod) String() (string, error) {
 }
 
 func (t *TokenEndpointAuthMethod) Scan(value interface{}) error {
-	bts, ok := value.([]byte)
-	if !ok {
+	var str string
+	switch v := value.(type) {
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	default:
 		return fmt.Errorf("failed to unmarshal TokenEndpointAuthMethod from value: %v", value)
 	}
-	str := string(bts)
 	switch str {
 	case ClientSecretSentAsPostStr:
 		*t = ClientSecretSentAsPost
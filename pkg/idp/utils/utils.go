@@ -30,6 +30,8 @@ type Claims struct {
 	Sub   string `json:"sub"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	// Groups are parsed from the groups claim configured in identity provider
+	Groups []string `json:"-"`
 }
 
 func MakeOuath2Config(ctx context.Context, idp *models.IdentityProvider,
@@ -152,6 +154,34 @@ func HandleOIDC(ctx context.Context, idp *models.IdentityProvider,
 			"failed to parse claims:\n"+
 				"err = %v", err)
 	}
+	if idp.GroupsClaim != "" {
+		var rawClaims map[string]interface{}
+		if err := userinfo.Claims(&rawClaims); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"failed to parse claims:\n"+
+					"err = %v", err)
+		}
+		claims.Groups = parseGroups(rawClaims[idp.GroupsClaim])
+	}
 
 	return &claims, nil
 }
+
+// parseGroups parses groups claim, which is an array of strings mostly,
+// and some identity providers return a string if there is only one group
+func parseGroups(claim interface{}) []string {
+	groups := make([]string, 0)
+	switch value := claim.(type) {
+	case string:
+		if value != "" {
+			groups = append(groups, value)
+		}
+	case []interface{}:
+		for _, item := range value {
+			if group, ok := item.(string); ok && group != "" {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return groups
+}
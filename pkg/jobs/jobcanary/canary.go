@@ -0,0 +1,223 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobcanary
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/canary/analysis"
+	canaryconfig "github.com/horizoncd/horizon/pkg/config/canary"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	uuid "github.com/satori/go.uuid"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	actionPromote = "promote"
+	actionAbort   = "abort"
+)
+
+var rolloutGVR = schema.GroupVersionResource{
+	Group:    "argoproj.io",
+	Version:  "v1alpha1",
+	Resource: "rollouts",
+}
+
+// clusterController is the part of cluster controller used by canary analysis
+type clusterController interface {
+	GetStep(ctx context.Context, clusterID uint) (*clusterctl.GetStepResponse, error)
+	ExecuteAction(ctx context.Context, clusterID uint, action string, gvr schema.GroupVersionResource) error
+}
+
+type canaryJob struct {
+	delay      time.Duration
+	mgr        *managerparam.Manager
+	clusterCtl clusterController
+	analyzer   analysis.Analyzer
+}
+
+// Run checks canary rules of clusters whose rollout is paused at a pause step,
+// promotes the rollout when all rules are healthy and aborts it when any rule is breached.
+func Run(ctx context.Context, jobConfig *canaryconfig.Config, mgr *managerparam.Manager,
+	clusterCtl clusterctl.Controller) {
+	// verify account
+	user, err := mgr.UserManager.GetUserByID(ctx, jobConfig.AccountID)
+	if err != nil {
+		log.Errorf(ctx, "failed to verify operator, err: %v", err.Error())
+		panic(err)
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+
+	job := &canaryJob{
+		delay:      jobConfig.AnalysisDelay,
+		mgr:        mgr,
+		clusterCtl: clusterCtl,
+		analyzer:   analysis.NewAnalyzer(jobConfig),
+	}
+
+	// start job
+	log.Infof(ctx, "Starting canary analysis every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping canary analysis")
+	ticker := time.NewTicker(jobConfig.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "canary analysis job starts to execute, rid: %v", rid)
+			job.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *canaryJob) process(ctx context.Context) {
+	op := "job: canary analysis"
+	clusterIDs, err := j.mgr.CanaryRuleMgr.ListClusterIDs(ctx)
+	if err != nil {
+		log.WithFiled(ctx, "op", op).
+			Errorf("failed to list clusters with canary rules, err: %v", err.Error())
+		return
+	}
+	for _, clusterID := range clusterIDs {
+		if err := j.analyzeCluster(ctx, clusterID); err != nil {
+			log.WithFiled(ctx, "op", op).
+				Errorf("failed to analyze cluster %d, err: %+v", clusterID, err)
+		}
+	}
+}
+
+func (j *canaryJob) analyzeCluster(ctx context.Context, clusterID uint) error {
+	cluster, err := j.mgr.ClusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		// rules of deleted cluster are ignored
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+
+	// 1. only analyze when the rollout is paused at a pause step
+	step, err := j.clusterCtl.GetStep(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if step == nil || !step.StepPaused {
+		return nil
+	}
+	if step.StepPausedAt != nil && time.Since(*step.StepPausedAt) < j.delay {
+		return nil
+	}
+
+	// 2. check rules against prometheus of region
+	application, err := j.mgr.ApplicationManager.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	region, err := j.mgr.RegionMgr.GetRegionByName(ctx, cluster.RegionName)
+	if err != nil {
+		return err
+	}
+	rules, err := j.mgr.CanaryRuleMgr.ListByClusterID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	verdict, err := j.analyzer.Analyze(ctx, region.PrometheusURL, rules, &analysis.QueryVars{
+		Application: application.Name,
+		Cluster:     cluster.Name,
+		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 3. promote or abort the rollout
+	var action, eventType string
+	switch verdict.Phase {
+	case analysis.PhaseHealthy:
+		action, eventType = actionPromote, eventmodels.PipelinerunCanaryPromoted
+	case analysis.PhaseBreached:
+		action, eventType = actionAbort, eventmodels.PipelinerunCanaryAborted
+	default:
+		log.Infof(ctx, "canary analysis of cluster %v is %v, wait for the next round",
+			cluster.Name, verdict.Phase)
+		return nil
+	}
+	log.Infof(ctx, "canary analysis of cluster %v is %v, %v the rollout at step %d",
+		cluster.Name, verdict.Phase, action, step.Index)
+	if err := j.clusterCtl.ExecuteAction(ctx, clusterID, action, rolloutGVR); err != nil {
+		return err
+	}
+
+	// 4. record the verdict as event of the latest pipelinerun
+	j.recordVerdict(ctx, clusterID, eventType, verdict)
+	return nil
+}
+
+func (j *canaryJob) recordVerdict(ctx context.Context, clusterID uint,
+	eventType string, verdict *analysis.Verdict) {
+	_, pipelineruns, err := j.mgr.PipelinerunMgr.GetByClusterID(ctx, clusterID, false, q.Query{
+		PageNumber: 1,
+		PageSize:   1,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to get latest pipelinerun of cluster %d, err: %s", clusterID, err.Error())
+		return
+	}
+	if len(pipelineruns) == 0 {
+		return
+	}
+
+	extraBytes, err := json.Marshal(verdict)
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal verdict, err: %s", err.Error())
+		return
+	}
+	extra := string(extraBytes)
+	if _, err := j.mgr.EventManager.CreateEvent(ctx, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourcePipelinerun,
+			EventType:    eventType,
+			ResourceID:   pipelineruns[0].ID,
+			Extra:        &extra,
+		},
+	}); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobcanary
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/canary/analysis"
+	canarymodels "github.com/horizoncd/horizon/pkg/canary/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	canaryconfig "github.com/horizoncd/horizon/pkg/config/canary"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	ctx     = context.Background()
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{},
+		&regionmodels.Region{}, &membermodels.Member{}, &usermodels.User{}, &tagmodels.Tag{},
+		&prmodels.Pipelinerun{}, &eventmodels.Event{}, &canarymodels.CanaryRule{}); err != nil {
+		panic(err)
+	}
+	// nolint
+	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "horizon",
+		ID:   uint(1),
+	})
+	os.Exit(m.Run())
+}
+
+type fakeClusterCtl struct {
+	step    *clusterctl.GetStepResponse
+	actions []string
+}
+
+func (f *fakeClusterCtl) GetStep(ctx context.Context, clusterID uint) (*clusterctl.GetStepResponse, error) {
+	return f.step, nil
+}
+
+func (f *fakeClusterCtl) ExecuteAction(ctx context.Context, clusterID uint,
+	action string, gvr schema.GroupVersionResource) error {
+	f.actions = append(f.actions, action)
+	return nil
+}
+
+func TestCanaryJob(t *testing.T) {
+	errorRate := "0.01"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, `error_rate{cluster="canary"}`, r.URL.Query().Get("query"))
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector",` +
+			`"result":[{"metric":{},"value":[1680000000,"` + errorRate + `"]}]}}`))
+	}))
+	defer server.Close()
+
+	region, err := manager.RegionMgr.Create(ctx, &regionmodels.Region{
+		Name:          "hz",
+		PrometheusURL: server.URL,
+	})
+	assert.Nil(t, err)
+	application, err := manager.ApplicationManager.Create(ctx, &appmodels.Application{
+		Name: "app",
+	}, nil)
+	assert.Nil(t, err)
+	cluster, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "canary",
+		EnvironmentName: "test",
+		RegionName:      region.Name,
+	}, nil, nil)
+	assert.Nil(t, err)
+	pr, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionDeploy,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, manager.CanaryRuleMgr.UpdateByClusterID(ctx, cluster.ID, []*canarymodels.CanaryRule{
+		{
+			Name:      "errorRate",
+			Type:      canarymodels.RuleTypeErrorRate,
+			Query:     `error_rate{cluster="{{.Cluster}}"}`,
+			Operator:  canarymodels.OperatorLessThan,
+			Threshold: 0.05,
+		},
+	}))
+	// rules of deleted cluster are ignored
+	assert.Nil(t, manager.CanaryRuleMgr.UpdateByClusterID(ctx, cluster.ID+1, []*canarymodels.CanaryRule{
+		{
+			Name:     "errorRate",
+			Type:     canarymodels.RuleTypeErrorRate,
+			Operator: canarymodels.OperatorLessThan,
+		},
+	}))
+
+	clusterCtl := &fakeClusterCtl{step: &clusterctl.GetStepResponse{}}
+	job := &canaryJob{
+		delay:      time.Minute,
+		mgr:        manager,
+		clusterCtl: clusterCtl,
+		analyzer:   analysis.NewAnalyzer(&canaryconfig.Config{}),
+	}
+
+	// not paused by step
+	job.process(ctx)
+	assert.Equal(t, 0, len(clusterCtl.actions))
+
+	// paused, but metrics are not ready
+	pausedAt := time.Now()
+	clusterCtl.step = &clusterctl.GetStepResponse{StepPaused: true, StepPausedAt: &pausedAt}
+	job.process(ctx)
+	assert.Equal(t, 0, len(clusterCtl.actions))
+
+	// healthy
+	pausedAt = time.Now().Add(-2 * time.Minute)
+	job.process(ctx)
+	assert.Equal(t, []string{actionPromote}, clusterCtl.actions)
+
+	// breached
+	errorRate = "0.1"
+	job.process(ctx)
+	assert.Equal(t, []string{actionPromote, actionAbort}, clusterCtl.actions)
+
+	events, err := manager.EventManager.ListEvents(ctx, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, eventmodels.PipelinerunCanaryPromoted, events[0].EventType)
+	assert.Equal(t, eventmodels.PipelinerunCanaryAborted, events[1].EventType)
+	for _, event := range events {
+		assert.Equal(t, common.ResourcePipelinerun, event.ResourceType)
+		assert.Equal(t, pr.ID, event.ResourceID)
+	}
+	assert.Contains(t, *events[1].Extra, analysis.PhaseBreached)
+}
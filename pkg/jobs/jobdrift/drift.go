@@ -0,0 +1,269 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobdrift
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cd"
+	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	driftconfig "github.com/horizoncd/horizon/pkg/config/drift"
+	"github.com/horizoncd/horizon/pkg/drift/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	uuid "github.com/satori/go.uuid"
+)
+
+// inProgressStatuses are statuses of pipelineruns which are going to change the cluster,
+// clusters are not checked until these pipelineruns are finished
+var inProgressStatuses = map[prmodels.PipelineStatus]bool{
+	prmodels.StatusCreated:   true,
+	prmodels.StatusCommitted: true,
+	prmodels.StatusMerged:    true,
+	prmodels.StatusDeployed:  true,
+}
+
+type driftJob struct {
+	batchSize      int
+	mgr            *managerparam.Manager
+	cd             cd.CD
+	clusterGitRepo clustergitrepo.ClusterGitRepo
+}
+
+// Run walks all clusters and compares their live state in argo with the gitops repo.
+// Drifts are recorded and notified by the clusters_drifted event,
+// and drifted clusters are resynced if their environments allow it.
+func Run(ctx context.Context, jobConfig *driftconfig.Config, mgr *managerparam.Manager,
+	cd cd.CD, clusterGitRepo clustergitrepo.ClusterGitRepo) {
+	// verify account
+	user, err := mgr.UserManager.GetUserByID(ctx, jobConfig.AccountID)
+	if err != nil {
+		log.Errorf(ctx, "failed to verify operator, err: %v", err.Error())
+		panic(err)
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+
+	job := &driftJob{
+		batchSize:      jobConfig.BatchSize,
+		mgr:            mgr,
+		cd:             cd,
+		clusterGitRepo: clusterGitRepo,
+	}
+
+	// start job
+	log.Infof(ctx, "Starting drift detection every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping drift detection")
+	ticker := time.NewTicker(jobConfig.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "drift detection job starts to execute, rid: %v", rid)
+			job.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *driftJob) process(ctx context.Context) {
+	op := "job: drift detection"
+	for pageNumber := 1; ; pageNumber++ {
+		total, clusters, err := j.mgr.ClusterMgr.List(ctx, &q.Query{
+			PageNumber: pageNumber,
+			PageSize:   j.batchSize,
+		})
+		if err != nil {
+			log.WithFiled(ctx, "op", op).
+				Errorf("failed to list clusters, err: %v", err.Error())
+			return
+		}
+		for _, cluster := range clusters {
+			if err := j.detect(ctx, cluster.Cluster); err != nil {
+				log.WithFiled(ctx, "op", op).
+					Errorf("failed to detect drift of cluster %d, err: %+v", cluster.ID, err)
+			}
+		}
+		if len(clusters) == 0 || pageNumber*j.batchSize >= total {
+			return
+		}
+	}
+}
+
+func (j *driftJob) detect(ctx context.Context, cluster *clustermodels.Cluster) error {
+	if cluster.Status != common.ClusterStatusEmpty {
+		return nil
+	}
+
+	// 1. skip clusters being deployed
+	_, pipelineruns, err := j.mgr.PipelinerunMgr.GetByClusterID(ctx, cluster.ID, false, q.Query{
+		PageNumber: 1,
+		PageSize:   1,
+	})
+	if err != nil {
+		return err
+	}
+	if len(pipelineruns) > 0 && inProgressStatuses[prmodels.PipelineStatus(pipelineruns[0].Status)] {
+		return nil
+	}
+
+	// 2. get live state from argo
+	application, err := j.mgr.ApplicationManager.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	regionEntity, err := j.mgr.RegionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return err
+	}
+	state, err := j.cd.GetClusterState(ctx, &cd.GetClusterStateV2Params{
+		Application:  application.Name,
+		Environment:  cluster.EnvironmentName,
+		Cluster:      cluster.Name,
+		RegionEntity: regionEntity,
+	})
+	if err != nil {
+		// clusters not deployed yet are ignored
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+
+	unresolved, err := j.mgr.ClusterDriftMgr.GetUnresolvedByClusterID(ctx, cluster.ID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+	}
+
+	// 3. resolve the drift once the cluster is synced again
+	if state.SyncStatus != string(applicationV1alpha1.SyncStatusCodeOutOfSync) {
+		if unresolved != nil {
+			log.Infof(ctx, "drift %d of cluster %v is resolved", unresolved.ID, cluster.Name)
+			return j.mgr.ClusterDriftMgr.Resolve(ctx, unresolved.ID, time.Now())
+		}
+		return nil
+	}
+
+	// 4. record the drift if it's new
+	resourcesBytes, err := json.Marshal(state.OutOfSyncResources)
+	if err != nil {
+		return err
+	}
+	resources := string(resourcesBytes)
+	if unresolved != nil {
+		if sameResources(unresolved.Resources, state.OutOfSyncResources) {
+			return nil
+		}
+		if err := j.mgr.ClusterDriftMgr.Resolve(ctx, unresolved.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+	drift, err := j.mgr.ClusterDriftMgr.Create(ctx, &models.ClusterDrift{
+		ClusterID:    cluster.ID,
+		SyncStatus:   state.SyncStatus,
+		HealthStatus: state.Status,
+		Revision:     state.Revision,
+		Resources:    resources,
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "cluster %v has drifted from gitops repo, resources: %v", cluster.Name, resources)
+	j.recordEvent(ctx, cluster.ID, resources)
+
+	// 5. resync the cluster if the environment allows it
+	env, err := j.mgr.EnvMgr.GetByName(ctx, cluster.EnvironmentName)
+	if err != nil {
+		return err
+	}
+	if !env.AutoResync {
+		return nil
+	}
+	return j.resync(ctx, application.Name, cluster, drift)
+}
+
+func (j *driftJob) resync(ctx context.Context, application string,
+	cluster *clustermodels.Cluster, drift *models.ClusterDrift) error {
+	configCommit, err := j.clusterGitRepo.GetConfigCommit(ctx, application, cluster.Name)
+	if err != nil {
+		return err
+	}
+	if err := j.cd.DeployCluster(ctx, &cd.DeployClusterParams{
+		Environment: cluster.EnvironmentName,
+		Cluster:     cluster.Name,
+		Revision:    configCommit.Master,
+	}); err != nil {
+		return err
+	}
+	log.Infof(ctx, "cluster %v is resynced to %v", cluster.Name, configCommit.Master)
+	return j.mgr.ClusterDriftMgr.UpdateResynced(ctx, drift.ID)
+}
+
+func (j *driftJob) recordEvent(ctx context.Context, clusterID uint, resources string) {
+	if _, err := j.mgr.EventManager.CreateEvent(ctx, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			EventType:    eventmodels.ClusterDrifted,
+			ResourceID:   clusterID,
+			Extra:        &resources,
+		},
+	}); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+}
+
+// sameResources checks whether the recorded resources are the same as the current ones regardless of order
+func sameResources(recorded string, current []cd.OutOfSyncResource) bool {
+	var resources []cd.OutOfSyncResource
+	if err := json.Unmarshal([]byte(recorded), &resources); err != nil {
+		return false
+	}
+	if len(resources) != len(current) {
+		return false
+	}
+	set := make(map[cd.OutOfSyncResource]struct{}, len(resources))
+	for _, resource := range resources {
+		set[resource] = struct{}{}
+	}
+	for _, resource := range current {
+		if _, ok := set[resource]; !ok {
+			return false
+		}
+	}
+	return true
+}
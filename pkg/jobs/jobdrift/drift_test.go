@@ -0,0 +1,179 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobdrift
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	cdmock "github.com/horizoncd/horizon/mock/pkg/cd"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	driftmodels "github.com/horizoncd/horizon/pkg/drift/models"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	ctx     = context.Background()
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{}, &envmodels.Environment{},
+		&regionmodels.Region{}, &registrymodels.Registry{}, &membermodels.Member{}, &usermodels.User{},
+		&tagmodels.Tag{}, &prmodels.Pipelinerun{}, &eventmodels.Event{}, &driftmodels.ClusterDrift{}); err != nil {
+		panic(err)
+	}
+	// nolint
+	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "horizon",
+		ID:   uint(1),
+	})
+	os.Exit(m.Run())
+}
+
+func TestDriftJob(t *testing.T) {
+	registryID, err := manager.RegistryManager.Create(ctx, &registrymodels.Registry{Name: "harbor"})
+	assert.Nil(t, err)
+	region, err := manager.RegionMgr.Create(ctx, &regionmodels.Region{Name: "hz", RegistryID: registryID})
+	assert.Nil(t, err)
+	_, err = manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{Name: "test"})
+	assert.Nil(t, err)
+	_, err = manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{Name: "online", AutoResync: true})
+	assert.Nil(t, err)
+	application, err := manager.ApplicationManager.Create(ctx, &appmodels.Application{Name: "app"}, nil)
+	assert.Nil(t, err)
+	testCluster, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "app-test",
+		EnvironmentName: "test",
+		RegionName:      region.Name,
+	}, nil, nil)
+	assert.Nil(t, err)
+	onlineCluster, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "app-online",
+		EnvironmentName: "online",
+		RegionName:      region.Name,
+	}, nil, nil)
+	assert.Nil(t, err)
+	// clusters being deployed are skipped
+	deploying, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "app-deploying",
+		EnvironmentName: "test",
+		RegionName:      region.Name,
+	}, nil, nil)
+	assert.Nil(t, err)
+	_, err = manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: deploying.ID,
+		Action:    prmodels.ActionDeploy,
+		Status:    string(prmodels.StatusMerged),
+	})
+	assert.Nil(t, err)
+
+	mockCtl := gomock.NewController(t)
+	mockCD := cdmock.NewMockCD(mockCtl)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	job := &driftJob{
+		batchSize:      1,
+		mgr:            manager,
+		cd:             mockCD,
+		clusterGitRepo: clusterGitRepo,
+	}
+	deployment := cd.OutOfSyncResource{Group: "apps", Version: "v1", Kind: "Deployment", Name: "app"}
+	service := cd.OutOfSyncResource{Version: "v1", Kind: "Service", Name: "app"}
+	states := map[string]*cd.ClusterStateV2{
+		testCluster.Name: {
+			Status:             "Healthy",
+			SyncStatus:         "OutOfSync",
+			Revision:           "master",
+			OutOfSyncResources: []cd.OutOfSyncResource{deployment, service},
+		},
+		onlineCluster.Name: {
+			Status:             "Healthy",
+			SyncStatus:         "OutOfSync",
+			Revision:           "master",
+			OutOfSyncResources: []cd.OutOfSyncResource{deployment},
+		},
+	}
+	mockCD.EXPECT().GetClusterState(ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params *cd.GetClusterStateV2Params) (*cd.ClusterStateV2, error) {
+			assert.NotEqual(t, deploying.Name, params.Cluster)
+			state, ok := states[params.Cluster]
+			if !ok {
+				return nil, herrors.NewErrNotFound(herrors.ClusterStateInArgo, "cluster not found in argo")
+			}
+			return state, nil
+		}).AnyTimes()
+	// only clusters in environments allowing auto resync are resynced
+	clusterGitRepo.EXPECT().GetConfigCommit(ctx, application.Name, onlineCluster.Name).
+		Return(&gitrepo.ClusterCommit{Master: "master", Gitops: "gitops"}, nil).Times(1)
+	mockCD.EXPECT().DeployCluster(ctx, &cd.DeployClusterParams{
+		Environment: "online",
+		Cluster:     onlineCluster.Name,
+		Revision:    "master",
+	}).Return(nil).Times(1)
+
+	job.process(ctx)
+	testDrift, err := manager.ClusterDriftMgr.GetUnresolvedByClusterID(ctx, testCluster.ID)
+	assert.Nil(t, err)
+	assert.False(t, testDrift.Resynced)
+	assert.Equal(t, "OutOfSync", testDrift.SyncStatus)
+	onlineDrift, err := manager.ClusterDriftMgr.GetUnresolvedByClusterID(ctx, onlineCluster.ID)
+	assert.Nil(t, err)
+	assert.True(t, onlineDrift.Resynced)
+	_, err = manager.ClusterDriftMgr.GetUnresolvedByClusterID(ctx, deploying.ID)
+	assert.NotNil(t, err)
+
+	// the same drift is recorded only once
+	states[testCluster.Name].OutOfSyncResources = []cd.OutOfSyncResource{service, deployment}
+	states[onlineCluster.Name] = &cd.ClusterStateV2{Status: "Healthy", SyncStatus: "Synced", Revision: "master"}
+	job.process(ctx)
+	drifts, err := manager.ClusterDriftMgr.ListByClusterID(ctx, testCluster.ID, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(drifts))
+	drifts, err = manager.ClusterDriftMgr.ListByClusterID(ctx, onlineCluster.ID, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(drifts))
+	assert.NotNil(t, drifts[0].ResolvedAt)
+
+	events, err := manager.EventManager.ListEvents(ctx, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(events))
+	for _, event := range events {
+		assert.Equal(t, eventmodels.ClusterDrifted, event.EventType)
+		assert.Equal(t, common.ResourceCluster, event.ResourceType)
+	}
+}
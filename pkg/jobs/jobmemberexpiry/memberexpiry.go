@@ -0,0 +1,122 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobmemberexpiry
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	memberexpiryconfig "github.com/horizoncd/horizon/pkg/config/memberexpiry"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	uuid "github.com/satori/go.uuid"
+)
+
+// RemovedMember is the extra of members_removed event
+type RemovedMember struct {
+	ID           uint              `json:"id"`
+	MemberType   models.MemberType `json:"memberType"`
+	MemberNameID uint              `json:"memberNameID"`
+	Role         string            `json:"role"`
+	ExpiredAt    *time.Time        `json:"expiredAt"`
+}
+
+type memberExpiryJob struct {
+	batchSize int
+	mgr       *managerparam.Manager
+}
+
+// Run removes members whose bindings are expired and records members_removed events.
+// Expired members are ignored by the authorizer already, the job only cleans them up.
+func Run(ctx context.Context, jobConfig *memberexpiryconfig.Config, mgr *managerparam.Manager) {
+	job := &memberExpiryJob{
+		batchSize: jobConfig.BatchSize,
+		mgr:       mgr,
+	}
+
+	// start job
+	log.Infof(ctx, "Starting removing expired members every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping removing expired members")
+	ticker := time.NewTicker(jobConfig.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "member expiry job starts to execute, rid: %v", rid)
+			job.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *memberExpiryJob) process(ctx context.Context) {
+	op := "job: member expiry"
+	now := time.Now()
+	for {
+		members, err := j.mgr.MemberManager.ListExpired(ctx, now, j.batchSize)
+		if err != nil {
+			log.WithFiled(ctx, "op", op).
+				Errorf("failed to list expired members, err: %v", err.Error())
+			return
+		}
+		for i := range members {
+			if err := j.mgr.MemberManager.DeleteMember(ctx, members[i].ID); err != nil {
+				log.WithFiled(ctx, "op", op).
+					Errorf("failed to remove expired member %s, err: %v", members[i].BaseInfo(), err.Error())
+				return
+			}
+			log.Infof(ctx, "expired member %s is removed", members[i].BaseInfo())
+			j.recordEvent(ctx, &members[i])
+		}
+		if len(members) < j.batchSize {
+			return
+		}
+	}
+}
+
+// recordEvent records members_removed event on the resource, the event is created by the grantor
+func (j *memberExpiryJob) recordEvent(ctx context.Context, member *models.Member) {
+	extra, err := json.Marshal(RemovedMember{
+		ID:           member.ID,
+		MemberType:   member.MemberType,
+		MemberNameID: member.MemberNameID,
+		Role:         member.Role,
+		ExpiredAt:    member.ExpiredAt,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal removed member, err: %s", err.Error())
+		return
+	}
+	extraStr := string(extra)
+	if _, err := j.mgr.EventManager.CreateEvent(ctx, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: string(member.ResourceType),
+			EventType:    eventmodels.MemberRemoved,
+			ResourceID:   member.ResourceID,
+			Extra:        &extraStr,
+		},
+		CreatedBy: member.GrantedBy,
+	}); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+}
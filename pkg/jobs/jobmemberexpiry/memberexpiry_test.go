@@ -0,0 +1,81 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobmemberexpiry
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemberExpiryJob(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.Member{}, &usermodels.User{}, &eventmodels.Event{}))
+	manager := managerparam.InitManager(db)
+	ctx := context.Background()
+
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+	var members []*models.Member
+	for i, expiredAt := range []*time.Time{&past, &past, &past, &future, nil} {
+		member := &models.Member{
+			ResourceType: models.TypeApplicationCluster,
+			ResourceID:   1,
+			Role:         "owner",
+			MemberType:   models.MemberUser,
+			MemberNameID: uint(i + 1),
+			GrantedBy:    10,
+			ExpiredAt:    expiredAt,
+		}
+		assert.Nil(t, db.Create(member).Error)
+		members = append(members, member)
+	}
+
+	job := &memberExpiryJob{batchSize: 2, mgr: manager}
+	job.process(ctx)
+
+	for i, member := range members {
+		memberInDB, err := manager.MemberManager.GetByID(ctx, member.ID)
+		assert.Nil(t, err)
+		if i < 3 {
+			assert.Nil(t, memberInDB)
+		} else {
+			assert.NotNil(t, memberInDB)
+		}
+	}
+
+	events, err := manager.EventManager.ListEvents(ctx, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(events))
+	for i, event := range events {
+		assert.Equal(t, eventmodels.MemberRemoved, event.EventType)
+		assert.Equal(t, common.ResourceCluster, event.ResourceType)
+		assert.Equal(t, uint(1), event.ResourceID)
+		assert.Equal(t, uint(10), event.CreatedBy)
+		var removed RemovedMember
+		assert.Nil(t, json.Unmarshal([]byte(*event.Extra), &removed))
+		assert.Equal(t, members[i].ID, removed.ID)
+		assert.Equal(t, members[i].MemberNameID, removed.MemberNameID)
+	}
+}
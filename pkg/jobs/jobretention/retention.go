@@ -0,0 +1,153 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobretention
+
+import (
+	"context"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
+	retentionconfig "github.com/horizoncd/horizon/pkg/config/retention"
+	tektonconfig "github.com/horizoncd/horizon/pkg/config/tekton"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	uuid "github.com/satori/go.uuid"
+)
+
+const _defaultTekton = "default"
+
+type retentionJob struct {
+	batchSize    int
+	mgr          *managerparam.Manager
+	tektonFty    factory.Factory
+	tektonMapper tektonconfig.Mapper
+}
+
+// Run expires logs and objects of pipelineruns collected into log storages
+// by the retention policies of environments.
+func Run(ctx context.Context, jobConfig *retentionconfig.Config, mgr *managerparam.Manager,
+	tektonFty factory.Factory, tektonMapper tektonconfig.Mapper) {
+	job := &retentionJob{
+		batchSize:    jobConfig.BatchSize,
+		mgr:          mgr,
+		tektonFty:    tektonFty,
+		tektonMapper: tektonMapper,
+	}
+
+	// start job
+	log.Infof(ctx, "Starting expiring pipelinerun logs every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping expiring pipelinerun logs")
+	ticker := time.NewTicker(jobConfig.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "pipelinerun retention job starts to execute, rid: %v", rid)
+			job.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *retentionJob) process(ctx context.Context) {
+	op := "job: pipelinerun retention"
+	clusterIDs, err := j.mgr.PipelinerunMgr.ListClusterIDsWithCollected(ctx)
+	if err != nil {
+		log.WithFiled(ctx, "op", op).
+			Errorf("failed to list clusters with collected pipelineruns, err: %v", err.Error())
+		return
+	}
+	for _, clusterID := range clusterIDs {
+		if err := j.expireCluster(ctx, clusterID); err != nil {
+			log.WithFiled(ctx, "op", op).
+				Errorf("failed to expire pipelineruns of cluster %d, err: %+v", clusterID, err)
+		}
+	}
+}
+
+func (j *retentionJob) expireCluster(ctx context.Context, clusterID uint) error {
+	// logs of deleted clusters are expired by the policy of their environments too
+	cluster, err := j.mgr.ClusterMgr.GetByIDIncludeSoftDelete(ctx, clusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+	retention := j.getRetention(cluster.EnvironmentName)
+	if retention == nil || (retention.MaxAge <= 0 && retention.KeepLastN <= 0) {
+		return nil
+	}
+	tektonCollector, err := j.tektonFty.GetTektonCollector(cluster.EnvironmentName)
+	if err != nil {
+		return err
+	}
+
+	// pipelineruns expired are cleared and not listed again, so offset only moves forward for kept ones,
+	// and rank is the position of pipelinerun in all collected ones of the cluster
+	offset, rank := 0, 0
+	for {
+		pipelineruns, err := j.mgr.PipelinerunMgr.ListCollectedByClusterID(ctx, clusterID, offset, j.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(pipelineruns) == 0 {
+			return nil
+		}
+		for _, pr := range pipelineruns {
+			rank++
+			if !expired(retention, rank, pr) {
+				offset++
+				continue
+			}
+			if err := tektonCollector.DeleteCollected(ctx, pr); err != nil {
+				return err
+			}
+			if err := j.mgr.PipelinerunMgr.ClearCollectedByID(ctx, pr.ID); err != nil {
+				return err
+			}
+			log.Infof(ctx, "log of pipelinerun %d of cluster %v is expired", pr.ID, cluster.Name)
+		}
+	}
+}
+
+// getRetention gets the retention policy of environment, using the default tekton's if it's not configured
+func (j *retentionJob) getRetention(environment string) *tektonconfig.Retention {
+	tekton, ok := j.tektonMapper[environment]
+	if !ok {
+		tekton, ok = j.tektonMapper[_defaultTekton]
+	}
+	if !ok || tekton == nil || tekton.LogStorage == nil {
+		return nil
+	}
+	return tekton.LogStorage.Retention
+}
+
+// expired checks whether pipelinerun is older than MaxAge or not in the latest KeepLastN ones,
+// rank is the position of pipelinerun starting from 1, the latest first
+func expired(retention *tektonconfig.Retention, rank int, pr *prmodels.Pipelinerun) bool {
+	if retention.KeepLastN > 0 && rank > retention.KeepLastN {
+		return true
+	}
+	return retention.MaxAge > 0 && time.Since(pr.CreatedAt) > retention.MaxAge
+}
@@ -0,0 +1,120 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobretention
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/lib/orm"
+	collectormock "github.com/horizoncd/horizon/mock/pkg/cluster/tekton/collector"
+	tektonftymock "github.com/horizoncd/horizon/mock/pkg/cluster/tekton/factory"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	tektonconfig "github.com/horizoncd/horizon/pkg/config/tekton"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetentionJob(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&clustermodels.Cluster{}, &prmodels.Pipelinerun{}))
+	manager := managerparam.InitManager(db)
+	ctx := context.Background()
+
+	testCluster := &clustermodels.Cluster{Name: "app-test", EnvironmentName: "test"}
+	onlineCluster := &clustermodels.Cluster{Name: "app-online", EnvironmentName: "online"}
+	devCluster := &clustermodels.Cluster{Name: "app-dev", EnvironmentName: "dev"}
+	for _, cluster := range []*clustermodels.Cluster{testCluster, onlineCluster, devCluster} {
+		assert.Nil(t, db.Create(cluster).Error)
+	}
+	now := time.Now()
+	createPipelineruns := func(cluster *clustermodels.Cluster, createdAts ...time.Time) []*prmodels.Pipelinerun {
+		pipelineruns := make([]*prmodels.Pipelinerun, 0)
+		for i, createdAt := range createdAts {
+			pr, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+				ClusterID: cluster.ID,
+				Action:    prmodels.ActionBuildDeploy,
+				S3Bucket:  "bucket",
+				LogObject: fmt.Sprintf("pr-log/%s/%d", cluster.Name, i),
+				PrObject:  fmt.Sprintf("pr/%s/%d", cluster.Name, i),
+				CreatedAt: createdAt,
+			})
+			assert.Nil(t, err)
+			pipelineruns = append(pipelineruns, pr)
+		}
+		return pipelineruns
+	}
+	// the first is the oldest
+	testPRs := createPipelineruns(testCluster, now.Add(-4*time.Hour), now.Add(-3*time.Hour),
+		now.Add(-2*time.Hour), now.Add(-time.Hour))
+	onlinePRs := createPipelineruns(onlineCluster, now.Add(-72*time.Hour), now.Add(-48*time.Hour),
+		now.Add(-time.Hour))
+	devPRs := createPipelineruns(devCluster, now.Add(-72*time.Hour))
+
+	mockCtl := gomock.NewController(t)
+	tektonFty := tektonftymock.NewMockFactory(mockCtl)
+	tektonCollector := collectormock.NewMockInterface(mockCtl)
+	tektonFty.EXPECT().GetTektonCollector(gomock.Any()).Return(tektonCollector, nil).AnyTimes()
+	deleted := make(map[uint]bool)
+	tektonCollector.EXPECT().DeleteCollected(ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, pr *prmodels.Pipelinerun) error {
+			deleted[pr.ID] = true
+			return nil
+		}).AnyTimes()
+
+	job := &retentionJob{
+		batchSize: 1,
+		mgr:       manager,
+		tektonFty: tektonFty,
+		tektonMapper: tektonconfig.Mapper{
+			// test uses default tekton, and keeps the latest 2 pipelineruns
+			"default": {LogStorage: &tektonconfig.LogStorage{
+				Type:      "s3",
+				Retention: &tektonconfig.Retention{KeepLastN: 2},
+			}},
+			"online": {LogStorage: &tektonconfig.LogStorage{
+				Type:      "s3",
+				Retention: &tektonconfig.Retention{MaxAge: 24 * time.Hour, KeepLastN: 10},
+			}},
+			// no retention policy
+			"dev": {LogStorage: &tektonconfig.LogStorage{Type: "s3"}},
+		},
+	}
+	job.process(ctx)
+
+	expected := map[uint]bool{
+		testPRs[0].ID:   true,
+		testPRs[1].ID:   true,
+		onlinePRs[0].ID: true,
+		onlinePRs[1].ID: true,
+	}
+	assert.Equal(t, expected, deleted)
+	for _, prs := range [][]*prmodels.Pipelinerun{testPRs, onlinePRs, devPRs} {
+		for _, pr := range prs {
+			prGet, err := manager.PipelinerunMgr.GetByID(ctx, pr.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, expected[pr.ID], prGet.PrObject == "")
+		}
+	}
+
+	// nothing is expired again
+	deleted = make(map[uint]bool)
+	job.process(ctx)
+	assert.Equal(t, 0, len(deleted))
+}
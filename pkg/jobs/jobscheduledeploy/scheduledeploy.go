@@ -0,0 +1,177 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobscheduledeploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	scheduledeployconfig "github.com/horizoncd/horizon/pkg/config/scheduledeploy"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	"github.com/horizoncd/horizon/pkg/scheduledeploy/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	uuid "github.com/satori/go.uuid"
+)
+
+// maxMessageLength is the length of message column in db
+const maxMessageLength = 2048
+
+// clusterController is the part of cluster controller used by scheduled deploys
+type clusterController interface {
+	BuildDeploy(ctx context.Context, clusterID uint,
+		request *clusterctl.BuildDeployRequest) (*clusterctl.BuildDeployResponse, error)
+	Deploy(ctx context.Context, clusterID uint,
+		request *clusterctl.DeployRequest) (*clusterctl.PipelinerunIDResponse, error)
+	Restart(ctx context.Context, clusterID uint) (*clusterctl.PipelinerunIDResponse, error)
+}
+
+type scheduledDeployJob struct {
+	batchSize  int
+	mgr        *managerparam.Manager
+	authorizer rbac.Authorizer
+	clusterCtl clusterController
+}
+
+// Run executes scheduled deploys whose scheduled time has come.
+// Scheduled deploys are persisted in db, so the ones due during a restart are executed after it.
+func Run(ctx context.Context, jobConfig *scheduledeployconfig.Config, mgr *managerparam.Manager,
+	authorizer rbac.Authorizer, clusterCtl clusterctl.Controller) {
+	job := &scheduledDeployJob{
+		batchSize:  jobConfig.BatchSize,
+		mgr:        mgr,
+		authorizer: authorizer,
+		clusterCtl: clusterCtl,
+	}
+
+	// start job
+	log.Infof(ctx, "Starting executing scheduled deploys every %v", jobConfig.JobInterval)
+	defer log.Infof(ctx, "Stopping executing scheduled deploys")
+	ticker := time.NewTicker(jobConfig.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "scheduled deploy job starts to execute, rid: %v", rid)
+			job.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *scheduledDeployJob) process(ctx context.Context) {
+	op := "job: scheduled deploy"
+	scheduledDeploys, err := j.mgr.ScheduledDeployMgr.ListDue(ctx, time.Now(), j.batchSize)
+	if err != nil {
+		log.WithFiled(ctx, "op", op).
+			Errorf("failed to list due scheduled deploys, err: %v", err.Error())
+		return
+	}
+	for _, scheduledDeploy := range scheduledDeploys {
+		if err := j.execute(ctx, scheduledDeploy); err != nil {
+			log.WithFiled(ctx, "op", op).
+				Errorf("failed to execute scheduled deploy %d, err: %+v", scheduledDeploy.ID, err)
+		}
+	}
+}
+
+func (j *scheduledDeployJob) execute(ctx context.Context, scheduledDeploy *models.ScheduledDeploy) error {
+	// 1. mark as running, skip it if it has been cancelled in the meantime
+	claimed, err := j.mgr.ScheduledDeployMgr.UpdateStatus(ctx, scheduledDeploy.ID,
+		models.StatusPending, models.StatusRunning)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	// 2. execute as the creator, the creator's permission of the action is checked again,
+	// because it may have been revoked since the deploy was scheduled
+	pipelinerunID, err := j.executeAsCreator(ctx, scheduledDeploy)
+	status, message := models.StatusSucceeded, ""
+	if err != nil {
+		status, message = models.StatusFailed, err.Error()
+		if runes := []rune(message); len(runes) > maxMessageLength {
+			message = string(runes[:maxMessageLength])
+		}
+	}
+	log.Infof(ctx, "scheduled deploy %d of cluster %d is %v, pipelinerun: %d",
+		scheduledDeploy.ID, scheduledDeploy.ClusterID, status, pipelinerunID)
+
+	// 3. record the result
+	return j.mgr.ScheduledDeployMgr.UpdateResult(ctx, scheduledDeploy.ID, status, pipelinerunID, message)
+}
+
+func (j *scheduledDeployJob) executeAsCreator(ctx context.Context,
+	scheduledDeploy *models.ScheduledDeploy) (uint, error) {
+	user, err := j.mgr.UserManager.GetUserByID(ctx, scheduledDeploy.CreatedBy)
+	if err != nil {
+		return 0, err
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+	// the subresource of cluster is the same as the action, such as clusters/deploy
+	if err := rbac.AuthorizeAction(ctx, j.authorizer, common.ResourceCluster,
+		scheduledDeploy.ClusterID, scheduledDeploy.Action); err != nil {
+		return 0, err
+	}
+
+	var request clusterctl.BuildDeployRequest
+	if err := json.Unmarshal([]byte(scheduledDeploy.Request), &request); err != nil {
+		return 0, err
+	}
+
+	switch scheduledDeploy.Action {
+	case models.ActionBuildDeploy:
+		resp, err := j.clusterCtl.BuildDeploy(ctx, scheduledDeploy.ClusterID, &request)
+		if err != nil {
+			return 0, err
+		}
+		return resp.PipelinerunID, nil
+	case models.ActionDeploy:
+		resp, err := j.clusterCtl.Deploy(ctx, scheduledDeploy.ClusterID, &clusterctl.DeployRequest{
+			Title:       request.Title,
+			Description: request.Description,
+		})
+		if err != nil {
+			return 0, err
+		}
+		return resp.PipelinerunID, nil
+	case models.ActionRestart:
+		resp, err := j.clusterCtl.Restart(ctx, scheduledDeploy.ClusterID)
+		if err != nil {
+			return 0, err
+		}
+		return resp.PipelinerunID, nil
+	default:
+		return 0, fmt.Errorf("unsupported action %s", scheduledDeploy.Action)
+	}
+}
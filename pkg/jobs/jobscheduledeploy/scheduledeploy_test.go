@@ -0,0 +1,158 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobscheduledeploy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	rbacmock "github.com/horizoncd/horizon/mock/pkg/rbac"
+	"github.com/horizoncd/horizon/pkg/auth"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/scheduledeploy/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	ctx     = context.Background()
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&usermodels.User{}, &models.ScheduledDeploy{}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+type fakeClusterCtl struct {
+	operators   []string
+	buildDeploy *clusterctl.BuildDeployRequest
+	restartErr  error
+}
+
+func (f *fakeClusterCtl) BuildDeploy(ctx context.Context, clusterID uint,
+	request *clusterctl.BuildDeployRequest) (*clusterctl.BuildDeployResponse, error) {
+	f.record(ctx)
+	f.buildDeploy = request
+	return &clusterctl.BuildDeployResponse{PipelinerunID: 1}, nil
+}
+
+func (f *fakeClusterCtl) Deploy(ctx context.Context, clusterID uint,
+	request *clusterctl.DeployRequest) (*clusterctl.PipelinerunIDResponse, error) {
+	f.record(ctx)
+	return &clusterctl.PipelinerunIDResponse{PipelinerunID: 2}, nil
+}
+
+func (f *fakeClusterCtl) Restart(ctx context.Context, clusterID uint) (*clusterctl.PipelinerunIDResponse, error) {
+	f.record(ctx)
+	return nil, f.restartErr
+}
+
+func (f *fakeClusterCtl) record(ctx context.Context) {
+	user, err := common.UserFromContext(ctx)
+	if err == nil {
+		f.operators = append(f.operators, user.GetName())
+	}
+}
+
+func TestScheduledDeployJob(t *testing.T) {
+	user, err := manager.UserManager.Create(ctx, &usermodels.User{
+		Name:  "horizon",
+		Email: "horizon@horizon.com",
+	})
+	assert.Nil(t, err)
+
+	create := func(clusterID uint, action, request string, scheduledAt time.Time) *models.ScheduledDeploy {
+		scheduledDeploy, err := manager.ScheduledDeployMgr.Create(ctx, &models.ScheduledDeploy{
+			ClusterID:   clusterID,
+			Action:      action,
+			Request:     request,
+			ScheduledAt: scheduledAt,
+			Status:      models.StatusPending,
+			CreatedBy:   user.ID,
+		})
+		assert.Nil(t, err)
+		return scheduledDeploy
+	}
+	now := time.Now()
+	buildDeploy := create(1, models.ActionBuildDeploy, `{"title":"nightly","git":{"branch":"release"}}`,
+		now.Add(-time.Minute))
+	deploy := create(1, models.ActionDeploy, `{"title":"deploy"}`, now.Add(-time.Second))
+	restart := create(1, models.ActionRestart, `{}`, now.Add(-time.Second))
+	future := create(1, models.ActionRestart, `{}`, now.Add(time.Hour))
+	// the creator's permission on cluster 2 has been revoked
+	forbidden := create(2, models.ActionDeploy, `{"title":"deploy"}`, now.Add(-time.Second))
+
+	mockCtl := gomock.NewController(t)
+	authorizer := rbacmock.NewMockAuthorizer(mockCtl)
+	authorizer.EXPECT().Authorize(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, attr auth.Attributes) (auth.Decision, string, error) {
+			assert.Equal(t, "horizon", attr.GetUser().GetName())
+			assert.Equal(t, common.ResourceCluster, attr.GetResource())
+			if attr.GetName() == "2" {
+				assert.Equal(t, models.ActionDeploy, attr.GetSubResource())
+				return auth.DecisionDeny, "guest", nil
+			}
+			return auth.DecisionAllow, "", nil
+		}).AnyTimes()
+
+	// long messages are truncated by rune
+	clusterCtl := &fakeClusterCtl{restartErr: errors.New(strings.Repeat("重启失败", maxMessageLength))}
+	job := &scheduledDeployJob{
+		batchSize:  10,
+		mgr:        manager,
+		authorizer: authorizer,
+		clusterCtl: clusterCtl,
+	}
+	job.process(ctx)
+
+	assert.Equal(t, []string{"horizon", "horizon", "horizon"}, clusterCtl.operators)
+	assert.Equal(t, "nightly", clusterCtl.buildDeploy.Title)
+	assert.Equal(t, "release", clusterCtl.buildDeploy.Git.Branch)
+
+	get := func(id uint) *models.ScheduledDeploy {
+		scheduledDeploy, err := manager.ScheduledDeployMgr.GetByID(ctx, id)
+		assert.Nil(t, err)
+		return scheduledDeploy
+	}
+	assert.Equal(t, models.StatusSucceeded, get(buildDeploy.ID).Status)
+	assert.Equal(t, uint(1), get(buildDeploy.ID).PipelinerunID)
+	assert.Equal(t, models.StatusSucceeded, get(deploy.ID).Status)
+	assert.Equal(t, uint(2), get(deploy.ID).PipelinerunID)
+	assert.Equal(t, models.StatusFailed, get(restart.ID).Status)
+	message := get(restart.ID).Message
+	assert.True(t, utf8.ValidString(message))
+	assert.Equal(t, maxMessageLength, utf8.RuneCountInString(message))
+	assert.Equal(t, models.StatusPending, get(future.ID).Status)
+	assert.Equal(t, models.StatusFailed, get(forbidden.ID).Status)
+	assert.Contains(t, get(forbidden.ID).Message, herrors.ErrForbidden.Error())
+
+	// executed ones are not executed again
+	job.process(ctx)
+	assert.Equal(t, 3, len(clusterCtl.operators))
+}
@@ -37,7 +37,8 @@ type DAO interface {
 	Delete(ctx context.Context, memberID uint) error
 	HardDelete(ctx context.Context, resourceType string, resourceID uint) error
 	DeleteByMemberNameID(ctx context.Context, memberNameID uint) error
-	UpdateByID(ctx context.Context, memberID uint, role string) (*models.Member, error)
+	UpdateByID(ctx context.Context, memberID uint, role string, expiredAt *time.Time,
+		clearExpiredAt bool) (*models.Member, error)
 	ListDirectMember(ctx context.Context, resourceType models.ResourceType,
 		resourceID uint) ([]models.Member, error)
 	ListDirectMemberOnCondition(ctx context.Context, resourceType models.ResourceType,
@@ -46,6 +47,7 @@ type DAO interface {
 	ListResourceOfMemberInfoByRole(ctx context.Context,
 		resourceType models.ResourceType, info uint, role string) ([]uint, error)
 	ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error)
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]models.Member, error)
 }
 
 var (
@@ -89,7 +91,8 @@ func (d *dao) GetByID(ctx context.Context, memberID uint) (*models.Member, error
 	return &member, nil
 }
 
-func (d *dao) UpdateByID(ctx context.Context, id uint, role string) (*models.Member, error) {
+func (d *dao) UpdateByID(ctx context.Context, id uint, role string,
+	expiredAt *time.Time, clearExpiredAt bool) (*models.Member, error) {
 	const op = "member dao: update by ID"
 
 	currentUser, err := common2.UserFromContext(ctx)
@@ -110,6 +113,11 @@ func (d *dao) UpdateByID(ctx context.Context, id uint, role string) (*models.Mem
 
 		// 2. update value
 		memberInDB.Role = role
+		if clearExpiredAt {
+			memberInDB.ExpiredAt = nil
+		} else if expiredAt != nil {
+			memberInDB.ExpiredAt = expiredAt
+		}
 		memberInDB.GrantedBy = currentUser.GetID()
 
 		// 3. save member after updated
@@ -140,7 +148,7 @@ func (d *dao) HardDelete(ctx context.Context, resourceType string, resourceID ui
 func (d *dao) ListDirectMember(ctx context.Context, resourceType models.ResourceType,
 	resourceID uint) ([]models.Member, error) {
 	var members []models.Member
-	result := d.db.WithContext(ctx).Raw(common.MemberSelectAll, resourceType, resourceID).Scan(&members)
+	result := d.db.WithContext(ctx).Raw(common.MemberSelectAll, resourceType, resourceID, time.Now()).Scan(&members)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -151,7 +159,8 @@ func (d *dao) ListDirectMemberOnCondition(ctx context.Context, resourceType mode
 	resourceID uint) ([]models.Member, error) {
 	var members []models.Member
 	if emails, ok := ctx.Value(memberctx.MemberEmails).([]string); ok {
-		result := d.db.WithContext(ctx).Raw(common.MemberSelectByUserEmails, resourceType, resourceID, emails).Scan(&members)
+		result := d.db.WithContext(ctx).Raw(common.MemberSelectByUserEmails, resourceType, resourceID, emails,
+			time.Now()).Scan(&members)
 		if result.Error != nil {
 			return nil, result.Error
 		}
@@ -162,7 +171,7 @@ func (d *dao) ListDirectMemberOnCondition(ctx context.Context, resourceType mode
 func (d *dao) ListResourceOfMemberInfo(ctx context.Context,
 	resourceType models.ResourceType, memberInfo uint) ([]uint, error) {
 	var resources []uint
-	result := d.db.WithContext(ctx).Raw(common.MemberListResource, resourceType, memberInfo).Scan(&resources)
+	result := d.db.WithContext(ctx).Raw(common.MemberListResource, resourceType, memberInfo, time.Now()).Scan(&resources)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -176,7 +185,8 @@ func (d *dao) ListResourceOfMemberInfoByRole(ctx context.Context,
 		ResourceType: resourceType,
 		Role:         role,
 		MemberNameID: info,
-	}).Find(&members)
+	}).Where("member_type = ?", models.MemberUser).
+		Where("expired_at is null or expired_at > ?", time.Now()).Find(&members)
 	if res.Error != nil {
 		return nil, perror.Wrapf(herrors.NewErrGetFailed(herrors.MemberInfoInDB, res.Error.Error()),
 			"failed to get members:\n"+
@@ -189,10 +199,21 @@ func (d *dao) ListMembersByUserID(ctx context.Context, userID uint) ([]models.Me
 	var members []models.Member
 	result := d.db.Model(model).WithContext(ctx).
 		Where("membername_id = ?", userID).
+		Where("member_type = ?", models.MemberUser).
 		Where("deleted_ts = 0").
+		Where("expired_at is null or expired_at > ?", time.Now()).
 		Scan(&members)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return members, nil
 }
+
+func (d *dao) ListExpired(ctx context.Context, now time.Time, limit int) ([]models.Member, error) {
+	var members []models.Member
+	result := d.db.WithContext(ctx).Raw(common.MemberListExpired, now, limit).Scan(&members)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.MemberInfoInDB, result.Error.Error())
+	}
+	return members, nil
+}
@@ -16,6 +16,7 @@ package member
 
 import (
 	"context"
+	"time"
 
 	"github.com/horizoncd/horizon/pkg/member/dao"
 	"github.com/horizoncd/horizon/pkg/member/models"
@@ -34,8 +35,9 @@ type Manager interface {
 	// GetByID get the member by ID
 	GetByID(ctx context.Context, memberID uint) (*models.Member, error)
 
-	// UpdateByID  update a member by memberID
-	UpdateByID(ctx context.Context, id uint, role string) (*models.Member, error)
+	// UpdateByID  update a member by memberID, expiredAt nil means never expired
+	UpdateByID(ctx context.Context, id uint, role string, expiredAt *time.Time,
+		clearExpiredAt bool) (*models.Member, error)
 
 	// DeleteMember Delete a member by memberID
 	DeleteMember(ctx context.Context, memberID uint) error
@@ -62,6 +64,9 @@ type Manager interface {
 		resourceType models.ResourceType, memberInfo uint, role string) ([]uint, error)
 
 	ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error)
+
+	// ListExpired list members which are expired at the time
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]models.Member, error)
 }
 
 type manager struct {
@@ -85,8 +90,9 @@ func (m *manager) GetByID(ctx context.Context, memberID uint) (*models.Member, e
 	return m.dao.GetByID(ctx, memberID)
 }
 
-func (m *manager) UpdateByID(ctx context.Context, memberID uint, role string) (*models.Member, error) {
-	return m.dao.UpdateByID(ctx, memberID, role)
+func (m *manager) UpdateByID(ctx context.Context, memberID uint, role string,
+	expiredAt *time.Time, clearExpiredAt bool) (*models.Member, error) {
+	return m.dao.UpdateByID(ctx, memberID, role, expiredAt, clearExpiredAt)
 }
 
 func (m *manager) DeleteMember(ctx context.Context, memberID uint) error {
@@ -124,3 +130,7 @@ func (m *manager) ListResourceOfMemberInfoByRole(ctx context.Context,
 func (m *manager) ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error) {
 	return m.dao.ListMembersByUserID(ctx, userID)
 }
+
+func (m *manager) ListExpired(ctx context.Context, now time.Time, limit int) ([]models.Member, error) {
+	return m.dao.ListExpired(ctx, now, limit)
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/lib/orm"
@@ -84,7 +85,7 @@ func TestBasic(t *testing.T) {
 	}
 	ctx = context.WithValue(ctx, common.UserContextKey(), grandUser)
 
-	retMember2, err := mgr.UpdateByID(ctx, retMember.ID, member1.Role)
+	retMember2, err := mgr.UpdateByID(ctx, retMember.ID, member1.Role, nil, false)
 	assert.Nil(t, err)
 
 	member1.GrantedBy = grantedByCat
@@ -176,6 +177,77 @@ func TestListResourceOfMemberInfo(t *testing.T) {
 	assert.Equal(t, uint(22), resourceIDs[1])
 }
 
+func TestExpiredMember(t *testing.T) {
+	ctx := context.WithValue(context.Background(), common.UserContextKey(),
+		&userauth.DefaultInfo{Name: "cat", ID: 3})
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	expiredMember := &models.Member{
+		ResourceType: models.TypeApplicationCluster,
+		ResourceID:   33,
+		Role:         "owner",
+		MemberType:   models.MemberUser,
+		MemberNameID: 1,
+		ExpiredAt:    &past,
+	}
+	_, err := mgr.Create(ctx, expiredMember)
+	assert.Nil(t, err)
+	validMember := &models.Member{
+		ResourceType: models.TypeApplicationCluster,
+		ResourceID:   33,
+		Role:         "owner",
+		MemberType:   models.MemberUser,
+		MemberNameID: 2,
+		ExpiredAt:    &future,
+	}
+	_, err = mgr.Create(ctx, validMember)
+	assert.Nil(t, err)
+
+	// expired members are not listed
+	members, err := mgr.ListDirectMember(ctx, models.TypeApplicationCluster, 33)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(members))
+	assert.Equal(t, validMember.ID, members[0].ID)
+	resourceIDs, err := mgr.ListResourceOfMemberInfo(ctx, models.TypeApplicationCluster, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(resourceIDs))
+
+	members, err = mgr.ListExpired(ctx, now, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(members))
+	assert.Equal(t, expiredMember.ID, members[0].ID)
+	assert.True(t, members[0].Expired(now))
+
+	// expiry is extended by update
+	retMember, err := mgr.UpdateByID(ctx, expiredMember.ID, "maintainer", &future, false)
+	assert.Nil(t, err)
+	assert.False(t, retMember.Expired(now))
+	members, err = mgr.ListDirectMember(ctx, models.TypeApplicationCluster, 33)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(members))
+	members, err = mgr.ListExpired(ctx, now, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(members))
+
+	// role only update keeps the expiry
+	retMember, err = mgr.UpdateByID(ctx, expiredMember.ID, "owner", nil, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "owner", retMember.Role)
+	assert.NotNil(t, retMember.ExpiredAt)
+	assert.WithinDuration(t, future, *retMember.ExpiredAt, time.Second)
+	retMember, err = mgr.GetByID(ctx, expiredMember.ID)
+	assert.Nil(t, err)
+	assert.NotNil(t, retMember.ExpiredAt)
+
+	// expiry is cleared explicitly
+	retMember, err = mgr.UpdateByID(ctx, expiredMember.ID, "owner", nil, true)
+	assert.Nil(t, err)
+	assert.Nil(t, retMember.ExpiredAt)
+	retMember, err = mgr.GetByID(ctx, expiredMember.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, retMember.ExpiredAt)
+}
+
 func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&models.Member{}, &usermodels.User{}); err != nil {
 		panic(err)
@@ -16,6 +16,7 @@ package models
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/pkg/server/global"
@@ -76,6 +77,14 @@ type Member struct {
 	// TODO(tom): change go user
 	GrantedBy uint `gorm:"column:granted_by"`
 	CreatedBy uint `gorm:"column:created_by"`
+
+	// ExpiredAt is the time when the binding expires, nil means never
+	ExpiredAt *time.Time `gorm:"column:expired_at"`
+}
+
+// Expired checks whether the binding is expired at the time
+func (m *Member) Expired(now time.Time) bool {
+	return m.ExpiredAt != nil && !m.ExpiredAt.After(now)
 }
 
 func (m *Member) BaseInfo() string {
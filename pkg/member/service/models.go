@@ -38,6 +38,12 @@ type PostMember struct {
 
 	// Role owner/maintainer/develop/...
 	Role string
+
+	// ExpiredAt the time when the member expires, nil means never
+	ExpiredAt *time.Time
+
+	// ClearExpiredAt makes an existing member never expire when it is posted again
+	ClearExpiredAt bool
 }
 
 type Member struct {
@@ -101,5 +107,6 @@ func ConvertPostMemberToMember(postMember PostMember, currentUser userauth.User)
 		MemberType:   postMember.MemberType,
 		MemberNameID: postMember.MemberInfo,
 		GrantedBy:    currentUser.GetID(),
+		ExpiredAt:    postMember.ExpiredAt,
 	}, nil
 }
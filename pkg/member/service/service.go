@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	herror "github.com/horizoncd/horizon/core/errors"
@@ -38,7 +39,9 @@ import (
 	templatereleasemanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	usergroupmanager "github.com/horizoncd/horizon/pkg/usergroup/manager"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/visibility"
 	webhookmanager "github.com/horizoncd/horizon/pkg/webhook/manager"
 )
 
@@ -50,8 +53,10 @@ type Service interface {
 	CreateMember(ctx context.Context, postMember PostMember) (*models.Member, error)
 	// GetMember return the current user member of direct or parent
 	GetMember(ctx context.Context, memberID uint) (*models.Member, error)
-	// UpdateMember update the member by the memberID
-	UpdateMember(ctx context.Context, memberID uint, role string) (*models.Member, error)
+	// UpdateMember update the member by the memberID, a nil expiredAt keeps the current expiry
+	// unless clearExpiredAt is set, which makes the member never expire
+	UpdateMember(ctx context.Context, memberID uint, role string, expiredAt *time.Time,
+		clearExpiredAt bool) (*models.Member, error)
 	// RemoveMember Remove the member by the memberID
 	RemoveMember(ctx context.Context, memberID uint) error
 	// ListMember list all the member of the resource
@@ -74,6 +79,8 @@ type service struct {
 	oauthManager              oauthmanager.Manager
 	userManager               usermanager.Manager
 	webhookManager            webhookmanager.Manager
+	userGroupManager          usergroupmanager.Manager
+	visibilityChecker         visibility.Checker
 }
 
 func NewService(roleService roleservice.Service, oauthManager oauthmanager.Manager,
@@ -90,6 +97,8 @@ func NewService(roleService roleservice.Service, oauthManager oauthmanager.Manag
 		oauthManager:              oauthManager,
 		userManager:               manager.UserManager,
 		webhookManager:            manager.WebhookManager,
+		userGroupManager:          manager.UserGroupMgr,
+		visibilityChecker:         manager.VisibilityChecker,
 	}
 }
 
@@ -104,8 +113,7 @@ func (s *service) RequirePermissionEqualOrHigher(ctx context.Context, role,
 		return nil
 	}
 	var userMemberInfo *models.Member
-	userMemberInfo, err = s.getMember(ctx, resourceType,
-		resourceID, models.MemberUser, currentUser.GetID())
+	userMemberInfo, err = s.getUserMember(ctx, resourceType, resourceID, currentUser.GetID())
 	if err != nil {
 		return err
 	}
@@ -137,8 +145,10 @@ func (s *service) CreateMember(ctx context.Context, postMember PostMember) (*mod
 		return nil, err
 	}
 	if memberItem != nil {
-		// if member exist, try to update the member
-		return s.UpdateMember(ctx, memberItem.ID, postMember.Role)
+		// if member exist, try to update the member,
+		// and an expired member which is not removed yet is granted afresh rather than keeping the expiry
+		clearExpiredAt := postMember.ClearExpiredAt || memberItem.Expired(time.Now())
+		return s.UpdateMember(ctx, memberItem.ID, postMember.Role, postMember.ExpiredAt, clearExpiredAt)
 	}
 
 	// 2. check if current user can create the role
@@ -146,6 +156,11 @@ func (s *service) CreateMember(ctx context.Context, postMember PostMember) (*mod
 	if err != nil {
 		return nil, err
 	}
+	if postMember.MemberType == models.MemberGroup {
+		if _, err := s.userGroupManager.GetByID(ctx, postMember.MemberInfo); err != nil {
+			return nil, err
+		}
+	}
 
 	// 3. do create  member
 	member, err := ConvertPostMemberToMember(postMember, currentUser)
@@ -167,7 +182,7 @@ func (s *service) getOauthAppMember(ctx context.Context, clientID string) (*mode
 	if !app.IsGroupOwnerType() {
 		return nil, herror.ErrOAuthNotGroupOwnerType
 	}
-	return s.getMember(ctx, common.ResourceGroup, app.OwnerID, models.MemberUser, currentUser.GetID())
+	return s.getUserMember(ctx, common.ResourceGroup, app.OwnerID, currentUser.GetID())
 }
 
 func (s *service) getPipelinerunMember(ctx context.Context, pipelinerunID uint) (*models.Member, error) {
@@ -184,8 +199,7 @@ func (s *service) getPipelinerunMember(ctx context.Context, pipelinerunID uint)
 		log.Warningf(ctx, msg)
 		return nil, herror.NewErrNotFound(herror.MemberInfoInDB, msg)
 	}
-	return s.getMember(ctx, common.ResourceCluster,
-		pipeline.ClusterID, models.MemberUser, currentUser.GetID())
+	return s.getUserMember(ctx, common.ResourceCluster, pipeline.ClusterID, currentUser.GetID())
 }
 
 func (s *service) listPipelinerunMember(ctx context.Context, pipelinerunID uint) ([]models.Member, error) {
@@ -245,7 +259,7 @@ func (s *service) GetMemberOfResource(ctx context.Context,
 		memberInfo, err = s.getOauthAppMember(ctx, resourceIDStr)
 	} else {
 		resourceID, _ := strconv.Atoi(resourceIDStr)
-		memberInfo, err = s.getMember(ctx, resourceType, uint(resourceID), models.MemberUser, currentUser.GetID())
+		memberInfo, err = s.getUserMember(ctx, resourceType, uint(resourceID), currentUser.GetID())
 	}
 	if err != nil {
 		return nil, err
@@ -254,6 +268,14 @@ func (s *service) GetMemberOfResource(ctx context.Context,
 		defaultRole := s.roleService.GetDefaultRole(ctx)
 		if nil != defaultRole {
 			resourceID, _ := strconv.Atoi(resourceIDStr)
+			// non members of private resources can not choose the default role
+			private, err := s.visibilityChecker.IsPrivate(ctx, resourceType, uint(resourceID))
+			if err != nil {
+				return nil, err
+			}
+			if private {
+				return nil, nil
+			}
 			memberInfo = &models.Member{
 				MemberType:   models.MemberUser,
 				Role:         defaultRole.Name,
@@ -287,18 +309,21 @@ func (s *service) RemoveMember(ctx context.Context, memberID uint) error {
 	}
 
 	// 3. check if common user
-	user, err := s.userManager.GetUserByID(ctx, memberItem.MemberNameID)
-	if err != nil {
-		return err
-	}
-	if user.UserType != usermodels.UserTypeCommon {
-		return perror.Wrapf(herror.ErrParamInvalid, "member of user type %d does not support updated", user.UserType)
+	if memberItem.MemberType == models.MemberUser {
+		user, err := s.userManager.GetUserByID(ctx, memberItem.MemberNameID)
+		if err != nil {
+			return err
+		}
+		if user.UserType != usermodels.UserTypeCommon {
+			return perror.Wrapf(herror.ErrParamInvalid, "member of user type %d does not support updated", user.UserType)
+		}
 	}
 
 	return s.memberManager.DeleteMember(ctx, memberID)
 }
 
-func (s *service) UpdateMember(ctx context.Context, memberID uint, role string) (*models.Member, error) {
+func (s *service) UpdateMember(ctx context.Context, memberID uint, role string,
+	expiredAt *time.Time, clearExpiredAt bool) (*models.Member, error) {
 	// 1. get the member
 	memberItem, err := s.memberManager.GetByID(ctx, memberID)
 	if err != nil {
@@ -320,16 +345,19 @@ func (s *service) UpdateMember(ctx context.Context, memberID uint, role string)
 	}
 
 	// 3. check if common user
-	user, err := s.userManager.GetUserByID(ctx, memberItem.MemberNameID)
-	if err != nil {
-		return nil, err
-	}
-	if user.UserType != usermodels.UserTypeCommon {
-		return nil, perror.Wrapf(herror.ErrParamInvalid, "member of user type %d does not support updated", user.UserType)
+	if memberItem.MemberType == models.MemberUser {
+		user, err := s.userManager.GetUserByID(ctx, memberItem.MemberNameID)
+		if err != nil {
+			return nil, err
+		}
+		if user.UserType != usermodels.UserTypeCommon {
+			return nil, perror.Wrapf(herror.ErrParamInvalid,
+				"member of user type %d does not support updated", user.UserType)
+		}
 	}
 
 	// 4. update the role
-	return s.memberManager.UpdateByID(ctx, memberItem.ID, role)
+	return s.memberManager.UpdateByID(ctx, memberItem.ID, role, expiredAt, clearExpiredAt)
 }
 
 func (s *service) ListMember(ctx context.Context, resourceType string, resourceID uint) ([]models.Member, error) {
@@ -378,20 +406,76 @@ func DeduplicateMember(members []models.Member) []models.Member {
 	return retMembers
 }
 
-// getMember return the direct member or member from the parent
-func (s *service) getMember(ctx context.Context, resourceType string, resourceID uint,
-	memberType models.MemberType, memberInfo uint) (*models.Member, error) {
+// getUserMember returns the member of user among bindings of the user and groups which the user belongs to,
+// the binding on the closest resource takes precedence, then the one with the highest role,
+// and the binding of user wins a tie with a binding of group
+func (s *service) getUserMember(ctx context.Context, resourceType string, resourceID uint,
+	userID uint) (*models.Member, error) {
 	members, err := s.ListMember(ctx, resourceType, resourceID)
 	if err != nil {
 		return nil, err
 	}
-	for _, item := range members {
-		if item.MemberType == memberType &&
-			item.MemberNameID == memberInfo {
-			return &item, nil
+
+	// members are listed from the resource itself up to its ancestors
+	proximity := make([]int, len(members))
+	resources := make(map[string]int)
+	hasGroupMember := false
+	for i := range members {
+		key := fmt.Sprintf("%s-%d", members[i].ResourceType, members[i].ResourceID)
+		if _, ok := resources[key]; !ok {
+			resources[key] = len(resources)
 		}
+		proximity[i] = resources[key]
+		if members[i].MemberType == models.MemberGroup {
+			hasGroupMember = true
+		}
+	}
+
+	userGroupIDs := make(map[uint]bool)
+	if hasGroupMember {
+		userGroups, err := s.userGroupManager.ListByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, userGroup := range userGroups {
+			userGroupIDs[userGroup.ID] = true
+		}
+	}
+
+	best := -1
+	for i := range members {
+		switch members[i].MemberType {
+		case models.MemberUser:
+			if members[i].MemberNameID != userID {
+				continue
+			}
+		case models.MemberGroup:
+			if !userGroupIDs[members[i].MemberNameID] {
+				continue
+			}
+		default:
+			continue
+		}
+		if best < 0 || proximity[i] < proximity[best] {
+			best = i
+			continue
+		}
+		if proximity[i] > proximity[best] {
+			continue
+		}
+		result, err := s.roleService.RoleCompare(ctx, members[i].Role, members[best].Role)
+		if err != nil {
+			return nil, err
+		}
+		if result == roleservice.RoleBigger ||
+			(result == roleservice.RoleEqual && members[i].MemberType == models.MemberUser) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil, nil
 	}
-	return nil, nil
+	return &members[best], nil
 }
 
 func (s *service) listGroupMembers(ctx context.Context, resourceID uint) ([]models.Member, error) {
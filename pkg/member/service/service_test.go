@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/horizoncd/horizon/core/common"
@@ -43,6 +44,7 @@ import (
 	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	usergroupmodels "github.com/horizoncd/horizon/pkg/usergroup/models"
 	webhookmodels "github.com/horizoncd/horizon/pkg/webhook/models"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
@@ -258,7 +260,7 @@ func TestCreateAndUpdateGroupMember(t *testing.T) {
 
 	// update member not exist
 	var memberIDNotExist uint = 123233434
-	member, err = s.UpdateMember(ctx, memberIDNotExist, "owner")
+	member, err = s.UpdateMember(ctx, memberIDNotExist, "owner", nil, false)
 	_, ok := perror.Cause(err).(*herror.HorizonErrNotFound)
 	assert.True(t, ok)
 
@@ -275,7 +277,7 @@ func TestCreateAndUpdateGroupMember(t *testing.T) {
 			TraversalIDs:    traversalIDs,
 		}, nil
 	}).Times(1)
-	member, err = s.UpdateMember(ctx, tomMember1.ID, "maintainer")
+	member, err = s.UpdateMember(ctx, tomMember1.ID, "maintainer", nil, false)
 	assert.Nil(t, err)
 	assert.Equal(t, member.Role, "maintainer")
 	assert.Equal(t, member.ID, tomMember1.ID)
@@ -300,6 +302,25 @@ func TestCreateAndUpdateGroupMember(t *testing.T) {
 	}).Times(1)
 	err = s.RemoveMember(ctx, catMember2.ID)
 	assert.Nil(t, err)
+
+	// an expired member which is not removed yet is granted afresh
+	past := time.Now().Add(-time.Hour)
+	expiredMember, err := manager.MemberManager.Create(ctx, &models.Member{
+		ResourceType: models.TypeGroup,
+		ResourceID:   group2ID,
+		Role:         "maintainer",
+		MemberType:   models.MemberUser,
+		MemberNameID: catID,
+		ExpiredAt:    &past,
+	})
+	assert.Nil(t, err)
+	groupManager.EXPECT().GetByID(gomock.Any(),
+		gomock.Any()).Return(&groupModels.Group{TraversalIDs: traversalIDs}, nil).AnyTimes()
+	member, err = s.CreateMember(ctx, postMemberCat2)
+	assert.Nil(t, err)
+	assert.Equal(t, expiredMember.ID, member.ID)
+	assert.Equal(t, postMemberCat2.Role, member.Role)
+	assert.Nil(t, member.ExpiredAt)
 }
 
 // nolint
@@ -804,7 +825,7 @@ func TestGetPipelinerunMember(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, PostMemberEqualsMember(postMembers[3], members))
 
-	members, err = s.UpdateMember(ctx, members.ID, roleservice.Maintainer)
+	members, err = s.UpdateMember(ctx, members.ID, roleservice.Maintainer, nil, false)
 	assert.Nil(t, err)
 	assert.Equal(t, roleservice.Maintainer, members.Role)
 
@@ -977,6 +998,106 @@ func TestListWebhookMember(t *testing.T) {
 	assert.Equal(t, "pe", memberInfo.Role)
 }
 
+func TestGetMemberOfUserGroup(t *testing.T) {
+	createEnv(t)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	groupManager := groupmanagermock.NewMockManager(mockCtrl)
+	roleMockService := rolemock.NewMockService(mockCtrl)
+	s = &service{
+		memberManager:    manager.MemberManager,
+		groupManager:     groupManager,
+		roleService:      roleMockService,
+		userManager:      manager.UserManager,
+		userGroupManager: manager.UserGroupMgr,
+	}
+
+	var groupID, subGroupID, idpID uint = 1, 2, 1
+	groupManager.EXPECT().IsRootGroup(gomock.Any(), gomock.Any()).AnyTimes().Return(false)
+	groupManager.EXPECT().GetByID(gomock.Any(), groupID).AnyTimes().Return(&groupModels.Group{
+		Model:        global.Model{ID: groupID},
+		TraversalIDs: "1",
+	}, nil)
+	groupManager.EXPECT().GetByID(gomock.Any(), subGroupID).AnyTimes().Return(&groupModels.Group{
+		Model:        global.Model{ID: subGroupID},
+		TraversalIDs: "1,2",
+	}, nil)
+	roleMockService.EXPECT().RoleCompare(gomock.Any(), roleservice.Owner, roleservice.Maintainer).
+		Return(roleservice.RoleBigger, nil).AnyTimes()
+	roleMockService.EXPECT().RoleCompare(gomock.Any(), roleservice.Maintainer, roleservice.Owner).
+		Return(roleservice.RoleSmaller, nil).AnyTimes()
+	roleMockService.EXPECT().RoleCompare(gomock.Any(), roleservice.Maintainer, roleservice.Maintainer).
+		Return(roleservice.RoleEqual, nil).AnyTimes()
+	roleMockService.EXPECT().GetDefaultRole(gomock.Any()).Return(nil).AnyTimes()
+
+	tom, err := manager.UserManager.Create(ctx, &usermodels.User{Name: "tom"})
+	assert.Nil(t, err)
+	jerry, err := manager.UserManager.Create(ctx, &usermodels.User{Name: "jerry"})
+	assert.Nil(t, err)
+	userGroups, err := manager.UserGroupMgr.SyncUserGroups(ctx, tom.ID, idpID, []string{"dev", "ops"})
+	assert.Nil(t, err)
+	dev, ops := userGroups[0], userGroups[1]
+
+	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{Name: tom.Name, ID: tom.ID})
+	for _, postMember := range []PostMember{
+		{MemberInfo: dev.ID, Role: roleservice.Maintainer},
+		{MemberInfo: ops.ID, Role: roleservice.Owner},
+	} {
+		postMember.ResourceType = common.ResourceGroup
+		postMember.ResourceID = groupID
+		postMember.MemberType = models.MemberGroup
+		_, err = s.(*service).createMemberDirect(ctx, postMember)
+		assert.Nil(t, err)
+	}
+
+	// the highest role of groups is applied
+	member, err := s.GetMemberOfResource(ctx, common.ResourceGroup, strconv.Itoa(int(groupID)))
+	assert.Nil(t, err)
+	assert.Equal(t, models.MemberGroup, member.MemberType)
+	assert.Equal(t, ops.ID, member.MemberNameID)
+	assert.Equal(t, roleservice.Owner, member.Role)
+
+	// binding of user does not override a higher role of groups on the same resource
+	_, err = s.(*service).createMemberDirect(ctx, PostMember{ResourceType: common.ResourceGroup,
+		ResourceID: groupID, MemberType: models.MemberUser, MemberInfo: tom.ID, Role: roleservice.Maintainer})
+	assert.Nil(t, err)
+	member, err = s.GetMemberOfResource(ctx, common.ResourceGroup, strconv.Itoa(int(groupID)))
+	assert.Nil(t, err)
+	assert.Equal(t, models.MemberGroup, member.MemberType)
+	assert.Equal(t, ops.ID, member.MemberNameID)
+
+	// binding on the closest resource takes precedence over bindings on ancestors
+	_, err = s.(*service).createMemberDirect(ctx, PostMember{ResourceType: common.ResourceGroup,
+		ResourceID: subGroupID, MemberType: models.MemberGroup, MemberInfo: dev.ID, Role: roleservice.Maintainer})
+	assert.Nil(t, err)
+	member, err = s.GetMemberOfResource(ctx, common.ResourceGroup, strconv.Itoa(int(subGroupID)))
+	assert.Nil(t, err)
+	assert.Equal(t, models.MemberGroup, member.MemberType)
+	assert.Equal(t, dev.ID, member.MemberNameID)
+	assert.Equal(t, subGroupID, member.ResourceID)
+
+	// binding of user wins a tie with groups
+	_, err = s.(*service).createMemberDirect(ctx, PostMember{ResourceType: common.ResourceGroup,
+		ResourceID: subGroupID, MemberType: models.MemberUser, MemberInfo: tom.ID, Role: roleservice.Maintainer})
+	assert.Nil(t, err)
+	member, err = s.GetMemberOfResource(ctx, common.ResourceGroup, strconv.Itoa(int(subGroupID)))
+	assert.Nil(t, err)
+	assert.Equal(t, models.MemberUser, member.MemberType)
+	assert.Equal(t, subGroupID, member.ResourceID)
+
+	// users out of groups are not members
+	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{Name: jerry.Name, ID: jerry.ID})
+	member, err = s.GetMemberOfResource(ctx, common.ResourceGroup, strconv.Itoa(int(groupID)))
+	assert.Nil(t, err)
+	assert.Nil(t, member)
+
+	// members of group type are listed
+	members, err := s.ListMember(ctx, common.ResourceGroup, groupID)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(members))
+}
+
 func createEnv(t *testing.T) {
 	db, _ = orm.NewSqliteDB("")
 	err := db.AutoMigrate(&models.Member{},
@@ -987,6 +1108,8 @@ func createEnv(t *testing.T) {
 		&templatemodels.Template{},
 		&webhookmodels.Webhook{},
 		&webhookmodels.WebhookLog{},
+		&usergroupmodels.UserGroup{},
+		&usergroupmodels.UserGroupMember{},
 	)
 
 	assert.Nil(t, err)
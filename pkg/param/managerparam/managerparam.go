@@ -19,9 +19,14 @@ import (
 	"gorm.io/gorm"
 
 	accesstokenmanager "github.com/horizoncd/horizon/pkg/accesstoken/manager"
+	alertmanager "github.com/horizoncd/horizon/pkg/alert/manager"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	applicationregionmanager "github.com/horizoncd/horizon/pkg/applicationregion/manager"
+	approvalmanager "github.com/horizoncd/horizon/pkg/approval/manager"
+	auditmanager "github.com/horizoncd/horizon/pkg/audit/manager"
+	canarymanager "github.com/horizoncd/horizon/pkg/canary/manager"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	driftmanager "github.com/horizoncd/horizon/pkg/drift/manager"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	environmentregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	eventManager "github.com/horizoncd/horizon/pkg/event/manager"
@@ -32,20 +37,26 @@ import (
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pipelinerun/pipeline/manager"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	registrymanager "github.com/horizoncd/horizon/pkg/registry/manager"
+	releasepipelinemanager "github.com/horizoncd/horizon/pkg/releasepipeline/manager"
+	scheduledeploymanager "github.com/horizoncd/horizon/pkg/scheduledeploy/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	templateschematagmanager "github.com/horizoncd/horizon/pkg/templateschematag/manager"
 	trtmanager "github.com/horizoncd/horizon/pkg/templateschematag/manager"
+	terminalrecordingmanager "github.com/horizoncd/horizon/pkg/terminalrecording/manager"
 	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	usergroupmanager "github.com/horizoncd/horizon/pkg/usergroup/manager"
 	linkmanager "github.com/horizoncd/horizon/pkg/userlink/manager"
+	"github.com/horizoncd/horizon/pkg/visibility"
 	webhookManager "github.com/horizoncd/horizon/pkg/webhook/manager"
 )
 
 type Manager struct {
 	UserManager              usermanager.Manager
 	UserLinksManager         linkmanager.Manager
+	UserGroupMgr             usergroupmanager.Manager
 	ApplicationManager       applicationmanager.Manager
 	TemplateReleaseManager   trmanager.Manager
 	TemplateSchemaTagManager trtmanager.Manager
@@ -69,12 +80,22 @@ type Manager struct {
 	WebhookManager           webhookManager.Manager
 	EventManager             eventManager.Manager
 	TokenManager             tokenmanager.Manager
+	CanaryRuleMgr            canarymanager.Manager
+	ScheduledDeployMgr       scheduledeploymanager.Manager
+	ApprovalMgr              approvalmanager.Manager
+	ReleasePipelineMgr       releasepipelinemanager.Manager
+	ClusterDriftMgr          driftmanager.Manager
+	AuditLogMgr              auditmanager.Manager
+	TerminalRecordingMgr     terminalrecordingmanager.Manager
+	AlertRuleMgr             alertmanager.Manager
+	VisibilityChecker        visibility.Checker
 }
 
 func InitManager(db *gorm.DB) *Manager {
-	return &Manager{
+	manager := &Manager{
 		UserManager:              usermanager.New(db),
 		UserLinksManager:         linkmanager.New(db),
+		UserGroupMgr:             usergroupmanager.New(db),
 		ApplicationManager:       applicationmanager.New(db),
 		TemplateReleaseManager:   trmanager.New(db),
 		TemplateSchemaTagManager: trtmanager.New(db),
@@ -98,5 +119,16 @@ func InitManager(db *gorm.DB) *Manager {
 		WebhookManager:           webhookManager.New(db),
 		EventManager:             eventManager.New(db),
 		TokenManager:             tokenmanager.New(db),
+		CanaryRuleMgr:            canarymanager.New(db),
+		ScheduledDeployMgr:       scheduledeploymanager.New(db),
+		ApprovalMgr:              approvalmanager.New(db),
+		ReleasePipelineMgr:       releasepipelinemanager.New(db),
+		ClusterDriftMgr:          driftmanager.New(db),
+		AuditLogMgr:              auditmanager.New(db),
+		TerminalRecordingMgr:     terminalrecordingmanager.New(db),
+		AlertRuleMgr:             alertmanager.New(db),
 	}
+	manager.VisibilityChecker = visibility.NewChecker(manager.GroupManager, manager.ApplicationManager,
+		manager.ClusterMgr, manager.PipelinerunMgr, manager.MemberManager)
+	return manager
 }
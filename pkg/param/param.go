@@ -17,9 +17,11 @@ package param
 import (
 	applicationgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
+	approvalservice "github.com/horizoncd/horizon/pkg/approval/service"
 	"github.com/horizoncd/horizon/pkg/cd"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
 	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/render"
 	clusterservice "github.com/horizoncd/horizon/pkg/cluster/service"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
 	"github.com/horizoncd/horizon/pkg/environment/service"
@@ -30,12 +32,15 @@ import (
 	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
 	"github.com/horizoncd/horizon/pkg/oauth/scope"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 
 	"github.com/horizoncd/horizon/core/controller/build"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	templatesource "github.com/horizoncd/horizon/pkg/templaterelease/source"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
 )
 
@@ -55,11 +60,15 @@ type Param struct {
 	RoleService    role.Service
 	ScopeService   scope.Service
 	GrafanaService grafana.Service
+	ApprovalSvc    approvalservice.Service
 
 	// others
 	Hook                 hook.Hook
 	ApplicationGitRepo   applicationgitrepo.ApplicationGitRepo
 	TemplateSchemaGetter templateschema.Getter
+	TemplateSourceGetter templatesource.Getter
+	MigrationGetter      migration.Getter
+	ManifestRenderer     render.Renderer
 	CD                   cd.CD
 	K8sUtil              cd.K8sUtil
 	OutputGetter         output.Getter
@@ -67,4 +76,5 @@ type Param struct {
 	ClusterGitRepo       clustergitrepo.ClusterGitRepo
 	GitGetter            code.GitGetter
 	BuildSchema          *build.Schema
+	Authorizer           rbac.Authorizer
 }
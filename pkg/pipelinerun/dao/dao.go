@@ -43,6 +43,12 @@ type DAO interface {
 	UpdateResultByID(ctx context.Context, pipelinerunID uint, result *models.Result) error
 	GetLatestSuccessByClusterID(ctx context.Context, clusterID uint) (*models.Pipelinerun, error)
 	GetFirstCanRollbackPipelinerun(ctx context.Context, clusterID uint) (*models.Pipelinerun, error)
+	// ListClusterIDsWithCollected lists clusters which have pipelineruns collected into the log storage
+	ListClusterIDsWithCollected(ctx context.Context) ([]uint, error)
+	// ListCollectedByClusterID lists pipelineruns of cluster collected into the log storage, the latest first
+	ListCollectedByClusterID(ctx context.Context, clusterID uint, offset, limit int) ([]*models.Pipelinerun, error)
+	// ClearCollectedByID clears the log and object of pipelinerun after they're deleted from the log storage
+	ClearCollectedByID(ctx context.Context, pipelinerunID uint) error
 }
 
 type dao struct{ db *gorm.DB }
@@ -226,3 +232,31 @@ func (d *dao) GetFirstCanRollbackPipelinerun(ctx context.Context, clusterID uint
 	}
 	return &pipelinerun, nil
 }
+
+func (d *dao) ListClusterIDsWithCollected(ctx context.Context) ([]uint, error) {
+	var clusterIDs []uint
+	result := d.db.WithContext(ctx).Raw(common.PipelinerunListClusterIDsWithCollected).Scan(&clusterIDs)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.PipelinerunInDB, result.Error.Error())
+	}
+	return clusterIDs, nil
+}
+
+func (d *dao) ListCollectedByClusterID(ctx context.Context, clusterID uint,
+	offset, limit int) ([]*models.Pipelinerun, error) {
+	var pipelineruns []*models.Pipelinerun
+	result := d.db.WithContext(ctx).Raw(common.PipelinerunListCollectedByClusterID,
+		clusterID, limit, offset).Scan(&pipelineruns)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.PipelinerunInDB, result.Error.Error())
+	}
+	return pipelineruns, nil
+}
+
+func (d *dao) ClearCollectedByID(ctx context.Context, pipelinerunID uint) error {
+	result := d.db.WithContext(ctx).Exec(common.PipelinerunClearCollectedByID, pipelinerunID)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.PipelinerunInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -45,6 +45,12 @@ type Manager interface {
 	UpdateCIEventIDByID(ctx context.Context, pipelinerunID uint, ciEventID string) error
 	// UpdateResultByID  update the pipelinerun restore result
 	UpdateResultByID(ctx context.Context, pipelinerunID uint, result *models.Result) error
+	// ListClusterIDsWithCollected lists clusters which have pipelineruns collected into the log storage
+	ListClusterIDsWithCollected(ctx context.Context) ([]uint, error)
+	// ListCollectedByClusterID lists pipelineruns of cluster collected into the log storage, the latest first
+	ListCollectedByClusterID(ctx context.Context, clusterID uint, offset, limit int) ([]*models.Pipelinerun, error)
+	// ClearCollectedByID clears the log and object of pipelinerun after they're deleted from the log storage
+	ClearCollectedByID(ctx context.Context, pipelinerunID uint) error
 }
 
 type manager struct {
@@ -115,3 +121,16 @@ func (m *manager) GetLatestByClusterIDAndActionAndStatus(ctx context.Context, cl
 	status string) (*models.Pipelinerun, error) {
 	return m.dao.GetLatestByClusterIDAndActionAndStatus(ctx, clusterID, action, status)
 }
+
+func (m *manager) ListClusterIDsWithCollected(ctx context.Context) ([]uint, error) {
+	return m.dao.ListClusterIDsWithCollected(ctx)
+}
+
+func (m *manager) ListCollectedByClusterID(ctx context.Context, clusterID uint,
+	offset, limit int) ([]*models.Pipelinerun, error) {
+	return m.dao.ListCollectedByClusterID(ctx, clusterID, offset, limit)
+}
+
+func (m *manager) ClearCollectedByID(ctx context.Context, pipelinerunID uint) error {
+	return m.dao.ClearCollectedByID(ctx, pipelinerunID)
+}
@@ -17,6 +17,7 @@ package manager
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -234,6 +235,48 @@ func TestGetFirstCanRollbackPipelinerun(t *testing.T) {
 	assert.Nil(t, pipelinerun)
 }
 
+func TestCollected(t *testing.T) {
+	clusterID := uint(20000)
+	for i := 0; i < 3; i++ {
+		_, err := mgr.Create(ctx, &models.Pipelinerun{
+			ClusterID: clusterID,
+			Action:    models.ActionBuildDeploy,
+			Status:    "ok",
+			S3Bucket:  "bucket",
+			LogObject: fmt.Sprintf("pr-log/%d", i),
+			PrObject:  fmt.Sprintf("pr/%d", i),
+		})
+		assert.Nil(t, err)
+	}
+	// not collected
+	_, err := mgr.Create(ctx, &models.Pipelinerun{
+		ClusterID: clusterID,
+		Action:    models.ActionDeploy,
+		Status:    "ok",
+	})
+	assert.Nil(t, err)
+
+	clusterIDs, err := mgr.ListClusterIDsWithCollected(ctx)
+	assert.Nil(t, err)
+	assert.Contains(t, clusterIDs, clusterID)
+
+	pipelineruns, err := mgr.ListCollectedByClusterID(ctx, clusterID, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(pipelineruns))
+	assert.Equal(t, "pr/1", pipelineruns[0].PrObject)
+	assert.Equal(t, "pr/0", pipelineruns[1].PrObject)
+
+	assert.Nil(t, mgr.ClearCollectedByID(ctx, pipelineruns[0].ID))
+	pr, err := mgr.GetByID(ctx, pipelineruns[0].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "", pr.LogObject)
+	assert.Equal(t, "", pr.PrObject)
+	assert.Equal(t, "bucket", pr.S3Bucket)
+	pipelineruns, err = mgr.ListCollectedByClusterID(ctx, clusterID, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(pipelineruns))
+}
+
 func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&models.Pipelinerun{}); err != nil {
 		panic(err)
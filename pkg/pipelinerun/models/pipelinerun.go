@@ -28,14 +28,16 @@ const (
 type PipelineStatus string
 
 const (
-	StatusCreated   PipelineStatus = "created"
-	StatusCommitted PipelineStatus = "committed"
-	StatusMerged    PipelineStatus = "merged"
-	StatusDeployed  PipelineStatus = "deployed"
-	StatusOK        PipelineStatus = "ok"
-	StatusFailed    PipelineStatus = "failed"
-	StatusCancelled PipelineStatus = "cancelled"
-	StatusUnknown   PipelineStatus = "unknown"
+	// StatusPendingApproval means the pipelinerun in protected environment is waiting for approval
+	StatusPendingApproval PipelineStatus = "pending_approval"
+	StatusCreated         PipelineStatus = "created"
+	StatusCommitted       PipelineStatus = "committed"
+	StatusMerged          PipelineStatus = "merged"
+	StatusDeployed        PipelineStatus = "deployed"
+	StatusOK              PipelineStatus = "ok"
+	StatusFailed          PipelineStatus = "failed"
+	StatusCancelled       PipelineStatus = "cancelled"
+	StatusUnknown         PipelineStatus = "unknown"
 )
 
 type Pipelinerun struct {
@@ -45,7 +47,7 @@ type Pipelinerun struct {
 	ClusterID uint
 	// Action type, which can be builddeploy, deploy, restart, rollback
 	Action string
-	// Status of this pipelinerun, which can be created, ok, failed, cancelled, unknown
+	// Status of this pipelinerun, which can be pending_approval, created, ok, failed, cancelled, unknown
 	Status string
 	// Title of this pipelinerun
 	Title string
@@ -17,36 +17,87 @@ package rbac
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	"github.com/horizoncd/horizon/pkg/auth"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	membermanager "github.com/horizoncd/horizon/pkg/member"
 	"github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmanager "github.com/horizoncd/horizon/pkg/pipelinerun/manager"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/sets"
+	"github.com/horizoncd/horizon/pkg/visibility"
 )
 
 // Authorizer use the basic rbac rules to check if the user
 // have the permissions
+//
+//go:generate mockgen -source=$GOFILE -destination=../../mock/pkg/rbac/auth_mock.go -package=mock_rbac
 type Authorizer interface {
 	Authorize(ctx context.Context, attributes auth.Attributes) (auth.Decision, string, error)
 }
 
 type VisitorFunc func(fmt.Stringer, *types.PolicyRule, error) bool
 
-func NewAuthorizer(roleservice role.Service, memberservice memberservice.Service) Authorizer {
+func NewAuthorizer(roleservice role.Service, memberservice memberservice.Service,
+	manager *managerparam.Manager) Authorizer {
 	return &authorizer{
-		roleService:   roleservice,
-		memberService: memberservice,
+		roleService:        roleservice,
+		memberService:      memberservice,
+		memberManager:      manager.MemberManager,
+		tokenManager:       manager.TokenManager,
+		groupManager:       manager.GroupManager,
+		applicationManager: manager.ApplicationManager,
+		clusterManager:     manager.ClusterMgr,
+		pipelinerunManager: manager.PipelinerunMgr,
+		visibilityChecker:  manager.VisibilityChecker,
 	}
 }
 
 type authorizer struct {
-	roleService   role.Service
-	memberService memberservice.Service
+	roleService        role.Service
+	memberService      memberservice.Service
+	memberManager      membermanager.Manager
+	tokenManager       tokenmanager.Manager
+	groupManager       groupmanager.Manager
+	applicationManager applicationmanager.Manager
+	clusterManager     clustermanager.Manager
+	pipelinerunManager prmanager.Manager
+
+	visibilityChecker visibility.Checker
 }
 
+const (
+	resourceMembers              = "members"
+	resourceEnvironments         = "environments"
+	resourceUsers                = "users"
+	resourcePersonalAccessTokens = "personalaccesstokens"
+	resourceAccessTokens         = "accesstokens"
+	resourceRoles                = "roles"
+	resourceScopes               = "scopes"
+
+	verbDelete = "delete"
+	verbCreate = "create"
+
+	nameSelf = "self"
+)
+
+// tokenUnscopedResources are resources which tokens restricted to resources are allowed to read
+// by requests not scoped by id, since they never list groups, applications, clusters or pipelineruns
+var tokenUnscopedResources = sets.NewString(resourceEnvironments, common.ResourceRegion,
+	resourceRoles, resourceScopes, common.ResourceTemplate)
+
 const (
 	NotChecked        = "not checked"
 	ResourceFormatErr = "format error"
@@ -55,27 +106,57 @@ const (
 	MemberNotExist    = "member not exist"
 	RoleNotExist      = "role not exist"
 	AdminAllow        = "admin allows everything"
+	AdminOnly         = "only admin is allowed"
+	ReadOnlyAllow     = "read only requests are allowed"
+	MemberLeaveAllow  = "members are allowed to leave"
+	TokenOwnerAllow   = "owner of token is allowed"
+	TokenOwnerOnly    = "only owner of token is allowed"
+	PrivateResource   = "private resource is only allowed for members"
+	TokenRestricted   = "resource is out of restrictions of token"
 )
 
 func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.Decision,
 	string, error) {
-	// 0. check (admin allows everything, and some are not checked)
+	// 0. check (restrictions of token apply to admin as well, admin allows everything, and some are not checked)
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
 		return auth.DecisionDeny, AnonymousUser, nil
 	}
+	if restrictions, ok := common.TokenRestrictionsFromContext(ctx); ok && len(restrictions.Resources) > 0 {
+		within, err := a.withinTokenResources(ctx, attr, restrictions.Resources)
+		if err != nil {
+			return auth.DecisionDeny, InternalError, err
+		}
+		if !within {
+			return auth.DecisionDeny, TokenRestricted, nil
+		}
+	}
 	if currentUser.IsAdmin() {
 		return auth.DecisionAllow, AdminAllow, nil
 	}
+	// read only requests not scoped by id, like list and search, are filtered by the visibility of resources
+	if attr.GetName() == "" && attr.IsReadOnly() {
+		return auth.DecisionAllow, ReadOnlyAllow, nil
+	}
 
-	// TODO(tom): members and pipelineruns and environments need to add to auth check
-	if attr.IsResourceRequest() && (attr.GetResource() == "members" ||
-		attr.GetResource() == "environments" || attr.GetResource() == "users" ||
-		attr.GetResource() == "personalaccesstokens" ||
-		(attr.GetResource() == "accesstokens" && attr.GetVerb() == "delete")) {
-		log.Warning(ctx,
-			"members|environments|access tokens are not authed yet")
-		return auth.DecisionAllow, NotChecked, nil
+	// members, environments, users and tokens have no member bindings of their own
+	if attr.IsResourceRequest() {
+		switch attr.GetResource() {
+		case resourceMembers:
+			return a.authorizeMember(ctx, attr)
+		case resourceEnvironments, resourceUsers:
+			// environments and users are managed by admins
+			if attr.IsReadOnly() {
+				return auth.DecisionAllow, ReadOnlyAllow, nil
+			}
+			return auth.DecisionDeny, AdminOnly, nil
+		case resourcePersonalAccessTokens:
+			return a.authorizePersonalAccessToken(ctx, attr)
+		case resourceAccessTokens:
+			if attr.GetVerb() == verbDelete {
+				return a.authorizeResourceAccessToken(ctx, attr)
+			}
+		}
 	}
 
 	// 1. get the member
@@ -90,14 +171,21 @@ func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.
 	// 2. get the role
 	var role *types.Role
 	if member == nil {
-		// TODO(tom): non public resources
-		// now default resource are public, if there is a default role, non member can choose the default role
+		// if there is a default role, non member of public resources can choose the default role
 		defaultRole := a.roleService.GetDefaultRole(ctx)
 		if defaultRole == nil {
 			log.Warningf(ctx, " user %s member and role not found of resourceType = %s, resourceID = %s",
 				attr.GetUser().String(), attr.GetResource(), attr.GetName())
 			return auth.DecisionDeny, MemberNotExist, nil
 		}
+		resourceID, _ := strconv.ParseUint(resourceIDStr, 10, 0)
+		private, err := a.visibilityChecker.IsPrivate(ctx, attr.GetResource(), uint(resourceID))
+		if err != nil {
+			return auth.DecisionDeny, InternalError, err
+		}
+		if private {
+			return auth.DecisionDeny, PrivateResource, nil
+		}
 		log.WithFiled(ctx, "user",
 			attr.GetUser().String()).Debugf(" use the default role %s", defaultRole.Name)
 		role = defaultRole
@@ -117,6 +205,35 @@ func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.
 	return VisitRoles(member, role, attr)
 }
 
+// AuthorizeAction checks if the current user could perform the action on the resource, the action is
+// a subresource requested by POST, such as clusters/deploy. It's for the actions which are triggered
+// indirectly, such as by a release pipeline or a job, so that they are not checked by the middleware.
+func AuthorizeAction(ctx context.Context, authorizer Authorizer,
+	resource string, resourceID uint, action string) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	decision, reason, err := authorizer.Authorize(ctx, auth.AttributesRecord{
+		User:            currentUser,
+		Verb:            verbCreate,
+		APIGroup:        common.GroupCore,
+		APIVersion:      "v2",
+		Resource:        resource,
+		SubResource:     action,
+		Name:            strconv.FormatUint(uint64(resourceID), 10),
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return err
+	}
+	if decision != auth.DecisionAllow {
+		return perror.Wrapf(herrors.ErrForbidden, "user %s is not allowed to %s %s %d, reason: %s",
+			currentUser.GetName(), action, resource, resourceID, reason)
+	}
+	return nil
+}
+
 func VisitRoles(member *models.Member, role *types.Role,
 	attr auth.Attributes) (_ auth.Decision, reason string, err error) {
 	var memberInfo string
@@ -135,3 +252,185 @@ func VisitRoles(member *models.Member, role *types.Role,
 	reason = fmt.Sprintf("user %s denied by member(%s)", attr.GetUser().String(), memberInfo)
 	return auth.DecisionDeny, reason, nil
 }
+
+// authorizeMember checks the change of member against the role of the resource which the member binds to,
+// the current user must be allowed to operate members of the resource and have an equal or higher role
+func (a *authorizer) authorizeMember(ctx context.Context, attr auth.Attributes) (auth.Decision, string, error) {
+	memberID, err := strconv.ParseUint(attr.GetName(), 10, 0)
+	if err != nil {
+		return auth.DecisionDeny, ResourceFormatErr, nil
+	}
+	member, err := a.memberService.GetMember(ctx, uint(memberID))
+	if err != nil {
+		return auth.DecisionDeny, InternalError, err
+	}
+	if member == nil {
+		return auth.DecisionDeny, MemberNotExist, nil
+	}
+
+	if attr.GetVerb() == verbDelete && member.MemberType == models.MemberUser &&
+		member.MemberNameID == attr.GetUser().GetID() {
+		return auth.DecisionAllow, MemberLeaveAllow, nil
+	}
+
+	decision, reason, err := a.Authorize(ctx, auth.AttributesRecord{
+		User:            attr.GetUser(),
+		Verb:            attr.GetVerb(),
+		APIGroup:        attr.GetAPIGroup(),
+		APIVersion:      attr.GetAPIVersion(),
+		Resource:        string(member.ResourceType),
+		SubResource:     resourceMembers,
+		Name:            strconv.FormatUint(uint64(member.ResourceID), 10),
+		Scope:           attr.GetScope(),
+		ResourceRequest: true,
+		Path:            attr.GetPath(),
+	})
+	if err != nil || decision == auth.DecisionDeny {
+		return decision, reason, err
+	}
+
+	if err := a.memberService.RequirePermissionEqualOrHigher(ctx, member.Role,
+		string(member.ResourceType), member.ResourceID); err != nil {
+		if perror.Cause(err) == herrors.ErrNoPrivilege {
+			return auth.DecisionDeny, fmt.Sprintf("user %s has a lower role than member(%s)",
+				attr.GetUser().String(), member.BaseInfo()), nil
+		}
+		return auth.DecisionDeny, InternalError, err
+	}
+	return auth.DecisionAllow, reason, nil
+}
+
+// authorizePersonalAccessToken allows users to create and list their own tokens,
+// and only the owner could operate a specified token
+func (a *authorizer) authorizePersonalAccessToken(ctx context.Context,
+	attr auth.Attributes) (auth.Decision, string, error) {
+	if attr.GetName() == "" {
+		return auth.DecisionAllow, TokenOwnerAllow, nil
+	}
+	tokenID, err := strconv.ParseUint(attr.GetName(), 10, 0)
+	if err != nil {
+		return auth.DecisionDeny, ResourceFormatErr, nil
+	}
+	token, err := a.tokenManager.LoadTokenByID(ctx, uint(tokenID))
+	if err != nil {
+		return auth.DecisionDeny, InternalError, err
+	}
+	if token.UserID != attr.GetUser().GetID() {
+		return auth.DecisionDeny, TokenOwnerOnly, nil
+	}
+	return auth.DecisionAllow, TokenOwnerAllow, nil
+}
+
+// authorizeResourceAccessToken only allows owners of all the resources which the token's robot binds to
+func (a *authorizer) authorizeResourceAccessToken(ctx context.Context,
+	attr auth.Attributes) (auth.Decision, string, error) {
+	tokenID, err := strconv.ParseUint(attr.GetName(), 10, 0)
+	if err != nil {
+		return auth.DecisionDeny, ResourceFormatErr, nil
+	}
+	token, err := a.tokenManager.LoadTokenByID(ctx, uint(tokenID))
+	if err != nil {
+		return auth.DecisionDeny, InternalError, err
+	}
+	members, err := a.memberManager.ListMembersByUserID(ctx, token.UserID)
+	if err != nil {
+		return auth.DecisionDeny, InternalError, err
+	}
+	if len(members) == 0 {
+		return auth.DecisionDeny, MemberNotExist, nil
+	}
+	for _, member := range members {
+		if err := a.memberService.RequirePermissionEqualOrHigher(ctx, role.Owner,
+			string(member.ResourceType), member.ResourceID); err != nil {
+			if perror.Cause(err) == herrors.ErrNoPrivilege {
+				return auth.DecisionDeny, TokenOwnerOnly, nil
+			}
+			return auth.DecisionDeny, InternalError, err
+		}
+	}
+	return auth.DecisionAllow, TokenOwnerAllow, nil
+}
+
+// withinTokenResources checks if the requested resource is one of the resources which the token is restricted to,
+// or under them. Requests of resources out of groups, applications, clusters and pipelineruns are read only,
+// and requests not scoped by id are denied unless they read tokenUnscopedResources or the current user.
+func (a *authorizer) withinTokenResources(ctx context.Context, attr auth.Attributes,
+	resources []string) (bool, error) {
+	if !attr.IsResourceRequest() {
+		return false, nil
+	}
+	resourceID, err := strconv.ParseUint(attr.GetName(), 10, 0)
+	if err != nil {
+		return attr.IsReadOnly() && (tokenUnscopedResources.Has(attr.GetResource()) ||
+			(attr.GetResource() == resourceUsers && attr.GetName() == nameSelf)), nil
+	}
+	ancestors, err := a.resourceAncestors(ctx, attr.GetResource(), uint(resourceID))
+	if err != nil {
+		return false, err
+	}
+	if ancestors == nil {
+		return attr.IsReadOnly(), nil
+	}
+	for _, resource := range resources {
+		resourceType, id, err := tokenmodels.ParseResource(resource)
+		if err != nil {
+			continue
+		}
+		if ancestors.Has(formatResource(resourceType, id)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resourceAncestors returns the resource and all the resources above it,
+// or nil if the resource is out of groups, applications, clusters and pipelineruns
+func (a *authorizer) resourceAncestors(ctx context.Context, resourceType string,
+	resourceID uint) (sets.String, error) {
+	ancestors := sets.NewString()
+	switch resourceType {
+	case common.ResourcePipelinerun:
+		pipelinerun, err := a.pipelinerunManager.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		if pipelinerun == nil {
+			return nil, herrors.NewErrNotFound(herrors.PipelinerunInDB,
+				fmt.Sprintf("pipelinerun %d does not exist", resourceID))
+		}
+		ancestors.Insert(formatResource(common.ResourcePipelinerun, resourceID))
+		resourceID = pipelinerun.ClusterID
+		fallthrough
+	case common.ResourceCluster:
+		cluster, err := a.clusterManager.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		ancestors.Insert(formatResource(common.ResourceCluster, resourceID))
+		resourceID = cluster.ApplicationID
+		fallthrough
+	case common.ResourceApplication:
+		application, err := a.applicationManager.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		ancestors.Insert(formatResource(common.ResourceApplication, resourceID))
+		resourceID = application.GroupID
+		fallthrough
+	case common.ResourceGroup:
+		group, err := a.groupManager.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs) {
+			ancestors.Insert(formatResource(common.ResourceGroup, id))
+		}
+	default:
+		return nil, nil
+	}
+	return ancestors, nil
+}
+
+func formatResource(resourceType string, resourceID uint) string {
+	return fmt.Sprintf("%s/%d", resourceType, resourceID)
+}
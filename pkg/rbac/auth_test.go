@@ -17,21 +17,39 @@ package rbac
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strconv"
 	"testing"
 
+	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
 	servicemock "github.com/horizoncd/horizon/mock/pkg/member/service"
 	rolemock "github.com/horizoncd/horizon/mock/pkg/rbac/role"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	"github.com/horizoncd/horizon/pkg/auth"
 	"github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	usergroupmodels "github.com/horizoncd/horizon/pkg/usergroup/models"
+	"github.com/horizoncd/horizon/pkg/visibility"
 )
 
-// members and pipelineruns are allowed
 var (
 	defaultUser = &user.DefaultInfo{
 		Name:     "tom",
@@ -64,8 +82,8 @@ func TestAuthMember(t *testing.T) {
 	ctx = context.WithValue(ctx, common.UserContextKey(), defaultUser)
 	decision, reason, err := testAuthorizer.Authorize(ctx, authRecord)
 	assert.Nil(t, err)
-	assert.Equal(t, auth.DecisionAllow, decision)
-	assert.Equal(t, NotChecked, reason)
+	assert.Equal(t, auth.DecisionDeny, decision)
+	assert.Equal(t, ResourceFormatErr, reason)
 
 	authRecord = auth.AttributesRecord{
 		User:            defaultUser,
@@ -146,3 +164,278 @@ func TestAuthRole(t *testing.T) {
 	assert.Equal(t, auth.DecisionDeny, decision)
 	assert.Nil(t, err)
 }
+
+// nolint
+func TestAuthorizeMatrix(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.Member{}, &groupmodels.Group{}, &appmodels.Application{}, &usermodels.User{},
+		&tokenmodels.Token{}, &usergroupmodels.UserGroup{}, &usergroupmodels.UserGroupMember{}))
+	manager := managerparam.InitManager(db)
+	rolesFile, err := os.Open("../../roles.yaml")
+	assert.Nil(t, err)
+	defer rolesFile.Close()
+	roleService, err := role.NewFileRole(context.Background(), rolesFile)
+	assert.Nil(t, err)
+	memberService := memberservice.NewService(roleService, nil, manager)
+	testAuthorizer := NewAuthorizer(roleService, memberService, manager)
+
+	adminCtx := common.WithContext(context.Background(), &user.DefaultInfo{Name: "admin", ID: 1000, Admin: true})
+	group, err := manager.GroupManager.Create(adminCtx, &groupmodels.Group{Name: "group", Path: "group"})
+	assert.Nil(t, err)
+	privateSubGroup, err := manager.GroupManager.Create(adminCtx, &groupmodels.Group{Name: "private-sub",
+		Path: "private-sub", ParentID: group.ID, VisibilityLevel: visibility.Private})
+	assert.Nil(t, err)
+	privateGroup, err := manager.GroupManager.Create(adminCtx, &groupmodels.Group{Name: "private",
+		Path: "private", VisibilityLevel: visibility.Private})
+	assert.Nil(t, err)
+
+	users := map[string]*usermodels.User{}
+	for _, name := range []string{role.PE, role.Owner, role.Maintainer, "tagger", role.Guest,
+		"outsider", "target", "robot"} {
+		u, err := manager.UserManager.Create(adminCtx, &usermodels.User{Name: name})
+		assert.Nil(t, err)
+		users[name] = u
+	}
+	bind := func(name, roleName string) *models.Member {
+		member, err := manager.MemberManager.Create(adminCtx, &models.Member{
+			ResourceType: models.TypeGroup,
+			ResourceID:   group.ID,
+			Role:         roleName,
+			MemberType:   models.MemberUser,
+			MemberNameID: users[name].ID,
+		})
+		assert.Nil(t, err)
+		return member
+	}
+	memberOf := map[string]*models.Member{}
+	for _, name := range []string{role.PE, role.Owner, role.Maintainer, "tagger", role.Guest} {
+		memberOf[name] = bind(name, name)
+	}
+	targetMember := bind("target", role.Maintainer)
+	bind("robot", role.Maintainer)
+
+	personalToken, err := manager.TokenManager.CreateToken(adminCtx, &tokenmodels.Token{
+		Name: "personal", Code: "personal", UserID: users[role.Maintainer].ID})
+	assert.Nil(t, err)
+	robotToken, err := manager.TokenManager.CreateToken(adminCtx, &tokenmodels.Token{
+		Name: "robot", Code: "robot", UserID: users["robot"].ID})
+	assert.Nil(t, err)
+
+	record := func(verb, resource, name string) auth.AttributesRecord {
+		return auth.AttributesRecord{
+			Verb:            verb,
+			APIGroup:        common.GroupCore,
+			APIVersion:      "v2",
+			Resource:        resource,
+			Name:            name,
+			ResourceRequest: true,
+		}
+	}
+	id := func(id uint) string { return strconv.Itoa(int(id)) }
+
+	cases := []struct {
+		name   string
+		record auth.AttributesRecord
+		// allowed lists the users allowed, the others are denied
+		allowed []string
+	}{
+		{
+			name:    "update member",
+			record:  record("update", "members", id(targetMember.ID)),
+			allowed: []string{role.PE, role.Owner, role.Maintainer},
+		},
+		{
+			name:    "delete member",
+			record:  record("delete", "members", id(targetMember.ID)),
+			allowed: []string{role.Owner},
+		},
+		{
+			name:    "update member of higher role",
+			record:  record("update", "members", id(memberOf[role.Owner].ID)),
+			allowed: []string{role.PE, role.Owner},
+		},
+		{
+			name:    "leave resource",
+			record:  record("delete", "members", id(memberOf[role.Guest].ID)),
+			allowed: []string{role.Owner, role.Guest},
+		},
+		{
+			name:    "get environment",
+			record:  record("get", "environments", "1"),
+			allowed: []string{role.PE, role.Owner, role.Maintainer, "tagger", role.Guest, "outsider"},
+		},
+		{
+			name:   "create environment",
+			record: record("create", "environments", ""),
+		},
+		{
+			name:   "update environment",
+			record: record("update", "environments", "1"),
+		},
+		{
+			name:   "delete environment",
+			record: record("delete", "environments", "1"),
+		},
+		{
+			name:    "get user",
+			record:  record("get", "users", id(users["target"].ID)),
+			allowed: []string{role.PE, role.Owner, role.Maintainer, "tagger", role.Guest, "outsider"},
+		},
+		{
+			name:   "update user",
+			record: record("update", "users", id(users["target"].ID)),
+		},
+		{
+			name:    "create personal access token",
+			record:  record("create", "personalaccesstokens", ""),
+			allowed: []string{role.PE, role.Owner, role.Maintainer, "tagger", role.Guest, "outsider"},
+		},
+		{
+			name:    "revoke personal access token",
+			record:  record("delete", "personalaccesstokens", id(personalToken.ID)),
+			allowed: []string{role.Maintainer},
+		},
+		{
+			name:    "get public group",
+			record:  record("get", "groups", id(group.ID)),
+			allowed: []string{role.PE, role.Owner, role.Maintainer, role.Guest, "outsider"},
+		},
+		{
+			name:    "get private group inheriting members",
+			record:  record("get", "groups", id(privateSubGroup.ID)),
+			allowed: []string{role.PE, role.Owner, role.Maintainer, role.Guest},
+		},
+		{
+			name:   "get private group without members",
+			record: record("get", "groups", id(privateGroup.ID)),
+		},
+		{
+			name:    "revoke resource access token",
+			record:  record("delete", "accesstokens", id(robotToken.ID)),
+			allowed: []string{role.PE, role.Owner},
+		},
+	}
+
+	for _, c := range cases {
+		allowed := map[string]bool{}
+		for _, name := range c.allowed {
+			allowed[name] = true
+		}
+		for _, name := range []string{role.PE, role.Owner, role.Maintainer, "tagger", role.Guest, "outsider"} {
+			u := &user.DefaultInfo{Name: name, ID: users[name].ID}
+			r := c.record
+			r.User = u
+			decision, reason, err := testAuthorizer.Authorize(common.WithContext(context.Background(), u), r)
+			assert.Nil(t, err, "%s by %s", c.name, name)
+			if allowed[name] {
+				assert.Equal(t, auth.DecisionAllow, decision, "%s by %s: %s", c.name, name, reason)
+			} else {
+				assert.Equal(t, auth.DecisionDeny, decision, "%s by %s: %s", c.name, name, reason)
+			}
+		}
+
+		// admins are allowed to do everything
+		admin := &user.DefaultInfo{Name: "admin", ID: 1000, Admin: true}
+		r := c.record
+		r.User = admin
+		decision, _, err := testAuthorizer.Authorize(common.WithContext(context.Background(), admin), r)
+		assert.Nil(t, err)
+		assert.Equal(t, auth.DecisionAllow, decision, "%s by admin", c.name)
+	}
+}
+
+func TestAuthorizeTokenRestrictions(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.Member{}, &groupmodels.Group{}, &appmodels.Application{},
+		&clustermodels.Cluster{}, &prmodels.Pipelinerun{}, &usermodels.User{}, &tokenmodels.Token{}))
+	manager := managerparam.InitManager(db)
+	rolesFile, err := os.Open("../../roles.yaml")
+	assert.Nil(t, err)
+	defer rolesFile.Close()
+	roleService, err := role.NewFileRole(context.Background(), rolesFile)
+	assert.Nil(t, err)
+	memberService := memberservice.NewService(roleService, nil, manager)
+	testAuthorizer := NewAuthorizer(roleService, memberService, manager)
+
+	admin := &user.DefaultInfo{Name: "admin", ID: 1000, Admin: true}
+	adminCtx := common.WithContext(context.Background(), admin)
+	group, err := manager.GroupManager.Create(adminCtx, &groupmodels.Group{Name: "group", Path: "group"})
+	assert.Nil(t, err)
+	app, err := manager.ApplicationManager.Create(adminCtx, &appmodels.Application{Name: "app", GroupID: group.ID},
+		nil)
+	assert.Nil(t, err)
+	otherApp, err := manager.ApplicationManager.Create(adminCtx, &appmodels.Application{Name: "other",
+		GroupID: group.ID}, nil)
+	assert.Nil(t, err)
+	cluster, err := manager.ClusterMgr.Create(adminCtx, &clustermodels.Cluster{Name: "cluster",
+		ApplicationID: app.ID}, nil, nil)
+	assert.Nil(t, err)
+	pipelinerun, err := manager.PipelinerunMgr.Create(adminCtx, &prmodels.Pipelinerun{ClusterID: cluster.ID})
+	assert.Nil(t, err)
+
+	record := func(verb, resource string, id uint) auth.AttributesRecord {
+		return auth.AttributesRecord{
+			User:            admin,
+			Verb:            verb,
+			APIGroup:        common.GroupCore,
+			APIVersion:      "v2",
+			Resource:        resource,
+			Name:            strconv.Itoa(int(id)),
+			ResourceRequest: true,
+		}
+	}
+	unscoped := func(verb, resource, name string) auth.AttributesRecord {
+		r := record(verb, resource, 0)
+		r.Name = name
+		return r
+	}
+	cases := []struct {
+		name    string
+		record  auth.AttributesRecord
+		allowed bool
+	}{
+		{"update application", record("update", common.ResourceApplication, app.ID), true},
+		{"update cluster of application", record("update", common.ResourceCluster, cluster.ID), true},
+		{"get pipelinerun of application", record("get", common.ResourcePipelinerun, pipelinerun.ID), true},
+		{"update other application", record("update", common.ResourceApplication, otherApp.ID), false},
+		{"get group of application", record("get", common.ResourceGroup, group.ID), false},
+		{"get template", record("get", "templates", 1), true},
+		{"update template", record("update", "templates", 1), false},
+		{"list applications", unscoped("get", common.ResourceApplication, ""), false},
+		{"search clusters", unscoped("get", "searchclusters", ""), false},
+		{"create application", unscoped("create", common.ResourceApplication, ""), false},
+		{"list templates", unscoped("get", common.ResourceTemplate, ""), true},
+		{"list environments", unscoped("get", "environments", ""), true},
+		{"get current user", unscoped("get", "users", "self"), true},
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	common.SetUser(c, admin)
+	common.SetTokenRestrictions(c, &tokenmodels.Restrictions{
+		Resources: []string{fmt.Sprintf("%s/%d", common.ResourceApplication, app.ID)},
+	})
+	for _, tc := range cases {
+		decision, reason, err := testAuthorizer.Authorize(c, tc.record)
+		assert.Nil(t, err, tc.name)
+		if tc.allowed {
+			assert.Equal(t, auth.DecisionAllow, decision, "%s: %s", tc.name, reason)
+		} else {
+			assert.Equal(t, auth.DecisionDeny, decision, "%s: %s", tc.name, reason)
+			assert.Equal(t, TokenRestricted, reason, tc.name)
+		}
+	}
+
+	// resources not found are reported
+	_, _, err = testAuthorizer.Authorize(c, record("get", common.ResourcePipelinerun, pipelinerun.ID+100))
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	_, _, err = testAuthorizer.Authorize(c, record("get", common.ResourceCluster, cluster.ID+100))
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// tokens without restrictions of resources are not affected
+	common.SetTokenRestrictions(c, &tokenmodels.Restrictions{Verbs: []string{"deploy"}})
+	decision, _, err := testAuthorizer.Authorize(c, record("update", common.ResourceApplication, otherApp.ID))
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionAllow, decision)
+}
@@ -0,0 +1,95 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/releasepipeline/models"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	// Create creates a release pipeline
+	Create(ctx context.Context, pipeline *models.ReleasePipeline) (*models.ReleasePipeline, error)
+	// GetByID gets a release pipeline by id
+	GetByID(ctx context.Context, id uint) (*models.ReleasePipeline, error)
+	// ListByApplicationID lists release pipelines of application
+	ListByApplicationID(ctx context.Context, applicationID uint) ([]*models.ReleasePipeline, error)
+	// UpdateByID updates name, description and stages of the release pipeline
+	UpdateByID(ctx context.Context, id uint, pipeline *models.ReleasePipeline) error
+	// DeleteByID deletes a release pipeline by id
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, pipeline *models.ReleasePipeline) (*models.ReleasePipeline, error) {
+	result := d.db.WithContext(ctx).Create(pipeline)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ReleasePipelineInDB, result.Error.Error())
+	}
+	return pipeline, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.ReleasePipeline, error) {
+	var pipeline models.ReleasePipeline
+	result := d.db.WithContext(ctx).Where("id = ?", id).First(&pipeline)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.ReleasePipelineInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.ReleasePipelineInDB, result.Error.Error())
+	}
+	return &pipeline, nil
+}
+
+func (d *dao) ListByApplicationID(ctx context.Context, applicationID uint) ([]*models.ReleasePipeline, error) {
+	var pipelines []*models.ReleasePipeline
+	result := d.db.WithContext(ctx).Where("application_id = ?", applicationID).
+		Order("id asc").Find(&pipelines)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ReleasePipelineInDB, result.Error.Error())
+	}
+	return pipelines, nil
+}
+
+func (d *dao) UpdateByID(ctx context.Context, id uint, pipeline *models.ReleasePipeline) error {
+	result := d.db.WithContext(ctx).Model(&models.ReleasePipeline{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"name":        pipeline.Name,
+			"description": pipeline.Description,
+			"stages":      pipeline.Stages,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ReleasePipelineInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteByID(ctx context.Context, id uint) error {
+	result := d.db.WithContext(ctx).Where("id = ?", id).Delete(&models.ReleasePipeline{})
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.ReleasePipelineInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/releasepipeline/dao"
+	"github.com/horizoncd/horizon/pkg/releasepipeline/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/releasepipeline/manager/manager.go -package=mock_manager
+type Manager interface {
+	// Create creates a release pipeline
+	Create(ctx context.Context, pipeline *models.ReleasePipeline) (*models.ReleasePipeline, error)
+	// GetByID gets a release pipeline by id
+	GetByID(ctx context.Context, id uint) (*models.ReleasePipeline, error)
+	// ListByApplicationID lists release pipelines of application
+	ListByApplicationID(ctx context.Context, applicationID uint) ([]*models.ReleasePipeline, error)
+	// UpdateByID updates name, description and stages of the release pipeline
+	UpdateByID(ctx context.Context, id uint, pipeline *models.ReleasePipeline) error
+	// DeleteByID deletes a release pipeline by id
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context, pipeline *models.ReleasePipeline) (*models.ReleasePipeline, error) {
+	return m.dao.Create(ctx, pipeline)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.ReleasePipeline, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) ListByApplicationID(ctx context.Context,
+	applicationID uint) ([]*models.ReleasePipeline, error) {
+	return m.dao.ListByApplicationID(ctx, applicationID)
+}
+
+func (m *manager) UpdateByID(ctx context.Context, id uint, pipeline *models.ReleasePipeline) error {
+	return m.dao.UpdateByID(ctx, id, pipeline)
+}
+
+func (m *manager) DeleteByID(ctx context.Context, id uint) error {
+	return m.dao.DeleteByID(ctx, id)
+}
@@ -0,0 +1,87 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/releasepipeline/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.ReleasePipeline{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	stages := models.Stages{
+		{ClusterID: 1, HealthGate: true},
+		{ClusterID: 2},
+	}
+	pipeline, err := mgr.Create(ctx, &models.ReleasePipeline{
+		ApplicationID: 1,
+		Name:          "release",
+		Stages:        stages,
+	})
+	assert.Nil(t, err)
+	_, err = mgr.Create(ctx, &models.ReleasePipeline{
+		ApplicationID: 2,
+		Name:          "release",
+	})
+	assert.Nil(t, err)
+
+	ret, err := mgr.GetByID(ctx, pipeline.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, stages, ret.Stages)
+
+	pipelines, err := mgr.ListByApplicationID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pipelines))
+	assert.Equal(t, pipeline.ID, pipelines[0].ID)
+
+	stages = append(stages, &models.Stage{ClusterID: 3})
+	err = mgr.UpdateByID(ctx, pipeline.ID, &models.ReleasePipeline{
+		Name:        "release-v2",
+		Description: "test -> pre -> online",
+		Stages:      stages,
+	})
+	assert.Nil(t, err)
+	ret, err = mgr.GetByID(ctx, pipeline.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "release-v2", ret.Name)
+	assert.Equal(t, "test -> pre -> online", ret.Description)
+	assert.Equal(t, 3, len(ret.Stages))
+
+	err = mgr.DeleteByID(ctx, pipeline.ID)
+	assert.Nil(t, err)
+	_, err = mgr.GetByID(ctx, pipeline.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,76 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ReleasePipeline is an ordered list of clusters of an application,
+// images and configs are promoted from one stage to the next.
+type ReleasePipeline struct {
+	ID            uint
+	ApplicationID uint
+	Name          string
+	Description   string
+	Stages        Stages `gorm:"type:text"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CreatedBy     uint
+	UpdatedBy     uint
+}
+
+// Stage is a cluster in release pipeline
+type Stage struct {
+	ClusterID uint `json:"clusterID"`
+	// HealthGate means the cluster must be healthy before being promoted to the next stage
+	HealthGate bool `json:"healthGate"`
+}
+
+type Stages []*Stage
+
+func (s *Stages) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan stages: %v", value)
+	}
+	if len(bytes) == 0 {
+		*s = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s Stages) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return "", nil
+	}
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(bytes), nil
+}
@@ -0,0 +1,112 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/scheduledeploy/models"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	// Create creates a scheduled deploy
+	Create(ctx context.Context, scheduledDeploy *models.ScheduledDeploy) (*models.ScheduledDeploy, error)
+	// GetByID gets a scheduled deploy by id
+	GetByID(ctx context.Context, id uint) (*models.ScheduledDeploy, error)
+	// ListByClusterID lists scheduled deploys of cluster, the latest scheduled first
+	ListByClusterID(ctx context.Context, clusterID uint) ([]*models.ScheduledDeploy, error)
+	// ListDue lists pending scheduled deploys whose scheduled time is before now
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledDeploy, error)
+	// UpdateStatus updates status of the scheduled deploy only if its status is fromStatus,
+	// returns false if the status has been changed by others
+	UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string) (bool, error)
+	// UpdateResult updates the result of an executed scheduled deploy
+	UpdateResult(ctx context.Context, id uint, status string, pipelinerunID uint, message string) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context,
+	scheduledDeploy *models.ScheduledDeploy) (*models.ScheduledDeploy, error) {
+	result := d.db.WithContext(ctx).Create(scheduledDeploy)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ScheduledDeployInDB, result.Error.Error())
+	}
+	return scheduledDeploy, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.ScheduledDeploy, error) {
+	var scheduledDeploy models.ScheduledDeploy
+	result := d.db.WithContext(ctx).Where("id = ?", id).First(&scheduledDeploy)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.ScheduledDeployInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.ScheduledDeployInDB, result.Error.Error())
+	}
+	return &scheduledDeploy, nil
+}
+
+func (d *dao) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.ScheduledDeploy, error) {
+	var scheduledDeploys []*models.ScheduledDeploy
+	result := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Order("scheduled_at desc, id desc").Find(&scheduledDeploys)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ScheduledDeployInDB, result.Error.Error())
+	}
+	return scheduledDeploys, nil
+}
+
+func (d *dao) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledDeploy, error) {
+	var scheduledDeploys []*models.ScheduledDeploy
+	result := d.db.WithContext(ctx).Where("status = ? and scheduled_at <= ?", models.StatusPending, now).
+		Order("scheduled_at asc, id asc").Limit(limit).Find(&scheduledDeploys)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ScheduledDeployInDB, result.Error.Error())
+	}
+	return scheduledDeploys, nil
+}
+
+func (d *dao) UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&models.ScheduledDeploy{}).
+		Where("id = ? and status = ?", id, fromStatus).Update("status", toStatus)
+	if result.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.ScheduledDeployInDB, result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (d *dao) UpdateResult(ctx context.Context, id uint, status string,
+	pipelinerunID uint, message string) error {
+	result := d.db.WithContext(ctx).Model(&models.ScheduledDeploy{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"pipelinerun_id": pipelinerunID,
+			"message":        message,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ScheduledDeployInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/scheduledeploy/dao"
+	"github.com/horizoncd/horizon/pkg/scheduledeploy/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/scheduledeploy/manager/manager.go -package=mock_manager
+type Manager interface {
+	// Create creates a scheduled deploy
+	Create(ctx context.Context, scheduledDeploy *models.ScheduledDeploy) (*models.ScheduledDeploy, error)
+	// GetByID gets a scheduled deploy by id
+	GetByID(ctx context.Context, id uint) (*models.ScheduledDeploy, error)
+	// ListByClusterID lists scheduled deploys of cluster, the latest scheduled first
+	ListByClusterID(ctx context.Context, clusterID uint) ([]*models.ScheduledDeploy, error)
+	// ListDue lists pending scheduled deploys whose scheduled time is before now
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledDeploy, error)
+	// UpdateStatus updates status of the scheduled deploy only if its status is fromStatus,
+	// returns false if the status has been changed by others
+	UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string) (bool, error)
+	// UpdateResult updates the result of an executed scheduled deploy
+	UpdateResult(ctx context.Context, id uint, status string, pipelinerunID uint, message string) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context,
+	scheduledDeploy *models.ScheduledDeploy) (*models.ScheduledDeploy, error) {
+	return m.dao.Create(ctx, scheduledDeploy)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.ScheduledDeploy, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.ScheduledDeploy, error) {
+	return m.dao.ListByClusterID(ctx, clusterID)
+}
+
+func (m *manager) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledDeploy, error) {
+	return m.dao.ListDue(ctx, now, limit)
+}
+
+func (m *manager) UpdateStatus(ctx context.Context, id uint, fromStatus, toStatus string) (bool, error) {
+	return m.dao.UpdateStatus(ctx, id, fromStatus, toStatus)
+}
+
+func (m *manager) UpdateResult(ctx context.Context, id uint, status string,
+	pipelinerunID uint, message string) error {
+	return m.dao.UpdateResult(ctx, id, status, pipelinerunID, message)
+}
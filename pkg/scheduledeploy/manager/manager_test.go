@@ -0,0 +1,88 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/scheduledeploy/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.ScheduledDeploy{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	now := time.Now()
+	due, err := mgr.Create(ctx, &models.ScheduledDeploy{
+		ClusterID:   1,
+		Action:      models.ActionRestart,
+		ScheduledAt: now.Add(-time.Minute),
+		Status:      models.StatusPending,
+	})
+	assert.Nil(t, err)
+	_, err = mgr.Create(ctx, &models.ScheduledDeploy{
+		ClusterID:   1,
+		Action:      models.ActionDeploy,
+		ScheduledAt: now.Add(time.Hour),
+		Status:      models.StatusPending,
+	})
+	assert.Nil(t, err)
+
+	ret, err := mgr.ListByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ret))
+	assert.Equal(t, models.ActionDeploy, ret[0].Action)
+
+	ret, err = mgr.ListDue(ctx, now, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ret))
+	assert.Equal(t, due.ID, ret[0].ID)
+
+	updated, err := mgr.UpdateStatus(ctx, due.ID, models.StatusPending, models.StatusRunning)
+	assert.Nil(t, err)
+	assert.True(t, updated)
+	updated, err = mgr.UpdateStatus(ctx, due.ID, models.StatusPending, models.StatusCancelled)
+	assert.Nil(t, err)
+	assert.False(t, updated)
+
+	ret, err = mgr.ListDue(ctx, now, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ret))
+
+	assert.Nil(t, mgr.UpdateResult(ctx, due.ID, models.StatusSucceeded, 2, ""))
+	scheduledDeploy, err := mgr.GetByID(ctx, due.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusSucceeded, scheduledDeploy.Status)
+	assert.Equal(t, uint(2), scheduledDeploy.PipelinerunID)
+
+	_, err = mgr.GetByID(ctx, 100)
+	assert.NotNil(t, err)
+}
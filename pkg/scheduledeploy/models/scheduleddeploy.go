@@ -0,0 +1,51 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	ActionBuildDeploy = "builddeploy"
+	ActionDeploy      = "deploy"
+	ActionRestart     = "restart"
+
+	// StatusPending means the deploy is waiting for the scheduled time
+	StatusPending = "pending"
+	// StatusRunning means the deploy is being executed by the job
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// ScheduledDeploy is a deploy or restart of cluster which is executed at ScheduledAt.
+type ScheduledDeploy struct {
+	ID        uint
+	ClusterID uint
+	// Action is one of builddeploy, deploy and restart
+	Action string
+	// Request is the json of request body of the action
+	Request     string
+	ScheduledAt time.Time
+	Status      string
+	// PipelinerunID is the pipelinerun created by the execution
+	PipelinerunID uint
+	// Message is the failure reason
+	Message   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+	UpdatedBy uint
+}
@@ -171,6 +171,9 @@ func (d dao) UpdateByID(ctx context.Context, templateID uint, template *models.T
 		if template.Repository != "" {
 			oldTemplate.Repository = template.Repository
 		}
+		if template.SourceKind != "" {
+			oldTemplate.SourceKind = template.SourceKind
+		}
 		if template.Description != "" {
 			oldTemplate.Description = template.Description
 		}
@@ -18,6 +18,17 @@ import (
 	"github.com/horizoncd/horizon/pkg/server/global"
 )
 
+// kinds of template source, which releases are pulled from, empty kind means SourceKindGit
+const (
+	// SourceKindGit pulls releases from tags of git repository, it's the default kind
+	SourceKindGit = "git"
+	// SourceKindOCI pulls releases from OCI registry, such as oci://harbor.example.com/charts/javaapp
+	SourceKindOCI = "oci"
+	// SourceKindHelm pulls releases from helm repository with index.yaml,
+	// such as https://charts.example.com/stable/javaapp
+	SourceKindHelm = "helm"
+)
+
 type Template struct {
 	global.Model
 
@@ -26,6 +37,7 @@ type Template struct {
 	ChartName   string
 	Description string
 	Repository  string
+	SourceKind  string
 	GroupID     uint
 	OnlyOwner   *bool
 	WithoutCI   bool
@@ -0,0 +1,294 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	// migration file path in chart
+	_migrationsPath = "migrations.yaml"
+
+	// OpRename moves value from `from` to `path`
+	OpRename = "rename"
+	// OpDefault sets value to `path` if it is absent
+	OpDefault = "default"
+	// OpSet sets value to `path` anyway
+	OpSet = "set"
+	// OpRemove removes value of `path`
+	OpRemove = "remove"
+
+	_pathSeparator = "."
+)
+
+// Migrations declares how to migrate values of clusters to the template release which ships it,
+// for example:
+//
+//	migrations:
+//	- fromReleases: [v1.0.0, v1.1.0]
+//	  application:
+//	  - op: rename
+//	    from: app.spec.replica
+//	    path: app.spec.replicas
+//	  - op: default
+//	    path: app.health.port
+//	    value: 8080
+//	  pipeline:
+//	  - op: remove
+//	    path: buildInfo.buildxml
+type Migrations struct {
+	Migrations []*Migration `json:"migrations"`
+}
+
+type Migration struct {
+	// FromReleases are names of releases which the migration applies to, empty means all releases
+	FromReleases []string     `json:"fromReleases"`
+	Application  []*Operation `json:"application"`
+	Pipeline     []*Operation `json:"pipeline"`
+}
+
+type Operation struct {
+	Op    string      `json:"op"`
+	From  string      `json:"from,omitempty"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Getter provides migrations shipped in template release
+//
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/templaterelease/migration/mock_migration.go -package=mock_migration
+type Getter interface {
+	// GetMigrations gets migrations of the specified template release, empty migrations is returned if absent
+	GetMigrations(ctx context.Context, templateName, releaseName string) (*Migrations, error)
+}
+
+type getter struct {
+	templateRepo       templaterepo.TemplateRepo
+	templateReleaseMgr trmanager.Manager
+}
+
+func NewGetter(repo templaterepo.TemplateRepo, manager *managerparam.Manager) Getter {
+	return &getter{
+		templateRepo:       repo,
+		templateReleaseMgr: manager.TemplateReleaseManager,
+	}
+}
+
+func (g *getter) GetMigrations(ctx context.Context, templateName, releaseName string) (*Migrations, error) {
+	const op = "template migration getter: get migrations"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	tr, err := g.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, templateName, releaseName)
+	if err != nil {
+		return nil, err
+	}
+	chart, err := g.templateRepo.GetChart(tr.ChartName, tr.ChartVersion, tr.LastSyncAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range chart.Files {
+		if file.Name == _migrationsPath {
+			return Parse(file.Data)
+		}
+	}
+	return &Migrations{}, nil
+}
+
+// Parse parses and validates content of migrations file
+func Parse(content []byte) (*Migrations, error) {
+	var migrations Migrations
+	if err := yaml.Unmarshal(content, &migrations); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"failed to unmarshal %s: %v", _migrationsPath, err)
+	}
+	for _, migration := range migrations.Migrations {
+		for _, operation := range append(migration.Application, migration.Pipeline...) {
+			if err := operation.validate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &migrations, nil
+}
+
+func (o *Operation) validate() error {
+	if o.Path == "" {
+		return perror.Wrapf(herrors.ErrParamInvalid, "path of %s operation is empty", o.Op)
+	}
+	switch o.Op {
+	case OpRename:
+		if o.From == "" {
+			return perror.Wrapf(herrors.ErrParamInvalid, "from of rename operation on %s is empty", o.Path)
+		}
+	case OpDefault, OpSet, OpRemove:
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "unsupported operation %s on %s", o.Op, o.Path)
+	}
+	return nil
+}
+
+// Apply applies migrations matching fromRelease to application and pipeline values,
+// the values passed in are not modified
+func (m *Migrations) Apply(fromRelease string, application,
+	pipeline map[string]interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	application, pipeline = deepCopy(application), deepCopy(pipeline)
+	for _, migration := range m.Migrations {
+		if !migration.match(fromRelease) {
+			continue
+		}
+		var err error
+		if application, err = applyOperations(application, migration.Application); err != nil {
+			return nil, nil, err
+		}
+		if pipeline, err = applyOperations(pipeline, migration.Pipeline); err != nil {
+			return nil, nil, err
+		}
+	}
+	return application, pipeline, nil
+}
+
+func (m *Migration) match(release string) bool {
+	if len(m.FromReleases) == 0 {
+		return true
+	}
+	for _, r := range m.FromReleases {
+		if r == release {
+			return true
+		}
+	}
+	return false
+}
+
+func applyOperations(values map[string]interface{}, operations []*Operation) (map[string]interface{}, error) {
+	if len(operations) > 0 && values == nil {
+		values = make(map[string]interface{})
+	}
+	for _, operation := range operations {
+		path := strings.Split(operation.Path, _pathSeparator)
+		switch operation.Op {
+		case OpRename:
+			from := strings.Split(operation.From, _pathSeparator)
+			value, ok := lookup(values, from)
+			if !ok {
+				continue
+			}
+			remove(values, from)
+			if err := set(values, path, value); err != nil {
+				return nil, err
+			}
+		case OpDefault:
+			if _, ok := lookup(values, path); ok {
+				continue
+			}
+			if err := set(values, path, deepCopyValue(operation.Value)); err != nil {
+				return nil, err
+			}
+		case OpSet:
+			if err := set(values, path, deepCopyValue(operation.Value)); err != nil {
+				return nil, err
+			}
+		case OpRemove:
+			remove(values, path)
+		default:
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported operation %s", operation.Op)
+		}
+	}
+	return values, nil
+}
+
+func lookup(values map[string]interface{}, path []string) (interface{}, bool) {
+	current := values
+	for i, key := range path {
+		value, ok := current[key]
+		if !ok {
+			return nil, false
+		}
+		if i == len(path)-1 {
+			return value, true
+		}
+		if current, ok = value.(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+func set(values map[string]interface{}, path []string, value interface{}) error {
+	current := values
+	for i, key := range path[:len(path)-1] {
+		next, ok := current[key]
+		if !ok || next == nil {
+			next = make(map[string]interface{})
+			current[key] = next
+		}
+		if current, ok = next.(map[string]interface{}); !ok {
+			return perror.Wrapf(herrors.ErrParamInvalid, "value of %s is not an object",
+				strings.Join(path[:i+1], _pathSeparator))
+		}
+	}
+	current[path[len(path)-1]] = value
+	return nil
+}
+
+func remove(values map[string]interface{}, path []string) {
+	parent := values
+	if len(path) > 1 {
+		value, ok := lookup(values, path[:len(path)-1])
+		if !ok {
+			return
+		}
+		if parent, ok = value.(map[string]interface{}); !ok {
+			return
+		}
+	}
+	delete(parent, path[len(path)-1])
+}
+
+func deepCopy(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	return deepCopyValue(values).(map[string]interface{})
+}
+
+func deepCopyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = deepCopyValue(value)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, value := range v {
+			s[i] = deepCopyValue(value)
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	trmock "github.com/horizoncd/horizon/mock/pkg/templaterelease/manager"
+	repomock "github.com/horizoncd/horizon/mock/pkg/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+)
+
+const migrationsYAML = `migrations:
+- fromReleases: [v1.0.0]
+  application:
+  - op: rename
+    from: app.spec.replica
+    path: app.spec.replicas
+  - op: remove
+    path: app.legacy
+- application:
+  - op: default
+    path: app.health.port
+    value: 8080
+  - op: set
+    path: app.version
+    value: v2
+  pipeline:
+  - op: rename
+    from: buildxml
+    path: buildInfo.buildxml
+`
+
+func TestGetMigrations(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	repo := repomock.NewMockTemplateRepo(mockCtl)
+	templateReleaseMgr := trmock.NewMockManager(mockCtl)
+	g := &getter{
+		templateRepo:       repo,
+		templateReleaseMgr: templateReleaseMgr,
+	}
+
+	now := time.Now()
+	templateReleaseMgr.EXPECT().GetByTemplateNameAndRelease(gomock.Any(), "javaapp", "v2.0.0").
+		Return(&trmodels.TemplateRelease{ChartName: "javaapp", ChartVersion: "v2.0.0-5e5193b3",
+			LastSyncAt: now}, nil).Times(2)
+	repo.EXPECT().GetChart("javaapp", "v2.0.0-5e5193b3", now).Return(&chart.Chart{
+		Files: []*chart.File{{Name: _migrationsPath, Data: []byte(migrationsYAML)}},
+	}, nil)
+	repo.EXPECT().GetChart("javaapp", "v2.0.0-5e5193b3", now).Return(&chart.Chart{}, nil)
+
+	migrations, err := g.GetMigrations(context.TODO(), "javaapp", "v2.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(migrations.Migrations))
+
+	migrations, err = g.GetMigrations(context.TODO(), "javaapp", "v2.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(migrations.Migrations))
+}
+
+func TestApply(t *testing.T) {
+	migrations, err := Parse([]byte(migrationsYAML))
+	assert.Nil(t, err)
+
+	application := map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec":   map[string]interface{}{"replica": 2},
+			"legacy": true,
+			"health": map[string]interface{}{"port": 9090},
+		},
+	}
+	pipeline := map[string]interface{}{"buildxml": "<project/>"}
+
+	newApplication, newPipeline, err := migrations.Apply("v1.0.0", application, pipeline)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec":    map[string]interface{}{"replicas": 2},
+			"health":  map[string]interface{}{"port": 9090},
+			"version": "v2",
+		},
+	}, newApplication)
+	assert.Equal(t, map[string]interface{}{
+		"buildInfo": map[string]interface{}{"buildxml": "<project/>"},
+	}, newPipeline)
+	// values passed in are not modified
+	assert.Equal(t, true, application["app"].(map[string]interface{})["legacy"])
+	assert.Equal(t, "<project/>", pipeline["buildxml"])
+
+	// migration of v1.0.0 is skipped
+	newApplication, _, err = migrations.Apply("v1.1.0", map[string]interface{}{
+		"app": map[string]interface{}{"spec": map[string]interface{}{"replica": 2}},
+	}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec":    map[string]interface{}{"replica": 2},
+			"health":  map[string]interface{}{"port": float64(8080)},
+			"version": "v2",
+		},
+	}, newApplication)
+
+	_, _, err = migrations.Apply("v1.1.0", map[string]interface{}{"app": "javaapp"}, nil)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	_, err = Parse([]byte("migrations:\n- application:\n  - op: move\n    path: app\n"))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
@@ -39,6 +39,7 @@ type TemplateRelease struct {
 	LastSyncAt   time.Time
 	FailedReason string
 	CommitID     string
+	Digest       string
 	CreatedBy    uint
 	UpdatedBy    uint
 }
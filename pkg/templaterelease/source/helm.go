@@ -0,0 +1,174 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo/oci"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _indexFileName = "index.yaml"
+
+// indexFile is the index.yaml of helm repository, only fields used are declared
+type indexFile struct {
+	Entries map[string][]*chartVersion `json:"entries"`
+}
+
+type chartVersion struct {
+	Version string   `json:"version"`
+	Digest  string   `json:"digest"`
+	URLs    []string `json:"urls"`
+}
+
+// getHelmArchive pulls chart from repository like https://charts.example.com/stable/javaapp,
+// which means chart javaapp in the helm repository https://charts.example.com/stable
+func (g *getter) getHelmArchive(ctx context.Context, repository, version string) (*Archive, error) {
+	const op = "template source: get helm archive"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	base, entry, err := g.getHelmIndexEntry(ctx, repository, version)
+	if err != nil {
+		return nil, err
+	}
+	if len(entry.URLs) == 0 {
+		return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"no url of %s-%s in index", path.Base(repository), version)
+	}
+	link, err := base.Parse(entry.URLs[0])
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid chart url %s: %v", entry.URLs[0], err)
+	}
+	data, err := g.getHelmFile(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	digest := oci.Digest(data)
+	if entry.Digest != "" && normalizeDigest(entry.Digest) != digest {
+		return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"digest of %s mismatch, expected %s", link, entry.Digest)
+	}
+	return &Archive{
+		Digest: digest,
+		Data:   data,
+	}, nil
+}
+
+func (g *getter) getHelmDigest(ctx context.Context, repository, version string) (string, error) {
+	_, entry, err := g.getHelmIndexEntry(ctx, repository, version)
+	if err != nil {
+		return "", err
+	}
+	if entry.Digest != "" {
+		return normalizeDigest(entry.Digest), nil
+	}
+	// digest is optional in index
+	archive, err := g.getHelmArchive(ctx, repository, version)
+	if err != nil {
+		return "", err
+	}
+	return archive.Digest, nil
+}
+
+// getHelmIndexEntry returns url of the helm repository and the chart version in its index
+func (g *getter) getHelmIndexEntry(ctx context.Context,
+	repository, version string) (*url.URL, *chartVersion, error) {
+	u, err := url.Parse(strings.TrimSuffix(repository, "/"))
+	if err != nil || u.Host == "" {
+		return nil, nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid helm repository %s", repository)
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return nil, nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"chart name is absent in helm repository %s", repository)
+	}
+	// trailing slash makes relative urls in index resolved under the repository
+	base := *u
+	base.Path = path.Dir(u.Path) + "/"
+	if base.Path == "//" {
+		base.Path = "/"
+	}
+
+	link, _ := base.Parse(_indexFileName)
+	content, err := g.getHelmFile(ctx, link)
+	if err != nil {
+		return nil, nil, err
+	}
+	var index indexFile
+	if err := yaml.Unmarshal(content, &index); err != nil {
+		return nil, nil, perror.Wrapf(herrors.ErrParamInvalid, "could not unmarshal index of %s: %v", link, err)
+	}
+	for _, entry := range index.Entries[name] {
+		if entry.Version == version {
+			return &base, entry, nil
+		}
+	}
+	reason := fmt.Sprintf("chart %s-%s not found in %s", name, version, link)
+	return nil, nil, perror.Wrap(herrors.NewErrNotFound(herrors.TemplateReleaseInRepo, reason), reason)
+}
+
+func (g *getter) getHelmFile(ctx context.Context, link *url.URL) ([]byte, error) {
+	source := g.source(link.Host)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link.String(), nil)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to create request: %v", err)
+	}
+	if source.Username != "" {
+		req.SetBasicAuth(source.Username, source.Password)
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: g.tlsConfig(source),
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to send request: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		reason := fmt.Sprintf("%s not found", link)
+		return nil, perror.Wrap(herrors.NewErrNotFound(herrors.TemplateReleaseInRepo, reason), reason)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrReadFailed, "failed to read response: %v", err)
+	}
+	return content, nil
+}
+
+// normalizeDigest prefixes hex digest in index with sha256:
+func normalizeDigest(digest string) string {
+	if strings.HasPrefix(digest, _digestPrefix) {
+		return digest
+	}
+	return _digestPrefix + digest
+}
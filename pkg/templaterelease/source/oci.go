@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo/oci"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// getOCIArchive pulls chart from repository like oci://harbor.example.com/charts/javaapp,
+// version is the tag of the chart
+func (g *getter) getOCIArchive(ctx context.Context, repository, version string) (*Archive, error) {
+	const op = "template source: get oci archive"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	client, path, err := g.ociClient(repository)
+	if err != nil {
+		return nil, err
+	}
+	data, digest, err := oci.PullChart(ctx, client, path, oci.Tag(version))
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{
+		Digest: digest,
+		Data:   data,
+	}, nil
+}
+
+func (g *getter) getOCIDigest(ctx context.Context, repository, version string) (string, error) {
+	client, path, err := g.ociClient(repository)
+	if err != nil {
+		return "", err
+	}
+	return client.ManifestDigest(ctx, path, oci.Tag(version))
+}
+
+// ociClient returns client of the registry and path of the repository
+func (g *getter) ociClient(repository string) (*oci.Client, string, error) {
+	repository = strings.TrimPrefix(repository, oci.Scheme)
+	i := strings.Index(repository, "/")
+	if i == -1 || i == len(repository)-1 {
+		return nil, "", perror.Wrapf(herrors.ErrParamInvalid, "invalid oci repository %s", repository)
+	}
+	host, path := repository[:i], strings.Trim(repository[i+1:], "/")
+
+	g.m.Lock()
+	defer g.m.Unlock()
+	if client, ok := g.ociClients[host]; ok {
+		return client, path, nil
+	}
+	source := g.source(host)
+	address := source.Host
+	if hostOf(address) != host {
+		address = host
+	}
+	client, err := oci.NewClient(address, source.Username, source.Password, g.tlsConfig(source))
+	if err != nil {
+		return nil, "", err
+	}
+	g.ociClients[host] = client
+	return client, path, nil
+}
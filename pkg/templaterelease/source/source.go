@@ -0,0 +1,130 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"crypto/tls"
+	"net/url"
+	"strings"
+	"sync"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/template/models"
+	"github.com/horizoncd/horizon/pkg/templaterepo/oci"
+)
+
+const (
+	_digestPrefix    = "sha256:"
+	_shortDigestSize = 8
+)
+
+// Archive is a chart archive pulled from source
+type Archive struct {
+	// Digest identifies the content of archive, such as sha256:<hex>
+	Digest string
+	Data   []byte
+}
+
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/templaterelease/source/mock_source.go -package=mock_source
+type Getter interface {
+	// GetArchive pulls the chart archive of version from repository of the kind
+	GetArchive(ctx context.Context, kind, repository, version string) (*Archive, error)
+	// GetDigest gets digest of the chart archive of version, the archive is not downloaded if possible
+	GetDigest(ctx context.Context, kind, repository, version string) (string, error)
+}
+
+type getter struct {
+	sources map[string]*config.Source
+
+	ociClients map[string]*oci.Client
+	m          sync.Mutex
+}
+
+var _ Getter = (*getter)(nil)
+
+// NewGetter creates a getter to pull charts from OCI registries and helm repositories,
+// credentials of sources are matched by host.
+func NewGetter(sources []*config.Source) Getter {
+	g := &getter{
+		sources:    make(map[string]*config.Source),
+		ociClients: make(map[string]*oci.Client),
+	}
+	for _, source := range sources {
+		g.sources[hostOf(source.Host)] = source
+	}
+	return g
+}
+
+func (g *getter) GetArchive(ctx context.Context, kind, repository, version string) (*Archive, error) {
+	switch kind {
+	case models.SourceKindOCI:
+		return g.getOCIArchive(ctx, repository, version)
+	case models.SourceKindHelm:
+		return g.getHelmArchive(ctx, repository, version)
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported source kind %s", kind)
+	}
+}
+
+func (g *getter) GetDigest(ctx context.Context, kind, repository, version string) (string, error) {
+	switch kind {
+	case models.SourceKindOCI:
+		return g.getOCIDigest(ctx, repository, version)
+	case models.SourceKindHelm:
+		return g.getHelmDigest(ctx, repository, version)
+	default:
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "unsupported source kind %s", kind)
+	}
+}
+
+// source returns the configured source of host, or an anonymous one
+func (g *getter) source(host string) *config.Source {
+	if source, ok := g.sources[host]; ok {
+		return source
+	}
+	return &config.Source{Host: host}
+}
+
+func (g *getter) tlsConfig(source *config.Source) *tls.Config {
+	return &tls.Config{InsecureSkipVerify: source.Insecure} // nolint:gosec
+}
+
+// ShortDigest returns the first 8 hex characters of sha256 digest, which is used in chart version,
+// other digest such as commit id is returned as it is
+func ShortDigest(digest string) string {
+	if !strings.HasPrefix(digest, _digestPrefix) {
+		return digest
+	}
+	digest = strings.TrimPrefix(digest, _digestPrefix)
+	if len(digest) > _shortDigestSize {
+		return digest[:_shortDigestSize]
+	}
+	return digest
+}
+
+// hostOf returns host of url like https://harbor.example.com or harbor.example.com
+func hostOf(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return u.Host
+}
@@ -0,0 +1,149 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/template/models"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/templaterepo/oci"
+	"github.com/horizoncd/horizon/pkg/templaterepo/oci/mockserver"
+)
+
+func chartArchive(t *testing.T, version string) []byte {
+	var buf bytes.Buffer
+	err := templaterepo.ChartSerialize(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "javaapp", Version: version},
+	}, &buf)
+	assert.Nil(t, err)
+	return buf.Bytes()
+}
+
+func TestOCI(t *testing.T) {
+	server := mockserver.NewRegistryServer()
+	server.Username, server.Password = "horizon", "password"
+	s := httptest.NewServer(server.R)
+	defer s.Close()
+	host := s.Listener.Addr().String()
+
+	// push chart like helm push
+	repo, err := oci.NewRepo(config.Repo{Host: s.URL, Username: "horizon", Password: "password", RepoName: "charts"})
+	assert.Nil(t, err)
+	assert.Nil(t, repo.UploadChart(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "javaapp", Version: "v1.0.0"},
+	}))
+
+	g := NewGetter([]*config.Source{{Host: s.URL, Username: "horizon", Password: "password"}})
+	ctx := context.Background()
+	repository := fmt.Sprintf("oci://%s/charts/javaapp", host)
+	archive, err := g.GetArchive(ctx, models.SourceKindOCI, repository, "v1.0.0")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(archive.Digest, "sha256:"))
+	assert.NotEmpty(t, archive.Data)
+
+	digest, err := g.GetDigest(ctx, models.SourceKindOCI, repository, "v1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, archive.Digest, digest)
+
+	_, err = g.GetDigest(ctx, models.SourceKindOCI, repository, "v2.0.0")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// credential is required
+	_, err = NewGetter(nil).GetDigest(ctx, models.SourceKindOCI, repository, "v1.0.0")
+	assert.NotNil(t, err)
+}
+
+func TestHelm(t *testing.T) {
+	v1, v2 := chartArchive(t, "1.0.0"), chartArchive(t, "2.0.0")
+	index := fmt.Sprintf(`apiVersion: v1
+entries:
+  javaapp:
+  - version: 2.0.0
+    urls:
+    - charts/javaapp-2.0.0.tgz
+  - version: 1.0.0
+    digest: %s
+    urls:
+    - charts/javaapp-1.0.0.tgz
+`, strings.TrimPrefix(oci.Digest(v1), "sha256:"))
+	files := map[string][]byte{
+		"/stable/index.yaml":               []byte(index),
+		"/stable/charts/javaapp-1.0.0.tgz": v1,
+		"/stable/charts/javaapp-2.0.0.tgz": v2,
+		"/absolute/index.yaml":             []byte(strings.ReplaceAll(index, "charts/", "/stable/charts/")),
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "horizon" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		content, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer s.Close()
+
+	g := NewGetter([]*config.Source{{Host: s.URL, Username: "horizon", Password: "password"}})
+	ctx := context.Background()
+	repository := s.URL + "/stable/javaapp"
+
+	// digest in index
+	digest, err := g.GetDigest(ctx, models.SourceKindHelm, repository, "1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, oci.Digest(v1), digest)
+	archive, err := g.GetArchive(ctx, models.SourceKindHelm, repository, "1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, digest, archive.Digest)
+	assert.Equal(t, v1, archive.Data)
+
+	// digest is computed if absent in index
+	digest, err = g.GetDigest(ctx, models.SourceKindHelm, repository, "2.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, oci.Digest(v2), digest)
+
+	// absolute path in index
+	archive, err = g.GetArchive(ctx, models.SourceKindHelm, s.URL+"/absolute/javaapp", "2.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, v2, archive.Data)
+
+	_, err = g.GetArchive(ctx, models.SourceKindHelm, repository, "3.0.0")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	_, err = g.GetArchive(ctx, models.SourceKindGit, repository, "1.0.0")
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
+
+func TestShortDigest(t *testing.T) {
+	assert.Equal(t, "5e5193b3", ShortDigest("sha256:5e5193b3c0b8e4e8"))
+	assert.Equal(t, "33da3204", ShortDigest("33da3204"))
+}
@@ -0,0 +1,393 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// media types of helm chart stored as OCI artifact
+const (
+	ManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	ChartConfigMediaType = "application/vnd.cncf.helm.config.v1+json"
+	ChartLayerMediaType  = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+)
+
+const (
+	_headerContentDigest   = "Docker-Content-Digest"
+	_headerWWWAuthenticate = "WWW-Authenticate"
+	_headerLocation        = "Location"
+	_schemeBearer          = "bearer"
+)
+
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type Manifest struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	MediaType     string        `json:"mediaType,omitempty"`
+	Config        Descriptor    `json:"config"`
+	Layers        []*Descriptor `json:"layers"`
+}
+
+// Client talks to a registry by OCI distribution API, it supports both basic auth
+// and bearer token issued by the token service of registry
+type Client struct {
+	base     *url.URL
+	username string
+	password string
+	client   *http.Client
+
+	// tokens caches bearer token by repository
+	tokens map[string]string
+	m      sync.Mutex
+}
+
+// NewClient creates a client of registry at host, https is used if the scheme of host is absent
+func NewClient(host, username, password string, tlsConfig *tls.Config) (*Client, error) {
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	base, err := url.Parse(host)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "url is incorrect: %v", err)
+	}
+	return &Client{
+		base:     &url.URL{Scheme: base.Scheme, Host: base.Host},
+		username: username,
+		password: password,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+		tokens: make(map[string]string),
+	}, nil
+}
+
+// Host returns host of the registry
+func (c *Client) Host() string {
+	return c.base.Host
+}
+
+// Digest computes digest of the content
+func Digest(content []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
+
+// ManifestDigest resolves the digest of the manifest referenced by tag or digest
+func (c *Client) ManifestDigest(ctx context.Context, repository, reference string) (string, error) {
+	resp, err := c.do(ctx, http.MethodHead, c.link(repository, "manifests", reference), nil,
+		http.Header{"Accept": []string{ManifestMediaType}})
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := checkResponse(ctx, resp, repository, reference, http.StatusOK); err != nil {
+		return "", err
+	}
+	if digest := resp.Header.Get(_headerContentDigest); digest != "" {
+		return digest, nil
+	}
+
+	// registry is not obliged to return the digest for HEAD request
+	_, digest, err := c.GetManifest(ctx, repository, reference)
+	return digest, err
+}
+
+// GetManifest gets the manifest referenced by tag or digest, with its digest
+func (c *Client) GetManifest(ctx context.Context, repository, reference string) (*Manifest, string, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.link(repository, "manifests", reference), nil,
+		http.Header{"Accept": []string{ManifestMediaType}})
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := checkResponse(ctx, resp, repository, reference, http.StatusOK); err != nil {
+		return nil, "", err
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", perror.Wrapf(herrors.ErrReadFailed, "failed to read response: %v", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, "", perror.Wrapf(herrors.ErrParamInvalid, "could not unmarshal manifest: %v", err)
+	}
+	digest := resp.Header.Get(_headerContentDigest)
+	if digest == "" {
+		digest = Digest(content)
+	}
+	return &manifest, digest, nil
+}
+
+// GetBlob downloads the blob and verifies its digest
+func (c *Client) GetBlob(ctx context.Context, repository, digest string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.link(repository, "blobs", digest), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := checkResponse(ctx, resp, repository, digest, http.StatusOK); err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrReadFailed, "failed to read response: %v", err)
+	}
+	if Digest(content) != digest {
+		return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"digest of blob mismatch, expected %s", digest)
+	}
+	return content, nil
+}
+
+// PushBlob uploads the content monolithically if it does not exist yet
+func (c *Client) PushBlob(ctx context.Context, repository string, content []byte) (*Descriptor, error) {
+	desc := &Descriptor{
+		Digest: Digest(content),
+		Size:   int64(len(content)),
+	}
+	resp, err := c.do(ctx, http.MethodHead, c.link(repository, "blobs", desc.Digest), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return desc, nil
+	}
+
+	resp, err = c.do(ctx, http.MethodPost, c.link(repository, "blobs", "uploads/"), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if err := checkResponse(ctx, resp, repository, desc.Digest, http.StatusAccepted); err != nil {
+		return nil, err
+	}
+	location, err := c.base.Parse(resp.Header.Get(_headerLocation))
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "invalid upload location: %v", err)
+	}
+	query := location.Query()
+	query.Set("digest", desc.Digest)
+	location.RawQuery = query.Encode()
+
+	resp, err = c.do(ctx, http.MethodPut, location.String(), content,
+		http.Header{"Content-Type": []string{"application/octet-stream"}})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := checkResponse(ctx, resp, repository, desc.Digest, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
+// PutManifest uploads the manifest with tag, returns digest of the manifest
+func (c *Client) PutManifest(ctx context.Context, repository, tag string, manifest *Manifest) (string, error) {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "could not marshal manifest: %v", err)
+	}
+	resp, err := c.do(ctx, http.MethodPut, c.link(repository, "manifests", tag), content,
+		http.Header{"Content-Type": []string{manifest.MediaType}})
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := checkResponse(ctx, resp, repository, tag, http.StatusCreated); err != nil {
+		return "", err
+	}
+	return Digest(content), nil
+}
+
+// DeleteManifest deletes the manifest referenced by digest
+func (c *Client) DeleteManifest(ctx context.Context, repository, digest string) error {
+	resp, err := c.do(ctx, http.MethodDelete, c.link(repository, "manifests", digest), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return checkResponse(ctx, resp, repository, digest, http.StatusAccepted)
+}
+
+func (c *Client) link(repository, kind, reference string) string {
+	return fmt.Sprintf("%s/v2/%s/%s/%s", c.base.String(), strings.Trim(repository, "/"), kind, reference)
+}
+
+func checkResponse(ctx context.Context, resp *http.Response, repository, reference string, expected int) error {
+	if resp.StatusCode == expected {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		reason := fmt.Sprintf("%s:%s not found", repository, reference)
+		return perror.Wrap(herrors.NewErrNotFound(herrors.TemplateReleaseInRepo, reason), reason)
+	}
+	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+}
+
+// do sends the request, if the registry challenges for bearer token,
+// requests a token from the token service and resends the request with it
+func (c *Client) do(ctx context.Context, method, link string,
+	body []byte, header http.Header) (*http.Response, error) {
+	resp, err := c.send(ctx, method, link, body, header, c.cachedToken(link))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	scheme, params := parseChallenge(resp.Header.Get(_headerWWWAuthenticate))
+	if scheme != _schemeBearer {
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	token, err := c.fetchToken(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	c.m.Lock()
+	c.tokens[repositoryOfLink(link)] = token
+	c.m.Unlock()
+	return c.send(ctx, method, link, body, header, token)
+}
+
+func (c *Client) send(ctx context.Context, method, link string,
+	body []byte, header http.Header, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, bytes.NewReader(body))
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to create request: %v", err)
+	}
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed, "failed to send request: %v", err)
+	}
+	return resp, nil
+}
+
+func (c *Client) cachedToken(link string) string {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.tokens[repositoryOfLink(link)]
+}
+
+func (c *Client) fetchToken(ctx context.Context, params map[string]string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "invalid realm of challenge: %s", params["realm"])
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	resp, err := c.send(ctx, http.MethodGet, realm.String(), nil, nil, "")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "could not unmarshal token: %v", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+// repositoryOfLink returns the repository part of link like <host>/v2/<repository>/manifests/<reference>,
+// tokens are scoped by repository
+func repositoryOfLink(link string) string {
+	for _, kind := range []string{"/manifests/", "/blobs/"} {
+		if i := strings.LastIndex(link, kind); i != -1 {
+			return link[:i]
+		}
+	}
+	return link
+}
+
+// parseChallenge parses header like: Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(header string) (string, map[string]string) {
+	params := make(map[string]string)
+	header = strings.TrimSpace(header)
+	i := strings.Index(header, " ")
+	if i == -1 {
+		return strings.ToLower(header), params
+	}
+	scheme, rest := header[:i], header[i+1:]
+	for {
+		rest = strings.TrimLeft(rest, ", ")
+		eq := strings.Index(rest, "=")
+		if eq == -1 {
+			break
+		}
+		key, value := strings.ToLower(strings.TrimSpace(rest[:eq])), ""
+		rest = rest[eq+1:]
+		if strings.HasPrefix(rest, `"`) {
+			// quoted value may contain comma, such as scope="repository:foo:pull,push"
+			end := strings.Index(rest[1:], `"`)
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			value, rest = rest[1:end+1], rest[end+1:]
+			rest = strings.TrimPrefix(rest, `"`)
+		} else if comma := strings.Index(rest, ","); comma != -1 {
+			value, rest = rest[:comma], rest[comma:]
+		} else {
+			value, rest = rest, ""
+		}
+		params[key] = value
+	}
+	return strings.ToLower(scheme), params
+}
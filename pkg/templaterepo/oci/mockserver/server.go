@@ -0,0 +1,236 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mockserver
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	Token = "mock-token"
+
+	_mediaTypeManifest = "application/vnd.oci.image.manifest.v1+json"
+)
+
+// RegistryServer is an in-memory OCI registry, which requires bearer token issued by /token
+type RegistryServer struct {
+	R *mux.Router
+	// Username and Password are required by token service if not empty
+	Username string
+	Password string
+	// Manifests maps repository to tags and digests to manifests
+	Manifests map[string]map[string][]byte
+	// Blobs maps digest to blob
+	Blobs map[string][]byte
+	// TokenRequests is the number of tokens issued
+	TokenRequests int
+
+	m sync.Mutex
+}
+
+func NewRegistryServer() *RegistryServer {
+	r := mux.NewRouter()
+	s := &RegistryServer{
+		R:         r,
+		Manifests: map[string]map[string][]byte{},
+		Blobs:     map[string][]byte{},
+	}
+	r.Path("/token").Methods(http.MethodGet).HandlerFunc(s.IssueToken)
+	v2 := r.PathPrefix("/v2").Subrouter()
+	v2.Use(s.authenticate)
+	v2.Path("/{name:.+}/manifests/{reference}").Methods(http.MethodGet, http.MethodHead).HandlerFunc(s.GetManifest)
+	v2.Path("/{name:.+}/manifests/{reference}").Methods(http.MethodPut).HandlerFunc(s.PutManifest)
+	v2.Path("/{name:.+}/manifests/{reference}").Methods(http.MethodDelete).HandlerFunc(s.DeleteManifest)
+	v2.Path("/{name:.+}/blobs/uploads/").Methods(http.MethodPost).HandlerFunc(s.StartUpload)
+	v2.Path("/{name:.+}/blobs/uploads/{uuid}").Methods(http.MethodPut).HandlerFunc(s.FinishUpload)
+	v2.Path("/{name:.+}/blobs/{digest}").Methods(http.MethodGet, http.MethodHead).HandlerFunc(s.GetBlob)
+	return s
+}
+
+func Digest(content []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
+
+// PushBlob stores the blob, returns its digest
+func (s *RegistryServer) PushBlob(content []byte) string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	digest := Digest(content)
+	s.Blobs[digest] = content
+	return digest
+}
+
+// PushManifest stores the manifest with tag, returns its digest
+func (s *RegistryServer) PushManifest(repository, tag string, content []byte) string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	digest := Digest(content)
+	if _, ok := s.Manifests[repository]; !ok {
+		s.Manifests[repository] = map[string][]byte{}
+	}
+	s.Manifests[repository][tag] = content
+	s.Manifests[repository][digest] = content
+	return digest
+}
+
+func (s *RegistryServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+Token {
+			name := strings.Split(strings.TrimPrefix(r.URL.Path, "/v2/"), "/manifests/")[0]
+			name = strings.Split(name, "/blobs/")[0]
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+				`Bearer realm="http://%s/token",service="mock",scope="repository:%s:pull,push"`, r.Host, name))
+			s.responseError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *RegistryServer) IssueToken(w http.ResponseWriter, r *http.Request) {
+	if s.Username != "" {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != s.Username || password != s.Password {
+			s.responseError(w, http.StatusUnauthorized, fmt.Errorf("invalid credential"))
+			return
+		}
+	}
+	if r.URL.Query().Get("scope") == "" || r.URL.Query().Get("service") != "mock" {
+		s.responseError(w, http.StatusBadRequest, fmt.Errorf("scope and service are required"))
+		return
+	}
+	s.m.Lock()
+	s.TokenRequests++
+	s.m.Unlock()
+	_ = json.NewEncoder(w).Encode(map[string]string{"token": Token})
+}
+
+func (s *RegistryServer) GetManifest(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	s.m.Lock()
+	content, ok := s.Manifests[vars["name"]][vars["reference"]]
+	s.m.Unlock()
+	if !ok {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("manifest %s not found", vars["reference"]))
+		return
+	}
+	w.Header().Set("Content-Type", _mediaTypeManifest)
+	w.Header().Set("Docker-Content-Digest", Digest(content))
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(content)
+}
+
+func (s *RegistryServer) PutManifest(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.responseError(w, http.StatusBadRequest, err)
+		return
+	}
+	var manifest struct {
+		Config struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+		Layers []struct {
+			Digest string `json:"digest"`
+		} `json:"layers"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		s.responseError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.m.Lock()
+	for _, layer := range manifest.Layers {
+		if _, ok := s.Blobs[layer.Digest]; !ok {
+			s.m.Unlock()
+			s.responseError(w, http.StatusBadRequest, fmt.Errorf("blob %s unknown", layer.Digest))
+			return
+		}
+	}
+	s.m.Unlock()
+	digest := s.PushManifest(vars["name"], vars["reference"], content)
+	w.Header().Set("Docker-Content-Digest", digest)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// DeleteManifest deletes the manifest referenced by digest, with all tags of it
+func (s *RegistryServer) DeleteManifest(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	s.m.Lock()
+	defer s.m.Unlock()
+	found := false
+	for reference, content := range s.Manifests[vars["name"]] {
+		if Digest(content) == vars["reference"] {
+			delete(s.Manifests[vars["name"]], reference)
+			found = true
+		}
+	}
+	if !found {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("manifest %s not found", vars["reference"]))
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *RegistryServer) StartUpload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/upload-id?state=mock", mux.Vars(r)["name"]))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *RegistryServer) FinishUpload(w http.ResponseWriter, r *http.Request) {
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.responseError(w, http.StatusBadRequest, err)
+		return
+	}
+	if r.URL.Query().Get("state") != "mock" || r.URL.Query().Get("digest") != Digest(content) {
+		s.responseError(w, http.StatusBadRequest, fmt.Errorf("digest invalid"))
+		return
+	}
+	s.PushBlob(content)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *RegistryServer) GetBlob(w http.ResponseWriter, r *http.Request) {
+	digest := mux.Vars(r)["digest"]
+	s.m.Lock()
+	content, ok := s.Blobs[digest]
+	s.m.Unlock()
+	if !ok {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("blob %s not found", digest))
+		return
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(content)
+}
+
+func (s *RegistryServer) responseError(w http.ResponseWriter, code int, err error) {
+	w.WriteHeader(code)
+	if err != nil {
+		_, _ = w.Write([]byte(err.Error()))
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"k8s.io/helm/pkg/tlsutil"
+)
+
+const (
+	kind = "oci"
+
+	// Scheme is the scheme of OCI chart repository used by helm
+	Scheme = "oci://"
+
+	_annotationTitle   = "org.opencontainers.image.title"
+	_annotationVersion = "org.opencontainers.image.version"
+	_annotationCreated = "org.opencontainers.image.created"
+)
+
+func init() {
+	templaterepo.Register(kind, NewRepo)
+}
+
+// Repo stores charts in an OCI registry as OCI artifacts, chart <name>:<version>
+// is stored as <host>/<repoName>/<name>:<version>
+type Repo struct {
+	repoName string
+	client   *Client
+}
+
+func NewRepo(config config.Repo) (templaterepo.TemplateRepo, error) {
+	tlsConf, err := tlsutil.NewClientTLS(config.CertFile, config.KeyFile, config.CAFile)
+	if err != nil {
+		return nil, perror.Wrap(herrors.NewErrCreateFailed(herrors.TLS, err.Error()),
+			"failed to create TLS: %v")
+	}
+	tlsConf.InsecureSkipVerify = config.Insecure
+
+	client, err := NewClient(config.Host, config.Username, config.Password, tlsConf)
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{
+		repoName: strings.Trim(config.RepoName, "/"),
+		client:   client,
+	}, nil
+}
+
+func (r *Repo) GetLoc() string {
+	return Scheme + path.Join(r.client.Host(), r.repoName)
+}
+
+func (r *Repo) UploadChart(chartPkg *chart.Chart) error {
+	ctx := context.Background()
+	repository := r.repository(chartPkg.Metadata.Name)
+
+	var buf bytes.Buffer
+	if err := templaterepo.ChartSerialize(chartPkg, &buf); err != nil {
+		return err
+	}
+	configContent, err := json.Marshal(chartPkg.Metadata)
+	if err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "could not marshal chart metadata: %v", err)
+	}
+
+	configDesc, err := r.client.PushBlob(ctx, repository, configContent)
+	if err != nil {
+		return err
+	}
+	configDesc.MediaType = ChartConfigMediaType
+	layerDesc, err := r.client.PushBlob(ctx, repository, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	layerDesc.MediaType = ChartLayerMediaType
+	layerDesc.Annotations = map[string]string{
+		_annotationTitle: fmt.Sprintf("%s-%s.tgz", chartPkg.Metadata.Name, chartPkg.Metadata.Version),
+	}
+
+	manifest := &Manifest{
+		SchemaVersion: 2,
+		MediaType:     ManifestMediaType,
+		Config:        *configDesc,
+		Layers:        []*Descriptor{layerDesc},
+	}
+	_, err = r.client.PutManifest(ctx, repository, Tag(chartPkg.Metadata.Version), manifest)
+	return err
+}
+
+func (r *Repo) DeleteChart(name string, version string) error {
+	ctx := context.Background()
+	repository := r.repository(name)
+	digest, err := r.client.ManifestDigest(ctx, repository, Tag(version))
+	if err != nil {
+		return err
+	}
+	return r.client.DeleteManifest(ctx, repository, digest)
+}
+
+func (r *Repo) ExistChart(name string, version string) (bool, error) {
+	_, err := r.client.ManifestDigest(context.Background(), r.repository(name), Tag(version))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *Repo) GetChart(name string, version string, lastSyncAt time.Time) (*chart.Chart, error) {
+	content, _, err := PullChart(context.Background(), r.client, r.repository(name), Tag(version))
+	if err != nil {
+		return nil, err
+	}
+	chartPackage, err := loader.LoadArchive(bytes.NewReader(content))
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrLoadChartArchive,
+			fmt.Sprintf("failed to load archive: %v", err))
+	}
+	return chartPackage, nil
+}
+
+func (r *Repo) repository(name string) string {
+	return path.Join(r.repoName, name)
+}
+
+// Tag converts chart version to tag, '+' is not allowed in tag, helm replaces it with '_'
+func Tag(version string) string {
+	return strings.ReplaceAll(version, "+", "_")
+}
+
+// PullChart downloads the chart archive of reference, returns the archive and digest of the manifest
+func PullChart(ctx context.Context, client *Client, repository, reference string) ([]byte, string, error) {
+	manifest, digest, err := client.GetManifest(ctx, repository, reference)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == ChartLayerMediaType {
+			content, err := client.GetBlob(ctx, repository, layer.Digest)
+			if err != nil {
+				return nil, "", err
+			}
+			return content, digest, nil
+		}
+	}
+	return nil, "", perror.Wrapf(herrors.ErrLoadChartArchive,
+		"%s:%s is not a helm chart", repository, reference)
+}
@@ -0,0 +1,92 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo/oci/mockserver"
+)
+
+func TestRepo(t *testing.T) {
+	server := mockserver.NewRegistryServer()
+	server.Username, server.Password = "horizon", "password"
+	s := httptest.NewServer(server.R)
+	defer s.Close()
+
+	repo, err := NewRepo(config.Repo{
+		Kind:     kind,
+		Host:     s.URL,
+		Username: "horizon",
+		Password: "password",
+		RepoName: "/horizon/charts/",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "oci://"+s.Listener.Addr().String()+"/horizon/charts", repo.GetLoc())
+
+	exist, err := repo.ExistChart("javaapp", "v1.0.0-5e5193b3")
+	assert.Nil(t, err)
+	assert.False(t, exist)
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "javaapp",
+			Version:    "v1.0.0-5e5193b3+build.1",
+		},
+		Templates: []*chart.File{{Name: "templates/deployment.yaml", Data: []byte("kind: Deployment")}},
+	}
+	assert.Nil(t, repo.UploadChart(c))
+	assert.Contains(t, server.Manifests["horizon/charts/javaapp"], "v1.0.0-5e5193b3_build.1")
+	// token is cached by repository
+	assert.Equal(t, 1, server.TokenRequests)
+
+	exist, err = repo.ExistChart("javaapp", "v1.0.0-5e5193b3+build.1")
+	assert.Nil(t, err)
+	assert.True(t, exist)
+
+	c, err = repo.GetChart("javaapp", "v1.0.0-5e5193b3+build.1", time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, "javaapp", c.Name())
+	assert.Equal(t, "kind: Deployment", string(c.Templates[0].Data))
+
+	assert.Nil(t, repo.DeleteChart("javaapp", "v1.0.0-5e5193b3+build.1"))
+	_, err = repo.GetChart("javaapp", "v1.0.0-5e5193b3+build.1", time.Now())
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",` +
+		`service="registry.docker.io",scope="repository:library/nginx:pull,push"`)
+	assert.Equal(t, "bearer", scheme)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/nginx:pull,push",
+	}, params)
+
+	scheme, params = parseChallenge(`Basic realm=harbor`)
+	assert.Equal(t, "basic", scheme)
+	assert.Equal(t, "harbor", params["realm"])
+}
@@ -0,0 +1,119 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	goerrors "errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/terminalrecording/models"
+)
+
+type DAO interface {
+	// Create creates the index of a terminal recording
+	Create(ctx context.Context, recording *models.TerminalRecording) (*models.TerminalRecording, error)
+	// GetByID gets a terminal recording by id
+	GetByID(ctx context.Context, id uint) (*models.TerminalRecording, error)
+	// List lists terminal recordings matching the query with paging, the latest first
+	List(ctx context.Context, query *q.Query) ([]*models.TerminalRecording, int64, error)
+	// UpdateEnded records the end time, the size and the number of chunks of a terminal recording
+	UpdateEnded(ctx context.Context, id uint, endedAt time.Time, size int64, chunks int) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context,
+	recording *models.TerminalRecording) (*models.TerminalRecording, error) {
+	result := d.db.WithContext(ctx).Create(recording)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.TerminalRecordingInDB, result.Error.Error())
+	}
+	return recording, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.TerminalRecording, error) {
+	var recording models.TerminalRecording
+	result := d.db.WithContext(ctx).Where("id = ?", id).First(&recording)
+	if result.Error != nil {
+		if goerrors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.TerminalRecordingInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TerminalRecordingInDB, result.Error.Error())
+	}
+	return &recording, nil
+}
+
+func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.TerminalRecording, int64, error) {
+	var (
+		recordings []*models.TerminalRecording
+		total      int64
+	)
+	statement := d.filter(ctx, query)
+	if result := statement.Count(&total); result.Error != nil {
+		return nil, 0, herrors.NewErrListFailed(herrors.TerminalRecordingInDB, result.Error.Error())
+	}
+	result := statement.Order("id desc").Limit(query.Limit()).Offset(query.Offset()).Find(&recordings)
+	if result.Error != nil {
+		return nil, 0, herrors.NewErrListFailed(herrors.TerminalRecordingInDB, result.Error.Error())
+	}
+	return recordings, total, nil
+}
+
+func (d *dao) UpdateEnded(ctx context.Context, id uint, endedAt time.Time, size int64, chunks int) error {
+	result := d.db.WithContext(ctx).Model(&models.TerminalRecording{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"ended_at": endedAt,
+			"size":     size,
+			"chunks":   chunks,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.TerminalRecordingInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) filter(ctx context.Context, query *q.Query) *gorm.DB {
+	statement := d.db.WithContext(ctx).Model(&models.TerminalRecording{})
+	if query == nil {
+		return statement
+	}
+	for k, v := range query.Keywords {
+		switch k {
+		case common.TerminalRecordingQueryClusterID:
+			statement = statement.Where("cluster_id = ?", v)
+		case common.TerminalRecordingQueryPod:
+			statement = statement.Where("pod = ?", v)
+		case common.TerminalRecordingQueryUserID:
+			statement = statement.Where("user_id = ?", v)
+		case common.TerminalRecordingQueryStartTime:
+			statement = statement.Where("started_at >= ?", v)
+		case common.TerminalRecordingQueryEndTime:
+			statement = statement.Where("started_at < ?", v)
+		}
+	}
+	return statement
+}
@@ -0,0 +1,65 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/terminalrecording/dao"
+	"github.com/horizoncd/horizon/pkg/terminalrecording/models"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/terminalrecording/manager/manager.go -package=mock_manager
+type Manager interface {
+	// Create creates the index of a terminal recording
+	Create(ctx context.Context, recording *models.TerminalRecording) (*models.TerminalRecording, error)
+	// GetByID gets a terminal recording by id
+	GetByID(ctx context.Context, id uint) (*models.TerminalRecording, error)
+	// List lists terminal recordings matching the query with paging, the latest first
+	List(ctx context.Context, query *q.Query) ([]*models.TerminalRecording, int64, error)
+	// UpdateEnded records the end time, the size and the number of chunks of a terminal recording
+	UpdateEnded(ctx context.Context, id uint, endedAt time.Time, size int64, chunks int) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) Create(ctx context.Context,
+	recording *models.TerminalRecording) (*models.TerminalRecording, error) {
+	return m.dao.Create(ctx, recording)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.TerminalRecording, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) List(ctx context.Context, query *q.Query) ([]*models.TerminalRecording, int64, error) {
+	return m.dao.List(ctx, query)
+}
+
+func (m *manager) UpdateEnded(ctx context.Context, id uint, endedAt time.Time, size int64, chunks int) error {
+	return m.dao.UpdateEnded(ctx, id, endedAt, size, chunks)
+}
@@ -0,0 +1,97 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/terminalrecording/models"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.TerminalRecording{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	now := time.Now()
+	for i, recording := range []*models.TerminalRecording{
+		{ClusterID: 1, Pod: "pod-a", UserID: 1},
+		{ClusterID: 1, Pod: "pod-b", UserID: 2},
+		{ClusterID: 2, Pod: "pod-c", UserID: 1},
+	} {
+		recording.StartedAt = now.Add(time.Duration(i) * time.Minute)
+		_, err := mgr.Create(ctx, recording)
+		assert.Nil(t, err)
+	}
+
+	recordings, total, err := mgr.List(ctx, q.New(q.KeyWords{common.TerminalRecordingQueryClusterID: uint(1)}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "pod-b", recordings[0].Pod)
+
+	_, total, err = mgr.List(ctx, q.New(q.KeyWords{
+		common.TerminalRecordingQueryClusterID: uint(1),
+		common.TerminalRecordingQueryPod:       "pod-a",
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+
+	recordings, total, err = mgr.List(ctx, q.New(q.KeyWords{common.TerminalRecordingQueryUserID: uint(1)}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "pod-c", recordings[0].Pod)
+
+	_, total, err = mgr.List(ctx, q.New(q.KeyWords{
+		common.TerminalRecordingQueryStartTime: now.Add(30 * time.Second),
+		common.TerminalRecordingQueryEndTime:   now.Add(90 * time.Second),
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+
+	recording, err := mgr.GetByID(ctx, recordings[0].ID)
+	assert.Nil(t, err)
+	assert.Nil(t, recording.EndedAt)
+	err = mgr.UpdateEnded(ctx, recording.ID, now.Add(time.Hour), 1024, 2)
+	assert.Nil(t, err)
+	recording, err = mgr.GetByID(ctx, recording.ID)
+	assert.Nil(t, err)
+	assert.NotNil(t, recording.EndedAt)
+	assert.Equal(t, int64(1024), recording.Size)
+	assert.Equal(t, 2, recording.Chunks)
+
+	_, err = mgr.GetByID(ctx, 100)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,40 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// TerminalRecording indexes a terminal session recorded in asciinema v2 format,
+// the recording itself is stored in the log storage of the environment
+type TerminalRecording struct {
+	ID          uint
+	SessionID   string
+	ClusterID   uint
+	Environment string
+	Pod         string
+	Container   string
+	UserID      uint
+	UserName    string
+	// ObjectPath is the path prefix of the recording in the log storage,
+	// the recording is stored by chunks at ObjectPath.0, ObjectPath.1 ...
+	ObjectPath string
+	// Size is the bytes of the recording, and 0 before the session ends
+	Size int64
+	// Chunks is the number of chunks the recording is stored by, and 0 before the session ends
+	Chunks    int
+	StartedAt time.Time
+	EndedAt   *time.Time
+	CreatedAt time.Time
+}
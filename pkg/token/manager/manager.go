@@ -16,6 +16,7 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"github.com/horizoncd/horizon/pkg/token/models"
 	"github.com/horizoncd/horizon/pkg/token/storage"
@@ -28,6 +29,8 @@ type Manager interface {
 	LoadTokenByCode(ctx context.Context, code string) (*models.Token, error)
 	RevokeTokenByID(context.Context, uint) error
 	RevokeTokenByClientID(ctx context.Context, clientID string) error
+	// UpdateLastUsed records the last time and source ip the token is used
+	UpdateLastUsed(ctx context.Context, id uint, ip string, usedAt time.Time) error
 }
 
 func New(db *gorm.DB) Manager {
@@ -57,3 +60,7 @@ func (m *manager) RevokeTokenByID(ctx context.Context, id uint) error {
 func (m *manager) RevokeTokenByClientID(ctx context.Context, clientID string) error {
 	return m.storage.DeleteByClientID(ctx, clientID)
 }
+
+func (m *manager) UpdateLastUsed(ctx context.Context, id uint, ip string, usedAt time.Time) error {
+	return m.storage.UpdateLastUsed(ctx, id, ip, usedAt)
+}
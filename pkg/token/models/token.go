@@ -15,6 +15,10 @@
 package models
 
 import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -36,4 +40,88 @@ type Token struct {
 	Scope     string        `gorm:"column:scope"`
 
 	UserID uint `gorm:"column:user_id"`
+
+	// restrictions of access token, joined by space as scope
+	Resources    string `gorm:"column:resources"`
+	Verbs        string `gorm:"column:verbs"`
+	AllowedCIDRs string `gorm:"column:allowed_cidrs"`
+
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+	LastUsedIP string     `gorm:"column:last_used_ip"`
+}
+
+// Restrictions narrows down what an access token could do beyond its scopes,
+// and the empty ones mean no restrictions
+type Restrictions struct {
+	// Resources are the only resources the token could reach, in the format of <resourceType>/<resourceID>,
+	// and resources under them are included, e.g. clusters of an application
+	Resources []string `json:"resources,omitempty"`
+	// Verbs are the only verbs the token could perform, and actions such as deploy are matched by sub resource
+	Verbs []string `json:"verbs,omitempty"`
+	// AllowedCIDRs are the source addresses the token could be used from
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+}
+
+func (t *Token) GetRestrictions() *Restrictions {
+	return &Restrictions{
+		Resources:    strings.Fields(t.Resources),
+		Verbs:        strings.Fields(t.Verbs),
+		AllowedCIDRs: strings.Fields(t.AllowedCIDRs),
+	}
+}
+
+func (t *Token) SetRestrictions(restrictions *Restrictions) {
+	if restrictions == nil {
+		return
+	}
+	t.Resources = strings.Join(restrictions.Resources, " ")
+	t.Verbs = strings.Join(restrictions.Verbs, " ")
+	t.AllowedCIDRs = strings.Join(restrictions.AllowedCIDRs, " ")
+}
+
+// AllowIP checks if the token could be used from ip
+func (r *Restrictions) AllowIP(ip string) bool {
+	if len(r.AllowedCIDRs) == 0 {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, cidr := range r.AllowedCIDRs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if ipNet.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowVerb checks if the token could perform verb, or the action named by subResource
+func (r *Restrictions) AllowVerb(verb, subResource string) bool {
+	if len(r.Verbs) == 0 {
+		return true
+	}
+	for _, v := range r.Verbs {
+		if v == verb || (subResource != "" && v == subResource) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseResource parses resource in the format of <resourceType>/<resourceID>
+func ParseResource(resource string) (string, uint, error) {
+	parts := strings.Split(resource, "/")
+	if len(parts) != 2 || parts[0] == "" {
+		return "", 0, fmt.Errorf("resource %s is not in the format of <resourceType>/<resourceID>", resource)
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 0)
+	if err != nil {
+		return "", 0, fmt.Errorf("resource %s has an invalid id: %v", resource, err)
+	}
+	return parts[0], uint(id), nil
 }
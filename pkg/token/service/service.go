@@ -16,10 +16,12 @@ package service
 
 import (
 	"context"
+	"net"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/horizoncd/horizon/core/common"
 	herror "github.com/horizoncd/horizon/core/errors"
 	tokenconfig "github.com/horizoncd/horizon/pkg/config/token"
 	perror "github.com/horizoncd/horizon/pkg/errors"
@@ -32,7 +34,7 @@ import (
 type Service interface {
 	// CreateAccessToken used for personal access Token and resource access Token
 	CreateAccessToken(ctx context.Context, name, expiresAtStr string,
-		userID uint, scopes []string) (*tokenmodels.Token, error)
+		userID uint, scopes []string, restrictions *tokenmodels.Restrictions) (*tokenmodels.Token, error)
 	CreateJWTToken(subject string, expiresIn time.Duration, options ...ClaimsOption) (string, error)
 	ParseJWTToken(tokenStr string) (Claims, error)
 }
@@ -50,7 +52,7 @@ type service struct {
 }
 
 func (s *service) CreateAccessToken(ctx context.Context, name, expiresAtStr string,
-	userID uint, scopes []string) (*tokenmodels.Token, error) {
+	userID uint, scopes []string, restrictions *tokenmodels.Restrictions) (*tokenmodels.Token, error) {
 	// 1. check expiration date
 	createdAt := time.Now()
 	expiresIn := time.Duration(0)
@@ -64,13 +66,18 @@ func (s *service) CreateAccessToken(ctx context.Context, name, expiresAtStr stri
 		}
 		expiresIn = expiredAt.Sub(createdAt)
 	}
-	// 2. generate user access token
+	// 2. check restrictions
+	if err := validateRestrictions(restrictions); err != nil {
+		return nil, err
+	}
+	// 3. generate user access token
 	gen := generator.NewGeneralAccessTokenGenerator()
 	token, err := s.genAccessToken(gen, name, userID, scopes, createdAt, expiresIn)
 	if err != nil {
 		return nil, err
 	}
-	// 3. create token in db
+	token.SetRestrictions(restrictions)
+	// 4. create token in db
 	token, err = s.tokenManager.CreateToken(ctx, token)
 	if err != nil {
 		return nil, err
@@ -78,6 +85,44 @@ func (s *service) CreateAccessToken(ctx context.Context, name, expiresAtStr stri
 	return token, nil
 }
 
+// validateRestrictions checks restrictions, and turns plain ips of allowed cidrs into cidrs
+func validateRestrictions(restrictions *tokenmodels.Restrictions) error {
+	if restrictions == nil {
+		return nil
+	}
+	for _, resource := range restrictions.Resources {
+		resourceType, _, err := tokenmodels.ParseResource(resource)
+		if err != nil {
+			return perror.Wrap(herror.ErrParamInvalid, err.Error())
+		}
+		switch resourceType {
+		case common.ResourceGroup, common.ResourceApplication, common.ResourceCluster:
+		default:
+			return perror.Wrapf(herror.ErrParamInvalid,
+				"resource type %s is not supported in restrictions", resourceType)
+		}
+	}
+	for _, verb := range restrictions.Verbs {
+		if verb == "" || strings.ContainsAny(verb, " /") {
+			return perror.Wrapf(herror.ErrParamInvalid, "invalid verb %q", verb)
+		}
+	}
+	for i, cidr := range restrictions.AllowedCIDRs {
+		if ip := net.ParseIP(cidr); ip != nil {
+			if ip.To4() != nil {
+				restrictions.AllowedCIDRs[i] = cidr + "/32"
+			} else {
+				restrictions.AllowedCIDRs[i] = cidr + "/128"
+			}
+			continue
+		}
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return perror.Wrapf(herror.ErrParamInvalid, "invalid cidr %s: %v", cidr, err)
+		}
+	}
+	return nil
+}
+
 func (s *service) genAccessToken(gen generator.AccessTokenCodeGenerator, name string, userID uint,
 	scopes []string, createdAt time.Time, expiresIn time.Duration) (*tokenmodels.Token, error) {
 	code := gen.GenCode(&generator.CodeGenerateInfo{
@@ -74,12 +74,51 @@ func TestService(t *testing.T) {
 	scopes := make([]string, 2)
 	scopes = append(scopes, "clusters:read-write")
 	scopes = append(scopes, "applications:read-only")
-	token, err := tokenSvc.CreateAccessToken(ctx, name, expiresAtStr, aUser.GetID(), scopes)
+	token, err := tokenSvc.CreateAccessToken(ctx, name, expiresAtStr, aUser.GetID(), scopes, nil)
 	assert.Nil(t, err)
 	tokenInDB, err := tokenManager.LoadTokenByID(ctx, token.ID)
 	assert.Nil(t, err)
 	assert.Equal(t, name, tokenInDB.Name)
 	assert.Equal(t, strings.Join(scopes, " "), tokenInDB.Scope)
+	assert.Equal(t, &tokenmodels.Restrictions{Resources: []string{}, Verbs: []string{}, AllowedCIDRs: []string{}},
+		tokenInDB.GetRestrictions())
+
+	// Create restricted AccessToken
+	token, err = tokenSvc.CreateAccessToken(ctx, name, NeverExpire, aUser.GetID(), scopes,
+		&tokenmodels.Restrictions{
+			Resources:    []string{"applications/1"},
+			Verbs:        []string{"deploy", "get"},
+			AllowedCIDRs: []string{"10.0.0.0/8", "192.168.1.1"},
+		})
+	assert.Nil(t, err)
+	tokenInDB, err = tokenManager.LoadTokenByID(ctx, token.ID)
+	assert.Nil(t, err)
+	restrictions := tokenInDB.GetRestrictions()
+	assert.Equal(t, []string{"applications/1"}, restrictions.Resources)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1/32"}, restrictions.AllowedCIDRs)
+	assert.True(t, restrictions.AllowIP("10.1.2.3"))
+	assert.True(t, restrictions.AllowIP("192.168.1.1"))
+	assert.False(t, restrictions.AllowIP("192.168.1.2"))
+	assert.True(t, restrictions.AllowVerb("create", "deploy"))
+	assert.True(t, restrictions.AllowVerb("get", ""))
+	assert.False(t, restrictions.AllowVerb("update", ""))
+
+	for _, invalid := range []*tokenmodels.Restrictions{
+		{Resources: []string{"applications"}},
+		{Resources: []string{"templates/1"}},
+		{Verbs: []string{"clusters/deploy"}},
+		{AllowedCIDRs: []string{"10.0.0.0/33"}},
+	} {
+		_, err = tokenSvc.CreateAccessToken(ctx, name, NeverExpire, aUser.GetID(), scopes, invalid)
+		assert.NotNil(t, err)
+	}
+
+	now := time.Now()
+	assert.Nil(t, tokenManager.UpdateLastUsed(ctx, token.ID, "10.1.2.3", now))
+	tokenInDB, err = tokenManager.LoadTokenByID(ctx, token.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.1.2.3", tokenInDB.LastUsedIP)
+	assert.Equal(t, now.Unix(), tokenInDB.LastUsedAt.Unix())
 
 	// Create JWT token
 	jwtToken, err := tokenSvc.CreateJWTToken(strconv.Itoa(int(aUser.GetID())), 2*time.Hour,
@@ -17,6 +17,7 @@ package storage
 import (
 	"context"
 	goerrors "errors"
+	"time"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/common"
@@ -75,3 +76,15 @@ func (d *storage) DeleteByClientID(ctx context.Context, clientID string) error {
 	result := d.db.WithContext(ctx).Exec(common.DeleteByClientID, clientID)
 	return result.Error
 }
+
+func (d *storage) UpdateLastUsed(ctx context.Context, id uint, ip string, usedAt time.Time) error {
+	result := d.db.WithContext(ctx).Model(&models.Token{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_used_at": usedAt,
+			"last_used_ip": ip,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.TokenInDB, result.Error.Error())
+	}
+	return nil
+}
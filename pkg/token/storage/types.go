@@ -16,6 +16,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/horizoncd/horizon/pkg/token/models"
 )
@@ -27,4 +28,5 @@ type Storage interface {
 	DeleteByID(ctx context.Context, id uint) error
 	DeleteByCode(ctx context.Context, code string) error
 	DeleteByClientID(ctx context.Context, clientID string) error
+	UpdateLastUsed(ctx context.Context, id uint, ip string, usedAt time.Time) error
 }
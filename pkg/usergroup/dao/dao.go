@@ -0,0 +1,161 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/usergroup/models"
+)
+
+type DAO interface {
+	// SyncUserGroups makes the user a member of exactly the named groups of the identity provider,
+	// groups are created if absent
+	SyncUserGroups(ctx context.Context, userID, idpID uint, names []string) ([]*models.UserGroup, error)
+	GetByID(ctx context.Context, id uint) (*models.UserGroup, error)
+	ListByIDs(ctx context.Context, ids []uint) ([]*models.UserGroup, error)
+	// ListByUserID lists groups which the user belongs to
+	ListByUserID(ctx context.Context, userID uint) ([]*models.UserGroup, error)
+	// List lists groups whose name contains filter
+	List(ctx context.Context, filter string) ([]*models.UserGroup, error)
+}
+
+// _syncRetries is the number of attempts to sync user groups,
+// the sync is retried when a concurrent sync has created the same records
+const _syncRetries = 3
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) SyncUserGroups(ctx context.Context, userID, idpID uint,
+	names []string) ([]*models.UserGroup, error) {
+	var (
+		groups []*models.UserGroup
+		err    error
+	)
+	for i := 0; i < _syncRetries; i++ {
+		// records created by the concurrent sync are found by the retry
+		groups, err = d.syncUserGroups(ctx, userID, idpID, names)
+		if err == nil || !isDuplicateKey(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.UserGroupInDB, err.Error())
+	}
+	return groups, nil
+}
+
+func (d *dao) syncUserGroups(ctx context.Context, userID, idpID uint,
+	names []string) ([]*models.UserGroup, error) {
+	groups := make([]*models.UserGroup, 0, len(names))
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		groupIDs := make([]uint, 0, len(names))
+		for _, name := range names {
+			group := &models.UserGroup{IdpID: idpID, Name: name}
+			if err := tx.Where(group).FirstOrCreate(group).Error; err != nil {
+				return err
+			}
+			groups = append(groups, group)
+			groupIDs = append(groupIDs, group.ID)
+		}
+
+		// remove the user from groups of the identity provider which are absent in claim
+		query := tx.Where("user_id = ?", userID).
+			Where("user_group_id in (?)", tx.Model(&models.UserGroup{}).Select("id").Where("idp_id = ?", idpID))
+		if len(groupIDs) > 0 {
+			query = query.Where("user_group_id not in ?", groupIDs)
+		}
+		if err := query.Delete(&models.UserGroupMember{}).Error; err != nil {
+			return err
+		}
+
+		for _, groupID := range groupIDs {
+			member := &models.UserGroupMember{UserGroupID: groupID, UserID: userID}
+			if err := tx.Where(member).FirstOrCreate(member).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// isDuplicateKey returns whether the error is a violation of unique index, in mysql or sqlite
+func isDuplicateKey(err error) bool {
+	return strings.Contains(err.Error(), "Duplicate") || strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.UserGroup, error) {
+	var group models.UserGroup
+	result := d.db.WithContext(ctx).Where("id = ?", id).First(&group)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.UserGroupInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.UserGroupInDB, result.Error.Error())
+	}
+	return &group, nil
+}
+
+func (d *dao) ListByIDs(ctx context.Context, ids []uint) ([]*models.UserGroup, error) {
+	var groups []*models.UserGroup
+	if len(ids) == 0 {
+		return groups, nil
+	}
+	result := d.db.WithContext(ctx).Where("id in ?", ids).Find(&groups)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.UserGroupInDB, result.Error.Error())
+	}
+	return groups, nil
+}
+
+func (d *dao) ListByUserID(ctx context.Context, userID uint) ([]*models.UserGroup, error) {
+	var groups []*models.UserGroup
+	result := d.db.WithContext(ctx).
+		Where("id in (?)", d.db.Model(&models.UserGroupMember{}).Select("user_group_id").
+			Where("user_id = ?", userID)).
+		Order("id").
+		Find(&groups)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.UserGroupInDB, result.Error.Error())
+	}
+	return groups, nil
+}
+
+func (d *dao) List(ctx context.Context, filter string) ([]*models.UserGroup, error) {
+	var groups []*models.UserGroup
+	query := d.db.WithContext(ctx)
+	if filter != "" {
+		query = query.Where("name like ?", "%"+filter+"%")
+	}
+	result := query.Order("name").Find(&groups)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.UserGroupInDB, result.Error.Error())
+	}
+	return groups, nil
+}
@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/usergroup/dao"
+	"github.com/horizoncd/horizon/pkg/usergroup/models"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/usergroup/manager/manager.go -package=mock_manager
+type Manager interface {
+	// SyncUserGroups makes the user a member of exactly the named groups of the identity provider,
+	// groups are created if absent
+	SyncUserGroups(ctx context.Context, userID, idpID uint, names []string) ([]*models.UserGroup, error)
+	GetByID(ctx context.Context, id uint) (*models.UserGroup, error)
+	ListByIDs(ctx context.Context, ids []uint) ([]*models.UserGroup, error)
+	// ListByUserID lists groups which the user belongs to
+	ListByUserID(ctx context.Context, userID uint) ([]*models.UserGroup, error)
+	// List lists groups whose name contains filter
+	List(ctx context.Context, filter string) ([]*models.UserGroup, error)
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) SyncUserGroups(ctx context.Context, userID, idpID uint,
+	names []string) ([]*models.UserGroup, error) {
+	return m.dao.SyncUserGroups(ctx, userID, idpID, names)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.UserGroup, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) ListByIDs(ctx context.Context, ids []uint) ([]*models.UserGroup, error) {
+	return m.dao.ListByIDs(ctx, ids)
+}
+
+func (m *manager) ListByUserID(ctx context.Context, userID uint) ([]*models.UserGroup, error) {
+	return m.dao.ListByUserID(ctx, userID)
+}
+
+func (m *manager) List(ctx context.Context, filter string) ([]*models.UserGroup, error) {
+	return m.dao.List(ctx, filter)
+}
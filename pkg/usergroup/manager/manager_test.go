@@ -0,0 +1,126 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/usergroup/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.UserGroup{}, &models.UserGroupMember{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func groupNames(groups []*models.UserGroup) []string {
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+	return names
+}
+
+func Test(t *testing.T) {
+	var userID, otherUserID, idpID, otherIdpID uint = 1, 2, 1, 2
+
+	groups, err := mgr.SyncUserGroups(ctx, userID, idpID, []string{"dev", "ops"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"dev", "ops"}, groupNames(groups))
+	_, err = mgr.SyncUserGroups(ctx, userID, otherIdpID, []string{"dev"})
+	assert.Nil(t, err)
+	_, err = mgr.SyncUserGroups(ctx, otherUserID, idpID, []string{"dev"})
+	assert.Nil(t, err)
+
+	groups, err = mgr.ListByUserID(ctx, userID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"dev", "ops", "dev"}, groupNames(groups))
+
+	// user left ops and joined qa in the identity provider
+	_, err = mgr.SyncUserGroups(ctx, userID, idpID, []string{"dev", "qa"})
+	assert.Nil(t, err)
+	groups, err = mgr.ListByUserID(ctx, userID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"dev", "dev", "qa"}, groupNames(groups))
+
+	// groups of other identity provider and other users are kept
+	_, err = mgr.SyncUserGroups(ctx, userID, idpID, nil)
+	assert.Nil(t, err)
+	groups, err = mgr.ListByUserID(ctx, userID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(groups))
+	assert.Equal(t, otherIdpID, groups[0].IdpID)
+	groups, err = mgr.ListByUserID(ctx, otherUserID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"dev"}, groupNames(groups))
+
+	groups, err = mgr.List(ctx, "o")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ops"}, groupNames(groups))
+	groups, err = mgr.List(ctx, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(groups))
+
+	group, err := mgr.GetByID(ctx, groups[0].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, groups[0].Name, group.Name)
+	groups, err = mgr.ListByIDs(ctx, []uint{group.ID})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(groups))
+
+	_, err = mgr.GetByID(ctx, 100)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestSyncUserGroupsConflict(t *testing.T) {
+	var userID, idpID uint = 3, 3
+
+	// the first insert conflicts with a concurrent sync
+	conflicts := 0
+	const callback = "test:conflict"
+	assert.Nil(t, db.Callback().Create().Before("gorm:create").Register(callback, func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Model.(*models.UserGroup); ok && conflicts == 0 {
+			conflicts++
+			_ = tx.AddError(errors.New("UNIQUE constraint failed: tb_user_group.idp_id, tb_user_group.name"))
+		}
+	}))
+	defer func() { _ = db.Callback().Create().Remove(callback) }()
+
+	groups, err := mgr.SyncUserGroups(ctx, userID, idpID, []string{"sre"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, conflicts)
+	assert.Equal(t, []string{"sre"}, groupNames(groups))
+	groups, err = mgr.ListByUserID(ctx, userID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sre"}, groupNames(groups))
+}
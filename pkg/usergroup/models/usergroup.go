@@ -0,0 +1,36 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// UserGroup is a directory group synced from the groups claim of identity provider,
+// which can be bound as member of groups, applications and clusters.
+type UserGroup struct {
+	ID uint
+	// IdpID is the identity provider which the group comes from
+	IdpID     uint   `gorm:"uniqueIndex:idx_idp_name"`
+	Name      string `gorm:"uniqueIndex:idx_idp_name"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// UserGroupMember is the relation between user and group, refreshed when the user logs in with the identity provider
+type UserGroupMember struct {
+	ID          uint
+	UserGroupID uint `gorm:"uniqueIndex:idx_group_user"`
+	UserID      uint `gorm:"uniqueIndex:idx_group_user;index"`
+	CreatedAt   time.Time
+}
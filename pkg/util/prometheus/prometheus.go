@@ -0,0 +1,160 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// Client runs PromQL queries by the prometheus http api
+type Client struct {
+	client *http.Client
+}
+
+func NewClient(timeout time.Duration) *Client {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &Client{client: &http.Client{Timeout: timeout}}
+}
+
+// queryResponse is the response of prometheus instant query api
+type queryResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type sample struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+// Query runs an instant query evaluated at ts and returns values of all the samples,
+// the query is evaluated at the current time if ts is zero
+func (c *Client) Query(ctx context.Context, prometheusURL, query string, ts time.Time) ([]float64, error) {
+	samples, err := c.query(ctx, prometheusURL, query, ts)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		values = append(values, s.value)
+	}
+	return values, nil
+}
+
+// QueryByLabel runs an instant query like Query, and returns values of the samples keyed by the value of label,
+// the values of samples with the same label value are summed up, and NaN values are skipped
+func (c *Client) QueryByLabel(ctx context.Context, prometheusURL, query string, ts time.Time,
+	label string) (map[string]float64, error) {
+	samples, err := c.query(ctx, prometheusURL, query, ts)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]float64, len(samples))
+	for _, s := range samples {
+		if !math.IsNaN(s.value) {
+			values[s.metric[label]] += s.value
+		}
+	}
+	return values, nil
+}
+
+type labeledValue struct {
+	metric map[string]string
+	value  float64
+}
+
+func (c *Client) query(ctx context.Context, prometheusURL, query string, ts time.Time) ([]labeledValue, error) {
+	params := url.Values{"query": []string{query}}
+	if !ts.IsZero() {
+		params.Set("time", strconv.FormatInt(ts.Unix(), 10))
+	}
+	u := fmt.Sprintf("%v/api/v1/query?%v", strings.TrimSuffix(prometheusURL, "/"), params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.Prometheus, err.Error())
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.Prometheus, err.Error())
+	}
+	var queryResp queryResponse
+	if err := json.Unmarshal(body, &queryResp); err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.Prometheus,
+			fmt.Sprintf("failed to unmarshal response, status code = %d, body = %s", resp.StatusCode, body))
+	}
+	if queryResp.Status != "success" {
+		return nil, herrors.NewErrGetFailed(herrors.Prometheus,
+			fmt.Sprintf("query %s failed: %s: %s", query, queryResp.ErrorType, queryResp.Error))
+	}
+
+	var samples []sample
+	switch queryResp.Data.ResultType {
+	case "vector":
+		if err := json.Unmarshal(queryResp.Data.Result, &samples); err != nil {
+			return nil, herrors.NewErrGetFailed(herrors.Prometheus, err.Error())
+		}
+	case "scalar":
+		var value []interface{}
+		if err := json.Unmarshal(queryResp.Data.Result, &value); err != nil {
+			return nil, herrors.NewErrGetFailed(herrors.Prometheus, err.Error())
+		}
+		samples = append(samples, sample{Value: value})
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"result type %v of query %s is not supported", queryResp.Data.ResultType, query)
+	}
+
+	values := make([]labeledValue, 0, len(samples))
+	for _, s := range samples {
+		if len(s.Value) != 2 {
+			continue
+		}
+		str, ok := s.Value[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, herrors.NewErrGetFailed(herrors.Prometheus, err.Error())
+		}
+		values = append(values, labeledValue{metric: s.Metric, value: value})
+	}
+	return values, nil
+}
@@ -0,0 +1,41 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/horizoncd/horizon/core/common"
+)
+
+// invalidLabelChars are replaced by '_' when kube-state-metrics exports kubernetes labels
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// ClusterLabel is the label of kube_pod_labels exported by kube-state-metrics for common.ClusterClusterLabelKey
+var ClusterLabel = LabelName(common.ClusterClusterLabelKey)
+
+// LabelName returns the name of label exported by kube-state-metrics for the key of a kubernetes label
+func LabelName(key string) string {
+	return "label_" + invalidLabelChars.ReplaceAllString(key, "_")
+}
+
+// ClusterPodSelector returns the expression to multiply a metric of pods by, so that only the pods of clusters
+// matched by the matcher of ClusterLabel are kept, such as ="{{.Cluster}}", and the metric is labeled with
+// ClusterLabel to aggregate by
+func ClusterPodSelector(matcher string) string {
+	return fmt.Sprintf(` * on(namespace, pod) group_left(%[1]s) max by (namespace, pod, %[1]s) `+
+		`(kube_pod_labels{%[1]s%[2]s})`, ClusterLabel, matcher)
+}
@@ -0,0 +1,307 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package visibility
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	"github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermanager "github.com/horizoncd/horizon/pkg/member"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	prmanager "github.com/horizoncd/horizon/pkg/pipelinerun/manager"
+)
+
+const (
+	Public  = "public"
+	Private = "private"
+
+	// cacheTTL bounds how long the list APIs of an instance keep showing groups and applications
+	// which are made private by another instance, reads of the resources themselves are not cached
+	cacheTTL = 10 * time.Second
+
+	// _rootGroupID is the id of the root group, which does not exist in db and is public
+	_rootGroupID = 0
+)
+
+// Validate checks the visibility level, and the empty level means public
+func Validate(visibilityLevel string) error {
+	switch visibilityLevel {
+	case "", Public, Private:
+		return nil
+	}
+	return perror.Wrapf(herrors.ErrParamInvalid,
+		"visibilityLevel should be %s or %s, but got %s", Public, Private, visibilityLevel)
+}
+
+// LevelOrDefault returns the visibility level, resources are public by default
+func LevelOrDefault(visibilityLevel string) string {
+	if visibilityLevel == "" {
+		return Public
+	}
+	return visibilityLevel
+}
+
+// Checker checks the visibility of groups and applications.
+// A resource is private if itself or any group in its traversalIDs is private,
+// and private resources are only visible to their members.
+type Checker interface {
+	// IsPrivate returns whether the resource is private, it reads through db and groups not found are private.
+	// Clusters and pipelineruns inherit the visibility of their applications
+	IsPrivate(ctx context.Context, resourceType string, resourceID uint) (bool, error)
+	// ListInvisible lists the private groups and applications which the user is not a member of,
+	// it is served from the cache for list APIs
+	ListInvisible(ctx context.Context, currentUser user.User) (*groupmodels.Invisible, error)
+	// Invalidate drops the cached visibility, it should be called after the visibility of any resource changed
+	Invalidate()
+}
+
+func NewChecker(groupMgr groupmanager.Manager, applicationMgr appmanager.Manager,
+	clusterMgr clustermanager.Manager, pipelinerunMgr prmanager.Manager,
+	memberMgr membermanager.Manager) Checker {
+	return &checker{
+		groupMgr:       groupMgr,
+		applicationMgr: applicationMgr,
+		clusterMgr:     clusterMgr,
+		pipelinerunMgr: pipelinerunMgr,
+		memberMgr:      memberMgr,
+	}
+}
+
+type checker struct {
+	groupMgr       groupmanager.Manager
+	applicationMgr appmanager.Manager
+	clusterMgr     clustermanager.Manager
+	pipelinerunMgr prmanager.Manager
+	memberMgr      membermanager.Manager
+
+	lock     sync.RWMutex
+	snapshot *snapshot
+}
+
+// snapshot is the cached visibility of all groups and applications
+type snapshot struct {
+	// traversalIDs of all the groups
+	traversalIDs map[uint][]uint
+	// privateGroups holds private groups and their descendants
+	privateGroups map[uint]struct{}
+	// privateApplications maps private applications to their groups
+	privateApplications map[uint]uint
+	expiredAt           time.Time
+}
+
+func (s *snapshot) empty() bool {
+	return len(s.privateGroups) == 0 && len(s.privateApplications) == 0
+}
+
+func (c *checker) Invalidate() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.snapshot = nil
+}
+
+func (c *checker) IsPrivate(ctx context.Context, resourceType string, resourceID uint) (bool, error) {
+	switch resourceType {
+	case common.ResourceGroup:
+		return c.isGroupPrivate(ctx, resourceID)
+	case common.ResourceApplication:
+		return c.isApplicationPrivate(ctx, resourceID)
+	case common.ResourceCluster:
+		cluster, err := c.clusterMgr.GetByID(ctx, resourceID)
+		if err != nil {
+			return false, err
+		}
+		return c.isApplicationPrivate(ctx, cluster.ApplicationID)
+	case common.ResourcePipelinerun:
+		pipelinerun, err := c.pipelinerunMgr.GetByID(ctx, resourceID)
+		if err != nil {
+			return false, err
+		}
+		if pipelinerun == nil {
+			return false, herrors.NewErrNotFound(herrors.PipelinerunInDB,
+				fmt.Sprintf("pipelinerun %d does not exist", resourceID))
+		}
+		cluster, err := c.clusterMgr.GetByID(ctx, pipelinerun.ClusterID)
+		if err != nil {
+			return false, err
+		}
+		return c.isApplicationPrivate(ctx, cluster.ApplicationID)
+	}
+	return false, nil
+}
+
+func (c *checker) isApplicationPrivate(ctx context.Context, applicationID uint) (bool, error) {
+	application, err := c.applicationMgr.GetByID(ctx, applicationID)
+	if err != nil {
+		return false, err
+	}
+	if application.VisibilityLevel == Private {
+		return true, nil
+	}
+	return c.isGroupPrivate(ctx, application.GroupID)
+}
+
+// isGroupPrivate checks the group and its ancestors in db, groups not found are taken as private
+func (c *checker) isGroupPrivate(ctx context.Context, groupID uint) (bool, error) {
+	if groupID == _rootGroupID {
+		return false, nil
+	}
+	group, err := c.groupMgr.GetByID(ctx, groupID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return true, nil
+		}
+		return false, err
+	}
+	if group.VisibilityLevel == Private {
+		return true, nil
+	}
+	traversalIDs := groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs)
+	groups, err := c.groupMgr.GetByIDs(ctx, traversalIDs)
+	if err != nil {
+		return false, err
+	}
+	if len(groups) != len(traversalIDs) {
+		return true, nil
+	}
+	for _, g := range groups {
+		if g.VisibilityLevel == Private {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *checker) ListInvisible(ctx context.Context, currentUser user.User) (*groupmodels.Invisible, error) {
+	invisible := &groupmodels.Invisible{
+		Groups:       map[uint]struct{}{},
+		Applications: map[uint]struct{}{},
+	}
+	if currentUser.IsAdmin() {
+		return invisible, nil
+	}
+	s, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.empty() {
+		return invisible, nil
+	}
+
+	memberGroups, err := c.listResourceOfMember(ctx, membermodels.TypeGroup, currentUser.GetID())
+	if err != nil {
+		return nil, err
+	}
+	memberApplications, err := c.listResourceOfMember(ctx, membermodels.TypeApplication, currentUser.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	// members of a group or any of its ancestors can see the group
+	visible := func(groupID uint) bool {
+		for _, id := range s.traversalIDs[groupID] {
+			if _, ok := memberGroups[id]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	for groupID := range s.privateGroups {
+		if !visible(groupID) {
+			invisible.Groups[groupID] = struct{}{}
+		}
+	}
+	for applicationID, groupID := range s.privateApplications {
+		if _, ok := memberApplications[applicationID]; ok {
+			continue
+		}
+		if !visible(groupID) {
+			invisible.Applications[applicationID] = struct{}{}
+		}
+	}
+	return invisible, nil
+}
+
+func (c *checker) listResourceOfMember(ctx context.Context,
+	resourceType membermodels.ResourceType, userID uint) (map[uint]struct{}, error) {
+	resourceIDs, err := c.memberMgr.ListResourceOfMemberInfo(ctx, resourceType, userID)
+	if err != nil {
+		return nil, err
+	}
+	resources := make(map[uint]struct{}, len(resourceIDs))
+	for _, id := range resourceIDs {
+		resources[id] = struct{}{}
+	}
+	return resources, nil
+}
+
+// load returns the cached snapshot, and reloads it from db after expired
+func (c *checker) load(ctx context.Context) (*snapshot, error) {
+	c.lock.RLock()
+	s := c.snapshot
+	c.lock.RUnlock()
+	if s != nil && time.Now().Before(s.expiredAt) {
+		return s, nil
+	}
+
+	groups, err := c.groupMgr.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applications, err := c.applicationMgr.GetByVisibilityLevel(ctx, Private)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &snapshot{
+		traversalIDs:        make(map[uint][]uint, len(groups)),
+		privateGroups:       map[uint]struct{}{},
+		privateApplications: make(map[uint]uint, len(applications)),
+		expiredAt:           time.Now().Add(cacheTTL),
+	}
+	privateRoots := map[uint]struct{}{}
+	for _, group := range groups {
+		s.traversalIDs[group.ID] = groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs)
+		if group.VisibilityLevel == Private {
+			privateRoots[group.ID] = struct{}{}
+		}
+	}
+	// visibility is inherited through traversalIDs
+	for groupID, traversalIDs := range s.traversalIDs {
+		for _, id := range traversalIDs {
+			if _, ok := privateRoots[id]; ok {
+				s.privateGroups[groupID] = struct{}{}
+				break
+			}
+		}
+	}
+	for _, application := range applications {
+		s.privateApplications[application.ID] = application.GroupID
+	}
+
+	c.lock.Lock()
+	c.snapshot = s
+	c.lock.Unlock()
+	return s, nil
+}
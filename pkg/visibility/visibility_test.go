@@ -0,0 +1,143 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package visibility
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermanager "github.com/horizoncd/horizon/pkg/member"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	prmanager "github.com/horizoncd/horizon/pkg/pipelinerun/manager"
+)
+
+var (
+	db, _             = orm.NewSqliteDB("")
+	ctx               context.Context
+	groupMgr          = groupmanager.New(db)
+	appMgr            = appmanager.New(db)
+	memberMgr         = membermanager.New(db)
+	visibilityChecker = NewChecker(groupMgr, appMgr, clustermanager.New(db), prmanager.New(db), memberMgr)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{}, &membermodels.Member{}); err != nil {
+		panic(err)
+	}
+	ctx = common.WithContext(context.Background(), &user.DefaultInfo{Name: "admin", ID: 1000, Admin: true})
+	os.Exit(m.Run())
+}
+
+func TestChecker(t *testing.T) {
+	public, err := groupMgr.Create(ctx, &groupmodels.Group{Name: "public", Path: "public",
+		VisibilityLevel: Public})
+	assert.Nil(t, err)
+	private, err := groupMgr.Create(ctx, &groupmodels.Group{Name: "private", Path: "private",
+		VisibilityLevel: Private})
+	assert.Nil(t, err)
+	sub, err := groupMgr.Create(ctx, &groupmodels.Group{Name: "sub", Path: "sub",
+		VisibilityLevel: Public, ParentID: private.ID})
+	assert.Nil(t, err)
+
+	publicApp := &appmodels.Application{Name: "public-app", GroupID: public.ID, VisibilityLevel: Public}
+	privateApp := &appmodels.Application{Name: "private-app", GroupID: public.ID, VisibilityLevel: Private}
+	subApp := &appmodels.Application{Name: "sub-app", GroupID: sub.ID, VisibilityLevel: Public}
+	for _, app := range []*appmodels.Application{publicApp, privateApp, subApp} {
+		assert.Nil(t, db.Create(app).Error)
+	}
+
+	// visibility is inherited through traversalIDs
+	for _, c := range []struct {
+		resourceType string
+		resourceID   uint
+		private      bool
+	}{
+		{common.ResourceGroup, public.ID, false},
+		{common.ResourceGroup, private.ID, true},
+		{common.ResourceGroup, sub.ID, true},
+		{common.ResourceApplication, publicApp.ID, false},
+		{common.ResourceApplication, privateApp.ID, true},
+		{common.ResourceApplication, subApp.ID, true},
+	} {
+		isPrivate, err := visibilityChecker.IsPrivate(ctx, c.resourceType, c.resourceID)
+		assert.Nil(t, err)
+		assert.Equal(t, c.private, isPrivate, "%s/%d", c.resourceType, c.resourceID)
+	}
+
+	var groupMember, appMember, outsider uint = 1, 2, 3
+	for _, member := range []*membermodels.Member{
+		{ResourceType: membermodels.TypeGroup, ResourceID: private.ID, MemberNameID: groupMember},
+		{ResourceType: membermodels.TypeApplication, ResourceID: privateApp.ID, MemberNameID: appMember},
+	} {
+		member.Role = "guest"
+		member.MemberType = membermodels.MemberUser
+		_, err := memberMgr.Create(ctx, member)
+		assert.Nil(t, err)
+	}
+
+	invisible, err := visibilityChecker.ListInvisible(ctx, &user.DefaultInfo{ID: groupMember})
+	assert.Nil(t, err)
+	assert.Empty(t, invisible.Groups)
+	assert.True(t, invisible.HasApplication(privateApp.ID))
+
+	invisible, err = visibilityChecker.ListInvisible(ctx, &user.DefaultInfo{ID: appMember})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []uint{private.ID, sub.ID}, invisible.GroupIDs())
+	assert.Empty(t, invisible.Applications)
+
+	invisible, err = visibilityChecker.ListInvisible(ctx, &user.DefaultInfo{ID: outsider})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []uint{private.ID, sub.ID}, invisible.GroupIDs())
+	assert.ElementsMatch(t, []uint{privateApp.ID}, invisible.ApplicationIDs())
+
+	invisible, err = visibilityChecker.ListInvisible(ctx, &user.DefaultInfo{ID: outsider, Admin: true})
+	assert.Nil(t, err)
+	assert.Empty(t, invisible.Groups)
+	assert.Empty(t, invisible.Applications)
+
+	// groups not found are private
+	isPrivate, err := visibilityChecker.IsPrivate(ctx, common.ResourceGroup, 10000)
+	assert.Nil(t, err)
+	assert.True(t, isPrivate)
+
+	// visibility of resources is read through, while the list cache is dropped after the visibility changed
+	private.VisibilityLevel = Public
+	assert.Nil(t, groupMgr.UpdateBasic(ctx, private))
+	isPrivate, err = visibilityChecker.IsPrivate(ctx, common.ResourceGroup, sub.ID)
+	assert.Nil(t, err)
+	assert.False(t, isPrivate)
+	invisible, err = visibilityChecker.ListInvisible(ctx, &user.DefaultInfo{ID: outsider})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []uint{private.ID, sub.ID}, invisible.GroupIDs())
+	visibilityChecker.Invalidate()
+	invisible, err = visibilityChecker.ListInvisible(ctx, &user.DefaultInfo{ID: outsider})
+	assert.Nil(t, err)
+	assert.Empty(t, invisible.Groups)
+
+	assert.Nil(t, Validate(""))
+	assert.Nil(t, Validate(Private))
+	assert.NotNil(t, Validate("internal"))
+}
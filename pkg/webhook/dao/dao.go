@@ -17,6 +17,7 @@ package dao
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -41,6 +42,9 @@ type DAO interface {
 		resources map[string][]uint) ([]*models.WebhookLogWithEventInfo, int64, error)
 	ListWebhookLogsByStatus(ctx context.Context, wID uint,
 		status string) ([]*models.WebhookLog, error)
+	ListWebhookLogsToSend(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error)
+	ListFinishedWebhookLogs(ctx context.Context, wID uint, since time.Time) ([]*models.WebhookLog, error)
+	UpdateWebhookDeliveryState(ctx context.Context, id uint, consecutiveFailures uint, enabled bool) error
 	ListWebhookLogsByMap(ctx context.Context,
 		webhookEventMap map[uint][]uint) ([]*models.WebhookLog, error)
 	UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error)
@@ -129,7 +133,8 @@ func (d *dao) ListWebhookOfResources(ctx context.Context,
 func (d *dao) UpdateWebhook(ctx context.Context, id uint,
 	w *models.Webhook) (*models.Webhook, error) {
 	if result := d.db.WithContext(ctx).Where("id = ?", id).
-		Select("enabled", "url", "enable_ssl_verify", "description", "secret", "triggers").
+		Select("enabled", "url", "enable_ssl_verify", "description", "secret", "triggers",
+			"format", "max_attempts", "consecutive_failures").
 		Updates(w); result.Error != nil {
 		return nil, herrors.NewErrUpdateFailed(herrors.WebhookInDB, result.Error.Error())
 	}
@@ -249,10 +254,50 @@ func (d *dao) ListWebhookLogsByStatus(ctx context.Context, wID uint,
 	return ws, nil
 }
 
+// ListWebhookLogsToSend lists waiting logs and retrying logs whose retry time has come
+func (d *dao) ListWebhookLogsToSend(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error) {
+	var ws []*models.WebhookLog
+	if result := d.db.WithContext(ctx).Where("webhook_id = ?", wID).
+		Where(d.db.Where("status = ?", models.StatusWaiting).
+			Or("status = ? and next_retry_at <= ?", models.StatusRetrying, now)).
+		Order("id").
+		Find(&ws); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.WebhookLogInDB, result.Error.Error())
+	}
+	return ws, nil
+}
+
+// ListFinishedWebhookLogs lists logs which are succeeded or dead since the specified time
+func (d *dao) ListFinishedWebhookLogs(ctx context.Context, wID uint,
+	since time.Time) ([]*models.WebhookLog, error) {
+	var ws []*models.WebhookLog
+	if result := d.db.WithContext(ctx).Select("id", "status", "attempts", "latency_ms").
+		Where("webhook_id = ?", wID).
+		Where("status in ?", []string{models.StatusSuccess, models.StatusDead}).
+		Where("updated_at >= ?", since).
+		Find(&ws); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.WebhookLogInDB, result.Error.Error())
+	}
+	return ws, nil
+}
+
+func (d *dao) UpdateWebhookDeliveryState(ctx context.Context, id uint,
+	consecutiveFailures uint, enabled bool) error {
+	if result := d.db.WithContext(ctx).Model(&models.Webhook{ID: id}).
+		Select("consecutive_failures", "enabled").
+		Updates(&models.Webhook{
+			ConsecutiveFailures: consecutiveFailures,
+			Enabled:             enabled,
+		}); result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.WebhookInDB, result.Error.Error())
+	}
+	return nil
+}
+
 func (d *dao) UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error) {
 	if result := d.db.WithContext(ctx).Where("id = ?", wl.ID).
 		Select("status", "response_headers", "response_body",
-			"status", "error_message").
+			"status", "error_message", "attempts", "next_retry_at", "latency_ms").
 		Updates(wl); result.Error != nil {
 		return nil, herrors.NewErrUpdateFailed(herrors.WebhookLogInDB, result.Error.Error())
 	}
@@ -16,6 +16,9 @@ package manager
 
 import (
 	"context"
+	"math"
+	"sort"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -41,6 +44,12 @@ type Manager interface {
 		webhookEventMap map[uint][]uint) ([]*models.WebhookLog, error)
 	ListWebhookLogsByStatus(ctx context.Context, wID uint,
 		status string) ([]*models.WebhookLog, error)
+	// ListWebhookLogsToSend lists waiting logs and retrying logs whose retry time has come
+	ListWebhookLogsToSend(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error)
+	// UpdateWebhookDeliveryState records consecutive failures of a webhook, and disables it if not enabled
+	UpdateWebhookDeliveryState(ctx context.Context, id uint, consecutiveFailures uint, enabled bool) error
+	// GetWebhookStats calculates delivery statistics of logs finished since the specified time
+	GetWebhookStats(ctx context.Context, id uint, since time.Time) (*models.WebhookStats, error)
 	UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error)
 	GetWebhookLog(ctx context.Context, id uint) (*models.WebhookLog, error)
 	ResendWebhook(ctx context.Context, id uint) (*models.WebhookLog, error)
@@ -129,6 +138,56 @@ func (m *manager) ListWebhookLogsByStatus(ctx context.Context, wID uint,
 	return m.dao.ListWebhookLogsByStatus(ctx, wID, status)
 }
 
+func (m *manager) ListWebhookLogsToSend(ctx context.Context, wID uint,
+	now time.Time) ([]*models.WebhookLog, error) {
+	return m.dao.ListWebhookLogsToSend(ctx, wID, now)
+}
+
+func (m *manager) UpdateWebhookDeliveryState(ctx context.Context, id uint,
+	consecutiveFailures uint, enabled bool) error {
+	const op = "webhook manager: update webhook delivery state"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateWebhookDeliveryState(ctx, id, consecutiveFailures, enabled)
+}
+
+func (m *manager) GetWebhookStats(ctx context.Context, id uint, since time.Time) (*models.WebhookStats, error) {
+	const op = "webhook manager: get webhook stats"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	webhook, err := m.dao.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	wls, err := m.dao.ListFinishedWebhookLogs(ctx, id, since)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.WebhookStats{
+		Since:               since,
+		Total:               uint(len(wls)),
+		ConsecutiveFailures: webhook.ConsecutiveFailures,
+	}
+	latencies := make([]uint, 0, len(wls))
+	for _, wl := range wls {
+		if wl.Status == models.StatusSuccess {
+			stats.Succeeded++
+		} else {
+			stats.Dead++
+		}
+		latencies = append(latencies, wl.LatencyMs)
+	}
+	if stats.Total == 0 {
+		return stats, nil
+	}
+	stats.SuccessRate = float64(stats.Succeeded) / float64(stats.Total)
+	// nearest-rank percentile
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	rank := int(math.Ceil(0.95 * float64(len(latencies))))
+	stats.P95LatencyMs = latencies[rank-1]
+	return stats, nil
+}
+
 func (m *manager) UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error) {
 	const op = "webhook manager: update  webhook log"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -20,6 +20,20 @@ const (
 	StatusWaiting = "waiting"
 	StatusSuccess = "success"
 	StatusFailed  = "failed"
+	// StatusRetrying means the last attempt failed and the log will be sent again at NextRetryAt
+	StatusRetrying = "retrying"
+	// StatusDead means all attempts failed, the log will not be sent automatically any more
+	StatusDead = "dead"
+)
+
+// formats of webhook request body
+const (
+	// FormatJSON sends MessageContent as json, it's the default format
+	FormatJSON = "json"
+	// FormatCloudEventsStructured sends CloudEvents 1.0 in structured content mode
+	FormatCloudEventsStructured = "cloudevents-structured"
+	// FormatCloudEventsBinary sends CloudEvents 1.0 in binary content mode
+	FormatCloudEventsBinary = "cloudevents-binary"
 )
 
 type Webhook struct {
@@ -32,10 +46,16 @@ type Webhook struct {
 	Triggers         string
 	ResourceType     string
 	ResourceID       uint
-	CreatedAt        time.Time
-	CreatedBy        uint
-	UpdatedAt        time.Time
-	UpdatedBy        uint
+	// Format is the format of request body, empty means FormatJSON
+	Format string
+	// MaxAttempts is the max attempts to send a log, 0 means using the default of webhook service
+	MaxAttempts uint
+	// ConsecutiveFailures is the number of failed attempts since last success
+	ConsecutiveFailures uint
+	CreatedAt           time.Time
+	CreatedBy           uint
+	UpdatedAt           time.Time
+	UpdatedBy           uint
 }
 
 type WebhookLog struct {
@@ -49,9 +69,28 @@ type WebhookLog struct {
 	ResponseBody    string
 	Status          string
 	ErrorMessage    string
-	CreatedAt       time.Time
-	CreatedBy       uint
-	UpdatedAt       time.Time
+	// Attempts is the number of attempts made to send the log
+	Attempts    uint
+	NextRetryAt *time.Time
+	// LatencyMs is the latency of the last attempt in milliseconds
+	LatencyMs uint
+	CreatedAt time.Time
+	CreatedBy uint
+	UpdatedAt time.Time
+}
+
+// WebhookStats is delivery statistics of a webhook
+type WebhookStats struct {
+	Since time.Time
+	// Total is the number of logs finished, succeeded or dead
+	Total     uint
+	Succeeded uint
+	Dead      uint
+	// SuccessRate is Succeeded / Total, 0 if Total is 0
+	SuccessRate float64
+	// P95LatencyMs is the 95th percentile latency of the last attempt of logs
+	P95LatencyMs        uint
+	ConsecutiveFailures uint
 }
 
 type WebhookLogWithEventInfo struct {
@@ -23,6 +23,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -39,11 +40,16 @@ import (
 	webhookmanager "github.com/horizoncd/horizon/pkg/webhook/manager"
 	"github.com/horizoncd/horizon/pkg/webhook/models"
 	webhookmodels "github.com/horizoncd/horizon/pkg/webhook/models"
+	"github.com/horizoncd/horizon/pkg/webhook/signature"
 )
 
 type worker struct {
 	idleWaitInterval         uint
 	responseBodyTruncateSize uint
+	maxAttempts              uint
+	retryBackoffBase         time.Duration
+	retryBackoffMax          time.Duration
+	disableThreshold         uint
 
 	ctx            context.Context
 	insecureClient http.Client
@@ -131,8 +137,7 @@ func (s *service) reconcileWorkers() {
 		} else {
 			// 2.2 create workers
 			s.workers[id] = newWebhookWorker(s.webhookManager, s.eventManager,
-				s.userManager, webhook, s.config.IdleWaitInterval,
-				s.config.ClientTimeout, s.config.ResponseBodyTruncateSize)
+				s.userManager, webhook, s.config)
 		}
 		reconciled[id] = true
 	}
@@ -182,15 +187,18 @@ func (s *service) StopAndWait() {
 
 func newWebhookWorker(webhookMgr webhookmanager.Manager,
 	eventMgr eventmanager.Manager, userMgr usermanager.Manager,
-	webhook *models.Webhook, idleWaitInterval uint,
-	clientTimeout, responseBodyTruncateSize uint) *worker {
+	webhook *models.Webhook, config webhookconfig.Config) *worker {
 	ww := &worker{
-		idleWaitInterval:         idleWaitInterval,
-		responseBodyTruncateSize: responseBodyTruncateSize,
+		idleWaitInterval:         config.IdleWaitInterval,
+		responseBodyTruncateSize: config.ResponseBodyTruncateSize,
+		maxAttempts:              config.MaxAttempts,
+		retryBackoffBase:         time.Second * time.Duration(config.RetryBackoffBase),
+		retryBackoffMax:          time.Second * time.Duration(config.RetryBackoffMax),
+		disableThreshold:         config.DisableThreshold,
 		ctx:                      context.Background(),
 		quit:                     make(chan bool, 1),
 		insecureClient: http.Client{
-			Timeout: time.Second * time.Duration(clientTimeout),
+			Timeout: time.Second * time.Duration(config.ClientTimeout),
 			Transport: &http.Transport{
 				TLSClientConfig: &tls.Config{
 					InsecureSkipVerify: true,
@@ -198,7 +206,7 @@ func newWebhookWorker(webhookMgr webhookmanager.Manager,
 			},
 		},
 		secureClient: http.Client{
-			Timeout: time.Second * time.Duration(clientTimeout),
+			Timeout: time.Second * time.Duration(config.ClientTimeout),
 		},
 		webhookManager: webhookMgr,
 		eventManager:   eventMgr,
@@ -210,10 +218,34 @@ func newWebhookWorker(webhookMgr webhookmanager.Manager,
 }
 
 func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models.WebhookLog {
-	// 1. make request and set body
-	reqBody, err := addWebhookLogID([]byte(wl.RequestData), wl.ID)
+	// 0. reset result of last attempt
+	wl.Attempts++
+	wl.ErrorMessage = ""
+	wl.ResponseHeaders = ""
+	wl.ResponseBody = ""
+	wl.LatencyMs = 0
+
+	webhook, err := w.getWebhook()
 	if err != nil {
-		wl.ErrorMessage = fmt.Sprintf("failed to add id, error: %+v", err)
+		log.Error(ctx, err)
+		wl.ErrorMessage = err.Error()
+		return wl
+	}
+
+	// 1. make headers
+	headers := http.Header{}
+	if err := yaml.Unmarshal([]byte(wl.RequestHeaders), &headers); err != nil {
+		wl.ErrorMessage = fmt.Sprintf("failed to unmarshal header, error: %+v", err)
+		log.Errorf(ctx, wl.ErrorMessage)
+		return wl
+	}
+	// logs created before signing was introduced carry the secret in plaintext
+	headers.Del(wlgenerator.WebhookSecretHeader)
+
+	// 2. make body in the format of webhook
+	reqBody, err := encodeRequestBody(webhook.Format, wl, headers)
+	if err != nil {
+		wl.ErrorMessage = fmt.Sprintf("failed to encode body, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
 		return wl
 	}
@@ -225,28 +257,20 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 		return wl
 	}
 
-	// 2. set headers
-	headers := http.Header{}
-	if err := yaml.Unmarshal([]byte(wl.RequestHeaders), &headers); err != nil {
-		wl.ErrorMessage = fmt.Sprintf("failed to unmarshal header, error: %+v", err)
-		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
+	// 3. sign and send request
+	headers.Set(signature.HeaderDelivery, strconv.FormatUint(uint64(wl.ID), 10))
+	if webhook.Secret != "" {
+		signature.SetHeaders(headers, webhook.Secret, reqBody, time.Now())
 	}
 	req.Header = headers
 
-	// 3. send request
 	cli := w.secureClient
-	webhook, err := w.getWebhook()
-	if err != nil {
-		log.Error(ctx, err)
-		wl.ErrorMessage = err.Error()
-		return wl
-	}
-
 	if !webhook.SSLVerifyEnabled {
 		cli = w.insecureClient
 	}
+	begin := time.Now()
 	resp, err := cli.Do(req)
+	wl.LatencyMs = uint(time.Since(begin).Milliseconds())
 	if err != nil {
 		wl.ErrorMessage = fmt.Sprintf("failed to send req, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
@@ -297,42 +321,105 @@ L:
 			close(w.quit)
 			break L
 		default:
-			// TODO: set limit and find a way to avoid this invoke
-			webhook, err := w.getWebhook()
-			if err != nil {
-				log.Error(ctx, err)
-				continue
-			}
-			wls, err := w.webhookManager.ListWebhookLogsByStatus(ctx, webhook.ID,
-				webhookmodels.StatusWaiting)
-			if err != nil {
-				log.Errorf(ctx, "failed to list webhook logs of %d, error: %s", webhook.ID, err.Error())
-				continue
-			}
-			if len(wls) == 0 {
+			if !w.process(ctx) {
 				time.Sleep(time.Second * time.Duration(w.idleWaitInterval))
-				continue
-			}
-			for _, wl := range wls {
-				saveResult := func() {
-					if wl.ErrorMessage != "" {
-						wl.Status = webhookmodels.StatusFailed
-					} else {
-						wl.Status = webhookmodels.StatusSuccess
-					}
-					_, err := w.webhookManager.UpdateWebhookLog(ctx, wl)
-					if err != nil {
-						log.Errorf(ctx, "failed to update webhook log %d, error: %s", wl.ID, err.Error())
-					}
-				}
-
-				wl = w.sendWebhook(ctx, wl)
-				saveResult()
 			}
 		}
 	}
 }
 
+// process sends logs which are due, returns false if there is nothing to send
+func (w *worker) process(ctx context.Context) bool {
+	// TODO: set limit and find a way to avoid this invoke
+	webhook, err := w.getWebhook()
+	if err != nil {
+		log.Error(ctx, err)
+		return false
+	}
+	// logs of disabled webhook are kept until it is enabled again
+	if !webhook.Enabled {
+		return false
+	}
+	wls, err := w.webhookManager.ListWebhookLogsToSend(ctx, webhook.ID, time.Now())
+	if err != nil {
+		log.Errorf(ctx, "failed to list webhook logs of %d, error: %s", webhook.ID, err.Error())
+		return false
+	}
+	if len(wls) == 0 {
+		return false
+	}
+	for _, wl := range wls {
+		wl = w.sendWebhook(ctx, wl)
+		w.saveResult(ctx, wl)
+	}
+	return true
+}
+
+// saveResult updates status of the log according to the attempt result,
+// and disables the webhook if it keeps failing
+func (w *worker) saveResult(ctx context.Context, wl *models.WebhookLog) {
+	webhook, err := w.getWebhook()
+	if err != nil {
+		log.Error(ctx, err)
+		return
+	}
+
+	failures := webhook.ConsecutiveFailures
+	wl.NextRetryAt = nil
+	if wl.ErrorMessage == "" {
+		wl.Status = webhookmodels.StatusSuccess
+		failures = 0
+	} else {
+		failures++
+		if wl.Attempts >= w.getMaxAttempts(webhook) {
+			wl.Status = webhookmodels.StatusDead
+		} else {
+			wl.Status = webhookmodels.StatusRetrying
+			nextRetryAt := time.Now().Add(w.backoff(wl.Attempts))
+			wl.NextRetryAt = &nextRetryAt
+		}
+	}
+	if _, err := w.webhookManager.UpdateWebhookLog(ctx, wl); err != nil {
+		log.Errorf(ctx, "failed to update webhook log %d, error: %s", wl.ID, err.Error())
+	}
+
+	if failures == webhook.ConsecutiveFailures {
+		return
+	}
+	enabled := webhook.Enabled
+	if enabled && failures >= w.disableThreshold {
+		log.Warningf(ctx, "webhook %d is disabled after %d consecutive failures", webhook.ID, failures)
+		enabled = false
+	}
+	if err := w.webhookManager.UpdateWebhookDeliveryState(ctx, webhook.ID, failures, enabled); err != nil {
+		log.Errorf(ctx, "failed to update delivery state of webhook %d, error: %s", webhook.ID, err.Error())
+		return
+	}
+	updated := *webhook
+	updated.ConsecutiveFailures = failures
+	updated.Enabled = enabled
+	w.setWebhook(&updated)
+}
+
+func (w *worker) getMaxAttempts(webhook *models.Webhook) uint {
+	if webhook.MaxAttempts > 0 {
+		return webhook.MaxAttempts
+	}
+	return w.maxAttempts
+}
+
+// backoff returns duration to wait before next attempt, doubled for each failed attempt
+func (w *worker) backoff(attempts uint) time.Duration {
+	d := w.retryBackoffBase
+	for i := uint(1); i < attempts && d < w.retryBackoffMax; i++ {
+		d *= 2
+	}
+	if d > w.retryBackoffMax {
+		d = w.retryBackoffMax
+	}
+	return d
+}
+
 func (w *worker) Stop() *worker {
 	w.quit <- true
 	return w
@@ -347,13 +434,21 @@ func (w *worker) Wait() {
 	log.Infof(w.ctx, "webhook worker %d stopped", webhook.ID)
 }
 
-func addWebhookLogID(reqData []byte, id uint) ([]byte, error) {
+// encodeRequestBody sets id of the log to the message and encodes it in format
+func encodeRequestBody(format string, wl *models.WebhookLog, headers http.Header) ([]byte, error) {
 	var content wlgenerator.MessageContent
-	err := json.Unmarshal([]byte(reqData), &content)
+	err := json.Unmarshal([]byte(wl.RequestData), &content)
 	if err != nil {
 		return nil, err
 	}
-
-	content.ID = id
+	content.ID = wl.ID
+
+	switch format {
+	case models.FormatCloudEventsStructured:
+		headers.Set(wlgenerator.WebhookContentTypeHeader, wlgenerator.CloudEventContentType)
+		return json.Marshal(wlgenerator.NewCloudEvent(&content, wl.CreatedAt))
+	case models.FormatCloudEventsBinary:
+		wlgenerator.NewCloudEvent(&content, wl.CreatedAt).SetBinaryHeaders(headers)
+	}
 	return json.Marshal(content)
 }
@@ -0,0 +1,191 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/eventhandler/wlgenerator"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/webhook/models"
+	"github.com/horizoncd/horizon/pkg/webhook/signature"
+)
+
+func TestWorker(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	assert.Nil(t, db.AutoMigrate(&models.Webhook{}, &models.WebhookLog{}))
+	manager := managerparam.InitManager(db)
+	ctx := context.Background()
+
+	statusCode := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	webhook, err := manager.WebhookManager.CreateWebhook(ctx, &models.Webhook{
+		Enabled:     true,
+		URL:         server.URL,
+		MaxAttempts: 2,
+	})
+	assert.Nil(t, err)
+	w := &worker{
+		maxAttempts:      5,
+		retryBackoffBase: time.Second,
+		retryBackoffMax:  time.Minute,
+		disableThreshold: 3,
+		webhookManager:   manager.WebhookManager,
+	}
+	w.setWebhook(webhook)
+
+	assert.Equal(t, time.Second, w.backoff(1))
+	assert.Equal(t, 4*time.Second, w.backoff(3))
+	assert.Equal(t, time.Minute, w.backoff(10))
+
+	newLog := func() *models.WebhookLog {
+		wl, err := manager.WebhookManager.CreateWebhookLog(ctx, &models.WebhookLog{
+			WebhookID:   webhook.ID,
+			URL:         server.URL,
+			RequestData: "{}",
+			Status:      models.StatusWaiting,
+		})
+		assert.Nil(t, err)
+		return wl
+	}
+	wl := newLog()
+
+	// first attempt failed, retry later
+	assert.True(t, w.process(ctx))
+	wl, err = manager.WebhookManager.GetWebhookLog(ctx, wl.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusRetrying, wl.Status)
+	assert.Equal(t, uint(1), wl.Attempts)
+	assert.NotNil(t, wl.NextRetryAt)
+	assert.False(t, w.process(ctx))
+
+	// retry is due, the last attempt failed
+	wls, err := manager.WebhookManager.ListWebhookLogsToSend(ctx, webhook.ID, time.Now().Add(time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(wls))
+	w.saveResult(ctx, w.sendWebhook(ctx, wls[0]))
+	wl, err = manager.WebhookManager.GetWebhookLog(ctx, wl.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusDead, wl.Status)
+	assert.Equal(t, uint(2), wl.Attempts)
+	assert.Nil(t, wl.NextRetryAt)
+
+	// success resets consecutive failures
+	statusCode = http.StatusOK
+	wl = newLog()
+	assert.True(t, w.process(ctx))
+	wl, err = manager.WebhookManager.GetWebhookLog(ctx, wl.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusSuccess, wl.Status)
+	webhook, err = manager.WebhookManager.GetWebhook(ctx, webhook.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(0), webhook.ConsecutiveFailures)
+
+	// webhook keeps failing is disabled
+	statusCode = http.StatusBadGateway
+	for i := 0; i < 3; i++ {
+		newLog()
+	}
+	assert.True(t, w.process(ctx))
+	webhook, err = manager.WebhookManager.GetWebhook(ctx, webhook.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(3), webhook.ConsecutiveFailures)
+	assert.False(t, webhook.Enabled)
+	assert.False(t, w.process(ctx))
+
+	stats, err := manager.WebhookManager.GetWebhookStats(ctx, webhook.ID, time.Now().Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), stats.Total)
+	assert.Equal(t, uint(1), stats.Succeeded)
+	assert.Equal(t, uint(1), stats.Dead)
+	assert.Equal(t, 0.5, stats.SuccessRate)
+	assert.Equal(t, uint(3), stats.ConsecutiveFailures)
+}
+
+func TestSendWebhookSigned(t *testing.T) {
+	var (
+		header http.Header
+		body   []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	createdAt := time.Now()
+	wl := &models.WebhookLog{
+		ID:             1,
+		WebhookID:      1,
+		URL:            server.URL,
+		RequestHeaders: "X-Horizon-Webhook-Secret:\n    - secret\nContent-Type:\n    - application/json;charset=utf-8\n",
+		RequestData:    `{"eventType":"clusters_created","cluster":{"id":2,"name":"horizon-demo-dev"}}`,
+		CreatedAt:      createdAt,
+	}
+	webhook := &models.Webhook{ID: 1, URL: server.URL, Secret: "secret"}
+	w := &worker{responseBodyTruncateSize: 1024}
+	w.setWebhook(webhook)
+
+	// json
+	wl = w.sendWebhook(context.Background(), wl)
+	assert.Equal(t, "", wl.ErrorMessage)
+	assert.Equal(t, "", header.Get(wlgenerator.WebhookSecretHeader))
+	assert.Equal(t, "1", header.Get(signature.HeaderDelivery))
+	assert.Nil(t, signature.Verify(header, "secret", body, signature.DefaultTolerance, time.Now()))
+	var content wlgenerator.MessageContent
+	assert.Nil(t, json.Unmarshal(body, &content))
+	assert.Equal(t, uint(1), content.ID)
+
+	// cloudevents in structured mode
+	webhook.Format = models.FormatCloudEventsStructured
+	wl = w.sendWebhook(context.Background(), wl)
+	assert.Equal(t, "", wl.ErrorMessage)
+	assert.Equal(t, wlgenerator.CloudEventContentType, header.Get("Content-Type"))
+	assert.Nil(t, signature.Verify(header, "secret", body, signature.DefaultTolerance, time.Now()))
+	var ce wlgenerator.CloudEvent
+	assert.Nil(t, json.Unmarshal(body, &ce))
+	assert.Equal(t, "1.0", ce.SpecVersion)
+	assert.Equal(t, "1", ce.ID)
+	assert.Equal(t, "/horizon/clusters/2", ce.Source)
+	assert.Equal(t, "io.horizoncd.horizon.clusters_created", ce.Type)
+	assert.Equal(t, "horizon-demo-dev", ce.Subject)
+	assert.Equal(t, "horizon-demo-dev", ce.Data.Cluster.Name)
+
+	// cloudevents in binary mode
+	webhook.Format = models.FormatCloudEventsBinary
+	wl = w.sendWebhook(context.Background(), wl)
+	assert.Equal(t, "", wl.ErrorMessage)
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, "1.0", header.Get("Ce-Specversion"))
+	assert.Equal(t, "/horizon/clusters/2", header.Get("Ce-Source"))
+	assert.Equal(t, "io.horizoncd.horizon.clusters_created", header.Get("Ce-Type"))
+	assert.Equal(t, createdAt.UTC().Format(time.RFC3339Nano), header.Get("Ce-Time"))
+	assert.Nil(t, json.Unmarshal(body, &content))
+	assert.Equal(t, "horizon-demo-dev", content.Cluster.Name)
+	assert.Equal(t, uint(3), wl.Attempts)
+}
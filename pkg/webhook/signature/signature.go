@@ -0,0 +1,88 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signature signs webhook deliveries and verifies them on the receiver side.
+//
+// The signature is hex encoded HMAC-SHA256 of "<timestamp>.<body>" keyed by the
+// webhook secret, sent as "X-Horizon-Webhook-Signature: sha256=<signature>" together
+// with the unix timestamp in "X-Horizon-Webhook-Timestamp". Receivers should reject
+// deliveries with a stale timestamp, and deliveries whose "X-Horizon-Webhook-Delivery"
+// has been seen before, to protect against replays.
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-Horizon-Webhook-Signature"
+	HeaderTimestamp = "X-Horizon-Webhook-Timestamp"
+	// HeaderDelivery is the unique id of a delivery, it keeps the same when the delivery is retried
+	HeaderDelivery = "X-Horizon-Webhook-Delivery"
+
+	// DefaultTolerance is the max age of a delivery accepted by Verify
+	DefaultTolerance = 5 * time.Minute
+
+	_signaturePrefix = "sha256="
+)
+
+var (
+	ErrSignatureMissing  = errors.New("webhook signature missing")
+	ErrSignatureMismatch = errors.New("webhook signature mismatch")
+	ErrTimestampExpired  = errors.New("webhook timestamp expired")
+)
+
+// Sign returns the value of signature header of the body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return _signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetHeaders signs the body at now and sets signature headers
+func SetHeaders(header http.Header, secret string, body []byte, now time.Time) {
+	timestamp := now.Unix()
+	header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	header.Set(HeaderSignature, Sign(secret, timestamp, body))
+}
+
+// Verify checks the signature headers of a delivery received at now,
+// deliveries older than tolerance are rejected.
+func Verify(header http.Header, secret string, body []byte, tolerance time.Duration, now time.Time) error {
+	sig, ts := header.Get(HeaderSignature), header.Get(HeaderTimestamp)
+	if sig == "" || ts == "" || !strings.HasPrefix(sig, _signaturePrefix) {
+		return ErrSignatureMissing
+	}
+	timestamp, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid webhook timestamp %s: %w", ts, ErrSignatureMismatch)
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return ErrTimestampExpired
+	}
+	if !hmac.Equal([]byte(sig), []byte(Sign(secret, timestamp, body))) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	// echo -n '1700000000.{"id":1}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11",
+		Sign("secret", 1700000000, body))
+
+	now := time.Unix(1700000000, 0)
+	header := http.Header{}
+	assert.True(t, errors.Is(Verify(header, "secret", body, DefaultTolerance, now), ErrSignatureMissing))
+
+	SetHeaders(header, "secret", body, now)
+	assert.Nil(t, Verify(header, "secret", body, DefaultTolerance, now.Add(time.Minute)))
+	assert.True(t, errors.Is(Verify(header, "another", body, DefaultTolerance, now), ErrSignatureMismatch))
+	assert.True(t, errors.Is(Verify(header, "secret", []byte(`{"id":2}`), DefaultTolerance, now),
+		ErrSignatureMismatch))
+	assert.True(t, errors.Is(Verify(header, "secret", body, DefaultTolerance, now.Add(time.Hour)),
+		ErrTimestampExpired))
+}
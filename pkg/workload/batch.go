@@ -0,0 +1,70 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+// BatchReplicas splits replicas into batches of nearly equal size
+func BatchReplicas(replicas, batches int) []int {
+	if replicas < 1 {
+		return []int{replicas}
+	}
+	if batches > replicas {
+		batches = replicas
+	}
+	if batches < 1 {
+		batches = 1
+	}
+	result := make([]int, 0, batches)
+	released := 0
+	for i := 1; i <= batches; i++ {
+		sum := (i*replicas + batches - 1) / batches
+		result = append(result, sum-released)
+		released = sum
+	}
+	return result
+}
+
+// PartitionStep gets the step of workload released in batches by partition,
+// pods except the partition ones are updated, so the partition is decreased batch by batch
+func PartitionStep(replicas, partition, batches int) *Step {
+	replicasList := BatchReplicas(replicas, batches)
+	released := replicas - partition
+	index := 0
+	for i, sum := 0, 0; i < len(replicasList); i++ {
+		sum += replicasList[i]
+		if sum > released {
+			break
+		}
+		index++
+	}
+	return &Step{
+		Index:    index,
+		Total:    len(replicasList),
+		Replicas: replicasList,
+		// the release waits for promotion after every batch
+		StepPaused: index > 0 && index < len(replicasList),
+	}
+}
+
+// NextPartition returns the partition to release the next batch
+func NextPartition(replicas, partition, batches int) int {
+	released := replicas - partition
+	for i, sum, replicasList := 0, 0, BatchReplicas(replicas, batches); i < len(replicasList); i++ {
+		sum += replicasList[i]
+		if sum > released {
+			return replicas - sum
+		}
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchReplicas(t *testing.T) {
+	assert.Equal(t, []int{5}, BatchReplicas(5, 1))
+	assert.Equal(t, []int{3, 2}, BatchReplicas(5, 2))
+	assert.Equal(t, []int{1, 1, 1}, BatchReplicas(3, 3))
+	assert.Equal(t, []int{1, 1}, BatchReplicas(2, 5))
+	assert.Equal(t, []int{0}, BatchReplicas(0, 3))
+}
+
+func TestPartitionStep(t *testing.T) {
+	step := PartitionStep(6, 4, 3)
+	assert.Equal(t, []int{2, 2, 2}, step.Replicas)
+	assert.Equal(t, 1, step.Index)
+	assert.True(t, step.StepPaused)
+	step = PartitionStep(6, 0, 3)
+	assert.Equal(t, 3, step.Index)
+	assert.False(t, step.StepPaused)
+	step = PartitionStep(6, 6, 3)
+	assert.Equal(t, 0, step.Index)
+	assert.False(t, step.StepPaused)
+
+	assert.Equal(t, 4, NextPartition(6, 6, 3))
+	assert.Equal(t, 2, NextPartition(6, 4, 3))
+	assert.Equal(t, 0, NextPartition(6, 2, 3))
+	assert.Equal(t, 0, NextPartition(6, 0, 3))
+}
@@ -0,0 +1,205 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronjob
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+	"github.com/horizoncd/horizon/pkg/workload/job"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+const (
+	// _instantiateAnnotation is the same as kubectl create job --from=cronjob/name
+	_instantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+	// _maxNameLength keeps the job-name label of pods valid
+	_maxNameLength = 63
+)
+
+func init() {
+	workload.Register(ability)
+}
+
+// please refer to github.com/horizoncd/horizon/pkg/cluster/cd/workload/workload.go
+var ability = &cronJob{}
+
+type cronJob struct{}
+
+func (*cronJob) MatchGK(gk schema.GroupKind) bool {
+	return gk.Group == "batch" && gk.Kind == "CronJob"
+}
+
+// getCronJobByNode gets cronjob by the version of node, both batch/v1beta1 and batch/v1 are supported
+func getCronJobByNode(node *v1alpha1.ResourceNode,
+	client *kube.Client) (*batchv1beta1.CronJob, *unstructured.Unstructured, error) {
+	gvr := schema.GroupVersionResource{
+		Group:    "batch",
+		Version:  node.Version,
+		Resource: "cronjobs",
+	}
+
+	un, err := client.Dynamic.Resource(gvr).Namespace(node.Namespace).
+		Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				"failed to get cronjob in k8s"),
+			"failed to get cronjob in k8s: cronjob = %s, ns = %v, err = %v", node.Name, node.Namespace, err)
+	}
+
+	var instance *batchv1beta1.CronJob
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(un.UnstructuredContent(), &instance)
+	if err != nil {
+		return nil, un, err
+	}
+	return instance, un, nil
+}
+
+// IsHealthy takes the cronjob as healthy unless the latest finished job has failed
+func (*cronJob) IsHealthy(node *v1alpha1.ResourceNode, client *kube.Client) (bool, error) {
+	jobs, err := ListJobs(node, client)
+	if err != nil {
+		return true, err
+	}
+	for i := range jobs {
+		if job.IsFailed(&jobs[i]) {
+			return false, nil
+		}
+		if job.IsComplete(&jobs[i]) {
+			return true, nil
+		}
+	}
+	return true, nil
+}
+
+func (*cronJob) ListPods(node *v1alpha1.ResourceNode, client *kube.Client) ([]corev1.Pod, error) {
+	jobs, err := ListJobs(node, client)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]corev1.Pod, 0)
+	for i := range jobs {
+		jobPods, err := job.ListPods(client, &jobs[i])
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, jobPods...)
+	}
+	return pods, nil
+}
+
+func (*cronJob) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	var suspend bool
+	switch actionName {
+	case "suspend":
+		suspend = true
+	case "resume":
+		suspend = false
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action: %v", actionName)
+	}
+	if err := unstructured.SetNestedField(un.Object, suspend, "spec", "suspend"); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to set suspend: %v", err)
+	}
+	return un, nil
+}
+
+// ListJobs lists jobs owned by the cronjob, the latest first
+func ListJobs(node *v1alpha1.ResourceNode, client *kube.Client) ([]batchv1.Job, error) {
+	instance, _, err := getCronJobByNode(node, client)
+	if err != nil {
+		return nil, err
+	}
+
+	jobList, err := client.Basic.BatchV1().Jobs(instance.Namespace).
+		List(context.TODO(), metav1.ListOptions{ResourceVersion: "0"})
+	if err != nil {
+		return nil, perror.Wrapf(herrors.NewErrGetFailed(herrors.ResourceInK8S, "failed to list jobs in k8s"),
+			"failed to list jobs of cronjob %s: %v", instance.Name, err)
+	}
+	jobs := make([]batchv1.Job, 0)
+	for _, item := range jobList.Items {
+		for _, ref := range item.OwnerReferences {
+			if ref.UID == instance.UID {
+				jobs = append(jobs, item)
+				break
+			}
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[j].CreationTimestamp.Before(&jobs[i].CreationTimestamp)
+	})
+	return jobs, nil
+}
+
+// Trigger creates a job from the template of the cronjob immediately, like kubectl create job --from=cronjob/name
+func Trigger(node *v1alpha1.ResourceNode, client *kube.Client) (*batchv1.Job, error) {
+	instance, un, err := getCronJobByNode(node, client)
+	if err != nil {
+		return nil, err
+	}
+
+	name := instance.Name
+	suffix := fmt.Sprintf("-manual-%s", rand.String(5))
+	if len(name)+len(suffix) > _maxNameLength {
+		name = name[:_maxNameLength-len(suffix)]
+	}
+	annotations := map[string]string{_instantiateAnnotation: "manual"}
+	for k, v := range instance.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+	isController := true
+	newJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name + suffix,
+			Namespace:   instance.Namespace,
+			Labels:      instance.Spec.JobTemplate.Labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: un.GetAPIVersion(),
+				Kind:       un.GetKind(),
+				Name:       instance.Name,
+				UID:        instance.UID,
+				Controller: &isController,
+			}},
+		},
+		Spec: instance.Spec.JobTemplate.Spec,
+	}
+	created, err := client.Basic.BatchV1().Jobs(instance.Namespace).Create(context.TODO(), newJob, metav1.CreateOptions{})
+	if err != nil {
+		return nil, perror.Wrapf(herrors.NewErrCreateFailed(herrors.ResourceInK8S, "failed to create job in k8s"),
+			"failed to create job from cronjob %s: %v", instance.Name, err)
+	}
+	return created, nil
+}
+
+// IsManual returns whether the job is triggered manually
+func IsManual(instance *batchv1.Job) bool {
+	return instance.Annotations[_instantiateAnnotation] == "manual"
+}
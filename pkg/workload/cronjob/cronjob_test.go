@@ -0,0 +1,129 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronjob
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newJob(name string, uid types.UID, created time.Time, condition batchv1.JobConditionType) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "test",
+			CreationTimestamp: metav1.NewTime(created),
+			OwnerReferences:   []metav1.OwnerReference{{Kind: "CronJob", Name: "app", UID: uid}},
+		},
+	}
+	if condition != "" {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue}}
+	}
+	return job
+}
+
+func TestCronJob(t *testing.T) {
+	cronJob := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1beta1",
+		"kind":       "CronJob",
+		"metadata": map[string]interface{}{
+			"name":      "app",
+			"namespace": "test",
+			"uid":       "cronjob-uid",
+		},
+		"spec": map[string]interface{}{
+			"schedule": "*/5 * * * *",
+			"jobTemplate": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"app": "app"},
+				},
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"containers": []interface{}{
+								map[string]interface{}{"name": "app", "image": "app:v1"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+	now := time.Now()
+	basic := fake.NewSimpleClientset(
+		newJob("app-1", "cronjob-uid", now.Add(-2*time.Hour), batchv1.JobComplete),
+		newJob("app-2", "cronjob-uid", now.Add(-time.Hour), batchv1.JobFailed),
+		newJob("other-1", "other-uid", now, ""),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "app-2-abcde", Namespace: "test", Labels: map[string]string{"job-name": "app-2"},
+		}},
+	)
+	client := &kube.Client{
+		Basic:   basic,
+		Dynamic: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), cronJob),
+	}
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+		Group: "batch", Version: "v1beta1", Kind: "CronJob", Namespace: "test", Name: "app",
+	}}
+
+	jobs, err := ListJobs(node, client)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(jobs))
+	assert.Equal(t, "app-2", jobs[0].Name)
+
+	// the latest finished job has failed
+	healthy, err := ability.IsHealthy(node, client)
+	assert.Nil(t, err)
+	assert.False(t, healthy)
+
+	pods, err := ability.ListPods(node, client)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pods))
+	assert.Equal(t, "app-2-abcde", pods[0].Name)
+
+	job, err := Trigger(node, client)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(job.Name, "app-manual-"))
+	assert.True(t, IsManual(job))
+	assert.Equal(t, "app", job.Labels["app"])
+	assert.Equal(t, types.UID("cronjob-uid"), job.OwnerReferences[0].UID)
+	assert.Equal(t, "batch/v1beta1", job.OwnerReferences[0].APIVersion)
+	assert.Equal(t, "app:v1", job.Spec.Template.Spec.Containers[0].Image)
+	_, err = basic.BatchV1().Jobs("test").Get(context.TODO(), job.Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+
+	un, err := ability.Action("suspend", cronJob.DeepCopy())
+	assert.Nil(t, err)
+	suspend, _, _ := unstructured.NestedBool(un.Object, "spec", "suspend")
+	assert.True(t, suspend)
+	_, err = ability.Action("promote", cronJob.DeepCopy())
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
@@ -0,0 +1,111 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemonset
+
+import (
+	"context"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+func init() {
+	workload.Register(ability)
+}
+
+// please refer to github.com/horizoncd/horizon/pkg/cluster/cd/workload/workload.go
+var ability = &daemonSet{}
+
+type daemonSet struct{}
+
+func (*daemonSet) MatchGK(gk schema.GroupKind) bool {
+	return gk.Group == "apps" && gk.Kind == "DaemonSet"
+}
+
+func (*daemonSet) getDaemonSetByNode(node *v1alpha1.ResourceNode,
+	client *kube.Client) (*appsv1.DaemonSet, error) {
+	instance, err := client.Basic.AppsV1().DaemonSets(node.Namespace).
+		Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				"failed to get daemonset in k8s"),
+			"failed to get daemonset in k8s: daemonset = %s, ns = %v, err = %v", node.Name, node.Namespace, err)
+	}
+	return instance, nil
+}
+
+func (d *daemonSet) IsHealthy(node *v1alpha1.ResourceNode,
+	client *kube.Client) (bool, error) {
+	instance, err := d.getDaemonSetByNode(node, client)
+	if err != nil {
+		return true, err
+	}
+
+	if instance.Status.ObservedGeneration != instance.Generation {
+		return false, nil
+	}
+	desired := instance.Status.DesiredNumberScheduled
+	return instance.Status.UpdatedNumberScheduled == desired && instance.Status.NumberAvailable == desired, nil
+}
+
+func (d *daemonSet) ListPods(node *v1alpha1.ResourceNode, client *kube.Client) ([]corev1.Pod, error) {
+	instance, err := d.getDaemonSetByNode(node, client)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := client.Basic.CoreV1().Pods(instance.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector:   polymorphichelpers.MakeLabels(instance.Spec.Selector.MatchLabels),
+		ResourceVersion: "0",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// GetSteps takes the rolling update of daemonset as one step, which is finished when all nodes are updated
+func (d *daemonSet) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*workload.Step, error) {
+	instance, err := d.getDaemonSetByNode(node, client)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := int(instance.Status.DesiredNumberScheduled)
+	index := 0
+	if instance.Status.ObservedGeneration == instance.Generation &&
+		int(instance.Status.UpdatedNumberScheduled) == desired {
+		index = 1
+	}
+	return &workload.Step{
+		Index:    index,
+		Total:    1,
+		Replicas: []int{desired},
+	}, nil
+}
+
+func (*daemonSet) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return un, nil
+}
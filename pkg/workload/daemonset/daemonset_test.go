@@ -0,0 +1,93 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemonset
+
+import (
+	"testing"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newDaemonSet(desired, updated, available int32) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "app",
+			Namespace:  "test",
+			Generation: 2,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}},
+		},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     2,
+			DesiredNumberScheduled: desired,
+			UpdatedNumberScheduled: updated,
+			NumberAvailable:        available,
+		},
+	}
+}
+
+func TestGetStepsAndIsHealthy(t *testing.T) {
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+		Group: "apps", Version: "v1", Kind: "DaemonSet", Namespace: "test", Name: "app",
+	}}
+	notObserved := newDaemonSet(3, 3, 3)
+	notObserved.Status.ObservedGeneration = 1
+	for _, c := range []struct {
+		instance *appsv1.DaemonSet
+		index    int
+		healthy  bool
+	}{
+		{instance: newDaemonSet(3, 3, 3), index: 1, healthy: true},
+		{instance: newDaemonSet(3, 1, 3), index: 0, healthy: false},
+		{instance: newDaemonSet(3, 3, 2), index: 1, healthy: false},
+		{instance: notObserved, index: 0, healthy: false},
+	} {
+		client := &kube.Client{Basic: fake.NewSimpleClientset(c.instance)}
+		step, err := ability.GetSteps(node, client)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, step.Total)
+		assert.Equal(t, []int{3}, step.Replicas)
+		assert.Equal(t, c.index, step.Index)
+
+		healthy, err := ability.IsHealthy(node, client)
+		assert.Nil(t, err)
+		assert.Equal(t, c.healthy, healthy)
+	}
+
+	_, err := ability.IsHealthy(node, &kube.Client{Basic: fake.NewSimpleClientset()})
+	assert.NotNil(t, err)
+}
+
+func TestListPods(t *testing.T) {
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+		Group: "apps", Version: "v1", Kind: "DaemonSet", Namespace: "test", Name: "app",
+	}}
+	newPod := func(name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Labels: labels}}
+	}
+	client := &kube.Client{Basic: fake.NewSimpleClientset(newDaemonSet(1, 1, 1),
+		newPod("app-x", map[string]string{"app": "app"}), newPod("other", map[string]string{"app": "other"}))}
+	pods, err := ability.ListPods(node, client)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pods))
+	assert.Equal(t, "app-x", pods[0].Name)
+}
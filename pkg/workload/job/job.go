@@ -0,0 +1,118 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const _jobNameLabel = "job-name"
+
+func init() {
+	workload.Register(ability)
+}
+
+// please refer to github.com/horizoncd/horizon/pkg/cluster/cd/workload/workload.go
+var ability = &job{}
+
+type job struct{}
+
+func (*job) MatchGK(gk schema.GroupKind) bool {
+	return gk.Group == "batch" && gk.Kind == "Job"
+}
+
+func (*job) getJobByNode(node *v1alpha1.ResourceNode, client *kube.Client) (*batchv1.Job, error) {
+	instance, err := client.Basic.BatchV1().Jobs(node.Namespace).Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				"failed to get job in k8s"),
+			"failed to get job in k8s: job = %s, ns = %v, err = %v", node.Name, node.Namespace, err)
+	}
+	return instance, nil
+}
+
+// IsHealthy takes the job as healthy unless it has failed
+func (j *job) IsHealthy(node *v1alpha1.ResourceNode, client *kube.Client) (bool, error) {
+	instance, err := j.getJobByNode(node, client)
+	if err != nil {
+		return true, err
+	}
+	return !IsFailed(instance), nil
+}
+
+func (j *job) ListPods(node *v1alpha1.ResourceNode, client *kube.Client) ([]corev1.Pod, error) {
+	instance, err := j.getJobByNode(node, client)
+	if err != nil {
+		return nil, err
+	}
+	return ListPods(client, instance)
+}
+
+func (*job) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return un, nil
+}
+
+// ListPods lists pods created by the job
+func ListPods(client *kube.Client, instance *batchv1.Job) ([]corev1.Pod, error) {
+	// the selector is generated by k8s, and pods are labeled by job-name as well
+	labelSelector := fmt.Sprintf("%s=%s", _jobNameLabel, instance.Name)
+	if instance.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(instance.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		labelSelector = selector.String()
+	}
+	pods, err := client.Basic.CoreV1().Pods(instance.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector:   labelSelector,
+		ResourceVersion: "0",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// IsFailed returns whether the job has failed
+func IsFailed(instance *batchv1.Job) bool {
+	return hasCondition(instance, batchv1.JobFailed)
+}
+
+// IsComplete returns whether the job has completed successfully
+func IsComplete(instance *batchv1.Job) bool {
+	return hasCondition(instance, batchv1.JobComplete)
+}
+
+func hasCondition(instance *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range instance.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"testing"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newJob(selector *metav1.LabelSelector, conditions ...batchv1.JobConditionType) *batchv1.Job {
+	instance := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Spec:       batchv1.JobSpec{Selector: selector},
+	}
+	for _, condition := range conditions {
+		instance.Status.Conditions = append(instance.Status.Conditions,
+			batchv1.JobCondition{Type: condition, Status: corev1.ConditionTrue})
+	}
+	return instance
+}
+
+func TestIsHealthy(t *testing.T) {
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+		Group: "batch", Version: "v1", Kind: "Job", Namespace: "test", Name: "app",
+	}}
+	for _, c := range []struct {
+		instance *batchv1.Job
+		failed   bool
+		complete bool
+	}{
+		{instance: newJob(nil)},
+		{instance: newJob(nil, batchv1.JobComplete), complete: true},
+		{instance: newJob(nil, batchv1.JobFailed), failed: true},
+	} {
+		assert.Equal(t, c.failed, IsFailed(c.instance))
+		assert.Equal(t, c.complete, IsComplete(c.instance))
+
+		healthy, err := ability.IsHealthy(node, &kube.Client{Basic: fake.NewSimpleClientset(c.instance)})
+		assert.Nil(t, err)
+		assert.Equal(t, !c.failed, healthy)
+	}
+
+	// condition not true is ignored
+	unknown := newJob(nil)
+	unknown.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionUnknown}}
+	assert.False(t, IsFailed(unknown))
+
+	_, err := ability.IsHealthy(node, &kube.Client{Basic: fake.NewSimpleClientset()})
+	assert.NotNil(t, err)
+}
+
+func TestListPods(t *testing.T) {
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+		Group: "batch", Version: "v1", Kind: "Job", Namespace: "test", Name: "app",
+	}}
+	newPod := func(name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Labels: labels}}
+	}
+	podsOfJob := []runtime.Object{
+		newPod("app-x", map[string]string{_jobNameLabel: "app", "controller-uid": "uid"}),
+		newPod("other-x", map[string]string{_jobNameLabel: "other", "controller-uid": "other-uid"}),
+	}
+
+	// pods are selected by job-name without selector
+	client := &kube.Client{Basic: fake.NewSimpleClientset(append(podsOfJob, newJob(nil))...)}
+	pods, err := ability.ListPods(node, client)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pods))
+	assert.Equal(t, "app-x", pods[0].Name)
+
+	// pods are selected by selector generated by k8s
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"controller-uid": "other-uid"}}
+	client = &kube.Client{Basic: fake.NewSimpleClientset(append(podsOfJob, newJob(selector))...)}
+	pods, err = ability.ListPods(node, client)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pods))
+	assert.Equal(t, "other-x", pods[0].Name)
+}
@@ -0,0 +1,258 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kruise
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+const (
+	Group = "apps.kruise.io"
+
+	KindCloneSet    = "CloneSet"
+	KindStatefulSet = "StatefulSet"
+)
+
+func init() {
+	workload.Register(cloneSetAbility)
+	workload.Register(statefulSetAbility)
+}
+
+// please refer to github.com/horizoncd/horizon/pkg/cluster/cd/workload/workload.go
+var (
+	cloneSetAbility = &kruiseWorkload{
+		kind:          KindCloneSet,
+		resource:      "clonesets",
+		partitionPath: []string{"spec", "updateStrategy", "partition"},
+		pausedPath:    []string{"spec", "updateStrategy", "paused"},
+	}
+	statefulSetAbility = &kruiseWorkload{
+		kind:          KindStatefulSet,
+		resource:      "statefulsets",
+		partitionPath: []string{"spec", "updateStrategy", "rollingUpdate", "partition"},
+		pausedPath:    []string{"spec", "updateStrategy", "rollingUpdate", "paused"},
+	}
+)
+
+// kruiseWorkload releases pods of OpenKruise CloneSet or Advanced StatefulSet in batches by the partition,
+// which is the number of pods kept in the old revision, so the partition is decreased batch by batch.
+// The number of batches is specified by the annotation ClusterReleaseBatchesKey, and the chart is supposed to
+// render the partition to hold all pods but the first batch when a new revision is deployed.
+// Pods may be updated in place, so revisions are tracked by the label controller-revision-hash.
+type kruiseWorkload struct {
+	kind          string
+	resource      string
+	partitionPath []string
+	pausedPath    []string
+}
+
+// Workload is the status of CloneSet or Advanced StatefulSet needed by horizon
+type Workload struct {
+	*unstructured.Unstructured
+	Replicas           int
+	Partition          int
+	Paused             bool
+	Batches            int
+	Selector           map[string]string
+	ObservedGeneration int64
+	ReadyReplicas      int
+	UpdatedReplicas    int
+	UpdateRevision     string
+}
+
+// MatchKruiseWorkload returns whether the node is CloneSet or Advanced StatefulSet
+func MatchKruiseWorkload(gk schema.GroupKind) bool {
+	return cloneSetAbility.MatchGK(gk) || statefulSetAbility.MatchGK(gk)
+}
+
+// GetByNode gets CloneSet or Advanced StatefulSet by the version of node
+func GetByNode(node *v1alpha1.ResourceNode, client *kube.Client) (*Workload, error) {
+	ability := abilityOf(node.Kind)
+	if ability == nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported kruise workload: %v", node.Kind)
+	}
+	return ability.getByNode(node, client)
+}
+
+func abilityOf(kind string) *kruiseWorkload {
+	switch kind {
+	case KindCloneSet:
+		return cloneSetAbility
+	case KindStatefulSet:
+		return statefulSetAbility
+	}
+	return nil
+}
+
+func (w *kruiseWorkload) MatchGK(gk schema.GroupKind) bool {
+	return gk.Group == Group && gk.Kind == w.kind
+}
+
+func (w *kruiseWorkload) getByNode(node *v1alpha1.ResourceNode, client *kube.Client) (*Workload, error) {
+	gvr := schema.GroupVersionResource{
+		Group:    Group,
+		Version:  node.Version,
+		Resource: w.resource,
+	}
+
+	un, err := client.Dynamic.Resource(gvr).Namespace(node.Namespace).
+		Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				"failed to get kruise workload in k8s"),
+			"failed to get %s in k8s: name = %s, ns = %v, err = %v", w.kind, node.Name, node.Namespace, err)
+	}
+	return w.parse(un), nil
+}
+
+func (w *kruiseWorkload) parse(un *unstructured.Unstructured) *Workload {
+	instance := &Workload{
+		Unstructured: un,
+		Replicas:     1,
+		Batches:      1,
+	}
+	if replicas, ok, _ := unstructured.NestedInt64(un.Object, "spec", "replicas"); ok {
+		instance.Replicas = int(replicas)
+	}
+	if value, ok, _ := unstructured.NestedFieldNoCopy(un.Object, w.partitionPath...); ok {
+		instance.Partition = scaledPartition(value, instance.Replicas)
+	}
+	instance.Paused, _, _ = unstructured.NestedBool(un.Object, w.pausedPath...)
+	if batches, err := strconv.Atoi(un.GetAnnotations()[common.ClusterReleaseBatchesKey]); err == nil && batches > 1 {
+		instance.Batches = batches
+	}
+	instance.Selector, _, _ = unstructured.NestedStringMap(un.Object, "spec", "selector", "matchLabels")
+	instance.ObservedGeneration, _, _ = unstructured.NestedInt64(un.Object, "status", "observedGeneration")
+	if ready, ok, _ := unstructured.NestedInt64(un.Object, "status", "readyReplicas"); ok {
+		instance.ReadyReplicas = int(ready)
+	}
+	if updated, ok, _ := unstructured.NestedInt64(un.Object, "status", "updatedReplicas"); ok {
+		instance.UpdatedReplicas = int(updated)
+	}
+	instance.UpdateRevision, _, _ = unstructured.NestedString(un.Object, "status", "updateRevision")
+	return instance
+}
+
+// scaledPartition converts the partition to the number of pods, as CloneSet accepts percentage partition
+func scaledPartition(value interface{}, replicas int) int {
+	var partition intstr.IntOrString
+	switch v := value.(type) {
+	case int64:
+		partition = intstr.FromInt(int(v))
+	case float64:
+		partition = intstr.FromInt(int(v))
+	case string:
+		partition = intstr.Parse(v)
+	default:
+		return 0
+	}
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(&partition, replicas, true)
+	if err != nil || scaled < 0 {
+		return 0
+	}
+	if scaled > replicas {
+		return replicas
+	}
+	return scaled
+}
+
+func (w *kruiseWorkload) IsHealthy(node *v1alpha1.ResourceNode,
+	client *kube.Client) (bool, error) {
+	instance, err := w.getByNode(node, client)
+	if err != nil {
+		return true, err
+	}
+
+	if instance.ObservedGeneration != instance.GetGeneration() {
+		return false, nil
+	}
+	// the release is in progress until all batches are released
+	if instance.Partition > 0 {
+		return false, nil
+	}
+	return instance.ReadyReplicas == instance.Replicas && instance.UpdatedReplicas == instance.Replicas, nil
+}
+
+func (w *kruiseWorkload) ListPods(node *v1alpha1.ResourceNode, client *kube.Client) ([]corev1.Pod, error) {
+	instance, err := w.getByNode(node, client)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := client.Basic.CoreV1().Pods(instance.GetNamespace()).List(context.TODO(), metav1.ListOptions{
+		LabelSelector:   polymorphichelpers.MakeLabels(instance.Selector),
+		ResourceVersion: "0",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+func (w *kruiseWorkload) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*workload.Step, error) {
+	instance, err := w.getByNode(node, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return instance.Step(), nil
+}
+
+// Step gets the batch step of the workload by the partition
+func (w *Workload) Step() *workload.Step {
+	step := workload.PartitionStep(w.Replicas, w.Partition, w.Batches)
+	step.ManualPaused = w.Paused
+	return step
+}
+
+func (w *kruiseWorkload) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	instance := w.parse(un)
+
+	partition, paused := instance.Partition, false
+	switch actionName {
+	case "promote":
+		partition = workload.NextPartition(instance.Replicas, instance.Partition, instance.Batches)
+	case "promote-full":
+		partition = 0
+	case "resume":
+	case "pause":
+		paused = true
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action: %v", actionName)
+	}
+
+	if err := unstructured.SetNestedField(un.Object, int64(partition), w.partitionPath...); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to set partition: %v", err)
+	}
+	if err := unstructured.SetNestedField(un.Object, paused, w.pausedPath...); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to set paused: %v", err)
+	}
+	return un, nil
+}
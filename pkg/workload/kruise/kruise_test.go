@@ -0,0 +1,198 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kruise
+
+import (
+	"testing"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newCloneSet(replicas int64, partition interface{}, paused bool, batches string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps.kruise.io/v1alpha1",
+		"kind":       KindCloneSet,
+		"metadata": map[string]interface{}{
+			"name":        "app",
+			"namespace":   "test",
+			"generation":  int64(2),
+			"annotations": map[string]interface{}{common.ClusterReleaseBatchesKey: batches},
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"app": "app"},
+			},
+			"updateStrategy": map[string]interface{}{
+				"type":      "InPlaceIfPossible",
+				"partition": partition,
+				"paused":    paused,
+			},
+		},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(2),
+			"replicas":           replicas,
+			"readyReplicas":      replicas,
+			"updatedReplicas":    replicas - int64(scaledPartition(partition, int(replicas))),
+			"updateRevision":     "app-5d8f7b9c6",
+		},
+	}}
+}
+
+func newAdvancedStatefulSet(replicas, partition int64, batches string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps.kruise.io/v1beta1",
+		"kind":       KindStatefulSet,
+		"metadata": map[string]interface{}{
+			"name":        "app",
+			"namespace":   "test",
+			"generation":  int64(2),
+			"annotations": map[string]interface{}{common.ClusterReleaseBatchesKey: batches},
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"app": "app"},
+			},
+			"updateStrategy": map[string]interface{}{
+				"type": "RollingUpdate",
+				"rollingUpdate": map[string]interface{}{
+					"podUpdatePolicy": "InPlaceIfPossible",
+					"partition":       partition,
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(2),
+			"replicas":           replicas,
+			"readyReplicas":      replicas,
+			"updatedReplicas":    replicas - partition,
+			"updateRevision":     "app-7c9f6d8b5",
+		},
+	}}
+}
+
+func TestGetStepsAndIsHealthy(t *testing.T) {
+	cloneSetNode := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+		Group: Group, Version: "v1alpha1", Kind: KindCloneSet, Namespace: "test", Name: "app",
+	}}
+	statefulSetNode := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+		Group: Group, Version: "v1beta1", Kind: KindStatefulSet, Namespace: "test", Name: "app",
+	}}
+	for _, c := range []struct {
+		node    *v1alpha1.ResourceNode
+		un      *unstructured.Unstructured
+		step    []int
+		index   int
+		paused  bool
+		healthy bool
+	}{
+		{node: cloneSetNode, un: newCloneSet(5, int64(0), false, ""), step: []int{5}, index: 1, healthy: true},
+		{node: cloneSetNode, un: newCloneSet(6, int64(4), false, "3"), step: []int{2, 2, 2}, index: 1},
+		{node: cloneSetNode, un: newCloneSet(6, "50%", true, "3"), step: []int{2, 2, 2}, index: 1, paused: true},
+		{node: cloneSetNode, un: newCloneSet(6, "100%", false, "3"), step: []int{2, 2, 2}, index: 0},
+		{node: statefulSetNode, un: newAdvancedStatefulSet(6, 2, "3"), step: []int{2, 2, 2}, index: 2},
+		{node: statefulSetNode, un: newAdvancedStatefulSet(6, 0, "3"), step: []int{2, 2, 2}, index: 3, healthy: true},
+	} {
+		client := &kube.Client{Dynamic: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), c.un)}
+		ability := abilityOf(c.node.Kind)
+		step, err := ability.GetSteps(c.node, client)
+		assert.Nil(t, err)
+		assert.Equal(t, c.step, step.Replicas)
+		assert.Equal(t, len(c.step), step.Total)
+		assert.Equal(t, c.index, step.Index)
+		assert.Equal(t, c.paused, step.ManualPaused)
+		assert.Equal(t, c.index > 0 && c.index < step.Total, step.StepPaused)
+
+		healthy, err := ability.IsHealthy(c.node, client)
+		assert.Nil(t, err)
+		assert.Equal(t, c.healthy, healthy)
+	}
+}
+
+func TestListPods(t *testing.T) {
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+		Group: Group, Version: "v1alpha1", Kind: KindCloneSet, Namespace: "test", Name: "app",
+	}}
+	client := &kube.Client{
+		Basic: fake.NewSimpleClientset(
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name: "app-x7k2p", Namespace: "test", Labels: map[string]string{"app": "app"},
+			}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name: "other-q8d4n", Namespace: "test", Labels: map[string]string{"app": "other"},
+			}},
+		),
+		Dynamic: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newCloneSet(1, int64(0), false, "")),
+	}
+	pods, err := cloneSetAbility.ListPods(node, client)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pods))
+	assert.Equal(t, "app-x7k2p", pods[0].Name)
+}
+
+func TestAction(t *testing.T) {
+	fieldsOf := func(ability *kruiseWorkload, un *unstructured.Unstructured) (int64, bool) {
+		partition, _, err := unstructured.NestedInt64(un.Object, ability.partitionPath...)
+		assert.Nil(t, err)
+		paused, _, err := unstructured.NestedBool(un.Object, ability.pausedPath...)
+		assert.Nil(t, err)
+		return partition, paused
+	}
+
+	un, err := cloneSetAbility.Action("promote", newCloneSet(6, int64(4), false, "3"))
+	assert.Nil(t, err)
+	partition, paused := fieldsOf(cloneSetAbility, un)
+	assert.Equal(t, int64(2), partition)
+	assert.False(t, paused)
+	un, err = cloneSetAbility.Action("promote", newCloneSet(6, "100%", false, "3"))
+	assert.Nil(t, err)
+	partition, _ = fieldsOf(cloneSetAbility, un)
+	assert.Equal(t, int64(4), partition)
+	un, err = cloneSetAbility.Action("pause", newCloneSet(6, int64(4), false, "3"))
+	assert.Nil(t, err)
+	partition, paused = fieldsOf(cloneSetAbility, un)
+	assert.Equal(t, int64(4), partition)
+	assert.True(t, paused)
+	un, err = cloneSetAbility.Action("resume", newCloneSet(6, int64(4), true, "3"))
+	assert.Nil(t, err)
+	partition, paused = fieldsOf(cloneSetAbility, un)
+	assert.Equal(t, int64(4), partition)
+	assert.False(t, paused)
+
+	un, err = statefulSetAbility.Action("promote-full", newAdvancedStatefulSet(6, 4, "3"))
+	assert.Nil(t, err)
+	partition, paused = fieldsOf(statefulSetAbility, un)
+	assert.Equal(t, int64(0), partition)
+	assert.False(t, paused)
+	un, err = statefulSetAbility.Action("pause", newAdvancedStatefulSet(6, 4, "3"))
+	assert.Nil(t, err)
+	_, paused = fieldsOf(statefulSetAbility, un)
+	assert.True(t, paused)
+
+	_, err = cloneSetAbility.Action("abort", newCloneSet(6, int64(4), false, "3"))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
@@ -17,6 +17,7 @@ package rollout
 import (
 	"context"
 	"math"
+	"time"
 
 	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	rolloutsv1alpha1 "github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
@@ -200,6 +201,16 @@ func (r *rollout) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*w
 		return nil, err
 	}
 
+	var stepPausedAt *time.Time
+	if !instance.Status.Abort {
+		for _, condition := range instance.Status.PauseConditions {
+			if condition.Reason == rolloutsv1alpha1.PauseReasonCanaryPauseStep {
+				stepPausedAt = &condition.StartTime.Time
+				break
+			}
+		}
+	}
+
 	// manual paused
 	return &workload.Step{
 		Index:        stepIndex,
@@ -207,6 +218,8 @@ func (r *rollout) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*w
 		Replicas:     incrementReplicasList,
 		ManualPaused: instance.Spec.Paused,
 		AutoPromote:  autoPromote,
+		StepPaused:   stepPausedAt != nil,
+		StepPausedAt: stepPausedAt,
 	}, nil
 }
 
@@ -244,6 +257,9 @@ func (r *rollout) Action(actionName string, un *unstructured.Unstructured) (*uns
 		spec["paused"] = false
 	case "cancel-auto-promote":
 		delete(status, "autoPromote")
+	case "abort":
+		// argo rollouts scales down the canary and rolls back to the stable version
+		status["abort"] = true
 	default:
 		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action: %v", actionName)
 	}
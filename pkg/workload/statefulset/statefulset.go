@@ -0,0 +1,185 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/workload"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+func init() {
+	workload.Register(ability)
+}
+
+// please refer to github.com/horizoncd/horizon/pkg/cluster/cd/workload/workload.go
+var ability = &statefulSet{}
+
+// statefulSet releases pods in batches by the partition of rolling update,
+// pods with ordinal not less than the partition are updated, so the partition is decreased batch by batch.
+// The number of batches is specified by the annotation ClusterReleaseBatchesKey, and the chart is supposed to
+// render the partition to hold all pods but the first batch when a new revision is deployed.
+type statefulSet struct{}
+
+func (*statefulSet) MatchGK(gk schema.GroupKind) bool {
+	return gk.Group == "apps" && gk.Kind == "StatefulSet"
+}
+
+func (*statefulSet) getStatefulSetByNode(node *v1alpha1.ResourceNode,
+	client *kube.Client) (*appsv1.StatefulSet, error) {
+	instance, err := client.Basic.AppsV1().StatefulSets(node.Namespace).
+		Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, perror.Wrapf(
+			herrors.NewErrGetFailed(herrors.ResourceInK8S,
+				"failed to get statefulset in k8s"),
+			"failed to get statefulset in k8s: statefulset = %s, ns = %v, err = %v", node.Name, node.Namespace, err)
+	}
+	return instance, nil
+}
+
+func (s *statefulSet) IsHealthy(node *v1alpha1.ResourceNode,
+	client *kube.Client) (bool, error) {
+	instance, err := s.getStatefulSetByNode(node, client)
+	if err != nil {
+		return true, err
+	}
+
+	if instance.Status.ObservedGeneration != instance.Generation {
+		return false, nil
+	}
+	// the release is in progress until all pods are updated to the update revision,
+	// the partition is not checked as the chart always renders one
+	if !revisionUpdated(instance) {
+		return false, nil
+	}
+	return instance.Status.ReadyReplicas == replicasOf(instance), nil
+}
+
+func (s *statefulSet) ListPods(node *v1alpha1.ResourceNode, client *kube.Client) ([]corev1.Pod, error) {
+	instance, err := s.getStatefulSetByNode(node, client)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := client.Basic.CoreV1().Pods(instance.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector:   polymorphichelpers.MakeLabels(instance.Spec.Selector.MatchLabels),
+		ResourceVersion: "0",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+func (s *statefulSet) GetSteps(node *v1alpha1.ResourceNode, client *kube.Client) (*workload.Step, error) {
+	instance, err := s.getStatefulSetByNode(node, client)
+	if err != nil {
+		return nil, err
+	}
+
+	partition := partitionOf(instance)
+	if instance.Status.ObservedGeneration == instance.Generation && revisionUpdated(instance) {
+		// all batches have been released
+		partition = 0
+	}
+	return workload.PartitionStep(int(replicasOf(instance)), int(partition), batchesOf(instance)), nil
+}
+
+func (*statefulSet) Action(actionName string, un *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	var instance *appsv1.StatefulSet
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(un.UnstructuredContent(), &instance)
+	if err != nil {
+		return un, perror.Wrapf(herrors.ErrParamInvalid, "convert to statefulset failed: %v", err)
+	}
+	if instance.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"action %v is not supported by statefulset with OnDelete strategy", actionName)
+	}
+
+	replicas := int(replicasOf(instance))
+	var partition int
+	switch actionName {
+	case "promote":
+		partition = workload.NextPartition(replicas, int(partitionOf(instance)), batchesOf(instance))
+	case "promote-full", "resume":
+		partition = 0
+	case "pause":
+		// hold the pods which have not been updated
+		partition = replicas - int(instance.Status.UpdatedReplicas)
+		if current := int(partitionOf(instance)); current > partition {
+			partition = current
+		}
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action: %v", actionName)
+	}
+
+	if err := unstructured.SetNestedField(un.Object, int64(partition),
+		"spec", "updateStrategy", "rollingUpdate", "partition"); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to set partition: %v", err)
+	}
+	return un, nil
+}
+
+func replicasOf(instance *appsv1.StatefulSet) int32 {
+	if instance.Spec.Replicas == nil {
+		return 1
+	}
+	return *instance.Spec.Replicas
+}
+
+func partitionOf(instance *appsv1.StatefulSet) int32 {
+	rollingUpdate := instance.Spec.UpdateStrategy.RollingUpdate
+	if instance.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType ||
+		rollingUpdate == nil || rollingUpdate.Partition == nil {
+		return 0
+	}
+	if *rollingUpdate.Partition > replicasOf(instance) {
+		return replicasOf(instance)
+	}
+	return *rollingUpdate.Partition
+}
+
+// revisionUpdated returns whether all pods are updated to the update revision
+func revisionUpdated(instance *appsv1.StatefulSet) bool {
+	if instance.Status.UpdateRevision != "" {
+		return instance.Status.UpdateRevision == instance.Status.CurrentRevision
+	}
+	return instance.Status.UpdatedReplicas == replicasOf(instance)
+}
+
+func batchesOf(instance *appsv1.StatefulSet) int {
+	if instance.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return 1
+	}
+	batches, err := strconv.Atoi(instance.Annotations[common.ClusterReleaseBatchesKey])
+	if err != nil || batches < 1 {
+		return 1
+	}
+	return batches
+}
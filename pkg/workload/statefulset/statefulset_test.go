@@ -0,0 +1,153 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"testing"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newStatefulSet(replicas, partition int32, batches string) *appsv1.StatefulSet {
+	currentRevision := "app-1"
+	if partition == 0 {
+		currentRevision = "app-2"
+	}
+	return &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Namespace:   "test",
+			Generation:  2,
+			Annotations: map[string]string{common.ClusterReleaseBatchesKey: batches},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}},
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{
+			ObservedGeneration: 2,
+			ReadyReplicas:      replicas,
+			UpdatedReplicas:    replicas - partition,
+			CurrentRevision:    currentRevision,
+			UpdateRevision:     "app-2",
+		},
+	}
+}
+
+func TestGetStepsAndIsHealthy(t *testing.T) {
+	node := &v1alpha1.ResourceNode{ResourceRef: v1alpha1.ResourceRef{
+		Group: "apps", Version: "v1", Kind: "StatefulSet", Namespace: "test", Name: "app",
+	}}
+	for _, c := range []struct {
+		replicas  int32
+		partition int32
+		batches   string
+		step      []int
+		index     int
+		healthy   bool
+	}{
+		{replicas: 5, partition: 0, batches: "", step: []int{5}, index: 1, healthy: true},
+		{replicas: 6, partition: 4, batches: "3", step: []int{2, 2, 2}, index: 1, healthy: false},
+		{replicas: 6, partition: 2, batches: "3", step: []int{2, 2, 2}, index: 2, healthy: false},
+		{replicas: 6, partition: 0, batches: "3", step: []int{2, 2, 2}, index: 3, healthy: true},
+		{replicas: 6, partition: 6, batches: "3", step: []int{2, 2, 2}, index: 0, healthy: false},
+	} {
+		client := &kube.Client{Basic: fake.NewSimpleClientset(newStatefulSet(c.replicas, c.partition, c.batches))}
+		step, err := ability.GetSteps(node, client)
+		assert.Nil(t, err)
+		assert.Equal(t, c.step, step.Replicas)
+		assert.Equal(t, len(c.step), step.Total)
+		assert.Equal(t, c.index, step.Index)
+		assert.Equal(t, c.index > 0 && c.index < step.Total, step.StepPaused)
+
+		healthy, err := ability.IsHealthy(node, client)
+		assert.Nil(t, err)
+		assert.Equal(t, c.healthy, healthy)
+	}
+
+	// the partition rendered by chart is kept after all pods are updated
+	released := newStatefulSet(6, 4, "3")
+	released.Status.UpdatedReplicas = 6
+	released.Status.CurrentRevision = released.Status.UpdateRevision
+	client := &kube.Client{Basic: fake.NewSimpleClientset(released)}
+	step, err := ability.GetSteps(node, client)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, step.Index)
+	assert.False(t, step.StepPaused)
+	healthy, err := ability.IsHealthy(node, client)
+	assert.Nil(t, err)
+	assert.True(t, healthy)
+
+	// pods not ready
+	released.Status.ReadyReplicas = 5
+	client = &kube.Client{Basic: fake.NewSimpleClientset(released)}
+	healthy, err = ability.IsHealthy(node, client)
+	assert.Nil(t, err)
+	assert.False(t, healthy)
+}
+
+func TestAction(t *testing.T) {
+	toUnstructured := func(instance *appsv1.StatefulSet) *unstructured.Unstructured {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(instance)
+		assert.Nil(t, err)
+		return &unstructured.Unstructured{Object: content}
+	}
+	partitionOfUn := func(un *unstructured.Unstructured) int64 {
+		partition, _, err := unstructured.NestedInt64(un.Object, "spec", "updateStrategy", "rollingUpdate", "partition")
+		assert.Nil(t, err)
+		return partition
+	}
+
+	un, err := ability.Action("promote", toUnstructured(newStatefulSet(6, 4, "3")))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), partitionOfUn(un))
+	un, err = ability.Action("promote", toUnstructured(newStatefulSet(6, 2, "3")))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), partitionOfUn(un))
+	un, err = ability.Action("promote", toUnstructured(newStatefulSet(6, 6, "3")))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), partitionOfUn(un))
+	un, err = ability.Action("promote-full", toUnstructured(newStatefulSet(6, 4, "3")))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), partitionOfUn(un))
+
+	paused := newStatefulSet(6, 0, "3")
+	paused.Status.UpdatedReplicas = 1
+	un, err = ability.Action("pause", toUnstructured(paused))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), partitionOfUn(un))
+
+	_, err = ability.Action("abort", toUnstructured(newStatefulSet(6, 4, "3")))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	onDelete := newStatefulSet(6, 4, "3")
+	onDelete.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+	_, err = ability.Action("promote", toUnstructured(onDelete))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
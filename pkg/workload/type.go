@@ -15,6 +15,8 @@
 package workload
 
 import (
+	"time"
+
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -24,6 +26,10 @@ type Step struct {
 	Replicas     []int
 	ManualPaused bool
 	AutoPromote  bool
+	// StepPaused indicates whether the workload is paused by a pause step
+	StepPaused bool
+	// StepPausedAt is the time when the workload is paused by the pause step
+	StepPausedAt *time.Time
 }
 
 type Revision struct {